@@ -56,6 +56,15 @@ func main() {
 	cmd.RegisterDatastoreRootFlags(datastoreCmd)
 	rootCmd.AddCommand(datastoreCmd)
 
+	// Add schema commands
+	schemaCmd, err := cmd.NewSchemaCommand(rootCmd.Use)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to register schema command")
+	}
+
+	cmd.RegisterSchemaRootFlags(schemaCmd)
+	rootCmd.AddCommand(schemaCmd)
+
 	// Add head command.
 	headCmd := cmd.NewHeadCommand(rootCmd.Use)
 	cmd.RegisterHeadFlags(headCmd)
@@ -69,6 +78,11 @@ func main() {
 	}
 	rootCmd.AddCommand(serveCmd)
 
+	// Add language server command
+	lspCmd := cmd.NewLSPCommand(rootCmd.Use)
+	cmd.RegisterLSPFlags(lspCmd)
+	rootCmd.AddCommand(lspCmd)
+
 	devtoolsCmd := cmd.NewDevtoolsCommand(rootCmd.Use)
 	cmd.RegisterDevtoolsFlags(devtoolsCmd)
 	rootCmd.AddCommand(devtoolsCmd)
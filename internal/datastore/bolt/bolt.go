@@ -0,0 +1,193 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+)
+
+func init() {
+	datastore.Engines = append(datastore.Engines, Engine)
+}
+
+const (
+	Engine = "bolt"
+
+	errUnableToInstantiate = "unable to instantiate bolt datastore: %w"
+
+	openTimeout = 5 * time.Second
+)
+
+var errNotMigrated = errors.New("bolt datastore is not migrated, please run `spicedb migrate`")
+
+// NewBoltDatastore creates a new datastore which stores its relationships, definitions and
+// revisions in the bolt file at the given path, which must already have been migrated.
+//
+// Every version of the stored objects is kept until it was deleted outside of the GC window,
+// so that any revision within the window can be read directly from the file.
+func NewBoltDatastore(path string, options ...Option) (datastore.Datastore, error) {
+	config, err := generateConfig(options)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
+	quantization := config.revisionQuantization
+	if quantization <= 1 {
+		quantization = 1
+	}
+
+	gcCtx, cancelGc := context.WithCancel(context.Background())
+	ds := &boltDatastore{
+		db:       db,
+		driver:   migrations.NewBoltDriverFromDB(db),
+		writeSem: make(chan struct{}, 1),
+
+		watchBufferLength:  config.watchBufferLength,
+		quantizationPeriod: quantization.Nanoseconds(),
+		gcWindow:           config.gcWindow,
+		gcInterval:         config.gcInterval,
+		gcTimeout:          config.gcMaxOperationTime,
+		gcCtx:              gcCtx,
+		cancelGc:           cancelGc,
+	}
+
+	if err := ds.loadMetadata(); err != nil {
+		cancelGc()
+		db.Close()
+		return nil, fmt.Errorf(errUnableToInstantiate, err)
+	}
+
+	// Start a goroutine for garbage collection.
+	if ds.gcInterval > 0*time.Minute && config.gcEnabled {
+		ds.gcGroup, ds.gcCtx = errgroup.WithContext(ds.gcCtx)
+		ds.gcGroup.Go(func() error {
+			return common.StartGarbageCollector(
+				ds.gcCtx,
+				ds,
+				ds.gcInterval,
+				ds.gcWindow,
+				ds.gcTimeout,
+			)
+		})
+	} else {
+		log.Warn().Msg("datastore background garbage collection disabled")
+	}
+
+	return ds, nil
+}
+
+type boltDatastore struct {
+	revision.DecimalDecoder
+
+	db       *bbolt.DB
+	driver   *migrations.BoltDriver
+	uniqueID string
+
+	// writeSem is held by the read-write transaction which has begun writing, as bolt allows
+	// only a single writer at a time.
+	writeSem chan struct{}
+
+	// headRevision and gcWatermark cache the values persisted in the metadata bucket, and are
+	// updated once the transactions which change them have committed.
+	sync.RWMutex
+	headRevision uint64
+	gcWatermark  uint64
+
+	watchBufferLength  uint16
+	quantizationPeriod int64
+	gcWindow           time.Duration
+	gcInterval         time.Duration
+	gcTimeout          time.Duration
+	gcGroup            *errgroup.Group
+	gcCtx              context.Context
+	cancelGc           context.CancelFunc
+}
+
+// loadMetadata loads the unique ID, head revision and GC watermark of the datastore. A file
+// which has not been migrated has none, and is reported as not ready.
+func (bds *boltDatastore) loadMetadata() error {
+	return bds.db.View(func(tx *bbolt.Tx) error {
+		metadata := tx.Bucket(migrations.BucketMetadata)
+		if metadata == nil {
+			return nil
+		}
+
+		bds.uniqueID = string(metadata.Get(migrations.KeyUniqueID))
+		if head := metadata.Get(migrations.KeyHeadRevision); head != nil {
+			bds.headRevision = binary.BigEndian.Uint64(head)
+		}
+		if watermark := metadata.Get(migrations.KeyGCWatermark); watermark != nil {
+			bds.gcWatermark = binary.BigEndian.Uint64(watermark)
+		}
+		return nil
+	})
+}
+
+// bucket returns the bucket with the given name, which is missing if the datastore has not
+// been migrated.
+func bucket(tx *bbolt.Tx, name []byte) (*bbolt.Bucket, error) {
+	found := tx.Bucket(name)
+	if found == nil {
+		return nil, errNotMigrated
+	}
+	return found, nil
+}
+
+func (bds *boltDatastore) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
+	headMigration, err := migrations.BoltMigrations.HeadRevision()
+	if err != nil {
+		return datastore.ReadyState{}, fmt.Errorf("invalid head migration found for bolt: %w", err)
+	}
+
+	version, err := bds.driver.Version(ctx)
+	if err != nil {
+		return datastore.ReadyState{}, err
+	}
+
+	if version != headMigration {
+		return datastore.ReadyState{
+			Message: fmt.Sprintf(
+				"datastore is not migrated: currently at revision `%s`, but requires `%s`. Please run `spicedb migrate`.",
+				version,
+				headMigration,
+			),
+			IsReady: false,
+		}, nil
+	}
+
+	return datastore.ReadyState{IsReady: true}, nil
+}
+
+func (bds *boltDatastore) Features(_ context.Context) (*datastore.Features, error) {
+	return &datastore.Features{Watch: datastore.Feature{Enabled: true}}, nil
+}
+
+func (bds *boltDatastore) Close() error {
+	bds.cancelGc()
+	if bds.gcGroup != nil {
+		if err := bds.gcGroup.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+			log.Error().Err(err).Msg("error waiting for garbage collector to shutdown")
+		}
+	}
+
+	return bds.db.Close()
+}
+
+var _ datastore.Datastore = &boltDatastore{}
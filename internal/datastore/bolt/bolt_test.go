@@ -0,0 +1,202 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	test "github.com/authzed/spicedb/pkg/datastore/test"
+	"github.com/authzed/spicedb/pkg/migrate"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type boltTest struct {
+	t *testing.T
+}
+
+func (bt boltTest) New(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+	path := newMigratedFile(bt.t)
+	return NewBoltDatastore(
+		path,
+		RevisionQuantization(revisionQuantization),
+		GCInterval(gcInterval),
+		GCWindow(gcWindow),
+		WatchBufferLength(watchBufferLength),
+	)
+}
+
+func newMigratedFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "spicedb.db")
+
+	driver, err := migrations.NewBoltDriver(path)
+	require.NoError(t, err)
+	defer driver.Close(context.Background())
+
+	require.NoError(t, migrations.BoltMigrations.Run(context.Background(), driver, migrate.Head, migrate.LiveRun))
+	return path
+}
+
+func TestBoltDatastore(t *testing.T) {
+	test.All(t, boltTest{t})
+	t.Run("TestWatchSchemaDeletion", func(t *testing.T) { test.WatchSchemaDeletionTest(t, boltTest{t}) })
+}
+
+func TestBoltNotMigrated(t *testing.T) {
+	require := require.New(t)
+
+	ds, err := NewBoltDatastore(filepath.Join(t.TempDir(), "spicedb.db"))
+	require.NoError(err)
+	defer ds.Close()
+
+	state, err := ds.ReadyState(context.Background())
+	require.NoError(err)
+	require.False(state.IsReady)
+	require.Contains(state.Message, "not migrated")
+}
+
+var (
+	testResourceNS = ns.Namespace("resource", ns.MustRelation("reader", nil))
+	testUserNS     = ns.Namespace("user")
+)
+
+func TestBoltDurability(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	path := newMigratedFile(t)
+
+	ds, err := NewBoltDatastore(path, GCEnabled(false))
+	require.NoError(err)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, testResourceNS, testUserNS)
+	})
+	require.NoError(err)
+
+	written, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("resource:first#reader@user:tom")),
+			tuple.Create(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	deleted, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Delete(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	stats, err := ds.Statistics(ctx)
+	require.NoError(err)
+	require.NoError(ds.Close())
+
+	// Reopening the datastore restores its state at every revision.
+	ds, err = NewBoltDatastore(path, GCEnabled(false))
+	require.NoError(err)
+	defer ds.Close()
+
+	head, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+	require.True(head.Equal(deleted))
+
+	requireRelationships(ctx, require, ds.SnapshotReader(written), "resource:first#reader@user:tom", "resource:second#reader@user:sarah")
+	requireRelationships(ctx, require, ds.SnapshotReader(deleted), "resource:first#reader@user:tom")
+
+	reopenedStats, err := ds.Statistics(ctx)
+	require.NoError(err)
+	require.Equal(stats.UniqueID, reopenedStats.UniqueID)
+
+	// Writes after reopening are made at later revisions.
+	rewritten, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Touch(tuple.MustParse("resource:third#reader@user:tom")),
+		})
+	})
+	require.NoError(err)
+	require.True(rewritten.GreaterThan(deleted))
+}
+
+func TestBoltGarbageCollection(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	path := newMigratedFile(t)
+
+	ds, err := NewBoltDatastore(path, GCEnabled(false))
+	require.NoError(err)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, testResourceNS, testUserNS)
+	})
+	require.NoError(err)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("resource:first#reader@user:tom")),
+			tuple.Create(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	collectedThrough, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Delete(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	latest, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("resource:third#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	gc := ds.(common.GarbageCollector)
+	watermark, err := gc.TxIDBefore(ctx, time.Now().Add(time.Hour))
+	require.NoError(err)
+	require.True(watermark.Equal(latest))
+
+	counts, err := gc.DeleteBeforeTx(ctx, collectedThrough)
+	require.NoError(err)
+	require.Equal(common.DeletionCounts{Relationships: 1, Transactions: 3}, counts)
+	require.NoError(ds.Close())
+
+	// The collected revisions remain unreadable once the datastore is reopened.
+	ds, err = NewBoltDatastore(path, GCEnabled(false))
+	require.NoError(err)
+	defer ds.Close()
+
+	_, err = ds.SnapshotReader(collectedThrough).QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: testResourceNS.Name})
+	require.ErrorAs(err, &datastore.ErrInvalidRevision{})
+
+	requireRelationships(ctx, require, ds.SnapshotReader(latest), "resource:first#reader@user:tom", "resource:third#reader@user:sarah")
+
+	_, _, err = ds.SnapshotReader(latest).ReadNamespaceByName(ctx, testResourceNS.Name)
+	require.NoError(err)
+
+	stats, err := ds.DetailedStatistics(ctx)
+	require.NoError(err)
+	require.Equal(uint64(1), stats.ChangelogBacklog)
+}
+
+func requireRelationships(ctx context.Context, require *require.Assertions, reader datastore.Reader, expected ...string) {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: testResourceNS.Name})
+	require.NoError(err)
+	defer iter.Close()
+
+	var found []string
+	for rt := iter.Next(); rt != nil; rt = iter.Next() {
+		found = append(found, tuple.MustString(rt))
+	}
+	require.NoError(iter.Err())
+	require.ElementsMatch(expected, found)
+}
@@ -0,0 +1,89 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/util"
+)
+
+func (r *boltReader) ReadCaveatByName(_ context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	if r.initErr != nil {
+		return nil, datastore.NoRevision, r.initErr
+	}
+
+	found, err := readDefinitions(r.view, migrations.BucketCaveats, []string{name}, newCaveat)
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	if len(found) == 0 {
+		return nil, datastore.NoRevision, datastore.NewCaveatNameNotFoundErr(name)
+	}
+
+	return found[0].Definition, found[0].LastWrittenRevision, nil
+}
+
+func (r *boltReader) ListAllCaveats(_ context.Context) ([]datastore.RevisionedCaveat, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	return readDefinitions(r.view, migrations.BucketCaveats, nil, newCaveat)
+}
+
+func (r *boltReader) LookupCaveatsWithNames(_ context.Context, caveatNames []string) ([]datastore.RevisionedCaveat, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	if len(caveatNames) == 0 {
+		return nil, nil
+	}
+
+	return readDefinitions(r.view, migrations.BucketCaveats, caveatNames, newCaveat)
+}
+
+func newCaveat() *core.CaveatDefinition {
+	return &core.CaveatDefinition{}
+}
+
+func (rwt *boltReadWriteTx) WriteCaveats(_ context.Context, caveats []*core.CaveatDefinition) error {
+	return rwt.withTx(func(tx *bbolt.Tx, rev uint64) error {
+		definitions := tx.Bucket(migrations.BucketCaveats)
+		caveatNames := util.NewSet[string]()
+		for _, coreCaveat := range caveats {
+			if !caveatNames.Add(coreCaveat.Name) {
+				return fmt.Errorf("duplicate caveat %s", coreCaveat.Name)
+			}
+
+			if err := writeDefinition(definitions, coreCaveat.Name, coreCaveat, rev); err != nil {
+				return err
+			}
+			rwt.changes.AddChangedDefinition(rwt.ctx, revisionFromNanos(rev), coreCaveat.CloneVT())
+		}
+		return nil
+	})
+}
+
+func (rwt *boltReadWriteTx) DeleteCaveats(_ context.Context, names []string) error {
+	return rwt.withTx(func(tx *bbolt.Tx, rev uint64) error {
+		definitions := tx.Bucket(migrations.BucketCaveats)
+		for _, name := range names {
+			found, err := deleteDefinition(definitions, name, rev)
+			if err != nil {
+				return err
+			}
+
+			if found {
+				rwt.changes.AddDeletedCaveat(rwt.ctx, revisionFromNanos(rev), name)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,275 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var _ common.GarbageCollector = (*boltDatastore)(nil)
+
+func (bds *boltDatastore) Now(_ context.Context) (time.Time, error) {
+	return time.Now().UTC(), nil
+}
+
+func (bds *boltDatastore) TxIDBefore(ctx context.Context, before time.Time) (datastore.Revision, error) {
+	var found []byte
+	err := bds.db.View(func(tx *bbolt.Tx) error {
+		changelog, err := bucket(tx, migrations.BucketChangelog)
+		if err != nil {
+			return err
+		}
+
+		// Find the highest revision before the GC window.
+		cursor := changelog.Cursor()
+		k, _ := cursor.Seek(revisionKey(uint64(before.UnixNano())))
+		if k == nil {
+			k, _ = cursor.Last()
+		} else {
+			k, _ = cursor.Prev()
+		}
+		found = bytes.Clone(k)
+		return nil
+	})
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	if found == nil {
+		log.Ctx(ctx).Debug().Time("before", before).Msg("no stale transactions found in the datastore")
+		return datastore.NoRevision, nil
+	}
+	return revisionFromKey(found), nil
+}
+
+// DeleteBeforeTx removes the changelog entries at revisions up to and including txID, along
+// with the versions of relationships and definitions deleted at those revisions, after which
+// the revisions can no longer be read or watched. The state at txID itself remains readable
+// while it is the head revision.
+func (bds *boltDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revision) (common.DeletionCounts, error) {
+	var counts common.DeletionCounts
+	if txID == datastore.NoRevision {
+		return counts, nil
+	}
+
+	watermark, err := nanosFromRevision(txID)
+	if err != nil {
+		return counts, err
+	}
+
+	err = bds.update(ctx, func(tx *bbolt.Tx) error {
+		transactions, err := deleteChangelogThrough(tx, watermark)
+		if err != nil {
+			return err
+		}
+		counts.Transactions = transactions
+
+		relationships, err := deleteRelationshipsDeletedThrough(tx, watermark)
+		if err != nil {
+			return err
+		}
+		counts.Relationships = relationships
+
+		namespaces, err := deleteDefinitionsDeletedThrough(tx, migrations.BucketNamespaces, watermark)
+		if err != nil {
+			return err
+		}
+		counts.Namespaces = namespaces
+
+		if _, err := deleteDefinitionsDeletedThrough(tx, migrations.BucketCaveats, watermark); err != nil {
+			return err
+		}
+
+		return tx.Bucket(migrations.BucketMetadata).Put(migrations.KeyGCWatermark, revisionKey(watermark))
+	})
+	if err != nil {
+		return common.DeletionCounts{}, err
+	}
+
+	bds.Lock()
+	bds.gcWatermark = watermark
+	bds.Unlock()
+
+	return counts, nil
+}
+
+// update runs f in a write transaction, once no read-write transaction is writing.
+func (bds *boltDatastore) update(ctx context.Context, f func(tx *bbolt.Tx) error) error {
+	select {
+	case bds.writeSem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-bds.writeSem }()
+
+	return bds.db.Update(f)
+}
+
+// deleteChangelogThrough deletes the changelog entries at revisions up to and including the
+// watermark.
+func deleteChangelogThrough(tx *bbolt.Tx, watermark uint64) (int64, error) {
+	changelog, err := bucket(tx, migrations.BucketChangelog)
+	if err != nil {
+		return 0, err
+	}
+
+	// Keys cannot be deleted while iterating over the bucket with a cursor.
+	var collected [][]byte
+	cursor := changelog.Cursor()
+	for k, _ := cursor.First(); k != nil && binary.BigEndian.Uint64(k) <= watermark; k, _ = cursor.Next() {
+		collected = append(collected, bytes.Clone(k))
+	}
+
+	for _, k := range collected {
+		if err := changelog.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(collected)), nil
+}
+
+// deleteRelationshipsDeletedThrough deletes the versions of relationships which were deleted
+// at revisions up to and including the watermark, found through the index of deletions.
+func deleteRelationshipsDeletedThrough(tx *bbolt.Tx, watermark uint64) (int64, error) {
+	deletions, err := bucket(tx, migrations.BucketRelationshipDeletions)
+	if err != nil {
+		return 0, err
+	}
+
+	relationships := tx.Bucket(migrations.BucketRelationships)
+	bySubject := tx.Bucket(migrations.BucketRelationshipsBySubject)
+
+	var collected [][]byte
+	cursor := deletions.Cursor()
+	for k, _ := cursor.First(); k != nil && binary.BigEndian.Uint64(k) <= watermark; k, _ = cursor.Next() {
+		collected = append(collected, bytes.Clone(k))
+	}
+
+	var removed int64
+	for _, k := range collected {
+		key := k[revisionSize:]
+		if value := relationships.Get(key); value != nil {
+			rt, err := decodeRelationship(value)
+			if err != nil {
+				return 0, err
+			}
+
+			if err := bySubject.Delete(versionKey(subjectIndexKey(rt), createdFromKey(key))); err != nil {
+				return 0, err
+			}
+			if err := relationships.Delete(key); err != nil {
+				return 0, err
+			}
+			removed++
+		}
+
+		if err := deletions.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return removed, nil
+}
+
+// deleteDefinitionsDeletedThrough deletes the versions of definitions which were deleted at
+// revisions up to and including the watermark.
+func deleteDefinitionsDeletedThrough(tx *bbolt.Tx, bucketName []byte, watermark uint64) (int64, error) {
+	definitions, err := bucket(tx, bucketName)
+	if err != nil {
+		return 0, err
+	}
+
+	var collected [][]byte
+	if err := definitions.ForEach(func(k, v []byte) error {
+		if deletedFromValue(v) <= watermark {
+			collected = append(collected, bytes.Clone(k))
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	for _, k := range collected {
+		if err := definitions.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(collected)), nil
+}
+
+// DeleteExpiredRels deletes all relationships which have expired, returning the number of
+// relationships deleted. The deletions are made in a new revision, so that watchers are
+// notified of the removal of each expired relationship.
+func (bds *boltDatastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	// Check for expired relationships before starting a transaction, to avoid creating empty
+	// revisions when there is nothing to remove.
+	var expired []*core.RelationTupleUpdate
+	if err := bds.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		expired, err = expiredRelationships(tx, now)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	var removed int64
+	_, err := bds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		boltRWT := rwt.(*boltReadWriteTx)
+		return boltRWT.withTx(func(tx *bbolt.Tx, rev uint64) error {
+			expired, err := expiredRelationships(tx, now)
+			if err != nil {
+				return err
+			}
+
+			removed = int64(len(expired))
+			return boltRWT.write(tx, rev, expired...)
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// expiredRelationships returns deletion mutations for all live relationships which have
+// expired as of the given time.
+func expiredRelationships(tx *bbolt.Tx, now time.Time) ([]*core.RelationTupleUpdate, error) {
+	relationships, err := bucket(tx, migrations.BucketRelationships)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []*core.RelationTupleUpdate
+	err = relationships.ForEach(func(_, v []byte) error {
+		if deletedFromValue(v) != liveRevision {
+			return nil
+		}
+
+		rt, err := decodeRelationship(v)
+		if err != nil {
+			return err
+		}
+
+		if isExpired(rt, now) {
+			expired = append(expired, tuple.Delete(rt))
+		}
+		return nil
+	})
+	return expired, err
+}
@@ -0,0 +1,47 @@
+package migrations
+
+var (
+	// BucketMetadata holds the migration version, and the unique ID, head revision and GC
+	// watermark of the datastore.
+	BucketMetadata = []byte("metadata")
+
+	// BucketNamespaces holds every version of the namespace definitions, keyed by name and
+	// the revision at which the version was written.
+	BucketNamespaces = []byte("namespaces")
+
+	// BucketCaveats holds every version of the caveat definitions, keyed by name and the
+	// revision at which the version was written.
+	BucketCaveats = []byte("caveats")
+
+	// BucketRelationships holds every version of the relationships, keyed by resource, relation
+	// and subject, followed by the revision at which the version was written.
+	BucketRelationships = []byte("relationships")
+
+	// BucketRelationshipsBySubject indexes the relationships by subject, resource and relation,
+	// followed by the revision at which the version was written. Each value is the key of the
+	// version in BucketRelationships.
+	BucketRelationshipsBySubject = []byte("relationships_by_subject")
+
+	// BucketRelationshipDeletions indexes the deleted versions of relationships by the revision
+	// at which they were deleted, followed by the key of the version in BucketRelationships,
+	// so that garbage collection does not need to scan every relationship.
+	BucketRelationshipDeletions = []byte("relationship_deletions")
+
+	// BucketRelationshipCounts holds the number of relationships stored for each relation,
+	// keyed by definition and relation.
+	BucketRelationshipCounts = []byte("relationship_counts")
+
+	// BucketChangelog holds the changes made at each revision which has not yet been garbage
+	// collected, by revision.
+	BucketChangelog = []byte("changelog")
+
+	// KeyUniqueID is the metadata key of the unique ID of the datastore.
+	KeyUniqueID = []byte("unique_id")
+
+	// KeyHeadRevision is the metadata key of the revision of the last committed transaction.
+	KeyHeadRevision = []byte("head_revision")
+
+	// KeyGCWatermark is the metadata key of the revision up to which the datastore has been
+	// garbage collected, and so can no longer be read.
+	KeyGCWatermark = []byte("gc_watermark")
+)
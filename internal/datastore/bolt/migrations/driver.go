@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+const openTimeout = 5 * time.Second
+
+var keyVersion = []byte("version")
+
+// BoltDriver can migrate a bolt datastore file.
+type BoltDriver struct {
+	db     *bbolt.DB
+	ownsDB bool
+}
+
+// NewBoltDriver returns a migration driver for the bolt datastore file at the given path,
+// creating it if it does not exist.
+func NewBoltDriver(path string) (*BoltDriver, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open bolt datastore file: %w", err)
+	}
+
+	return &BoltDriver{db, true}, nil
+}
+
+// NewBoltDriverFromDB returns a migration driver for an already opened bolt datastore file,
+// which is not closed by the driver.
+func NewBoltDriverFromDB(db *bbolt.DB) *BoltDriver {
+	return &BoltDriver{db, false}
+}
+
+func (bd *BoltDriver) Version(_ context.Context) (string, error) {
+	var version string
+	err := bd.db.View(func(tx *bbolt.Tx) error {
+		metadata := tx.Bucket(BucketMetadata)
+		if metadata == nil {
+			// There is no metadata bucket, empty datastore
+			return nil
+		}
+
+		version = string(metadata.Get(keyVersion))
+		return nil
+	})
+	return version, err
+}
+
+// Conn returns the underlying bolt database for MigrationFunc to use.
+func (bd *BoltDriver) Conn() *bbolt.DB {
+	return bd.db
+}
+
+func (bd *BoltDriver) RunTx(ctx context.Context, f migrate.TxMigrationFunc[*bbolt.Tx]) error {
+	return bd.db.Update(func(tx *bbolt.Tx) error {
+		return f(ctx, tx)
+	})
+}
+
+func (bd *BoltDriver) WriteVersion(_ context.Context, tx *bbolt.Tx, version, _ string) error {
+	metadata := tx.Bucket(BucketMetadata)
+	if metadata == nil {
+		return fmt.Errorf("missing %s bucket", BucketMetadata)
+	}
+
+	return metadata.Put(keyVersion, []byte(version))
+}
+
+func (bd *BoltDriver) Close(_ context.Context) error {
+	if !bd.ownsDB {
+		return nil
+	}
+	return bd.db.Close()
+}
+
+var _ migrate.Driver[*bbolt.DB, *bbolt.Tx] = &BoltDriver{}
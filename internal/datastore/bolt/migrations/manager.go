@@ -0,0 +1,10 @@
+package migrations
+
+import (
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/pkg/migrate"
+)
+
+// BoltMigrations implements a migration manager for the bolt datastore.
+var BoltMigrations = migrate.NewManager[*BoltDriver, *bbolt.DB, *bbolt.Tx]()
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+func init() {
+	if err := BoltMigrations.Register("initial", "", nil, func(_ context.Context, tx *bbolt.Tx) error {
+		for _, name := range [][]byte{
+			BucketMetadata,
+			BucketNamespaces,
+			BucketCaveats,
+			BucketRelationships,
+			BucketRelationshipsBySubject,
+			BucketRelationshipDeletions,
+			BucketRelationshipCounts,
+			BucketChangelog,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		metadata := tx.Bucket(BucketMetadata)
+		if err := metadata.Put(KeyUniqueID, []byte(uuid.NewString())); err != nil {
+			return err
+		}
+
+		headRevision := binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano()))
+		return metadata.Put(KeyHeadRevision, headRevision)
+	}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
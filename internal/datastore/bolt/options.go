@@ -0,0 +1,113 @@
+package bolt
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	errQuantizationTooLarge = "revision quantization interval (%s) must be less than GC window (%s)"
+
+	defaultGarbageCollectionWindow           = 24 * time.Hour
+	defaultGarbageCollectionInterval         = time.Minute * 3
+	defaultGarbageCollectionMaxOperationTime = time.Minute
+	defaultWatchBufferLength                 = 128
+	defaultQuantization                      = 5 * time.Second
+	defaultGCEnabled                         = true
+)
+
+type boltOptions struct {
+	revisionQuantization time.Duration
+	gcWindow             time.Duration
+	gcInterval           time.Duration
+	gcMaxOperationTime   time.Duration
+	watchBufferLength    uint16
+	gcEnabled            bool
+}
+
+// Option provides the facility to configure the bolt datastore.
+type Option func(*boltOptions)
+
+func generateConfig(options []Option) (boltOptions, error) {
+	computed := boltOptions{
+		gcWindow:             defaultGarbageCollectionWindow,
+		gcInterval:           defaultGarbageCollectionInterval,
+		gcMaxOperationTime:   defaultGarbageCollectionMaxOperationTime,
+		watchBufferLength:    defaultWatchBufferLength,
+		revisionQuantization: defaultQuantization,
+		gcEnabled:            defaultGCEnabled,
+	}
+
+	for _, option := range options {
+		option(&computed)
+	}
+
+	// Run any checks on the config that need to be done
+	if computed.revisionQuantization >= computed.gcWindow {
+		return computed, fmt.Errorf(
+			errQuantizationTooLarge,
+			computed.revisionQuantization,
+			computed.gcWindow,
+		)
+	}
+
+	return computed, nil
+}
+
+// WatchBufferLength is the number of entries that can be stored in the watch
+// buffer while awaiting read by the client.
+//
+// This value defaults to 128.
+func WatchBufferLength(watchBufferLength uint16) Option {
+	return func(bo *boltOptions) {
+		bo.watchBufferLength = watchBufferLength
+	}
+}
+
+// RevisionQuantization is the time bucket size to which advertised
+// revisions will be rounded.
+//
+// This value defaults to 5 seconds.
+func RevisionQuantization(quantization time.Duration) Option {
+	return func(bo *boltOptions) {
+		bo.revisionQuantization = quantization
+	}
+}
+
+// GCWindow is the maximum age of a passed revision that will be considered
+// valid.
+//
+// This value defaults to 24 hours.
+func GCWindow(window time.Duration) Option {
+	return func(bo *boltOptions) {
+		bo.gcWindow = window
+	}
+}
+
+// GCInterval is the interval at which garbage collection will occur.
+//
+// This value defaults to 3 minutes.
+func GCInterval(interval time.Duration) Option {
+	return func(bo *boltOptions) {
+		bo.gcInterval = interval
+	}
+}
+
+// GCEnabled indicates whether garbage collection is enabled.
+//
+// GC is enabled by default.
+func GCEnabled(isGCEnabled bool) Option {
+	return func(bo *boltOptions) {
+		bo.gcEnabled = isGCEnabled
+	}
+}
+
+// GCMaxOperationTime is the maximum operation time of a garbage collection
+// pass before it times out.
+//
+// This value defaults to 1 minute.
+func GCMaxOperationTime(time time.Duration) Option {
+	return func(bo *boltOptions) {
+		bo.gcMaxOperationTime = time
+	}
+}
@@ -0,0 +1,497 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jzelinskie/stringz"
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// iteratorChunkSize is the number of relationships read by an iterator in each bolt
+// transaction. Iterators do not hold a transaction open while they are being consumed, as a
+// long-running read transaction prevents bolt from growing the file.
+const iteratorChunkSize = 1000
+
+// viewFunc runs f in a bolt transaction, along with the revision at which it reads.
+type viewFunc func(f func(tx *bbolt.Tx, rev uint64) error) error
+
+type boltReader struct {
+	view    viewFunc
+	initErr error
+}
+
+// QueryRelationships reads relationships starting from the resource side.
+func (r *boltReader) QueryRelationships(
+	_ context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	queryOpts := options.NewQueryOptionsWithOptions(opts...)
+
+	if queryOpts.After != nil && queryOpts.Sort == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
+
+	matchingRelationshipsFilterFunc := filterFuncForFilters(
+		filter.ResourceType,
+		filter.OptionalResourceIds,
+		filter.OptionalResourceRelation,
+		filter.OptionalSubjectsSelectors,
+		filter.OptionalCaveatName,
+		queryOpts.Usersets,
+	)
+
+	return newRelationshipIterator(
+		r.view,
+		scansForFilter(filter, queryOpts.After),
+		matchingRelationshipsFilterFunc,
+		queryOpts.Limit,
+		queryOpts.Sort,
+	), nil
+}
+
+// ReverseQueryRelationships reads relationships starting from the subject.
+func (r *boltReader) ReverseQueryRelationships(
+	_ context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+
+	if queryOpts.AfterForReverse != nil && queryOpts.SortForReverse == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
+
+	filterObjectType, filterRelation := "", ""
+	if queryOpts.ResRelation != nil {
+		filterObjectType = queryOpts.ResRelation.Namespace
+		filterRelation = queryOpts.ResRelation.Relation
+	}
+
+	matchingRelationshipsFilterFunc := filterFuncForFilters(
+		filterObjectType,
+		nil,
+		filterRelation,
+		[]datastore.SubjectsSelector{subjectsFilter.AsSelector()},
+		"",
+		nil,
+	)
+
+	return newRelationshipIterator(
+		r.view,
+		scansForReverseQuery(subjectsFilter, queryOpts),
+		matchingRelationshipsFilterFunc,
+		queryOpts.ReverseLimit,
+		queryOpts.SortForReverse,
+	), nil
+}
+
+// ReadNamespaceByName reads a namespace definition and the revision at which it was created
+// or last written, if found.
+func (r *boltReader) ReadNamespaceByName(_ context.Context, nsName string) (ns *core.NamespaceDefinition, lastWritten datastore.Revision, err error) {
+	if r.initErr != nil {
+		return nil, datastore.NoRevision, r.initErr
+	}
+
+	found, err := readDefinitions(r.view, migrations.BucketNamespaces, []string{nsName}, newNamespace)
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+
+	if len(found) == 0 {
+		return nil, datastore.NoRevision, datastore.NewNamespaceNotFoundErr(nsName)
+	}
+
+	return found[0].Definition, found[0].LastWrittenRevision, nil
+}
+
+// ListAllNamespaces lists all namespaces defined.
+func (r *boltReader) ListAllNamespaces(_ context.Context) ([]datastore.RevisionedNamespace, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	return readDefinitions(r.view, migrations.BucketNamespaces, nil, newNamespace)
+}
+
+func (r *boltReader) LookupNamespacesWithNames(_ context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
+	if r.initErr != nil {
+		return nil, r.initErr
+	}
+
+	if len(nsNames) == 0 {
+		return nil, nil
+	}
+
+	return readDefinitions(r.view, migrations.BucketNamespaces, nsNames, newNamespace)
+}
+
+func newNamespace() *core.NamespaceDefinition {
+	return &core.NamespaceDefinition{}
+}
+
+type deserializable interface {
+	datastore.SchemaDefinition
+	UnmarshalVT([]byte) error
+}
+
+// readDefinitions reads the definitions with the given names, or every definition if no names
+// are given, along with the revisions at which they were last written.
+func readDefinitions[T deserializable](
+	view viewFunc,
+	bucketName []byte,
+	names []string,
+	newDefinition func() T,
+) ([]datastore.RevisionedDefinition[T], error) {
+	var found []datastore.RevisionedDefinition[T]
+	err := view(func(tx *bbolt.Tx, rev uint64) error {
+		definitions, err := bucket(tx, bucketName)
+		if err != nil {
+			return err
+		}
+
+		var prefixes [][]byte
+		for _, name := range stringz.Dedup(names) {
+			prefixes = append(prefixes, definitionKey(name))
+		}
+		if len(names) == 0 {
+			prefixes = [][]byte{nil}
+		}
+
+		cursor := definitions.Cursor()
+		for _, prefix := range prefixes {
+			for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+				if !visibleAt(k, v, rev) {
+					continue
+				}
+
+				loaded := newDefinition()
+				if err := loaded.UnmarshalVT(objectFromValue(v)); err != nil {
+					return err
+				}
+
+				found = append(found, datastore.RevisionedDefinition[T]{
+					Definition:          loaded,
+					LastWrittenRevision: revisionFromNanos(createdFromKey(k)),
+				})
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// scan is a range of keys in the relationships bucket, or in the index of relationships by
+// subject, from which an iterator reads.
+type scan struct {
+	bySubject bool
+	prefix    []byte
+
+	// from is the key from which the scan continues.
+	from []byte
+
+	// after, if set, is the key of the relationship after which sorted results begin.
+	after []byte
+}
+
+func newScan(bySubject bool, prefix []byte, after *core.RelationTuple) scan {
+	s := scan{bySubject: bySubject, prefix: prefix, from: prefix}
+	if after != nil {
+		s.after = relationshipKey(after)
+		if bytes.Compare(s.after, s.from) > 0 {
+			s.from = s.after
+		}
+	}
+	return s
+}
+
+// scansForFilter returns the ranges of the relationships bucket which hold the relationships
+// matching the filter, in order of their keys.
+func scansForFilter(filter datastore.RelationshipsFilter, after *core.RelationTuple) []scan {
+	// The resource ID precedes the relation in keys, so without IDs the relation is filtered.
+	if len(filter.OptionalResourceIds) == 0 {
+		return []scan{newScan(false, appendKeyComponents(nil, filter.ResourceType), after)}
+	}
+
+	resourceIDs := stringz.Dedup(filter.OptionalResourceIds)
+	sort.Strings(resourceIDs)
+
+	scans := make([]scan, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		prefix := appendKeyComponents(nil, filter.ResourceType, resourceID)
+		if filter.OptionalResourceRelation != "" {
+			prefix = appendKeyComponents(prefix, filter.OptionalResourceRelation)
+		}
+		scans = append(scans, newScan(false, prefix, after))
+	}
+	return scans
+}
+
+// scansForReverseQuery returns the ranges from which to read a reverse query. When sorting is
+// requested, the relationships bucket is read in order of resource, rather than the index of
+// relationships by subject.
+func scansForReverseQuery(subjectsFilter datastore.SubjectsFilter, queryOpts *options.ReverseQueryOptions) []scan {
+	if queryOpts.SortForReverse == options.Unsorted {
+		if len(subjectsFilter.OptionalSubjectIds) == 0 {
+			return []scan{newScan(true, appendKeyComponents(nil, subjectsFilter.SubjectType), nil)}
+		}
+
+		subjectIDs := stringz.Dedup(subjectsFilter.OptionalSubjectIds)
+		scans := make([]scan, 0, len(subjectIDs))
+		for _, subjectID := range subjectIDs {
+			scans = append(scans, newScan(true, appendKeyComponents(nil, subjectsFilter.SubjectType, subjectID), nil))
+		}
+		return scans
+	}
+
+	if queryOpts.ResRelation != nil {
+		return []scan{newScan(false, appendKeyComponents(nil, queryOpts.ResRelation.Namespace), queryOpts.AfterForReverse)}
+	}
+
+	return []scan{newScan(false, nil, queryOpts.AfterForReverse)}
+}
+
+// filterFuncForFilters returns a function which returns true for the relationships which do
+// not match the filters.
+func filterFuncForFilters(
+	optionalResourceType string,
+	optionalResourceIds []string,
+	optionalRelation string,
+	optionalSubjectsSelectors []datastore.SubjectsSelector,
+	optionalCaveatFilter string,
+	usersets []*core.ObjectAndRelation,
+) func(*core.RelationTuple) bool {
+	now := time.Now()
+	return func(rt *core.RelationTuple) bool {
+		resource, subject := rt.ResourceAndRelation, rt.Subject
+
+		switch {
+		case isExpired(rt, now):
+			return true
+		case optionalResourceType != "" && optionalResourceType != resource.Namespace:
+			return true
+		case len(optionalResourceIds) > 0 && !stringz.SliceContains(optionalResourceIds, resource.ObjectId):
+			return true
+		case optionalRelation != "" && optionalRelation != resource.Relation:
+			return true
+		case optionalCaveatFilter != "" && (rt.Caveat == nil || rt.Caveat.CaveatName != optionalCaveatFilter):
+			return true
+		}
+
+		applySubjectSelector := func(selector datastore.SubjectsSelector) bool {
+			switch {
+			case len(selector.OptionalSubjectType) > 0 && selector.OptionalSubjectType != subject.Namespace:
+				return false
+			case len(selector.OptionalSubjectIds) > 0 && !stringz.SliceContains(selector.OptionalSubjectIds, subject.ObjectId):
+				return false
+			}
+
+			if selector.RelationFilter.OnlyNonEllipsisRelations {
+				return subject.Relation != datastore.Ellipsis
+			}
+
+			relations := make([]string, 0, 2)
+			if selector.RelationFilter.IncludeEllipsisRelation {
+				relations = append(relations, datastore.Ellipsis)
+			}
+
+			if selector.RelationFilter.NonEllipsisRelation != "" {
+				relations = append(relations, selector.RelationFilter.NonEllipsisRelation)
+			}
+
+			return len(relations) == 0 || stringz.SliceContains(relations, subject.Relation)
+		}
+
+		if len(optionalSubjectsSelectors) > 0 {
+			hasMatchingSelector := false
+			for _, selector := range optionalSubjectsSelectors {
+				if applySubjectSelector(selector) {
+					hasMatchingSelector = true
+					break
+				}
+			}
+
+			if !hasMatchingSelector {
+				return true
+			}
+		}
+
+		if len(usersets) > 0 {
+			found := false
+			for _, filter := range usersets {
+				if filter.Namespace == subject.Namespace &&
+					filter.ObjectId == subject.ObjectId &&
+					filter.Relation == subject.Relation {
+					found = true
+					break
+				}
+			}
+			return !found
+		}
+
+		return false
+	}
+}
+
+func isExpired(rt *core.RelationTuple, now time.Time) bool {
+	expiration := common.ExpirationTimeOf(rt)
+	return expiration != nil && !expiration.After(now)
+}
+
+func newRelationshipIterator(
+	view viewFunc,
+	scans []scan,
+	filter func(*core.RelationTuple) bool,
+	limit *uint64,
+	order options.SortOrder,
+) *boltTupleIterator {
+	return &boltTupleIterator{view: view, scans: scans, filter: filter, limit: limit, order: order}
+}
+
+// boltTupleIterator reads the relationships from each scan in turn, in chunks.
+type boltTupleIterator struct {
+	view   viewFunc
+	scans  []scan
+	filter func(*core.RelationTuple) bool
+
+	buffered []*core.RelationTuple
+	closed   bool
+	limit    *uint64
+	count    uint64
+	err      error
+	order    options.SortOrder
+	last     *core.RelationTuple
+}
+
+func (bti *boltTupleIterator) Next() *core.RelationTuple {
+	if bti.closed || bti.err != nil {
+		return nil
+	}
+
+	if bti.limit != nil && bti.count >= *bti.limit {
+		return nil
+	}
+
+	if len(bti.buffered) == 0 && len(bti.scans) > 0 {
+		if err := bti.view(bti.readChunk); err != nil {
+			bti.err = err
+			return nil
+		}
+	}
+
+	if len(bti.buffered) == 0 {
+		return nil
+	}
+
+	rt := bti.buffered[0]
+	bti.buffered = bti.buffered[1:]
+	bti.count++
+
+	bti.last = rt
+	return rt
+}
+
+// readChunk reads the next chunk of matching relationships visible at the revision.
+func (bti *boltTupleIterator) readChunk(tx *bbolt.Tx, rev uint64) error {
+	relationships, err := bucket(tx, migrations.BucketRelationships)
+	if err != nil {
+		return err
+	}
+
+	bySubject, err := bucket(tx, migrations.BucketRelationshipsBySubject)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := uint64(iteratorChunkSize)
+	if bti.limit != nil && *bti.limit-bti.count < chunkSize {
+		chunkSize = *bti.limit - bti.count
+	}
+
+	for len(bti.scans) > 0 {
+		current := &bti.scans[0]
+
+		cursor := relationships.Cursor()
+		if current.bySubject {
+			cursor = bySubject.Cursor()
+		}
+
+		for k, v := cursor.Seek(current.from); k != nil && bytes.HasPrefix(k, current.prefix); k, v = cursor.Next() {
+			if uint64(len(bti.buffered)) >= chunkSize {
+				// The scan continues from this key in the next chunk.
+				current.from = bytes.Clone(k)
+				return nil
+			}
+
+			if current.after != nil && bytes.Compare(k[:len(k)-revisionSize], current.after) <= 0 {
+				continue
+			}
+
+			if current.bySubject {
+				k, v = v, relationships.Get(v)
+				if v == nil {
+					continue
+				}
+			}
+
+			if !visibleAt(k, v, rev) {
+				continue
+			}
+
+			rt, err := decodeRelationship(v)
+			if err != nil {
+				return err
+			}
+
+			if bti.filter(rt) {
+				continue
+			}
+			bti.buffered = append(bti.buffered, rt)
+		}
+
+		bti.scans = bti.scans[1:]
+	}
+
+	return nil
+}
+
+func (bti *boltTupleIterator) Cursor() (options.Cursor, error) {
+	switch {
+	case bti.closed:
+		return nil, datastore.ErrClosedIterator
+	case bti.order == options.Unsorted:
+		return nil, datastore.ErrCursorsWithoutSorting
+	case bti.last == nil:
+		return nil, datastore.ErrCursorEmpty
+	default:
+		return bti.last, nil
+	}
+}
+
+func (bti *boltTupleIterator) Err() error {
+	return bti.err
+}
+
+func (bti *boltTupleIterator) Close() {
+	bti.closed = true
+	bti.err = datastore.ErrClosedIterator
+}
+
+var _ datastore.Reader = &boltReader{}
@@ -0,0 +1,556 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/stringz"
+	"go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	numRetries = 10
+
+	// writeWaitTimeout is how long a transaction waits to begin writing while another
+	// transaction is writing, before it fails with a serialization error and is retried.
+	writeWaitTimeout = 100 * time.Millisecond
+)
+
+var errSerialization = errors.New("serialization error")
+
+func (bds *boltDatastore) SnapshotReader(revisionRaw datastore.Revision) datastore.Reader {
+	dr, ok := revisionRaw.(revision.Decimal)
+	if !ok {
+		return &boltReader{nil, datastore.NewInvalidRevisionErr(revisionRaw, datastore.CouldNotDetermineRevision)}
+	}
+
+	rev, err := bds.checkRevision(dr)
+	if err != nil {
+		return &boltReader{nil, err}
+	}
+
+	return &boltReader{func(f func(tx *bbolt.Tx, rev uint64) error) error {
+		return bds.db.View(func(tx *bbolt.Tx) error {
+			return f(tx, rev)
+		})
+	}, nil}
+}
+
+func (bds *boltDatastore) ReadWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
+) (datastore.Revision, error) {
+	for i := 0; i < numRetries; i++ {
+		rwt := &boltReadWriteTx{
+			ds:      bds,
+			ctx:     ctx,
+			changes: common.NewChanges(revision.DecimalKeyFunc),
+		}
+		rwt.view = rwt.withTx
+
+		if err := f(rwt); err != nil {
+			rwt.rollback()
+
+			// If the error was a serialization error, retry the transaction
+			if errors.Is(err, errSerialization) {
+				continue
+			}
+
+			// We *must* return the inner error unmodified in case it's not an error type
+			// that supports unwrapping (e.g. gRPC errors)
+			return datastore.NoRevision, err
+		}
+
+		newRevision, err := rwt.commit()
+		if errors.Is(err, errSerialization) {
+			continue
+		}
+		if err != nil {
+			return datastore.NoRevision, err
+		}
+		return newRevision, nil
+	}
+
+	return datastore.NoRevision, errors.New("serialization max retries exceeded")
+}
+
+type boltReadWriteTx struct {
+	boltReader
+	ds  *boltDatastore
+	ctx context.Context
+
+	// The bolt transaction is begun on first use, and cannot be used concurrently.
+	sync.Mutex
+	tx          *bbolt.Tx
+	newRevision uint64
+	changes     common.Changes[revision.Decimal, int64]
+}
+
+// withTx runs f in the bolt transaction, beginning it if it has not yet been begun.
+func (rwt *boltReadWriteTx) withTx(f func(tx *bbolt.Tx, rev uint64) error) error {
+	rwt.Lock()
+	defer rwt.Unlock()
+
+	tx, err := rwt.txSourceCallerMustLock()
+	if err != nil {
+		return err
+	}
+	return f(tx, rwt.newRevision)
+}
+
+func (rwt *boltReadWriteTx) txSourceCallerMustLock() (*bbolt.Tx, error) {
+	if rwt.tx != nil {
+		return rwt.tx, nil
+	}
+
+	wait := time.NewTimer(writeWaitTimeout)
+	defer wait.Stop()
+
+	select {
+	case rwt.ds.writeSem <- struct{}{}:
+	case <-wait.C:
+		return nil, errSerialization
+	case <-rwt.ctx.Done():
+		return nil, rwt.ctx.Err()
+	}
+
+	tx, err := rwt.ds.db.Begin(true)
+	if err != nil {
+		<-rwt.ds.writeSem
+		return nil, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+
+	metadata, err := bucket(tx, migrations.BucketMetadata)
+	if err != nil {
+		_ = tx.Rollback()
+		<-rwt.ds.writeSem
+		return nil, err
+	}
+
+	rwt.tx = tx
+	rwt.newRevision = newRevision(binary.BigEndian.Uint64(metadata.Get(migrations.KeyHeadRevision)))
+	return tx, nil
+}
+
+// commit records the changes made by the transaction in the changelog, and commits it at the
+// new revision. A transaction which made no changes is still committed, so that its revision
+// is later than those of all earlier transactions.
+func (rwt *boltReadWriteTx) commit() (datastore.Revision, error) {
+	rwt.Lock()
+	defer rwt.Unlock()
+
+	tx, err := rwt.txSourceCallerMustLock()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	defer func() { <-rwt.ds.writeSem }()
+
+	newRevision := revisionFromNanos(rwt.newRevision)
+	changes := datastore.RevisionChanges{Revision: newRevision}
+	if recorded := rwt.changes.AsRevisionChanges(revision.DecimalKeyLessThanFunc); len(recorded) > 0 {
+		changes = recorded[0]
+	}
+
+	serialized, err := proto.Marshal(changesToProto(&changes))
+	if err != nil {
+		_ = tx.Rollback()
+		return datastore.NoRevision, fmt.Errorf("unable to serialize changes: %w", err)
+	}
+
+	headKey := revisionKey(rwt.newRevision)
+	if err := tx.Bucket(migrations.BucketChangelog).Put(headKey, serialized); err != nil {
+		_ = tx.Rollback()
+		return datastore.NoRevision, fmt.Errorf("error writing changelog: %w", err)
+	}
+
+	if err := tx.Bucket(migrations.BucketMetadata).Put(migrations.KeyHeadRevision, headKey); err != nil {
+		_ = tx.Rollback()
+		return datastore.NoRevision, fmt.Errorf("error writing head revision: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return datastore.NoRevision, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	rwt.ds.Lock()
+	rwt.ds.headRevision = rwt.newRevision
+	rwt.ds.Unlock()
+
+	return newRevision, nil
+}
+
+func (rwt *boltReadWriteTx) rollback() {
+	rwt.Lock()
+	defer rwt.Unlock()
+
+	if rwt.tx != nil {
+		_ = rwt.tx.Rollback()
+		rwt.tx = nil
+		<-rwt.ds.writeSem
+	}
+}
+
+func (rwt *boltReadWriteTx) WriteRelationships(_ context.Context, mutations []*core.RelationTupleUpdate) error {
+	return rwt.withTx(func(tx *bbolt.Tx, rev uint64) error {
+		return rwt.write(tx, rev, mutations...)
+	})
+}
+
+// Caller must already hold the concurrent access lock!
+func (rwt *boltReadWriteTx) write(tx *bbolt.Tx, rev uint64, mutations ...*core.RelationTupleUpdate) error {
+	relationships := tx.Bucket(migrations.BucketRelationships)
+	now := time.Now()
+
+	for _, mutation := range mutations {
+		key := relationshipKey(mutation.Tuple)
+		existingKey, existingValue := liveVersion(relationships, key)
+
+		var existing *core.RelationTuple
+		if existingKey != nil {
+			var err error
+			existing, err = decodeRelationship(existingValue)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch mutation.Operation {
+		case core.RelationTupleUpdate_CREATE:
+			// An expired relationship which has not yet been reaped is replaced.
+			if existing != nil && !isExpired(existing, now) {
+				return common.NewCreateRelationshipExistsError(existing)
+			}
+			fallthrough
+		case core.RelationTupleUpdate_TOUCH:
+			if existing != nil {
+				if err := removeRelationshipVersion(tx, existingKey, existingValue, existing, rev); err != nil {
+					return err
+				}
+			} else if err := adjustRelationshipCount(tx, mutation.Tuple, 1); err != nil {
+				return err
+			}
+
+			// Bulk load sources may reuse the tuple for the next relationship.
+			rt := mutation.Tuple.CloneVT()
+			if rt.Caveat != nil && rt.Caveat.Context == nil {
+				rt.Caveat.Context = &structpb.Struct{}
+			}
+
+			if err := insertRelationshipVersion(tx, key, rt, rev); err != nil {
+				return err
+			}
+
+			rwt.changes.AddChange(rwt.ctx, revisionFromNanos(rev), rt, core.RelationTupleUpdate_TOUCH)
+		case core.RelationTupleUpdate_DELETE:
+			if existing != nil {
+				if err := removeRelationshipVersion(tx, existingKey, existingValue, existing, rev); err != nil {
+					return err
+				}
+				if err := adjustRelationshipCount(tx, existing, -1); err != nil {
+					return err
+				}
+				rwt.changes.AddChange(rwt.ctx, revisionFromNanos(rev), existing, core.RelationTupleUpdate_DELETE)
+			}
+		default:
+			return fmt.Errorf("unknown tuple mutation operation type: %s", mutation.Operation)
+		}
+	}
+
+	return nil
+}
+
+// liveVersion returns the key and value of the version of the object with the given key prefix
+// which has not been deleted, if any.
+func liveVersion(versions *bbolt.Bucket, prefix []byte) ([]byte, []byte) {
+	cursor := versions.Cursor()
+
+	// The latest version is the last with the prefix.
+	k, v := cursor.Seek(versionKey(prefix, liveRevision))
+	if k == nil {
+		k, v = cursor.Last()
+	} else {
+		k, v = cursor.Prev()
+	}
+
+	if k == nil || !bytes.HasPrefix(k, prefix) || len(k) != len(prefix)+revisionSize || deletedFromValue(v) != liveRevision {
+		return nil, nil
+	}
+	return k, v
+}
+
+// insertRelationshipVersion writes a version of the relationship created at the revision.
+func insertRelationshipVersion(tx *bbolt.Tx, key []byte, rt *core.RelationTuple, rev uint64) error {
+	value, err := encodeVersion(liveRevision, rt)
+	if err != nil {
+		return err
+	}
+
+	primaryKey := versionKey(key, rev)
+	if err := tx.Bucket(migrations.BucketRelationships).Put(primaryKey, value); err != nil {
+		return fmt.Errorf("error inserting relationship: %w", err)
+	}
+
+	if err := tx.Bucket(migrations.BucketRelationshipsBySubject).Put(versionKey(subjectIndexKey(rt), rev), primaryKey); err != nil {
+		return fmt.Errorf("error indexing relationship: %w", err)
+	}
+	return nil
+}
+
+// removeRelationshipVersion deletes the live version of a relationship at the revision. A
+// version which was created at the same revision was never visible, and is removed outright.
+func removeRelationshipVersion(tx *bbolt.Tx, key, value []byte, rt *core.RelationTuple, rev uint64) error {
+	relationships := tx.Bucket(migrations.BucketRelationships)
+
+	if createdFromKey(key) == rev {
+		if err := relationships.Delete(key); err != nil {
+			return fmt.Errorf("error deleting relationship: %w", err)
+		}
+		if err := tx.Bucket(migrations.BucketRelationshipsBySubject).Delete(versionKey(subjectIndexKey(rt), rev)); err != nil {
+			return fmt.Errorf("error deleting relationship index: %w", err)
+		}
+		return nil
+	}
+
+	if err := relationships.Put(key, markDeleted(value, rev)); err != nil {
+		return fmt.Errorf("error deleting relationship: %w", err)
+	}
+	if err := tx.Bucket(migrations.BucketRelationshipDeletions).Put(deletionKey(rev, key), nil); err != nil {
+		return fmt.Errorf("error recording relationship deletion: %w", err)
+	}
+	return nil
+}
+
+// adjustRelationshipCount adds the delta to the number of relationships stored for the
+// relation of the relationship.
+func adjustRelationshipCount(tx *bbolt.Tx, rt *core.RelationTuple, delta int64) error {
+	counts := tx.Bucket(migrations.BucketRelationshipCounts)
+	key := relationshipCountKey(rt.ResourceAndRelation.Namespace, rt.ResourceAndRelation.Relation)
+
+	var count uint64
+	if found := counts.Get(key); found != nil {
+		count = binary.BigEndian.Uint64(found)
+	}
+
+	updated := uint64(int64(count) + delta)
+	if updated == 0 {
+		if err := counts.Delete(key); err != nil {
+			return fmt.Errorf("error deleting relationship count: %w", err)
+		}
+		return nil
+	}
+
+	if err := counts.Put(key, binary.BigEndian.AppendUint64(nil, updated)); err != nil {
+		return fmt.Errorf("error updating relationship count: %w", err)
+	}
+	return nil
+}
+
+// RelationshipCounts returns the counts of the given definitions. The counts of all definitions
+// are maintained by the bolt datastore, since each is a single key updated in the transaction
+// which writes the relationships.
+func (rwt *boltReadWriteTx) RelationshipCounts(_ context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	counts := make(map[datastore.RelationKey]uint64)
+	err := rwt.withTx(func(tx *bbolt.Tx, _ uint64) error {
+		cursor := tx.Bucket(migrations.BucketRelationshipCounts).Cursor()
+		for _, definition := range stringz.Dedup(definitions) {
+			prefix := definitionKey(definition)
+			for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+				namespace, relation := relationFromCountKey(k)
+				counts[datastore.RelationKey{Definition: namespace, Relation: relation}] = binary.BigEndian.Uint64(v)
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+func (rwt *boltReadWriteTx) DeleteRelationships(_ context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
+	delOpts := options.NewDeleteOptionsWithOptions(opts...)
+
+	var limitReached bool
+	err := rwt.withTx(func(tx *bbolt.Tx, rev uint64) error {
+		var err error
+		limitReached, err = rwt.deleteWithLock(tx, rev, filter, delOpts.DeleteLimit)
+		return err
+	})
+	return limitReached, err
+}
+
+// caller must already hold the concurrent access lock
+func (rwt *boltReadWriteTx) deleteWithLock(tx *bbolt.Tx, rev uint64, filter *v1.RelationshipFilter, limit *uint64) (bool, error) {
+	// Expired relationships are deleted along with the others, so they are not matched by the
+	// filters of queries.
+	iter := newRelationshipIterator(
+		func(f func(tx *bbolt.Tx, rev uint64) error) error { return f(tx, rev) },
+		scansForFilter(datastore.RelationshipsFilterFromPublicFilter(filter), nil),
+		relationshipFilterFilterFunc(filter),
+		nil,
+		options.Unsorted,
+	)
+	defer iter.Close()
+
+	// Collect the tuples into a slice of mutations, as the relationships cannot be modified
+	// while they are being read.
+	var mutations []*core.RelationTupleUpdate
+	limitReached := false
+	for rt := iter.Next(); rt != nil; rt = iter.Next() {
+		mutations = append(mutations, tuple.Delete(rt))
+
+		if limit != nil && uint64(len(mutations)) >= *limit {
+			limitReached = true
+			break
+		}
+	}
+	if iter.Err() != nil {
+		return false, iter.Err()
+	}
+
+	return limitReached, rwt.write(tx, rev, mutations...)
+}
+
+func (rwt *boltReadWriteTx) WriteNamespaces(_ context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	return rwt.withTx(func(tx *bbolt.Tx, rev uint64) error {
+		namespaces := tx.Bucket(migrations.BucketNamespaces)
+		for _, newConfig := range newConfigs {
+			if err := writeDefinition(namespaces, newConfig.Name, newConfig, rev); err != nil {
+				return err
+			}
+			rwt.changes.AddChangedDefinition(rwt.ctx, revisionFromNanos(rev), newConfig.CloneVT())
+		}
+		return nil
+	})
+}
+
+func (rwt *boltReadWriteTx) DeleteNamespaces(_ context.Context, nsNames ...string) error {
+	return rwt.withTx(func(tx *bbolt.Tx, rev uint64) error {
+		namespaces := tx.Bucket(migrations.BucketNamespaces)
+		for _, nsName := range nsNames {
+			found, err := deleteDefinition(namespaces, nsName, rev)
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return datastore.NewNamespaceNotFoundErr(nsName)
+			}
+			rwt.changes.AddDeletedNamespace(rwt.ctx, revisionFromNanos(rev), nsName)
+
+			// Delete the relationships from the namespace
+			if _, err := rwt.deleteWithLock(tx, rev, &v1.RelationshipFilter{
+				ResourceType: nsName,
+			}, nil); err != nil {
+				return fmt.Errorf("unable to delete relationships from deleted namespace: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// writeDefinition writes a version of the definition created at the revision, replacing the
+// live version.
+func writeDefinition(definitions *bbolt.Bucket, name string, definition proto.Message, rev uint64) error {
+	if _, err := deleteDefinition(definitions, name, rev); err != nil {
+		return err
+	}
+
+	value, err := encodeVersion(liveRevision, definition)
+	if err != nil {
+		return err
+	}
+
+	if err := definitions.Put(versionKey(definitionKey(name), rev), value); err != nil {
+		return fmt.Errorf("error writing definition %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteDefinition deletes the live version of the definition at the revision, returning
+// false if there is none.
+func deleteDefinition(definitions *bbolt.Bucket, name string, rev uint64) (bool, error) {
+	key, value := liveVersion(definitions, definitionKey(name))
+	if key == nil {
+		return false, nil
+	}
+
+	var err error
+	if createdFromKey(key) == rev {
+		err = definitions.Delete(key)
+	} else {
+		err = definitions.Put(key, markDeleted(value, rev))
+	}
+	if err != nil {
+		return false, fmt.Errorf("error deleting definition %s: %w", name, err)
+	}
+	return true, nil
+}
+
+func (rwt *boltReadWriteTx) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var numCopied uint64
+	var next *core.RelationTuple
+	var err error
+
+	updates := []*core.RelationTupleUpdate{{
+		Operation: core.RelationTupleUpdate_CREATE,
+	}}
+
+	for next, err = iter.Next(ctx); next != nil && err == nil; next, err = iter.Next(ctx) {
+		updates[0].Tuple = next
+		if err := rwt.WriteRelationships(ctx, updates); err != nil {
+			return 0, err
+		}
+		numCopied++
+	}
+
+	return numCopied, err
+}
+
+// relationshipFilterFilterFunc returns a function which returns true for the relationships
+// which do not match the filter.
+func relationshipFilterFilterFunc(filter *v1.RelationshipFilter) func(*core.RelationTuple) bool {
+	return func(rt *core.RelationTuple) bool {
+		resource, subject := rt.ResourceAndRelation, rt.Subject
+
+		// If it doesn't match one of the resource filters, filter it.
+		switch {
+		case filter.ResourceType != resource.Namespace:
+			return true
+		case filter.OptionalResourceId != "" && filter.OptionalResourceId != resource.ObjectId:
+			return true
+		case filter.OptionalRelation != "" && filter.OptionalRelation != resource.Relation:
+			return true
+		}
+
+		// If it doesn't match one of the subject filters, filter it.
+		if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
+			switch {
+			case subjectFilter.SubjectType != subject.Namespace:
+				return true
+			case subjectFilter.OptionalSubjectId != "" && subjectFilter.OptionalSubjectId != subject.ObjectId:
+				return true
+			case subjectFilter.OptionalRelation != nil &&
+				stringz.DefaultEmpty(subjectFilter.OptionalRelation.Relation, datastore.Ellipsis) != subject.Relation:
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+var _ datastore.ReadWriteTransaction = &boltReadWriteTx{}
@@ -0,0 +1,75 @@
+package bolt
+
+import (
+	"context"
+	"time"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+)
+
+func (bds *boltDatastore) HeadRevision(_ context.Context) (datastore.Revision, error) {
+	bds.RLock()
+	defer bds.RUnlock()
+
+	return revisionFromNanos(bds.headRevision), nil
+}
+
+func (bds *boltDatastore) OptimizedRevision(_ context.Context) (datastore.Revision, error) {
+	now := time.Now().UTC().UnixNano()
+	return revisionFromNanos(uint64(now - now%bds.quantizationPeriod)), nil
+}
+
+// newRevision returns the revision of a transaction beginning after the given head revision.
+// Revisions are timestamps, but must always move forward even if the clock does not.
+func newRevision(head uint64) uint64 {
+	now := uint64(time.Now().UTC().UnixNano())
+	if now <= head {
+		return head + 1
+	}
+	return now
+}
+
+func (bds *boltDatastore) CheckRevision(_ context.Context, revisionRaw datastore.Revision) error {
+	dr, ok := revisionRaw.(revision.Decimal)
+	if !ok {
+		return datastore.NewInvalidRevisionErr(revisionRaw, datastore.CouldNotDetermineRevision)
+	}
+
+	_, err := bds.checkRevision(dr)
+	return err
+}
+
+// checkRevision checks that the revision can be read, and returns the revision at which it is
+// read from the file. Revisions after the head revision read the state as of the head revision.
+func (bds *boltDatastore) checkRevision(dr revision.Decimal) (uint64, error) {
+	bds.RLock()
+	defer bds.RUnlock()
+
+	rev := uint64(dr.IntPart())
+	now := uint64(time.Now().UTC().UnixNano())
+
+	// The head revision is readable even once it falls outside of the GC window, as no later
+	// revision exists from which to read the same state.
+	if rev == bds.headRevision {
+		return rev, nil
+	}
+
+	// Revisions outside of the GC window are stale. Versions of objects which were deleted at
+	// or before the GC watermark have been removed, so the revisions up to it are stale too.
+	oldest := int64(now) - bds.gcWindow.Nanoseconds()
+	if int64(rev) < oldest || rev <= bds.gcWatermark {
+		return 0, datastore.NewInvalidRevisionErr(dr, datastore.RevisionStale)
+	}
+
+	if rev > bds.headRevision {
+		// No transaction has committed since the head revision, so a later revision reads the
+		// same state, unless it is in the "future" and a transaction may yet commit before it.
+		if rev > now {
+			return 0, datastore.NewInvalidRevisionErr(dr, datastore.CouldNotDetermineRevision)
+		}
+		return bds.headRevision, nil
+	}
+
+	return rev, nil
+}
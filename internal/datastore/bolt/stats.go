@@ -0,0 +1,92 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+func (bds *boltDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
+	head, err := bds.HeadRevision(ctx)
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to compute head revision: %w", err)
+	}
+
+	counts, err := bds.relationshipCounts()
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to count relationships: %w", err)
+	}
+
+	var count uint64
+	for _, relationCount := range counts {
+		count += relationCount
+	}
+
+	objTypes, err := bds.SnapshotReader(head).ListAllNamespaces(ctx)
+	if err != nil {
+		return datastore.Stats{}, fmt.Errorf("unable to list object types: %w", err)
+	}
+
+	return datastore.Stats{
+		UniqueID:                   bds.uniqueID,
+		EstimatedRelationshipCount: count,
+		ObjectTypeStatistics:       datastore.ComputeObjectTypeStats(objTypes),
+	}, nil
+}
+
+// DetailedStatistics reports the relationship counts maintained by the datastore, which include
+// expired relationships that have not yet been deleted, along with the storage used by the
+// relationships and the number of transactions in the changelog.
+func (bds *boltDatastore) DetailedStatistics(_ context.Context) (datastore.DetailedStats, error) {
+	counts, err := bds.relationshipCounts()
+	if err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to count relationships: %w", err)
+	}
+
+	stats := datastore.DetailedStats{Definitions: datastore.ComputeDefinitionStats(counts)}
+	if err := bds.db.View(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{migrations.BucketRelationships, migrations.BucketRelationshipsBySubject} {
+			relationships, err := bucket(tx, name)
+			if err != nil {
+				return err
+			}
+
+			bucketStats := relationships.Stats()
+			stats.StorageSizeBytes += uint64(bucketStats.BranchAlloc + bucketStats.LeafAlloc)
+		}
+
+		changelog, err := bucket(tx, migrations.BucketChangelog)
+		if err != nil {
+			return err
+		}
+		stats.ChangelogBacklog = uint64(changelog.Stats().KeyN)
+		return nil
+	}); err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to read file statistics: %w", err)
+	}
+
+	return stats, nil
+}
+
+// relationshipCounts reads the number of relationships stored for each relation.
+func (bds *boltDatastore) relationshipCounts() (map[datastore.RelationKey]uint64, error) {
+	counts := make(map[datastore.RelationKey]uint64)
+	err := bds.db.View(func(tx *bbolt.Tx) error {
+		relationshipCounts, err := bucket(tx, migrations.BucketRelationshipCounts)
+		if err != nil {
+			return err
+		}
+
+		return relationshipCounts.ForEach(func(k, v []byte) error {
+			namespace, relation := relationFromCountKey(k)
+			counts[datastore.RelationKey{Definition: namespace, Relation: relation}] = binary.BigEndian.Uint64(v)
+			return nil
+		})
+	})
+	return counts, err
+}
@@ -0,0 +1,210 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	implv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+)
+
+// Every version of a relationship, namespace or caveat is stored under the key of the object,
+// followed by the revision at which the version was written, so that the versions of an object
+// are adjacent and ordered by revision. The value of a version is the revision at which it was
+// deleted, or liveRevision if it has not been, followed by the serialized object.
+//
+// The components of keys are separated by a zero byte, which cannot appear in the names of
+// definitions or relations, or in object IDs.
+
+// liveRevision is the deleted revision of versions which have not been deleted.
+const liveRevision = math.MaxUint64
+
+const (
+	keySeparator = 0
+	revisionSize = 8
+)
+
+// appendKeyComponents appends each component to the key, followed by the separator.
+func appendKeyComponents(key []byte, components ...string) []byte {
+	for _, component := range components {
+		key = append(key, component...)
+		key = append(key, keySeparator)
+	}
+	return key
+}
+
+// relationshipKey is the prefix of the keys of the versions of the relationship.
+func relationshipKey(rt *core.RelationTuple) []byte {
+	return appendKeyComponents(nil,
+		rt.ResourceAndRelation.Namespace,
+		rt.ResourceAndRelation.ObjectId,
+		rt.ResourceAndRelation.Relation,
+		rt.Subject.Namespace,
+		rt.Subject.ObjectId,
+		rt.Subject.Relation,
+	)
+}
+
+// subjectIndexKey is the prefix of the keys of the versions of the relationship in the index
+// by subject.
+func subjectIndexKey(rt *core.RelationTuple) []byte {
+	return appendKeyComponents(nil,
+		rt.Subject.Namespace,
+		rt.Subject.ObjectId,
+		rt.Subject.Relation,
+		rt.ResourceAndRelation.Namespace,
+		rt.ResourceAndRelation.ObjectId,
+		rt.ResourceAndRelation.Relation,
+	)
+}
+
+// definitionKey is the prefix of the keys of the versions of the namespace or caveat.
+func definitionKey(name string) []byte {
+	return appendKeyComponents(nil, name)
+}
+
+// relationshipCountKey is the key of the count of relationships of the relation.
+func relationshipCountKey(namespace, relation string) []byte {
+	return appendKeyComponents(nil, namespace, relation)
+}
+
+// relationFromCountKey returns the definition and relation of a key of a relationship count.
+func relationFromCountKey(key []byte) (string, string) {
+	namespace, relation, _ := bytes.Cut(key, []byte{keySeparator})
+	return string(namespace), string(bytes.TrimSuffix(relation, []byte{keySeparator}))
+}
+
+// versionKey returns the key of the version of the object written at the given revision.
+func versionKey(prefix []byte, created uint64) []byte {
+	key := make([]byte, 0, len(prefix)+revisionSize)
+	key = append(key, prefix...)
+	return binary.BigEndian.AppendUint64(key, created)
+}
+
+// createdFromKey returns the revision at which the version with the given key was written.
+func createdFromKey(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key[len(key)-revisionSize:])
+}
+
+// deletionKey returns the key of the deleted version of a relationship in the index of
+// deletions.
+func deletionKey(deleted uint64, key []byte) []byte {
+	deletion := make([]byte, 0, revisionSize+len(key))
+	deletion = binary.BigEndian.AppendUint64(deletion, deleted)
+	return append(deletion, key...)
+}
+
+// encodeVersion returns the value of a version which was deleted at the given revision.
+//
+// Objects are serialized with proto.Marshal, as MarshalVT drops empty messages set in oneofs,
+// such as the nil operation of a userset rewrite.
+func encodeVersion(deleted uint64, object proto.Message) ([]byte, error) {
+	serialized, err := proto.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize %T: %w", object, err)
+	}
+
+	value := make([]byte, 0, revisionSize+len(serialized))
+	value = binary.BigEndian.AppendUint64(value, deleted)
+	return append(value, serialized...), nil
+}
+
+// markDeleted returns a copy of the value of a version, deleted at the given revision.
+func markDeleted(value []byte, deleted uint64) []byte {
+	updated := bytes.Clone(value)
+	binary.BigEndian.PutUint64(updated, deleted)
+	return updated
+}
+
+// deletedFromValue returns the revision at which the version was deleted.
+func deletedFromValue(value []byte) uint64 {
+	return binary.BigEndian.Uint64(value)
+}
+
+// objectFromValue returns the serialized object of a version.
+func objectFromValue(value []byte) []byte {
+	return value[revisionSize:]
+}
+
+// visibleAt returns true if the version with the given key and value can be read at the revision.
+func visibleAt(key, value []byte, rev uint64) bool {
+	return createdFromKey(key) <= rev && rev < deletedFromValue(value)
+}
+
+func decodeRelationship(value []byte) (*core.RelationTuple, error) {
+	rt := &core.RelationTuple{}
+	if err := rt.UnmarshalVT(objectFromValue(value)); err != nil {
+		return nil, fmt.Errorf("unable to deserialize relationship: %w", err)
+	}
+	return rt, nil
+}
+
+func changesToProto(changes *datastore.RevisionChanges) *implv1.RevisionChanges {
+	serialized := &implv1.RevisionChanges{
+		RelationshipUpdates: changes.Changes,
+		DeletedNamespaces:   changes.DeletedNamespaces,
+		DeletedCaveats:      changes.DeletedCaveats,
+	}
+
+	for _, def := range changes.ChangedDefinitions {
+		switch typed := def.(type) {
+		case *core.NamespaceDefinition:
+			serialized.ChangedNamespaces = append(serialized.ChangedNamespaces, typed)
+		case *core.CaveatDefinition:
+			serialized.ChangedCaveats = append(serialized.ChangedCaveats, typed)
+		}
+	}
+
+	return serialized
+}
+
+func changesFromProto(key, value []byte) (*datastore.RevisionChanges, error) {
+	serialized := &implv1.RevisionChanges{}
+	if err := serialized.UnmarshalVT(value); err != nil {
+		return nil, fmt.Errorf("unable to deserialize changes: %w", err)
+	}
+
+	changes := &datastore.RevisionChanges{
+		Revision:          revisionFromKey(key),
+		Changes:           serialized.RelationshipUpdates,
+		DeletedNamespaces: serialized.DeletedNamespaces,
+		DeletedCaveats:    serialized.DeletedCaveats,
+	}
+	for _, def := range serialized.ChangedNamespaces {
+		changes.ChangedDefinitions = append(changes.ChangedDefinitions, def)
+	}
+	for _, def := range serialized.ChangedCaveats {
+		changes.ChangedDefinitions = append(changes.ChangedDefinitions, def)
+	}
+
+	return changes, nil
+}
+
+// revisionKey encodes a revision as a key which sorts in revision order.
+func revisionKey(rev uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, rev)
+}
+
+func revisionFromKey(key []byte) revision.Decimal {
+	return revisionFromNanos(binary.BigEndian.Uint64(key))
+}
+
+func revisionFromNanos(nanos uint64) revision.Decimal {
+	return revision.NewFromDecimal(decimal.NewFromInt(int64(nanos)))
+}
+
+// nanosFromRevision returns the revision as a number of nanoseconds.
+func nanosFromRevision(rev datastore.Revision) (uint64, error) {
+	dr, ok := rev.(revision.Decimal)
+	if !ok {
+		return 0, fmt.Errorf("unexpected revision type %T", rev)
+	}
+	return uint64(dr.IntPart()), nil
+}
@@ -0,0 +1,152 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/authzed/spicedb/internal/datastore/bolt/migrations"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+)
+
+const (
+	errWatchError = "watch error: %w"
+
+	// watchSleep is the interval at which the changelog is read for new changes.
+	watchSleep = 100 * time.Millisecond
+)
+
+func (bds *boltDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
+	updates := make(chan *datastore.RevisionChanges, bds.watchBufferLength)
+	errs := make(chan error, 1)
+
+	ar, ok := afterRevision.(revision.Decimal)
+	if !ok {
+		errs <- datastore.NewInvalidRevisionErr(afterRevision, datastore.CouldNotDetermineRevision)
+		return updates, errs
+	}
+
+	go func() {
+		defer close(updates)
+		defer close(errs)
+
+		currentTxn := uint64(ar.IntPart())
+		lastCheckpoint := time.Now()
+
+		for {
+			var stagedUpdates []*datastore.RevisionChanges
+			var err error
+			stagedUpdates, currentTxn, err = bds.loadChanges(currentTxn, options)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			// Write the staged updates to the channel
+			for _, changeToWrite := range stagedUpdates {
+				select {
+				case updates <- changeToWrite:
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
+				}
+			}
+
+			if options.CheckpointInterval > 0 && time.Since(lastCheckpoint) >= options.CheckpointInterval {
+				checkpoint := &datastore.RevisionChanges{
+					Revision:     revisionFromNanos(currentTxn),
+					IsCheckpoint: true,
+				}
+
+				select {
+				case updates <- checkpoint:
+					lastCheckpoint = time.Now()
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
+				}
+			}
+
+			if len(stagedUpdates) > 0 {
+				continue
+			}
+
+			sleep := time.NewTimer(watchSleep)
+			select {
+			case <-sleep.C:
+			case <-ctx.Done():
+				sleep.Stop()
+				if errors.Is(ctx.Err(), context.Canceled) {
+					errs <- datastore.NewWatchCanceledErr()
+				} else {
+					errs <- fmt.Errorf(errWatchError, ctx.Err())
+				}
+				return
+			}
+		}
+	}()
+
+	return updates, errs
+}
+
+// loadChanges reads the changes committed after the current revision from the changelog,
+// returning those requested by the options and the revision of the last change read.
+func (bds *boltDatastore) loadChanges(currentTxn uint64, options datastore.WatchOptions) ([]*datastore.RevisionChanges, uint64, error) {
+	// Changes up to the GC watermark have been removed from the changelog, so a watch which
+	// has not yet seen them can no longer be continued.
+	bds.RLock()
+	gcWatermark := bds.gcWatermark
+	bds.RUnlock()
+
+	if currentTxn < gcWatermark {
+		return nil, 0, datastore.NewInvalidRevisionErr(revisionFromNanos(currentTxn), datastore.RevisionStale)
+	}
+
+	var changes []*datastore.RevisionChanges
+	lastRevision := currentTxn
+	err := bds.db.View(func(tx *bbolt.Tx) error {
+		changelog, err := bucket(tx, migrations.BucketChangelog)
+		if err != nil {
+			return err
+		}
+
+		cursor := changelog.Cursor()
+		k, v := cursor.Seek(revisionKey(currentTxn))
+		if k != nil && bytes.Equal(k, revisionKey(currentTxn)) {
+			k, v = cursor.Next()
+		}
+
+		for ; k != nil; k, v = cursor.Next() {
+			change, err := changesFromProto(k, v)
+			if err != nil {
+				return err
+			}
+			lastRevision = uint64(change.Revision.(revision.Decimal).IntPart())
+
+			// The changelog records all changes, so strip those the watch did not request.
+			if !options.Includes(datastore.WatchRelationships) {
+				change.Changes = nil
+			}
+			if !options.Includes(datastore.WatchSchema) {
+				change.ChangedDefinitions = nil
+				change.DeletedNamespaces = nil
+				change.DeletedCaveats = nil
+			}
+
+			if len(change.Changes) > 0 || change.HasSchemaChanges() {
+				changes = append(changes, change)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf(errWatchError, err)
+	}
+
+	return changes, lastRevision, nil
+}
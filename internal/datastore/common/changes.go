@@ -2,15 +2,18 @@ package common
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
+	"golang.org/x/exp/maps"
+
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
-// Changes represents a set of tuple mutations that are kept self-consistent
+// Changes represents a set of tuple and schema mutations that are kept self-consistent
 // across one or more transaction revisions.
 type Changes[R datastore.Revision, K comparable] struct {
 	records map[K]changeRecord[R]
@@ -18,9 +21,12 @@ type Changes[R datastore.Revision, K comparable] struct {
 }
 
 type changeRecord[R datastore.Revision] struct {
-	rev          R
-	tupleTouches map[string]*core.RelationTuple
-	tupleDeletes map[string]*core.RelationTuple
+	rev                R
+	tupleTouches       map[string]*core.RelationTuple
+	tupleDeletes       map[string]*core.RelationTuple
+	definitionsChanged map[string]datastore.SchemaDefinition
+	namespacesDeleted  map[string]struct{}
+	caveatsDeleted     map[string]struct{}
 }
 
 // NewChanges creates a new Changes object for change tracking and de-duplication.
@@ -38,16 +44,7 @@ func (ch Changes[R, K]) AddChange(
 	tpl *core.RelationTuple,
 	op core.RelationTupleUpdate_Operation,
 ) {
-	k := ch.keyFunc(rev)
-	revisionChanges, ok := ch.records[k]
-	if !ok {
-		revisionChanges = changeRecord[R]{
-			rev,
-			make(map[string]*core.RelationTuple),
-			make(map[string]*core.RelationTuple),
-		}
-		ch.records[k] = revisionChanges
-	}
+	revisionChanges := ch.recordFor(rev)
 
 	tplKey := tuple.StringWithoutCaveat(tpl)
 
@@ -68,6 +65,78 @@ func (ch Changes[R, K]) AddChange(
 	}
 }
 
+// AddChangedDefinition adds a namespace or caveat definition written at the given revision.
+// A definition written and deleted at the same revision is reported as written.
+func (ch Changes[R, K]) AddChangedDefinition(
+	ctx context.Context,
+	rev R,
+	def datastore.SchemaDefinition,
+) {
+	revisionChanges := ch.recordFor(rev)
+
+	switch t := def.(type) {
+	case *core.NamespaceDefinition:
+		delete(revisionChanges.namespacesDeleted, t.Name)
+		revisionChanges.definitionsChanged[namespaceChangeKey(t.Name)] = t
+
+	case *core.CaveatDefinition:
+		delete(revisionChanges.caveatsDeleted, t.Name)
+		revisionChanges.definitionsChanged[caveatChangeKey(t.Name)] = t
+
+	default:
+		log.Ctx(ctx).Fatal().Str("type", fmt.Sprintf("%T", def)).Msg("unknown schema definition type")
+	}
+}
+
+// AddDeletedNamespace adds a namespace deleted at the given revision.
+func (ch Changes[R, K]) AddDeletedNamespace(
+	_ context.Context,
+	rev R,
+	namespaceName string,
+) {
+	revisionChanges := ch.recordFor(rev)
+	if _, alreadyChanged := revisionChanges.definitionsChanged[namespaceChangeKey(namespaceName)]; !alreadyChanged {
+		revisionChanges.namespacesDeleted[namespaceName] = struct{}{}
+	}
+}
+
+// AddDeletedCaveat adds a caveat deleted at the given revision.
+func (ch Changes[R, K]) AddDeletedCaveat(
+	_ context.Context,
+	rev R,
+	caveatName string,
+) {
+	revisionChanges := ch.recordFor(rev)
+	if _, alreadyChanged := revisionChanges.definitionsChanged[caveatChangeKey(caveatName)]; !alreadyChanged {
+		revisionChanges.caveatsDeleted[caveatName] = struct{}{}
+	}
+}
+
+func (ch Changes[R, K]) recordFor(rev R) changeRecord[R] {
+	k := ch.keyFunc(rev)
+	revisionChanges, ok := ch.records[k]
+	if !ok {
+		revisionChanges = changeRecord[R]{
+			rev,
+			make(map[string]*core.RelationTuple),
+			make(map[string]*core.RelationTuple),
+			make(map[string]datastore.SchemaDefinition),
+			make(map[string]struct{}),
+			make(map[string]struct{}),
+		}
+		ch.records[k] = revisionChanges
+	}
+	return revisionChanges
+}
+
+func namespaceChangeKey(name string) string {
+	return "namespace:" + name
+}
+
+func caveatChangeKey(name string) string {
+	return "caveat:" + name
+}
+
 // AsRevisionChanges returns the list of changes processed so far as a datastore watch
 // compatible, ordered, changelist.
 func (ch Changes[R, K]) AsRevisionChanges(lessThanFunc func(lhs, rhs K) bool) []datastore.RevisionChanges {
@@ -96,6 +165,22 @@ func (ch Changes[R, K]) AsRevisionChanges(lessThanFunc func(lhs, rhs K) bool) []
 				Tuple:     tpl,
 			})
 		}
+
+		definitionKeys := maps.Keys(revisionChangeRecord.definitionsChanged)
+		sort.Strings(definitionKeys)
+		for _, key := range definitionKeys {
+			changes[i].ChangedDefinitions = append(changes[i].ChangedDefinitions, revisionChangeRecord.definitionsChanged[key])
+		}
+
+		if len(revisionChangeRecord.namespacesDeleted) > 0 {
+			changes[i].DeletedNamespaces = maps.Keys(revisionChangeRecord.namespacesDeleted)
+			sort.Strings(changes[i].DeletedNamespaces)
+		}
+
+		if len(revisionChangeRecord.caveatsDeleted) > 0 {
+			changes[i].DeletedCaveats = maps.Keys(revisionChangeRecord.caveatsDeleted)
+			sort.Strings(changes[i].DeletedCaveats)
+		}
 	}
 
 	return changes
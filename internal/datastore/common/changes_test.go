@@ -194,6 +194,42 @@ func TestChanges(t *testing.T) {
 	}
 }
 
+func TestSchemaChanges(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	documentDef := &core.NamespaceDefinition{Name: "document"}
+	folderDef := &core.NamespaceDefinition{Name: "folder"}
+	caveatDef := &core.CaveatDefinition{Name: "document"}
+
+	ch := NewChanges(revision.DecimalKeyFunc)
+
+	// A definition rewritten within a transaction is reported as written, in either order.
+	ch.AddDeletedNamespace(ctx, rev1, "document")
+	ch.AddChangedDefinition(ctx, rev1, documentDef)
+	ch.AddChangedDefinition(ctx, rev1, caveatDef)
+	ch.AddDeletedCaveat(ctx, rev1, "document")
+
+	ch.AddDeletedNamespace(ctx, rev2, "document")
+	ch.AddDeletedCaveat(ctx, rev2, "somecaveat")
+	ch.AddChangedDefinition(ctx, rev2, folderDef)
+	ch.AddChange(ctx, rev2, tuple.MustParse(tuple1), core.RelationTupleUpdate_DELETE)
+
+	require.Equal([]datastore.RevisionChanges{
+		{
+			Revision:           rev1,
+			ChangedDefinitions: []datastore.SchemaDefinition{caveatDef, documentDef},
+		},
+		{
+			Revision:           rev2,
+			Changes:            []*core.RelationTupleUpdate{del(tuple1)},
+			ChangedDefinitions: []datastore.SchemaDefinition{folderDef},
+			DeletedNamespaces:  []string{"document"},
+			DeletedCaveats:     []string{"somecaveat"},
+		},
+	}, ch.AsRevisionChanges(revision.DecimalKeyLessThanFunc))
+}
+
 func TestCanonicalize(t *testing.T) {
 	testCases := []struct {
 		name            string
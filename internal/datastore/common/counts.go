@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// RelationshipCountShards is the number of rows across which the count of each relation is
+// spread, so that concurrent transactions writing relationships for the same relation rarely
+// update the same row.
+const RelationshipCountShards = 16
+
+// RandomRelationshipCountShard selects the shard to which a transaction adds its changes to
+// the relationship counts.
+func RandomRelationshipCountShard() int {
+	return rand.Intn(RelationshipCountShards)
+}
+
+// RelationshipCountDeltas accumulates the changes a transaction makes to the number of
+// relationships stored for each relation, to be applied to the counts maintained by the
+// datastore.
+type RelationshipCountDeltas map[datastore.RelationKey]int64
+
+// Add adds the delta to the change in the count of the relation.
+func (d RelationshipCountDeltas) Add(namespace, relation string, delta int64) {
+	key := datastore.RelationKey{Definition: namespace, Relation: relation}
+	d[key] += delta
+	if d[key] == 0 {
+		delete(d, key)
+	}
+}
+
+// Namespaces returns the sorted names of the namespaces whose counts are changed.
+func (d RelationshipCountDeltas) Namespaces() []string {
+	seen := make(map[string]struct{}, len(d))
+	namespaces := make([]string, 0, len(d))
+	for key := range d {
+		if _, ok := seen[key.Definition]; !ok {
+			seen[key.Definition] = struct{}{}
+			namespaces = append(namespaces, key.Definition)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// NewCountingBulkSource wraps a bulk load source, adding each relationship it returns to the
+// deltas.
+func NewCountingBulkSource(source datastore.BulkWriteRelationshipSource, deltas RelationshipCountDeltas) datastore.BulkWriteRelationshipSource {
+	return &countingBulkSource{source, deltas}
+}
+
+type countingBulkSource struct {
+	delegate datastore.BulkWriteRelationshipSource
+	deltas   RelationshipCountDeltas
+}
+
+func (s *countingBulkSource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	tpl, err := s.delegate.Next(ctx)
+	if tpl != nil && err == nil {
+		s.deltas.Add(tpl.ResourceAndRelation.Namespace, tpl.ResourceAndRelation.Relation, 1)
+	}
+	return tpl, err
+}
@@ -36,6 +36,13 @@ var (
 		Help:      "The number of stale transactions deleted by the datastore garbage collection.",
 	})
 
+	gcExpiredRelationshipsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "gc_expired_relationships_total",
+		Help:      "The number of expired relationships deleted by the datastore garbage collection.",
+	})
+
 	gcNamespacesCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "spicedb",
 		Subsystem: "datastore",
@@ -57,6 +64,7 @@ func RegisterGCMetrics() error {
 	for _, metric := range []prometheus.Collector{
 		gcDurationHistogram,
 		gcRelationshipsCounter,
+		gcExpiredRelationshipsCounter,
 		gcTransactionsCounter,
 		gcNamespacesCounter,
 		gcFailureCounter,
@@ -76,6 +84,10 @@ type GarbageCollector interface {
 	Now(context.Context) (time.Time, error)
 	TxIDBefore(context.Context, time.Time) (datastore.Revision, error)
 	DeleteBeforeTx(ctx context.Context, txID datastore.Revision) (DeletionCounts, error)
+
+	// DeleteExpiredRels deletes all relationships which have expired, in a new transaction
+	// so that the deletions are visible to watchers, returning the number deleted.
+	DeleteExpiredRels(ctx context.Context) (int64, error)
 }
 
 // DeletionCounts tracks the amount of deletions that occurred when calling
@@ -172,16 +184,25 @@ func RunGarbageCollection(gc GarbageCollector, window, timeout time.Duration) er
 		return fmt.Errorf("error deleting in gc: %w", err)
 	}
 
+	// Expired relationships are deleted as of a new revision, and are therefore physically
+	// removed by a later run, once that revision falls outside of the GC window.
+	expired, err := gc.DeleteExpiredRels(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting expired relationships in gc: %w", err)
+	}
+
 	collectionDuration := time.Since(startTime)
 	log.Ctx(ctx).Debug().
 		Stringer("highestTxID", watermark).
 		Dur("duration", collectionDuration).
 		Time("nowTime", now).
 		Interface("collected", collected).
+		Int64("expired", expired).
 		Msg("datastore garbage collection completed successfully")
 
 	gcDurationHistogram.Observe(collectionDuration.Seconds())
 	gcRelationshipsCounter.Add(float64(collected.Relationships))
+	gcExpiredRelationshipsCounter.Add(float64(expired))
 	gcTransactionsCounter.Add(float64(collected.Transactions))
 	gcNamespacesCounter.Add(float64(collected.Namespaces))
 	return nil
@@ -3,8 +3,10 @@ package common
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -69,3 +71,51 @@ func ContextualizedCaveatFrom(name string, context map[string]any) (*core.Contex
 	}
 	return caveat, nil
 }
+
+// ExpirationTimeFrom converts an optional expiration time read from a datastore into the
+// expiration time of a tuple.
+func ExpirationTimeFrom(expiration *time.Time) *timestamppb.Timestamp {
+	if expiration == nil {
+		return nil
+	}
+	return timestamppb.New(*expiration)
+}
+
+// ExpirationTimeOf returns the optional expiration time of the tuple, in UTC, for storage in
+// a datastore.
+func ExpirationTimeOf(tpl *core.RelationTuple) *time.Time {
+	if tpl.OptionalExpirationTime == nil {
+		return nil
+	}
+
+	expiration := tpl.OptionalExpirationTime.AsTime().UTC()
+	return &expiration
+}
+
+// IntegrityFrom converts the optional serialized integrity read from a datastore into the
+// integrity of a tuple.
+func IntegrityFrom(serialized []byte) (*core.RelationshipIntegrity, error) {
+	if len(serialized) == 0 {
+		return nil, nil
+	}
+
+	integrity := &core.RelationshipIntegrity{}
+	if err := integrity.UnmarshalVT(serialized); err != nil {
+		return nil, fmt.Errorf("unable to read relationship integrity: %w", err)
+	}
+	return integrity, nil
+}
+
+// IntegrityOf returns the optional integrity of the tuple, serialized for storage in a
+// datastore.
+func IntegrityOf(tpl *core.RelationTuple) ([]byte, error) {
+	if tpl.Integrity == nil {
+		return nil, nil
+	}
+
+	serialized, err := tpl.Integrity.MarshalVT()
+	if err != nil {
+		return nil, fmt.Errorf("unable to write relationship integrity: %w", err)
+	}
+	return serialized, nil
+}
@@ -44,6 +44,8 @@ const (
 	tableTuple        = "relation_tuple"
 	tableTransactions = "transactions"
 	tableCaveat       = "caveat"
+	tableCounts       = "relationship_counts"
+	tableCounted      = "relationship_counted_namespaces"
 
 	colNamespace         = "namespace"
 	colConfig            = "serialized_config"
@@ -58,6 +60,10 @@ const (
 	colCaveatDefinition  = "definition"
 	colCaveatContextName = "caveat_name"
 	colCaveatContext     = "caveat_context"
+	colExpiration        = "expiration"
+	colIntegrity         = "integrity"
+	colRelationshipCount = "relationship_count"
+	colShard             = "shard"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	errRevision            = "unable to find revision: %w"
@@ -180,10 +186,24 @@ func newCRDBDatastore(url string, options ...Option) (datastore.Datastore, error
 		executeWithMaxRetries(config.maxRetries),
 		config.disableStats,
 		changefeedQuery,
+		func() {},
 	}
 
 	ds.RemoteClockRevisions.SetNowFunc(ds.headRevisionInternal)
 
+	// Expired relationships are deleted by SpiceDB rather than by CockroachDB's row-level TTL,
+	// so that the deletions are subtracted from the maintained relationship counts.
+	if config.gcInterval > 0 && config.gcEnabled {
+		gcCtx, cancel := context.WithCancel(context.Background())
+		if err := ds.runGC(gcCtx, common.WithJitter(0.2, config.gcInterval)); err != nil {
+			cancel()
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
+		ds.stopGC = cancel
+	} else {
+		log.Warn().Msg("datastore background garbage collection disabled")
+	}
+
 	return ds, nil
 }
 
@@ -210,6 +230,8 @@ type crdbDatastore struct {
 	disableStats        bool
 
 	beginChangefeedQuery string
+
+	stopGC context.CancelFunc
 }
 
 func (cds *crdbDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
@@ -330,6 +352,7 @@ func (cds *crdbDatastore) ReadyState(ctx context.Context) (datastore.ReadyState,
 }
 
 func (cds *crdbDatastore) Close() error {
+	cds.stopGC()
 	cds.readPool.Close()
 	cds.writePool.Close()
 	return nil
@@ -25,16 +25,19 @@ import (
 	"github.com/stretchr/testify/require"
 
 	crdbmigrations "github.com/authzed/spicedb/internal/datastore/crdb/migrations"
+	"github.com/authzed/spicedb/internal/testfixtures"
 	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 	"github.com/authzed/spicedb/pkg/datastore/test"
 	"github.com/authzed/spicedb/pkg/migrate"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func TestCRDBDatastore(t *testing.T) {
 	b := testdatastore.RunCRDBForTesting(t, "")
-	test.All(t, test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+	tester := test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
 		ds := b.NewDatastore(t, func(engine, uri string) datastore.Datastore {
 			ds, err := NewCRDBDatastore(
 				uri,
@@ -48,7 +51,49 @@ func TestCRDBDatastore(t *testing.T) {
 		})
 
 		return ds, nil
-	}))
+	})
+
+	test.All(t, tester)
+	t.Run("TestWatchSchemaDeletion", func(t *testing.T) { test.WatchSchemaDeletionTest(t, tester) })
+}
+
+func TestCRDBDeleteExpiredRels(t *testing.T) {
+	ctx := context.Background()
+	require := require.New(t)
+
+	rawDS := testdatastore.RunCRDBForTesting(t, "").NewDatastore(t, func(engine, uri string) datastore.Datastore {
+		ds, err := newCRDBDatastore(uri, GCEnabled(false))
+		require.NoError(err)
+		return ds
+	})
+	t.Cleanup(func() { rawDS.Close() })
+
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+	documentViewer := datastore.RelationKey{Definition: "document", Relation: "viewer"}
+
+	_, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		counts, err := rwt.RelationshipCounts(ctx, "document")
+		require.NoError(err)
+		require.Empty(counts)
+
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustWithExpiration(tuple.MustParse("document:first#viewer@user:tom"), time.Now().Add(-time.Minute))),
+			tuple.Create(tuple.MustParse("document:second#viewer@user:tom")),
+		})
+	})
+	require.NoError(err)
+
+	removed, err := rawDS.(*crdbDatastore).DeleteExpiredRels(ctx)
+	require.NoError(err)
+	require.Equal(int64(1), removed)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		counts, err := rwt.RelationshipCounts(ctx, "document")
+		require.NoError(err)
+		require.Equal(map[datastore.RelationKey]uint64{documentViewer: 1}, counts)
+		return nil
+	})
+	require.NoError(err)
 }
 
 func TestCRDBDatastoreWithFollowerReads(t *testing.T) {
@@ -158,7 +203,7 @@ func TestWatchFeatureDetection(t *testing.T) {
 				headRevision, err := ds.HeadRevision(ctx)
 				require.NoError(t, err)
 
-				_, errChan := ds.Watch(ctx, headRevision)
+				_, errChan := ds.Watch(ctx, headRevision, datastore.WatchJustRelationships())
 				err = <-errChan
 				require.NotNil(t, err)
 				require.Contains(t, err.Error(), "watch is currently disabled")
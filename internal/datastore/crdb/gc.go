@@ -0,0 +1,97 @@
+package crdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-co-op/gocron"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// gcBatchDeleteSize is the maximum number of expired relationships deleted per transaction.
+const gcBatchDeleteSize = 1000
+
+// DeleteExpiredRels deletes all relationships which have expired, subtracting them from the
+// maintained relationship counts. The deletions are emitted by Watch as with any other
+// deletion. Returns the number of relationships deleted.
+func (cds *crdbDatastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
+	var removed int64
+	for {
+		var deleted int64
+		_, err := cds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+			crdbRWT := rwt.(*crdbReadWriteTXN)
+
+			deltas := common.RelationshipCountDeltas{}
+			var err error
+			deleted, err = crdbRWT.deleteTuples(
+				ctx,
+				queryDeleteTuples.Where(sq.LtOrEq{colExpiration: time.Now()}).Limit(gcBatchDeleteSize),
+				deltas,
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, namespace := range deltas.Namespaces() {
+				crdbRWT.addOverlapKey(namespace)
+			}
+			crdbRWT.relCountChange -= deleted
+
+			return crdbRWT.updateRelationshipCounts(ctx, deltas)
+		})
+		if err != nil {
+			return removed, err
+		}
+
+		removed += deleted
+		if deleted < gcBatchDeleteSize {
+			return removed, nil
+		}
+	}
+}
+
+// runGC runs the gc cron job, which deletes expired relationships, returning an error if it
+// can't start properly. It cleans up and stops when ctx is Done.
+//
+// Every SpiceDB node schedules the job independently, so runs on different nodes may overlap.
+// This is safe: each batch deletes the rows and adjusts the counts by the rows it actually
+// deleted within the same serializable transaction, so a relationship removed by one node is
+// neither deleted nor subtracted again by another. Conflicting batches are retried by ReadWriteTx.
+func (cds *crdbDatastore) runGC(ctx context.Context, interval time.Duration) error {
+	log.Ctx(ctx).Info().Stringer("interval", interval).Msg("garbage collection: starting")
+
+	s := gocron.NewScheduler(time.UTC)
+
+	_, err := s.Every(interval).Do(func() {
+		ctx, span := tracer.Start(context.Background(), "CollectGarbage")
+		defer span.End()
+
+		ctx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+
+		expired, err := cds.DeleteExpiredRels(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("garbage collection: error deleting expired relationships")
+			return
+		}
+
+		log.Ctx(ctx).Info().Int64("removed", expired).Msg("garbage collection: removed expired relationships")
+	})
+	if err != nil {
+		return fmt.Errorf("unable to start garbage collection: %w", err)
+	}
+
+	s.StartAsync()
+
+	go func() {
+		<-ctx.Done()
+		log.Ctx(ctx).Info().Msg("garbage collection: stopping")
+		s.Stop()
+	}()
+	return nil
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	addRelationshipExpiration = `ALTER TABLE relation_tuple
+		ADD COLUMN expiration TIMESTAMPTZ;`
+
+	// Expired relationships are removed by CockroachDB's row-level TTL job. Rows without an
+	// expiration are never removed. Requires CockroachDB v22.2 or later.
+	enableRelationshipTTL = `ALTER TABLE relation_tuple
+		SET (ttl_expiration_expression = 'expiration');`
+)
+
+func init() {
+	err := CRDBMigrations.Register("add-expiration", "add-caveats", addExpirationFunc, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
+
+func addExpirationFunc(ctx context.Context, conn *pgx.Conn) error {
+	if _, err := conn.Exec(ctx, addRelationshipExpiration); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, enableRelationshipTTL); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const addRelationshipIntegrity = `ALTER TABLE relation_tuple
+	ADD COLUMN IF NOT EXISTS integrity BYTES;`
+
+func init() {
+	err := CRDBMigrations.Register("add-relationship-integrity", "add-expiration", addRelationshipIntegrityFunc, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
+
+func addRelationshipIntegrityFunc(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, addRelationshipIntegrity)
+	return err
+}
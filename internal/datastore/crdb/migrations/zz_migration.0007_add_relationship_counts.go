@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	createRelationshipCountedNamespaces = `CREATE TABLE relationship_counted_namespaces (
+	namespace VARCHAR NOT NULL,
+	CONSTRAINT pk_relationship_counted_namespaces PRIMARY KEY (namespace)
+);`
+
+	createRelationshipCounts = `CREATE TABLE relationship_counts (
+	namespace VARCHAR NOT NULL,
+	relation VARCHAR NOT NULL,
+	shard INT NOT NULL,
+	relationship_count INT NOT NULL,
+	CONSTRAINT pk_relationship_counts PRIMARY KEY (namespace, relation, shard)
+);`
+)
+
+func init() {
+	if err := CRDBMigrations.Register("add-relationship-counts", "add-relationship-integrity", noNonAtomicMigration, func(ctx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, createRelationshipCountedNamespaces); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, createRelationshipCounts); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Expired relationships are deleted by SpiceDB's garbage collection, rather than by
+// CockroachDB's row-level TTL, so that the deletions are subtracted from the maintained
+// relationship counts.
+const removeRelationshipTTL = `ALTER TABLE relation_tuple
+	RESET (ttl_expiration_expression);`
+
+func init() {
+	err := CRDBMigrations.Register("remove-relationship-ttl", "add-relationship-counts", removeRelationshipTTLFunc, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
+
+func removeRelationshipTTLFunc(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, removeRelationshipTTL)
+	return err
+}
@@ -15,6 +15,8 @@ type crdbOptions struct {
 	followerReadDelay           time.Duration
 	maxRevisionStalenessPercent float64
 	gcWindow                    time.Duration
+	gcInterval                  time.Duration
+	gcEnabled                   bool
 	maxRetries                  uint8
 	splitAtUsersetCount         uint16
 	overlapStrategy             string
@@ -36,6 +38,8 @@ const (
 	defaultMaxRevisionStalenessPercent = 0.1
 	defaultWatchBufferLength           = 128
 	defaultSplitSize                   = 1024
+	defaultGCInterval                  = 3 * time.Minute
+	defaultGCEnabled                   = true
 
 	defaultMaxRetries      = 5
 	defaultOverlapKey      = "defaultsynckey"
@@ -51,6 +55,8 @@ type Option func(*crdbOptions)
 func generateConfig(options []Option) (crdbOptions, error) {
 	computed := crdbOptions{
 		gcWindow:                    24 * time.Hour,
+		gcInterval:                  defaultGCInterval,
+		gcEnabled:                   defaultGCEnabled,
 		watchBufferLength:           defaultWatchBufferLength,
 		revisionQuantization:        defaultRevisionQuantization,
 		followerReadDelay:           defaultFollowerReadDelay,
@@ -253,6 +259,21 @@ func GCWindow(window time.Duration) Option {
 	return func(po *crdbOptions) { po.gcWindow = window }
 }
 
+// GCInterval is the interval at which expired relationships are deleted.
+//
+// This value defaults to 3 minutes.
+func GCInterval(interval time.Duration) Option {
+	return func(po *crdbOptions) { po.gcInterval = interval }
+}
+
+// GCEnabled indicates whether expired relationships are deleted in the
+// background.
+//
+// GC is enabled by default.
+func GCEnabled(isGCEnabled bool) Option {
+	return func(po *crdbOptions) { po.gcEnabled = isGCEnabled }
+}
+
 // MaxRetries is the maximum number of times a retriable transaction will be
 // client-side retried.
 // Default: 5
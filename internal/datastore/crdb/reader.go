@@ -33,6 +33,8 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
+		colIntegrity,
 	)
 
 	schema = common.NewSchemaInformation(
@@ -47,6 +49,13 @@ var (
 	)
 )
 
+// notExpiredClause matches relationships which have no expiration or which expire after now.
+// Expired relationships are removed by garbage collection, but the removal is not immediate
+// and so they must also be filtered out when read.
+func notExpiredClause(now time.Time) sq.Sqlizer {
+	return sq.Or{sq.Eq{colExpiration: nil}, sq.Gt{colExpiration: now}}
+}
+
 type crdbReader struct {
 	txSource      pgxcommon.TxFactory
 	querySplitter common.TupleQuerySplitter
@@ -146,7 +155,7 @@ func (cr *crdbReader) QueryRelationships(
 	filter datastore.RelationshipsFilter,
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	query := cr.fromBuilder(queryTuples, tableTuple)
+	query := cr.fromBuilder(queryTuples, tableTuple).Where(notExpiredClause(time.Now()))
 	qBuilder, err := common.NewSchemaQueryFilterer(schema, query).FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
@@ -167,7 +176,7 @@ func (cr *crdbReader) ReverseQueryRelationships(
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	query := cr.fromBuilder(queryTuples, tableTuple)
+	query := cr.fromBuilder(queryTuples, tableTuple).Where(notExpiredClause(time.Now()))
 	qBuilder, err := common.NewSchemaQueryFilterer(schema, query).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
@@ -187,6 +196,8 @@ func (cr *crdbReader) ReverseQueryRelationships(
 			ctx,
 			qBuilder,
 			options.WithLimit(queryOpts.ReverseLimit),
+			options.WithSort(queryOpts.SortForReverse),
+			options.WithAfter(queryOpts.AfterForReverse),
 		)
 		return err
 	})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -11,17 +12,25 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 const (
-	errUnableToWriteConfig         = "unable to write namespace config: %w"
-	errUnableToDeleteConfig        = "unable to delete namespace config: %w"
-	errUnableToWriteRelationships  = "unable to write relationships: %w"
-	errUnableToDeleteRelationships = "unable to delete relationships: %w"
+	errUnableToWriteConfig           = "unable to write namespace config: %w"
+	errUnableToDeleteConfig          = "unable to delete namespace config: %w"
+	errUnableToWriteRelationships    = "unable to write relationships: %w"
+	errUnableToDeleteRelationships   = "unable to delete relationships: %w"
+	errUnableToBulkLoadRelationships = "unable to bulk load relationships: %w"
+	errUnableToReadCounts            = "unable to read relationship counts: %w"
+
+	// bulkLoadBatchSize is the number of relationships written per INSERT statement when bulk
+	// loading.
+	bulkLoadBatchSize = 1000
 )
 
 var (
@@ -47,7 +56,7 @@ type crdbReadWriteTXN struct {
 
 var (
 	upsertTupleSuffix = fmt.Sprintf(
-		"ON CONFLICT (%s,%s,%s,%s,%s,%s) DO UPDATE SET %s = now(), %s = excluded.%s, %s = excluded.%s",
+		"ON CONFLICT (%s,%s,%s,%s,%s,%s) DO UPDATE SET %s = now(), %s = excluded.%s, %s = excluded.%s, %s = excluded.%s, %s = excluded.%s",
 		colNamespace,
 		colObjectID,
 		colRelation,
@@ -59,6 +68,10 @@ var (
 		colCaveatContextName,
 		colCaveatContext,
 		colCaveatContext,
+		colExpiration,
+		colExpiration,
+		colIntegrity,
+		colIntegrity,
 	)
 
 	queryWriteTuple = psql.Insert(tableTuple).Columns(
@@ -70,12 +83,35 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
+		colIntegrity,
 	)
 
 	queryTouchTuple = queryWriteTuple.Suffix(upsertTupleSuffix)
 
 	queryDeleteTuples = psql.Delete(tableTuple)
 
+	queryExistingTuples = psql.Select(colNamespace, colRelation).From(tableTuple)
+
+	queryUpsertCounts = psql.Insert(tableCounts).Columns(colNamespace, colRelation, colShard, colRelationshipCount).Suffix(fmt.Sprintf(
+		"ON CONFLICT (%[1]s, %[2]s, %[3]s) DO UPDATE SET %[4]s = %[5]s.%[4]s + excluded.%[4]s",
+		colNamespace, colRelation, colShard, colRelationshipCount, tableCounts,
+	))
+
+	queryReadCounts = psql.Select(colNamespace, colRelation, fmt.Sprintf("sum(%s)::INT8", colRelationshipCount)).
+			From(tableCounts).
+			GroupBy(colNamespace, colRelation).
+			Having(fmt.Sprintf("sum(%s) > 0", colRelationshipCount))
+
+	queryReadCountedNamespaces = psql.Select(colNamespace).From(tableCounted)
+
+	queryWriteCountedNamespace = psql.Insert(tableCounted).Columns(colNamespace)
+
+	// The existing relationships of a namespace are counted into the first shard of each count.
+	queryBackfillCounts = psql.Select(colNamespace, colRelation, "0", "count(*)").
+				From(tableTuple).
+				GroupBy(colNamespace, colRelation)
+
 	queryTouchTransaction = fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES ($1::text) ON CONFLICT (%s) DO UPDATE SET %s = now()",
 		tableTransactions,
@@ -91,6 +127,14 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 
 	bulkTouch := queryTouchTuple
 	var bulkTouchCount int64
+	touchClauses := sq.Or{}
+
+	deltas := common.RelationshipCountDeltas{}
+
+	// Relationships being created may replace ones which have expired but which have not yet
+	// been removed by garbage collection.
+	expiredCreateClauses := sq.Or{}
+	now := time.Now()
 
 	// Process the actual updates
 	for _, mutation := range mutations {
@@ -103,6 +147,11 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 			caveatContext = rel.Caveat.Context.AsMap()
 		}
 
+		integrity, err := common.IntegrityOf(rel)
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
 		rwt.addOverlapKey(rel.ResourceAndRelation.Namespace)
 		rwt.addOverlapKey(rel.Subject.Namespace)
 
@@ -118,8 +167,12 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 				rel.Subject.Relation,
 				caveatName,
 				caveatContext,
+				common.ExpirationTimeOf(rel),
+				integrity,
 			)
 			bulkTouchCount++
+			touchClauses = append(touchClauses, exactRelationshipClause(rel))
+			deltas.Add(rel.ResourceAndRelation.Namespace, rel.ResourceAndRelation.Relation, 1)
 		case core.RelationTupleUpdate_CREATE:
 			rwt.relCountChange++
 			bulkWrite = bulkWrite.Values(
@@ -131,16 +184,18 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 				rel.Subject.Relation,
 				caveatName,
 				caveatContext,
+				common.ExpirationTimeOf(rel),
+				integrity,
 			)
 			bulkWriteCount++
+			expiredCreateClauses = append(expiredCreateClauses, sq.And{
+				exactRelationshipClause(rel),
+				sq.LtOrEq{colExpiration: now},
+			})
+			deltas.Add(rel.ResourceAndRelation.Namespace, rel.ResourceAndRelation.Relation, 1)
 		case core.RelationTupleUpdate_DELETE:
 			rwt.relCountChange--
-			sql, args, err := queryDeleteTuples.Where(exactRelationshipClause(rel)).ToSql()
-			if err != nil {
-				return fmt.Errorf(errUnableToWriteRelationships, err)
-			}
-
-			if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+			if _, err := rwt.deleteTuples(ctx, queryDeleteTuples.Where(exactRelationshipClause(rel)), deltas); err != nil {
 				return fmt.Errorf(errUnableToWriteRelationships, err)
 			}
 		default:
@@ -149,6 +204,40 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 		}
 	}
 
+	if len(expiredCreateClauses) > 0 {
+		deleted, err := rwt.deleteTuples(ctx, queryDeleteTuples.Where(expiredCreateClauses), deltas)
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+		rwt.relCountChange -= deleted
+	}
+
+	// Touched relationships which already exist do not change the counts. As the upsert does
+	// not report which rows it inserted, the existing relationships are read beforehand.
+	if len(touchClauses) > 0 {
+		sql, args, err := queryExistingTuples.Where(touchClauses).ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		rows, err := rwt.tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+		for rows.Next() {
+			var namespace, relation string
+			if err := rows.Scan(&namespace, &relation); err != nil {
+				rows.Close()
+				return fmt.Errorf(errUnableToWriteRelationships, err)
+			}
+			deltas.Add(namespace, relation, -1)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+	}
+
 	bulkUpdateQueries := make([]sq.InsertBuilder, 0, 2)
 	if bulkWriteCount > 0 {
 		bulkUpdateQueries = append(bulkUpdateQueries, bulkWrite)
@@ -174,9 +263,172 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 		}
 	}
 
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
 	return nil
 }
 
+// deleteTuples deletes the tuples matched by the query, and adds their removal to the deltas.
+// Returns the number of tuples deleted.
+func (rwt *crdbReadWriteTXN) deleteTuples(ctx context.Context, query sq.DeleteBuilder, deltas common.RelationshipCountDeltas) (int64, error) {
+	sql, args, err := query.Suffix(fmt.Sprintf("RETURNING %s, %s", colNamespace, colRelation)).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := rwt.tx.Query(ctx, fmt.Sprintf(
+		"WITH deleted AS (%s) SELECT %s, %s, count(*) FROM deleted GROUP BY %s, %s",
+		sql, colNamespace, colRelation, colNamespace, colRelation,
+	), args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total int64
+	for rows.Next() {
+		var namespace, relation string
+		var count int64
+		if err := rows.Scan(&namespace, &relation, &count); err != nil {
+			return 0, err
+		}
+		deltas.Add(namespace, relation, -count)
+		total += count
+	}
+	return total, rows.Err()
+}
+
+// updateRelationshipCounts applies the deltas to the maintained relationship counts, for the
+// namespaces whose counts are maintained. The deltas are added to a randomly selected shard of
+// each count.
+func (rwt *crdbReadWriteTXN) updateRelationshipCounts(ctx context.Context, deltas common.RelationshipCountDeltas) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	counted, err := rwt.countedNamespaces(ctx, deltas.Namespaces())
+	if err != nil {
+		return err
+	}
+	if len(counted) == 0 {
+		return nil
+	}
+
+	shard := common.RandomRelationshipCountShard()
+	query := queryUpsertCounts
+	for key, delta := range deltas {
+		if _, ok := counted[key.Definition]; ok {
+			query = query.Values(key.Definition, key.Relation, shard, delta)
+		}
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = rwt.tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// countedNamespaces returns the set of the given namespaces whose relationship counts are
+// maintained.
+func (rwt *crdbReadWriteTXN) countedNamespaces(ctx context.Context, namespaces []string) (map[string]struct{}, error) {
+	sql, args, err := queryReadCountedNamespaces.Where(sq.Eq{colNamespace: namespaces}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rwt.tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counted := make(map[string]struct{}, len(namespaces))
+	for rows.Next() {
+		var namespace string
+		if err := rows.Scan(&namespace); err != nil {
+			return nil, err
+		}
+		counted[namespace] = struct{}{}
+	}
+	return counted, rows.Err()
+}
+
+// startCounting starts maintaining the relationship counts of the given namespaces which are
+// not yet counted, by counting their existing relationships.
+func (rwt *crdbReadWriteTXN) startCounting(ctx context.Context, namespaces []string) error {
+	counted, err := rwt.countedNamespaces(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+
+	uncounted := make([]string, 0, len(namespaces))
+	insertQuery := queryWriteCountedNamespace
+	for _, namespace := range namespaces {
+		if _, ok := counted[namespace]; !ok {
+			counted[namespace] = struct{}{}
+			uncounted = append(uncounted, namespace)
+			insertQuery = insertQuery.Values(namespace)
+		}
+	}
+	if len(uncounted) == 0 {
+		return nil
+	}
+
+	sql, args, err := insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, err = psql.Insert(tableCounts).
+		Columns(colNamespace, colRelation, colShard, colRelationshipCount).
+		Select(queryBackfillCounts.Where(sq.Eq{colNamespace: uncounted})).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = rwt.tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func (rwt *crdbReadWriteTXN) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	if err := rwt.startCounting(ctx, definitions); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	sql, args, err := queryReadCounts.Where(sq.Eq{colNamespace: definitions}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	rows, err := rwt.tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[datastore.RelationKey]uint64)
+	for rows.Next() {
+		var key datastore.RelationKey
+		var count int64
+		if err := rows.Scan(&key.Definition, &key.Relation, &count); err != nil {
+			return nil, fmt.Errorf(errUnableToReadCounts, err)
+		}
+		counts[key] = uint64(count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+	return counts, nil
+}
+
 func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	return sq.Eq{
 		colNamespace:        r.ResourceAndRelation.Namespace,
@@ -188,7 +440,7 @@ func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	}
 }
 
-func (rwt *crdbReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+func (rwt *crdbReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
 	// Add clauses for the ResourceFilter
 	query := queryDeleteTuples.Where(sq.Eq{colNamespace: filter.ResourceType})
 	if filter.OptionalResourceId != "" {
@@ -210,19 +462,25 @@ func (rwt *crdbReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1
 		}
 		rwt.addOverlapKey(subjectFilter.SubjectType)
 	}
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+
+	delOpts := options.NewDeleteOptionsWithOptions(opts...)
+	if delOpts.DeleteLimit != nil {
+		query = query.Limit(*delOpts.DeleteLimit)
 	}
 
-	modified, err := rwt.tx.Exec(ctx, sql, args...)
+	deltas := common.RelationshipCountDeltas{}
+	deleted, err := rwt.deleteTuples(ctx, query, deltas)
 	if err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+		return false, fmt.Errorf(errUnableToDeleteRelationships, err)
 	}
 
-	rwt.relCountChange -= modified.RowsAffected()
+	rwt.relCountChange -= deleted
 
-	return nil
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return false, fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	return delOpts.DeleteLimit != nil && uint64(deleted) == *delOpts.DeleteLimit, nil
 }
 
 func (rwt *crdbReadWriteTXN) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
@@ -280,20 +538,106 @@ func (rwt *crdbReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...st
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
-	deleteTupleSQL, deleteTupleArgs, err := queryDeleteTuples.Where(sq.Or(tplClauses)).ToSql()
+	deltas := common.RelationshipCountDeltas{}
+	numRowsDeleted, err := rwt.deleteTuples(ctx, queryDeleteTuples.Where(sq.Or(tplClauses)), deltas)
 	if err != nil {
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
-	modified, err := rwt.tx.Exec(ctx, deleteTupleSQL, deleteTupleArgs...)
-	if err != nil {
+	rwt.relCountChange -= numRowsDeleted
+
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
-	numRowsDeleted := modified.RowsAffected()
-	rwt.relCountChange -= numRowsDeleted
-
 	return nil
 }
 
+func (rwt *crdbReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var numLoaded uint64
+	deltas := common.RelationshipCountDeltas{}
+
+	bulkWrite := queryWriteTuple
+	var batchCount int
+	flush := func() error {
+		if batchCount == 0 {
+			return nil
+		}
+
+		sql, args, err := bulkWrite.ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+			// If a unique constraint violation is returned, then its likely that the cause
+			// was an existing relationship.
+			if cerr := pgxcommon.ConvertToWriteConstraintError(livingTupleConstraint, err); cerr != nil {
+				return cerr
+			}
+
+			return fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		bulkWrite = queryWriteTuple
+		batchCount = 0
+		return nil
+	}
+
+	var rel *core.RelationTuple
+	var err error
+	for rel, err = iter.Next(ctx); rel != nil && err == nil; rel, err = iter.Next(ctx) {
+		var caveatContext map[string]any
+		var caveatName string
+		if rel.Caveat != nil {
+			caveatName = rel.Caveat.CaveatName
+			caveatContext = rel.Caveat.Context.AsMap()
+		}
+
+		integrity, err := common.IntegrityOf(rel)
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		rwt.addOverlapKey(rel.ResourceAndRelation.Namespace)
+		rwt.addOverlapKey(rel.Subject.Namespace)
+		rwt.relCountChange++
+
+		bulkWrite = bulkWrite.Values(
+			rel.ResourceAndRelation.Namespace,
+			rel.ResourceAndRelation.ObjectId,
+			rel.ResourceAndRelation.Relation,
+			rel.Subject.Namespace,
+			rel.Subject.ObjectId,
+			rel.Subject.Relation,
+			caveatName,
+			caveatContext,
+			common.ExpirationTimeOf(rel),
+			integrity,
+		)
+		batchCount++
+		numLoaded++
+		deltas.Add(rel.ResourceAndRelation.Namespace, rel.ResourceAndRelation.Relation, 1)
+
+		if batchCount >= bulkLoadBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+	}
+
+	return numLoaded, nil
+}
+
 var _ datastore.ReadWriteTransaction = &crdbReadWriteTXN{}
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 
+	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
 )
@@ -91,3 +92,131 @@ func updateCounter(ctx context.Context, tx pgx.Tx, change int64) (revision.Decim
 
 	return revision.NewFromDecimal(timestamp), nil
 }
+
+// detailedStatsExactCountLimit is the number of rows in the tuple table, according to its
+// table statistics, up to which relationships are counted exactly. The relationships of
+// larger tables are estimated from the histogram of the table statistics instead.
+const detailedStatsExactCountLimit = 1_000_000
+
+var (
+	queryTupleTableStatistics = fmt.Sprintf("SHOW STATISTICS USING JSON FOR TABLE %s", tableTuple)
+
+	queryTupleStorageSize = fmt.Sprintf(
+		"SELECT COALESCE(SUM(range_size), 0)::INT FROM [SHOW RANGES FROM TABLE %s WITH DETAILS]",
+		tableTuple,
+	)
+)
+
+// tableStatistic is a single statistic, as returned by SHOW STATISTICS USING JSON.
+type tableStatistic struct {
+	Columns      []string          `json:"columns"`
+	CreatedAt    string            `json:"created_at"`
+	RowCount     uint64            `json:"row_count"`
+	HistoBuckets []histogramBucket `json:"histo_buckets"`
+}
+
+type histogramBucket struct {
+	NumEq      float64 `json:"num_eq"`
+	UpperBound string  `json:"upper_bound"`
+}
+
+// DetailedStatistics reads the number of rows in the tuple table from its table statistics.
+// Tables with up to detailedStatsExactCountLimit rows are counted exactly, in a low priority
+// transaction so that the count yields to concurrent writes. The relationships of larger
+// tables are estimated for each definition from the histogram of the namespace column,
+// without counts for each relation. CockroachDB does not keep a changelog, so no backlog is
+// reported.
+func (cds *crdbDatastore) DetailedStatistics(ctx context.Context) (datastore.DetailedStats, error) {
+	var statistics []tableStatistic
+	if err := cds.readPool.QueryRow(ctx, queryTupleTableStatistics).Scan(&statistics); err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to read table statistics: %w", err)
+	}
+
+	var stats datastore.DetailedStats
+	if namespaceStatistic := latestNamespaceStatistic(statistics); namespaceStatistic != nil && namespaceStatistic.RowCount > detailedStatsExactCountLimit {
+		stats.CountsAreEstimates = true
+		stats.Definitions = datastore.ComputeDefinitionStats(namespaceStatistic.definitionCounts())
+	} else {
+		counts, err := cds.countRelationships(ctx)
+		if err != nil {
+			return datastore.DetailedStats{}, err
+		}
+		stats.Definitions = datastore.ComputeDefinitionStats(counts)
+	}
+
+	// Range details are only available from CockroachDB v23.1, so the storage size is left
+	// unreported on earlier versions.
+	if err := cds.readPool.QueryRow(ctx, queryTupleStorageSize).Scan(&stats.StorageSizeBytes); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("unable to read relationship storage size")
+	}
+
+	return stats, nil
+}
+
+// latestNamespaceStatistic returns the most recently collected statistic of the namespace
+// column, or nil if none has been collected yet.
+func latestNamespaceStatistic(statistics []tableStatistic) *tableStatistic {
+	var latest *tableStatistic
+	for i, statistic := range statistics {
+		if len(statistic.Columns) != 1 || statistic.Columns[0] != colNamespace {
+			continue
+		}
+		// Creation times are formatted with fixed width fields, so they sort as strings.
+		if latest == nil || statistic.CreatedAt > latest.CreatedAt {
+			latest = &statistics[i]
+		}
+	}
+	return latest
+}
+
+// definitionCounts returns the number of relationships of each definition according to the
+// histogram of the namespace column. Definitions are few enough for each to be the upper
+// bound of its own bucket, so rows counted in the range of a bucket are not attributed.
+func (ts *tableStatistic) definitionCounts() map[datastore.RelationKey]uint64 {
+	counts := make(map[datastore.RelationKey]uint64, len(ts.HistoBuckets))
+	for _, bucket := range ts.HistoBuckets {
+		if bucket.NumEq < 1 {
+			continue
+		}
+		counts[datastore.RelationKey{Definition: bucket.UpperBound}] += uint64(bucket.NumEq)
+	}
+	return counts
+}
+
+func (cds *crdbDatastore) countRelationships(ctx context.Context) (map[datastore.RelationKey]uint64, error) {
+	sql, args, err := psql.Select(colNamespace, colRelation, "COUNT(*)").
+		From(tableTuple).
+		Where(notExpiredClause(time.Now())).
+		GroupBy(colNamespace, colRelation).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare relation count sql: %w", err)
+	}
+
+	counts := make(map[datastore.RelationKey]uint64)
+	if err := pgx.BeginTxFunc(ctx, cds.readPool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SET TRANSACTION PRIORITY LOW"); err != nil {
+			return fmt.Errorf("unable to set transaction priority: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("unable to count relationships: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var key datastore.RelationKey
+			var count uint64
+			if err := rows.Scan(&key.Definition, &key.Relation, &count); err != nil {
+				return fmt.Errorf("unable to count relationships: %w", err)
+			}
+			counts[key] = count
+		}
+		return rows.Err()
+	}); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
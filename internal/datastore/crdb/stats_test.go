@@ -0,0 +1,38 @@
+package crdb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const testTableStatistics = `[
+	{"name": "__auto__", "columns": ["namespace"], "created_at": "2023-05-01 10:00:00.000000", "row_count": 10,
+	 "histo_buckets": [{"num_eq": 10, "num_range": 0, "distinct_range": 0, "upper_bound": "document"}]},
+	{"name": "__auto__", "columns": ["relation"], "created_at": "2023-05-02 10:00:00.000000", "row_count": 5000000,
+	 "histo_buckets": [{"num_eq": 5000000, "num_range": 0, "distinct_range": 0, "upper_bound": "viewer"}]},
+	{"name": "__auto__", "columns": ["namespace"], "created_at": "2023-05-02 10:00:00.000000", "row_count": 5000000,
+	 "histo_buckets": [
+		{"num_eq": 3000000, "num_range": 0, "distinct_range": 0, "upper_bound": "document"},
+		{"num_eq": 0, "num_range": 5, "distinct_range": 1, "upper_bound": "folder"},
+		{"num_eq": 1999995, "num_range": 0, "distinct_range": 0, "upper_bound": "user"}
+	 ]}
+]`
+
+func TestDefinitionCountsFromTableStatistics(t *testing.T) {
+	var statistics []tableStatistic
+	require.NoError(t, json.Unmarshal([]byte(testTableStatistics), &statistics))
+
+	latest := latestNamespaceStatistic(statistics)
+	require.NotNil(t, latest)
+	require.Equal(t, uint64(5000000), latest.RowCount)
+	require.Equal(t, map[datastore.RelationKey]uint64{
+		{Definition: "document"}: 3000000,
+		{Definition: "user"}:     1999995,
+	}, latest.definitionCounts())
+
+	require.Nil(t, latestNamespaceStatistic(statistics[1:2]))
+}
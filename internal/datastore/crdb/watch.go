@@ -2,10 +2,12 @@ package crdb
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -24,12 +26,19 @@ type changeDetails struct {
 	Resolved string
 	Updated  string
 	After    *struct {
+		// Columns of the relationship table.
 		CaveatContext map[string]any `json:"caveat_context"`
 		CaveatName    string         `json:"caveat_name"`
+		Expiration    *time.Time     `json:"expiration"`
+
+		// Columns of the namespace and caveat tables. Bytes columns are hex encoded with a
+		// leading `\x`.
+		SerializedNamespaceConfig string `json:"serialized_config"`
+		CaveatDefinition          string `json:"definition"`
 	}
 }
 
-func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
 	updates := make(chan *datastore.RevisionChanges, cds.watchBufferLength)
 	errs := make(chan error, 1)
 
@@ -55,13 +64,27 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 		return updates, errs
 	}
 
-	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, tableTuple, afterRevision)
+	var tables []string
+	if options.Includes(datastore.WatchRelationships) {
+		tables = append(tables, tableTuple)
+	}
+	if options.Includes(datastore.WatchSchema) {
+		tables = append(tables, tableNamespace, tableCaveat)
+	}
+
+	if len(tables) == 0 {
+		errs <- fmt.Errorf("at least one kind of change must be watched")
+		return updates, errs
+	}
+
+	interpolated := fmt.Sprintf(cds.beginChangefeedQuery, strings.Join(tables, ", "), afterRevision)
 
 	go func() {
 		defer close(updates)
 		defer close(errs)
 
 		pendingChanges := make(map[string]*datastore.RevisionChanges)
+		lastCheckpoint := time.Now()
 
 		changes, err := conn.Query(ctx, interpolated)
 		if err != nil {
@@ -78,11 +101,11 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 		defer func() { go changes.Close() }()
 
 		for changes.Next() {
-			var unused interface{}
+			var tableName string
 			var changeJSON []byte
 			var primaryKeyValuesJSON []byte
 
-			if err := changes.Scan(&unused, &primaryKeyValuesJSON, &changeJSON); err != nil {
+			if err := changes.Scan(&tableName, &primaryKeyValuesJSON, &changeJSON); err != nil {
 				if errors.Is(ctx.Err(), context.Canceled) {
 					errs <- datastore.NewWatchCanceledErr()
 				} else {
@@ -127,10 +150,24 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 					}
 				}
 
+				if options.CheckpointInterval > 0 && time.Since(lastCheckpoint) >= options.CheckpointInterval {
+					// Changes at exactly the resolved timestamp are held back until the next
+					// resolved timestamp, so only checkpoint once none of them are pending.
+					if !hasPendingAtOrBefore(pendingChanges, resolved) {
+						select {
+						case updates <- &datastore.RevisionChanges{Revision: resolved, IsCheckpoint: true}:
+							lastCheckpoint = time.Now()
+						default:
+							errs <- datastore.NewWatchDisconnectedErr()
+							return
+						}
+					}
+				}
+
 				continue
 			}
 
-			var pkValues [6]string
+			var pkValues []string
 			if err := json.Unmarshal(primaryKeyValuesJSON, &pkValues); err != nil {
 				errs <- err
 				return
@@ -142,40 +179,6 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				return
 			}
 
-			var caveatName string
-			var caveatContext map[string]any
-			if details.After != nil && details.After.CaveatName != "" {
-				caveatName = details.After.CaveatName
-				caveatContext = details.After.CaveatContext
-			}
-			ctxCaveat, err := common.ContextualizedCaveatFrom(caveatName, caveatContext)
-			if err != nil {
-				errs <- err
-				return
-			}
-
-			oneChange := &core.RelationTupleUpdate{
-				Tuple: &core.RelationTuple{
-					ResourceAndRelation: &core.ObjectAndRelation{
-						Namespace: pkValues[0],
-						ObjectId:  pkValues[1],
-						Relation:  pkValues[2],
-					},
-					Subject: &core.ObjectAndRelation{
-						Namespace: pkValues[3],
-						ObjectId:  pkValues[4],
-						Relation:  pkValues[5],
-					},
-					Caveat: ctxCaveat,
-				},
-			}
-
-			if details.After == nil {
-				oneChange.Operation = core.RelationTupleUpdate_DELETE
-			} else {
-				oneChange.Operation = core.RelationTupleUpdate_TOUCH
-			}
-
 			pending, ok := pendingChanges[details.Updated]
 			if !ok {
 				pending = &datastore.RevisionChanges{
@@ -183,7 +186,21 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 				}
 				pendingChanges[details.Updated] = pending
 			}
-			pending.Changes = append(pending.Changes, oneChange)
+
+			switch tableName {
+			case tableTuple:
+				err = addRelationshipChange(pending, pkValues, details)
+			case tableNamespace:
+				err = addNamespaceChange(pending, pkValues, details)
+			case tableCaveat:
+				err = addCaveatChange(pending, pkValues, details)
+			default:
+				err = fmt.Errorf("unexpected table in changefeed: %s", tableName)
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
 		}
 
 		if changes.Err() != nil {
@@ -202,3 +219,112 @@ func (cds *crdbDatastore) Watch(ctx context.Context, afterRevision datastore.Rev
 	}()
 	return updates, errs
 }
+
+// hasPendingAtOrBefore returns true if any pending change is at or before the given revision.
+func hasPendingAtOrBefore(pendingChanges map[string]*datastore.RevisionChanges, rev datastore.Revision) bool {
+	for _, pending := range pendingChanges {
+		if !pending.Revision.GreaterThan(rev) {
+			return true
+		}
+	}
+	return false
+}
+
+func addRelationshipChange(pending *datastore.RevisionChanges, pkValues []string, details changeDetails) error {
+	if len(pkValues) != 6 {
+		return fmt.Errorf("unexpected primary key for relationship: %v", pkValues)
+	}
+
+	var caveatName string
+	var caveatContext map[string]any
+	if details.After != nil && details.After.CaveatName != "" {
+		caveatName = details.After.CaveatName
+		caveatContext = details.After.CaveatContext
+	}
+	ctxCaveat, err := common.ContextualizedCaveatFrom(caveatName, caveatContext)
+	if err != nil {
+		return err
+	}
+
+	oneChange := &core.RelationTupleUpdate{
+		Tuple: &core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{
+				Namespace: pkValues[0],
+				ObjectId:  pkValues[1],
+				Relation:  pkValues[2],
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: pkValues[3],
+				ObjectId:  pkValues[4],
+				Relation:  pkValues[5],
+			},
+			Caveat: ctxCaveat,
+		},
+	}
+
+	if details.After != nil {
+		oneChange.Tuple.OptionalExpirationTime = common.ExpirationTimeFrom(details.After.Expiration)
+	}
+
+	if details.After == nil {
+		oneChange.Operation = core.RelationTupleUpdate_DELETE
+	} else {
+		oneChange.Operation = core.RelationTupleUpdate_TOUCH
+	}
+
+	pending.Changes = append(pending.Changes, oneChange)
+	return nil
+}
+
+func addNamespaceChange(pending *datastore.RevisionChanges, pkValues []string, details changeDetails) error {
+	if len(pkValues) != 1 {
+		return fmt.Errorf("unexpected primary key for namespace: %v", pkValues)
+	}
+
+	if details.After == nil {
+		pending.DeletedNamespaces = append(pending.DeletedNamespaces, pkValues[0])
+		return nil
+	}
+
+	configBytes, err := decodeChangefeedBytes(details.After.SerializedNamespaceConfig)
+	if err != nil {
+		return fmt.Errorf("malformed namespace config in changefeed: %w", err)
+	}
+
+	loaded := &core.NamespaceDefinition{}
+	if err := loaded.UnmarshalVT(configBytes); err != nil {
+		return fmt.Errorf(errUnableToReadConfig, err)
+	}
+
+	pending.ChangedDefinitions = append(pending.ChangedDefinitions, loaded)
+	return nil
+}
+
+func addCaveatChange(pending *datastore.RevisionChanges, pkValues []string, details changeDetails) error {
+	if len(pkValues) != 1 {
+		return fmt.Errorf("unexpected primary key for caveat: %v", pkValues)
+	}
+
+	if details.After == nil {
+		pending.DeletedCaveats = append(pending.DeletedCaveats, pkValues[0])
+		return nil
+	}
+
+	definitionBytes, err := decodeChangefeedBytes(details.After.CaveatDefinition)
+	if err != nil {
+		return fmt.Errorf("malformed caveat definition in changefeed: %w", err)
+	}
+
+	loaded := &core.CaveatDefinition{}
+	if err := loaded.UnmarshalVT(definitionBytes); err != nil {
+		return fmt.Errorf(errReadCaveat, pkValues[0], err)
+	}
+
+	pending.ChangedDefinitions = append(pending.ChangedDefinitions, loaded)
+	return nil
+}
+
+// decodeChangefeedBytes decodes a bytes column value as encoded in changefeed JSON.
+func decodeChangefeedBytes(encoded string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(encoded, `\x`))
+}
@@ -0,0 +1,266 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/shopspring/decimal"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var _ common.GarbageCollector = (*memdbDatastore)(nil)
+
+func (mdb *memdbDatastore) Now(_ context.Context) (time.Time, error) {
+	return time.Now().UTC(), nil
+}
+
+func (mdb *memdbDatastore) TxIDBefore(_ context.Context, before time.Time) (datastore.Revision, error) {
+	mdb.RLock()
+	defer mdb.RUnlock()
+
+	found, ok := mdb.snapshotBeforeCallerMustLock(revisionFromTimestamp(before).Decimal)
+	if !ok {
+		return datastore.NoRevision, nil
+	}
+	return revision.NewFromDecimal(found), nil
+}
+
+// DeleteBeforeTx removes the snapshots and changelog entries at revisions up to and including
+// txID, after which those revisions can no longer be read or watched. The snapshot at the head
+// revision is always kept.
+func (mdb *memdbDatastore) DeleteBeforeTx(_ context.Context, txID datastore.Revision) (common.DeletionCounts, error) {
+	if txID == datastore.NoRevision {
+		return common.DeletionCounts{}, nil
+	}
+
+	watermark, ok := txID.(revision.Decimal)
+	if !ok {
+		return common.DeletionCounts{}, fmt.Errorf("cannot garbage collect revision of type %T", txID)
+	}
+
+	mdb.Lock()
+	defer mdb.Unlock()
+
+	if mdb.db == nil {
+		return common.DeletionCounts{}, fmt.Errorf("datastore is closed")
+	}
+
+	// The changelog can only be modified in a write transaction, which cannot be started
+	// until the one already open has finished.
+	if mdb.activeWriteTxn != nil {
+		return common.DeletionCounts{}, errSerialization
+	}
+
+	tx := mdb.db.Txn(true)
+	counts, err := mdb.collectCallerMustLock(tx, watermark.Decimal)
+	if err != nil {
+		tx.Abort()
+		return counts, err
+	}
+	tx.Commit()
+
+	// The head snapshot still references the removed changelog entries, so it is replaced with
+	// one of the same state without them.
+	mdb.revisions[len(mdb.revisions)-1].db = mdb.db.Snapshot()
+	return counts, nil
+}
+
+// snapshotBeforeCallerMustLock returns the revision of the latest snapshot before the given
+// revision, if any.
+func (mdb *memdbDatastore) snapshotBeforeCallerMustLock(before decimal.Decimal) (decimal.Decimal, bool) {
+	index := sort.Search(len(mdb.revisions), func(i int) bool {
+		return mdb.revisions[i].revision.GreaterThanOrEqual(before)
+	})
+	if index == 0 {
+		return decimal.Zero, false
+	}
+	return mdb.revisions[index-1].revision, true
+}
+
+// collectCallerMustLock deletes the changelog entries up to and including the watermark in the
+// given write transaction, and drops the snapshots up to and including the watermark other than
+// the one at the head revision.
+func (mdb *memdbDatastore) collectCallerMustLock(tx *memdb.Txn, watermark decimal.Decimal) (common.DeletionCounts, error) {
+	var counts common.DeletionCounts
+
+	it, err := tx.Get(tableChangelog, indexRevision)
+	if err != nil {
+		return counts, fmt.Errorf("unable to read changelog: %w", err)
+	}
+
+	var collected []*changelog
+	for changeRaw := it.Next(); changeRaw != nil; changeRaw = it.Next() {
+		change := changeRaw.(*changelog)
+		if change.revisionNanos > watermark.IntPart() {
+			break
+		}
+		collected = append(collected, change)
+	}
+
+	for _, change := range collected {
+		if err := tx.Delete(tableChangelog, change); err != nil {
+			return counts, fmt.Errorf("unable to delete changelog entry: %w", err)
+		}
+
+		counts.Transactions++
+		counts.Namespaces += int64(len(change.changes.DeletedNamespaces))
+		for _, update := range change.changes.Changes {
+			if update.Operation == core.RelationTupleUpdate_DELETE {
+				counts.Relationships++
+			}
+		}
+	}
+
+	kept := sort.Search(len(mdb.revisions)-1, func(i int) bool {
+		return mdb.revisions[i].revision.GreaterThan(watermark)
+	})
+	for i := 0; i < kept; i++ {
+		// Clear the dropped snapshots so that their state can be freed before the slice is
+		// next reallocated.
+		mdb.revisions[i] = snapshot{}
+	}
+	mdb.revisions = mdb.revisions[kept:]
+
+	if watermark.GreaterThan(mdb.gcWatermark) {
+		mdb.gcWatermark = watermark
+	}
+	return counts, nil
+}
+
+// collectOutsideGCWindowCallerMustLock collects the revisions which have fallen outside of the
+// GC window as part of the given write transaction.
+func (mdb *memdbDatastore) collectOutsideGCWindowCallerMustLock(tx *memdb.Txn) error {
+	oldest := revisionFromTimestamp(time.Now().UTC()).Add(mdb.negativeGCWindow)
+	watermark, ok := mdb.snapshotBeforeCallerMustLock(oldest)
+	if !ok || !watermark.GreaterThan(mdb.gcWatermark) {
+		return nil
+	}
+
+	_, err := mdb.collectCallerMustLock(tx, watermark)
+	return err
+}
+
+// DeleteExpiredRels deletes all relationships which have expired, returning the number of
+// relationships deleted. The deletions are made in a new revision, so that watchers are
+// notified of the removal of each expired relationship.
+func (mdb *memdbDatastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	// Check for expired relationships before starting a transaction, to avoid creating empty
+	// revisions when there is nothing to remove.
+	mdb.RLock()
+	db := mdb.db
+	mdb.RUnlock()
+	if db == nil {
+		return 0, fmt.Errorf("datastore is closed")
+	}
+
+	expired, err := expiredRelationships(db.Txn(false), now)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	var removed int64
+	_, err = mdb.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		memdbRWT := rwt.(*memdbReadWriteTx)
+		memdbRWT.mustLock()
+		defer memdbRWT.Unlock()
+
+		tx, err := memdbRWT.txSource()
+		if err != nil {
+			return err
+		}
+
+		expired, err := expiredRelationships(tx, now)
+		if err != nil {
+			return err
+		}
+
+		removed = int64(len(expired))
+		return memdbRWT.write(tx, expired...)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// expiredRelationshipsInterval is the interval at which expired relationships are deleted by
+// memdb datastores which are not collected by another datastore.
+var expiredRelationshipsInterval = 1 * time.Minute
+
+// startExpiredRelationshipsCollector starts deleting expired relationships at the given
+// interval, until the datastore is closed. Expired relationships are hidden from reads as soon
+// as they expire; deleting them notifies watchers and frees their memory.
+func (mdb *memdbDatastore) startExpiredRelationshipsCollector(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	mdb.stopExpiredRelationshipsCollector = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				removed, err := mdb.DeleteExpiredRels(ctx)
+				if err != nil {
+					log.Warn().Err(err).Msg("error deleting expired relationships")
+					continue
+				}
+
+				if removed > 0 {
+					log.Debug().Int64("relationships", removed).Msg("deleted expired relationships")
+				}
+			}
+		}
+	}()
+}
+
+// expiredRelationships returns deletion mutations for all relationships which have expired
+// as of the given time.
+func expiredRelationships(tx *memdb.Txn, now time.Time) ([]*core.RelationTupleUpdate, error) {
+	iter, err := tx.Get(tableRelationship, indexID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read relationships: %w", err)
+	}
+
+	var expired []*core.RelationTupleUpdate
+	for row := iter.Next(); row != nil; row = iter.Next() {
+		rel := row.(*relationship)
+		if !rel.isExpired(now) {
+			continue
+		}
+
+		rt, err := rel.RelationTuple()
+		if err != nil {
+			return nil, err
+		}
+		expired = append(expired, tuple.Delete(rt))
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,183 @@
+package memdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestGarbageCollection(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(err)
+	defer ds.Close()
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("resource", ns.MustRelation("reader", nil)), ns.Namespace("user"))
+	})
+	require.NoError(err)
+
+	written, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("resource:first#reader@user:tom")),
+			tuple.Create(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	deleted, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{
+			tuple.Delete(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	head, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("resource:third#reader@user:tom")),
+		})
+	})
+	require.NoError(err)
+
+	gc := ds.(common.GarbageCollector)
+	watermark, err := gc.TxIDBefore(ctx, time.Now().Add(time.Hour))
+	require.NoError(err)
+	require.True(watermark.Equal(head))
+
+	counts, err := gc.DeleteBeforeTx(ctx, deleted)
+	require.NoError(err)
+	require.Equal(common.DeletionCounts{Relationships: 1, Transactions: 3}, counts)
+
+	// Collected revisions are rejected even though they are within the GC window.
+	require.ErrorAs(ds.CheckRevision(ctx, written), &datastore.ErrInvalidRevision{})
+	require.ErrorAs(ds.CheckRevision(ctx, deleted), &datastore.ErrInvalidRevision{})
+	require.NoError(ds.CheckRevision(ctx, head))
+
+	_, err = ds.SnapshotReader(deleted).QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "resource"})
+	require.Error(err)
+
+	// Watches can still be started after the watermark, but not before it.
+	_, errs := ds.Watch(ctx, written, datastore.WatchJustRelationships())
+	require.ErrorAs(<-errs, &datastore.ErrInvalidRevision{})
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	changes, errs := ds.Watch(watchCtx, deleted, datastore.WatchJustRelationships())
+	change := <-changes
+	require.True(change.Revision.Equal(head))
+	cancel()
+	require.Error(<-errs)
+
+	// Collecting the head revision keeps its snapshot.
+	_, err = gc.DeleteBeforeTx(ctx, head)
+	require.NoError(err)
+	require.NoError(ds.CheckRevision(ctx, head))
+
+	iter, err := ds.SnapshotReader(head).QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "resource"})
+	require.NoError(err)
+	defer iter.Close()
+
+	var found []string
+	for rt := iter.Next(); rt != nil; rt = iter.Next() {
+		found = append(found, tuple.MustString(rt))
+	}
+	require.NoError(iter.Err())
+	require.ElementsMatch([]string{"resource:first#reader@user:tom", "resource:third#reader@user:tom"}, found)
+}
+
+func TestGarbageCollectionOutsideGCWindow(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, 100*time.Millisecond)
+	require.NoError(err)
+	defer ds.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+			return rwt.WriteNamespaces(ctx, ns.Namespace("user"))
+		})
+		require.NoError(err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	// Writes collect the revisions which have fallen outside of the GC window.
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("user"))
+	})
+	require.NoError(err)
+
+	mdb := ds.(*memdbDatastore)
+	mdb.RLock()
+	defer mdb.RUnlock()
+
+	require.Len(mdb.revisions, 2)
+	changelog, err := mdb.db.Txn(false).Get(tableChangelog, indexRevision)
+	require.NoError(err)
+
+	var remaining int
+	for change := changelog.Next(); change != nil; change = changelog.Next() {
+		remaining++
+	}
+	require.Equal(1, remaining)
+}
+
+func TestExpiredRelationshipsCollector(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	defaultInterval := expiredRelationshipsInterval
+	expiredRelationshipsInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		expiredRelationshipsInterval = defaultInterval
+	})
+
+	// No collector is started when garbage collection is disabled.
+	disabled, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(err)
+	require.Nil(disabled.(*memdbDatastore).stopExpiredRelationshipsCollector)
+	require.NoError(disabled.Close())
+
+	ds, err := NewMemdbDatastore(0, 0, 1*time.Hour)
+	require.NoError(err)
+	defer ds.Close()
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("resource", ns.MustRelation("reader", nil)), ns.Namespace("user"))
+	})
+	require.NoError(err)
+
+	expiring := tuple.MustWithExpiration(tuple.MustParse("resource:first#reader@user:tom"), time.Now().Add(100*time.Millisecond))
+	written, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{
+			tuple.Create(expiring),
+			tuple.Create(tuple.MustParse("resource:second#reader@user:sarah")),
+		})
+	})
+	require.NoError(err)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes, errchan := ds.Watch(watchCtx, written, datastore.WatchJustRelationships())
+	select {
+	case change := <-changes:
+		require.Len(change.Changes, 1)
+		require.Equal(corev1.RelationTupleUpdate_DELETE, change.Changes[0].Operation)
+		require.Equal(tuple.StringWithoutCaveat(expiring), tuple.StringWithoutCaveat(change.Changes[0].Tuple))
+	case err := <-errchan:
+		require.Fail("unexpected watch error", err)
+	case <-time.After(5 * time.Second):
+		require.Fail("timed out waiting for the expired relationship to be deleted")
+	}
+}
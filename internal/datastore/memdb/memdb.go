@@ -37,6 +37,20 @@ func NewMemdbDatastore(
 	revisionQuantization,
 	gcWindow time.Duration,
 ) (datastore.Datastore, error) {
+	return newMemdbDatastore(
+		watchBufferLength,
+		revisionQuantization,
+		gcWindow,
+		revisionFromTimestamp(time.Now().UTC()).Decimal,
+	)
+}
+
+func newMemdbDatastore(
+	watchBufferLength uint16,
+	revisionQuantization,
+	gcWindow time.Duration,
+	initialRevision decimal.Decimal,
+) (*memdbDatastore, error) {
 	if revisionQuantization > gcWindow {
 		return nil, errors.New("gc window must be larger than quantization interval")
 	}
@@ -58,11 +72,11 @@ func NewMemdbDatastore(
 
 	negativeGCWindow := decimal.NewFromInt(gcWindow.Nanoseconds()).Mul(decimal.NewFromInt(-1))
 
-	return &memdbDatastore{
+	mdb := &memdbDatastore{
 		db: db,
 		revisions: []snapshot{
 			{
-				revision: revisionFromTimestamp(time.Now().UTC()).Decimal,
+				revision: initialRevision,
 				db:       db,
 			},
 		},
@@ -71,7 +85,13 @@ func NewMemdbDatastore(
 		quantizationPeriod: decimal.NewFromInt(revisionQuantization.Nanoseconds()),
 		watchBufferLength:  watchBufferLength,
 		uniqueID:           uniqueID,
-	}, nil
+	}
+
+	if gcWindow != DisableGC {
+		mdb.startExpiredRelationshipsCollector(expiredRelationshipsInterval)
+	}
+
+	return mdb, nil
 }
 
 type memdbDatastore struct {
@@ -83,9 +103,14 @@ type memdbDatastore struct {
 	activeWriteTxn *memdb.Txn
 
 	negativeGCWindow   decimal.Decimal
+	gcWatermark        decimal.Decimal
 	quantizationPeriod decimal.Decimal
 	watchBufferLength  uint16
 	uniqueID           string
+	snapshotPath       string
+
+	stopExpiredRelationshipsCollector func()
+	stopSnapshotFileWriter            func()
 }
 
 type snapshot struct {
@@ -187,60 +212,118 @@ func (mdb *memdbDatastore) ReadWriteTx(
 		mdb.Lock()
 		defer mdb.Unlock()
 
-		// Record the changes that were made
-		newChanges := datastore.RevisionChanges{
-			Revision: newRevision,
-			Changes:  nil,
+		newChanges, err := changesForTxn(tx, newRevision)
+		if err != nil {
+			return datastore.NoRevision, err
+		}
+
+		if err := mdb.commitCallerMustLock(tx, newChanges); err != nil {
+			return datastore.NoRevision, err
 		}
-		if tx != nil {
-			for _, change := range tx.Changes() {
-				if change.Table == tableRelationship {
-					if change.After != nil {
-						rt, err := change.After.(*relationship).RelationTuple()
-						if err != nil {
-							return datastore.NoRevision, err
-						}
-						newChanges.Changes = append(newChanges.Changes, &corev1.RelationTupleUpdate{
-							Operation: corev1.RelationTupleUpdate_TOUCH,
-							Tuple:     rt,
-						})
-					}
-					if change.After == nil && change.Before != nil {
-						rt, err := change.Before.(*relationship).RelationTuple()
-						if err != nil {
-							return datastore.NoRevision, err
-						}
-						newChanges.Changes = append(newChanges.Changes, &corev1.RelationTupleUpdate{
-							Operation: corev1.RelationTupleUpdate_DELETE,
-							Tuple:     rt,
-						})
-					}
+		return newRevision, nil
+	}
+
+	return datastore.NoRevision, errors.New("serialization max retries exceeded")
+}
+
+// changesForTxn records the changes made by the transaction, which may be nil if nothing was
+// written.
+func changesForTxn(tx *memdb.Txn, newRevision revision.Decimal) (*datastore.RevisionChanges, error) {
+	newChanges := &datastore.RevisionChanges{
+		Revision: newRevision,
+		Changes:  nil,
+	}
+	if tx == nil {
+		return newChanges, nil
+	}
+
+	for _, change := range tx.Changes() {
+		switch change.Table {
+		case tableRelationship:
+			if change.After != nil {
+				rt, err := change.After.(*relationship).RelationTuple()
+				if err != nil {
+					return nil, err
 				}
+				newChanges.Changes = append(newChanges.Changes, &corev1.RelationTupleUpdate{
+					Operation: corev1.RelationTupleUpdate_TOUCH,
+					Tuple:     rt,
+				})
+			}
+			if change.After == nil && change.Before != nil {
+				rt, err := change.Before.(*relationship).RelationTuple()
+				if err != nil {
+					return nil, err
+				}
+				newChanges.Changes = append(newChanges.Changes, &corev1.RelationTupleUpdate{
+					Operation: corev1.RelationTupleUpdate_DELETE,
+					Tuple:     rt,
+				})
 			}
 
-			change := &changelog{
-				revisionNanos: newRevision.IntPart(),
-				changes:       newChanges,
+		case tableNamespace:
+			if change.After != nil {
+				loaded := &corev1.NamespaceDefinition{}
+				if err := loaded.UnmarshalVT(change.After.(*namespace).configBytes); err != nil {
+					return nil, err
+				}
+				newChanges.ChangedDefinitions = append(newChanges.ChangedDefinitions, loaded)
 			}
-			if err := tx.Insert(tableChangelog, change); err != nil {
-				return datastore.NoRevision, fmt.Errorf("error writing changelog: %w", err)
+			if change.After == nil && change.Before != nil {
+				newChanges.DeletedNamespaces = append(newChanges.DeletedNamespaces, change.Before.(*namespace).name)
 			}
 
-			tx.Commit()
+		case tableCaveats:
+			if change.After != nil {
+				loaded, err := change.After.(*caveat).Unwrap()
+				if err != nil {
+					return nil, err
+				}
+				newChanges.ChangedDefinitions = append(newChanges.ChangedDefinitions, loaded)
+			}
+			if change.After == nil && change.Before != nil {
+				newChanges.DeletedCaveats = append(newChanges.DeletedCaveats, change.Before.(*caveat).name)
+			}
 		}
-		mdb.activeWriteTxn = nil
+	}
 
-		// Create a snapshot and add it to the revisions slice
-		if mdb.db == nil {
-			return datastore.NoRevision, fmt.Errorf("datastore has been closed")
+	return newChanges, nil
+}
+
+// commitCallerMustLock commits the transaction, which may be nil if nothing was written,
+// along with its changelog entry and the collection of any revisions which have fallen
+// outside of the GC window, and creates a snapshot at the new revision.
+func (mdb *memdbDatastore) commitCallerMustLock(tx *memdb.Txn, changes *datastore.RevisionChanges) error {
+	newRevision := changes.Revision.(revision.Decimal)
+	if tx != nil {
+		change := &changelog{
+			revisionNanos: newRevision.IntPart(),
+			changes:       *changes,
+		}
+		if err := tx.Insert(tableChangelog, change); err != nil {
+			tx.Abort()
+			mdb.activeWriteTxn = nil
+			return fmt.Errorf("error writing changelog: %w", err)
 		}
 
-		snap := mdb.db.Snapshot()
-		mdb.revisions = append(mdb.revisions, snapshot{newRevision.Decimal, snap})
-		return newRevision, nil
+		if err := mdb.collectOutsideGCWindowCallerMustLock(tx); err != nil {
+			tx.Abort()
+			mdb.activeWriteTxn = nil
+			return fmt.Errorf("error collecting revisions outside of the gc window: %w", err)
+		}
+
+		tx.Commit()
 	}
+	mdb.activeWriteTxn = nil
 
-	return datastore.NoRevision, errors.New("serialization max retries exceeded")
+	// Create a snapshot and add it to the revisions slice
+	if mdb.db == nil {
+		return fmt.Errorf("datastore has been closed")
+	}
+
+	snap := mdb.db.Snapshot()
+	mdb.revisions = append(mdb.revisions, snapshot{newRevision.Decimal, snap})
+	return nil
 }
 
 func (mdb *memdbDatastore) ReadyState(_ context.Context) (datastore.ReadyState, error) {
@@ -258,9 +341,30 @@ func (mdb *memdbDatastore) Features(_ context.Context) (*datastore.Features, err
 }
 
 func (mdb *memdbDatastore) Close() error {
+	// The collector takes the lock to delete relationships, so it must be stopped first.
+	if mdb.stopExpiredRelationshipsCollector != nil {
+		mdb.stopExpiredRelationshipsCollector()
+	}
+
+	// The final snapshot is written below, and must not be replaced by a periodic one.
+	if mdb.stopSnapshotFileWriter != nil {
+		mdb.stopSnapshotFileWriter()
+	}
+
 	mdb.Lock()
 	defer mdb.Unlock()
 
+	var snapshotErr error
+	if mdb.snapshotPath != "" && mdb.db != nil {
+		head, err := mdb.headSnapshotCallerMustLock()
+		if err == nil {
+			err = writeSnapshotFile(mdb.snapshotPath, head)
+		}
+		if err != nil {
+			snapshotErr = fmt.Errorf("unable to write memdb snapshot file: %w", err)
+		}
+	}
+
 	// TODO Make this nil once we have removed all access to closed datastores
 	if db := mdb.db; db != nil {
 		mdb.revisions = []snapshot{
@@ -275,7 +379,7 @@ func (mdb *memdbDatastore) Close() error {
 
 	mdb.db = nil
 
-	return nil
+	return snapshotErr
 }
 
 var _ datastore.Datastore = &memdbDatastore{}
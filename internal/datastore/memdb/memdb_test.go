@@ -25,6 +25,7 @@ func (mdbt memDBTest) New(revisionQuantization, _, gcWindow time.Duration, watch
 
 func TestMemdbDatastore(t *testing.T) {
 	test.All(t, memDBTest{})
+	t.Run("TestWatchSchemaDeletion", func(t *testing.T) { test.WatchSchemaDeletionTest(t, memDBTest{}) })
 }
 
 func TestConcurrentWritePanic(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/hashicorp/go-memdb"
 	"github.com/jzelinskie/stringz"
@@ -91,11 +92,11 @@ func (r *memdbReader) ReverseQueryRelationships(
 
 	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
 
-	iterator, err := tx.Get(
-		tableRelationship,
-		indexSubjectNamespace,
-		subjectsFilter.SubjectType,
-	)
+	if queryOpts.AfterForReverse != nil && queryOpts.SortForReverse == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
+
+	iterator, err := iteratorForReverseQuery(tx, subjectsFilter, queryOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -113,11 +114,11 @@ func (r *memdbReader) ReverseQueryRelationships(
 		[]datastore.SubjectsSelector{subjectsFilter.AsSelector()},
 		"",
 		nil,
-		noopCursorFilter,
+		makeCursorFilterFn(queryOpts.AfterForReverse, queryOpts.SortForReverse),
 	)
 	filteredIterator := memdb.NewFilterIterator(iterator, matchingRelationshipsFilterFunc)
 
-	return newMemdbTupleIterator(filteredIterator, queryOpts.ReverseLimit, options.Unsorted), nil
+	return newMemdbTupleIterator(filteredIterator, queryOpts.ReverseLimit, queryOpts.SortForReverse), nil
 }
 
 // ReadNamespace reads a namespace definition and version and returns it, and the revision at
@@ -262,6 +263,20 @@ func iteratorForFilter(txn *memdb.Txn, filter datastore.RelationshipsFilter) (me
 	return iter, err
 }
 
+// iteratorForReverseQuery returns the index iterator for a reverse query. When sorting is
+// requested, the iterator walks an index ordered by resource, rather than the subject index.
+func iteratorForReverseQuery(txn *memdb.Txn, subjectsFilter datastore.SubjectsFilter, queryOpts *options.ReverseQueryOptions) (memdb.ResultIterator, error) {
+	if queryOpts.SortForReverse == options.Unsorted {
+		return txn.Get(tableRelationship, indexSubjectNamespace, subjectsFilter.SubjectType)
+	}
+
+	if queryOpts.ResRelation != nil {
+		return txn.Get(tableRelationship, indexNamespaceAndRelation, queryOpts.ResRelation.Namespace, queryOpts.ResRelation.Relation)
+	}
+
+	return txn.Get(tableRelationship, indexID)
+}
+
 func filterFuncForFilters(
 	optionalResourceType string,
 	optionalResourceIds []string,
@@ -271,10 +286,13 @@ func filterFuncForFilters(
 	usersets []*core.ObjectAndRelation,
 	cursorFilter func(*relationship) bool,
 ) memdb.FilterFunc {
+	now := time.Now()
 	return func(tupleRaw interface{}) bool {
 		tuple := tupleRaw.(*relationship)
 
 		switch {
+		case tuple.isExpired(now):
+			return true
 		case optionalResourceType != "" && optionalResourceType != tuple.namespace:
 			return true
 		case len(optionalResourceIds) > 0 && !stringz.SliceContains(optionalResourceIds, tuple.resourceID):
@@ -3,6 +3,7 @@ package memdb
 import (
 	"context"
 	"fmt"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/hashicorp/go-memdb"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
@@ -44,6 +46,8 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 			mutation.Tuple.Subject.ObjectId,
 			mutation.Tuple.Subject.Relation,
 			rwt.toCaveatReference(mutation),
+			common.ExpirationTimeOf(mutation.Tuple),
+			mutation.Tuple.Integrity.CloneVT(),
 		}
 
 		found, err := tx.First(
@@ -67,7 +71,8 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 
 		switch mutation.Operation {
 		case core.RelationTupleUpdate_CREATE:
-			if existing != nil {
+			// An expired relationship which has not yet been reaped is replaced.
+			if existing != nil && !existing.isExpired(time.Now()) {
 				rt, err := existing.RelationTuple()
 				if err != nil {
 					return err
@@ -79,11 +84,19 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 			if err := tx.Insert(tableRelationship, rel); err != nil {
 				return fmt.Errorf("error inserting relationship: %w", err)
 			}
+			if existing == nil {
+				if err := adjustRelationshipCount(tx, rel.namespace, rel.relation, 1); err != nil {
+					return err
+				}
+			}
 		case core.RelationTupleUpdate_DELETE:
 			if existing != nil {
 				if err := tx.Delete(tableRelationship, existing); err != nil {
 					return fmt.Errorf("error deleting relationship: %w", err)
 				}
+				if err := adjustRelationshipCount(tx, existing.namespace, existing.relation, -1); err != nil {
+					return err
+				}
 			}
 		default:
 			return fmt.Errorf("unknown tuple mutation operation type: %s", mutation.Operation)
@@ -93,6 +106,66 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 	return nil
 }
 
+// adjustRelationshipCount adds the delta to the number of relationships stored for the relation.
+func adjustRelationshipCount(tx *memdb.Txn, namespace, relation string, delta int64) error {
+	found, err := tx.First(tableRelationshipCount, indexID, namespace, relation)
+	if err != nil {
+		return fmt.Errorf("error loading relationship count: %w", err)
+	}
+
+	var count uint64
+	if found != nil {
+		count = found.(*relationshipCount).count
+	}
+
+	updated := &relationshipCount{namespace, relation, uint64(int64(count) + delta)}
+	if updated.count == 0 {
+		if found != nil {
+			if err := tx.Delete(tableRelationshipCount, found); err != nil {
+				return fmt.Errorf("error deleting relationship count: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := tx.Insert(tableRelationshipCount, updated); err != nil {
+		return fmt.Errorf("error updating relationship count: %w", err)
+	}
+	return nil
+}
+
+// RelationshipCounts returns the counts of the given definitions. The counts of all definitions
+// are maintained by the memdb datastore, since doing so is cheap in memory.
+func (rwt *memdbReadWriteTx) RelationshipCounts(_ context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	rwt.mustLock()
+	defer rwt.Unlock()
+
+	tx, err := rwt.txSource()
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := tx.Get(tableRelationshipCount, indexID)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]struct{}, len(definitions))
+	for _, definition := range definitions {
+		requested[definition] = struct{}{}
+	}
+
+	counts := make(map[datastore.RelationKey]uint64)
+	for row := it.Next(); row != nil; row = it.Next() {
+		found := row.(*relationshipCount)
+		if _, ok := requested[found.namespace]; !ok {
+			continue
+		}
+		counts[datastore.RelationKey{Definition: found.namespace, Relation: found.relation}] = found.count
+	}
+	return counts, nil
+}
+
 func (rwt *memdbReadWriteTx) toCaveatReference(mutation *core.RelationTupleUpdate) *contextualizedCaveat {
 	var cr *contextualizedCaveat
 	if mutation.Tuple.Caveat != nil {
@@ -104,38 +177,45 @@ func (rwt *memdbReadWriteTx) toCaveatReference(mutation *core.RelationTupleUpdat
 	return cr
 }
 
-func (rwt *memdbReadWriteTx) DeleteRelationships(_ context.Context, filter *v1.RelationshipFilter) error {
+func (rwt *memdbReadWriteTx) DeleteRelationships(_ context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
 	rwt.mustLock()
 	defer rwt.Unlock()
 
 	tx, err := rwt.txSource()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return rwt.deleteWithLock(tx, filter)
+	delOpts := options.NewDeleteOptionsWithOptions(opts...)
+	return rwt.deleteWithLock(tx, filter, delOpts.DeleteLimit)
 }
 
 // caller must already hold the concurrent access lock
-func (rwt *memdbReadWriteTx) deleteWithLock(tx *memdb.Txn, filter *v1.RelationshipFilter) error {
+func (rwt *memdbReadWriteTx) deleteWithLock(tx *memdb.Txn, filter *v1.RelationshipFilter, limit *uint64) (bool, error) {
 	// Create an iterator to find the relevant tuples
 	bestIter, err := iteratorForFilter(tx, datastore.RelationshipsFilterFromPublicFilter(filter))
 	if err != nil {
-		return err
+		return false, err
 	}
 	filteredIter := memdb.NewFilterIterator(bestIter, relationshipFilterFilterFunc(filter))
 
 	// Collect the tuples into a slice of mutations for the changelog
 	var mutations []*core.RelationTupleUpdate
+	limitReached := false
 	for row := filteredIter.Next(); row != nil; row = filteredIter.Next() {
 		rt, err := row.(*relationship).RelationTuple()
 		if err != nil {
-			return err
+			return false, err
 		}
 		mutations = append(mutations, tuple.Delete(rt))
+
+		if limit != nil && uint64(len(mutations)) >= *limit {
+			limitReached = true
+			break
+		}
 	}
 
-	return rwt.write(tx, mutations...)
+	return limitReached, rwt.write(tx, mutations...)
 }
 
 func (rwt *memdbReadWriteTx) WriteNamespaces(_ context.Context, newConfigs ...*core.NamespaceDefinition) error {
@@ -188,9 +268,9 @@ func (rwt *memdbReadWriteTx) DeleteNamespaces(_ context.Context, nsNames ...stri
 		}
 
 		// Delete the relationships from the namespace
-		if err := rwt.deleteWithLock(tx, &v1.RelationshipFilter{
+		if _, err := rwt.deleteWithLock(tx, &v1.RelationshipFilter{
 			ResourceType: nsName,
-		}); err != nil {
+		}, nil); err != nil {
 			return fmt.Errorf("unable to delete relationships from deleted namespace: %w", err)
 		}
 	}
@@ -198,6 +278,26 @@ func (rwt *memdbReadWriteTx) DeleteNamespaces(_ context.Context, nsNames ...stri
 	return nil
 }
 
+func (rwt *memdbReadWriteTx) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var numCopied uint64
+	var next *core.RelationTuple
+	var err error
+
+	updates := []*core.RelationTupleUpdate{{
+		Operation: core.RelationTupleUpdate_CREATE,
+	}}
+
+	for next, err = iter.Next(ctx); next != nil && err == nil; next, err = iter.Next(ctx) {
+		updates[0].Tuple = next
+		if err := rwt.WriteRelationships(ctx, updates); err != nil {
+			return 0, err
+		}
+		numCopied++
+	}
+
+	return numCopied, err
+}
+
 func relationshipFilterFilterFunc(filter *v1.RelationshipFilter) func(interface{}) bool {
 	return func(tupleRaw interface{}) bool {
 		tuple := tupleRaw.(*relationship)
@@ -0,0 +1,88 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// Replay applies changes made by an earlier instance of the datastore at their original
+// revision, which must be after the current head revision.
+func (mdb *memdbDatastore) Replay(ctx context.Context, changes *datastore.RevisionChanges) error {
+	replayedRevision, ok := changes.Revision.(revision.Decimal)
+	if !ok {
+		return fmt.Errorf("cannot replay changes at revision of type %T", changes.Revision)
+	}
+
+	mdb.Lock()
+	defer mdb.Unlock()
+
+	if mdb.db == nil {
+		return fmt.Errorf("datastore is closed")
+	}
+
+	if mdb.activeWriteTxn != nil {
+		return errSerialization
+	}
+
+	if head := mdb.headRevisionNoLock(); !replayedRevision.Decimal.GreaterThan(head) {
+		return fmt.Errorf("cannot replay revision %s at or before the head revision %s", replayedRevision, head)
+	}
+
+	tx := mdb.db.Txn(true)
+	mdb.activeWriteTxn = tx
+
+	rwt := &memdbReadWriteTx{
+		memdbReader{noopTryLocker{}, func() (*memdb.Txn, error) { return tx, nil }, nil},
+		replayedRevision,
+	}
+	if err := replayChanges(ctx, rwt, tx, changes); err != nil {
+		tx.Abort()
+		mdb.activeWriteTxn = nil
+		return fmt.Errorf("unable to replay revision %s: %w", replayedRevision, err)
+	}
+
+	return mdb.commitCallerMustLock(tx, changes)
+}
+
+func replayChanges(ctx context.Context, rwt *memdbReadWriteTx, tx *memdb.Txn, changes *datastore.RevisionChanges) error {
+	var namespaces []*core.NamespaceDefinition
+	var caveats []*core.CaveatDefinition
+	for _, def := range changes.ChangedDefinitions {
+		switch typed := def.(type) {
+		case *core.NamespaceDefinition:
+			namespaces = append(namespaces, typed)
+		case *core.CaveatDefinition:
+			caveats = append(caveats, typed)
+		default:
+			return fmt.Errorf("unknown schema definition type %T", def)
+		}
+	}
+
+	if err := rwt.WriteNamespaces(ctx, namespaces...); err != nil {
+		return err
+	}
+
+	if err := rwt.writeCaveat(tx, caveats); err != nil {
+		return err
+	}
+
+	// The deletion of the relationships of deleted namespaces is recorded in the changes, so
+	// the namespaces themselves are removed only after the relationships.
+	if err := rwt.write(tx, changes.Changes...); err != nil {
+		return err
+	}
+
+	for _, nsName := range changes.DeletedNamespaces {
+		if _, err := tx.DeleteAll(tableNamespace, indexID, nsName); err != nil {
+			return err
+		}
+	}
+
+	return rwt.DeleteCaveats(ctx, changes.DeletedCaveats)
+}
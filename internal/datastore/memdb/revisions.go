@@ -26,13 +26,16 @@ func (mdb *memdbDatastore) newRevisionID() revision.Decimal {
 	// and the result of a ReadWriteTx could return the *same* transaction ID
 	// if both are executed in sequence without any other forms of delay on
 	// macOS. We therefore check if the created transaction ID matches that
-	// previously created and, if not, add to it.
+	// previously created and, if so, add to it.
 	//
 	// See: https://github.com/golang/go/issues/22037 which appeared to fix
 	// this in Go 1.9.2, but there appears to have been a reversion with either
 	// the new version of macOS or Go.
-	if created.Equals(existing) {
-		return revision.NewFromDecimal(created.Add(decimal.NewFromInt(1)))
+	//
+	// Replayed revisions may also have been created by a clock which was ahead
+	// of this one, so the transaction ID is never allowed to move backwards.
+	if created.LessThanOrEqual(existing) {
+		return revision.NewFromDecimal(existing.Add(decimal.NewFromInt(1)))
 	}
 	return revision.NewFromDecimal(created)
 }
@@ -82,6 +85,12 @@ func (mdb *memdbDatastore) checkRevisionLocalCallerMustLock(revisionRaw revision
 		return datastore.NewInvalidRevisionErr(revisionRaw, datastore.RevisionStale)
 	}
 
+	// Revisions collected by DeleteBeforeTx may still be within the GC window, but their
+	// snapshots are gone.
+	if mdb.revisionCollected(revisionRaw) {
+		return datastore.NewInvalidRevisionErr(revisionRaw, datastore.RevisionStale)
+	}
+
 	// If the revision <= now and later than the GC window, it is assumed to be valid, even if
 	// HEAD revision is behind it.
 	if revisionRaw.GreaterThan(now) {
@@ -106,3 +115,11 @@ func (mdb *memdbDatastore) revisionOutsideGCWindow(now revision.Decimal, revisio
 	oldest := revision.NewFromDecimal(now.Add(mdb.negativeGCWindow))
 	return revisionRaw.LessThan(oldest)
 }
+
+func (mdb *memdbDatastore) revisionCollected(revisionRaw revision.Decimal) bool {
+	// the snapshot at the head revision is never collected
+	if revisionRaw.Equals(mdb.headRevisionNoLock()) {
+		return false
+	}
+	return revisionRaw.LessThanOrEqual(mdb.gcWatermark)
+}
@@ -1,12 +1,15 @@
 package memdb
 
 import (
+	"time"
+
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/hashicorp/go-memdb"
 	"github.com/jzelinskie/stringz"
 	"github.com/rs/zerolog"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
@@ -22,6 +25,8 @@ const (
 
 	tableChangelog = "changelog"
 	indexRevision  = "id"
+
+	tableRelationshipCount = "relationshipCount"
 )
 
 type namespace struct {
@@ -42,6 +47,16 @@ type relationship struct {
 	subjectObjectID  string
 	subjectRelation  string
 	caveat           *contextualizedCaveat
+	expiration       *time.Time
+	integrity        *core.RelationshipIntegrity
+}
+
+// relationshipCount is the number of relationships stored for a relation, maintained as
+// relationships are written and deleted.
+type relationshipCount struct {
+	namespace string
+	relation  string
+	count     uint64
 }
 
 type contextualizedCaveat struct {
@@ -69,7 +84,17 @@ func (r relationship) String() string {
 		caveat = "[" + r.caveat.caveatName + "]"
 	}
 
-	return r.namespace + ":" + r.resourceID + "#" + r.relation + "@" + r.subjectNamespace + ":" + r.subjectObjectID + "#" + r.subjectRelation + caveat
+	expiration := ""
+	if r.expiration != nil {
+		expiration = "[expiration:" + r.expiration.Format(time.RFC3339Nano) + "]"
+	}
+
+	return r.namespace + ":" + r.resourceID + "#" + r.relation + "@" + r.subjectNamespace + ":" + r.subjectObjectID + "#" + r.subjectRelation + caveat + expiration
+}
+
+// isExpired returns true if the relationship has an expiration at or before the given time.
+func (r relationship) isExpired(now time.Time) bool {
+	return r.expiration != nil && !r.expiration.After(now)
 }
 
 func (r relationship) MarshalZerologObject(e *zerolog.Event) {
@@ -98,6 +123,7 @@ func (r relationship) RelationTuple() (*core.RelationTuple, error) {
 	if err != nil {
 		return nil, err
 	}
+
 	return &core.RelationTuple{
 		ResourceAndRelation: &core.ObjectAndRelation{
 			Namespace: r.namespace,
@@ -109,7 +135,9 @@ func (r relationship) RelationTuple() (*core.RelationTuple, error) {
 			ObjectId:  r.subjectObjectID,
 			Relation:  r.subjectRelation,
 		},
-		Caveat: cr,
+		Caveat:                 cr,
+		OptionalExpirationTime: common.ExpirationTimeFrom(r.expiration),
+		Integrity:              r.integrity.CloneVT(),
 	}, nil
 }
 
@@ -179,6 +207,21 @@ var schema = &memdb.DBSchema{
 				},
 			},
 		},
+		tableRelationshipCount: {
+			Name: tableRelationshipCount,
+			Indexes: map[string]*memdb.IndexSchema{
+				indexID: {
+					Name:   indexID,
+					Unique: true,
+					Indexer: &memdb.CompoundIndex{
+						Indexes: []memdb.Indexer{
+							&memdb.StringFieldIndex{Field: "namespace"},
+							&memdb.StringFieldIndex{Field: "relation"},
+						},
+					},
+				},
+			},
+		},
 		tableCaveats: {
 			Name: tableCaveats,
 			Indexes: map[string]*memdb.IndexSchema{
@@ -0,0 +1,293 @@
+package memdb
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	implv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Snapshotter is implemented by memdb datastores, which can write their full state to a
+// snapshot that can later be loaded with NewMemdbDatastoreFromSnapshot.
+type Snapshotter interface {
+	// WriteSnapshot writes the namespaces, caveats and relationships of the datastore as of
+	// the head revision, along with the head revision itself.
+	WriteSnapshot(w io.Writer) error
+}
+
+// NewMemdbDatastoreFromSnapshot creates a new memdb datastore holding the state read from a
+// snapshot, as of the revision at which the snapshot was written.
+func NewMemdbDatastoreFromSnapshot(
+	r io.Reader,
+	watchBufferLength uint16,
+	revisionQuantization,
+	gcWindow time.Duration,
+) (datastore.Datastore, error) {
+	mdb, err := newMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow, decimal.Zero)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := readSnapshot(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read memdb snapshot: %w", err)
+	}
+
+	if err := mdb.Replay(context.Background(), contents); err != nil {
+		return nil, fmt.Errorf("unable to load memdb snapshot: %w", err)
+	}
+
+	return mdb, nil
+}
+
+// NewMemdbDatastoreWithSnapshotFile creates a new memdb datastore which is loaded from the
+// snapshot file at the given path, if it exists, and which writes its state to the file
+// at the given interval whenever it has changed, as well as when it is closed. An interval
+// of zero writes the snapshot file only when the datastore is closed.
+func NewMemdbDatastoreWithSnapshotFile(
+	path string,
+	snapshotInterval time.Duration,
+	watchBufferLength uint16,
+	revisionQuantization,
+	gcWindow time.Duration,
+) (datastore.Datastore, error) {
+	var ds datastore.Datastore
+	file, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		ds, err = NewMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow)
+		if err != nil {
+			return nil, err
+		}
+
+	case err != nil:
+		return nil, fmt.Errorf("unable to open memdb snapshot file: %w", err)
+
+	default:
+		defer file.Close()
+
+		start := time.Now()
+		ds, err = NewMemdbDatastoreFromSnapshot(file, watchBufferLength, revisionQuantization, gcWindow)
+		if err != nil {
+			return nil, err
+		}
+		log.Info().Str("path", path).Dur("duration", time.Since(start)).Msg("loaded memdb snapshot file")
+	}
+
+	mdb := ds.(*memdbDatastore)
+	mdb.snapshotPath = path
+	if snapshotInterval > 0 {
+		mdb.startSnapshotFileWriter(snapshotInterval)
+	}
+	return mdb, nil
+}
+
+func (mdb *memdbDatastore) WriteSnapshot(w io.Writer) error {
+	head, err := mdb.headSnapshot()
+	if err != nil {
+		return err
+	}
+
+	return writeSnapshot(w, head)
+}
+
+// headSnapshot returns the snapshot at the head revision. Snapshots are never modified once
+// taken, so the returned snapshot can be read without holding the lock.
+func (mdb *memdbDatastore) headSnapshot() (snapshot, error) {
+	mdb.RLock()
+	defer mdb.RUnlock()
+
+	return mdb.headSnapshotCallerMustLock()
+}
+
+func (mdb *memdbDatastore) headSnapshotCallerMustLock() (snapshot, error) {
+	if mdb.db == nil {
+		return snapshot{}, fmt.Errorf("datastore is closed")
+	}
+
+	return mdb.revisions[len(mdb.revisions)-1], nil
+}
+
+func writeSnapshot(w io.Writer, head snapshot) error {
+	tx := head.db.Txn(false)
+	defer tx.Abort()
+
+	contents := &implv1.MemdbSnapshot{
+		Revision: revision.NewFromDecimal(head.revision).String(),
+	}
+
+	namespaces, err := tx.Get(tableNamespace, indexID)
+	if err != nil {
+		return err
+	}
+	for row := namespaces.Next(); row != nil; row = namespaces.Next() {
+		def := &core.NamespaceDefinition{}
+		if err := def.UnmarshalVT(row.(*namespace).configBytes); err != nil {
+			return err
+		}
+		contents.Namespaces = append(contents.Namespaces, def)
+	}
+
+	caveats, err := tx.Get(tableCaveats, indexID)
+	if err != nil {
+		return err
+	}
+	for row := caveats.Next(); row != nil; row = caveats.Next() {
+		def, err := row.(*caveat).Unwrap()
+		if err != nil {
+			return err
+		}
+		contents.Caveats = append(contents.Caveats, def)
+	}
+
+	relationships, err := tx.Get(tableRelationship, indexID)
+	if err != nil {
+		return err
+	}
+	for row := relationships.Next(); row != nil; row = relationships.Next() {
+		rt, err := row.(*relationship).RelationTuple()
+		if err != nil {
+			return err
+		}
+		contents.Relationships = append(contents.Relationships, rt)
+	}
+
+	serialized, err := contents.MarshalVT()
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(serialized); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// readSnapshot reads a snapshot as the changes which create its state at its revision.
+func readSnapshot(r io.Reader) (*datastore.RevisionChanges, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	serialized, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &implv1.MemdbSnapshot{}
+	if err := contents.UnmarshalVT(serialized); err != nil {
+		return nil, err
+	}
+
+	snapshotRevision, err := revision.DecimalDecoder{}.RevisionFromString(contents.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &datastore.RevisionChanges{
+		Revision: snapshotRevision,
+		Changes:  make([]*core.RelationTupleUpdate, 0, len(contents.Relationships)),
+	}
+	for _, def := range contents.Namespaces {
+		changes.ChangedDefinitions = append(changes.ChangedDefinitions, def)
+	}
+	for _, def := range contents.Caveats {
+		changes.ChangedDefinitions = append(changes.ChangedDefinitions, def)
+	}
+	for _, rt := range contents.Relationships {
+		changes.Changes = append(changes.Changes, tuple.Touch(rt))
+	}
+
+	return changes, nil
+}
+
+// writeSnapshotFile writes the snapshot to a temporary file which then replaces the snapshot
+// file, so that an existing snapshot is never left partially written.
+func writeSnapshotFile(path string, head snapshot) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeSnapshot(tmp, head); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// startSnapshotFileWriter starts writing the snapshot file at the given interval, until the
+// datastore is closed. The file is only rewritten when the head revision has changed since it
+// was last written, and is written without holding the lock, so writes are not blocked while
+// the snapshot is serialized.
+func (mdb *memdbDatastore) startSnapshotFileWriter(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	mdb.stopSnapshotFileWriter = func() {
+		cancel()
+		<-done
+	}
+
+	// The state at startup was either just loaded from the snapshot file or is empty, so it
+	// need not be written until it changes.
+	mdb.RLock()
+	written := mdb.revisions[len(mdb.revisions)-1].revision
+	mdb.RUnlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				head, err := mdb.headSnapshot()
+				if err != nil {
+					log.Warn().Err(err).Msg("error reading memdb head revision")
+					continue
+				}
+
+				if head.revision.Equal(written) {
+					continue
+				}
+
+				start := time.Now()
+				if err := writeSnapshotFile(mdb.snapshotPath, head); err != nil {
+					log.Warn().Err(err).Str("path", mdb.snapshotPath).Msg("error writing memdb snapshot file")
+					continue
+				}
+				written = head.revision
+				log.Debug().Str("path", mdb.snapshotPath).Dur("duration", time.Since(start)).Msg("wrote memdb snapshot file")
+			}
+		}
+	}()
+}
+
+var _ Snapshotter = &memdbDatastore{}
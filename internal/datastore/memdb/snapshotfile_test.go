@@ -0,0 +1,169 @@
+package memdb
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+const snapshotTestContents = `---
+schema: |+
+  definition user {}
+
+  caveat only_on_tuesday(day_of_week string) {
+    day_of_week == 'tuesday'
+  }
+
+  definition document {
+    relation viewer: user | user with only_on_tuesday
+  }
+
+relationships: |-
+  document:firstdoc#viewer@user:tom
+  document:seconddoc#viewer@user:sarah[only_on_tuesday:{"day_of_week":"tuesday"}]
+`
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(err)
+
+	_, written, err := validationfile.PopulateFromFilesContents(ctx, ds, map[string][]byte{"test": []byte(snapshotTestContents)})
+	require.NoError(err)
+
+	var buf bytes.Buffer
+	require.NoError(ds.(Snapshotter).WriteSnapshot(&buf))
+
+	loaded, err := NewMemdbDatastoreFromSnapshot(&buf, 0, 0, DisableGC)
+	require.NoError(err)
+
+	head, err := loaded.HeadRevision(ctx)
+	require.NoError(err)
+	require.True(head.Equal(written))
+
+	requireSameState(ctx, require, ds.SnapshotReader(written), loaded.SnapshotReader(head))
+
+	// Writes to the loaded datastore are made at later revisions.
+	rewritten, err := loaded.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteNamespaces(ctx, "document")
+	})
+	require.NoError(err)
+	require.True(rewritten.GreaterThan(written))
+}
+
+func TestSnapshotFile(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot")
+
+	ds, err := NewMemdbDatastoreWithSnapshotFile(path, 0, 0, 0, DisableGC)
+	require.NoError(err)
+
+	_, written, err := validationfile.PopulateFromFilesContents(ctx, ds, map[string][]byte{"test": []byte(snapshotTestContents)})
+	require.NoError(err)
+
+	// The serialized expression of a caveat differs each time it is compiled, so the file is
+	// compared against the state of the datastore which wrote it, rather than one populated
+	// from the same contents.
+	var expectedSnapshot bytes.Buffer
+	require.NoError(ds.(Snapshotter).WriteSnapshot(&expectedSnapshot))
+	expected, err := NewMemdbDatastoreFromSnapshot(&expectedSnapshot, 0, 0, DisableGC)
+	require.NoError(err)
+
+	// Closing the datastore again must not overwrite the snapshot file.
+	require.NoError(ds.Close())
+	require.NoError(ds.Close())
+
+	reopened, err := NewMemdbDatastoreWithSnapshotFile(path, 0, 0, 0, DisableGC)
+	require.NoError(err)
+	defer reopened.Close()
+
+	head, err := reopened.HeadRevision(ctx)
+	require.NoError(err)
+	require.True(head.Equal(written))
+
+	requireSameState(ctx, require, expected.SnapshotReader(written), reopened.SnapshotReader(head))
+}
+
+func TestSnapshotFileWrittenPeriodically(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot")
+
+	ds, err := NewMemdbDatastoreWithSnapshotFile(path, 10*time.Millisecond, 0, 0, DisableGC)
+	require.NoError(err)
+	defer ds.Close()
+
+	_, written, err := validationfile.PopulateFromFilesContents(ctx, ds, map[string][]byte{"test": []byte(snapshotTestContents)})
+	require.NoError(err)
+
+	// The snapshot file is written without the datastore being closed.
+	require.Eventually(func() bool {
+		file, err := os.Open(path)
+		if err != nil {
+			return false
+		}
+		defer file.Close()
+
+		contents, err := readSnapshot(file)
+		return err == nil && contents.Revision.Equal(written)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	file, err := os.Open(path)
+	require.NoError(err)
+	defer file.Close()
+
+	loaded, err := NewMemdbDatastoreFromSnapshot(file, 0, 0, DisableGC)
+	require.NoError(err)
+
+	head, err := loaded.HeadRevision(ctx)
+	require.NoError(err)
+	requireSameState(ctx, require, ds.SnapshotReader(written), loaded.SnapshotReader(head))
+}
+
+func requireSameState(ctx context.Context, require *require.Assertions, expected, found datastore.Reader) {
+	expectedNamespaces, err := expected.ListAllNamespaces(ctx)
+	require.NoError(err)
+	foundNamespaces, err := found.ListAllNamespaces(ctx)
+	require.NoError(err)
+	require.Len(foundNamespaces, len(expectedNamespaces))
+	for i := range expectedNamespaces {
+		require.True(expectedNamespaces[i].Definition.EqualVT(foundNamespaces[i].Definition))
+	}
+
+	expectedCaveats, err := expected.ListAllCaveats(ctx)
+	require.NoError(err)
+	foundCaveats, err := found.ListAllCaveats(ctx)
+	require.NoError(err)
+	require.Len(foundCaveats, len(expectedCaveats))
+	for i := range expectedCaveats {
+		require.True(expectedCaveats[i].Definition.EqualVT(foundCaveats[i].Definition))
+	}
+
+	require.Equal(relationshipStrings(ctx, require, expected), relationshipStrings(ctx, require, found))
+}
+
+func relationshipStrings(ctx context.Context, require *require.Assertions, reader datastore.Reader) []string {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "document"})
+	require.NoError(err)
+	defer iter.Close()
+
+	var found []string
+	for rt := iter.Next(); rt != nil; rt = iter.Next() {
+		found = append(found, tuple.MustString(rt))
+	}
+	require.NoError(iter.Err())
+	require.Len(found, 2)
+	return found
+}
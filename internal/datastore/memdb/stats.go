@@ -3,6 +3,7 @@ package memdb
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 )
@@ -49,3 +50,47 @@ func (mdb *memdbDatastore) countRelationships(_ context.Context) (uint64, error)
 
 	return count, nil
 }
+
+// DetailedStatistics counts the relationships of each relation exactly. Storage is not
+// reported, as the size of in-memory structures cannot be cheaply measured.
+func (mdb *memdbDatastore) DetailedStatistics(_ context.Context) (datastore.DetailedStats, error) {
+	mdb.RLock()
+	defer mdb.RUnlock()
+
+	if mdb.db == nil {
+		return datastore.DetailedStats{}, fmt.Errorf("datastore is closed")
+	}
+
+	txn := mdb.db.Txn(false)
+	defer txn.Abort()
+
+	relationships, err := txn.LowerBound(tableRelationship, indexID)
+	if err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to count relationships: %w", err)
+	}
+
+	now := time.Now()
+	counts := make(map[datastore.RelationKey]uint64)
+	for row := relationships.Next(); row != nil; row = relationships.Next() {
+		rel := row.(*relationship)
+		if rel.isExpired(now) {
+			continue
+		}
+		counts[datastore.RelationKey{Definition: rel.namespace, Relation: rel.relation}]++
+	}
+
+	changelog, err := txn.Get(tableChangelog, indexRevision)
+	if err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to count changelog entries: %w", err)
+	}
+
+	var backlog uint64
+	for row := changelog.Next(); row != nil; row = changelog.Next() {
+		backlog++
+	}
+
+	return datastore.DetailedStats{
+		Definitions:      datastore.ComputeDefinitionStats(counts),
+		ChangelogBacklog: backlog,
+	}, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/go-memdb"
+	"github.com/shopspring/decimal"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
@@ -13,7 +15,7 @@ import (
 
 const errWatchError = "watch error: %w"
 
-func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
 	ar := afterRevision.(revision.Decimal)
 
 	updates := make(chan *datastore.RevisionChanges, mdb.watchBufferLength)
@@ -24,12 +26,13 @@ func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Re
 		defer close(errs)
 
 		currentTxn := ar.IntPart()
+		lastCheckpoint := time.Now()
 
 		for {
 			var stagedUpdates []*datastore.RevisionChanges
 			var watchChan <-chan struct{}
 			var err error
-			stagedUpdates, currentTxn, watchChan, err = mdb.loadChanges(ctx, currentTxn)
+			stagedUpdates, currentTxn, watchChan, err = mdb.loadChanges(ctx, currentTxn, options)
 			if err != nil {
 				errs <- err
 				return
@@ -45,13 +48,36 @@ func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Re
 				}
 			}
 
-			// Wait for new changes
+			if options.CheckpointInterval > 0 && time.Since(lastCheckpoint) >= options.CheckpointInterval {
+				checkpoint := &datastore.RevisionChanges{
+					Revision:     revision.NewFromDecimal(decimal.NewFromInt(currentTxn)),
+					IsCheckpoint: true,
+				}
+
+				select {
+				case updates <- checkpoint:
+					lastCheckpoint = time.Now()
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
+				}
+			}
+
+			// Wait for new changes, or until the next checkpoint is due
 			ws := memdb.NewWatchSet()
 			ws.Add(watchChan)
 
-			err = ws.WatchCtx(ctx)
+			waitCtx, cancelWait := ctx, context.CancelFunc(func() {})
+			if options.CheckpointInterval > 0 {
+				waitCtx, cancelWait = context.WithDeadline(ctx, lastCheckpoint.Add(options.CheckpointInterval))
+			}
+
+			err = ws.WatchCtx(waitCtx)
+			cancelWait()
 			if err != nil {
 				switch {
+				case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+					continue
 				case errors.Is(err, context.Canceled):
 					errs <- datastore.NewWatchCanceledErr()
 				default:
@@ -65,10 +91,16 @@ func (mdb *memdbDatastore) Watch(ctx context.Context, afterRevision datastore.Re
 	return updates, errs
 }
 
-func (mdb *memdbDatastore) loadChanges(_ context.Context, currentTxn int64) ([]*datastore.RevisionChanges, int64, <-chan struct{}, error) {
+func (mdb *memdbDatastore) loadChanges(_ context.Context, currentTxn int64, options datastore.WatchOptions) ([]*datastore.RevisionChanges, int64, <-chan struct{}, error) {
 	mdb.RLock()
 	defer mdb.RUnlock()
 
+	// Changes up to the GC watermark have been removed from the changelog, so a watch which
+	// has not yet seen them can no longer be continued.
+	if currentTxn < mdb.gcWatermark.IntPart() {
+		return nil, 0, nil, datastore.NewInvalidRevisionErr(revision.NewFromDecimal(decimal.NewFromInt(currentTxn)), datastore.RevisionStale)
+	}
+
 	loadNewTxn := mdb.db.Txn(false)
 	defer loadNewTxn.Abort()
 
@@ -81,8 +113,22 @@ func (mdb *memdbDatastore) loadChanges(_ context.Context, currentTxn int64) ([]*
 	lastRevision := currentTxn
 	for changeRaw := it.Next(); changeRaw != nil; changeRaw = it.Next() {
 		change := changeRaw.(*changelog)
-		changes = append(changes, &change.changes)
 		lastRevision = change.revisionNanos
+
+		// The changelog records all changes, so strip those the watch did not request.
+		filtered := change.changes
+		if !options.Includes(datastore.WatchRelationships) {
+			filtered.Changes = nil
+		}
+		if !options.Includes(datastore.WatchSchema) {
+			filtered.ChangedDefinitions = nil
+			filtered.DeletedNamespaces = nil
+			filtered.DeletedCaveats = nil
+		}
+
+		if len(filtered.Changes) > 0 || filtered.HasSchemaChanges() {
+			changes = append(changes, &filtered)
+		}
 	}
 
 	watchChan, _, err := loadNewTxn.LastWatch(tableChangelog, indexRevision)
@@ -32,21 +32,25 @@ import (
 const (
 	Engine = "mysql"
 
-	colID               = "id"
-	colTimestamp        = "timestamp"
-	colNamespace        = "namespace"
-	colConfig           = "serialized_config"
-	colCreatedTxn       = "created_transaction"
-	colDeletedTxn       = "deleted_transaction"
-	colObjectID         = "object_id"
-	colRelation         = "relation"
-	colUsersetNamespace = "userset_namespace"
-	colUsersetObjectID  = "userset_object_id"
-	colUsersetRelation  = "userset_relation"
-	colName             = "name"
-	colCaveatDefinition = "definition"
-	colCaveatName       = "caveat_name"
-	colCaveatContext    = "caveat_context"
+	colID                = "id"
+	colTimestamp         = "timestamp"
+	colNamespace         = "namespace"
+	colConfig            = "serialized_config"
+	colCreatedTxn        = "created_transaction"
+	colDeletedTxn        = "deleted_transaction"
+	colObjectID          = "object_id"
+	colRelation          = "relation"
+	colUsersetNamespace  = "userset_namespace"
+	colUsersetObjectID   = "userset_object_id"
+	colUsersetRelation   = "userset_relation"
+	colName              = "name"
+	colCaveatDefinition  = "definition"
+	colCaveatName        = "caveat_name"
+	colCaveatContext     = "caveat_context"
+	colExpiration        = "expiration"
+	colIntegrity         = "integrity"
+	colRelationshipCount = "relationship_count"
+	colShard             = "shard"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	liveDeletedTxnID       = uint64(math.MaxInt64)
@@ -376,6 +380,8 @@ func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 
 			var caveatName string
 			var caveatContext caveatContextWrapper
+			var expiration sql.NullTime
+			var integrity []byte
 			err := rows.Scan(
 				&nextTuple.ResourceAndRelation.Namespace,
 				&nextTuple.ResourceAndRelation.ObjectId,
@@ -385,6 +391,8 @@ func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 				&nextTuple.Subject.Relation,
 				&caveatName,
 				&caveatContext,
+				&expiration,
+				&integrity,
 			)
 			if err != nil {
 				return nil, fmt.Errorf(errUnableToQueryTuples, err)
@@ -394,6 +402,11 @@ func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 			if err != nil {
 				return nil, fmt.Errorf(errUnableToQueryTuples, err)
 			}
+			nextTuple.OptionalExpirationTime = expirationTimeFrom(expiration)
+			nextTuple.Integrity, err = common.IntegrityFrom(integrity)
+			if err != nil {
+				return nil, fmt.Errorf(errUnableToQueryTuples, err)
+			}
 
 			tuples = append(tuples, nextTuple)
 		}
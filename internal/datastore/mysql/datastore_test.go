@@ -90,6 +90,9 @@ func TestMySQLDatastore(t *testing.T) {
 	b := testdatastore.RunMySQLForTesting(t, "")
 	dst := datastoreTester{b: b, t: t}
 	test.All(t, test.DatastoreTesterFunc(dst.createDatastore))
+	t.Run("TestWatchSchemaDeletion", func(t *testing.T) {
+		test.WatchSchemaDeletionTest(t, test.DatastoreTesterFunc(dst.createDatastore))
+	})
 
 	t.Run("DatabaseSeeding", createDatastoreTest(b, DatabaseSeedingTest))
 	t.Run("PrometheusCollector", createDatastoreTest(
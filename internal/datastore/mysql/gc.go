@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -83,6 +84,45 @@ func (mds *Datastore) DeleteBeforeTx(
 	return
 }
 
+// DeleteExpiredRels marks all live relationships which have expired as deleted in a new
+// transaction, so that they are emitted as deletions by Watch. The rows themselves are
+// removed once the transaction falls outside of the GC window.
+func (mds *Datastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	// Avoid creating an empty transaction when there is nothing to remove.
+	query, args, err := mds.QueryTupleExistsQuery.
+		Where(sq.Eq{colDeletedTxn: liveDeletedTxnID}).
+		Where(expiredClause(now)).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = mds.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	_, err = mds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		mysqlRWT := rwt.(*mysqlReadWriteTXN)
+
+		var err error
+		removed, err = mysqlRWT.deleteTuples(ctx, mysqlRWT.DeleteTupleQuery, expiredClause(now), nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
 // TODO (@vroldanbet) dupe from postgres datastore - need to refactor
 // - query was reworked to make it compatible with Vitess
 // - API differences with PSQL driver
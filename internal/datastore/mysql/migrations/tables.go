@@ -7,6 +7,8 @@ const (
 	tableMigrationVersion   = "mysql_migration_version"
 	tableMetadataDefault    = "mysql_metadata"
 	tableCaveatDefault      = "caveat"
+	tableCountsDefault      = "relationship_counts"
+	tableCountedDefault     = "relationship_counted_namespaces"
 )
 
 type tables struct {
@@ -16,6 +18,8 @@ type tables struct {
 	tableNamespace        string
 	tableMetadata         string
 	tableCaveat           string
+	tableCounts           string
+	tableCounted          string
 }
 
 func newTables(prefix string) *tables {
@@ -26,6 +30,8 @@ func newTables(prefix string) *tables {
 		tableNamespace:        prefix + tableNamespaceDefault,
 		tableMetadata:         prefix + tableMetadataDefault,
 		tableCaveat:           prefix + tableCaveatDefault,
+		tableCounts:           prefix + tableCountsDefault,
+		tableCounted:          prefix + tableCountedDefault,
 	}
 }
 
@@ -55,3 +61,14 @@ func (tn *tables) Metadata() string {
 func (tn *tables) Caveat() string {
 	return tn.tableCaveat
 }
+
+// RelationshipCounts returns the prefixed relationship counts table name.
+func (tn *tables) RelationshipCounts() string {
+	return tn.tableCounts
+}
+
+// RelationshipCountedNamespaces returns the prefixed table name of the namespaces whose
+// relationship counts are maintained.
+func (tn *tables) RelationshipCountedNamespaces() string {
+	return tn.tableCounted
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import "fmt"
+
+// addExpirationToRelationTuplesTable adds a nullable expiration column, indexed so that
+// the garbage collector can efficiently find expired relationships.
+func addExpirationToRelationTuplesTable(t *tables) string {
+	return fmt.Sprintf(`ALTER TABLE %s
+			ADD COLUMN expiration DATETIME(6) NULL,
+			ADD INDEX ix_relation_tuple_expiration (expiration);`,
+		t.RelationTuple(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("add_expiration", "extend_object_id", noNonatomicMigration,
+		newStatementBatch(
+			addExpirationToRelationTuplesTable,
+		).execute,
+	)
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import "fmt"
+
+// addIntegrityToRelationTuplesTable adds a nullable column holding the serialized integrity
+// of each relationship.
+func addIntegrityToRelationTuplesTable(t *tables) string {
+	return fmt.Sprintf(`ALTER TABLE %s
+			ADD COLUMN integrity VARBINARY(1024) NULL;`,
+		t.RelationTuple(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("add_relationship_integrity", "add_expiration", noNonatomicMigration,
+		newStatementBatch(
+			addIntegrityToRelationTuplesTable,
+		).execute,
+	)
+}
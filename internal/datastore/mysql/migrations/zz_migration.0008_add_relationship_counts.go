@@ -0,0 +1,36 @@
+package migrations
+
+import "fmt"
+
+// createRelationshipCountedNamespacesTable creates the table of the namespaces whose
+// relationship counts are maintained, which are those whose counts have been read.
+func createRelationshipCountedNamespacesTable(t *tables) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+		namespace VARCHAR(128) NOT NULL,
+		CONSTRAINT pk_relationship_counted_namespaces PRIMARY KEY (namespace)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`,
+		t.RelationshipCountedNamespaces(),
+	)
+}
+
+// createRelationshipCountsTable creates the table holding the number of live relationships
+// stored for each relation, maintained as relationships are written and deleted. Each count is
+// spread across multiple shards, which are summed when read.
+func createRelationshipCountsTable(t *tables) string {
+	return fmt.Sprintf(`CREATE TABLE %s (
+		namespace VARCHAR(128) NOT NULL,
+		relation VARCHAR(64) NOT NULL,
+		shard INT NOT NULL,
+		relationship_count BIGINT NOT NULL,
+		CONSTRAINT pk_relationship_counts PRIMARY KEY (namespace, relation, shard)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`,
+		t.RelationshipCounts(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("add_relationship_counts", "add_relationship_integrity", noNonatomicMigration,
+		newStatementBatch(
+			createRelationshipCountedNamespacesTable,
+			createRelationshipCountsTable,
+		).execute,
+	)
+}
@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"fmt"
+
 	"github.com/authzed/spicedb/internal/datastore/mysql/migrations"
 
 	sq "github.com/Masterminds/squirrel"
@@ -16,6 +18,7 @@ type QueryBuilder struct {
 	ReadNamespaceQuery         sq.SelectBuilder
 	DeleteNamespaceQuery       sq.UpdateBuilder
 	DeleteNamespaceTuplesQuery sq.UpdateBuilder
+	QueryChangedNamespaceQuery sq.SelectBuilder
 
 	QueryTupleIdsQuery    sq.SelectBuilder
 	QueryTuplesQuery      sq.SelectBuilder
@@ -25,10 +28,18 @@ type QueryBuilder struct {
 	QueryChangedQuery     sq.SelectBuilder
 	CountTupleQuery       sq.SelectBuilder
 
-	WriteCaveatQuery  sq.InsertBuilder
-	ReadCaveatQuery   sq.SelectBuilder
-	ListCaveatsQuery  sq.SelectBuilder
-	DeleteCaveatQuery sq.UpdateBuilder
+	UpsertRelationshipCountsQuery     sq.InsertBuilder
+	ReadRelationshipCountsQuery       sq.SelectBuilder
+	ReadCountedNamespacesQuery        sq.SelectBuilder
+	WriteCountedNamespaceQuery        sq.InsertBuilder
+	BackfillRelationshipCountsQuery   sq.InsertBuilder
+	CountRelationshipsByRelationQuery sq.SelectBuilder
+
+	WriteCaveatQuery        sq.InsertBuilder
+	ReadCaveatQuery         sq.SelectBuilder
+	ListCaveatsQuery        sq.SelectBuilder
+	DeleteCaveatQuery       sq.UpdateBuilder
+	QueryChangedCaveatQuery sq.SelectBuilder
 }
 
 // NewQueryBuilder returns a new QueryBuilder instance. The migration
@@ -44,6 +55,7 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	builder.WriteNamespaceQuery = writeNamespace(driver.Namespace())
 	builder.ReadNamespaceQuery = readNamespace(driver.Namespace())
 	builder.DeleteNamespaceQuery = deleteNamespace(driver.Namespace())
+	builder.QueryChangedNamespaceQuery = queryChangedNamespaces(driver.Namespace())
 
 	// tuple builders
 	builder.QueryTupleIdsQuery = queryTupleIds(driver.RelationTuple())
@@ -55,11 +67,20 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	builder.QueryChangedQuery = queryChanged(driver.RelationTuple())
 	builder.CountTupleQuery = countTuples(driver.RelationTuple())
 
+	// relationship count builders
+	builder.UpsertRelationshipCountsQuery = upsertRelationshipCounts(driver.RelationshipCounts())
+	builder.ReadRelationshipCountsQuery = readRelationshipCounts(driver.RelationshipCounts())
+	builder.ReadCountedNamespacesQuery = readCountedNamespaces(driver.RelationshipCountedNamespaces())
+	builder.WriteCountedNamespaceQuery = writeCountedNamespace(driver.RelationshipCountedNamespaces())
+	builder.BackfillRelationshipCountsQuery = backfillRelationshipCounts(driver.RelationshipCounts())
+	builder.CountRelationshipsByRelationQuery = countRelationshipsByRelation(driver.RelationTuple())
+
 	// caveat builders
 	builder.ReadCaveatQuery = readCaveat(driver.Caveat())
 	builder.ListCaveatsQuery = listCaveats(driver.Caveat())
 	builder.WriteCaveatQuery = writeCaveat(driver.Caveat())
 	builder.DeleteCaveatQuery = deleteCaveat(driver.Caveat())
+	builder.QueryChangedCaveatQuery = queryChangedCaveats(driver.Caveat())
 
 	return &builder
 }
@@ -84,6 +105,10 @@ func readCaveat(tableCaveat string) sq.SelectBuilder {
 	return sb.Select(colCaveatDefinition, colCreatedTxn).From(tableCaveat)
 }
 
+func queryChangedCaveats(tableCaveat string) sq.SelectBuilder {
+	return sb.Select(colName, colCaveatDefinition, colCreatedTxn, colDeletedTxn).From(tableCaveat)
+}
+
 func getLastRevision(tableTransaction string) sq.SelectBuilder {
 	return sb.Select("MAX(id)").From(tableTransaction).Limit(1)
 }
@@ -108,6 +133,10 @@ func deleteNamespace(tableNamespace string) sq.UpdateBuilder {
 	return sb.Update(tableNamespace).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
 }
 
+func queryChangedNamespaces(tableNamespace string) sq.SelectBuilder {
+	return sb.Select(colNamespace, colConfig, colCreatedTxn, colDeletedTxn).From(tableNamespace)
+}
+
 func deleteNamespaceTuples(tableTuple string) sq.UpdateBuilder {
 	return sb.Update(tableTuple).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
 }
@@ -128,6 +157,8 @@ func queryTuples(tableTuple string) sq.SelectBuilder {
 		colUsersetRelation,
 		colCaveatName,
 		colCaveatContext,
+		colExpiration,
+		colIntegrity,
 	).From(tableTuple)
 }
 
@@ -137,6 +168,48 @@ func countTuples(tableTuple string) sq.SelectBuilder {
 	).From(tableTuple)
 }
 
+func upsertRelationshipCounts(tableCounts string) sq.InsertBuilder {
+	return sb.Insert(tableCounts).Columns(
+		colNamespace,
+		colRelation,
+		colShard,
+		colRelationshipCount,
+	).Suffix(fmt.Sprintf("ON DUPLICATE KEY UPDATE %[1]s = %[1]s + VALUES(%[1]s)", colRelationshipCount))
+}
+
+func readRelationshipCounts(tableCounts string) sq.SelectBuilder {
+	return sb.Select(colNamespace, colRelation, fmt.Sprintf("CAST(SUM(%s) AS SIGNED)", colRelationshipCount)).
+		From(tableCounts).
+		GroupBy(colNamespace, colRelation).
+		Having(fmt.Sprintf("SUM(%s) > 0", colRelationshipCount))
+}
+
+func readCountedNamespaces(tableCounted string) sq.SelectBuilder {
+	return sb.Select(colNamespace).From(tableCounted)
+}
+
+func writeCountedNamespace(tableCounted string) sq.InsertBuilder {
+	return sb.Insert(tableCounted).Columns(colNamespace)
+}
+
+func backfillRelationshipCounts(tableCounts string) sq.InsertBuilder {
+	return sb.Insert(tableCounts).Columns(
+		colNamespace,
+		colRelation,
+		colShard,
+		colRelationshipCount,
+	)
+}
+
+// countRelationshipsByRelation counts the live relationships of each relation, into the first
+// shard of each count.
+func countRelationshipsByRelation(tableTuple string) sq.SelectBuilder {
+	return sb.Select(colNamespace, colRelation, "0", "COUNT(*)").
+		From(tableTuple).
+		Where(sq.Eq{colDeletedTxn: liveDeletedTxnID}).
+		GroupBy(colNamespace, colRelation)
+}
+
 func deleteTuple(tableTuple string) sq.UpdateBuilder {
 	return sb.Update(tableTuple).Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
 }
@@ -155,6 +228,8 @@ func writeTuple(tableTuple string) sq.InsertBuilder {
 		colUsersetRelation,
 		colCaveatName,
 		colCaveatContext,
+		colExpiration,
+		colIntegrity,
 		colCreatedTxn,
 	)
 }
@@ -169,6 +244,7 @@ func queryChanged(tableTuple string) sq.SelectBuilder {
 		colUsersetRelation,
 		colCaveatName,
 		colCaveatContext,
+		colExpiration,
 		colCreatedTxn,
 		colDeletedTxn,
 	).From(tableTuple)
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -48,13 +50,33 @@ var schema = common.NewSchemaInformation(
 	common.TupleComparison,
 )
 
+// notExpiredClause matches relationships which have no expiration or which expire after now.
+// The time is supplied by the caller rather than computed by MySQL so that it is interpreted
+// in the same location as the stored expiration times.
+func notExpiredClause(now time.Time) sq.Sqlizer {
+	return sq.Or{sq.Eq{colExpiration: nil}, sq.Gt{colExpiration: now}}
+}
+
+// expiredClause matches relationships which have expired as of now.
+func expiredClause(now time.Time) sq.Sqlizer {
+	return sq.LtOrEq{colExpiration: now}
+}
+
+// expirationTimeFrom converts a nullable expiration column value into its proto representation.
+func expirationTimeFrom(expiration sql.NullTime) *timestamppb.Timestamp {
+	if !expiration.Valid {
+		return nil
+	}
+	return common.ExpirationTimeFrom(&expiration.Time)
+}
+
 func (mr *mysqlReader) QueryRelationships(
 	ctx context.Context,
 	filter datastore.RelationshipsFilter,
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery)).FilterWithRelationshipsFilter(filter)
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery.Where(notExpiredClause(time.Now())))).FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +90,7 @@ func (mr *mysqlReader) ReverseQueryRelationships(
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery)).
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, mr.filterer(mr.QueryTuplesQuery.Where(notExpiredClause(time.Now())))).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -86,6 +108,8 @@ func (mr *mysqlReader) ReverseQueryRelationships(
 		ctx,
 		qBuilder,
 		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
 	)
 }
 
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -18,14 +19,21 @@ import (
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 const (
-	errUnableToWriteRelationships  = "unable to write relationships: %w"
-	errUnableToDeleteRelationships = "unable to delete relationships: %w"
-	errUnableToWriteConfig         = "unable to write namespace config: %w"
-	errUnableToDeleteConfig        = "unable to delete namespace config: %w"
+	errUnableToWriteRelationships    = "unable to write relationships: %w"
+	errUnableToDeleteRelationships   = "unable to delete relationships: %w"
+	errUnableToWriteConfig           = "unable to write namespace config: %w"
+	errUnableToDeleteConfig          = "unable to delete namespace config: %w"
+	errUnableToBulkLoadRelationships = "unable to bulk load relationships: %w"
+	errUnableToReadCounts            = "unable to read relationship counts: %w"
+
+	// bulkLoadBatchSize is the number of relationships written per INSERT statement when bulk
+	// loading.
+	bulkLoadBatchSize = 1000
 )
 
 var duplicateEntryRegx = regexp.MustCompile(`^Duplicate entry '(.+)' for key 'uq_relation_tuple_living'$`)
@@ -61,9 +69,13 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 	bulkWrite := rwt.WriteTupleQuery
 	bulkWriteHasValues := false
 
-	selectForUpdateQuery := rwt.QueryTupleIdsQuery
+	selectForUpdateQuery := rwt.QueryTupleIdsQuery.
+		Columns(colNamespace, colRelation).
+		Where(sq.Eq{colDeletedTxn: liveDeletedTxnID})
 
 	clauses := sq.Or{}
+	now := time.Now()
+	deltas := common.RelationshipCountDeltas{}
 
 	// Process the actual updates
 	for _, mut := range mutations {
@@ -74,6 +86,11 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 			clauses = append(clauses, exactRelationshipClause(tpl))
 		}
 
+		// An expired relationship which has not yet been garbage collected is replaced.
+		if mut.Operation == core.RelationTupleUpdate_CREATE {
+			clauses = append(clauses, sq.And{exactRelationshipClause(tpl), expiredClause(now)})
+		}
+
 		var caveatName string
 		var caveatContext caveatContextWrapper
 		if tpl.Caveat != nil {
@@ -81,6 +98,11 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 			caveatContext = tpl.Caveat.Context.AsMap()
 		}
 		if mut.Operation == core.RelationTupleUpdate_TOUCH || mut.Operation == core.RelationTupleUpdate_CREATE {
+			integrity, err := common.IntegrityOf(tpl)
+			if err != nil {
+				return fmt.Errorf(errUnableToWriteRelationships, err)
+			}
+
 			bulkWrite = bulkWrite.Values(
 				tpl.ResourceAndRelation.Namespace,
 				tpl.ResourceAndRelation.ObjectId,
@@ -90,9 +112,12 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 				tpl.Subject.Relation,
 				caveatName,
 				&caveatContext,
+				common.ExpirationTimeOf(tpl),
+				integrity,
 				rwt.newTxnID,
 			)
 			bulkWriteHasValues = true
+			deltas.Add(tpl.ResourceAndRelation.Namespace, tpl.ResourceAndRelation.Relation, 1)
 		}
 	}
 
@@ -111,11 +136,13 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 		tupleIds := make([]int64, 0, len(clauses))
 		for rows.Next() {
 			var tupleID int64
-			if err := rows.Scan(&tupleID); err != nil {
+			var namespace, relation string
+			if err := rows.Scan(&tupleID, &namespace, &relation); err != nil {
 				return fmt.Errorf(errUnableToWriteRelationships, err)
 			}
 
 			tupleIds = append(tupleIds, tupleID)
+			deltas.Add(namespace, relation, -1)
 		}
 
 		if rows.Err() != nil {
@@ -153,43 +180,230 @@ func (rwt *mysqlReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 		}
 	}
 
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
 	return nil
 }
 
-func (rwt *mysqlReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+func (rwt *mysqlReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
 	// TODO (@vroldanbet) dupe from postgres datastore - need to refactor
 	// Add clauses for the ResourceFilter
-	query := rwt.DeleteTupleQuery.Where(sq.Eq{colNamespace: filter.ResourceType})
+	clauses := sq.And{sq.Eq{colNamespace: filter.ResourceType}}
 	if filter.OptionalResourceId != "" {
-		query = query.Where(sq.Eq{colObjectID: filter.OptionalResourceId})
+		clauses = append(clauses, sq.Eq{colObjectID: filter.OptionalResourceId})
 	}
 	if filter.OptionalRelation != "" {
-		query = query.Where(sq.Eq{colRelation: filter.OptionalRelation})
+		clauses = append(clauses, sq.Eq{colRelation: filter.OptionalRelation})
 	}
 
 	// Add clauses for the SubjectFilter
 	if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
-		query = query.Where(sq.Eq{colUsersetNamespace: subjectFilter.SubjectType})
+		clauses = append(clauses, sq.Eq{colUsersetNamespace: subjectFilter.SubjectType})
 		if subjectFilter.OptionalSubjectId != "" {
-			query = query.Where(sq.Eq{colUsersetObjectID: subjectFilter.OptionalSubjectId})
+			clauses = append(clauses, sq.Eq{colUsersetObjectID: subjectFilter.OptionalSubjectId})
 		}
 		if relationFilter := subjectFilter.OptionalRelation; relationFilter != nil {
-			query = query.Where(sq.Eq{colUsersetRelation: stringz.DefaultEmpty(relationFilter.Relation, datastore.Ellipsis)})
+			clauses = append(clauses, sq.Eq{colUsersetRelation: stringz.DefaultEmpty(relationFilter.Relation, datastore.Ellipsis)})
+		}
+	}
+
+	delOpts := options.NewDeleteOptionsWithOptions(opts...)
+	deleted, err := rwt.deleteTuples(ctx, rwt.DeleteTupleQuery, clauses, delOpts.DeleteLimit)
+	if err != nil {
+		return false, fmt.Errorf(errUnableToDeleteRelationships, err)
+	}
+
+	return delOpts.DeleteLimit != nil && uint64(deleted) == *delOpts.DeleteLimit, nil
+}
+
+// deleteTuples marks the live tuples matching the clauses as deleted, up to the limit if one is
+// given, and subtracts them from the relationship counts. As MySQL cannot return the rows
+// modified by an update, the tuples are counted beforehand, ordered by ID so that the count
+// covers the same tuples as the limited update. Returns the number of tuples deleted.
+func (rwt *mysqlReadWriteTXN) deleteTuples(ctx context.Context, query sq.UpdateBuilder, clauses sq.Sqlizer, limit *uint64) (int64, error) {
+	matching := rwt.QueryTupleIdsQuery.
+		Columns(colNamespace, colRelation).
+		Where(sq.Eq{colDeletedTxn: liveDeletedTxnID}).
+		Where(clauses)
+	query = query.Where(clauses)
+	if limit != nil {
+		matching = matching.OrderBy(colID).Limit(*limit)
+		query = query.OrderBy(colID).Limit(*limit)
+	}
+
+	countSQL, countArgs, err := sb.Select("count(*)", colNamespace, colRelation).
+		FromSelect(matching, "matching").
+		GroupBy(colNamespace, colRelation).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := rwt.tx.QueryContext(ctx, countSQL, countArgs...)
+	if err != nil {
+		return 0, err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	deltas := common.RelationshipCountDeltas{}
+	for rows.Next() {
+		var count int64
+		var namespace, relation string
+		if err := rows.Scan(&count, &namespace, &relation); err != nil {
+			return 0, err
 		}
+		deltas.Add(namespace, relation, -count)
+	}
+	if rows.Err() != nil {
+		return 0, rows.Err()
+	}
+
+	querySQL, args, err := query.Set(colDeletedTxn, rwt.newTxnID).ToSql()
+	if err != nil {
+		return 0, err
 	}
 
-	query = query.Set(colDeletedTxn, rwt.newTxnID)
+	result, err := rwt.tx.ExecContext(ctx, querySQL, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, rwt.updateRelationshipCounts(ctx, deltas)
+}
+
+// updateRelationshipCounts applies the deltas to the maintained relationship counts, for the
+// namespaces whose counts are maintained. The deltas are added to a randomly selected shard of
+// each count.
+func (rwt *mysqlReadWriteTXN) updateRelationshipCounts(ctx context.Context, deltas common.RelationshipCountDeltas) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	counted, err := rwt.countedNamespaces(ctx, deltas.Namespaces())
+	if err != nil {
+		return err
+	}
+	if len(counted) == 0 {
+		return nil
+	}
+
+	shard := common.RandomRelationshipCountShard()
+	query := rwt.UpsertRelationshipCountsQuery
+	for key, delta := range deltas {
+		if _, ok := counted[key.Definition]; ok {
+			query = query.Values(key.Definition, key.Relation, shard, delta)
+		}
+	}
 
 	querySQL, args, err := query.ToSql()
 	if err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+		return err
+	}
+
+	_, err = rwt.tx.ExecContext(ctx, querySQL, args...)
+	return err
+}
+
+// countedNamespaces returns the set of the given namespaces whose relationship counts are
+// maintained.
+func (rwt *mysqlReadWriteTXN) countedNamespaces(ctx context.Context, namespaces []string) (map[string]struct{}, error) {
+	querySQL, args, err := rwt.ReadCountedNamespacesQuery.Where(sq.Eq{colNamespace: namespaces}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rwt.tx.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	counted := make(map[string]struct{}, len(namespaces))
+	for rows.Next() {
+		var namespace string
+		if err := rows.Scan(&namespace); err != nil {
+			return nil, err
+		}
+		counted[namespace] = struct{}{}
+	}
+	return counted, rows.Err()
+}
+
+// startCounting starts maintaining the relationship counts of the given namespaces which are
+// not yet counted, by counting their existing relationships.
+func (rwt *mysqlReadWriteTXN) startCounting(ctx context.Context, namespaces []string) error {
+	counted, err := rwt.countedNamespaces(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+
+	uncounted := make([]string, 0, len(namespaces))
+	insertQuery := rwt.WriteCountedNamespaceQuery
+	for _, namespace := range namespaces {
+		if _, ok := counted[namespace]; !ok {
+			counted[namespace] = struct{}{}
+			uncounted = append(uncounted, namespace)
+			insertQuery = insertQuery.Values(namespace)
+		}
+	}
+	if len(uncounted) == 0 {
+		return nil
 	}
 
+	querySQL, args, err := insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
 	if _, err := rwt.tx.ExecContext(ctx, querySQL, args...); err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+		return err
 	}
 
-	return nil
+	querySQL, args, err = rwt.BackfillRelationshipCountsQuery.
+		Select(rwt.CountRelationshipsByRelationQuery.Where(sq.Eq{colNamespace: uncounted})).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = rwt.tx.ExecContext(ctx, querySQL, args...)
+	return err
+}
+
+func (rwt *mysqlReadWriteTXN) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	if err := rwt.startCounting(ctx, definitions); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	querySQL, args, err := rwt.ReadRelationshipCountsQuery.Where(sq.Eq{colNamespace: definitions}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	rows, err := rwt.tx.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	counts := make(map[datastore.RelationKey]uint64)
+	for rows.Next() {
+		var key datastore.RelationKey
+		var count int64
+		if err := rows.Scan(&key.Definition, &key.Relation, &count); err != nil {
+			return nil, fmt.Errorf(errUnableToReadCounts, err)
+		}
+		counts[key] = uint64(count)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, rows.Err())
+	}
+	return counts, nil
 }
 
 func (rwt *mysqlReadWriteTXN) WriteNamespaces(ctx context.Context, newNamespaces ...*core.NamespaceDefinition) error {
@@ -272,16 +486,7 @@ func (rwt *mysqlReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...s
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
-	deleteTupleSQL, deleteTupleArgs, err := rwt.DeleteNamespaceTuplesQuery.
-		Set(colDeletedTxn, rwt.newTxnID).
-		Where(sq.Or(tplClauses)).
-		ToSql()
-	if err != nil {
-		return fmt.Errorf(errUnableToDeleteConfig, err)
-	}
-
-	_, err = rwt.tx.ExecContext(ctx, deleteTupleSQL, deleteTupleArgs...)
-	if err != nil {
+	if _, err := rwt.deleteTuples(ctx, rwt.DeleteNamespaceTuplesQuery, sq.Or(tplClauses), nil); err != nil {
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
@@ -327,4 +532,86 @@ func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	}
 }
 
+func (rwt *mysqlReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	var numLoaded uint64
+	deltas := common.RelationshipCountDeltas{}
+
+	bulkWrite := rwt.WriteTupleQuery
+	var batchCount int
+	flush := func() error {
+		if batchCount == 0 {
+			return nil
+		}
+
+		query, args, err := bulkWrite.ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		if _, err := rwt.tx.ExecContext(ctx, query, args...); err != nil {
+			if cerr := convertToWriteConstraintError(err); cerr != nil {
+				return cerr
+			}
+
+			return fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		bulkWrite = rwt.WriteTupleQuery
+		batchCount = 0
+		return nil
+	}
+
+	var tpl *core.RelationTuple
+	var err error
+	for tpl, err = iter.Next(ctx); tpl != nil && err == nil; tpl, err = iter.Next(ctx) {
+		var caveatName string
+		var caveatContext caveatContextWrapper
+		if tpl.Caveat != nil {
+			caveatName = tpl.Caveat.CaveatName
+			caveatContext = tpl.Caveat.Context.AsMap()
+		}
+
+		integrity, err := common.IntegrityOf(tpl)
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		bulkWrite = bulkWrite.Values(
+			tpl.ResourceAndRelation.Namespace,
+			tpl.ResourceAndRelation.ObjectId,
+			tpl.ResourceAndRelation.Relation,
+			tpl.Subject.Namespace,
+			tpl.Subject.ObjectId,
+			tpl.Subject.Relation,
+			caveatName,
+			&caveatContext,
+			common.ExpirationTimeOf(tpl),
+			integrity,
+			rwt.newTxnID,
+		)
+		batchCount++
+		numLoaded++
+		deltas.Add(tpl.ResourceAndRelation.Namespace, tpl.ResourceAndRelation.Relation, 1)
+
+		if batchCount >= bulkLoadBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+	}
+
+	return numLoaded, nil
+}
+
 var _ datastore.ReadWriteTransaction = &mysqlReadWriteTXN{}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 
@@ -14,6 +15,8 @@ const (
 	informationSchemaTableRowsColumn = "table_rows"
 	informationSchemaTablesTable     = "INFORMATION_SCHEMA.TABLES"
 	informationSchemaTableNameColumn = "table_name"
+	informationSchemaDataLength      = "data_length"
+	informationSchemaIndexLength     = "index_length"
 
 	metadataIDColumn       = "id"
 	metadataUniqueIDColumn = "unique_id"
@@ -79,6 +82,93 @@ func (mds *Datastore) Statistics(ctx context.Context) (datastore.Stats, error) {
 	}, nil
 }
 
+// detailedStatsExactCountLimit is the number of rows in the tuple table, according to the
+// table statistics kept by MySQL, up to which relationships are counted for each relation.
+// MySQL keeps no statistics from which the relationships of larger tables can be estimated
+// for each relation, so they are not reported.
+const detailedStatsExactCountLimit = 1_000_000
+
+// DetailedStatistics reads the storage size and changelog backlog from the table statistics
+// kept by MySQL, and counts the relationships of each relation exactly if the tuple table
+// has at most detailedStatsExactCountLimit rows.
+func (mds *Datastore) DetailedStatistics(ctx context.Context) (datastore.DetailedStats, error) {
+	query, args, err := sb.
+		Select(
+			"COALESCE("+informationSchemaTableRowsColumn+", 0)",
+			"COALESCE("+informationSchemaDataLength+" + "+informationSchemaIndexLength+", 0)",
+		).
+		From(informationSchemaTablesTable).
+		Where(squirrel.Eq{informationSchemaTableNameColumn: mds.driver.RelationTuple()}).
+		ToSql()
+	if err != nil {
+		return datastore.DetailedStats{}, err
+	}
+
+	var stats datastore.DetailedStats
+	var estimatedRows uint64
+	if err := mds.db.QueryRowContext(ctx, query, args...).Scan(&estimatedRows, &stats.StorageSizeBytes); err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to read storage size: %w", err)
+	}
+
+	query, args, err = sb.
+		Select("COALESCE(" + informationSchemaTableRowsColumn + ", 0)").
+		From(informationSchemaTablesTable).
+		Where(squirrel.Eq{informationSchemaTableNameColumn: mds.driver.RelationTupleTransaction()}).
+		ToSql()
+	if err != nil {
+		return datastore.DetailedStats{}, err
+	}
+	if err := mds.db.QueryRowContext(ctx, query, args...).Scan(&stats.ChangelogBacklog); err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to read transaction count: %w", err)
+	}
+
+	if estimatedRows > detailedStatsExactCountLimit {
+		stats.CountsAreEstimates = true
+		return stats, nil
+	}
+
+	counts, err := mds.countRelationships(ctx)
+	if err != nil {
+		return datastore.DetailedStats{}, err
+	}
+	stats.Definitions = datastore.ComputeDefinitionStats(counts)
+
+	return stats, nil
+}
+
+func (mds *Datastore) countRelationships(ctx context.Context) (map[datastore.RelationKey]uint64, error) {
+	query, args, err := sb.Select(colNamespace, colRelation, "COUNT(*)").
+		From(mds.driver.RelationTuple()).
+		Where(squirrel.Eq{colDeletedTxn: liveDeletedTxnID}).
+		Where(notExpiredClause(time.Now())).
+		GroupBy(colNamespace, colRelation).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare relation count sql: %w", err)
+	}
+
+	rows, err := mds.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count relationships: %w", err)
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	counts := make(map[datastore.RelationKey]uint64)
+	for rows.Next() {
+		var key datastore.RelationKey
+		var count uint64
+		if err := rows.Scan(&key.Definition, &key.Relation, &count); err != nil {
+			return nil, fmt.Errorf("unable to count relationships: %w", err)
+		}
+		counts[key] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to count relationships: %w", err)
+	}
+
+	return counts, nil
+}
+
 func (mds *Datastore) getUniqueID(ctx context.Context) (string, error) {
 	sql, args, err := sb.Select(metadataUniqueIDColumn).From(mds.driver.Metadata()).ToSql()
 	if err != nil {
@@ -2,7 +2,9 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -17,12 +19,13 @@ const (
 	watchSleep = 100 * time.Millisecond
 )
 
-// Watch notifies the caller about all changes to tuples.
+// Watch notifies the caller about all changes to tuples and, if requested in the options, to
+// the schema.
 //
 // All events following afterRevision will be sent to the caller.
 //
 // TODO (@vroldanbet) dupe from postgres datastore - need to refactor
-func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
 	afterRevision := afterRevisionRaw.(revision.Decimal)
 
 	updates := make(chan *datastore.RevisionChanges, mds.watchBufferLength)
@@ -34,10 +37,12 @@ func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revi
 
 		currentTxn := transactionFromRevision(afterRevision)
 
+		lastCheckpoint := time.Now()
+
 		for {
 			var stagedUpdates []datastore.RevisionChanges
 			var err error
-			stagedUpdates, currentTxn, err = mds.loadChanges(ctx, currentTxn)
+			stagedUpdates, currentTxn, err = mds.loadChanges(ctx, currentTxn, options)
 			if err != nil {
 				if errors.Is(ctx.Err(), context.Canceled) {
 					errs <- datastore.NewWatchCanceledErr()
@@ -59,6 +64,16 @@ func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revi
 				}
 			}
 
+			if options.CheckpointInterval > 0 && time.Since(lastCheckpoint) >= options.CheckpointInterval {
+				select {
+				case updates <- &datastore.RevisionChanges{Revision: revisionFromTransaction(currentTxn), IsCheckpoint: true}:
+					lastCheckpoint = time.Now()
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
+				}
+			}
+
 			// If there were no changes, sleep a bit
 			if len(stagedUpdates) == 0 {
 				sleep := time.NewTimer(watchSleep)
@@ -81,6 +96,7 @@ func (mds *Datastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revi
 func (mds *Datastore) loadChanges(
 	ctx context.Context,
 	afterRevision uint64,
+	options datastore.WatchOptions,
 ) (changes []datastore.RevisionChanges, newRevision uint64, err error) {
 	newRevision, err = mds.loadRevision(ctx)
 	if err != nil {
@@ -91,7 +107,7 @@ func (mds *Datastore) loadChanges(
 		return
 	}
 
-	sql, args, err := mds.QueryChangedQuery.Where(sq.Or{
+	inRange := sq.Or{
 		sq.And{
 			sq.Gt{colCreatedTxn: afterRevision},
 			sq.LtOrEq{colCreatedTxn: newRevision},
@@ -100,22 +116,50 @@ func (mds *Datastore) loadChanges(
 			sq.Gt{colDeletedTxn: afterRevision},
 			sq.LtOrEq{colDeletedTxn: newRevision},
 		},
-	}).ToSql()
+	}
+	isInRange := func(txn uint64) bool {
+		return txn > afterRevision && txn <= newRevision
+	}
+
+	stagedChanges := common.NewChanges(revision.DecimalKeyFunc)
+
+	if options.Includes(datastore.WatchRelationships) {
+		if err = mds.loadRelationshipChanges(ctx, inRange, isInRange, stagedChanges); err != nil {
+			return
+		}
+	}
+
+	if options.Includes(datastore.WatchSchema) {
+		if err = mds.loadSchemaChanges(ctx, inRange, isInRange, stagedChanges); err != nil {
+			return
+		}
+	}
+
+	changes = stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
+
+	return
+}
+
+func (mds *Datastore) loadRelationshipChanges(
+	ctx context.Context,
+	inRange sq.Sqlizer,
+	isInRange func(uint64) bool,
+	stagedChanges common.Changes[revision.Decimal, int64],
+) error {
+	query, args, err := mds.QueryChangedQuery.Where(inRange).ToSql()
 	if err != nil {
-		return
+		return err
 	}
 
-	rows, err := mds.db.QueryContext(ctx, sql, args...)
+	rows, err := mds.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			err = datastore.NewWatchCanceledErr()
 		}
-		return
+		return err
 	}
 	defer common.LogOnError(ctx, rows.Close)
 
-	stagedChanges := common.NewChanges(revision.DecimalKeyFunc)
-
 	for rows.Next() {
 		nextTuple := &core.RelationTuple{
 			ResourceAndRelation: &core.ObjectAndRelation{},
@@ -126,6 +170,7 @@ func (mds *Datastore) loadChanges(
 		var deletedTxn uint64
 		var caveatName string
 		var caveatContext caveatContextWrapper
+		var expiration sql.NullTime
 		err = rows.Scan(
 			&nextTuple.ResourceAndRelation.Namespace,
 			&nextTuple.ResourceAndRelation.ObjectId,
@@ -135,30 +180,110 @@ func (mds *Datastore) loadChanges(
 			&nextTuple.Subject.Relation,
 			&caveatName,
 			&caveatContext,
+			&expiration,
 			&createdTxn,
 			&deletedTxn,
 		)
 		if err != nil {
-			return
+			return err
 		}
 		nextTuple.Caveat, err = common.ContextualizedCaveatFrom(caveatName, caveatContext)
 		if err != nil {
-			return
+			return err
 		}
+		nextTuple.OptionalExpirationTime = expirationTimeFrom(expiration)
 
-		if createdTxn > afterRevision && createdTxn <= newRevision {
+		if isInRange(createdTxn) {
 			stagedChanges.AddChange(ctx, revisionFromTransaction(createdTxn), nextTuple, core.RelationTupleUpdate_TOUCH)
 		}
 
-		if deletedTxn > afterRevision && deletedTxn <= newRevision {
+		if isInRange(deletedTxn) {
 			stagedChanges.AddChange(ctx, revisionFromTransaction(deletedTxn), nextTuple, core.RelationTupleUpdate_DELETE)
 		}
 	}
-	if err = rows.Err(); err != nil {
-		return
+	return rows.Err()
+}
+
+func (mds *Datastore) loadSchemaChanges(
+	ctx context.Context,
+	inRange sq.Sqlizer,
+	isInRange func(uint64) bool,
+	stagedChanges common.Changes[revision.Decimal, int64],
+) error {
+	// Namespaces and caveats are rewritten by marking the previous row as deleted and
+	// inserting a new one in the same transaction, which the change tracker reconciles
+	// into a single write.
+	query, args, err := mds.QueryChangedNamespaceQuery.Where(inRange).ToSql()
+	if err != nil {
+		return err
 	}
 
-	changes = stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
+	rows, err := mds.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			err = datastore.NewWatchCanceledErr()
+		}
+		return err
+	}
+	defer common.LogOnError(ctx, rows.Close)
 
-	return
+	for rows.Next() {
+		var name string
+		var config []byte
+		var createdTxn, deletedTxn uint64
+		if err := rows.Scan(&name, &config, &createdTxn, &deletedTxn); err != nil {
+			return err
+		}
+
+		if isInRange(createdTxn) {
+			loaded := &core.NamespaceDefinition{}
+			if err := loaded.UnmarshalVT(config); err != nil {
+				return fmt.Errorf(errUnableToReadConfig, err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, revisionFromTransaction(createdTxn), loaded)
+		}
+
+		if isInRange(deletedTxn) {
+			stagedChanges.AddDeletedNamespace(ctx, revisionFromTransaction(deletedTxn), name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	query, args, err = mds.QueryChangedCaveatQuery.Where(inRange).ToSql()
+	if err != nil {
+		return err
+	}
+
+	caveatRows, err := mds.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			err = datastore.NewWatchCanceledErr()
+		}
+		return err
+	}
+	defer common.LogOnError(ctx, caveatRows.Close)
+
+	for caveatRows.Next() {
+		var name string
+		var definition []byte
+		var createdTxn, deletedTxn uint64
+		if err := caveatRows.Scan(&name, &definition, &createdTxn, &deletedTxn); err != nil {
+			return err
+		}
+
+		if isInRange(createdTxn) {
+			loaded := &core.CaveatDefinition{}
+			if err := loaded.UnmarshalVT(definition); err != nil {
+				return fmt.Errorf(errReadCaveat, err)
+			}
+			stagedChanges.AddChangedDefinition(ctx, revisionFromTransaction(createdTxn), loaded)
+		}
+
+		if isInRange(deletedTxn) {
+			stagedChanges.AddDeletedCaveat(ctx, revisionFromTransaction(deletedTxn), name)
+		}
+	}
+	return caveatRows.Err()
 }
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// tupleSourceAdapter adapts a datastore.BulkWriteRelationshipSource into a pgx.CopyFromSource.
+type tupleSourceAdapter struct {
+	source datastore.BulkWriteRelationshipSource
+	ctx    context.Context
+
+	current      *core.RelationTuple
+	err          error
+	valuesBuffer []any
+}
+
+// Next returns true if there is another row and makes the next row data
+// available to Values(). When there are no more rows available or an error
+// has occurred it returns false.
+func (tg *tupleSourceAdapter) Next() bool {
+	tg.current, tg.err = tg.source.Next(tg.ctx)
+	return tg.current != nil
+}
+
+// Values returns the values for the current row.
+func (tg *tupleSourceAdapter) Values() ([]any, error) {
+	var caveatName string
+	var caveatContext map[string]any
+	if tg.current.Caveat != nil {
+		caveatName = tg.current.Caveat.CaveatName
+		caveatContext = tg.current.Caveat.Context.AsMap()
+	}
+
+	tg.valuesBuffer[0] = tg.current.ResourceAndRelation.Namespace
+	tg.valuesBuffer[1] = tg.current.ResourceAndRelation.ObjectId
+	tg.valuesBuffer[2] = tg.current.ResourceAndRelation.Relation
+	tg.valuesBuffer[3] = tg.current.Subject.Namespace
+	tg.valuesBuffer[4] = tg.current.Subject.ObjectId
+	tg.valuesBuffer[5] = tg.current.Subject.Relation
+	tg.valuesBuffer[6] = caveatName
+	tg.valuesBuffer[7] = caveatContext
+	tg.valuesBuffer[8] = common.ExpirationTimeOf(tg.current)
+
+	integrity, err := common.IntegrityOf(tg.current)
+	if err != nil {
+		return nil, err
+	}
+	tg.valuesBuffer[9] = integrity
+
+	return tg.valuesBuffer, nil
+}
+
+// Err returns any error that has been encountered by the CopyFromSource. If
+// this is not nil *Conn.CopyFrom will abort the copy.
+func (tg *tupleSourceAdapter) Err() error {
+	return tg.err
+}
+
+// BulkLoad copies all relationships from the source into the given table using the COPY
+// protocol. The column names must be given in the order: resource namespace, resource object
+// ID, relation, subject namespace, subject object ID, subject relation, caveat name, caveat
+// context, expiration and integrity.
+func BulkLoad(
+	ctx context.Context,
+	tx pgx.Tx,
+	tupleTableName string,
+	colNames []string,
+	iter datastore.BulkWriteRelationshipSource,
+) (uint64, error) {
+	adapter := &tupleSourceAdapter{
+		source:       iter,
+		ctx:          ctx,
+		valuesBuffer: make([]any, len(colNames)),
+	}
+	copied, err := tx.CopyFrom(ctx, pgx.Identifier{tupleTableName}, colNames, adapter)
+	return uint64(copied), err
+}
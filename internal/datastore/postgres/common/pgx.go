@@ -55,6 +55,8 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 		}
 		var caveatName sql.NullString
 		var caveatCtx map[string]any
+		var expiration *time.Time
+		var integrity []byte
 		err := rows.Scan(
 			&nextTuple.ResourceAndRelation.Namespace,
 			&nextTuple.ResourceAndRelation.ObjectId,
@@ -64,6 +66,8 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 			&nextTuple.Subject.Relation,
 			&caveatName,
 			&caveatCtx,
+			&expiration,
+			&integrity,
 		)
 		if err != nil {
 			return nil, fmt.Errorf(errUnableToQueryTuples, err)
@@ -73,6 +77,11 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 		if err != nil {
 			return nil, fmt.Errorf("unable to fetch caveat context: %w", err)
 		}
+		nextTuple.OptionalExpirationTime = common.ExpirationTimeFrom(expiration)
+		nextTuple.Integrity, err = common.IntegrityFrom(integrity)
+		if err != nil {
+			return nil, fmt.Errorf(errUnableToQueryTuples, err)
+		}
 		tuples = append(tuples, nextTuple)
 	}
 	if err := rows.Err(); err != nil {
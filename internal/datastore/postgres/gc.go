@@ -110,6 +110,55 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 	return
 }
 
+func (pgd *pgDatastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
+	// Check for expired relationships before starting a transaction, to avoid creating empty
+	// transactions when there is nothing to remove.
+	sql, args, err := psql.Select("1").
+		From(tableTuple).
+		Where(sq.Eq{colDeletedXid: liveDeletedTxnID}).
+		Where(expiredClause).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := pgd.readPool.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	hasExpired := rows.Next()
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if !hasExpired {
+		return 0, nil
+	}
+
+	// Expired relationships are marked as deleted in a new transaction, so that the
+	// deletions are seen by watchers and the rows are later removed by DeleteBeforeTx.
+	var removed int64
+	_, err = pgd.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		pgRWT := rwt.(*pgReadWriteTXN)
+
+		deltas := common.RelationshipCountDeltas{}
+		deleted, err := pgRWT.deleteTuples(ctx, deleteTuple.Where(expiredClause), deltas)
+		if err != nil {
+			return err
+		}
+
+		removed = deleted
+		return pgRWT.updateRelationshipCounts(ctx, deltas)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
 func (pgd *pgDatastore) batchDelete(
 	ctx context.Context,
 	tableName string,
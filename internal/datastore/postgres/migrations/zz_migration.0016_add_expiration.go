@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const addExpirationColumn = `ALTER TABLE relation_tuple
+	ADD COLUMN IF NOT EXISTS expiration TIMESTAMPTZ;`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-expiration-support", "add-gc-covering-index",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, addExpirationColumn); err != nil {
+				return err
+			}
+			return nil
+		}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Used by garbage collection to find the relationships which have expired.
+const createRelationTupleExpirationIndex = `CREATE INDEX CONCURRENTLY
+	IF NOT EXISTS ix_relation_tuple_expired
+	ON relation_tuple (expiration)
+	WHERE (expiration IS NOT NULL);`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-expiration-index", "add-expiration-support",
+		func(ctx context.Context, conn *pgx.Conn) error {
+			if _, err := conn.Exec(ctx, createRelationTupleExpirationIndex); err != nil {
+				return err
+			}
+			return nil
+		},
+		noTxMigration); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
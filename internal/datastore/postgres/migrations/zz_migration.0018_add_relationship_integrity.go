@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const addIntegrityColumn = `ALTER TABLE relation_tuple
+	ADD COLUMN IF NOT EXISTS integrity BYTEA;`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-relationship-integrity", "add-expiration-index",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, addIntegrityColumn); err != nil {
+				return err
+			}
+			return nil
+		}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var relationshipCountStatements = []string{
+	`CREATE TABLE relationship_counted_namespaces (
+		namespace VARCHAR NOT NULL,
+		CONSTRAINT pk_relationship_counted_namespaces PRIMARY KEY (namespace));`,
+	`CREATE TABLE relationship_counts (
+		namespace VARCHAR NOT NULL,
+		relation VARCHAR NOT NULL,
+		shard INT NOT NULL,
+		relationship_count BIGINT NOT NULL,
+		CONSTRAINT pk_relationship_counts PRIMARY KEY (namespace, relation, shard));`,
+}
+
+func init() {
+	if err := DatabaseMigrations.Register("add-relationship-counts", "add-relationship-integrity",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			for _, stmt := range relationshipCountStatements {
+				if _, err := tx.Exec(ctx, stmt); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
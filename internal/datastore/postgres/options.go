@@ -10,6 +10,9 @@ import (
 type postgresOptions struct {
 	readPoolOpts, writePoolOpts pgxcommon.PoolOptions
 
+	readReplicaURIs         []string
+	readReplicaPollInterval time.Duration
+
 	maxRevisionStalenessPercent float64
 
 	watchBufferLength    uint16
@@ -58,6 +61,7 @@ const (
 	defaultEnablePrometheusStats             = false
 	defaultMaxRetries                        = 10
 	defaultGCEnabled                         = true
+	defaultReadReplicaPollInterval           = 500 * time.Millisecond
 )
 
 // Option provides the facility to configure how clients within the
@@ -76,6 +80,7 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		enablePrometheusStats:       defaultEnablePrometheusStats,
 		maxRetries:                  defaultMaxRetries,
 		gcEnabled:                   defaultGCEnabled,
+		readReplicaPollInterval:     defaultReadReplicaPollInterval,
 		queryInterceptor:            nil,
 	}
 
@@ -92,6 +97,10 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		)
 	}
 
+	if len(computed.readReplicaURIs) > 0 && computed.readReplicaPollInterval <= 0 {
+		return computed, fmt.Errorf("read replica poll interval must be positive")
+	}
+
 	if _, ok := migrationPhases[computed.migrationPhase]; !ok {
 		return computed, fmt.Errorf("unknown migration phase: %s", computed.migrationPhase)
 	}
@@ -99,6 +108,23 @@ func generateConfig(options []Option) (postgresOptions, error) {
 	return computed, nil
 }
 
+// ReadReplicaConnURIs is the connection URIs of read replicas of the primary, to which
+// snapshot reads are routed once they have replayed the revision being read. The connection
+// pools of the replicas are configured with the read connection pool options.
+//
+// No read replicas are used by default.
+func ReadReplicaConnURIs(uris []string) Option {
+	return func(po *postgresOptions) { po.readReplicaURIs = uris }
+}
+
+// ReadReplicaPollInterval is the interval at which read replicas are polled for their health
+// and the transactions they have replayed.
+//
+// This value defaults to 500ms.
+func ReadReplicaPollInterval(interval time.Duration) Option {
+	return func(po *postgresOptions) { po.readReplicaPollInterval = interval }
+}
+
 // SplitAtUsersetCount is the batch size for which userset queries will be
 // split into smaller queries.
 //
@@ -37,6 +37,8 @@ const (
 	tableTransaction = "relation_tuple_transaction"
 	tableTuple       = "relation_tuple"
 	tableCaveat      = "caveat"
+	tableCounts      = "relationship_counts"
+	tableCounted     = "relationship_counted_namespaces"
 
 	colXID               = "xid"
 	colTimestamp         = "timestamp"
@@ -54,6 +56,10 @@ const (
 	colCaveatDefinition  = "definition"
 	colCaveatContextName = "caveat_name"
 	colCaveatContext     = "caveat_context"
+	colExpiration        = "expiration"
+	colIntegrity         = "integrity"
+	colCount             = "relationship_count"
+	colShard             = "shard"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 
@@ -199,6 +205,19 @@ func newPostgresDatastore(
 		if err := common.RegisterGCMetrics(); err != nil {
 			return nil, fmt.Errorf(errUnableToInstantiate, err)
 		}
+		if len(config.readReplicaURIs) > 0 {
+			if err := registerReadReplicaMetrics(); err != nil {
+				return nil, fmt.Errorf(errUnableToInstantiate, err)
+			}
+		}
+	}
+
+	var readReplicas *readReplicaSet
+	if len(config.readReplicaURIs) > 0 {
+		readReplicas, err = newReadReplicaSet(initializationContext, config.readReplicaURIs, config)
+		if err != nil {
+			return nil, fmt.Errorf(errUnableToInstantiate, err)
+		}
 	}
 
 	gcCtx, cancelGc := context.WithCancel(context.Background())
@@ -235,6 +254,7 @@ func newPostgresDatastore(
 		dburl:                   url,
 		readPool:                pgxcommon.MustNewInterceptorPooler(readPool, config.queryInterceptor),
 		writePool:               pgxcommon.MustNewInterceptorPooler(writePool, config.queryInterceptor),
+		readReplicas:            readReplicas,
 		watchBufferLength:       config.watchBufferLength,
 		optimizedRevisionQuery:  revisionQuery,
 		validTransactionQuery:   validTransactionQuery,
@@ -276,6 +296,7 @@ type pgDatastore struct {
 
 	dburl                   string
 	readPool, writePool     pgxcommon.ConnPooler
+	readReplicas            *readReplicaSet
 	watchBufferLength       uint16
 	optimizedRevisionQuery  string
 	validTransactionQuery   string
@@ -296,8 +317,17 @@ type pgDatastore struct {
 func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Reader {
 	rev := revRaw.(postgresRevision)
 
+	// Reads are routed to a read replica only if it has already replayed the revision, as
+	// otherwise it could be missing changes visible at the revision.
+	pool := pgd.readPool
+	if pgd.readReplicas != nil {
+		if replicaPool := pgd.readReplicas.poolFor(rev); replicaPool != nil {
+			pool = replicaPool
+		}
+	}
+
 	createTxFunc := func(ctx context.Context) (pgxcommon.DBReader, common.TxCleanupFunc, error) {
-		return pgd.readPool, func(ctx context.Context) {}, nil
+		return pool, func(ctx context.Context) {}, nil
 	}
 
 	querySplitter := common.TupleQuerySplitter{
@@ -372,6 +402,10 @@ func (pgd *pgDatastore) Close() error {
 		log.Warn().Err(err).Msg("completed shutdown of postgres datastore")
 	}
 
+	if pgd.readReplicas != nil {
+		pgd.readReplicas.Close()
+	}
+
 	pgd.readPool.Close()
 	pgd.writePool.Close()
 	return nil
@@ -40,7 +40,7 @@ func TestPostgresDatastore(t *testing.T) {
 			t.Parallel()
 			b := testdatastore.RunPostgresForTesting(t, "", config.targetMigration)
 
-			test.All(t, test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+			tester := test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
 				ds := b.NewDatastore(t, func(engine, uri string) datastore.Datastore {
 					ds, err := newPostgresDatastore(uri,
 						RevisionQuantization(revisionQuantization),
@@ -54,7 +54,10 @@ func TestPostgresDatastore(t *testing.T) {
 					return ds
 				})
 				return ds, nil
-			}))
+			})
+
+			test.All(t, tester)
+			t.Run("TestWatchSchemaDeletion", func(t *testing.T) { test.WatchSchemaDeletionTest(t, tester) })
 
 			t.Run("WithSplit", func(t *testing.T) {
 				// Set the split at a VERY small size, to ensure any WithUsersets queries are split.
@@ -77,6 +80,29 @@ func TestPostgresDatastore(t *testing.T) {
 				}))
 			})
 
+			t.Run("WithReadReplica", func(t *testing.T) {
+				// Use the primary as its own read replica, so that every snapshot read which has
+				// been replayed is routed through the replica.
+				test.All(t, test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+					ds := b.NewDatastore(t, func(engine, uri string) datastore.Datastore {
+						ds, err := newPostgresDatastore(uri,
+							RevisionQuantization(revisionQuantization),
+							GCWindow(gcWindow),
+							GCInterval(gcInterval),
+							WatchBufferLength(watchBufferLength),
+							DebugAnalyzeBeforeStatistics(),
+							ReadReplicaConnURIs([]string{uri}),
+							ReadReplicaPollInterval(10*time.Millisecond),
+							MigrationPhase(config.migrationPhase),
+						)
+						require.NoError(t, err)
+						return ds
+					})
+
+					return ds, nil
+				}))
+			})
+
 			t.Run("GarbageCollection", createDatastoreTest(
 				b,
 				GarbageCollectionTest,
@@ -832,6 +858,7 @@ func WatchNotEnabledTest(t *testing.T, _ testdatastore.RunningEngineForTest) {
 	_, errChan := ds.Watch(
 		context.Background(),
 		revision,
+		datastore.WatchJustRelationships(),
 	)
 	err := <-errChan
 	require.NotNil(err)
@@ -32,7 +32,16 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
-	).From(tableTuple)
+		colExpiration,
+		colIntegrity,
+	).From(tableTuple).Where(notExpiredClause)
+
+	// notExpiredClause filters out relationships whose expiration time has passed. Expired
+	// relationships are marked as deleted by garbage collection.
+	notExpiredClause = sq.Or{sq.Eq{colExpiration: nil}, sq.Expr(colExpiration + " > NOW()")}
+
+	// expiredClause matches relationships whose expiration time has passed.
+	expiredClause = sq.Expr(colExpiration + " <= NOW()")
 
 	schema = common.NewSchemaInformation(
 		colNamespace,
@@ -90,6 +99,8 @@ func (r *pgReader) ReverseQueryRelationships(
 	return r.querySplitter.SplitAndExecuteQuery(ctx,
 		qBuilder,
 		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
 	)
 }
 
@@ -11,16 +11,20 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 const (
-	errUnableToWriteConfig         = "unable to write namespace config: %w"
-	errUnableToDeleteConfig        = "unable to delete namespace config: %w"
-	errUnableToWriteRelationships  = "unable to write relationships: %w"
-	errUnableToDeleteRelationships = "unable to delete relationships: %w"
+	errUnableToWriteConfig           = "unable to write namespace config: %w"
+	errUnableToDeleteConfig          = "unable to delete namespace config: %w"
+	errUnableToWriteRelationships    = "unable to write relationships: %w"
+	errUnableToDeleteRelationships   = "unable to delete relationships: %w"
+	errUnableToBulkLoadRelationships = "unable to bulk load relationships: %w"
+	errUnableToReadCounts            = "unable to read relationship counts: %w"
 )
 
 var (
@@ -42,9 +46,44 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
+		colIntegrity,
 	)
 
 	deleteTuple = psql.Update(tableTuple).Where(sq.Eq{colDeletedXid: liveDeletedTxnID})
+
+	upsertCounts = psql.Insert(tableCounts).Columns(colNamespace, colRelation, colShard, colCount).Suffix(fmt.Sprintf(
+		"ON CONFLICT (%[1]s, %[2]s, %[3]s) DO UPDATE SET %[4]s = %[5]s.%[4]s + EXCLUDED.%[4]s",
+		colNamespace, colRelation, colShard, colCount, tableCounts,
+	))
+
+	readCounts = psql.Select(colNamespace, colRelation, fmt.Sprintf("SUM(%s)::BIGINT", colCount)).
+			From(tableCounts).
+			GroupBy(colNamespace, colRelation).
+			Having(fmt.Sprintf("SUM(%s) > 0", colCount))
+
+	readCountedNamespaces = psql.Select(colNamespace).From(tableCounted)
+
+	writeCountedNamespace = psql.Insert(tableCounted).Columns(colNamespace)
+
+	// The existing relationships of a namespace are counted into the first shard of each count.
+	backfillCounts = psql.Select(colNamespace, colRelation, "0", "COUNT(*)").
+			From(tableTuple).
+			Where(sq.Eq{colDeletedXid: liveDeletedTxnID}).
+			GroupBy(colNamespace, colRelation)
+
+	copyCols = []string{
+		colNamespace,
+		colObjectID,
+		colRelation,
+		colUsersetNamespace,
+		colUsersetObjectID,
+		colUsersetRelation,
+		colCaveatContextName,
+		colCaveatContext,
+		colExpiration,
+		colIntegrity,
+	}
 )
 
 type pgReadWriteTXN struct {
@@ -57,6 +96,7 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 	bulkWrite := writeTuple
 	bulkWriteHasValues := false
 	deleteClauses := sq.Or{}
+	deltas := common.RelationshipCountDeltas{}
 
 	// Process the actual updates
 	for _, mut := range mutations {
@@ -66,6 +106,11 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 			deleteClauses = append(deleteClauses, exactRelationshipClause(tpl))
 		}
 
+		// An expired relationship which has not yet been garbage collected is replaced.
+		if mut.Operation == core.RelationTupleUpdate_CREATE {
+			deleteClauses = append(deleteClauses, sq.And{exactRelationshipClause(tpl), expiredClause})
+		}
+
 		if mut.Operation == core.RelationTupleUpdate_TOUCH || mut.Operation == core.RelationTupleUpdate_CREATE {
 			var caveatName string
 			var caveatContext map[string]any
@@ -73,6 +118,12 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 				caveatName = tpl.Caveat.CaveatName
 				caveatContext = tpl.Caveat.Context.AsMap()
 			}
+
+			integrity, err := common.IntegrityOf(tpl)
+			if err != nil {
+				return fmt.Errorf(errUnableToWriteRelationships, err)
+			}
+
 			valuesToWrite := []interface{}{
 				tpl.ResourceAndRelation.Namespace,
 				tpl.ResourceAndRelation.ObjectId,
@@ -82,23 +133,18 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 				tpl.Subject.Relation,
 				caveatName,
 				caveatContext, // PGX driver serializes map[string]any to JSONB type columns
+				common.ExpirationTimeOf(tpl),
+				integrity,
 			}
 
 			bulkWrite = bulkWrite.Values(valuesToWrite...)
 			bulkWriteHasValues = true
+			deltas.Add(tpl.ResourceAndRelation.Namespace, tpl.ResourceAndRelation.Relation, 1)
 		}
 	}
 
 	if len(deleteClauses) > 0 {
-		sql, args, err := deleteTuple.
-			Where(deleteClauses).
-			Set(colDeletedXid, rwt.newXID).
-			ToSql()
-		if err != nil {
-			return fmt.Errorf(errUnableToWriteRelationships, err)
-		}
-
-		if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+		if _, err := rwt.deleteTuples(ctx, deleteTuple.Where(deleteClauses), deltas); err != nil {
 			return fmt.Errorf(errUnableToWriteRelationships, err)
 		}
 	}
@@ -120,40 +166,221 @@ func (rwt *pgReadWriteTXN) WriteRelationships(ctx context.Context, mutations []*
 		}
 	}
 
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
 	return nil
 }
 
-func (rwt *pgReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+// deleteTuples marks the tuples matched by the query as deleted, and adds their removal to
+// the deltas. Returns the number of tuples deleted.
+func (rwt *pgReadWriteTXN) deleteTuples(ctx context.Context, query sq.UpdateBuilder, deltas common.RelationshipCountDeltas) (int64, error) {
+	sql, args, err := query.
+		Set(colDeletedXid, rwt.newXID).
+		Suffix(fmt.Sprintf("RETURNING %s, %s", colNamespace, colRelation)).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := rwt.tx.Query(ctx, fmt.Sprintf(
+		"WITH deleted AS (%s) SELECT %s, %s, COUNT(*) FROM deleted GROUP BY %s, %s",
+		sql, colNamespace, colRelation, colNamespace, colRelation,
+	), args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total int64
+	for rows.Next() {
+		var namespace, relation string
+		var count int64
+		if err := rows.Scan(&namespace, &relation, &count); err != nil {
+			return 0, err
+		}
+		deltas.Add(namespace, relation, -count)
+		total += count
+	}
+	return total, rows.Err()
+}
+
+// updateRelationshipCounts applies the deltas to the maintained relationship counts, for the
+// namespaces whose counts are maintained. The deltas are added to a randomly selected shard of
+// each count.
+func (rwt *pgReadWriteTXN) updateRelationshipCounts(ctx context.Context, deltas common.RelationshipCountDeltas) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	counted, err := rwt.countedNamespaces(ctx, deltas.Namespaces())
+	if err != nil {
+		return err
+	}
+	if len(counted) == 0 {
+		return nil
+	}
+
+	shard := common.RandomRelationshipCountShard()
+	query := upsertCounts
+	for key, delta := range deltas {
+		if _, ok := counted[key.Definition]; ok {
+			query = query.Values(key.Definition, key.Relation, shard, delta)
+		}
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = rwt.tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// countedNamespaces returns the set of the given namespaces whose relationship counts are
+// maintained.
+func (rwt *pgReadWriteTXN) countedNamespaces(ctx context.Context, namespaces []string) (map[string]struct{}, error) {
+	sql, args, err := readCountedNamespaces.Where(sq.Eq{colNamespace: namespaces}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := rwt.tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counted := make(map[string]struct{}, len(namespaces))
+	for rows.Next() {
+		var namespace string
+		if err := rows.Scan(&namespace); err != nil {
+			return nil, err
+		}
+		counted[namespace] = struct{}{}
+	}
+	return counted, rows.Err()
+}
+
+// startCounting starts maintaining the relationship counts of the given namespaces which are
+// not yet counted, by counting their existing relationships.
+func (rwt *pgReadWriteTXN) startCounting(ctx context.Context, namespaces []string) error {
+	counted, err := rwt.countedNamespaces(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+
+	uncounted := make([]string, 0, len(namespaces))
+	insertQuery := writeCountedNamespace
+	for _, namespace := range namespaces {
+		if _, ok := counted[namespace]; !ok {
+			counted[namespace] = struct{}{}
+			uncounted = append(uncounted, namespace)
+			insertQuery = insertQuery.Values(namespace)
+		}
+	}
+	if len(uncounted) == 0 {
+		return nil
+	}
+
+	sql, args, err := insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, err = psql.Insert(tableCounts).
+		Columns(colNamespace, colRelation, colShard, colCount).
+		Select(backfillCounts.Where(sq.Eq{colNamespace: uncounted})).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = rwt.tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func (rwt *pgReadWriteTXN) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	if err := rwt.startCounting(ctx, definitions); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	sql, args, err := readCounts.Where(sq.Eq{colNamespace: definitions}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	rows, err := rwt.tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[datastore.RelationKey]uint64)
+	for rows.Next() {
+		var key datastore.RelationKey
+		var count int64
+		if err := rows.Scan(&key.Definition, &key.Relation, &count); err != nil {
+			return nil, fmt.Errorf(errUnableToReadCounts, err)
+		}
+		counts[key] = uint64(count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+	return counts, nil
+}
+
+func (rwt *pgReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
 	// Add clauses for the ResourceFilter
-	query := deleteTuple.Where(sq.Eq{colNamespace: filter.ResourceType})
+	clauses := sq.And{sq.Eq{colNamespace: filter.ResourceType}}
 	if filter.OptionalResourceId != "" {
-		query = query.Where(sq.Eq{colObjectID: filter.OptionalResourceId})
+		clauses = append(clauses, sq.Eq{colObjectID: filter.OptionalResourceId})
 	}
 	if filter.OptionalRelation != "" {
-		query = query.Where(sq.Eq{colRelation: filter.OptionalRelation})
+		clauses = append(clauses, sq.Eq{colRelation: filter.OptionalRelation})
 	}
 
 	// Add clauses for the SubjectFilter
 	if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
-		query = query.Where(sq.Eq{colUsersetNamespace: subjectFilter.SubjectType})
+		clauses = append(clauses, sq.Eq{colUsersetNamespace: subjectFilter.SubjectType})
 		if subjectFilter.OptionalSubjectId != "" {
-			query = query.Where(sq.Eq{colUsersetObjectID: subjectFilter.OptionalSubjectId})
+			clauses = append(clauses, sq.Eq{colUsersetObjectID: subjectFilter.OptionalSubjectId})
 		}
 		if relationFilter := subjectFilter.OptionalRelation; relationFilter != nil {
-			query = query.Where(sq.Eq{colUsersetRelation: stringz.DefaultEmpty(relationFilter.Relation, datastore.Ellipsis)})
+			clauses = append(clauses, sq.Eq{colUsersetRelation: stringz.DefaultEmpty(relationFilter.Relation, datastore.Ellipsis)})
 		}
 	}
 
-	sql, args, err := query.Set(colDeletedXid, rwt.newXID).ToSql()
+	query := deleteTuple.Where(clauses)
+
+	// Postgres does not support a limit on updates, so the rows to delete are selected by a
+	// limited subquery.
+	delOpts := options.NewDeleteOptionsWithOptions(opts...)
+	if delOpts.DeleteLimit != nil {
+		query = query.Where(sq.Expr("ctid IN (?)", sq.Select("ctid").
+			From(tableTuple).
+			Where(sq.Eq{colDeletedXid: liveDeletedTxnID}).
+			Where(clauses).
+			Limit(*delOpts.DeleteLimit),
+		))
+	}
+
+	deltas := common.RelationshipCountDeltas{}
+	deleted, err := rwt.deleteTuples(ctx, query, deltas)
 	if err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+		return false, fmt.Errorf(errUnableToDeleteRelationships, err)
 	}
 
-	if _, err := rwt.tx.Exec(ctx, sql, args...); err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return false, fmt.Errorf(errUnableToDeleteRelationships, err)
 	}
 
-	return nil
+	return delOpts.DeleteLimit != nil && uint64(deleted) == *delOpts.DeleteLimit, nil
 }
 
 func (rwt *pgReadWriteTXN) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
@@ -233,16 +460,12 @@ func (rwt *pgReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...stri
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
-	deleteTupleSQL, deleteTupleArgs, err := deleteNamespaceTuples.
-		Set(colDeletedXid, rwt.newXID).
-		Where(sq.Or(tplClauses)).
-		ToSql()
-	if err != nil {
+	deltas := common.RelationshipCountDeltas{}
+	if _, err := rwt.deleteTuples(ctx, deleteNamespaceTuples.Where(sq.Or(tplClauses)), deltas); err != nil {
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
-	_, err = rwt.tx.Exec(ctx, deleteTupleSQL, deleteTupleArgs...)
-	if err != nil {
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
 		return fmt.Errorf(errUnableToDeleteConfig, err)
 	}
 
@@ -260,4 +483,24 @@ func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
 	}
 }
 
+func (rwt *pgReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	deltas := common.RelationshipCountDeltas{}
+	loaded, err := pgxcommon.BulkLoad(ctx, rwt.tx, tableTuple, copyCols, common.NewCountingBulkSource(iter, deltas))
+	if err != nil {
+		// If a unique constraint violation is returned, then its likely that the cause
+		// was an existing relationship.
+		if cerr := pgxcommon.ConvertToWriteConstraintError(livingTupleConstraint, err); cerr != nil {
+			return 0, cerr
+		}
+
+		return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+	}
+
+	if err := rwt.updateRelationshipCounts(ctx, deltas); err != nil {
+		return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+	}
+
+	return loaded, nil
+}
+
 var _ datastore.ReadWriteTransaction = &pgReadWriteTXN{}
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	// queryReplicaState returns the snapshot of the transactions replayed by a replica, and the
+	// time elapsed since the last replayed transaction was committed on the primary.
+	queryReplicaState = `SELECT pg_current_snapshot(), COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0);`
+
+	primaryReadTarget = "primary"
+)
+
+var (
+	replicaHealthyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "postgres_read_replica_healthy",
+		Help:      "Whether the postgres read replica responded to the most recent poll of its replay state.",
+	}, []string{"replica"})
+
+	replicaLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "postgres_read_replica_lag_seconds",
+		Help:      "The time since the commit of the last transaction replayed by the postgres read replica.",
+	}, []string{"replica"})
+
+	snapshotReadsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "postgres_snapshot_reads_total",
+		Help:      "The number of snapshot readers created, by the read replica or primary they were routed to.",
+	}, []string{"target"})
+)
+
+func registerReadReplicaMetrics() error {
+	for _, metric := range []prometheus.Collector{
+		replicaHealthyGauge,
+		replicaLagGauge,
+		snapshotReadsCounter,
+	} {
+		if err := prometheus.Register(metric); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readReplica is a read-only replica of the primary, which serves snapshot reads at revisions
+// it has already replayed.
+type readReplica struct {
+	name string
+	pool pgxcommon.ConnPooler
+
+	sync.RWMutex
+	healthy  bool
+	snapshot pgSnapshot
+}
+
+func newReadReplica(ctx context.Context, url string, config postgresOptions) (*readReplica, error) {
+	poolConfig, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	config.readPoolOpts.ConfigurePgx(poolConfig)
+	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		RegisterTypes(conn.TypeMap())
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &readReplica{
+		// The name is used in logs and metrics, so must not include the credentials.
+		name: fmt.Sprintf("%s:%d", poolConfig.ConnConfig.Host, poolConfig.ConnConfig.Port),
+		pool: pgxcommon.MustNewInterceptorPooler(pool, config.queryInterceptor),
+	}, nil
+}
+
+// refresh polls the replica for the transactions it has replayed.
+func (rr *readReplica) refresh(ctx context.Context) {
+	var snapshot pgSnapshot
+	var lagSeconds float64
+	err := rr.pool.QueryRow(ctx, queryReplicaState).Scan(&snapshot, &lagSeconds)
+
+	rr.Lock()
+	defer rr.Unlock()
+
+	if err != nil {
+		if rr.healthy {
+			log.Ctx(ctx).Warn().Err(err).Str("replica", rr.name).Msg("postgres read replica is unhealthy, reads will be routed elsewhere")
+		}
+		rr.healthy = false
+		replicaHealthyGauge.WithLabelValues(rr.name).Set(0)
+		return
+	}
+
+	if !rr.healthy {
+		log.Ctx(ctx).Info().Str("replica", rr.name).Msg("postgres read replica is healthy")
+	}
+	rr.healthy = true
+	rr.snapshot = snapshot
+	replicaHealthyGauge.WithLabelValues(rr.name).Set(1)
+	replicaLagGauge.WithLabelValues(rr.name).Set(lagSeconds)
+}
+
+// canServe returns true if the replica is healthy and had replayed every transaction visible
+// at the revision when it was last polled.
+func (rr *readReplica) canServe(rev postgresRevision) bool {
+	rr.RLock()
+	defer rr.RUnlock()
+
+	if !rr.healthy {
+		return false
+	}
+
+	switch rev.snapshot.compare(rr.snapshot) {
+	case equal, lt:
+		return true
+	default:
+		return false
+	}
+}
+
+// readReplicaSet routes snapshot reads to the read replicas which can serve them, in turn.
+type readReplicaSet struct {
+	replicas []*readReplica
+	next     atomic.Uint32
+
+	cancelPolling context.CancelFunc
+	pollingDone   chan struct{}
+}
+
+func newReadReplicaSet(ctx context.Context, urls []string, config postgresOptions) (*readReplicaSet, error) {
+	replicas := make([]*readReplica, 0, len(urls))
+	for _, url := range urls {
+		replica, err := newReadReplica(ctx, url, config)
+		if err != nil {
+			for _, created := range replicas {
+				created.pool.Close()
+			}
+			return nil, fmt.Errorf("unable to connect to read replica: %w", err)
+		}
+
+		// A replica which cannot be reached yet is not an error; it is polled again until it is.
+		replica.refresh(ctx)
+		replicas = append(replicas, replica)
+	}
+
+	pollingCtx, cancelPolling := context.WithCancel(context.Background())
+	rrs := &readReplicaSet{
+		replicas:      replicas,
+		cancelPolling: cancelPolling,
+		pollingDone:   make(chan struct{}),
+	}
+
+	go rrs.poll(pollingCtx, config.readReplicaPollInterval)
+
+	return rrs, nil
+}
+
+func (rrs *readReplicaSet) poll(ctx context.Context, interval time.Duration) {
+	defer close(rrs.pollingDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, replica := range rrs.replicas {
+				refreshCtx, cancel := context.WithTimeout(ctx, interval)
+				replica.refresh(refreshCtx)
+				cancel()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poolFor returns the pool of a read replica which can serve reads at the revision, or nil if
+// none can.
+func (rrs *readReplicaSet) poolFor(rev postgresRevision) pgxcommon.ConnPooler {
+	start := rrs.next.Add(1)
+	for i := range rrs.replicas {
+		replica := rrs.replicas[(int(start)+i)%len(rrs.replicas)]
+		if replica.canServe(rev) {
+			snapshotReadsCounter.WithLabelValues(replica.name).Inc()
+			return replica.pool
+		}
+	}
+
+	snapshotReadsCounter.WithLabelValues(primaryReadTarget).Inc()
+	return nil
+}
+
+func (rrs *readReplicaSet) Close() {
+	rrs.cancelPolling()
+	<-rrs.pollingDone
+
+	for _, replica := range rrs.replicas {
+		replica.pool.Close()
+	}
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pgxcommon "github.com/authzed/spicedb/internal/datastore/postgres/common"
+)
+
+func TestReadReplicaCanServe(t *testing.T) {
+	testCases := []struct {
+		replicaSnapshot pgSnapshot
+		healthy         bool
+		revision        pgSnapshot
+		expected        bool
+	}{
+		{snap(5, 5), true, snap(5, 5), true},
+		{snap(5, 5), true, snap(3, 5, 3), true},
+		{snap(5, 5), true, snap(5, 6), false},
+		{snap(5, 5), true, snap(4, 4).markComplete(5), false},
+		{snap(3, 6, 3), true, snap(5, 5), false},
+		{snap(3, 6, 3), true, snap(3, 5, 3), true},
+		{snap(5, 5), false, snap(5, 5), false},
+		{snap(10, 10), false, snap(5, 5), false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s(%t)>=%s", tc.replicaSnapshot, tc.healthy, tc.revision), func(t *testing.T) {
+			replica := &readReplica{healthy: tc.healthy, snapshot: tc.replicaSnapshot}
+			require.Equal(t, tc.expected, replica.canServe(postgresRevision{tc.revision}))
+		})
+	}
+}
+
+type namedPool struct {
+	pgxcommon.ConnPooler
+	name string
+}
+
+func TestReadReplicaSetPoolFor(t *testing.T) {
+	require := require.New(t)
+
+	newReplica := func(name string, healthy bool, snapshot pgSnapshot) *readReplica {
+		return &readReplica{
+			name:     name,
+			pool:     namedPool{name: name},
+			healthy:  healthy,
+			snapshot: snapshot,
+		}
+	}
+
+	rrs := &readReplicaSet{
+		replicas: []*readReplica{
+			newReplica("behind", true, snap(3, 3)),
+			newReplica("unhealthy", false, snap(10, 10)),
+			newReplica("caughtup1", true, snap(10, 10)),
+			newReplica("caughtup2", true, snap(8, 8)),
+		},
+	}
+
+	// Reads are spread over the replicas which have replayed the revision.
+	chosen := map[string]int{}
+	for i := 0; i < 20; i++ {
+		pool := rrs.poolFor(postgresRevision{snap(5, 5)})
+		require.NotNil(pool)
+		chosen[pool.(namedPool).name]++
+	}
+	require.Len(chosen, 2)
+	require.Positive(chosen["caughtup1"])
+	require.Positive(chosen["caughtup2"])
+
+	// Reads at revisions no replica has replayed fall back to the primary.
+	require.Nil(rrs.poolFor(postgresRevision{snap(12, 12)}))
+}
@@ -84,3 +84,92 @@ func (pgd *pgDatastore) Statistics(ctx context.Context) (datastore.Stats, error)
 		EstimatedRelationshipCount: relCountUint,
 	}, nil
 }
+
+// detailedStatsSampleSize is the estimated number of rows in the tuple table up to which
+// relationships are counted exactly. Larger tables are counted from a sample of roughly
+// this many rows, scaled up to the estimated size of the table.
+const detailedStatsSampleSize = 1_000_000
+
+var (
+	queryTupleStorageSize          = fmt.Sprintf("SELECT pg_total_relation_size('%s')", tableTuple)
+	queryEstimatedTransactionCount = psql.
+					Select(colReltuples).
+					From(tablePGClass).
+					Where(sq.Eq{colRelname: tableTransaction})
+)
+
+func (pgd *pgDatastore) DetailedStatistics(ctx context.Context) (datastore.DetailedStats, error) {
+	rowCountSQL, rowCountArgs, err := queryEstimatedRowCount.ToSql()
+	if err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to prepare row count sql: %w", err)
+	}
+
+	txCountSQL, txCountArgs, err := queryEstimatedTransactionCount.ToSql()
+	if err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to prepare transaction count sql: %w", err)
+	}
+
+	var stats datastore.DetailedStats
+	if err := pgx.BeginTxFunc(ctx, pgd.readPool, pgd.readTxOptions, func(tx pgx.Tx) error {
+		var estimatedRows, estimatedTransactions float64
+		if err := tx.QueryRow(ctx, rowCountSQL, rowCountArgs...).Scan(&estimatedRows); err != nil {
+			return fmt.Errorf("unable to read relationship count: %w", err)
+		}
+		if err := tx.QueryRow(ctx, txCountSQL, txCountArgs...).Scan(&estimatedTransactions); err != nil {
+			return fmt.Errorf("unable to read transaction count: %w", err)
+		}
+		if err := tx.QueryRow(ctx, queryTupleStorageSize).Scan(&stats.StorageSizeBytes); err != nil {
+			return fmt.Errorf("unable to read storage size: %w", err)
+		}
+
+		// The row count estimates are negative before the table is first analyzed.
+		if estimatedTransactions > 0 {
+			stats.ChangelogBacklog = uint64(estimatedTransactions)
+		}
+
+		from := tableTuple
+		scale := 1.0
+		if estimatedRows > detailedStatsSampleSize {
+			percent := 100 * detailedStatsSampleSize / estimatedRows
+			from = fmt.Sprintf("%s TABLESAMPLE SYSTEM (%f)", tableTuple, percent)
+			scale = 100 / percent
+			stats.CountsAreEstimates = true
+		}
+
+		sql, args, err := psql.Select(colNamespace, colRelation, "COUNT(*)").
+			From(from).
+			Where(sq.Eq{colDeletedXid: liveDeletedTxnID}).
+			Where(notExpiredClause).
+			GroupBy(colNamespace, colRelation).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to prepare relation count sql: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return fmt.Errorf("unable to count relationships: %w", err)
+		}
+		defer rows.Close()
+
+		counts := make(map[datastore.RelationKey]uint64)
+		for rows.Next() {
+			var key datastore.RelationKey
+			var count uint64
+			if err := rows.Scan(&key.Definition, &key.Relation, &count); err != nil {
+				return fmt.Errorf("unable to count relationships: %w", err)
+			}
+			counts[key] = uint64(float64(count) * scale)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("unable to count relationships: %w", err)
+		}
+
+		stats.Definitions = datastore.ComputeDefinitionStats(counts)
+		return nil
+	}); err != nil {
+		return datastore.DetailedStats{}, err
+	}
+
+	return stats, nil
+}
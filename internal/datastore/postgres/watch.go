@@ -43,14 +43,30 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colExpiration,
 		colCreatedXid,
 		colDeletedXid,
 	).From(tableTuple)
+
+	queryChangedNamespaces = psql.Select(
+		colNamespace,
+		colConfig,
+		colCreatedXid,
+		colDeletedXid,
+	).From(tableNamespace)
+
+	queryChangedCaveats = psql.Select(
+		colCaveatName,
+		colCaveatDefinition,
+		colCreatedXid,
+		colDeletedXid,
+	).From(tableCaveat)
 )
 
 func (pgd *pgDatastore) Watch(
 	ctx context.Context,
 	afterRevisionRaw datastore.Revision,
+	options datastore.WatchOptions,
 ) (<-chan *datastore.RevisionChanges, <-chan error) {
 	updates := make(chan *datastore.RevisionChanges, pgd.watchBufferLength)
 	errs := make(chan error, 1)
@@ -67,6 +83,7 @@ func (pgd *pgDatastore) Watch(
 		defer close(errs)
 
 		currentTxn := afterRevision
+		lastCheckpoint := time.Now()
 
 		for {
 			newTxns, err := pgd.getNewRevisions(ctx, currentTxn)
@@ -80,7 +97,7 @@ func (pgd *pgDatastore) Watch(
 			}
 
 			if len(newTxns) > 0 {
-				changesToWrite, err := pgd.loadChanges(ctx, newTxns)
+				changesToWrite, err := pgd.loadChanges(ctx, newTxns, options)
 				if err != nil {
 					if errors.Is(ctx.Err(), context.Canceled) {
 						errs <- datastore.NewWatchCanceledErr()
@@ -100,10 +117,24 @@ func (pgd *pgDatastore) Watch(
 						errs <- datastore.NewWatchDisconnectedErr()
 						return
 					}
+				}
+
+				// Advance past all of the loaded transactions, including those without any
+				// watched changes, so that they are not loaded again.
+				currentTxn = newTxns[len(newTxns)-1].postgresRevision
+			}
 
-					currentTxn = changeToWrite.Revision.(revisionWithXid).postgresRevision
+			if options.CheckpointInterval > 0 && time.Since(lastCheckpoint) >= options.CheckpointInterval {
+				select {
+				case updates <- &datastore.RevisionChanges{Revision: currentTxn, IsCheckpoint: true}:
+					lastCheckpoint = time.Now()
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
 				}
-			} else {
+			}
+
+			if len(newTxns) == 0 {
 				sleep := time.NewTimer(watchSleep)
 
 				select {
@@ -152,7 +183,7 @@ func (pgd *pgDatastore) getNewRevisions(ctx context.Context, afterTX postgresRev
 	return ids, nil
 }
 
-func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWithXid) ([]datastore.RevisionChanges, error) {
+func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWithXid, options datastore.WatchOptions) ([]datastore.RevisionChanges, error) {
 	min := revisions[0].tx.Uint64
 	max := revisions[0].tx.Uint64
 	filter := make(map[uint64]int, len(revisions))
@@ -169,7 +200,7 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 		txidToRevision[rev.tx.Uint64] = rev
 	}
 
-	sql, args, err := queryChanged.Where(sq.Or{
+	inRange := sq.Or{
 		sq.And{
 			sq.LtOrEq{colCreatedXid: max},
 			sq.GtOrEq{colCreatedXid: min},
@@ -178,17 +209,46 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 			sq.LtOrEq{colDeletedXid: max},
 			sq.GtOrEq{colDeletedXid: min},
 		},
-	}).ToSql()
+	}
+
+	tracked := common.NewChanges(revisionKeyFunc)
+
+	if options.Includes(datastore.WatchRelationships) {
+		if err := pgd.loadRelationshipChanges(ctx, inRange, filter, txidToRevision, tracked); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Includes(datastore.WatchSchema) {
+		if err := pgd.loadSchemaChanges(ctx, inRange, filter, txidToRevision, tracked); err != nil {
+			return nil, err
+		}
+	}
+
+	reconciledChanges := tracked.AsRevisionChanges(func(lhs, rhs uint64) bool {
+		return filter[lhs] < filter[rhs]
+	})
+	return reconciledChanges, nil
+}
+
+func (pgd *pgDatastore) loadRelationshipChanges(
+	ctx context.Context,
+	inRange sq.Sqlizer,
+	filter map[uint64]int,
+	txidToRevision map[uint64]revisionWithXid,
+	tracked common.Changes[revisionWithXid, uint64],
+) error {
+	sql, args, err := queryChanged.Where(inRange).ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("unable to prepare changes SQL: %w", err)
+		return fmt.Errorf("unable to prepare changes SQL: %w", err)
 	}
 
 	changes, err := pgd.readPool.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load changes for XID: %w", err)
+		return fmt.Errorf("unable to load changes for XID: %w", err)
 	}
+	defer changes.Close()
 
-	tracked := common.NewChanges(revisionKeyFunc)
 	for changes.Next() {
 		nextTuple := &core.RelationTuple{
 			ResourceAndRelation: &core.ObjectAndRelation{},
@@ -198,6 +258,7 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 		var createdXID, deletedXID xid8
 		var caveatName string
 		var caveatContext map[string]any
+		var expiration *time.Time
 		if err := changes.Scan(
 			&nextTuple.ResourceAndRelation.Namespace,
 			&nextTuple.ResourceAndRelation.ObjectId,
@@ -207,22 +268,24 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 			&nextTuple.Subject.Relation,
 			&caveatName,
 			&caveatContext,
+			&expiration,
 			&createdXID,
 			&deletedXID,
 		); err != nil {
-			return nil, fmt.Errorf("unable to parse changed tuple: %w", err)
+			return fmt.Errorf("unable to parse changed tuple: %w", err)
 		}
 
 		if caveatName != "" {
 			contextStruct, err := structpb.NewStruct(caveatContext)
 			if err != nil {
-				return nil, fmt.Errorf("failed to read caveat context from update: %w", err)
+				return fmt.Errorf("failed to read caveat context from update: %w", err)
 			}
 			nextTuple.Caveat = &core.ContextualizedCaveat{
 				CaveatName: caveatName,
 				Context:    contextStruct,
 			}
 		}
+		nextTuple.OptionalExpirationTime = common.ExpirationTimeFrom(expiration)
 
 		if _, found := filter[createdXID.Uint64]; found {
 			tracked.AddChange(ctx, txidToRevision[createdXID.Uint64], nextTuple, core.RelationTupleUpdate_TOUCH)
@@ -232,11 +295,89 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 		}
 	}
 	if changes.Err() != nil {
-		return nil, fmt.Errorf("unable to load changes for XID: %w", err)
+		return fmt.Errorf("unable to load changes for XID: %w", changes.Err())
 	}
 
-	reconciledChanges := tracked.AsRevisionChanges(func(lhs, rhs uint64) bool {
-		return filter[lhs] < filter[rhs]
-	})
-	return reconciledChanges, nil
+	return nil
+}
+
+func (pgd *pgDatastore) loadSchemaChanges(
+	ctx context.Context,
+	inRange sq.Sqlizer,
+	filter map[uint64]int,
+	txidToRevision map[uint64]revisionWithXid,
+	tracked common.Changes[revisionWithXid, uint64],
+) error {
+	// Namespaces and caveats are rewritten by marking the previous row as deleted and
+	// inserting a new one in the same transaction, which the change tracker reconciles
+	// into a single write.
+	sql, args, err := queryChangedNamespaces.Where(inRange).ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to prepare changed namespaces SQL: %w", err)
+	}
+
+	rows, err := pgd.readPool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("unable to load changed namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var config []byte
+		var createdXID, deletedXID xid8
+		if err := rows.Scan(&name, &config, &createdXID, &deletedXID); err != nil {
+			return fmt.Errorf("unable to parse changed namespace: %w", err)
+		}
+
+		if _, found := filter[createdXID.Uint64]; found {
+			loaded := &core.NamespaceDefinition{}
+			if err := loaded.UnmarshalVT(config); err != nil {
+				return fmt.Errorf(errUnableToReadConfig, err)
+			}
+			tracked.AddChangedDefinition(ctx, txidToRevision[createdXID.Uint64], loaded)
+		}
+		if _, found := filter[deletedXID.Uint64]; found {
+			tracked.AddDeletedNamespace(ctx, txidToRevision[deletedXID.Uint64], name)
+		}
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("unable to load changed namespaces: %w", rows.Err())
+	}
+
+	sql, args, err = queryChangedCaveats.Where(inRange).ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to prepare changed caveats SQL: %w", err)
+	}
+
+	caveatRows, err := pgd.readPool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("unable to load changed caveats: %w", err)
+	}
+	defer caveatRows.Close()
+
+	for caveatRows.Next() {
+		var name string
+		var definition []byte
+		var createdXID, deletedXID xid8
+		if err := caveatRows.Scan(&name, &definition, &createdXID, &deletedXID); err != nil {
+			return fmt.Errorf("unable to parse changed caveat: %w", err)
+		}
+
+		if _, found := filter[createdXID.Uint64]; found {
+			loaded := &core.CaveatDefinition{}
+			if err := loaded.UnmarshalVT(definition); err != nil {
+				return fmt.Errorf(errReadCaveat, err)
+			}
+			tracked.AddChangedDefinition(ctx, txidToRevision[createdXID.Uint64], loaded)
+		}
+		if _, found := filter[deletedXID.Uint64]; found {
+			tracked.AddDeletedCaveat(ctx, txidToRevision[deletedXID.Uint64], name)
+		}
+	}
+	if caveatRows.Err() != nil {
+		return fmt.Errorf("unable to load changed caveats: %w", caveatRows.Err())
+	}
+
+	return nil
 }
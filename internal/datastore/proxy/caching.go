@@ -26,7 +26,7 @@ import (
 // all existing definitions found in consistency tests and is
 // enforced via the estimatedsize_test.
 const (
-	namespaceDefinitionSizeVTMultiplier = 10
+	namespaceDefinitionSizeVTMultiplier = 15
 	namespaceDefinitionMinimumSize      = 150
 
 	caveatDefinitionSizeVTMultiplier = 10
@@ -105,8 +105,8 @@ func (p *observableProxy) RevisionFromString(serialized string) (datastore.Revis
 	return p.delegate.RevisionFromString(serialized)
 }
 
-func (p *observableProxy) Watch(ctx context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
-	return p.delegate.Watch(ctx, afterRevision)
+func (p *observableProxy) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
+	return p.delegate.Watch(ctx, afterRevision, options)
 }
 
 func (p *observableProxy) Features(ctx context.Context) (*datastore.Features, error) {
@@ -123,6 +123,13 @@ func (p *observableProxy) Statistics(ctx context.Context) (datastore.Stats, erro
 	return p.delegate.Statistics(ctx)
 }
 
+func (p *observableProxy) DetailedStatistics(ctx context.Context) (datastore.DetailedStats, error) {
+	ctx, closer := observe(ctx, "DetailedStatistics")
+	defer closer()
+
+	return p.delegate.DetailedStatistics(ctx)
+}
+
 func (p *observableProxy) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
 	ctx, closer := observe(ctx, "ReadyState")
 	defer closer()
@@ -287,13 +294,27 @@ func (rwt *observableRWT) DeleteNamespaces(ctx context.Context, nsNames ...strin
 	return rwt.delegate.DeleteNamespaces(ctx, nsNames...)
 }
 
-func (rwt *observableRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+func (rwt *observableRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
 	ctx, closer := observe(ctx, "DeleteRelationships", trace.WithAttributes(
 		filterToAttributes(filter)...,
 	))
 	defer closer()
 
-	return rwt.delegate.DeleteRelationships(ctx, filter)
+	return rwt.delegate.DeleteRelationships(ctx, filter, options...)
+}
+
+func (rwt *observableRWT) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	ctx, closer := observe(ctx, "BulkLoad")
+	defer closer()
+
+	return rwt.delegate.BulkLoad(ctx, iter)
+}
+
+func (rwt *observableRWT) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	ctx, closer := observe(ctx, "RelationshipCounts")
+	defer closer()
+
+	return rwt.delegate.RelationshipCounts(ctx, definitions...)
 }
 
 func observe(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, func()) {
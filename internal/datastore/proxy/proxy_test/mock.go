@@ -54,7 +54,7 @@ func (dm *MockDatastore) RevisionFromString(s string) (datastore.Revision, error
 	return args.Get(0).(datastore.Revision), args.Error(1)
 }
 
-func (dm *MockDatastore) Watch(_ context.Context, afterRevision datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+func (dm *MockDatastore) Watch(_ context.Context, afterRevision datastore.Revision, _ datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
 	args := dm.Called(afterRevision)
 	return args.Get(0).(<-chan *datastore.RevisionChanges), args.Get(1).(<-chan error)
 }
@@ -74,6 +74,11 @@ func (dm *MockDatastore) Statistics(_ context.Context) (datastore.Stats, error)
 	return args.Get(0).(datastore.Stats), args.Error(1)
 }
 
+func (dm *MockDatastore) DetailedStatistics(_ context.Context) (datastore.DetailedStats, error) {
+	args := dm.Called()
+	return args.Get(0).(datastore.DetailedStats), args.Error(1)
+}
+
 func (dm *MockDatastore) Close() error {
 	args := dm.Called()
 	return args.Error(0)
@@ -241,9 +246,9 @@ func (dm *MockReadWriteTransaction) WriteRelationships(_ context.Context, mutati
 	return args.Error(0)
 }
 
-func (dm *MockReadWriteTransaction) DeleteRelationships(_ context.Context, filter *v1.RelationshipFilter) error {
-	args := dm.Called(filter)
-	return args.Error(0)
+func (dm *MockReadWriteTransaction) DeleteRelationships(_ context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
+	args := dm.Called(filter, options)
+	return args.Bool(0), args.Error(1)
 }
 
 func (dm *MockReadWriteTransaction) WriteNamespaces(_ context.Context, newConfigs ...*core.NamespaceDefinition) error {
@@ -261,6 +266,21 @@ func (dm *MockReadWriteTransaction) DeleteNamespaces(_ context.Context, nsNames
 	return args.Error(0)
 }
 
+func (dm *MockReadWriteTransaction) BulkLoad(_ context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	args := dm.Called(iter)
+	return uint64(args.Int(0)), args.Error(1)
+}
+
+func (dm *MockReadWriteTransaction) RelationshipCounts(_ context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	xs := make([]any, 0, len(definitions))
+	for _, definition := range definitions {
+		xs = append(xs, definition)
+	}
+
+	args := dm.Called(xs...)
+	return args.Get(0).(map[datastore.RelationKey]uint64), args.Error(1)
+}
+
 func (dm *MockReadWriteTransaction) ReadCaveatByName(_ context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
 	args := dm.Called(name)
 
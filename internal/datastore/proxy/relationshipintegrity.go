@@ -0,0 +1,286 @@
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ErrRelationshipIntegrity is returned when a relationship read from the datastore does not
+// carry a valid integrity hash.
+var ErrRelationshipIntegrity = errors.New("relationship failed integrity check")
+
+// KeyConfig is a key used to compute the integrity hashes of relationships.
+type KeyConfig struct {
+	// ID identifies the key and is stored alongside each hash computed with it.
+	ID string
+
+	// Bytes is the secret key itself.
+	Bytes []byte
+}
+
+// NewRelationshipIntegrityProxy creates a proxy which stores a keyed hash alongside every
+// relationship written, and verifies the hash of every relationship read.
+//
+// Hashes are always computed with the current key. Relationships hashed with one of the
+// expired keys are still verified, so that keys can be rotated without rewriting every
+// relationship at once. If logMismatches is true, relationships which fail verification are
+// logged and returned rather than failing the read.
+//
+// The hash covers the resource, subject, caveat name and caveat context of the relationship.
+// Expiration is not covered, as datastores store it with differing precision.
+func NewRelationshipIntegrityProxy(ds datastore.Datastore, currentKey KeyConfig, expiredKeys []KeyConfig, logMismatches bool) (datastore.Datastore, error) {
+	if currentKey.ID == "" {
+		return nil, fmt.Errorf("relationship integrity key must have an ID")
+	}
+	if len(currentKey.Bytes) == 0 {
+		return nil, fmt.Errorf("relationship integrity key %q is empty", currentKey.ID)
+	}
+
+	keys := map[string][]byte{currentKey.ID: currentKey.Bytes}
+	for _, key := range expiredKeys {
+		if _, ok := keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate relationship integrity key %q", key.ID)
+		}
+		if len(key.Bytes) == 0 {
+			return nil, fmt.Errorf("relationship integrity key %q is empty", key.ID)
+		}
+		keys[key.ID] = key.Bytes
+	}
+
+	return &relationshipIntegrityProxy{
+		Datastore:     ds,
+		currentKey:    currentKey,
+		keys:          keys,
+		logMismatches: logMismatches,
+	}, nil
+}
+
+type relationshipIntegrityProxy struct {
+	datastore.Datastore
+	currentKey    KeyConfig
+	keys          map[string][]byte
+	logMismatches bool
+}
+
+func (p *relationshipIntegrityProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &relationshipIntegrityReader{p.Datastore.SnapshotReader(rev), p}
+}
+
+func (p *relationshipIntegrityProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc) (datastore.Revision, error) {
+	return p.Datastore.ReadWriteTx(ctx, func(delegateRWT datastore.ReadWriteTransaction) error {
+		return f(&relationshipIntegrityRWT{&relationshipIntegrityReader{delegateRWT, p}, delegateRWT})
+	})
+}
+
+func (p *relationshipIntegrityProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+// withIntegrity returns a copy of the tuple with an integrity hash computed with the
+// current key.
+func (p *relationshipIntegrityProxy) withIntegrity(tpl *core.RelationTuple) (*core.RelationTuple, error) {
+	hash, err := computeRelationshipHash(p.currentKey.Bytes, tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	withIntegrity := tpl.CloneVT()
+	withIntegrity.Integrity = &core.RelationshipIntegrity{
+		KeyId: p.currentKey.ID,
+		Hash:  hash,
+	}
+	return withIntegrity, nil
+}
+
+func (p *relationshipIntegrityProxy) verify(tpl *core.RelationTuple) error {
+	if tpl.Integrity == nil {
+		return fmt.Errorf("%w: relationship %s has no integrity hash", ErrRelationshipIntegrity, tuple.StringWithoutCaveat(tpl))
+	}
+
+	key, ok := p.keys[tpl.Integrity.KeyId]
+	if !ok {
+		return fmt.Errorf("%w: relationship %s was hashed with unknown key %q", ErrRelationshipIntegrity, tuple.StringWithoutCaveat(tpl), tpl.Integrity.KeyId)
+	}
+
+	expected, err := computeRelationshipHash(key, tpl)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, tpl.Integrity.Hash) {
+		return fmt.Errorf("%w: relationship %s has an invalid integrity hash", ErrRelationshipIntegrity, tuple.StringWithoutCaveat(tpl))
+	}
+	return nil
+}
+
+// computeRelationshipHash computes the HMAC-SHA256 of the relationship, its expiration and its
+// caveat.
+func computeRelationshipHash(key []byte, tpl *core.RelationTuple) ([]byte, error) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(tuple.StringWithoutCaveat(tpl)))
+	mac.Write([]byte{0})
+
+	// The expiration is hashed at microsecond precision, which all datastores preserve, and as
+	// zero when the relationship does not expire.
+	var expiration [8]byte
+	if tpl.OptionalExpirationTime != nil {
+		binary.BigEndian.PutUint64(expiration[:], uint64(tpl.OptionalExpirationTime.AsTime().UnixMicro()))
+	}
+	mac.Write(expiration[:])
+
+	if tpl.Caveat != nil {
+		mac.Write([]byte(tpl.Caveat.CaveatName))
+		mac.Write([]byte{0})
+
+		// Map keys are sorted by deterministic marshaling, so the context hashes the same
+		// regardless of the order in which the datastore returns its fields.
+		serialized, err := proto.MarshalOptions{Deterministic: true}.Marshal(tpl.Caveat.Context)
+		if err != nil {
+			return nil, fmt.Errorf("unable to hash caveat context: %w", err)
+		}
+		mac.Write(serialized)
+	}
+
+	return mac.Sum(nil), nil
+}
+
+type relationshipIntegrityReader struct {
+	datastore.Reader
+	proxy *relationshipIntegrityProxy
+}
+
+func (r *relationshipIntegrityReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	iterator, err := r.Reader.QueryRelationships(ctx, filter, options...)
+	if err != nil {
+		return iterator, err
+	}
+	return &relationshipIntegrityIterator{ctx: ctx, proxy: r.proxy, delegate: iterator}, nil
+}
+
+func (r *relationshipIntegrityReader) ReverseQueryRelationships(ctx context.Context, subjectFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	iterator, err := r.Reader.ReverseQueryRelationships(ctx, subjectFilter, options...)
+	if err != nil {
+		return iterator, err
+	}
+	return &relationshipIntegrityIterator{ctx: ctx, proxy: r.proxy, delegate: iterator}, nil
+}
+
+type relationshipIntegrityIterator struct {
+	ctx      context.Context
+	proxy    *relationshipIntegrityProxy
+	delegate datastore.RelationshipIterator
+	err      error
+}
+
+func (i *relationshipIntegrityIterator) Next() *core.RelationTuple {
+	if i.err != nil {
+		return nil
+	}
+
+	next := i.delegate.Next()
+	if next == nil {
+		return nil
+	}
+
+	if err := i.proxy.verify(next); err != nil {
+		if !i.proxy.logMismatches || !errors.Is(err, ErrRelationshipIntegrity) {
+			i.err = err
+			return nil
+		}
+		log.Ctx(i.ctx).Warn().Err(err).Msg("relationship failed integrity check")
+	}
+	return next
+}
+
+func (i *relationshipIntegrityIterator) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.delegate.Err()
+}
+
+func (i *relationshipIntegrityIterator) Cursor() (options.Cursor, error) {
+	return i.delegate.Cursor()
+}
+
+func (i *relationshipIntegrityIterator) Close() { i.delegate.Close() }
+
+type relationshipIntegrityRWT struct {
+	*relationshipIntegrityReader
+	delegate datastore.ReadWriteTransaction
+}
+
+func (rwt *relationshipIntegrityRWT) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	hashed := make([]*core.RelationTupleUpdate, 0, len(mutations))
+	for _, mutation := range mutations {
+		if mutation.Operation == core.RelationTupleUpdate_DELETE {
+			hashed = append(hashed, mutation)
+			continue
+		}
+
+		tpl, err := rwt.proxy.withIntegrity(mutation.Tuple)
+		if err != nil {
+			return err
+		}
+		hashed = append(hashed, &core.RelationTupleUpdate{Operation: mutation.Operation, Tuple: tpl})
+	}
+	return rwt.delegate.WriteRelationships(ctx, hashed)
+}
+
+func (rwt *relationshipIntegrityRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
+	return rwt.delegate.DeleteRelationships(ctx, filter, options...)
+}
+
+func (rwt *relationshipIntegrityRWT) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return rwt.delegate.BulkLoad(ctx, &relationshipIntegritySource{iter, rwt.proxy})
+}
+
+func (rwt *relationshipIntegrityRWT) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	return rwt.delegate.RelationshipCounts(ctx, definitions...)
+}
+
+func (rwt *relationshipIntegrityRWT) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	return rwt.delegate.WriteNamespaces(ctx, newConfigs...)
+}
+
+func (rwt *relationshipIntegrityRWT) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	return rwt.delegate.DeleteNamespaces(ctx, nsNames...)
+}
+
+func (rwt *relationshipIntegrityRWT) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	return rwt.delegate.WriteCaveats(ctx, caveats)
+}
+
+func (rwt *relationshipIntegrityRWT) DeleteCaveats(ctx context.Context, names []string) error {
+	return rwt.delegate.DeleteCaveats(ctx, names)
+}
+
+type relationshipIntegritySource struct {
+	delegate datastore.BulkWriteRelationshipSource
+	proxy    *relationshipIntegrityProxy
+}
+
+func (s *relationshipIntegritySource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	tpl, err := s.delegate.Next(ctx)
+	if tpl == nil || err != nil {
+		return tpl, err
+	}
+	return s.proxy.withIntegrity(tpl)
+}
+
+var (
+	_ datastore.Datastore            = &relationshipIntegrityProxy{}
+	_ datastore.UnwrappableDatastore = &relationshipIntegrityProxy{}
+)
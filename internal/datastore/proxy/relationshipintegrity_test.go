@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	currentIntegrityKey = KeyConfig{ID: "current", Bytes: []byte("current-secret")}
+	expiredIntegrityKey = KeyConfig{ID: "expired", Bytes: []byte("expired-secret")}
+)
+
+func newIntegrityProxy(t *testing.T, delegate datastore.Datastore, currentKey KeyConfig, logMismatches bool, expiredKeys ...KeyConfig) datastore.Datastore {
+	ds, err := NewRelationshipIntegrityProxy(delegate, currentKey, expiredKeys, logMismatches)
+	require.NoError(t, err)
+	return ds
+}
+
+func readAllRelationships(ctx context.Context, ds datastore.Datastore, rev datastore.Revision) ([]*core.RelationTuple, error) {
+	iter, err := ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "document"})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var found []*core.RelationTuple
+	for rt := iter.Next(); rt != nil; rt = iter.Next() {
+		found = append(found, rt)
+	}
+	return found, iter.Err()
+}
+
+func TestRelationshipIntegrityRoundTrip(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	defer delegate.Close()
+
+	ds := newIntegrityProxy(t, delegate, currentIntegrityKey, false)
+
+	written := tuple.MustWithCaveat(tuple.MustParse("document:first#viewer@user:tom"), "somecaveat", map[string]any{"a": 1, "b": "two"})
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, written, tuple.MustParse("document:second#viewer@user:sarah"))
+	require.NoError(err)
+
+	// The written tuples are not modified by the proxy.
+	require.Nil(written.Integrity)
+
+	found, err := readAllRelationships(ctx, ds, rev)
+	require.NoError(err)
+	require.Len(found, 2)
+	for _, rt := range found {
+		require.Equal(currentIntegrityKey.ID, rt.Integrity.KeyId)
+	}
+
+	// Relationships hashed with a key which has since expired can still be read.
+	rotated := newIntegrityProxy(t, delegate, KeyConfig{ID: "next", Bytes: []byte("next-secret")}, false, currentIntegrityKey)
+	found, err = readAllRelationships(ctx, rotated, rev)
+	require.NoError(err)
+	require.Len(found, 2)
+
+	// Relationships hashed with a key which is no longer configured cannot be read.
+	_, err = readAllRelationships(ctx, newIntegrityProxy(t, delegate, expiredIntegrityKey, false), rev)
+	require.ErrorIs(err, ErrRelationshipIntegrity)
+}
+
+func TestRelationshipIntegrityMismatch(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	defer delegate.Close()
+
+	ds := newIntegrityProxy(t, delegate, currentIntegrityKey, false)
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tuple.MustParse("document:first#viewer@user:tom"))
+	require.NoError(err)
+
+	// Tamper with the relationship by moving its hash onto a different relationship.
+	rev, err := delegate.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		iter, err := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "document"})
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+
+		tampered := tuple.MustParse("document:first#viewer@user:sarah")
+		tampered.Integrity = iter.Next().Integrity
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Touch(tampered)})
+	})
+	require.NoError(err)
+
+	_, err = readAllRelationships(ctx, ds, rev)
+	require.ErrorIs(err, ErrRelationshipIntegrity)
+
+	// Relationships without any hash are rejected.
+	rev, err = common.WriteTuples(ctx, delegate, core.RelationTupleUpdate_TOUCH, tuple.MustParse("document:second#viewer@user:tom"))
+	require.NoError(err)
+
+	iter, err := ds.SnapshotReader(rev).ReverseQueryRelationships(ctx, datastore.SubjectsFilter{SubjectType: "user"})
+	require.NoError(err)
+	for rt := iter.Next(); rt != nil; rt = iter.Next() {
+		require.NotEqual("document:second#viewer@user:tom", tuple.MustString(rt))
+	}
+	require.ErrorIs(iter.Err(), ErrRelationshipIntegrity)
+	iter.Close()
+
+	// Mismatches are only logged when configured to do so.
+	found, err := readAllRelationships(ctx, newIntegrityProxy(t, delegate, currentIntegrityKey, true), rev)
+	require.NoError(err)
+	require.Len(found, 3)
+}
+
+func TestRelationshipIntegrityExpirationMismatch(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	defer delegate.Close()
+
+	ds := newIntegrityProxy(t, delegate, currentIntegrityKey, false)
+
+	written := tuple.MustParse("document:first#viewer@user:tom")
+	written.OptionalExpirationTime = timestamppb.New(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, written)
+	require.NoError(err)
+
+	found, err := readAllRelationships(ctx, ds, rev)
+	require.NoError(err)
+	require.Len(found, 1)
+
+	// Tamper with the relationship by extending its expiration while keeping its hash.
+	rev, err = delegate.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		tampered := found[0].CloneVT()
+		tampered.OptionalExpirationTime = timestamppb.New(time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC))
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Touch(tampered)})
+	})
+	require.NoError(err)
+
+	_, err = readAllRelationships(ctx, ds, rev)
+	require.ErrorIs(err, ErrRelationshipIntegrity)
+
+	// Removing the expiration is also detected.
+	rev, err = delegate.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		tampered := found[0].CloneVT()
+		tampered.OptionalExpirationTime = nil
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Touch(tampered)})
+	})
+	require.NoError(err)
+
+	_, err = readAllRelationships(ctx, ds, rev)
+	require.ErrorIs(err, ErrRelationshipIntegrity)
+}
+
+func TestRelationshipIntegrityKeyValidation(t *testing.T) {
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	defer delegate.Close()
+
+	for _, tc := range []struct {
+		name        string
+		currentKey  KeyConfig
+		expiredKeys []KeyConfig
+	}{
+		{"missing ID", KeyConfig{Bytes: []byte("secret")}, nil},
+		{"empty current key", KeyConfig{ID: "current"}, nil},
+		{"empty expired key", currentIntegrityKey, []KeyConfig{{ID: "expired"}}},
+		{"duplicate key", currentIntegrityKey, []KeyConfig{currentIntegrityKey}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRelationshipIntegrityProxy(delegate, tc.currentKey, tc.expiredKeys, false)
+			require.Error(t, err)
+		})
+	}
+}
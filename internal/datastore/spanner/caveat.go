@@ -7,6 +7,7 @@ import (
 
 	"cloud.google.com/go/spanner"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -125,6 +126,14 @@ func (rwt spannerReadWriteTXN) DeleteCaveats(_ context.Context, names []string)
 	return err
 }
 
+// expirationTimeFrom converts a nullable expiration column value into its proto representation.
+func expirationTimeFrom(expiration spanner.NullTime) *timestamppb.Timestamp {
+	if !expiration.Valid {
+		return nil
+	}
+	return timestamppb.New(expiration.Time)
+}
+
 func ContextualizedCaveatFrom(name spanner.NullString, context spanner.NullJSON) (*core.ContextualizedCaveat, error) {
 	if name.Valid {
 		var cctx map[string]any
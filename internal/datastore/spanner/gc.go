@@ -12,6 +12,25 @@ import (
 	log "github.com/authzed/spicedb/internal/logging"
 )
 
+// DeleteExpiredRels deletes all relationships which have expired, writing a changelog entry
+// for each so that they are emitted as deletions by Watch. Returns the number of
+// relationships deleted.
+func (sd spannerDatastore) DeleteExpiredRels(ctx context.Context) (int64, error) {
+	queries := selectAndDelete{queryTuples, sql.Delete(tableRelationship)}.Where(expiredClause(time.Now()))
+
+	var numDeleted int64
+	_, err := sd.client.ReadWriteTransaction(ctx, func(ctx context.Context, rwt *spanner.ReadWriteTransaction) error {
+		var err error
+		numDeleted, err = deleteSelected(ctx, rwt, queries, sd.config.disableStats, nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numDeleted, nil
+}
+
 // runGC runs the gc cron job, returning an error if it can't start properly.
 // it cleans up and stops when ctx is Done.
 func (sd spannerDatastore) runGC(ctx context.Context) error {
@@ -54,6 +73,13 @@ func (sd spannerDatastore) runGC(ctx context.Context) error {
 
 		log.Ctx(ctx).Info().Int64("removed", numRemoved).Stringer("before", oldestRevision).
 			Msg("garbage collection: removed changelog entries")
+
+		expired, err := sd.DeleteExpiredRels(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("garbage collection: error deleting expired relationships")
+		}
+
+		log.Ctx(ctx).Info().Int64("removed", expired).Msg("garbage collection: removed expired relationships")
 	})
 	if err != nil {
 		return fmt.Errorf("unable to start garbage collection: %w", err)
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+)
+
+const (
+	addRelationshipExpiration = `ALTER TABLE relation_tuple
+		ADD COLUMN expiration TIMESTAMP`
+
+	addChangelogExpiration = `ALTER TABLE changelog
+		ADD COLUMN expiration TIMESTAMP`
+
+	createExpirationIndex = `CREATE NULL_FILTERED INDEX ix_relation_tuple_expiration ON relation_tuple (expiration)`
+)
+
+func init() {
+	if err := SpannerMigrations.Register("add-expiration", "add-caveats", func(ctx context.Context, w Wrapper) error {
+		updateOp, err := w.adminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+			Database: w.client.DatabaseName(),
+			Statements: []string{
+				addRelationshipExpiration,
+				addChangelogExpiration,
+				createExpirationIndex,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return updateOp.Wait(ctx)
+	}, nil); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
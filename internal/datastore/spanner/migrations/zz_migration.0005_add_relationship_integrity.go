@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+)
+
+const addRelationshipIntegrity = `ALTER TABLE relation_tuple
+	ADD COLUMN integrity BYTES(MAX)`
+
+func init() {
+	if err := SpannerMigrations.Register("add-relationship-integrity", "add-expiration", func(ctx context.Context, w Wrapper) error {
+		updateOp, err := w.adminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+			Database:   w.client.DatabaseName(),
+			Statements: []string{addRelationshipIntegrity},
+		})
+		if err != nil {
+			return err
+		}
+		return updateOp.Wait(ctx)
+	}, nil); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
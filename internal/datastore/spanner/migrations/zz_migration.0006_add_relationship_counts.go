@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+)
+
+const (
+	createRelationshipCountedNamespaces = `CREATE TABLE relationship_counted_namespaces (
+		namespace STRING(MAX) NOT NULL
+	) PRIMARY KEY (namespace)`
+
+	createRelationshipCounts = `CREATE TABLE relationship_counts (
+		namespace STRING(MAX) NOT NULL,
+		relation STRING(MAX) NOT NULL,
+		shard INT64 NOT NULL,
+		relationship_count INT64 NOT NULL
+	) PRIMARY KEY (namespace, relation, shard)`
+)
+
+func init() {
+	if err := SpannerMigrations.Register("add-relationship-counts", "add-relationship-integrity", func(ctx context.Context, w Wrapper) error {
+		updateOp, err := w.adminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+			Database:   w.client.DatabaseName(),
+			Statements: []string{createRelationshipCountedNamespaces, createRelationshipCounts},
+		})
+		if err != nil {
+			return err
+		}
+		return updateOp.Wait(ctx)
+	}, nil); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
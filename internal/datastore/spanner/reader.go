@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/spanner"
+	sq "github.com/Masterminds/squirrel"
 	"google.golang.org/grpc/codes"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -37,7 +38,7 @@ func (sr spannerReader) QueryRelationships(
 	filter datastore.RelationshipsFilter,
 	opts ...options.QueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples).FilterWithRelationshipsFilter(filter)
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples.Where(notExpiredClause(time.Now()))).FilterWithRelationshipsFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +51,7 @@ func (sr spannerReader) ReverseQueryRelationships(
 	subjectsFilter datastore.SubjectsFilter,
 	opts ...options.ReverseQueryOptionsOption,
 ) (iter datastore.RelationshipIterator, err error) {
-	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples).
+	qBuilder, err := common.NewSchemaQueryFilterer(schema, queryTuples.Where(notExpiredClause(time.Now()))).
 		FilterWithSubjectsSelectors(subjectsFilter.AsSelector())
 	if err != nil {
 		return nil, err
@@ -67,6 +68,8 @@ func (sr spannerReader) ReverseQueryRelationships(
 	return sr.querySplitter.SplitAndExecuteQuery(ctx,
 		qBuilder,
 		options.WithLimit(queryOpts.ReverseLimit),
+		options.WithSort(queryOpts.SortForReverse),
+		options.WithAfter(queryOpts.AfterForReverse),
 	)
 }
 
@@ -90,6 +93,8 @@ func queryExecutor(txSource txFactory) common.ExecuteQueryFunc {
 			}
 			var caveatName spanner.NullString
 			var caveatCtx spanner.NullJSON
+			var expiration spanner.NullTime
+			var integrity []byte
 			err := row.Columns(
 				&nextTuple.ResourceAndRelation.Namespace,
 				&nextTuple.ResourceAndRelation.ObjectId,
@@ -99,6 +104,8 @@ func queryExecutor(txSource txFactory) common.ExecuteQueryFunc {
 				&nextTuple.Subject.Relation,
 				&caveatName,
 				&caveatCtx,
+				&expiration,
+				&integrity,
 			)
 			if err != nil {
 				return err
@@ -108,6 +115,11 @@ func queryExecutor(txSource txFactory) common.ExecuteQueryFunc {
 			if err != nil {
 				return err
 			}
+			nextTuple.OptionalExpirationTime = expirationTimeFrom(expiration)
+			nextTuple.Integrity, err = common.IntegrityFrom(integrity)
+			if err != nil {
+				return err
+			}
 
 			tuples = append(tuples, nextTuple)
 
@@ -226,8 +238,20 @@ var queryTuples = sql.Select(
 	colUsersetRelation,
 	colCaveatName,
 	colCaveatContext,
+	colExpiration,
+	colIntegrity,
 ).From(tableRelationship)
 
+// notExpiredClause matches relationships which have no expiration or which expire after now.
+func notExpiredClause(now time.Time) sq.Sqlizer {
+	return sq.Or{sq.Eq{colExpiration: nil}, sq.Gt{colExpiration: now}}
+}
+
+// expiredClause matches relationships which have expired as of now.
+func expiredClause(now time.Time) sq.Sqlizer {
+	return sq.LtOrEq{colExpiration: now}
+}
+
 var schema = common.NewSchemaInformation(
 	colNamespace,
 	colObjectID,
@@ -3,6 +3,7 @@ package spanner
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	sq "github.com/Masterminds/squirrel"
@@ -11,8 +12,10 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
@@ -27,18 +30,56 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 
 	var rowCountChange int64
 
+	// Relationships being created may replace ones which have expired but which have not yet
+	// been removed by garbage collection.
+	expiredCreateClauses := sq.Or{}
+	now := time.Now()
+
+	// Relationships which already exist are replaced by touches and creates, and removed by
+	// deletes, so are subtracted from the counts before each write is added.
+	deltas := common.RelationshipCountDeltas{}
+	keys := spanner.KeySets()
+	for _, mutation := range mutations {
+		keys = spanner.KeySets(keys, keyFromRelationship(mutation.Tuple))
+		if mutation.Operation != core.RelationTupleUpdate_DELETE {
+			deltas.Add(mutation.Tuple.ResourceAndRelation.Namespace, mutation.Tuple.ResourceAndRelation.Relation, 1)
+		}
+	}
+	if err := rwt.spannerRWT.Read(ctx, tableRelationship, keys, []string{colNamespace, colRelation}).Do(func(row *spanner.Row) error {
+		var namespace, relation string
+		if err := row.Columns(&namespace, &relation); err != nil {
+			return err
+		}
+		deltas.Add(namespace, relation, -1)
+		return nil
+	}); err != nil {
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
 	for _, mutation := range mutations {
 		var txnMut *spanner.Mutation
 		var op int
 		switch mutation.Operation {
 		case core.RelationTupleUpdate_TOUCH:
 			rowCountChange++
-			txnMut = spanner.InsertOrUpdate(tableRelationship, allRelationshipCols, upsertVals(mutation.Tuple))
+			vals, err := upsertVals(mutation.Tuple)
+			if err != nil {
+				return fmt.Errorf(errUnableToWriteRelationships, err)
+			}
+			txnMut = spanner.InsertOrUpdate(tableRelationship, allRelationshipCols, vals)
 			op = colChangeOpTouch
 		case core.RelationTupleUpdate_CREATE:
 			rowCountChange++
-			txnMut = spanner.Insert(tableRelationship, allRelationshipCols, upsertVals(mutation.Tuple))
+			vals, err := upsertVals(mutation.Tuple)
+			if err != nil {
+				return fmt.Errorf(errUnableToWriteRelationships, err)
+			}
+			txnMut = spanner.Insert(tableRelationship, allRelationshipCols, vals)
 			op = colChangeOpCreate
+			expiredCreateClauses = append(expiredCreateClauses, sq.And{
+				exactRelationshipClause(mutation.Tuple),
+				expiredClause(now),
+			})
 		case core.RelationTupleUpdate_DELETE:
 			rowCountChange--
 			txnMut = spanner.Delete(tableRelationship, keyFromRelationship(mutation.Tuple))
@@ -57,6 +98,25 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 		}
 	}
 
+	// DML is applied immediately, whereas the buffered inserts are applied at commit, so the
+	// expired relationships are removed before they are replaced.
+	if len(expiredCreateClauses) > 0 {
+		sql, args, err := sql.Delete(tableRelationship).Where(expiredCreateClauses).ToSql()
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+
+		numDeleted, err := rwt.spannerRWT.Update(ctx, statementFromSQL(sql, args))
+		if err != nil {
+			return fmt.Errorf(errUnableToWriteRelationships, err)
+		}
+		rowCountChange -= numDeleted
+	}
+
+	if err := updateRelationshipCounts(ctx, rwt.spannerRWT, deltas); err != nil {
+		return fmt.Errorf(errUnableToWriteRelationships, err)
+	}
+
 	if !rwt.disableStats {
 		if err := updateCounter(ctx, rwt.spannerRWT, rowCountChange); err != nil {
 			return fmt.Errorf(errUnableToWriteRelationships, err)
@@ -66,12 +126,13 @@ func (rwt spannerReadWriteTXN) WriteRelationships(ctx context.Context, mutations
 	return nil
 }
 
-func (rwt spannerReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
-	err := deleteWithFilter(ctx, rwt.spannerRWT, filter, rwt.disableStats)
+func (rwt spannerReadWriteTXN) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
+	delOpts := options.NewDeleteOptionsWithOptions(opts...)
+	limitReached, err := deleteWithFilter(ctx, rwt.spannerRWT, filter, rwt.disableStats, delOpts.DeleteLimit)
 	if err != nil {
-		return fmt.Errorf(errUnableToDeleteRelationships, err)
+		return false, fmt.Errorf(errUnableToDeleteRelationships, err)
 	}
-	return nil
+	return limitReached, nil
 }
 
 type selectAndDelete struct {
@@ -85,7 +146,7 @@ func (snd selectAndDelete) Where(pred interface{}, args ...interface{}) selectAn
 	return snd
 }
 
-func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, filter *v1.RelationshipFilter, disableStats bool) error {
+func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, filter *v1.RelationshipFilter, disableStats bool, limit *uint64) (bool, error) {
 	queries := selectAndDelete{queryTuples, sql.Delete(tableRelationship)}
 
 	// Add clauses for the ResourceFilter
@@ -108,9 +169,23 @@ func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, fi
 		}
 	}
 
+	numDeleted, err := deleteSelected(ctx, rwt, queries, disableStats, limit)
+	if err != nil {
+		return false, err
+	}
+	return limit != nil && uint64(numDeleted) == *limit, nil
+}
+
+// deleteSelected deletes the relationships matched by the given queries, up to the limit if one
+// is given, writing a changelog entry for each, and returns the number of relationships deleted.
+func deleteSelected(ctx context.Context, rwt *spanner.ReadWriteTransaction, queries selectAndDelete, disableStats bool, limit *uint64) (int64, error) {
+	if limit != nil {
+		queries.sel = queries.sel.Limit(*limit)
+	}
+
 	ssql, sargs, err := queries.sel.ToSql()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	toDelete := rwt.Query(ctx, statementFromSQL(ssql, sargs))
@@ -124,8 +199,12 @@ func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, fi
 	}
 	var caveatName spanner.NullString
 	var caveatCtx spanner.NullJSON
+	var expiration spanner.NullTime
+	var integrity []byte
 
 	var changelogMutations []*spanner.Mutation
+	var selectedKeys []spanner.KeySet
+	deltas := common.RelationshipCountDeltas{}
 	if err := toDelete.Do(func(row *spanner.Row) error {
 		err := row.Columns(
 			&rel.ResourceAndRelation.Namespace,
@@ -136,6 +215,8 @@ func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, fi
 			&rel.Subject.Relation,
 			&caveatName,
 			&caveatCtx,
+			&expiration,
+			&integrity,
 		)
 		if err != nil {
 			return err
@@ -144,45 +225,283 @@ func deleteWithFilter(ctx context.Context, rwt *spanner.ReadWriteTransaction, fi
 		if err != nil {
 			return err
 		}
+		rel.OptionalExpirationTime = expirationTimeFrom(expiration)
 
 		changelogMutations = append(changelogMutations, spanner.Insert(
 			tableChangelog,
 			allChangelogCols,
 			changeVals(changeUUID, colChangeOpDelete, &rel),
 		))
+		deltas.Add(rel.ResourceAndRelation.Namespace, rel.ResourceAndRelation.Relation, -1)
+		if limit != nil {
+			selectedKeys = append(selectedKeys, keyFromRelationship(&rel))
+		}
 		return nil
 	}); err != nil {
-		return err
+		return 0, err
 	}
 
 	if err := rwt.BufferWrite(changelogMutations); err != nil {
-		return err
+		return 0, err
+	}
+
+	// Spanner does not support a limit on deletes, so a limited delete removes exactly the
+	// relationships which were selected.
+	var numDeleted int64
+	if limit != nil {
+		if len(selectedKeys) > 0 {
+			if err := rwt.BufferWrite([]*spanner.Mutation{
+				spanner.Delete(tableRelationship, spanner.KeySets(selectedKeys...)),
+			}); err != nil {
+				return 0, err
+			}
+		}
+		numDeleted = int64(len(selectedKeys))
+	} else {
+		sql, args, err := queries.del.ToSql()
+		if err != nil {
+			return 0, err
+		}
+
+		numDeleted, err = rwt.Update(ctx, statementFromSQL(sql, args))
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	sql, args, err := queries.del.ToSql()
+	if err := updateRelationshipCounts(ctx, rwt, deltas); err != nil {
+		return 0, err
+	}
+
+	if !disableStats {
+		if err := updateCounter(ctx, rwt, -1*numDeleted); err != nil {
+			return 0, err
+		}
+	}
+
+	return numDeleted, nil
+}
+
+func (rwt spannerReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	changeUUID := uuid.New().String()
+
+	var numLoaded uint64
+	deltas := common.RelationshipCountDeltas{}
+	mutations := make([]*spanner.Mutation, 0, bulkLoadBatchSize*2)
+	flush := func() error {
+		if len(mutations) == 0 {
+			return nil
+		}
+
+		if err := rwt.spannerRWT.BufferWrite(mutations); err != nil {
+			return fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		mutations = make([]*spanner.Mutation, 0, bulkLoadBatchSize*2)
+		return nil
+	}
+
+	var tpl *core.RelationTuple
+	var err error
+	for tpl, err = iter.Next(ctx); tpl != nil && err == nil; tpl, err = iter.Next(ctx) {
+		vals, err := upsertVals(tpl)
+		if err != nil {
+			return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
+
+		mutations = append(mutations,
+			spanner.Insert(tableRelationship, allRelationshipCols, vals),
+			spanner.Insert(tableChangelog, allChangelogCols, changeVals(changeUUID, colChangeOpCreate, tpl)),
+		)
+		numLoaded++
+		deltas.Add(tpl.ResourceAndRelation.Namespace, tpl.ResourceAndRelation.Relation, 1)
+
+		if len(mutations) >= bulkLoadBatchSize*2 {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	if err := updateRelationshipCounts(ctx, rwt.spannerRWT, deltas); err != nil {
+		return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+	}
+
+	if !rwt.disableStats {
+		if err := updateCounter(ctx, rwt.spannerRWT, int64(numLoaded)); err != nil {
+			return 0, fmt.Errorf(errUnableToBulkLoadRelationships, err)
+		}
 	}
 
-	numDeleted, err := rwt.Update(ctx, statementFromSQL(sql, args))
+	return numLoaded, nil
+}
+
+// updateRelationshipCounts applies the deltas to the maintained relationship counts, for the
+// namespaces whose counts are maintained. The deltas are added to a randomly selected shard of
+// each count. The counts are updated with DML rather than buffered mutations, so that they can
+// be read back later in the same transaction.
+//
+// Buffered relationship mutations are not visible to reads in the same transaction, so
+// relationships written by an earlier call in the transaction are not seen to already exist by
+// later calls.
+func updateRelationshipCounts(ctx context.Context, rwt *spanner.ReadWriteTransaction, deltas common.RelationshipCountDeltas) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	counted, err := countedNamespaces(ctx, rwt, deltas.Namespaces())
 	if err != nil {
 		return err
 	}
 
-	if !disableStats {
-		if err := updateCounter(ctx, rwt, -1*numDeleted); err != nil {
+	shard := int64(common.RandomRelationshipCountShard())
+	for key, delta := range deltas {
+		if _, ok := counted[key.Definition]; !ok {
+			continue
+		}
+
+		params := map[string]any{
+			"namespace": key.Definition,
+			"relation":  key.Relation,
+			"shard":     shard,
+			"delta":     delta,
+		}
+
+		updated, err := rwt.Update(ctx, spanner.Statement{
+			SQL: fmt.Sprintf(
+				"UPDATE %[1]s SET %[2]s = %[2]s + @delta WHERE %[3]s = @namespace AND %[4]s = @relation AND %[5]s = @shard",
+				tableRelationshipCounts, colRelationshipCount, colNamespace, colRelation, colShard,
+			),
+			Params: params,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to update relationship count: %w", err)
+		}
+		if updated > 0 {
+			continue
+		}
+
+		if _, err := rwt.Update(ctx, spanner.Statement{
+			SQL: fmt.Sprintf(
+				"INSERT INTO %s (%s, %s, %s, %s) VALUES (@namespace, @relation, @shard, @delta)",
+				tableRelationshipCounts, colNamespace, colRelation, colShard, colRelationshipCount,
+			),
+			Params: params,
+		}); err != nil {
+			return fmt.Errorf("unable to insert relationship count: %w", err)
+		}
+	}
+	return nil
+}
+
+// countedNamespaces returns the set of the given namespaces whose relationship counts are
+// maintained.
+func countedNamespaces(ctx context.Context, rwt *spanner.ReadWriteTransaction, namespaces []string) (map[string]struct{}, error) {
+	counted := make(map[string]struct{}, len(namespaces))
+	if err := rwt.Query(ctx, spanner.Statement{
+		SQL: fmt.Sprintf(
+			"SELECT %[1]s FROM %[2]s WHERE %[1]s IN UNNEST(@namespaces)",
+			colNamespace, tableRelationshipCountedNamespaces,
+		),
+		Params: map[string]any{"namespaces": namespaces},
+	}).Do(func(row *spanner.Row) error {
+		var namespace string
+		if err := row.Columns(&namespace); err != nil {
 			return err
 		}
+		counted[namespace] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
+	return counted, nil
+}
 
-	return nil
+// startCounting starts maintaining the relationship counts of the given namespaces which are
+// not yet counted, by counting their existing relationships.
+func startCounting(ctx context.Context, rwt *spanner.ReadWriteTransaction, namespaces []string) error {
+	counted, err := countedNamespaces(ctx, rwt, namespaces)
+	if err != nil {
+		return err
+	}
+
+	uncounted := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if _, ok := counted[namespace]; !ok {
+			counted[namespace] = struct{}{}
+			uncounted = append(uncounted, namespace)
+		}
+	}
+	if len(uncounted) == 0 {
+		return nil
+	}
+
+	params := map[string]any{"namespaces": uncounted}
+	if _, err := rwt.Update(ctx, spanner.Statement{
+		SQL: fmt.Sprintf(
+			"INSERT INTO %s (%s) SELECT * FROM UNNEST(@namespaces)",
+			tableRelationshipCountedNamespaces, colNamespace,
+		),
+		Params: params,
+	}); err != nil {
+		return err
+	}
+
+	// The existing relationships of a namespace are counted into the first shard of each count.
+	_, err = rwt.Update(ctx, spanner.Statement{
+		SQL: fmt.Sprintf(
+			"INSERT INTO %[1]s (%[2]s, %[3]s, %[4]s, %[5]s) SELECT %[2]s, %[3]s, 0, COUNT(*) FROM %[6]s WHERE %[2]s IN UNNEST(@namespaces) GROUP BY %[2]s, %[3]s",
+			tableRelationshipCounts, colNamespace, colRelation, colShard, colRelationshipCount, tableRelationship,
+		),
+		Params: params,
+	})
+	return err
+}
+
+func (rwt spannerReadWriteTXN) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	if err := startCounting(ctx, rwt.spannerRWT, definitions); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+
+	counts := make(map[datastore.RelationKey]uint64)
+	if err := rwt.spannerRWT.Query(ctx, spanner.Statement{
+		SQL: fmt.Sprintf(
+			"SELECT %[1]s, %[2]s, SUM(%[3]s) FROM %[4]s WHERE %[1]s IN UNNEST(@definitions) GROUP BY %[1]s, %[2]s HAVING SUM(%[3]s) > 0",
+			colNamespace, colRelation, colRelationshipCount, tableRelationshipCounts,
+		),
+		Params: map[string]any{"definitions": definitions},
+	}).Do(func(row *spanner.Row) error {
+		var key datastore.RelationKey
+		var count int64
+		if err := row.Columns(&key.Definition, &key.Relation, &count); err != nil {
+			return err
+		}
+		counts[key] = uint64(count)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf(errUnableToReadCounts, err)
+	}
+	return counts, nil
 }
 
-func upsertVals(r *core.RelationTuple) []any {
+func upsertVals(r *core.RelationTuple) ([]any, error) {
+	integrity, err := common.IntegrityOf(r)
+	if err != nil {
+		return nil, err
+	}
+
 	key := keyFromRelationship(r)
 	key = append(key, spanner.CommitTimestamp)
 	key = append(key, caveatVals(r)...)
-	return key
+	key = append(key, expirationVal(r), integrity)
+	return key, nil
 }
 
 func keyFromRelationship(r *core.RelationTuple) spanner.Key {
@@ -209,6 +528,7 @@ func changeVals(changeUUID string, op int, r *core.RelationTuple) []any {
 		r.Subject.Relation,
 	}
 	vals = append(vals, caveatVals(r)...)
+	vals = append(vals, expirationVal(r))
 	return vals
 }
 
@@ -225,6 +545,24 @@ func caveatVals(r *core.RelationTuple) []any {
 	return vals
 }
 
+func expirationVal(r *core.RelationTuple) spanner.NullTime {
+	if r.OptionalExpirationTime == nil {
+		return spanner.NullTime{}
+	}
+	return spanner.NullTime{Time: r.OptionalExpirationTime.AsTime(), Valid: true}
+}
+
+func exactRelationshipClause(r *core.RelationTuple) sq.Eq {
+	return sq.Eq{
+		colNamespace:        r.ResourceAndRelation.Namespace,
+		colObjectID:         r.ResourceAndRelation.ObjectId,
+		colRelation:         r.ResourceAndRelation.Relation,
+		colUsersetNamespace: r.Subject.Namespace,
+		colUsersetObjectID:  r.Subject.ObjectId,
+		colUsersetRelation:  r.Subject.Relation,
+	}
+}
+
 func (rwt spannerReadWriteTXN) WriteNamespaces(_ context.Context, newConfigs ...*core.NamespaceDefinition) error {
 	mutations := make([]*spanner.Mutation, 0, len(newConfigs))
 	for _, newConfig := range newConfigs {
@@ -245,9 +583,9 @@ func (rwt spannerReadWriteTXN) WriteNamespaces(_ context.Context, newConfigs ...
 
 func (rwt spannerReadWriteTXN) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
 	for _, nsName := range nsNames {
-		if err := deleteWithFilter(ctx, rwt.spannerRWT, &v1.RelationshipFilter{
+		if _, err := deleteWithFilter(ctx, rwt.spannerRWT, &v1.RelationshipFilter{
 			ResourceType: nsName,
-		}, rwt.disableStats); err != nil {
+		}, rwt.disableStats, nil); err != nil {
 			return fmt.Errorf(errUnableToDeleteConfig, err)
 		}
 
@@ -20,6 +20,8 @@ const (
 	colTimestamp        = "timestamp"
 	colCaveatName       = "caveat_name"
 	colCaveatContext    = "caveat_context"
+	colExpiration       = "expiration"
+	colIntegrity        = "integrity"
 
 	tableChangelog            = "changelog"
 	colChangeUUID             = "uuid"
@@ -33,6 +35,7 @@ const (
 	colChangeUsersetRelation  = "userset_relation"
 	colChangeCaveatName       = "caveat_name"
 	colChangeCaveatContext    = "caveat_context"
+	colChangeExpiration       = "expiration"
 
 	tableCaveat         = "caveat"
 	colName             = "name"
@@ -46,6 +49,11 @@ const (
 	colID         = "id"
 	colCount      = "count"
 
+	tableRelationshipCounts            = "relationship_counts"
+	tableRelationshipCountedNamespaces = "relationship_counted_namespaces"
+	colRelationshipCount               = "relationship_count"
+	colShard                           = "shard"
+
 	colChangeOpCreate = 1
 	colChangeOpTouch  = 2
 	colChangeOpDelete = 3
@@ -61,6 +69,8 @@ var allRelationshipCols = []string{
 	colTimestamp,
 	colCaveatName,
 	colCaveatContext,
+	colExpiration,
+	colIntegrity,
 }
 
 var allChangelogCols = []string{
@@ -75,6 +85,7 @@ var allChangelogCols = []string{
 	colChangeUsersetRelation,
 	colChangeCaveatName,
 	colChangeCaveatContext,
+	colChangeExpiration,
 }
 
 // Both creates and touches are emitted as touched to match other datastores.
@@ -34,8 +34,10 @@ const (
 
 	errRevision = "unable to load revision: %w"
 
-	errUnableToWriteRelationships  = "unable to write relationships: %w"
-	errUnableToDeleteRelationships = "unable to delete relationships: %w"
+	errUnableToWriteRelationships    = "unable to write relationships: %w"
+	errUnableToDeleteRelationships   = "unable to delete relationships: %w"
+	errUnableToBulkLoadRelationships = "unable to bulk load relationships: %w"
+	errUnableToReadCounts            = "unable to read relationship counts: %w"
 
 	errUnableToWriteConfig    = "unable to write namespace config: %w"
 	errUnableToReadConfig     = "unable to read namespace config: %w"
@@ -52,6 +54,11 @@ const (
 	// https://cloud.google.com/spanner/quotas
 	// We can't share a default or config option with other datastore implementations.
 	usersetBatchsize = 100
+
+	// bulkLoadBatchSize is the number of relationships buffered into the transaction at once
+	// when bulk loading. Each relationship requires two mutations: one for the relationship
+	// and one for the changelog.
+	bulkLoadBatchSize = 500
 )
 
 var (
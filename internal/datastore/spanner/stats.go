@@ -95,3 +95,71 @@ func updateCounter(ctx context.Context, rwt *spanner.ReadWriteTransaction, chang
 
 	return nil
 }
+
+// detailedStatsExactCountLimit is the estimated number of relationships, according to the
+// relationship estimate counters, up to which relationships are counted for each relation.
+// Spanner keeps no statistics from which the relationships of larger tables can be estimated
+// for each relation, so they are not reported.
+const detailedStatsExactCountLimit = 1_000_000
+
+var queryTupleStorageSize = fmt.Sprintf(
+	"SELECT USED_BYTES FROM SPANNER_SYS.TABLE_SIZES_STATS_1HOUR WHERE TABLE_NAME = '%s' ORDER BY INTERVAL_END DESC LIMIT 1",
+	tableRelationship,
+)
+
+// DetailedStatistics reads the storage size from the hourly table size statistics kept by
+// Spanner, and counts the relationships of each relation exactly, in a read-only transaction
+// which takes no locks, if the relationship estimate counters hold at most
+// detailedStatsExactCountLimit relationships. Counting the changelog would require scanning
+// it, so no backlog is reported.
+func (sd spannerDatastore) DetailedStatistics(ctx context.Context) (datastore.DetailedStats, error) {
+	var stats datastore.DetailedStats
+
+	// Table size statistics are not available from the emulator, nor until they have first
+	// been collected, so the storage size is left unreported if they cannot be read.
+	var usedBytes spanner.NullInt64
+	if err := sd.client.Single().Query(ctx, spanner.Statement{SQL: queryTupleStorageSize}).Do(func(r *spanner.Row) error {
+		return r.Columns(&usedBytes)
+	}); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("unable to read relationship storage size")
+	} else if usedBytes.Valid {
+		stats.StorageSizeBytes = uint64(usedBytes.Int64)
+	}
+
+	var estimate spanner.NullInt64
+	if err := sd.client.Single().Query(ctx, spanner.Statement{SQL: queryRelationshipEstimate}).Do(func(r *spanner.Row) error {
+		return r.Columns(&estimate)
+	}); err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to read row counts: %w", err)
+	}
+
+	if estimate.Int64 > detailedStatsExactCountLimit {
+		stats.CountsAreEstimates = true
+		return stats, nil
+	}
+
+	query, args, err := sql.Select(colNamespace, colRelation, "COUNT(*)").
+		From(tableRelationship).
+		Where(notExpiredClause(time.Now())).
+		GroupBy(colNamespace, colRelation).
+		ToSql()
+	if err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to prepare relation count sql: %w", err)
+	}
+
+	counts := make(map[datastore.RelationKey]uint64)
+	if err := sd.client.Single().Query(ctx, statementFromSQL(query, args)).Do(func(r *spanner.Row) error {
+		var key datastore.RelationKey
+		var count int64
+		if err := r.Columns(&key.Definition, &key.Relation, &count); err != nil {
+			return err
+		}
+		counts[key] = uint64(count)
+		return nil
+	}); err != nil {
+		return datastore.DetailedStats{}, fmt.Errorf("unable to count relationships: %w", err)
+	}
+	stats.Definitions = datastore.ComputeDefinitionStats(counts)
+
+	return stats, nil
+}
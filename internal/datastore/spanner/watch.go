@@ -3,6 +3,7 @@ package spanner
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"cloud.google.com/go/spanner"
@@ -18,9 +19,19 @@ const (
 	watchSleep = 100 * time.Millisecond
 )
 
-var queryChanged = sql.Select(allChangelogCols...).From(tableChangelog)
+var (
+	queryChanged           = sql.Select(allChangelogCols...).From(tableChangelog)
+	queryChangedNamespaces = sql.Select(colNamespaceConfig, colNamespaceTS).From(tableNamespace)
+	queryChangedCaveats    = sql.Select(colCaveatDefinition, colCaveatTS).From(tableCaveat)
+)
 
-func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision) (<-chan *datastore.RevisionChanges, <-chan error) {
+// Watch notifies the caller about all changes to tuples and, if requested in the options, to
+// the schema.
+//
+// Namespaces and caveats are overwritten and deleted outright in Spanner and have no changelog,
+// so only the latest write of each definition is reported, and deleted namespaces and caveats
+// are never reported.
+func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
 	afterRevision := afterRevisionRaw.(revision.Decimal)
 
 	updates := make(chan *datastore.RevisionChanges, sd.config.watchBufferLength)
@@ -32,10 +43,12 @@ func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore
 
 		currentTxn := timestampFromRevision(afterRevision)
 
+		lastCheckpoint := time.Now()
+
 		for {
 			var stagedUpdates []datastore.RevisionChanges
 			var err error
-			stagedUpdates, currentTxn, err = sd.loadChanges(ctx, currentTxn)
+			stagedUpdates, currentTxn, err = sd.loadChanges(ctx, currentTxn, options)
 			if err != nil {
 				if errors.Is(ctx.Err(), context.Canceled) {
 					errs <- datastore.NewWatchCanceledErr()
@@ -57,6 +70,16 @@ func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore
 				}
 			}
 
+			if options.CheckpointInterval > 0 && time.Since(lastCheckpoint) >= options.CheckpointInterval {
+				select {
+				case updates <- &datastore.RevisionChanges{Revision: revisionFromTimestamp(currentTxn), IsCheckpoint: true}:
+					lastCheckpoint = time.Now()
+				default:
+					errs <- datastore.NewWatchDisconnectedErr()
+					return
+				}
+			}
+
 			// If there were no changes, sleep a bit
 			if len(stagedUpdates) == 0 {
 				sleep := time.NewTimer(watchSleep)
@@ -78,14 +101,49 @@ func (sd spannerDatastore) Watch(ctx context.Context, afterRevisionRaw datastore
 func (sd spannerDatastore) loadChanges(
 	ctx context.Context,
 	afterTimestamp time.Time,
+	options datastore.WatchOptions,
 ) ([]datastore.RevisionChanges, time.Time, error) {
+	// Read all changes from a single snapshot, so that no change committed between the
+	// queries is skipped.
+	txn := sd.client.ReadOnlyTransaction()
+	defer txn.Close()
+
+	stagedChanges := common.NewChanges(revision.DecimalKeyFunc)
+	newTimestamp := afterTimestamp
+
+	if options.Includes(datastore.WatchRelationships) {
+		latest, err := sd.loadRelationshipChanges(ctx, txn, afterTimestamp, stagedChanges)
+		if err != nil {
+			return nil, afterTimestamp, err
+		}
+		newTimestamp = maxTime(newTimestamp, latest)
+	}
+
+	if options.Includes(datastore.WatchSchema) {
+		latest, err := sd.loadSchemaChanges(ctx, txn, afterTimestamp, stagedChanges)
+		if err != nil {
+			return nil, afterTimestamp, err
+		}
+		newTimestamp = maxTime(newTimestamp, latest)
+	}
+
+	changes := stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
+
+	return changes, newTimestamp, nil
+}
+
+func (sd spannerDatastore) loadRelationshipChanges(
+	ctx context.Context,
+	txn *spanner.ReadOnlyTransaction,
+	afterTimestamp time.Time,
+	stagedChanges common.Changes[revision.Decimal, int64],
+) (time.Time, error) {
 	sql, args, err := queryChanged.Where(sq.Gt{colChangeTS: afterTimestamp}).ToSql()
 	if err != nil {
-		return nil, afterTimestamp, err
+		return afterTimestamp, err
 	}
 
-	rows := sd.client.Single().Query(ctx, statementFromSQL(sql, args))
-	stagedChanges := common.NewChanges(revision.DecimalKeyFunc)
+	rows := txn.Query(ctx, statementFromSQL(sql, args))
 
 	newTimestamp := afterTimestamp
 	err = rows.Do(func(r *spanner.Row) error {
@@ -99,6 +157,7 @@ func (sd spannerDatastore) loadChanges(
 		var colChangeUUID string
 		var caveatName spanner.NullString
 		var caveatCtx spanner.NullJSON
+		var expiration spanner.NullTime
 		err := r.Columns(
 			&timestamp,
 			&colChangeUUID,
@@ -111,6 +170,7 @@ func (sd spannerDatastore) loadChanges(
 			&tpl.Subject.Relation,
 			&caveatName,
 			&caveatCtx,
+			&expiration,
 		)
 		if err != nil {
 			return err
@@ -119,6 +179,7 @@ func (sd spannerDatastore) loadChanges(
 		if err != nil {
 			return err
 		}
+		tpl.OptionalExpirationTime = expirationTimeFrom(expiration)
 
 		newTimestamp = maxTime(newTimestamp, timestamp)
 
@@ -127,12 +188,71 @@ func (sd spannerDatastore) loadChanges(
 		return nil
 	})
 	if err != nil {
-		return nil, afterTimestamp, err
+		return afterTimestamp, err
 	}
 
-	changes := stagedChanges.AsRevisionChanges(revision.DecimalKeyLessThanFunc)
+	return newTimestamp, nil
+}
 
-	return changes, newTimestamp, nil
+func (sd spannerDatastore) loadSchemaChanges(
+	ctx context.Context,
+	txn *spanner.ReadOnlyTransaction,
+	afterTimestamp time.Time,
+	stagedChanges common.Changes[revision.Decimal, int64],
+) (time.Time, error) {
+	newTimestamp := afterTimestamp
+
+	sql, args, err := queryChangedNamespaces.Where(sq.Gt{colNamespaceTS: afterTimestamp}).ToSql()
+	if err != nil {
+		return afterTimestamp, err
+	}
+
+	err = txn.Query(ctx, statementFromSQL(sql, args)).Do(func(r *spanner.Row) error {
+		var serialized []byte
+		var timestamp time.Time
+		if err := r.Columns(&serialized, &timestamp); err != nil {
+			return err
+		}
+
+		loaded := &core.NamespaceDefinition{}
+		if err := loaded.UnmarshalVT(serialized); err != nil {
+			return fmt.Errorf(errUnableToReadConfig, err)
+		}
+
+		newTimestamp = maxTime(newTimestamp, timestamp)
+		stagedChanges.AddChangedDefinition(ctx, revisionFromTimestamp(timestamp), loaded)
+		return nil
+	})
+	if err != nil {
+		return afterTimestamp, err
+	}
+
+	sql, args, err = queryChangedCaveats.Where(sq.Gt{colCaveatTS: afterTimestamp}).ToSql()
+	if err != nil {
+		return afterTimestamp, err
+	}
+
+	err = txn.Query(ctx, statementFromSQL(sql, args)).Do(func(r *spanner.Row) error {
+		var serialized []byte
+		var timestamp time.Time
+		if err := r.Columns(&serialized, &timestamp); err != nil {
+			return err
+		}
+
+		loaded := &core.CaveatDefinition{}
+		if err := loaded.UnmarshalVT(serialized); err != nil {
+			return fmt.Errorf(errUnableToReadCaveat, err)
+		}
+
+		newTimestamp = maxTime(newTimestamp, timestamp)
+		stagedChanges.AddChangedDefinition(ctx, revisionFromTimestamp(timestamp), loaded)
+		return nil
+	})
+	if err != nil {
+		return afterTimestamp, err
+	}
+
+	return newTimestamp, nil
 }
 
 func maxTime(t1 time.Time, t2 time.Time) time.Time {
@@ -27,7 +27,7 @@ type ReadyState struct {
 type Dispatcher interface {
 	Check
 	Expand
-	Lookup
+	LookupResources
 	ReachableResources
 	LookupSubjects
 
@@ -50,10 +50,16 @@ type Expand interface {
 	DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error)
 }
 
-// Lookup interface describes just the methods required to dispatch lookup requests.
-type Lookup interface {
-	// DispatchLookup submits a single lookup request and returns its result.
-	DispatchLookup(ctx context.Context, req *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error)
+// LookupResourcesStream is an alias for the stream to which found resources will be written.
+type LookupResourcesStream = Stream[*v1.DispatchLookupResourcesResponse]
+
+// LookupResources interface describes just the methods required to dispatch lookup resources requests.
+type LookupResources interface {
+	// DispatchLookupResources submits a single lookup resources request, writing its results to the specified stream.
+	DispatchLookupResources(
+		req *v1.DispatchLookupResourcesRequest,
+		stream LookupResourcesStream,
+	) error
 }
 
 // ReachableResourcesStream is an alias for the stream to which reachable resources will be written.
@@ -86,7 +86,7 @@ func NewLocalOnlyDispatcherWithLimits(concurrencyLimits ConcurrencyLimits) dispa
 
 	d.checker = graph.NewConcurrentChecker(d, concurrencyLimits.Check)
 	d.expander = graph.NewConcurrentExpander(d)
-	d.lookupHandler = graph.NewConcurrentLookup(d, d, concurrencyLimits.LookupResources)
+	d.lookupHandler = graph.NewCursoredLookupResources(d, d, concurrencyLimits.LookupResources)
 	d.reachableResourcesHandler = graph.NewConcurrentReachableResources(d, concurrencyLimits.ReachableResources)
 	d.lookupSubjectsHandler = graph.NewConcurrentLookupSubjects(d, concurrencyLimits.LookupSubjects)
 
@@ -100,7 +100,7 @@ func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits Concurren
 
 	checker := graph.NewConcurrentChecker(redispatcher, concurrencyLimits.Check)
 	expander := graph.NewConcurrentExpander(redispatcher)
-	lookupHandler := graph.NewConcurrentLookup(redispatcher, redispatcher, concurrencyLimits.LookupResources)
+	lookupHandler := graph.NewCursoredLookupResources(redispatcher, redispatcher, concurrencyLimits.LookupResources)
 	reachableResourcesHandler := graph.NewConcurrentReachableResources(redispatcher, concurrencyLimits.ReachableResources)
 	lookupSubjectsHandler := graph.NewConcurrentLookupSubjects(redispatcher, concurrencyLimits.LookupSubjects)
 
@@ -116,7 +116,7 @@ func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits Concurren
 type localDispatcher struct {
 	checker                   *graph.ConcurrentChecker
 	expander                  *graph.ConcurrentExpander
-	lookupHandler             *graph.ConcurrentLookup
+	lookupHandler             *graph.CursoredLookupResources
 	reachableResourcesHandler *graph.ConcurrentReachableResources
 	lookupSubjectsHandler     *graph.ConcurrentLookupSubjects
 }
@@ -266,34 +266,34 @@ func (ld *localDispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchE
 	}, relation)
 }
 
-// DispatchLookup implements dispatch.Lookup interface
-func (ld *localDispatcher) DispatchLookup(ctx context.Context, req *v1.DispatchLookupRequest) (*v1.DispatchLookupResponse, error) {
-	// TODO(jschorr): Since lookup is now calling reachable resources exclusively, we should
-	// probably move it out of the dispatcher and into computed
-	ctx, span := tracer.Start(ctx, "DispatchLookup", trace.WithAttributes(
-		attribute.String("start", tuple.StringRR(req.ObjectRelation)),
+// DispatchLookupResources implements dispatch.LookupResources interface
+func (ld *localDispatcher) DispatchLookupResources(
+	req *v1.DispatchLookupResourcesRequest,
+	stream dispatch.LookupResourcesStream,
+) error {
+	ctx, span := tracer.Start(stream.Context(), "DispatchLookupResources", trace.WithAttributes(
+		attribute.String("resource-type", tuple.StringRR(req.ObjectRelation)),
 		attribute.String("subject", tuple.StringONR(req.Subject)),
-		attribute.Int64("limit", int64(req.Limit)),
+		attribute.Int64("limit", int64(req.OptionalLimit)),
 	))
 	defer span.End()
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata}, err
+		return err
 	}
 
 	revision, err := ld.parseRevision(ctx, req.Metadata.AtRevision)
 	if err != nil {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata}, err
-	}
-
-	if req.Limit <= 0 {
-		return &v1.DispatchLookupResponse{Metadata: emptyMetadata, ResolvedResources: []*v1.ResolvedResource{}}, nil
+		return err
 	}
 
-	return ld.lookupHandler.LookupViaReachability(ctx, graph.ValidatedLookupRequest{
-		DispatchLookupRequest: req,
-		Revision:              revision,
-	})
+	return ld.lookupHandler.LookupResources(
+		graph.ValidatedLookupResourcesRequest{
+			DispatchLookupResourcesRequest: req,
+			Revision:                       revision,
+		},
+		dispatch.StreamWithContext(ctx, stream),
+	)
 }
 
 // DispatchReachableResources implements dispatch.ReachableResources interface
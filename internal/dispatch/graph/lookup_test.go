@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -11,11 +12,14 @@ import (
 	"go.uber.org/goleak"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/util"
 )
 
 func RR(namespaceName string, relationName string) *core.RelationReference {
@@ -111,52 +115,224 @@ func TestSimpleLookup(t *testing.T) {
 			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
 
 			require := require.New(t)
-			ctx, dispatch, revision := newLocalDispatcher(t)
-			defer dispatch.Close()
+			ctx, dispatcher, revision := newLocalDispatcher(t)
+			defer dispatcher.Close()
 
-			lookupResult, err := dispatch.DispatchLookup(ctx, &v1.DispatchLookupRequest{
+			stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+			err := dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
 				ObjectRelation: tc.start,
 				Subject:        tc.target,
 				Metadata: &v1.ResolverMeta{
 					AtRevision:     revision.String(),
 					DepthRemaining: 50,
 				},
-				Limit: 10,
-			})
-
+			}, stream)
 			require.NoError(err)
-			require.ElementsMatch(tc.expectedResources, lookupResult.ResolvedResources, "Found: %v, Expected: %v", lookupResult.ResolvedResources, tc.expectedResources)
-			require.GreaterOrEqual(lookupResult.Metadata.DepthRequired, uint32(1))
-			require.LessOrEqual(int(lookupResult.Metadata.DispatchCount), tc.expectedDispatchCount, "Found dispatch count greater than expected")
-			require.Equal(0, int(lookupResult.Metadata.CachedDispatchCount))
-			require.Equal(tc.expectedDepthRequired, int(lookupResult.Metadata.DepthRequired), "Depth required mismatch")
+
+			foundResources, lookupMetadata := processResults(stream)
+			require.ElementsMatch(tc.expectedResources, foundResources, "Found: %v, Expected: %v", foundResources, tc.expectedResources)
+			require.GreaterOrEqual(lookupMetadata.DepthRequired, uint32(1))
+			require.LessOrEqual(int(lookupMetadata.DispatchCount), tc.expectedDispatchCount, "Found dispatch count greater than expected")
+			require.Equal(0, int(lookupMetadata.CachedDispatchCount))
+			require.Equal(tc.expectedDepthRequired, int(lookupMetadata.DepthRequired), "Depth required mismatch")
 
 			// We have to sleep a while to let the cache converge:
 			// https://github.com/outcaste-io/ristretto/blob/01b9f37dd0fd453225e042d6f3a27cd14f252cd0/cache_test.go#L17
 			time.Sleep(10 * time.Millisecond)
 
 			// Run again with the cache available.
-			lookupResult, err = dispatch.DispatchLookup(context.Background(), &v1.DispatchLookupRequest{
+			stream = dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+			err = dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
 				ObjectRelation: tc.start,
 				Subject:        tc.target,
 				Metadata: &v1.ResolverMeta{
 					AtRevision:     revision.String(),
 					DepthRemaining: 50,
 				},
-				Limit: 10,
-			})
-			dispatch.Close()
-
+			}, stream)
+			dispatcher.Close()
 			require.NoError(err)
-			require.ElementsMatch(tc.expectedResources, lookupResult.ResolvedResources, "Found: %v, Expected: %v", lookupResult.ResolvedResources, tc.expectedResources)
-			require.GreaterOrEqual(lookupResult.Metadata.DepthRequired, uint32(1))
-			require.Equal(0, int(lookupResult.Metadata.DispatchCount))
-			require.LessOrEqual(int(lookupResult.Metadata.CachedDispatchCount), tc.expectedDispatchCount)
-			require.Equal(tc.expectedDepthRequired, int(lookupResult.Metadata.DepthRequired))
+
+			foundResources, lookupMetadata = processResults(stream)
+			require.ElementsMatch(tc.expectedResources, foundResources, "Found: %v, Expected: %v", foundResources, tc.expectedResources)
+			require.GreaterOrEqual(lookupMetadata.DepthRequired, uint32(1))
+			require.Equal(0, int(lookupMetadata.DispatchCount))
+			require.LessOrEqual(int(lookupMetadata.CachedDispatchCount), tc.expectedDispatchCount)
+			require.Equal(tc.expectedDepthRequired, int(lookupMetadata.DepthRequired))
 		})
 	}
 }
 
+func TestSimpleLookupResourcesWithCursor(t *testing.T) {
+	defer goleak.VerifyNone(t, goleakIgnores...)
+
+	for _, tc := range []struct {
+		start    *core.RelationReference
+		target   *core.ObjectAndRelation
+		expected []string
+	}{
+		{RR("document", "view"), ONR("user", "legal", "..."), []string{"companyplan", "masterplan"}},
+		{RR("folder", "view"), ONR("user", "owner", "..."), []string{"company", "strategy"}},
+		{RR("document", "view"), ONR("user", "owner", "..."), []string{"companyplan", "masterplan"}},
+	} {
+		tc := tc
+		t.Run(tuple.StringONR(tc.target)+"->"+tc.start.Namespace, func(t *testing.T) {
+			require := require.New(t)
+
+			ctx, dispatcher, revision := newLocalDispatcher(t)
+			defer dispatcher.Close()
+
+			var currentCursor *v1.Cursor
+			found := []string{}
+			for i := 0; i < len(tc.expected)+1; i++ {
+				stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+				err := dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
+					ObjectRelation: tc.start,
+					Subject:        tc.target,
+					Metadata: &v1.ResolverMeta{
+						AtRevision:     revision.String(),
+						DepthRemaining: 50,
+					},
+					OptionalLimit:  1,
+					OptionalCursor: currentCursor,
+				}, stream)
+				require.NoError(err)
+
+				results := resultsWithResources(stream)
+				require.LessOrEqual(len(results), 1)
+				if len(results) == 0 {
+					break
+				}
+
+				found = append(found, results[0].ResolvedResource.ResourceId)
+				currentCursor = results[0].AfterResponseCursor
+			}
+
+			sort.Strings(found)
+			require.Equal(tc.expected, found)
+		})
+	}
+}
+
+func TestLookupResourcesCursorStability(t *testing.T) {
+	defer goleak.VerifyNone(t, goleakIgnores...)
+
+	testRels := make([]*core.RelationTuple, 0)
+	expected := make([]string, 0)
+	for i := 0; i < 1500; i++ {
+		testRels = append(testRels, tuple.MustParse(fmt.Sprintf("document:doc%04d#viewer@user:tom", i)))
+		expected = append(expected, fmt.Sprintf("doc%04d", i))
+		if i%3 == 0 {
+			testRels = append(testRels, tuple.MustParse(fmt.Sprintf("document:doc%04d#parent@folder:folder%d", i, i%25)))
+		}
+	}
+	for i := 0; i < 25; i++ {
+		testRels = append(testRels, tuple.MustParse(fmt.Sprintf("folder:folder%d#viewer@user:tom", i)))
+	}
+	for i := 1500; i < 1750; i++ {
+		testRels = append(testRels, tuple.MustParse(fmt.Sprintf("document:doc%04d#parent@folder:folder%d", i, i%25)))
+		expected = append(expected, fmt.Sprintf("doc%04d", i))
+	}
+
+	ctx, dispatcher, revision := newLocalDispatcherWithSchemaAndRels(t, `
+		definition user {}
+
+		definition folder {
+			relation viewer: user
+			permission view = viewer
+		}
+
+		definition document {
+			relation parent: folder
+			relation viewer: user
+			permission view = viewer + parent->view
+		}
+	`, testRels)
+	defer dispatcher.Close()
+
+	for _, pageSize := range []uint32{1, 7, 100, 333, 1000} {
+		pageSize := pageSize
+		t.Run(fmt.Sprintf("page-size-%d", pageSize), func(t *testing.T) {
+			require := require.New(t)
+
+			var currentCursor *v1.Cursor
+			found := util.NewSet[string]()
+			for {
+				stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+				err := dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
+					ObjectRelation: RR("document", "view"),
+					Subject:        ONR("user", "tom", "..."),
+					Metadata: &v1.ResolverMeta{
+						AtRevision:     revision.String(),
+						DepthRemaining: 50,
+					},
+					OptionalLimit:  pageSize,
+					OptionalCursor: currentCursor,
+				}, stream)
+				require.NoError(err)
+
+				results := resultsWithResources(stream)
+				require.LessOrEqual(len(results), int(pageSize))
+				if len(results) == 0 {
+					break
+				}
+
+				for _, result := range results {
+					found.Add(result.ResolvedResource.ResourceId)
+					currentCursor = result.AfterResponseCursor
+				}
+
+				if len(results) < int(pageSize) {
+					break
+				}
+			}
+
+			foundResources := found.AsSlice()
+			sort.Strings(foundResources)
+			require.Equal(expected, foundResources)
+		})
+	}
+}
+
+func TestLookupResourcesInvalidCursor(t *testing.T) {
+	defer goleak.VerifyNone(t, goleakIgnores...)
+
+	ctx, dispatcher, revision := newLocalDispatcher(t)
+	defer dispatcher.Close()
+
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+	err := dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
+		ObjectRelation: RR("document", "view"),
+		Subject:        ONR("user", "legal", "..."),
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 50,
+		},
+		OptionalCursor: &v1.Cursor{
+			Sections:        []string{"notanumber"},
+			DispatchVersion: 1,
+		},
+	}, stream)
+	require.Error(t, err)
+	require.ErrorAs(t, err, &graph.ErrInvalidArgument{})
+
+	stream = dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+	err = dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
+		ObjectRelation: RR("document", "view"),
+		Subject:        ONR("user", "legal", "..."),
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 50,
+		},
+		OptionalCursor: &v1.Cursor{
+			Sections:        []string{"0"},
+			DispatchVersion: 9999,
+		},
+	}, stream)
+	require.Error(t, err)
+	require.ErrorAs(t, err, &graph.ErrInvalidArgument{})
+}
+
 func TestMaxDepthLookup(t *testing.T) {
 	require := require.New(t)
 
@@ -165,23 +341,45 @@ func TestMaxDepthLookup(t *testing.T) {
 
 	ds, revision := testfixtures.StandardDatastoreWithData(rawDS, require)
 
-	dispatch := NewLocalOnlyDispatcher(10)
+	dispatcher := NewLocalOnlyDispatcher(10)
 	ctx := datastoremw.ContextWithHandle(context.Background())
 	require.NoError(datastoremw.SetInContext(ctx, ds))
 
-	_, err = dispatch.DispatchLookup(ctx, &v1.DispatchLookupRequest{
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](ctx)
+	err = dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
 		ObjectRelation: RR("document", "view"),
 		Subject:        ONR("user", "legal", "..."),
 		Metadata: &v1.ResolverMeta{
 			AtRevision:     revision.String(),
 			DepthRemaining: 0,
 		},
-		Limit: 10,
-	})
+	}, stream)
 
 	require.Error(err)
 }
 
+func processResults(stream *dispatch.CollectingDispatchStream[*v1.DispatchLookupResourcesResponse]) ([]*v1.ResolvedResource, *v1.ResponseMeta) {
+	foundResources := []*v1.ResolvedResource{}
+	responseMetadata := &v1.ResponseMeta{}
+	for _, result := range stream.Results() {
+		dispatch.AddResponseMetadata(responseMetadata, result.Metadata)
+		if result.ResolvedResource != nil {
+			foundResources = append(foundResources, result.ResolvedResource)
+		}
+	}
+	return foundResources, responseMetadata
+}
+
+func resultsWithResources(stream *dispatch.CollectingDispatchStream[*v1.DispatchLookupResourcesResponse]) []*v1.DispatchLookupResourcesResponse {
+	results := make([]*v1.DispatchLookupResourcesResponse, 0, len(stream.Results()))
+	for _, result := range stream.Results() {
+		if result.ResolvedResource != nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
 type OrderedResolved []*v1.ResolvedResource
 
 func (a OrderedResolved) Len() int { return len(a) }
@@ -533,7 +533,6 @@ func TestCaveatedReachableResources(t *testing.T) {
 			ONR("user", "tom", "..."),
 			[]reachableResource{
 				{"document:foo#view", true},
-				{"document:foo#view", true},
 			},
 		},
 		{
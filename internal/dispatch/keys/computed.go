@@ -59,11 +59,13 @@ func checkRequestToKeyWithCanonical(req *v1.DispatchCheckRequest, canonicalKey s
 }
 
 // lookupRequestToKey converts a lookup request into a cache key
-func lookupRequestToKey(req *v1.DispatchLookupRequest, option dispatchCacheKeyHashComputeOption) DispatchCacheKey {
+func lookupRequestToKey(req *v1.DispatchLookupResourcesRequest, option dispatchCacheKeyHashComputeOption) DispatchCacheKey {
 	return dispatchCacheKeyHash(lookupPrefix, req.Metadata.AtRevision, option,
 		hashableRelationReference{req.ObjectRelation},
 		hashableOnr{req.Subject},
 		hashableContext{req.Context}, // NOTE: context is included here because lookup does a single dispatch
+		hashableLimit(req.OptionalLimit),
+		hashableCursor{req.OptionalCursor},
 	)
 }
 
@@ -80,6 +82,7 @@ func reachableResourcesRequestToKey(req *v1.DispatchReachableResourcesRequest, o
 		hashableRelationReference{req.ResourceRelation},
 		hashableRelationReference{req.SubjectRelation},
 		hashableIds(req.SubjectIds),
+		hashableCursor{req.OptionalCursor},
 	)
 }
 
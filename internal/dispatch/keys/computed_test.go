@@ -109,39 +109,39 @@ func TestStableCacheKeys(t *testing.T) {
 		{
 			"lookup resources",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
 				}, computeBothHashes)
 			},
-			"87b8e4dcf893f4abd701",
+			"dafc9feacce9f4dbb001",
 		},
 		{
 			"lookup resources with nil context",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
 					Context: nil,
 				}, computeBothHashes)
 			},
-			"87b8e4dcf893f4abd701",
+			"dafc9feacce9f4dbb001",
 		},
 		{
 			"lookup resources with empty context",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -151,15 +151,15 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"87b8e4dcf893f4abd701",
+			"dafc9feacce9f4dbb001",
 		},
 		{
 			"lookup resources with context",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -172,15 +172,15 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"8a9bd5bba3ba9cde9301",
+			"b7b9abd5edfee4ff03",
 		},
 		{
 			"lookup resources with different context",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -193,15 +193,15 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"f6db868dc194c19ade01",
+			"83e597a2cca8bde95c",
 		},
 		{
 			"lookup resources with escaped string",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -213,15 +213,15 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"f98bb6f7fce8eb9ecc01",
+			"c1bfeb8ac6aadcac5f",
 		},
 		{
 			"lookup resources with nested context",
 			func() DispatchCacheKey {
-				return lookupRequestToKey(&v1.DispatchLookupRequest{
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 					ObjectRelation: RR("document", "view"),
 					Subject:        ONR("user", "mariah", "..."),
-					Limit:          10,
+					OptionalLimit:  10,
 					Metadata: &v1.ResolverMeta{
 						AtRevision: "1234",
 					},
@@ -237,7 +237,7 @@ func TestStableCacheKeys(t *testing.T) {
 					}(),
 				}, computeBothHashes)
 			},
-			"e0d8d0e099d68b96fa01",
+			"e3909c82bdbabfd06d",
 		},
 		{
 			"reachable resources",
@@ -251,7 +251,43 @@ func TestStableCacheKeys(t *testing.T) {
 					},
 				}, computeBothHashes)
 			},
-			"e8848b9dd68f93a6c801",
+			"caf99d9fe4d68ab63f",
+		},
+		{
+			"reachable resources with cursor",
+			func() DispatchCacheKey {
+				return reachableResourcesRequestToKey(&v1.DispatchReachableResourcesRequest{
+					ResourceRelation: RR("document", "view"),
+					SubjectRelation:  RR("user", "..."),
+					SubjectIds:       []string{"mariah", "tom"},
+					Metadata: &v1.ResolverMeta{
+						AtRevision: "1234",
+					},
+					OptionalCursor: &v1.Cursor{
+						Sections:        []string{"1", "0", ""},
+						DispatchVersion: 1,
+					},
+				}, computeBothHashes)
+			},
+			"a0d08d85add786e552",
+		},
+		{
+			"lookup resources with cursor",
+			func() DispatchCacheKey {
+				return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
+					ObjectRelation: RR("document", "view"),
+					Subject:        ONR("user", "mariah", "..."),
+					OptionalLimit:  10,
+					Metadata: &v1.ResolverMeta{
+						AtRevision: "1234",
+					},
+					OptionalCursor: &v1.Cursor{
+						Sections:        []string{"0", "1"},
+						DispatchVersion: 1,
+					},
+				}, computeBothHashes)
+			},
+			"8982d9a38899d0e327",
 		},
 		{
 			"lookup subjects",
@@ -296,17 +332,17 @@ var generatorFuncs = map[string]generatorFunc{
 		metadata *v1.ResolverMeta,
 	) (DispatchCacheKey, []string) {
 		return checkRequestToKey(&v1.DispatchCheckRequest{
-				ResourceRelation: resourceRelation,
-				ResourceIds:      resourceIds,
-				Subject:          ONR(subjectRelation.Namespace, subjectIds[0], subjectRelation.Relation),
-				Metadata:         metadata,
-			}, computeBothHashes), []string{
-				resourceRelation.Namespace,
-				resourceRelation.Relation,
-				subjectRelation.Namespace,
-				subjectIds[0],
-				subjectRelation.Relation,
-			}
+			ResourceRelation: resourceRelation,
+			ResourceIds:      resourceIds,
+			Subject:          ONR(subjectRelation.Namespace, subjectIds[0], subjectRelation.Relation),
+			Metadata:         metadata,
+		}, computeBothHashes), []string{
+			resourceRelation.Namespace,
+			resourceRelation.Relation,
+			subjectRelation.Namespace,
+			subjectIds[0],
+			subjectRelation.Relation,
+		}
 	},
 
 	// Canonical Check.
@@ -340,17 +376,17 @@ var generatorFuncs = map[string]generatorFunc{
 		subjectRelation *core.RelationReference,
 		metadata *v1.ResolverMeta,
 	) (DispatchCacheKey, []string) {
-		return lookupRequestToKey(&v1.DispatchLookupRequest{
-				ObjectRelation: resourceRelation,
-				Subject:        ONR(subjectRelation.Namespace, subjectIds[0], subjectRelation.Relation),
-				Metadata:       metadata,
-			}, computeBothHashes), []string{
-				resourceRelation.Namespace,
-				resourceRelation.Relation,
-				subjectRelation.Namespace,
-				subjectIds[0],
-				subjectRelation.Relation,
-			}
+		return lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
+			ObjectRelation: resourceRelation,
+			Subject:        ONR(subjectRelation.Namespace, subjectIds[0], subjectRelation.Relation),
+			Metadata:       metadata,
+		}, computeBothHashes), []string{
+			resourceRelation.Namespace,
+			resourceRelation.Relation,
+			subjectRelation.Namespace,
+			subjectIds[0],
+			subjectRelation.Relation,
+		}
 	},
 
 	// Expand.
@@ -362,13 +398,13 @@ var generatorFuncs = map[string]generatorFunc{
 		metadata *v1.ResolverMeta,
 	) (DispatchCacheKey, []string) {
 		return expandRequestToKey(&v1.DispatchExpandRequest{
-				ResourceAndRelation: ONR(resourceRelation.Namespace, resourceIds[0], resourceRelation.Relation),
-				Metadata:            metadata,
-			}, computeBothHashes), []string{
-				resourceRelation.Namespace,
-				resourceIds[0],
-				resourceRelation.Relation,
-			}
+			ResourceAndRelation: ONR(resourceRelation.Namespace, resourceIds[0], resourceRelation.Relation),
+			Metadata:            metadata,
+		}, computeBothHashes), []string{
+			resourceRelation.Namespace,
+			resourceIds[0],
+			resourceRelation.Relation,
+		}
 	},
 
 	// Reachable Resources.
@@ -508,10 +544,10 @@ func TestComputeOnlyStableHash(t *testing.T) {
 }
 
 func TestComputeContextHash(t *testing.T) {
-	result := lookupRequestToKey(&v1.DispatchLookupRequest{
+	result := lookupRequestToKey(&v1.DispatchLookupResourcesRequest{
 		ObjectRelation: RR("document", "view"),
 		Subject:        ONR("user", "mariah", "..."),
-		Limit:          10,
+		OptionalLimit:  10,
 		Metadata: &v1.ResolverMeta{
 			AtRevision: "1234",
 		},
@@ -531,5 +567,5 @@ func TestComputeContextHash(t *testing.T) {
 		}(),
 	}, computeBothHashes)
 
-	require.Equal(t, "82b4a3a3c5e3ecf1df01", hex.EncodeToString(result.StableSumAsBytes()))
+	require.Equal(t, "fffecbcab0f1fc9022", hex.EncodeToString(result.StableSumAsBytes()))
 }
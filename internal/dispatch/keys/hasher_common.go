@@ -70,6 +70,29 @@ func (hs hashableString) AppendToHash(hasher hasherInterface) {
 	hasher.WriteString(string(hs))
 }
 
+type hashableLimit uint32
+
+func (hl hashableLimit) AppendToHash(hasher hasherInterface) {
+	if hl > 0 {
+		hasher.WriteString(strconv.Itoa(int(hl)))
+	}
+}
+
+type hashableCursor struct{ *v1.Cursor }
+
+func (hc hashableCursor) AppendToHash(hasher hasherInterface) {
+	if hc.Cursor == nil {
+		return
+	}
+
+	hasher.WriteString(strconv.Itoa(int(hc.DispatchVersion)))
+	hasher.WriteString(":")
+	for _, section := range hc.Sections {
+		hasher.WriteString(section)
+		hasher.WriteString(",")
+	}
+}
+
 type hashableContext struct{ *structpb.Struct }
 
 func (hc hashableContext) AppendToHash(hasher hasherInterface) {
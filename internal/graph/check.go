@@ -450,7 +450,16 @@ func (cc *ConcurrentChecker) runSetOperation(ctx context.Context, crc currentReq
 	case *core.SetOperation_Child_UsersetRewrite:
 		return cc.checkUsersetRewrite(ctx, crc, child.UsersetRewrite)
 	case *core.SetOperation_Child_TupleToUserset:
-		return cc.checkTupleToUserset(ctx, crc, child.TupleToUserset)
+		return cc.checkTupleToUserset(ctx, crc, child.TupleToUserset.Tupleset, child.TupleToUserset.ComputedUserset)
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		switch child.FunctionedTupleToUserset.Function {
+		case core.FunctionedTupleToUserset_FUNCTION_ANY:
+			return cc.checkTupleToUserset(ctx, crc, child.FunctionedTupleToUserset.Tupleset, child.FunctionedTupleToUserset.ComputedUserset)
+		case core.FunctionedTupleToUserset_FUNCTION_ALL:
+			return cc.checkIntersectionTupleToUserset(ctx, crc, child.FunctionedTupleToUserset)
+		default:
+			return checkResultError(spiceerrors.MustBugf("unknown arrow function `%v` in check", child.FunctionedTupleToUserset.Function), emptyMetadata)
+		}
 	case *core.SetOperation_Child_XNil:
 		return noMembers()
 	default:
@@ -539,16 +548,18 @@ func removeIndexFromSlice[T any](s []T, index int) []T {
 	return append(cpy, s[index+1:]...)
 }
 
-func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc currentRequestContext, ttu *core.TupleToUserset) CheckResult {
-	log.Ctx(ctx).Trace().Object("ttu", crc.parentReq).Send()
+// loadTupleset loads the relationships of the tupleset relation for the resources being checked,
+// returning the batched dispatches for their subjects, along with the relationships keyed by the
+// string form of their subject.
+func (cc *ConcurrentChecker) loadTupleset(ctx context.Context, crc currentRequestContext, tupleset *core.TupleToUserset_Tupleset) ([]directDispatch, *util.MultiMap[string, *core.RelationTuple], error) {
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(crc.parentReq.Revision)
 	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
 		ResourceType:             crc.parentReq.ResourceRelation.Namespace,
 		OptionalResourceIds:      crc.filteredResourceIDs,
-		OptionalResourceRelation: ttu.Tupleset.Relation,
+		OptionalResourceRelation: tupleset.Relation,
 	})
 	if err != nil {
-		return checkResultError(NewCheckFailureErr(err), emptyMetadata)
+		return nil, nil, NewCheckFailureErr(err)
 	}
 	defer it.Close()
 
@@ -556,7 +567,7 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 	relationshipsBySubjectONR := util.NewMultiMap[string, *core.RelationTuple]()
 	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 		if it.Err() != nil {
-			return checkResultError(NewCheckFailureErr(it.Err()), emptyMetadata)
+			return nil, nil, NewCheckFailureErr(it.Err())
 		}
 
 		subjectsToDispatch.Add(tpl.Subject)
@@ -578,12 +589,22 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 		dispatchChunkCountHistogram.Observe(chunkCount)
 	})
 
+	return toDispatch, relationshipsBySubjectONR, nil
+}
+
+func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc currentRequestContext, tupleset *core.TupleToUserset_Tupleset, computedUserset *core.ComputedUserset) CheckResult {
+	log.Ctx(ctx).Trace().Object("ttu", crc.parentReq).Send()
+	toDispatch, relationshipsBySubjectONR, err := cc.loadTupleset(ctx, crc, tupleset)
+	if err != nil {
+		return checkResultError(err, emptyMetadata)
+	}
+
 	return union(
 		ctx,
 		crc,
 		toDispatch,
 		func(ctx context.Context, crc currentRequestContext, dd directDispatch) CheckResult {
-			childResult := cc.checkComputedUserset(ctx, crc, ttu.ComputedUserset, dd.resourceType, dd.resourceIds)
+			childResult := cc.checkComputedUserset(ctx, crc, computedUserset, dd.resourceType, dd.resourceIds)
 			if childResult.Err != nil {
 				return childResult
 			}
@@ -594,6 +615,123 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 	)
 }
 
+// checkIntersectionTupleToUserset checks an `all` arrow, under which a resource is a member only
+// if it has at least one relationship for the tupleset, and every subject of those relationships
+// has the computed userset.
+func (cc *ConcurrentChecker) checkIntersectionTupleToUserset(ctx context.Context, crc currentRequestContext, ttu *core.FunctionedTupleToUserset) CheckResult {
+	log.Ctx(ctx).Trace().Object("intersectionttu", crc.parentReq).Send()
+	toDispatch, relationshipsBySubjectONR, err := cc.loadTupleset(ctx, crc, ttu.Tupleset)
+	if err != nil {
+		return checkResultError(err, emptyMetadata)
+	}
+
+	// Every subject must be checked, so results cannot be short-circuited.
+	subjectsCRC := crc
+	subjectsCRC.resultsSetting = v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS
+
+	// Subjects of different types can share object IDs, so the results are keyed by the string
+	// form of each subject.
+	subjectsResult := union(
+		ctx,
+		subjectsCRC,
+		toDispatch,
+		func(ctx context.Context, crc currentRequestContext, dd directDispatch) CheckResult {
+			childResult := cc.checkComputedUserset(ctx, crc, ttu.ComputedUserset, dd.resourceType, dd.resourceIds)
+			if childResult.Err != nil {
+				return childResult
+			}
+
+			membershipSet := NewMembershipSet()
+			for foundResourceID, result := range childResult.Resp.ResultsByResourceId {
+				membershipSet.addMember(tuple.StringONR(&core.ObjectAndRelation{
+					Namespace: dd.resourceType.Namespace,
+					ObjectId:  foundResourceID,
+					Relation:  dd.resourceType.Relation,
+				}), result.Expression)
+			}
+
+			return checkResultsForMembership(membershipSet, childResult.Resp.Metadata)
+		},
+		cc.concurrencyLimit,
+	)
+	if subjectsResult.Err != nil {
+		return subjectsResult
+	}
+
+	relationshipsByResourceID := util.NewMultiMap[string, *core.RelationTuple]()
+	for _, relationship := range relationshipsBySubjectONR.Values() {
+		relationshipsByResourceID.Add(relationship.ResourceAndRelation.ObjectId, relationship)
+	}
+
+	membershipSet := NewMembershipSet()
+	for _, resourceID := range relationshipsByResourceID.Keys() {
+		relationships, _ := relationshipsByResourceID.Get(resourceID)
+		if isMember, caveatExpr := allSubjectsHaveMembership(relationships, subjectsResult.Resp.ResultsByResourceId); isMember {
+			membershipSet.addMember(resourceID, caveatExpr)
+		}
+	}
+
+	if membershipSet.IsEmpty() {
+		return noMembersWithMetadata(subjectsResult.Resp.Metadata)
+	}
+
+	return checkResultsForMembership(membershipSet, subjectsResult.Resp.Metadata)
+}
+
+// allSubjectsHaveMembership returns whether the subjects of all the given relationships of a
+// resource have membership, along with the caveat expression under which they do, if any.
+//
+// A caveated relationship only exists if its caveat is satisfied, so its subject only needs
+// membership if it does. If all relationships are caveated, at least one of them with a subject
+// having membership must exist.
+func allSubjectsHaveMembership(relationships []*core.RelationTuple, resultsBySubject CheckResultsMap) (bool, *core.CaveatExpression) {
+	var caveatExpr *core.CaveatExpression
+	var existenceExpr *core.CaveatExpression
+	allCaveated := true
+	anyFound := false
+
+	for _, relationship := range relationships {
+		relationshipCaveat := wrapCaveat(relationship.Caveat)
+		if relationshipCaveat == nil {
+			allCaveated = false
+		}
+
+		result, found := resultsBySubject[tuple.StringONR(relationship.Subject)]
+		if found {
+			anyFound = true
+			existenceExpr = caveatOr(existenceExpr, caveatAnd(relationshipCaveat, result.Expression))
+		}
+
+		switch {
+		case found && result.Expression == nil:
+			// The subject is unconditionally a member.
+			continue
+
+		case !found && relationshipCaveat == nil:
+			return false, nil
+
+		case !found:
+			caveatExpr = caveatAnd(caveatExpr, caveatInvert(relationshipCaveat))
+
+		case relationshipCaveat == nil:
+			caveatExpr = caveatAnd(caveatExpr, result.Expression)
+
+		default:
+			caveatExpr = caveatAnd(caveatExpr, caveatOr(caveatInvert(relationshipCaveat), result.Expression))
+		}
+	}
+
+	if !allCaveated {
+		return true, caveatExpr
+	}
+
+	if !anyFound {
+		return false, nil
+	}
+
+	return true, caveatAnd(caveatExpr, existenceExpr)
+}
+
 // union returns whether any one of the lazy checks pass, and is used for union.
 func union[T any](
 	ctx context.Context,
@@ -8,6 +8,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/caveats"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func TestAsyncDispatch(t *testing.T) {
@@ -83,3 +88,113 @@ func TestAsyncDispatch(t *testing.T) {
 		})
 	}
 }
+
+func TestAllSubjectsHaveMembership(t *testing.T) {
+	withCaveat := func(rel string, caveatName string) *core.RelationTuple {
+		tpl := tuple.MustParse(rel)
+		tpl.Caveat = caveats.CaveatForTesting(caveatName)
+		return tpl
+	}
+
+	testCases := []struct {
+		name             string
+		relationships    []*core.RelationTuple
+		resultsBySubject CheckResultsMap
+		expectedMember   bool
+		expectedCaveat   *core.CaveatExpression
+	}{
+		{
+			"all subjects are members",
+			[]*core.RelationTuple{
+				tuple.MustParse("document:doc#team@team:first"),
+				tuple.MustParse("document:doc#team@team:second"),
+			},
+			CheckResultsMap{
+				"team:first":  {Membership: v1.ResourceCheckResult_MEMBER},
+				"team:second": {Membership: v1.ResourceCheckResult_MEMBER},
+			},
+			true,
+			nil,
+		},
+		{
+			"one subject is not a member",
+			[]*core.RelationTuple{
+				tuple.MustParse("document:doc#team@team:first"),
+				tuple.MustParse("document:doc#team@team:second"),
+			},
+			CheckResultsMap{
+				"team:first": {Membership: v1.ResourceCheckResult_MEMBER},
+			},
+			false,
+			nil,
+		},
+		{
+			"caveated subject membership",
+			[]*core.RelationTuple{
+				tuple.MustParse("document:doc#team@team:first"),
+				tuple.MustParse("document:doc#team@team:second"),
+			},
+			CheckResultsMap{
+				"team:first":  {Membership: v1.ResourceCheckResult_MEMBER},
+				"team:second": {Membership: v1.ResourceCheckResult_CAVEATED_MEMBER, Expression: caveats.CaveatExprForTesting("somecaveat")},
+			},
+			true,
+			caveats.CaveatExprForTesting("somecaveat"),
+		},
+		{
+			"caveated relationship to a subject which is not a member",
+			[]*core.RelationTuple{
+				tuple.MustParse("document:doc#team@team:first"),
+				withCaveat("document:doc#team@team:second", "relcaveat"),
+			},
+			CheckResultsMap{
+				"team:first": {Membership: v1.ResourceCheckResult_MEMBER},
+			},
+			true,
+			caveats.Invert(caveats.CaveatExprForTesting("relcaveat")),
+		},
+		{
+			"caveated relationship to a caveated member",
+			[]*core.RelationTuple{
+				tuple.MustParse("document:doc#team@team:first"),
+				withCaveat("document:doc#team@team:second", "relcaveat"),
+			},
+			CheckResultsMap{
+				"team:first":  {Membership: v1.ResourceCheckResult_MEMBER},
+				"team:second": {Membership: v1.ResourceCheckResult_CAVEATED_MEMBER, Expression: caveats.CaveatExprForTesting("somecaveat")},
+			},
+			true,
+			caveats.Or(caveats.Invert(caveats.CaveatExprForTesting("relcaveat")), caveats.CaveatExprForTesting("somecaveat")),
+		},
+		{
+			"all relationships caveated",
+			[]*core.RelationTuple{
+				withCaveat("document:doc#team@team:first", "relcaveat"),
+			},
+			CheckResultsMap{
+				"team:first": {Membership: v1.ResourceCheckResult_MEMBER},
+			},
+			true,
+			caveats.CaveatExprForTesting("relcaveat"),
+		},
+		{
+			"all relationships caveated and no subjects are members",
+			[]*core.RelationTuple{
+				withCaveat("document:doc#team@team:first", "relcaveat"),
+				withCaveat("document:doc#team@team:second", "relcaveat"),
+			},
+			CheckResultsMap{},
+			false,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			isMember, caveatExpr := allSubjectsHaveMembership(tc.relationships, tc.resultsBySubject)
+			require.Equal(t, tc.expectedMember, isMember)
+			require.True(t, tc.expectedCaveat.EqualVT(caveatExpr), "expected caveat %v, found %v", tc.expectedCaveat, caveatExpr)
+		})
+	}
+}
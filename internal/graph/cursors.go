@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// lrDispatchVersion defines the "version" of the lookup resources and reachable resources
+// dispatchers. It must be incremented anytime an incompatible change is made to the
+// dispatchers themselves or to the cursors they produce.
+const lrDispatchVersion = 1
+
+// NewInvalidCursorErr constructs an error indicating that the cursor given is invalid.
+func NewInvalidCursorErr(reason string) error {
+	return NewErrInvalidArgument(fmt.Errorf("the supplied cursor is no longer valid: %s", reason))
+}
+
+// cursorSections returns the sections of the given cursor, or nil if no cursor was given.
+// An error is returned if the cursor was produced by a different version of the dispatcher.
+func cursorSections(cursor *v1.Cursor) ([]string, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	if cursor.DispatchVersion != lrDispatchVersion {
+		return nil, NewInvalidCursorErr("cursor was created by a different version of the dispatcher")
+	}
+
+	return cursor.Sections, nil
+}
+
+// cursorFromSections returns a cursor for the current dispatch version, containing the given
+// sections.
+func cursorFromSections(sections ...string) *v1.Cursor {
+	return &v1.Cursor{
+		Sections:        sections,
+		DispatchVersion: lrDispatchVersion,
+	}
+}
+
+// childCursorFromSections returns the cursor to be handed to a child dispatch, or nil if the
+// child dispatch should start from the beginning.
+func childCursorFromSections(sections []string) *v1.Cursor {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	return cursorFromSections(sections...)
+}
+
+// combineCursors returns a cursor consisting of the given prefix sections, followed by those
+// found in the child cursor (if any).
+func combineCursors(prefix []string, childCursor *v1.Cursor) *v1.Cursor {
+	sections := make([]string, 0, len(prefix)+len(childCursor.GetSections()))
+	sections = append(sections, prefix...)
+	sections = append(sections, childCursor.GetSections()...)
+	return cursorFromSections(sections...)
+}
+
+// intFromSections parses the leading section of the sections as a non-negative integer,
+// returning it along with the remaining sections. If no sections remain, zero is returned.
+func intFromSections(sections []string) (int, []string, error) {
+	if len(sections) == 0 {
+		return 0, nil, nil
+	}
+
+	value, err := strconv.Atoi(sections[0])
+	if err != nil {
+		return 0, nil, NewInvalidCursorErr("expected integer section")
+	}
+
+	if value < 0 {
+		return 0, nil, NewInvalidCursorErr("expected non-negative integer section")
+	}
+
+	return value, sections[1:], nil
+}
+
+var errPublisherClosed = errors.New("ordered publisher has already been closed")
+
+// orderedPublisher publishes the results of concurrently executing units of work to a parent
+// stream in the order in which the units were added. Results from the unit at the head of the
+// order are published immediately, while results from later units are buffered until all units
+// before them have completed.
+//
+// This is used to ensure that the results of a dispatch are returned in a deterministic order,
+// so that cursors can be used to resume the dispatch.
+type orderedPublisher[T any] struct {
+	ctx     context.Context
+	publish func(T) error
+	onDone  func() error
+
+	slots    []*orderedSlot[T]
+	head     int
+	closed   bool
+	finished bool
+
+	mu sync.Mutex
+}
+
+// newOrderedPublisher creates a new orderedPublisher, publishing to the given function. If
+// specified, onDone is invoked once the publisher has been closed and all of its units have
+// completed.
+func newOrderedPublisher[T any](ctx context.Context, publish func(T) error, onDone func() error) *orderedPublisher[T] {
+	return &orderedPublisher[T]{
+		ctx:     ctx,
+		publish: publish,
+		onDone:  onDone,
+	}
+}
+
+// nestedOrderedPublisher creates a new orderedPublisher whose units publish, in order, into the
+// given slot of a parent publisher. The slot is marked as done once the nested publisher is closed
+// and all of its units have completed.
+func nestedOrderedPublisher[T any](slot *orderedSlot[T]) *orderedPublisher[T] {
+	return newOrderedPublisher(slot.Context(), slot.Publish, slot.MarkDone)
+}
+
+// NewSlot adds a new unit of work to the end of the order, returning its slot.
+func (op *orderedPublisher[T]) NewSlot() *orderedSlot[T] {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	slot := &orderedSlot[T]{publisher: op, index: len(op.slots)}
+	op.slots = append(op.slots, slot)
+	return slot
+}
+
+// Close indicates that no further slots will be added to the publisher.
+func (op *orderedPublisher[T]) Close() error {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	op.closed = true
+	return op.advanceUnsafe()
+}
+
+// advanceUnsafe flushes the buffered results of the head slot(s), advancing the head past
+// any slots which have completed.
+func (op *orderedPublisher[T]) advanceUnsafe() error {
+	for op.head < len(op.slots) {
+		current := op.slots[op.head]
+		for _, result := range current.buffered {
+			if err := op.publish(result); err != nil {
+				return err
+			}
+		}
+		current.buffered = nil
+
+		if !current.done {
+			return nil
+		}
+
+		op.slots[op.head] = nil
+		op.head++
+	}
+
+	if op.closed && !op.finished {
+		op.finished = true
+		if op.onDone != nil {
+			return op.onDone()
+		}
+	}
+
+	return nil
+}
+
+// orderedSlot is a single unit of work in an orderedPublisher. It implements dispatch.Stream.
+type orderedSlot[T any] struct {
+	publisher *orderedPublisher[T]
+	index     int
+	buffered  []T
+	done      bool
+}
+
+// Context returns the context of the publisher.
+func (os *orderedSlot[T]) Context() context.Context {
+	return os.publisher.ctx
+}
+
+// Publish publishes the result if the slot is at the head of the order, and buffers it
+// otherwise.
+func (os *orderedSlot[T]) Publish(result T) error {
+	op := os.publisher
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	if os.done {
+		return errPublisherClosed
+	}
+
+	if os.index == op.head {
+		return op.publish(result)
+	}
+
+	os.buffered = append(os.buffered, result)
+	return nil
+}
+
+// MarkDone marks the unit of work for the slot as having completed.
+func (os *orderedSlot[T]) MarkDone() error {
+	op := os.publisher
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	os.done = true
+	return op.advanceUnsafe()
+}
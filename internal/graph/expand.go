@@ -191,7 +191,16 @@ func (ce *ConcurrentExpander) expandSetOperation(ctx context.Context, req Valida
 		case *core.SetOperation_Child_UsersetRewrite:
 			requests = append(requests, ce.expandUsersetRewrite(ctx, req, child.UsersetRewrite))
 		case *core.SetOperation_Child_TupleToUserset:
-			requests = append(requests, ce.expandTupleToUserset(ctx, req, child.TupleToUserset))
+			requests = append(requests, ce.expandTupleToUserset(ctx, req, child.TupleToUserset.Tupleset, child.TupleToUserset.ComputedUserset, expandAny))
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			switch child.FunctionedTupleToUserset.Function {
+			case core.FunctionedTupleToUserset_FUNCTION_ANY:
+				requests = append(requests, ce.expandTupleToUserset(ctx, req, child.FunctionedTupleToUserset.Tupleset, child.FunctionedTupleToUserset.ComputedUserset, expandAny))
+			case core.FunctionedTupleToUserset_FUNCTION_ALL:
+				requests = append(requests, ce.expandTupleToUserset(ctx, req, child.FunctionedTupleToUserset.Tupleset, child.FunctionedTupleToUserset.ComputedUserset, expandAll))
+			default:
+				return expandError(spiceerrors.MustBugf("unknown arrow function `%v` in expand", child.FunctionedTupleToUserset.Function))
+			}
 		case *core.SetOperation_Child_XNil:
 			requests = append(requests, emptyExpansion(req.ResourceAndRelation))
 		default:
@@ -253,13 +262,17 @@ func (ce *ConcurrentExpander) expandComputedUserset(ctx context.Context, req Val
 	})
 }
 
-func (ce *ConcurrentExpander) expandTupleToUserset(_ context.Context, req ValidatedExpandRequest, ttu *core.TupleToUserset) ReduceableExpandFunc {
+// expandTupleToUserset expands the computed userset for each subject found for the tupleset,
+// combining their expansions with the reducer. The caveat of each relationship is applied to the
+// expansion of its subject, so under an intersection the expansion is only an approximation when
+// the relationships are caveated.
+func (ce *ConcurrentExpander) expandTupleToUserset(_ context.Context, req ValidatedExpandRequest, tupleset *core.TupleToUserset_Tupleset, computedUserset *core.ComputedUserset, reducer ExpandReducer) ReduceableExpandFunc {
 	return func(ctx context.Context, resultChan chan<- ExpandResult) {
 		ds := datastoremw.MustFromContext(ctx).SnapshotReader(req.Revision)
 		it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
 			ResourceType:             req.ResourceAndRelation.Namespace,
 			OptionalResourceIds:      []string{req.ResourceAndRelation.ObjectId},
-			OptionalResourceRelation: ttu.Tupleset.Relation,
+			OptionalResourceRelation: tupleset.Relation,
 		})
 		if err != nil {
 			resultChan <- expandResultError(NewExpansionFailureErr(err), emptyMetadata)
@@ -274,12 +287,18 @@ func (ce *ConcurrentExpander) expandTupleToUserset(_ context.Context, req Valida
 				return
 			}
 
-			toDispatch := ce.expandComputedUserset(ctx, req, ttu.ComputedUserset, tpl)
+			toDispatch := ce.expandComputedUserset(ctx, req, computedUserset, tpl)
 			requestsToDispatch = append(requestsToDispatch, decorateWithCaveatIfNecessary(toDispatch, caveats.CaveatAsExpr(tpl.Caveat)))
 		}
 		it.Close()
 
-		resultChan <- expandAny(ctx, req.ResourceAndRelation, requestsToDispatch)
+		// An intersection over no subjects would be ambiguous, so no subjects are always
+		// expanded as an empty union.
+		if len(requestsToDispatch) == 0 {
+			reducer = expandAny
+		}
+
+		resultChan <- reducer(ctx, req.ResourceAndRelation, requestsToDispatch)
 	}
 }
 
@@ -3,154 +3,313 @@ package graph
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
 	"sync"
 
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
-
-	"github.com/authzed/spicedb/internal/dispatch"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
-// NewConcurrentLookup creates and instance of ConcurrentLookup.
-func NewConcurrentLookup(c dispatch.Check, r dispatch.ReachableResources, concurrencyLimit uint16) *ConcurrentLookup {
-	return &ConcurrentLookup{c, r, concurrencyLimit}
+// NewCursoredLookupResources creates and instance of CursoredLookupResources.
+func NewCursoredLookupResources(c dispatch.Check, r dispatch.ReachableResources, concurrencyLimit uint16) *CursoredLookupResources {
+	return &CursoredLookupResources{c, r, concurrencyLimit}
 }
 
-// ConcurrentLookup exposes a method to perform Lookup requests, and delegates subproblems to the
-// provided dispatch.Lookup instance.
-type ConcurrentLookup struct {
+// CursoredLookupResources exposes a method to perform LookupResources requests, and delegates
+// subproblems to the provided dispatch.Check and dispatch.ReachableResources instances.
+type CursoredLookupResources struct {
 	c                dispatch.Check
 	r                dispatch.ReachableResources
 	concurrencyLimit uint16
 }
 
-// ValidatedLookupRequest represents a request after it has been validated and parsed for internal
+// ValidatedLookupResourcesRequest represents a request after it has been validated and parsed for internal
 // consumption.
-type ValidatedLookupRequest struct {
-	*v1.DispatchLookupRequest
+type ValidatedLookupResourcesRequest struct {
+	*v1.DispatchLookupResourcesRequest
 	Revision datastore.Revision
 }
 
-type collectingStream struct {
-	checker *parallelChecker
-	req     ValidatedLookupRequest
-	context context.Context
-
-	dispatchCount       uint32
-	cachedDispatchCount uint32
-	depthRequired       uint32
-
-	mu sync.Mutex
-}
-
-func (ls *collectingStream) Context() context.Context {
-	return ls.context
-}
-
-func (ls *collectingStream) Publish(result *v1.DispatchReachableResourcesResponse) error {
-	if result == nil {
-		return spiceerrors.MustBugf("got nil result for Lookup publish")
+// LookupResources finds the resources for which the subject has the permission, publishing
+// them to the stream as they are found.
+//
+// Each published resource carries a cursor of the form:
+//
+//	[resultsToSkip, ...reachableResourcesSections]
+//
+// where reachableResourcesSections is the cursor of the reachable resources response *before* the
+// one in which the resource was found, and resultsToSkip is the number of results in that response
+// to skip when resuming.
+func (cl *CursoredLookupResources) LookupResources(
+	req ValidatedLookupResourcesRequest,
+	parentStream dispatch.LookupResourcesStream,
+) error {
+	if req.Subject.ObjectId == tuple.PublicWildcard {
+		return NewErrInvalidArgument(errors.New("cannot perform lookup resources on wildcard"))
 	}
 
-	func() {
-		ls.mu.Lock()
-		defer ls.mu.Unlock()
-
-		ls.dispatchCount += result.Metadata.DispatchCount
-		ls.cachedDispatchCount += result.Metadata.CachedDispatchCount
-		ls.depthRequired = max(result.Metadata.DepthRequired, ls.depthRequired)
-	}()
-
-	for _, found := range result.Resources {
-		if found.ResultStatus == v1.ReachableResource_HAS_PERMISSION {
-			ls.checker.AddResolvedResource(&v1.ResolvedResource{
-				ResourceId:     found.ResourceId,
-				Permissionship: v1.ResolvedResource_HAS_PERMISSION,
-			})
-			continue
-		}
-
-		ls.checker.QueueToCheck(found.ResourceId)
+	sections, err := cursorSections(req.OptionalCursor)
+	if err != nil {
+		return err
 	}
-	return nil
-}
 
-func (cl *ConcurrentLookup) LookupViaReachability(ctx context.Context, req ValidatedLookupRequest) (*v1.DispatchLookupResponse, error) {
-	if req.Subject.ObjectId == tuple.PublicWildcard {
-		resp := lookupResultError(NewErrInvalidArgument(errors.New("cannot perform lookup on wildcard")), emptyMetadata)
-		return resp.Resp, resp.Err
+	resultsToSkip, reachableSections, err := intFromSections(sections)
+	if err != nil {
+		return err
 	}
 
-	cancelCtx, cancel := context.WithCancel(ctx)
+	ctx, cancel := context.WithCancel(parentStream.Context())
 	defer cancel()
 
-	checker := newParallelChecker(cancelCtx, cancel, cl.c, req, cl.concurrencyLimit)
-	stream := &collectingStream{checker, req, cancelCtx, 0, 0, 0, sync.Mutex{}}
-
-	// Start the checker.
-	checker.Start()
+	stream := &checkingResourceStream{
+		ctx:           ctx,
+		cancel:        cancel,
+		parentStream:  parentStream,
+		checker:       cl.c,
+		req:           req,
+		currentCursor: childCursorFromSections(reachableSections),
+		resultsToSkip: resultsToSkip,
+		published:     map[string]v1.ResolvedResource_Permissionship{},
+		metadata:      &v1.ResponseMeta{DispatchCount: 1, DepthRequired: 1}, // 1 for the lookup
+	}
 
-	// Dispatch to the reachability API to find all reachable objects and queue them
-	// either for checks, or directly as results.
+	// Dispatch to the reachability API to find all reachable objects, checking those which
+	// require it and publishing them in order to the parent stream.
 	// NOTE: This dispatch call is blocking until all results have been sent to the specified
 	// stream.
-	err := cl.r.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
+	err = cl.r.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
 		ResourceRelation: req.ObjectRelation,
 		SubjectRelation: &core.RelationReference{
 			Namespace: req.Subject.Namespace,
 			Relation:  req.Subject.Relation,
 		},
 		SubjectIds: []string{req.Subject.ObjectId},
-		Metadata:   req.Metadata,
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     req.Revision.String(),
+			DepthRemaining: req.Metadata.DepthRemaining,
+		},
+		OptionalCursor: stream.currentCursor,
 	}, stream)
+	if stream.hasReachedLimit() {
+		return nil
+	}
 	if err != nil {
-		resp := lookupResultError(err, emptyMetadata)
-		return resp.Resp, resp.Err
+		return err
 	}
 
-	// Wait for the checker to finish.
-	allowed, err := checker.Wait()
-	if err != nil {
-		resp := lookupResultError(err, emptyMetadata)
-		return resp.Resp, resp.Err
+	return stream.publishRemainingMetadata()
+}
+
+// checkingResourceStream is a dispatch stream which receives reachable resources, checks those
+// requiring a check, and publishes the resolved resources to the parent stream.
+type checkingResourceStream struct {
+	ctx          context.Context
+	cancel       func()
+	parentStream dispatch.LookupResourcesStream
+	checker      dispatch.Check
+	req          ValidatedLookupResourcesRequest
+
+	// currentCursor is the cursor of the reachable resources response before the current one.
+	currentCursor *v1.Cursor
+
+	// resultsToSkip is the number of results to skip in the next response, if resuming.
+	resultsToSkip int
+
+	// published holds the resources published thus far, to avoid returning duplicates.
+	published map[string]v1.ResolvedResource_Permissionship
+
+	// metadata holds the metadata which has yet to be published to the parent stream.
+	metadata *v1.ResponseMeta
+
+	publishedCount uint32
+	limitReached   bool
+
+	mu sync.Mutex
+}
+
+func (crs *checkingResourceStream) Context() context.Context {
+	return crs.ctx
+}
+
+func (crs *checkingResourceStream) hasReachedLimit() bool {
+	crs.mu.Lock()
+	defer crs.mu.Unlock()
+	return crs.limitReached
+}
+
+// addSubproblemMetadata adds the metadata of a subproblem to the metadata to be published.
+func (crs *checkingResourceStream) addSubproblemMetadata(subProblemMetadata *v1.ResponseMeta) {
+	crs.metadata.DispatchCount += subProblemMetadata.DispatchCount
+	crs.metadata.CachedDispatchCount += subProblemMetadata.CachedDispatchCount
+	crs.metadata.DepthRequired = max(crs.metadata.DepthRequired, subProblemMetadata.DepthRequired+1) // +1 for the lookup
+}
+
+// publishRemainingMetadata publishes any metadata not yet published alongside a resource, as a
+// final response without a resolved resource.
+func (crs *checkingResourceStream) publishRemainingMetadata() error {
+	crs.mu.Lock()
+	defer crs.mu.Unlock()
+
+	if crs.metadata.DispatchCount == 0 && crs.metadata.CachedDispatchCount == 0 && crs.metadata.DepthRequired == 0 {
+		return nil
 	}
 
-	res := lookupResult(allowed, req, &v1.ResponseMeta{
-		DispatchCount:       stream.dispatchCount + checker.DispatchCount() + 1, // +1 for the lookup
-		CachedDispatchCount: stream.cachedDispatchCount + checker.CachedDispatchCount(),
-		DepthRequired:       max(stream.depthRequired, checker.DepthRequired()) + 1, // +1 for the lookup
+	err := crs.parentStream.Publish(&v1.DispatchLookupResourcesResponse{
+		Metadata: crs.metadata,
 	})
-	return res.Resp, res.Err
+	crs.metadata = &v1.ResponseMeta{}
+	return err
 }
 
-func lookupResult(foundResources []*v1.ResolvedResource, req ValidatedLookupRequest, subProblemMetadata *v1.ResponseMeta) LookupResult {
-	limitedResources := limitedSlice(foundResources, req.Limit)
+func (crs *checkingResourceStream) Publish(result *v1.DispatchReachableResourcesResponse) error {
+	if result == nil {
+		return spiceerrors.MustBugf("got nil result for LookupResources publish")
+	}
+
+	crs.mu.Lock()
+	defer crs.mu.Unlock()
 
-	return LookupResult{
-		&v1.DispatchLookupResponse{
-			Metadata:          ensureMetadata(subProblemMetadata),
-			ResolvedResources: limitedResources,
-		},
-		nil,
+	if crs.limitReached {
+		return nil
 	}
-}
 
-func limitedSlice[T any](slice []T, limit uint32) []T {
-	if len(slice) > int(limit) {
-		return slice[0:limit]
+	crs.addSubproblemMetadata(result.Metadata)
+
+	resolved, err := crs.resolveResources(result.Resources)
+	if err != nil {
+		return err
+	}
+
+	for index, resource := range resolved {
+		if crs.resultsToSkip > 0 {
+			crs.resultsToSkip--
+			continue
+		}
+
+		// The last result of the response points after the response, while all others
+		// point to the results remaining in this response.
+		cursor := combineCursors([]string{strconv.Itoa(index + 1)}, crs.currentCursor)
+		if index == len(resolved)-1 {
+			cursor = combineCursors([]string{"0"}, result.AfterResponseCursor)
+		}
+
+		// Skip any resources already published, unless the permissionship has been upgraded.
+		existing, ok := crs.published[resource.ResourceId]
+		if ok && (existing == v1.ResolvedResource_HAS_PERMISSION || resource.Permissionship == existing) {
+			continue
+		}
+		crs.published[resource.ResourceId] = resource.Permissionship
+
+		err := crs.parentStream.Publish(&v1.DispatchLookupResourcesResponse{
+			ResolvedResource:    resource,
+			Metadata:            crs.metadata,
+			AfterResponseCursor: cursor,
+		})
+		if err != nil {
+			return err
+		}
+
+		crs.metadata = &v1.ResponseMeta{}
+		crs.publishedCount++
+		if crs.req.OptionalLimit > 0 && crs.publishedCount >= crs.req.OptionalLimit {
+			// Cancel any further work.
+			crs.limitReached = true
+			crs.cancel()
+			return nil
+		}
 	}
 
-	return slice
+	crs.resultsToSkip = 0
+	crs.currentCursor = result.AfterResponseCursor
+	return nil
 }
 
-func lookupResultError(err error, subProblemMetadata *v1.ResponseMeta) LookupResult {
-	return LookupResult{
-		&v1.DispatchLookupResponse{
-			Metadata: ensureMetadata(subProblemMetadata),
-		},
-		err,
+// resolveResources returns the resolved resources for the reachable resources, in a stable
+// order, checking any which require a check.
+func (crs *checkingResourceStream) resolveResources(reachable []*v1.ReachableResource) ([]*v1.ResolvedResource, error) {
+	statuses := make(map[string]v1.ReachableResource_ResultStatus, len(reachable))
+	for _, resource := range reachable {
+		existing, ok := statuses[resource.ResourceId]
+		if !ok || existing == v1.ReachableResource_REQUIRES_CHECK {
+			statuses[resource.ResourceId] = resource.ResultStatus
+		}
+	}
+
+	resourceIDs := make([]string, 0, len(statuses))
+	toCheck := make([]string, 0, len(statuses))
+	for resourceID, status := range statuses {
+		resourceIDs = append(resourceIDs, resourceID)
+		if status == v1.ReachableResource_REQUIRES_CHECK {
+			toCheck = append(toCheck, resourceID)
+		}
+	}
+	sort.Strings(resourceIDs)
+	sort.Strings(toCheck)
+
+	checkResults := make(map[string]*v1.ResourceCheckResult, len(toCheck))
+	for start := 0; start < len(toCheck); start += int(maxDispatchChunkSize) {
+		end := start + int(maxDispatchChunkSize)
+		if end > len(toCheck) {
+			end = len(toCheck)
+		}
+
+		results, resultsMeta, err := computed.ComputeBulkCheck(crs.ctx, crs.checker,
+			computed.CheckParameters{
+				ResourceType:  crs.req.ObjectRelation,
+				Subject:       crs.req.Subject,
+				CaveatContext: crs.req.Context.AsMap(),
+				AtRevision:    crs.req.Revision,
+				MaximumDepth:  crs.req.Metadata.DepthRemaining,
+				DebugOption:   computed.NoDebugging,
+			},
+			toCheck[start:end],
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		crs.addSubproblemMetadata(resultsMeta)
+		for resourceID, result := range results {
+			checkResults[resourceID] = result
+		}
+	}
+
+	resolved := make([]*v1.ResolvedResource, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		if statuses[resourceID] == v1.ReachableResource_HAS_PERMISSION {
+			resolved = append(resolved, &v1.ResolvedResource{
+				ResourceId:     resourceID,
+				Permissionship: v1.ResolvedResource_HAS_PERMISSION,
+			})
+			continue
+		}
+
+		result, ok := checkResults[resourceID]
+		if !ok {
+			continue
+		}
+
+		switch result.Membership {
+		case v1.ResourceCheckResult_MEMBER:
+			resolved = append(resolved, &v1.ResolvedResource{
+				ResourceId:     resourceID,
+				Permissionship: v1.ResolvedResource_HAS_PERMISSION,
+			})
+
+		case v1.ResourceCheckResult_CAVEATED_MEMBER:
+			resolved = append(resolved, &v1.ResolvedResource{
+				ResourceId:             resourceID,
+				Permissionship:         v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION,
+				MissingRequiredContext: result.MissingExprFields,
+			})
+		}
 	}
+
+	return resolved, nil
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/util"
 )
@@ -187,20 +188,23 @@ func (cl *ConcurrentLookupSubjects) lookupViaComputed(
 	}, stream)
 }
 
-func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
+// loadTupleset loads the relationships of the tupleset relation for the resources, returning the
+// subjects to dispatch, keyed by their type rewritten to the computed userset relation, along with
+// the relationships keyed by their rewritten subject.
+func (cl *ConcurrentLookupSubjects) loadTupleset(
 	ctx context.Context,
 	parentRequest ValidatedLookupSubjectsRequest,
-	parentStream dispatch.LookupSubjectsStream,
-	ttu *core.TupleToUserset,
-) error {
+	tupleset *core.TupleToUserset_Tupleset,
+	computedUserset *core.ComputedUserset,
+) (*datasets.SubjectByTypeSet, *util.MultiMap[string, *core.RelationTuple], error) {
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(parentRequest.Revision)
 	it, err := ds.QueryRelationships(ctx, datastore.RelationshipsFilter{
 		ResourceType:             parentRequest.ResourceRelation.Namespace,
-		OptionalResourceRelation: ttu.Tupleset.Relation,
+		OptionalResourceRelation: tupleset.Relation,
 		OptionalResourceIds:      parentRequest.ResourceIds,
 	})
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer it.Close()
 
@@ -208,13 +212,13 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 	relationshipsBySubjectONR := util.NewMultiMap[string, *core.RelationTuple]()
 	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 		if it.Err() != nil {
-			return it.Err()
+			return nil, nil, it.Err()
 		}
 
 		// Add the subject to be dispatched.
 		err := toDispatchByTuplesetType.AddSubjectOf(tpl)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		// Add the *rewritten* subject to the relationships multimap for mapping back to the associated
@@ -222,14 +226,14 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 		relationshipsBySubjectONR.Add(tuple.StringONR(&core.ObjectAndRelation{
 			Namespace: tpl.Subject.Namespace,
 			ObjectId:  tpl.Subject.ObjectId,
-			Relation:  ttu.ComputedUserset.Relation,
+			Relation:  computedUserset.Relation,
 		}), tpl)
 	}
 	it.Close()
 
 	// Map the found subject types by the computed userset relation, so that we dispatch to it.
 	toDispatchByComputedRelationType, err := toDispatchByTuplesetType.Map(func(resourceType *core.RelationReference) (*core.RelationReference, error) {
-		if err := namespace.CheckNamespaceAndRelation(ctx, resourceType.Namespace, ttu.ComputedUserset.Relation, false, ds); err != nil {
+		if err := namespace.CheckNamespaceAndRelation(ctx, resourceType.Namespace, computedUserset.Relation, false, ds); err != nil {
 			if errors.As(err, &namespace.ErrRelationNotFound{}) {
 				return nil, nil
 			}
@@ -239,9 +243,24 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 
 		return &core.RelationReference{
 			Namespace: resourceType.Namespace,
-			Relation:  ttu.ComputedUserset.Relation,
+			Relation:  computedUserset.Relation,
 		}, nil
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return toDispatchByComputedRelationType, relationshipsBySubjectONR, nil
+}
+
+func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
+	ctx context.Context,
+	parentRequest ValidatedLookupSubjectsRequest,
+	parentStream dispatch.LookupSubjectsStream,
+	tupleset *core.TupleToUserset_Tupleset,
+	computedUserset *core.ComputedUserset,
+) error {
+	toDispatchByComputedRelationType, relationshipsBySubjectONR, err := cl.loadTupleset(ctx, parentRequest, tupleset, computedUserset)
 	if err != nil {
 		return err
 	}
@@ -249,6 +268,177 @@ func (cl *ConcurrentLookupSubjects) lookupViaTupleToUserset(
 	return cl.dispatchTo(ctx, parentRequest, toDispatchByComputedRelationType, relationshipsBySubjectONR, parentStream)
 }
 
+// lookupViaIntersectionTupleToUserset looks up the subjects of an `all` arrow, which are those
+// found for the subject of every relationship of the tupleset for a resource.
+func (cl *ConcurrentLookupSubjects) lookupViaIntersectionTupleToUserset(
+	ctx context.Context,
+	parentRequest ValidatedLookupSubjectsRequest,
+	parentStream dispatch.LookupSubjectsStream,
+	ttu *core.FunctionedTupleToUserset,
+) error {
+	toDispatchByComputedRelationType, relationshipsBySubjectONR, err := cl.loadTupleset(ctx, parentRequest, ttu.Tupleset, ttu.ComputedUserset)
+	if err != nil {
+		return err
+	}
+
+	subjectsBySubjectONR, metadata, err := cl.collectFromDispatches(ctx, parentRequest, toDispatchByComputedRelationType)
+	if err != nil {
+		return err
+	}
+
+	relationshipsByResourceID := util.NewMultiMap[string, *core.RelationTuple]()
+	for _, relationship := range relationshipsBySubjectONR.Values() {
+		relationshipsByResourceID.Add(relationship.ResourceAndRelation.ObjectId, relationship)
+	}
+
+	foundSubjectsByResourceID := make(map[string]*v1.FoundSubjects, relationshipsByResourceID.Len())
+	for _, resourceID := range relationshipsByResourceID.Keys() {
+		relationships, _ := relationshipsByResourceID.Get(resourceID)
+
+		var intersection datasets.SubjectSet
+		existing := datasets.NewSubjectSet()
+		allCaveated := true
+		for index, relationship := range relationships {
+			subjectSet := datasets.NewSubjectSet()
+			if found, ok := subjectsBySubjectONR[tuple.StringONR(&core.ObjectAndRelation{
+				Namespace: relationship.Subject.Namespace,
+				ObjectId:  relationship.Subject.ObjectId,
+				Relation:  ttu.ComputedUserset.Relation,
+			})]; ok {
+				if err := subjectSet.UnionWithSet(found); err != nil {
+					return fmt.Errorf("could not combine subject sets: %w", err)
+				}
+			}
+
+			if relationship.GetCaveat() == nil {
+				allCaveated = false
+			} else {
+				// A caveated relationship only requires its subject to have the computed relation
+				// if its caveat is satisfied, so every subject is found if it is not.
+				relationshipCaveat := wrapCaveat(relationship.Caveat)
+				if err := existing.UnionWithSet(subjectSet.WithParentCaveatExpression(relationshipCaveat)); err != nil {
+					return fmt.Errorf("could not combine subject sets: %w", err)
+				}
+
+				if err := subjectSet.Add(&v1.FoundSubject{
+					SubjectId:        tuple.PublicWildcard,
+					CaveatExpression: caveatInvert(relationshipCaveat),
+				}); err != nil {
+					return fmt.Errorf("could not add caveated wildcard: %w", err)
+				}
+			}
+
+			if index == 0 {
+				intersection = subjectSet
+				continue
+			}
+
+			if err := intersection.IntersectionDifference(subjectSet); err != nil {
+				return err
+			}
+		}
+
+		// If every relationship is caveated, each subject must also be found via at least one
+		// of them which exists.
+		if allCaveated {
+			if err := intersection.IntersectionDifference(existing); err != nil {
+				return err
+			}
+		}
+
+		if !intersection.IsEmpty() {
+			foundSubjectsByResourceID[resourceID] = intersection.AsFoundSubjects()
+		}
+	}
+
+	if len(foundSubjectsByResourceID) == 0 {
+		return nil
+	}
+
+	return parentStream.Publish(&v1.DispatchLookupSubjectsResponse{
+		FoundSubjectsByResourceId: foundSubjectsByResourceID,
+		Metadata:                  metadata,
+	})
+}
+
+// collectFromDispatches dispatches to the given subjects, returning the subjects found for each,
+// keyed by the string form of the subject.
+func (cl *ConcurrentLookupSubjects) collectFromDispatches(
+	ctx context.Context,
+	parentRequest ValidatedLookupSubjectsRequest,
+	toDispatchByType *datasets.SubjectByTypeSet,
+) (map[string]datasets.SubjectSet, *v1.ResponseMeta, error) {
+	subjectsBySubjectONR := map[string]datasets.SubjectSet{}
+	metadata := emptyMetadata
+	if toDispatchByType.IsEmpty() {
+		return subjectsBySubjectONR, metadata, nil
+	}
+
+	cancelCtx, checkCancel := context.WithCancel(ctx)
+	defer checkCancel()
+
+	g, subCtx := errgroup.WithContext(cancelCtx)
+	g.SetLimit(int(cl.concurrencyLimit))
+
+	var collectors []*dispatch.CollectingDispatchStream[*v1.DispatchLookupSubjectsResponse]
+	var collectorTypes []*core.RelationReference
+	toDispatchByType.ForEachType(func(resourceType *core.RelationReference, foundSubjects datasets.SubjectSet) {
+		slice := foundSubjects.AsSlice()
+		resourceIds := make([]string, 0, len(slice))
+		for _, foundSubject := range slice {
+			resourceIds = append(resourceIds, foundSubject.SubjectId)
+		}
+
+		util.ForEachChunk(resourceIds, maxDispatchChunkSize, func(resourceIdChunk []string) {
+			collector := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](subCtx)
+			collectors = append(collectors, collector)
+			collectorTypes = append(collectorTypes, resourceType)
+
+			g.Go(func() error {
+				return cl.d.DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{
+					ResourceRelation: resourceType,
+					ResourceIds:      resourceIdChunk,
+					SubjectRelation:  parentRequest.SubjectRelation,
+					Metadata: &v1.ResolverMeta{
+						AtRevision:     parentRequest.Revision.String(),
+						DepthRemaining: parentRequest.Metadata.DepthRemaining - 1,
+					},
+				}, collector)
+			})
+		})
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	for index, collector := range collectors {
+		resourceType := collectorTypes[index]
+		for _, result := range collector.Results() {
+			metadata = combineResponseMetadata(metadata, addCallToResponseMetadata(result.Metadata))
+			for childResourceID, foundSubjects := range result.FoundSubjectsByResourceId {
+				subjectKey := tuple.StringONR(&core.ObjectAndRelation{
+					Namespace: resourceType.Namespace,
+					ObjectId:  childResourceID,
+					Relation:  resourceType.Relation,
+				})
+
+				subjectSet, ok := subjectsBySubjectONR[subjectKey]
+				if !ok {
+					subjectSet = datasets.NewSubjectSet()
+					subjectsBySubjectONR[subjectKey] = subjectSet
+				}
+
+				if err := subjectSet.UnionWith(foundSubjects.FoundSubjects); err != nil {
+					return nil, nil, fmt.Errorf("could not combine subject sets: %w", err)
+				}
+			}
+		}
+	}
+
+	return subjectsBySubjectONR, metadata, nil
+}
+
 func (cl *ConcurrentLookupSubjects) lookupViaRewrite(
 	ctx context.Context,
 	req ValidatedLookupSubjectsRequest,
@@ -301,9 +491,25 @@ func (cl *ConcurrentLookupSubjects) lookupSetOperation(
 
 		case *core.SetOperation_Child_TupleToUserset:
 			g.Go(func() error {
-				return cl.lookupViaTupleToUserset(subCtx, req, stream, child.TupleToUserset)
+				return cl.lookupViaTupleToUserset(subCtx, req, stream, child.TupleToUserset.Tupleset, child.TupleToUserset.ComputedUserset)
 			})
 
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			switch child.FunctionedTupleToUserset.Function {
+			case core.FunctionedTupleToUserset_FUNCTION_ANY:
+				g.Go(func() error {
+					return cl.lookupViaTupleToUserset(subCtx, req, stream, child.FunctionedTupleToUserset.Tupleset, child.FunctionedTupleToUserset.ComputedUserset)
+				})
+
+			case core.FunctionedTupleToUserset_FUNCTION_ALL:
+				g.Go(func() error {
+					return cl.lookupViaIntersectionTupleToUserset(subCtx, req, stream, child.FunctionedTupleToUserset)
+				})
+
+			default:
+				return spiceerrors.MustBugf("unknown arrow function `%v` in lookup subjects", child.FunctionedTupleToUserset.Function)
+			}
+
 		case *core.SetOperation_Child_XNil:
 			// Purposely do nothing.
 			continue
@@ -7,10 +7,11 @@ import (
 )
 
 var (
-	caveatOr   = caveats.Or
-	caveatAnd  = caveats.And
-	caveatSub  = caveats.Subtract
-	wrapCaveat = caveats.CaveatAsExpr
+	caveatOr     = caveats.Or
+	caveatAnd    = caveats.And
+	caveatSub    = caveats.Subtract
+	caveatInvert = caveats.Invert
+	wrapCaveat   = caveats.CaveatAsExpr
 )
 
 // CheckResultsMap defines a type that is a map from resource ID to ResourceCheckResult.
@@ -3,6 +3,8 @@ package graph
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
@@ -34,20 +36,45 @@ type ValidatedReachableResourcesRequest struct {
 	Revision datastore.Revision
 }
 
+// ReachableResources finds the resources reachable from the subjects in the request, publishing
+// them to the stream.
+//
+// Results are published in a deterministic order, with each response carrying a cursor from
+// which the request can be resumed. The sections of the cursor are of the form:
+//
+//	[step, ...stepSections]
+//
+// where step 0 is the direct yielding of the subjects as resources (if applicable) and step N
+// (for N >= 1) is the (N-1)th entrypoint for the subject into the resource. For relation and
+// arrow entrypoints, the step sections are of the form:
+//
+//	[chunkIndex, relationshipBeforeChunk, ...childSections]
+//
+// while for computed userset entrypoints the step sections are those of the child dispatch.
 func (crr *ConcurrentReachableResources) ReachableResources(
 	req ValidatedReachableResourcesRequest,
 	stream dispatch.ReachableResourcesStream,
 ) error {
 	ctx := stream.Context()
-	dispatched := &syncONRSet{}
 
 	if len(req.SubjectIds) == 0 {
 		return fmt.Errorf("no subjects ids given to reachable resources dispatch")
 	}
 
+	sections, err := cursorSections(req.OptionalCursor)
+	if err != nil {
+		return err
+	}
+
+	startingStep, startingStepSections, err := intFromSections(sections)
+	if err != nil {
+		return err
+	}
+
 	// If the resource type matches the subject type, yield directly as a one-to-one result
 	// for each subjectID.
-	if req.SubjectRelation.Namespace == req.ResourceRelation.Namespace &&
+	if startingStep == 0 &&
+		req.SubjectRelation.Namespace == req.ResourceRelation.Namespace &&
 		req.SubjectRelation.Relation == req.ResourceRelation.Relation {
 		resources := make([]*v1.ReachableResource, 0, len(req.SubjectIds))
 		for _, subjectID := range req.SubjectIds {
@@ -59,8 +86,9 @@ func (crr *ConcurrentReachableResources) ReachableResources(
 		}
 
 		err := stream.Publish(&v1.DispatchReachableResourcesResponse{
-			Resources: resources,
-			Metadata:  emptyMetadata,
+			Resources:           resources,
+			Metadata:            emptyMetadata,
+			AfterResponseCursor: cursorFromSections("1"),
 		})
 		if err != nil {
 			return err
@@ -86,11 +114,27 @@ func (crr *ConcurrentReachableResources) ReachableResources(
 
 	t := NewTaskRunner(ctx, crr.concurrencyLimit)
 
+	// The results for each entrypoint are published in the order of the entrypoints, to ensure
+	// the cursors produced are stable.
+	publisher := newOrderedPublisher(ctx, stream.Publish, nil)
+
 	// For each entrypoint, load the necessary data and re-dispatch if a subproblem was found.
-	for _, entrypoint := range entrypoints {
+	for index, entrypoint := range entrypoints {
+		step := index + 1
+		if step < startingStep {
+			continue
+		}
+
+		var stepSections []string
+		if step == startingStep {
+			stepSections = startingStepSections
+		}
+
+		slot := publisher.NewSlot()
+
 		switch entrypoint.EntrypointKind() {
 		case core.ReachabilityEntrypoint_RELATION_ENTRYPOINT:
-			err := crr.lookupRelationEntrypoint(ctx, t, entrypoint, rg, reader, req, stream, dispatched)
+			err := crr.lookupRelationEntrypoint(ctx, t, step, stepSections, slot, entrypoint, rg, reader, req)
 			if err != nil {
 				return err
 			}
@@ -112,16 +156,20 @@ func (crr *ConcurrentReachableResources) ReachableResources(
 				drsm,
 				rg,
 				entrypoint,
-				stream,
+				slot,
 				req,
-				dispatched,
+				chunkCursors{
+					dispatchedPrefix: []string{strconv.Itoa(step)},
+					childCursor:      childCursorFromSections(stepSections),
+					afterChunk:       []string{strconv.Itoa(step + 1)},
+				},
 			)
 			if err != nil {
 				return err
 			}
 
 		case core.ReachabilityEntrypoint_TUPLESET_TO_USERSET_ENTRYPOINT:
-			err := crr.lookupTTUEntrypoint(ctx, t, entrypoint, rg, reader, req, stream, dispatched)
+			err := crr.lookupTTUEntrypoint(ctx, t, step, stepSections, slot, entrypoint, rg, reader, req)
 			if err != nil {
 				return err
 			}
@@ -131,18 +179,36 @@ func (crr *ConcurrentReachableResources) ReachableResources(
 		}
 	}
 
+	if err := publisher.Close(); err != nil {
+		return err
+	}
+
 	return t.Wait()
 }
 
+// chunkCursors holds the cursors associated with a chunk of found resources.
+type chunkCursors struct {
+	// dispatchedPrefix is the prefix of the cursor for any results found by dispatching
+	// over the chunk.
+	dispatchedPrefix []string
+
+	// childCursor is the cursor (if any) from which the dispatch over the chunk should resume.
+	childCursor *v1.Cursor
+
+	// afterChunk is the cursor pointing after the entire chunk.
+	afterChunk []string
+}
+
 func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 	ctx context.Context,
 	t *TaskRunner,
+	step int,
+	stepSections []string,
+	slot *orderedSlot[*v1.DispatchReachableResourcesResponse],
 	entrypoint namespace.ReachabilityEntrypoint,
 	rg *namespace.ReachabilityGraph,
 	reader datastore.Reader,
 	req ValidatedReachableResourcesRequest,
-	stream dispatch.ReachableResourcesStream,
-	dispatched *syncONRSet,
 ) error {
 	relationReference, err := entrypoint.DirectRelation()
 	if err != nil {
@@ -189,11 +255,10 @@ func (crr *ConcurrentReachableResources) lookupRelationEntrypoint(
 		},
 	}
 
-	crr.scheduleChunkedRedispatch(t, reader, subjectsFilter, relationReference,
-		func(ctx context.Context, drsm dispatchableResourcesSubjectMap) error {
-			return crr.redispatchOrReport(ctx, t, relationReference, drsm, rg, entrypoint, stream, req, dispatched)
+	return crr.scheduleChunkedRedispatch(t, reader, step, stepSections, slot, subjectsFilter, relationReference,
+		func(ctx context.Context, drsm dispatchableResourcesSubjectMap, chunkSlot *orderedSlot[*v1.DispatchReachableResourcesResponse], cursors chunkCursors) error {
+			return crr.redispatchOrReport(ctx, t, relationReference, drsm, rg, entrypoint, chunkSlot, req, cursors)
 		})
-	return nil
 }
 
 func min(a, b int) int {
@@ -203,15 +268,45 @@ func min(a, b int) int {
 	return a
 }
 
+// scheduleChunkedRedispatch schedules the loading of the relationships matching the subjects
+// filter, in resource order, and invokes the handler for each chunk of the found resources.
+// If the step sections are specified, the loading resumes from the chunk found in the sections.
 func (crr *ConcurrentReachableResources) scheduleChunkedRedispatch(
 	t *TaskRunner,
 	reader datastore.Reader,
+	step int,
+	stepSections []string,
+	slot *orderedSlot[*v1.DispatchReachableResourcesResponse],
 	subjectsFilter datastore.SubjectsFilter,
 	resourceType *core.RelationReference,
-	handler func(ctx context.Context, resources dispatchableResourcesSubjectMap) error,
-) {
+	handler func(ctx context.Context, resources dispatchableResourcesSubjectMap, chunkSlot *orderedSlot[*v1.DispatchReachableResourcesResponse], cursors chunkCursors) error,
+) error {
+	startingChunkIndex, remainingSections, err := intFromSections(stepSections)
+	if err != nil {
+		return err
+	}
+
+	var afterRelationship *core.RelationTuple
+	var startingChildCursor *v1.Cursor
+	if len(remainingSections) > 0 {
+		if remainingSections[0] != "" {
+			afterRelationship = tuple.Parse(remainingSections[0])
+			if afterRelationship == nil {
+				return NewInvalidCursorErr("invalid relationship section")
+			}
+		}
+
+		startingChildCursor = childCursorFromSections(remainingSections[1:])
+	}
+
+	type chunk struct {
+		rsm     resourcesSubjectMap
+		cursors chunkCursors
+	}
+
+	stepString := strconv.Itoa(step)
 	t.Schedule(func(ctx context.Context) error {
-		toBeHandled := make([]resourcesSubjectMap, 0)
+		toBeHandled := make([]chunk, 0)
 		it, err := reader.ReverseQueryRelationships(
 			ctx,
 			subjectsFilter,
@@ -219,14 +314,36 @@ func (crr *ConcurrentReachableResources) scheduleChunkedRedispatch(
 				Namespace: resourceType.Namespace,
 				Relation:  resourceType.Relation,
 			}),
+			options.WithSortForReverse(options.ByResource),
+			options.WithAfterForReverse(afterRelationship),
 		)
 		if err != nil {
 			return err
 		}
 		defer it.Close()
 
+		relationshipBeforeChunk := ""
+		if afterRelationship != nil {
+			relationshipBeforeChunk = tuple.StringWithoutCaveat(afterRelationship)
+		}
+
+		chunkIndex := startingChunkIndex
+		childCursor := startingChildCursor
+		addChunk := func(rsm resourcesSubjectMap, lastRelationship *core.RelationTuple) {
+			lastRelationshipString := tuple.StringWithoutCaveat(lastRelationship)
+			toBeHandled = append(toBeHandled, chunk{rsm, chunkCursors{
+				dispatchedPrefix: []string{stepString, strconv.Itoa(chunkIndex), relationshipBeforeChunk},
+				childCursor:      childCursor,
+				afterChunk:       []string{stepString, strconv.Itoa(chunkIndex + 1), lastRelationshipString},
+			}})
+
+			chunkIndex++
+			childCursor = nil
+			relationshipBeforeChunk = lastRelationshipString
+		}
+
 		rsm := newResourcesSubjectMap(resourceType)
-		chunkIndex := 0
+		var lastRelationship *core.RelationTuple
 		for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 			chunkSize := progressiveDispatchChunkSizes[min(chunkIndex, len(progressiveDispatchChunkSizes)-1)]
 			if it.Err() != nil {
@@ -237,13 +354,16 @@ func (crr *ConcurrentReachableResources) scheduleChunkedRedispatch(
 			if err != nil {
 				return err
 			}
+			lastRelationship = tpl
 
 			if rsm.len() == int(chunkSize) {
-				chunkIndex++
-				toBeHandled = append(toBeHandled, rsm)
+				addChunk(rsm, tpl)
 				rsm = newResourcesSubjectMap(resourceType)
 			}
 		}
+		if it.Err() != nil {
+			return it.Err()
+		}
 		it.Close()
 
 		if rsm.len() > 0 {
@@ -251,27 +371,32 @@ func (crr *ConcurrentReachableResources) scheduleChunkedRedispatch(
 				return fmt.Errorf("found reachableresources chunk in excess of expected max size")
 			}
 
-			toBeHandled = append(toBeHandled, rsm)
+			addChunk(rsm, lastRelationship)
 		}
 
-		for _, rsmToHandle := range toBeHandled {
-			err := handler(ctx, rsmToHandle.asReadOnly())
+		// Publish the results of each chunk in order, as a nested set of slots under the
+		// slot for the entrypoint.
+		chunkPublisher := nestedOrderedPublisher(slot)
+		for _, chunkToHandle := range toBeHandled {
+			err := handler(ctx, chunkToHandle.rsm.asReadOnly(), chunkPublisher.NewSlot(), chunkToHandle.cursors)
 			if err != nil {
 				return err
 			}
 		}
-		return nil
+		return chunkPublisher.Close()
 	})
+	return nil
 }
 
 func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context,
 	t *TaskRunner,
+	step int,
+	stepSections []string,
+	slot *orderedSlot[*v1.DispatchReachableResourcesResponse],
 	entrypoint namespace.ReachabilityEntrypoint,
 	rg *namespace.ReachabilityGraph,
 	reader datastore.Reader,
 	req ValidatedReachableResourcesRequest,
-	stream dispatch.ReachableResourcesStream,
-	dispatched *syncONRSet,
 ) error {
 	containingRelation := entrypoint.ContainingRelationOrPermission()
 
@@ -307,7 +432,7 @@ func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context
 	}
 
 	if relationFilter.IsEmpty() {
-		return nil
+		return slot.MarkDone()
 	}
 
 	// Search for the resolved subjects in the tupleset of the TTU.
@@ -322,16 +447,16 @@ func (crr *ConcurrentReachableResources) lookupTTUEntrypoint(ctx context.Context
 		Relation:  tuplesetRelation,
 	}
 
-	crr.scheduleChunkedRedispatch(t, reader, subjectsFilter, tuplesetRelationReference,
-		func(ctx context.Context, drsm dispatchableResourcesSubjectMap) error {
-			return crr.redispatchOrReport(ctx, t, containingRelation, drsm, rg, entrypoint, stream, req, dispatched)
+	return crr.scheduleChunkedRedispatch(t, reader, step, stepSections, slot, subjectsFilter, tuplesetRelationReference,
+		func(ctx context.Context, drsm dispatchableResourcesSubjectMap, chunkSlot *orderedSlot[*v1.DispatchReachableResourcesResponse], cursors chunkCursors) error {
+			return crr.redispatchOrReport(ctx, t, containingRelation, drsm, rg, entrypoint, chunkSlot, req, cursors)
 		})
-	return nil
 }
 
 // redispatchOrReport checks if further redispatching is necessary for the found resource
 // type. If not, and the found resource type+relation matches the target resource type+relation,
-// the resource is reported to the parent stream.
+// the resource is reported to the parent stream. The slot is marked done once all results for
+// the found resources have been published.
 func (crr *ConcurrentReachableResources) redispatchOrReport(
 	ctx context.Context,
 	t *TaskRunner,
@@ -339,13 +464,13 @@ func (crr *ConcurrentReachableResources) redispatchOrReport(
 	foundResources dispatchableResourcesSubjectMap,
 	rg *namespace.ReachabilityGraph,
 	entrypoint namespace.ReachabilityEntrypoint,
-	parentStream dispatch.ReachableResourcesStream,
+	slot *orderedSlot[*v1.DispatchReachableResourcesResponse],
 	parentRequest ValidatedReachableResourcesRequest,
-	dispatched *syncONRSet,
+	cursors chunkCursors,
 ) error {
 	if foundResources.isEmpty() {
 		// Nothing more to do.
-		return nil
+		return slot.MarkDone()
 	}
 
 	// Check for entrypoints for the new found resource type.
@@ -359,20 +484,24 @@ func (crr *ConcurrentReachableResources) redispatchOrReport(
 		// If the found resource matches the target resource type and relation, yield the resource.
 		if foundResourceType.Namespace == parentRequest.ResourceRelation.Namespace &&
 			foundResourceType.Relation == parentRequest.ResourceRelation.Relation {
-			return parentStream.Publish(&v1.DispatchReachableResourcesResponse{
-				Resources: foundResources.asReachableResources(entrypoint.IsDirectResult()),
-				Metadata:  emptyMetadata,
+			err := slot.Publish(&v1.DispatchReachableResourcesResponse{
+				Resources:           foundResources.asReachableResources(entrypoint.IsDirectResult()),
+				Metadata:            emptyMetadata,
+				AfterResponseCursor: cursorFromSections(cursors.afterChunk...),
 			})
+			if err != nil {
+				return err
+			}
 		}
 
 		// Otherwise, we're done.
-		return nil
+		return slot.MarkDone()
 	}
 
 	// Otherwise, redispatch.
 	t.Schedule(func(ctx context.Context) error {
 		stream := &dispatch.WrappedDispatchStream[*v1.DispatchReachableResourcesResponse]{
-			Stream: parentStream,
+			Stream: slot,
 			Ctx:    ctx,
 			Processor: func(result *v1.DispatchReachableResourcesResponse) (*v1.DispatchReachableResourcesResponse, bool, error) {
 				// If the context has been closed, nothing more to do.
@@ -391,8 +520,9 @@ func (crr *ConcurrentReachableResources) redispatchOrReport(
 				}
 
 				return &v1.DispatchReachableResourcesResponse{
-					Resources: mapped,
-					Metadata:  addCallToResponseMetadata(result.Metadata),
+					Resources:           mapped,
+					Metadata:            addCallToResponseMetadata(result.Metadata),
+					AfterResponseCursor: combineCursors(cursors.dispatchedPrefix, result.AfterResponseCursor),
 				}, true, nil
 			},
 		}
@@ -400,23 +530,26 @@ func (crr *ConcurrentReachableResources) redispatchOrReport(
 		// The new subject type for dispatching was the found type of the *resource*.
 		newSubjectType := foundResourceType
 
-		// To avoid duplicate work, remove any subjects already dispatched.
-		filteredSubjectIDs := foundResources.filterSubjectIDsToDispatch(dispatched, newSubjectType)
-		if len(filteredSubjectIDs) == 0 {
-			return nil
-		}
-
 		// Dispatch the found resources as the subjects for the next call, to continue the
-		// resolution.
-		return crr.d.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
+		// resolution. The subject IDs are sorted to ensure the dispatch is stable.
+		subjectIDs := foundResources.resourceIDs()
+		sort.Strings(subjectIDs)
+
+		err := crr.d.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
 			ResourceRelation: parentRequest.ResourceRelation,
 			SubjectRelation:  newSubjectType,
-			SubjectIds:       filteredSubjectIDs,
+			SubjectIds:       subjectIDs,
 			Metadata: &v1.ResolverMeta{
 				AtRevision:     parentRequest.Revision.String(),
 				DepthRemaining: parentRequest.Metadata.DepthRemaining - 1,
 			},
+			OptionalCursor: cursors.childCursor,
 		}, stream)
+		if err != nil {
+			return err
+		}
+
+		return slot.MarkDone()
 	})
 	return nil
 }
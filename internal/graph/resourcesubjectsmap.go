@@ -1,25 +1,12 @@
 package graph
 
 import (
-	"sync"
-
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
-	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/util"
 )
 
-type syncONRSet struct {
-	items sync.Map
-}
-
-func (s *syncONRSet) Add(onr *core.ObjectAndRelation) bool {
-	key := tuple.StringONR(onr)
-	_, existed := s.items.LoadOrStore(key, struct{}{})
-	return !existed
-}
-
 // resourcesSubjectMap is a multimap which tracks mappings from found resource IDs
 // to the subject IDs (may be more than one) for each, as well as whether the mapping
 // is conditional due to the use of a caveat on the relationship which formed the mapping.
@@ -93,24 +80,6 @@ func (rsm dispatchableResourcesSubjectMap) resourceIDs() []string {
 	return rsm.resourcesAndSubjects.Keys()
 }
 
-// filterSubjectIDsToDispatch returns the set of subject IDs that have not yet been
-// dispatched, by adding them to the dispatched set.
-func (rsm dispatchableResourcesSubjectMap) filterSubjectIDsToDispatch(dispatched *syncONRSet, dispatchSubjectType *core.RelationReference) []string {
-	resourceIDs := rsm.resourceIDs()
-	filtered := make([]string, 0, len(resourceIDs))
-	for _, resourceID := range resourceIDs {
-		if dispatched.Add(&core.ObjectAndRelation{
-			Namespace: dispatchSubjectType.Namespace,
-			ObjectId:  resourceID,
-			Relation:  dispatchSubjectType.Relation,
-		}) {
-			filtered = append(filtered, resourceID)
-		}
-	}
-
-	return filtered
-}
-
 // asReachableResources converts the resources found in the map into a slice of ReachableResource
 // messages, with isDirectEntrypoint and each subject's caveat indicating whether the resource
 // is directly found or requires an additional Check operation.
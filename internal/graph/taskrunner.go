@@ -64,6 +64,9 @@ func (tr *TaskRunner) spawnIfAvailable() {
 		go tr.runner()
 
 	case <-tr.ctx.Done():
+		// If the context was canceled, there may be no runner left to mark the
+		// remaining tasks as "Done", so do so here.
+		tr.emptyForCancel()
 		return
 
 	default:
@@ -87,6 +90,13 @@ func (tr *TaskRunner) runner() {
 				// it from the channel (freeing a slot potentially for another worker
 				// to be spawned later).
 				<-tr.sem
+
+				// A task may have been scheduled after the check above but before the
+				// token was returned, in which case no runner would have been spawned
+				// for it.
+				if tr.hasTasks() {
+					tr.spawnIfAvailable()
+				}
 				return
 			}
 
@@ -113,6 +123,12 @@ func (tr *TaskRunner) addTask(f TaskFunc) bool {
 	return true
 }
 
+func (tr *TaskRunner) hasTasks() bool {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	return len(tr.tasks) > 0
+}
+
 func (tr *TaskRunner) selectTask() TaskFunc {
 	tr.lock.Lock()
 	defer tr.lock.Unlock()
@@ -158,5 +174,8 @@ func (tr *TaskRunner) emptyForCancel() {
 // or the parent context to have been canceled.
 func (tr *TaskRunner) Wait() error {
 	tr.wg.Wait()
+
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
 	return tr.err
 }
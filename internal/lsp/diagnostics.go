@@ -0,0 +1,126 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+const diagnosticSource = "spicedb"
+
+// compile compiles the document, loading the files it imports via the given loader, if any.
+func (d *document) compile(loader compiler.FileLoader) (*compiler.CompiledSchema, error) {
+	source := input.Source(d.uri)
+	var opts []compiler.Option
+	if path, ok := d.filePath(); ok && loader != nil {
+		// Imports are resolved relative to the directory of the root schema, which is given a
+		// relative path so that it cannot itself be imported.
+		source = input.Source(filepath.Base(path))
+		opts = append(opts, compiler.WithFileLoader(loader))
+	}
+
+	emptyDefaultPrefix := ""
+	return compiler.Compile(compiler.InputSchema{
+		Source:       source,
+		SchemaString: d.text,
+	}, &emptyDefaultPrefix, opts...)
+}
+
+// diagnostics returns the errors and warnings found in the document: the compilation error or
+// type error, if any, or otherwise the warnings found by linting the schema. Errors and warnings
+// found in imported files are reported at the start of the document.
+func (d *document) diagnostics(ctx context.Context, loader compiler.FileLoader) []Diagnostic {
+	compiled, err := d.compile(loader)
+	if err != nil {
+		return []Diagnostic{d.errorDiagnostic(err)}
+	}
+
+	validated, err := shared.ValidateSchemaChanges(ctx, compiled, false)
+	if err != nil {
+		return []Diagnostic{d.errorDiagnostic(err)}
+	}
+
+	warnings, err := validated.Lint()
+	if err != nil {
+		return []Diagnostic{d.errorDiagnostic(err)}
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(warnings))
+	for _, warning := range warnings {
+		if warning.SourcePosition == nil || warning.SourcePosition.SourceFile != "" {
+			continue
+		}
+
+		line := int(warning.SourcePosition.ZeroIndexedLineNumber)
+		col := int(warning.SourcePosition.ZeroIndexedColumnPosition)
+		warningRange, ok := d.findOnLine(line, col, warning.SourceCodeString)
+		if !ok {
+			warningRange = d.rangeForOffsets(0, 0)
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    warningRange,
+			Severity: SeverityWarning,
+			Source:   diagnosticSource,
+			Message:  warning.Message,
+		})
+	}
+	return diagnostics
+}
+
+func (d *document) errorDiagnostic(err error) Diagnostic {
+	diagnostic := Diagnostic{
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  err.Error(),
+	}
+
+	var contextError compiler.ErrorWithContext
+	if errors.As(err, &contextError) {
+		diagnostic.Message = contextError.BaseMessage
+		if !d.isRootSource(contextError.Source) {
+			diagnostic.Message = fmt.Sprintf("in imported file `%s`: %s", contextError.Source, contextError.BaseMessage)
+			return diagnostic
+		}
+
+		start, serr := contextError.SourceRange.Start().RunePosition()
+		end, eerr := contextError.SourceRange.End().RunePosition()
+		if serr == nil && eerr == nil {
+			diagnostic.Range = d.rangeForOffsets(start, end+1)
+		}
+		return diagnostic
+	}
+
+	// Errors with a source hold 1-indexed positions; the source code is checked to be found at the
+	// position, as errors found in imported files hold positions within those files.
+	if errWithSource, ok := spiceerrors.AsErrorWithSource(err); ok && errWithSource.LineNumber > 0 {
+		line := int(errWithSource.LineNumber) - 1
+		col := int(errWithSource.ColumnPosition) - 1
+		if errorRange, ok := d.findOnLine(line, col, errWithSource.SourceCodeString); ok {
+			diagnostic.Range = errorRange
+		}
+	}
+	return diagnostic
+}
+
+// isRootSource returns true if the given source is that used when compiling the document.
+func (d *document) isRootSource(source input.Source) bool {
+	if path, ok := d.filePath(); ok && source == input.Source(filepath.Base(path)) {
+		return true
+	}
+	return source == input.Source(d.uri)
+}
+
+// formatted returns the document formatted, preserving its comments and layout.
+func (d *document) formatted() (string, error) {
+	return compiler.FormatSchema(compiler.InputSchema{
+		Source:       input.Source(d.uri),
+		SchemaString: d.text,
+	})
+}
@@ -0,0 +1,296 @@
+package lsp
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemadsl/parser"
+)
+
+// document is a schema document opened in the client, along with its parse tree.
+type document struct {
+	uri    string
+	text   string
+	lines  []string
+	mapper input.SourcePositionMapper
+	root   *node
+}
+
+func newDocument(uri string, text string) *document {
+	return &document{
+		uri:    uri,
+		text:   text,
+		lines:  strings.Split(text, "\n"),
+		mapper: input.CreateSourcePositionMapper([]byte(text)),
+		root:   parser.Parse(createNode, input.Source(uri), text).(*node),
+	}
+}
+
+// filePath returns the path of the document on the local file system, if it is a file.
+func (d *document) filePath() (string, bool) {
+	return filePathForURI(d.uri)
+}
+
+func filePathForURI(uri string) (string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return "", false
+	}
+	return parsed.Path, true
+}
+
+// positionForOffset converts a byte offset in the document into an LSP position, whose
+// character is counted in UTF-16 code units.
+func (d *document) positionForOffset(offset int) Position {
+	line, col, err := d.mapper.RunePositionToLineAndCol(offset)
+	if err != nil || line >= len(d.lines) {
+		return Position{}
+	}
+
+	lineText := d.lines[line]
+	if col > len(lineText) {
+		col = len(lineText)
+	}
+	return Position{Line: line, Character: utf16Length(lineText[:col])}
+}
+
+// offsetForPosition converts an LSP position into a byte offset in the document.
+func (d *document) offsetForPosition(pos Position) (int, bool) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return 0, false
+	}
+
+	lineText := d.lines[pos.Line]
+	col, units := 0, 0
+	for col < len(lineText) && units < pos.Character {
+		r, width := utf8.DecodeRuneInString(lineText[col:])
+		col += width
+		units += utf16RuneLength(r)
+	}
+
+	offset, err := d.mapper.LineAndColToRunePosition(pos.Line, col)
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
+
+// rangeForOffsets returns the range between the given byte offsets, with an exclusive end.
+func (d *document) rangeForOffsets(start int, end int) Range {
+	return Range{Start: d.positionForOffset(start), End: d.positionForOffset(end)}
+}
+
+// rangeForNode returns the range covered by the node.
+func (d *document) rangeForNode(n *node) Range {
+	start, end, ok := n.span()
+	if !ok {
+		return Range{}
+	}
+	return d.rangeForOffsets(start, end+1)
+}
+
+// fullRange returns the range covering the whole document.
+func (d *document) fullRange() Range {
+	return d.rangeForOffsets(0, len(d.text))
+}
+
+// textOf returns the source text of the node.
+func (d *document) textOf(n *node) string {
+	start, end, ok := n.span()
+	if !ok || end >= len(d.text) || start > end {
+		return ""
+	}
+	return d.text[start : end+1]
+}
+
+// nameRange returns the range of the name of a declaration, such as the name of a definition
+// or relation, falling back to the range of the whole declaration.
+func (d *document) nameRange(n *node) Range {
+	start, _, ok := n.span()
+	if !ok {
+		return Range{}
+	}
+
+	name := n.declarationName()
+	text := d.textOf(n)
+	keywordEnd := strings.IndexAny(text, " \t\r\n")
+	if name == "" || keywordEnd < 0 {
+		return d.rangeForNode(n)
+	}
+
+	nameStart := keywordEnd + len(text[keywordEnd:]) - len(strings.TrimLeft(text[keywordEnd:], " \t\r\n"))
+	if !strings.HasPrefix(text[nameStart:], name) {
+		return d.rangeForNode(n)
+	}
+	return d.rangeForOffsets(start+nameStart, start+nameStart+len(name))
+}
+
+// findOnLine returns the range of the given source code on the given 0-indexed line, at or after
+// the given 0-indexed byte column, if found.
+func (d *document) findOnLine(line int, col int, sourceCode string) (Range, bool) {
+	if line < 0 || line >= len(d.lines) || col < 0 || col > len(d.lines[line]) || sourceCode == "" {
+		return Range{}, false
+	}
+
+	index := strings.Index(d.lines[line][col:], sourceCode)
+	if index < 0 {
+		return Range{}, false
+	}
+
+	lineStart, err := d.mapper.LineAndColToRunePosition(line, 0)
+	if err != nil {
+		return Range{}, false
+	}
+
+	start := lineStart + col + index
+	return d.rangeForOffsets(start, start+len(sourceCode)), true
+}
+
+// nodeAt returns the innermost node containing the given byte offset, if any.
+func (d *document) nodeAt(offset int) *node {
+	var found *node
+	current := d.root
+	for current != nil {
+		found = current
+
+		var next *node
+		for _, child := range current.allChildren() {
+			if child.contains(offset) {
+				next = child
+				break
+			}
+		}
+		current = next
+	}
+	return found
+}
+
+// topLevel returns the top-level definition, caveat or partial with the given name, if any.
+func (d *document) topLevel(nodeType dslshape.NodeType, name string) *node {
+	for _, child := range d.root.list(dslshape.NodePredicateChild) {
+		if child.nodeType == nodeType && child.declarationName() == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// topLevelOfTypes returns the top-level nodes of the given types, in source order.
+func (d *document) topLevelOfTypes(nodeTypes ...dslshape.NodeType) []*node {
+	var found []*node
+	for _, child := range d.root.list(dslshape.NodePredicateChild) {
+		for _, nodeType := range nodeTypes {
+			if child.nodeType == nodeType {
+				found = append(found, child)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// definitionAt returns the definition or partial containing the given byte offset. As the
+// document may be incomplete while being edited, a definition which is missing its end is
+// considered to contain every offset after its start.
+func (d *document) definitionAt(offset int) *node {
+	var found *node
+	for _, def := range d.topLevelOfTypes(dslshape.NodeTypeDefinition, dslshape.NodeTypePartial) {
+		start, ok := def.position(dslshape.NodePredicateStartRune)
+		if !ok || start > offset {
+			continue
+		}
+
+		if def.contains(offset) {
+			return def
+		}
+
+		if _, hasEnd := def.position(dslshape.NodePredicateEndRune); !hasEnd {
+			found = def
+		}
+	}
+	return found
+}
+
+// relationsOf returns the relations and permissions of the definition or partial, including
+// those spliced in from the partials it references.
+func (d *document) relationsOf(def *node) []*node {
+	return d.collectRelations(def, map[string]struct{}{})
+}
+
+func (d *document) collectRelations(def *node, visited map[string]struct{}) []*node {
+	if def == nil {
+		return nil
+	}
+
+	var relations []*node
+	for _, child := range def.list(dslshape.NodePredicateChild) {
+		switch child.nodeType {
+		case dslshape.NodeTypeRelation, dslshape.NodeTypePermission:
+			relations = append(relations, child)
+
+		case dslshape.NodeTypePartialReference:
+			partialName := child.get(dslshape.NodePartialReferencePredicateName)
+			if _, ok := visited[partialName]; ok {
+				continue
+			}
+			visited[partialName] = struct{}{}
+			relations = append(relations, d.collectRelations(d.topLevel(dslshape.NodeTypePartial, partialName), visited)...)
+		}
+	}
+	return relations
+}
+
+// relationNamed returns the relation or permission of the definition with the given name, if any.
+func (d *document) relationNamed(def *node, name string) *node {
+	for _, relation := range d.relationsOf(def) {
+		if relation.declarationName() == name {
+			return relation
+		}
+	}
+	return nil
+}
+
+// subjectTypesOf returns the names of the subject types allowed on the relation, in source order.
+func subjectTypesOf(relation *node) []string {
+	var typeNames []string
+	seen := map[string]struct{}{}
+	for _, specificType := range specificTypesOf(relation) {
+		typeName := specificType.get(dslshape.NodeSpecificReferencePredicateType)
+		if _, ok := seen[typeName]; ok {
+			continue
+		}
+		seen[typeName] = struct{}{}
+		typeNames = append(typeNames, typeName)
+	}
+	return typeNames
+}
+
+func specificTypesOf(relation *node) []*node {
+	if relation == nil {
+		return nil
+	}
+
+	var specificTypes []*node
+	for _, typeRef := range relation.list(dslshape.NodeRelationPredicateAllowedTypes) {
+		specificTypes = append(specificTypes, typeRef.list(dslshape.NodeTypeReferencePredicateType)...)
+	}
+	return specificTypes
+}
+
+func utf16Length(s string) int {
+	length := 0
+	for _, r := range s {
+		length += utf16RuneLength(r)
+	}
+	return length
+}
+
+func utf16RuneLength(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
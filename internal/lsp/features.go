@@ -0,0 +1,286 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
+)
+
+// resolve returns the declarations referenced at the given byte offset. If the offset is on a
+// declaration itself, the declaration is returned. Arrows may reference a relation on more than
+// one subject type, in which case each is returned.
+func (d *document) resolve(offset int) []*node {
+	n := d.nodeAt(offset)
+	if n == nil {
+		return nil
+	}
+
+	switch n.nodeType {
+	case dslshape.NodeTypeDefinition, dslshape.NodeTypeCaveatDefinition, dslshape.NodeTypePartial,
+		dslshape.NodeTypeRelation, dslshape.NodeTypePermission, dslshape.NodeTypeCaveatParameter:
+		return []*node{n}
+
+	case dslshape.NodeTypeSpecificTypeReference:
+		typeName := n.get(dslshape.NodeSpecificReferencePredicateType)
+		def := d.topLevel(dslshape.NodeTypeDefinition, typeName)
+
+		relationName := n.get(dslshape.NodeSpecificReferencePredicateRelation)
+		start, _, _ := n.span()
+		hashIndex := strings.Index(d.textOf(n), "#")
+		if relationName != "" && hashIndex >= 0 && offset > start+hashIndex {
+			return nonNil(d.relationNamed(def, relationName))
+		}
+		return nonNil(def)
+
+	case dslshape.NodeTypeCaveatReference:
+		return nonNil(d.topLevel(dslshape.NodeTypeCaveatDefinition, n.get(dslshape.NodeCaveatPredicateCaveat)))
+
+	case dslshape.NodeTypePartialReference:
+		return nonNil(d.topLevel(dslshape.NodeTypePartial, n.get(dslshape.NodePartialReferencePredicateName)))
+
+	case dslshape.NodeTypeIdentifier:
+		def := n.ancestor(dslshape.NodeTypeDefinition, dslshape.NodeTypePartial)
+		identifier := n.get(dslshape.NodeIdentiferPredicateValue)
+
+		arrow := n.parent
+		if arrow != nil && arrow.nodeType == dslshape.NodeTypeArrowExpression && arrow.only(dslshape.NodeExpressionPredicateRightExpr) == n {
+			tupleset := arrow.only(dslshape.NodeExpressionPredicateLeftExpr)
+			if tupleset == nil || tupleset.nodeType != dslshape.NodeTypeIdentifier {
+				return nil
+			}
+			return d.arrowTargets(def, tupleset.get(dslshape.NodeIdentiferPredicateValue), identifier)
+		}
+
+		return nonNil(d.relationNamed(def, identifier))
+
+	default:
+		return nil
+	}
+}
+
+// arrowTargets returns the relations or permissions with the given name on each subject type
+// of the tupleset relation of an arrow.
+func (d *document) arrowTargets(def *node, tuplesetRelation string, computedRelation string) []*node {
+	var targets []*node
+	for _, typeName := range subjectTypesOf(d.relationNamed(def, tuplesetRelation)) {
+		if target := d.relationNamed(d.topLevel(dslshape.NodeTypeDefinition, typeName), computedRelation); target != nil {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func nonNil(n *node) []*node {
+	if n == nil {
+		return nil
+	}
+	return []*node{n}
+}
+
+// definitionLocations returns the locations of the declarations referenced at the position.
+func (d *document) definitionLocations(pos Position) []Location {
+	offset, ok := d.offsetForPosition(pos)
+	if !ok {
+		return nil
+	}
+
+	var locations []Location
+	for _, target := range d.resolve(offset) {
+		locations = append(locations, Location{URI: d.uri, Range: d.nameRange(target)})
+	}
+	return locations
+}
+
+// hover returns a description of the declarations referenced at the position, if any.
+func (d *document) hover(pos Position) *Hover {
+	offset, ok := d.offsetForPosition(pos)
+	if !ok {
+		return nil
+	}
+
+	targets := d.resolve(offset)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(targets))
+	for _, target := range targets {
+		description := d.describe(target)
+		if def := target.ancestor(dslshape.NodeTypeDefinition, dslshape.NodeTypePartial); def != nil && len(targets) > 1 {
+			description = def.declarationName() + ": " + description
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	hoverRange := d.rangeForNode(d.nodeAt(offset))
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: "```\n" + strings.Join(descriptions, "\n") + "\n```",
+		},
+		Range: &hoverRange,
+	}
+}
+
+// describe returns a single line describing the declaration, such as the allowed types of a
+// relation or the parameter types of a caveat.
+func (d *document) describe(n *node) string {
+	name := n.declarationName()
+	switch n.nodeType {
+	case dslshape.NodeTypeDefinition:
+		return "definition " + name
+
+	case dslshape.NodeTypePartial:
+		return "partial " + name
+
+	case dslshape.NodeTypeCaveatDefinition:
+		parameters := make([]string, 0, len(n.list(dslshape.NodeCaveatDefinitionPredicateParameters)))
+		for _, parameter := range n.list(dslshape.NodeCaveatDefinitionPredicateParameters) {
+			parameters = append(parameters, describeCaveatParameter(parameter))
+		}
+		return fmt.Sprintf("caveat %s(%s)", name, strings.Join(parameters, ", "))
+
+	case dslshape.NodeTypeCaveatParameter:
+		return describeCaveatParameter(n)
+
+	case dslshape.NodeTypeRelation:
+		allowedTypes := make([]string, 0, len(specificTypesOf(n)))
+		for _, specificType := range specificTypesOf(n) {
+			allowedTypes = append(allowedTypes, describeAllowedType(specificType))
+		}
+		return fmt.Sprintf("relation %s: %s", name, strings.Join(allowedTypes, " | "))
+
+	case dslshape.NodeTypePermission:
+		expression := n.only(dslshape.NodePermissionPredicateComputeExpression)
+		if expression == nil {
+			return "permission " + name
+		}
+		return fmt.Sprintf("permission %s = %s", name, d.textOf(expression))
+
+	default:
+		return name
+	}
+}
+
+func describeAllowedType(specificType *node) string {
+	described := specificType.get(dslshape.NodeSpecificReferencePredicateType)
+	if relationName := specificType.get(dslshape.NodeSpecificReferencePredicateRelation); relationName != "" {
+		described += "#" + relationName
+	}
+	if specificType.get(dslshape.NodeSpecificReferencePredicateWildcard) != "" {
+		described += ":*"
+	}
+	if caveat := specificType.only(dslshape.NodeSpecificReferencePredicateCaveat); caveat != nil {
+		described += " with " + caveat.get(dslshape.NodeCaveatPredicateCaveat)
+	}
+	return described
+}
+
+func describeCaveatParameter(parameter *node) string {
+	return parameter.declarationName() + " " + describeCaveatType(parameter.only(dslshape.NodeCaveatParameterPredicateType))
+}
+
+func describeCaveatType(typeRef *node) string {
+	if typeRef == nil {
+		return ""
+	}
+
+	described := typeRef.get(dslshape.NodeCaveatTypeReferencePredicateType)
+	childTypes := typeRef.list(dslshape.NodeCaveatTypeReferencePredicateChildTypes)
+	if len(childTypes) == 0 {
+		return described
+	}
+
+	children := make([]string, 0, len(childTypes))
+	for _, childType := range childTypes {
+		children = append(children, describeCaveatType(childType))
+	}
+	return described + "<" + strings.Join(children, ", ") + ">"
+}
+
+var (
+	subjectRelationPrefix = regexp.MustCompile(`([a-z][a-z0-9_/]*)#[a-z0-9_]*$`)
+	arrowPrefix           = regexp.MustCompile(`([a-z][a-z0-9_]*)(->|\.any\(|\.all\()[a-z0-9_]*$`)
+	caveatPrefix          = regexp.MustCompile(`\swith\s+[a-z0-9_/]*$`)
+	allowedTypesPrefix    = regexp.MustCompile(`^\s*relation\s+[a-z0-9_]+\s*:[^=]*$`)
+	expressionPrefix      = regexp.MustCompile(`^\s*permission\s+[a-z0-9_]+\s*=`)
+	partialPrefix         = regexp.MustCompile(`^\s*\.\.\.[a-z0-9_]*$`)
+)
+
+// completions returns the names which may be completed at the position, based on the text on
+// the line before it: definitions in allowed types, relations after `#` or an arrow, caveats
+// after `with`, and the relations and permissions of the enclosing definition in permission
+// expressions.
+func (d *document) completions(pos Position) []CompletionItem {
+	offset, ok := d.offsetForPosition(pos)
+	if !ok {
+		return nil
+	}
+
+	lineStart, err := d.mapper.LineAndColToRunePosition(pos.Line, 0)
+	if err != nil || lineStart > offset {
+		return nil
+	}
+	linePrefix := d.text[lineStart:offset]
+	enclosing := d.definitionAt(offset)
+
+	switch {
+	case caveatPrefix.MatchString(linePrefix):
+		return topLevelCompletions(d.topLevelOfTypes(dslshape.NodeTypeCaveatDefinition), CompletionKindFunction, "caveat")
+
+	case subjectRelationPrefix.MatchString(linePrefix):
+		typeName := subjectRelationPrefix.FindStringSubmatch(linePrefix)[1]
+		return relationCompletions(d.relationsOf(d.topLevel(dslshape.NodeTypeDefinition, typeName)))
+
+	case allowedTypesPrefix.MatchString(linePrefix):
+		return topLevelCompletions(d.topLevelOfTypes(dslshape.NodeTypeDefinition), CompletionKindClass, "definition")
+
+	case partialPrefix.MatchString(linePrefix):
+		return topLevelCompletions(d.topLevelOfTypes(dslshape.NodeTypePartial), CompletionKindClass, "partial")
+
+	case arrowPrefix.MatchString(linePrefix):
+		tuplesetRelation := arrowPrefix.FindStringSubmatch(linePrefix)[1]
+
+		var relations []*node
+		for _, typeName := range subjectTypesOf(d.relationNamed(enclosing, tuplesetRelation)) {
+			relations = append(relations, d.relationsOf(d.topLevel(dslshape.NodeTypeDefinition, typeName))...)
+		}
+		return relationCompletions(relations)
+
+	case expressionPrefix.MatchString(linePrefix):
+		return relationCompletions(d.relationsOf(enclosing))
+
+	default:
+		return nil
+	}
+}
+
+func topLevelCompletions(nodes []*node, kind CompletionItemKind, detail string) []CompletionItem {
+	items := make([]CompletionItem, 0, len(nodes))
+	for _, n := range nodes {
+		items = append(items, CompletionItem{Label: n.declarationName(), Kind: kind, Detail: detail})
+	}
+	return items
+}
+
+// relationCompletions returns a completion for each distinct relation or permission name.
+func relationCompletions(relations []*node) []CompletionItem {
+	items := make([]CompletionItem, 0, len(relations))
+	seen := map[string]struct{}{}
+	for _, relation := range relations {
+		name := relation.declarationName()
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		if relation.nodeType == dslshape.NodeTypePermission {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindProperty, Detail: "permission"})
+		} else {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindField, Detail: "relation"})
+		}
+	}
+	return items
+}
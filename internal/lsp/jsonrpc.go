@@ -0,0 +1,133 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+
+	// codeRequestFailed is the LSP error code for a request which was valid but failed.
+	codeRequestFailed = -32803
+)
+
+// message is a JSON-RPC 2.0 request, notification or response. Notifications have no ID.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+func (m *message) isNotification() bool {
+	return len(m.ID) == 0
+}
+
+// responseError is the error returned in a JSON-RPC response.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (re *responseError) Error() string {
+	return re.Message
+}
+
+func newResponseError(code int, format string, args ...interface{}) *responseError {
+	return &responseError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// conn reads and writes JSON-RPC messages framed by LSP base protocol headers.
+type conn struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{reader: bufio.NewReader(r), writer: w}
+}
+
+// read reads the next message, returning io.EOF once the input is closed.
+func (c *conn) read() (*message, error) {
+	headers, err := textproto.NewReader(c.reader).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(headers) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("could not read message headers: %w", err)
+	}
+
+	contentLength, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || contentLength < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header `%s`", headers.Get("Content-Length"))
+	}
+
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return nil, fmt.Errorf("could not read message body: %w", err)
+	}
+
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, newResponseError(codeParseError, "could not parse message: %s", err)
+	}
+	return msg, nil
+}
+
+// write writes the given message, prefixed by its headers.
+func (c *conn) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(body)
+	return err
+}
+
+// reply writes the response to the request with the given ID. The result is always written
+// for a successful response, even if it is null.
+func (c *conn) reply(id json.RawMessage, result interface{}, rerr *responseError) error {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	if rerr != nil {
+		return c.write(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Error   *responseError  `json:"error"`
+		}{"2.0", id, rerr})
+	}
+
+	return c.write(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result"`
+	}{"2.0", id, result})
+}
+
+// notify writes a notification with the given method and parameters.
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(struct {
+		JSONRPC string      `json:"jsonrpc"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params"`
+	}{"2.0", method, params})
+}
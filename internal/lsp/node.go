@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemadsl/parser"
+)
+
+// node is a node in the parse tree of a document.
+type node struct {
+	nodeType   dslshape.NodeType
+	properties map[string]string
+	positions  map[string]int
+	children   map[string][]*node
+	parent     *node
+}
+
+func createNode(_ input.Source, kind dslshape.NodeType) parser.AstNode {
+	return &node{
+		nodeType:   kind,
+		properties: map[string]string{},
+		positions:  map[string]int{},
+		children:   map[string][]*node{},
+	}
+}
+
+func (n *node) Connect(predicate string, other parser.AstNode) {
+	child := other.(*node)
+	child.parent = n
+	n.children[predicate] = append(n.children[predicate], child)
+}
+
+func (n *node) MustDecorate(property string, value string) parser.AstNode {
+	if _, ok := n.properties[property]; ok {
+		panic(fmt.Sprintf("Existing key for property %s\n\tNode: %v", property, n.properties))
+	}
+
+	n.properties[property] = value
+	return n
+}
+
+func (n *node) MustDecorateWithInt(property string, value int) parser.AstNode {
+	if _, ok := n.positions[property]; ok {
+		panic(fmt.Sprintf("Existing key for property %s\n\tNode: %v", property, n.positions))
+	}
+
+	n.positions[property] = value
+	return n
+}
+
+func (n *node) get(property string) string {
+	return n.properties[property]
+}
+
+func (n *node) position(property string) (int, bool) {
+	position, ok := n.positions[property]
+	return position, ok
+}
+
+// span returns the byte offsets of the first and last characters of the node.
+func (n *node) span() (int, int, bool) {
+	start, hasStart := n.position(dslshape.NodePredicateStartRune)
+	end, hasEnd := n.position(dslshape.NodePredicateEndRune)
+	return start, end, hasStart && hasEnd
+}
+
+func (n *node) contains(offset int) bool {
+	start, end, ok := n.span()
+	return ok && start <= offset && offset <= end
+}
+
+func (n *node) list(predicate string) []*node {
+	return n.children[predicate]
+}
+
+// only returns the single child node under the predicate, if any.
+func (n *node) only(predicate string) *node {
+	children := n.children[predicate]
+	if len(children) == 0 {
+		return nil
+	}
+	return children[0]
+}
+
+func (n *node) allChildren() []*node {
+	var children []*node
+	for _, predicateChildren := range n.children {
+		children = append(children, predicateChildren...)
+	}
+	return children
+}
+
+// ancestor returns the nearest ancestor of the node of one of the given types, if any.
+func (n *node) ancestor(nodeTypes ...dslshape.NodeType) *node {
+	for current := n.parent; current != nil; current = current.parent {
+		for _, nodeType := range nodeTypes {
+			if current.nodeType == nodeType {
+				return current
+			}
+		}
+	}
+	return nil
+}
+
+// declarationName returns the name declared by a definition, caveat, partial, relation,
+// permission or caveat parameter node.
+func (n *node) declarationName() string {
+	switch n.nodeType {
+	case dslshape.NodeTypeDefinition:
+		return n.get(dslshape.NodeDefinitionPredicateName)
+	case dslshape.NodeTypeCaveatDefinition:
+		return n.get(dslshape.NodeCaveatDefinitionPredicateName)
+	case dslshape.NodeTypePartial:
+		return n.get(dslshape.NodePartialPredicateName)
+	case dslshape.NodeTypeRelation, dslshape.NodeTypePermission:
+		return n.get(dslshape.NodePredicateName)
+	case dslshape.NodeTypeCaveatParameter:
+		return n.get(dslshape.NodeCaveatParameterPredicateName)
+	default:
+		return ""
+	}
+}
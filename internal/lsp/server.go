@@ -0,0 +1,247 @@
+// Package lsp implements a Language Server Protocol server for the schema DSL.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+)
+
+// Server is a language server for schema files, serving a single client.
+type Server struct {
+	version   string
+	documents map[string]*document
+	conn      *conn
+
+	shutdownRequested bool
+}
+
+// NewServer creates a new language server, which reports the given version to clients.
+func NewServer(version string) *Server {
+	return &Server{
+		version:   version,
+		documents: map[string]*document{},
+	}
+}
+
+// Run serves the client whose messages are read from r, writing messages for the client to w,
+// until the client sends the exit notification or r is closed. An error is returned if the
+// client exits without first requesting a shutdown.
+func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var rerr *responseError
+		if errors.As(err, &rerr) {
+			if err := s.conn.reply(nil, nil, rerr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdownRequested {
+				return errors.New("language server exited without a shutdown request")
+			}
+			return nil
+		}
+
+		if err := s.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a single request or notification. The returned error is only for failures to
+// write to the client; errors in requests are returned to the client in the response.
+func (s *Server) handle(ctx context.Context, msg *message) error {
+	if msg.Method == "" {
+		// Responses to requests made by the server are ignored, as it makes none.
+		return nil
+	}
+
+	result, rerr := s.dispatch(ctx, msg)
+	if msg.isNotification() {
+		if rerr != nil {
+			log.Ctx(ctx).Warn().Str("method", msg.Method).Str("error", rerr.Message).Msg("failed to handle language server notification")
+		}
+		return nil
+	}
+
+	return s.conn.reply(msg.ID, result, rerr)
+}
+
+func (s *Server) dispatch(ctx context.Context, msg *message) (interface{}, *responseError) {
+	if s.shutdownRequested && msg.Method != "shutdown" {
+		return nil, newResponseError(codeInvalidRequest, "language server is shutting down")
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:           TextDocumentSyncFull,
+				DefinitionProvider:         true,
+				HoverProvider:              true,
+				CompletionProvider:         &CompletionOptions{TriggerCharacters: []string{"#", ">", "(", ":", "|"}},
+				DocumentFormattingProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "spicedb", Version: s.version},
+		}, nil
+
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration", "textDocument/didSave":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdownRequested = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if rerr := unmarshalParams(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		return nil, s.updateDocument(ctx, params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if rerr := unmarshalParams(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.updateDocument(ctx, params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if rerr := unmarshalParams(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		delete(s.documents, params.TextDocument.URI)
+		if err := s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{}); err != nil {
+			return nil, newResponseError(codeInternalError, "could not publish diagnostics: %s", err)
+		}
+		return nil, nil
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		doc, rerr := s.documentForParams(msg, &params, &params.TextDocument)
+		if rerr != nil {
+			return nil, rerr
+		}
+		return doc.definitionLocations(params.Position), nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		doc, rerr := s.documentForParams(msg, &params, &params.TextDocument)
+		if rerr != nil {
+			return nil, rerr
+		}
+		return doc.hover(params.Position), nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		doc, rerr := s.documentForParams(msg, &params, &params.TextDocument)
+		if rerr != nil {
+			return nil, rerr
+		}
+		return doc.completions(params.Position), nil
+
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		doc, rerr := s.documentForParams(msg, &params, &params.TextDocument)
+		if rerr != nil {
+			return nil, rerr
+		}
+
+		formatted, err := doc.formatted()
+		if err != nil {
+			return nil, newResponseError(codeRequestFailed, "could not format schema: %s", err)
+		}
+		if formatted == doc.text {
+			return []TextEdit{}, nil
+		}
+		return []TextEdit{{Range: doc.fullRange(), NewText: formatted}}, nil
+
+	default:
+		return nil, newResponseError(codeMethodNotFound, "unsupported method `%s`", msg.Method)
+	}
+}
+
+func unmarshalParams(msg *message, params interface{}) *responseError {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return newResponseError(codeInvalidParams, "invalid parameters for `%s`: %s", msg.Method, err)
+	}
+	return nil
+}
+
+// documentForParams unmarshals the parameters of the request, returning the open document they
+// reference.
+func (s *Server) documentForParams(msg *message, params interface{}, textDocument *TextDocumentIdentifier) (*document, *responseError) {
+	if rerr := unmarshalParams(msg, params); rerr != nil {
+		return nil, rerr
+	}
+
+	doc, ok := s.documents[textDocument.URI]
+	if !ok {
+		return nil, newResponseError(codeInvalidParams, "document `%s` is not open", textDocument.URI)
+	}
+	return doc, nil
+}
+
+// updateDocument stores the latest text of the document and publishes its diagnostics.
+func (s *Server) updateDocument(ctx context.Context, uri string, text string) *responseError {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+
+	if err := s.publishDiagnostics(uri, doc.diagnostics(ctx, s.fileLoader(doc))); err != nil {
+		return newResponseError(codeInternalError, "could not publish diagnostics: %s", err)
+	}
+	return nil
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) error {
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// fileLoader returns the loader for the files imported by the document, if it is a file. The
+// text of open documents is used in place of their contents on disk.
+func (s *Server) fileLoader(doc *document) compiler.FileLoader {
+	path, ok := doc.filePath()
+	if !ok {
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	return compiler.FileLoaderFunc(func(importPath string) (string, error) {
+		fullPath := filepath.Join(dir, filepath.FromSlash(importPath))
+		for _, open := range s.documents {
+			if openPath, ok := open.filePath(); ok && openPath == fullPath {
+				return open.text, nil
+			}
+		}
+
+		contents, err := os.ReadFile(fullPath)
+		if err != nil {
+			return "", err
+		}
+		return string(contents), nil
+	})
+}
@@ -0,0 +1,314 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testMessage struct {
+	method string
+	id     int
+	params interface{}
+}
+
+func request(id int, method string, params interface{}) testMessage {
+	return testMessage{method: method, id: id, params: params}
+}
+
+func notification(method string, params interface{}) testMessage {
+	return testMessage{method: method, params: params}
+}
+
+// runServer runs the server over the given messages, returning the messages written by the
+// server and the error returned once it stopped.
+func runServer(t *testing.T, messages ...testMessage) ([]message, error) {
+	input := &bytes.Buffer{}
+	for _, msg := range messages {
+		encoded := map[string]interface{}{"jsonrpc": "2.0", "method": msg.method}
+		if msg.id != 0 {
+			encoded["id"] = msg.id
+		}
+		if msg.params != nil {
+			encoded["params"] = msg.params
+		}
+
+		body, err := json.Marshal(encoded)
+		require.NoError(t, err)
+		fmt.Fprintf(input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	output := &bytes.Buffer{}
+	runErr := NewServer("test").Run(context.Background(), input, output)
+
+	var written []message
+	outputConn := newConn(output, io.Discard)
+	for {
+		msg, err := outputConn.read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		written = append(written, *msg)
+	}
+	return written, runErr
+}
+
+func responseTo(t *testing.T, messages []message, id int) message {
+	for _, msg := range messages {
+		if msg.Method == "" && string(msg.ID) == fmt.Sprint(id) {
+			return msg
+		}
+	}
+	require.Failf(t, "missing response", "no response to request %d", id)
+	return message{}
+}
+
+func publishedDiagnostics(t *testing.T, messages []message) []PublishDiagnosticsParams {
+	var published []PublishDiagnosticsParams
+	for _, msg := range messages {
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+
+		var params PublishDiagnosticsParams
+		require.NoError(t, json.Unmarshal(msg.Params, &params))
+		published = append(published, params)
+	}
+	return published
+}
+
+func TestServerLifecycle(t *testing.T) {
+	require := require.New(t)
+
+	messages, err := runServer(t,
+		request(1, "initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}),
+		notification("initialized", map[string]interface{}{}),
+		request(2, "unknown/method", nil),
+		request(3, "shutdown", nil),
+		request(4, "textDocument/hover", nil),
+		notification("exit", nil),
+	)
+	require.NoError(err)
+	require.Len(messages, 4)
+
+	var result InitializeResult
+	require.NoError(json.Unmarshal(responseTo(t, messages, 1).Result, &result))
+	require.Equal(TextDocumentSyncFull, result.Capabilities.TextDocumentSync)
+	require.True(result.Capabilities.DefinitionProvider)
+	require.True(result.Capabilities.HoverProvider)
+	require.True(result.Capabilities.DocumentFormattingProvider)
+	require.NotNil(result.Capabilities.CompletionProvider)
+	require.Equal(ServerInfo{Name: "spicedb", Version: "test"}, result.ServerInfo)
+
+	require.Equal(codeMethodNotFound, responseTo(t, messages, 2).Error.Code)
+	require.Equal("null", string(responseTo(t, messages, 3).Result))
+	require.Equal(codeInvalidRequest, responseTo(t, messages, 4).Error.Code)
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	_, err := runServer(t, notification("exit", nil))
+	require.Error(t, err)
+
+	_, err = runServer(t)
+	require.NoError(t, err)
+}
+
+func TestServerInvalidMessage(t *testing.T) {
+	output := &bytes.Buffer{}
+	err := NewServer("test").Run(context.Background(), bytes.NewBufferString("Content-Length: 5\r\n\r\n{nope"), output)
+	require.NoError(t, err)
+
+	msg, err := newConn(output, io.Discard).read()
+	require.NoError(t, err)
+	require.Equal(t, codeParseError, msg.Error.Code)
+
+	err = NewServer("test").Run(context.Background(), bytes.NewBufferString("Content-Length: nope\r\n\r\n"), io.Discard)
+	require.Error(t, err)
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	require := require.New(t)
+	uri := "untitled:schema.zed"
+
+	messages, err := runServer(t,
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{
+			URI:  uri,
+			Text: "definition user {}\n\ndefinition document {\n\trelation viewer: user\n\tpermission view = viewer + editor\n}\n",
+		}}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: uri},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: "definition user {}\n\ndefinition document {\n\trelation viewer: usr\n}\n"}},
+		}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: uri},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: "definition user {}\n\ndefinition document {\n\trelation viewer: user\n\trelation owner: user\n\tpermission view = viewer\n}\n"}},
+		}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: uri},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: "definition user {}\n\ndefinition document {\n\trelation viewer: user\n\tpermission view = viewer\n}\n"}},
+		}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: uri},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: "definition user {\n"}},
+		}),
+		notification("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}),
+	)
+	require.NoError(err)
+
+	published := publishedDiagnostics(t, messages)
+	require.Len(published, 6)
+	for _, params := range published {
+		require.Equal(uri, params.URI)
+	}
+
+	// Type error.
+	require.Equal([]Diagnostic{{
+		Range:    Range{Start: Position{Line: 4, Character: 28}, End: Position{Line: 4, Character: 34}},
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  "relation/permission `editor` not found under definition `document`",
+	}}, published[0].Diagnostics)
+
+	// Unknown type.
+	require.Len(published[1].Diagnostics, 1)
+	require.Equal(SeverityError, published[1].Diagnostics[0].Severity)
+	require.Contains(published[1].Diagnostics[0].Message, "`usr`")
+	require.Equal(3, published[1].Diagnostics[0].Range.Start.Line)
+
+	// Lint warning.
+	require.Equal([]Diagnostic{{
+		Range:    Range{Start: Position{Line: 4, Character: 10}, End: Position{Line: 4, Character: 15}},
+		Severity: SeverityWarning,
+		Source:   diagnosticSource,
+		Message:  "relation `owner` is not referenced by any permission",
+	}}, published[2].Diagnostics)
+
+	// Valid schema.
+	require.Empty(published[3].Diagnostics)
+
+	// Parse error.
+	require.Len(published[4].Diagnostics, 1)
+	require.Equal(SeverityError, published[4].Diagnostics[0].Severity)
+
+	// Closed.
+	require.Empty(published[5].Diagnostics)
+}
+
+func TestServerDiagnosticsWithImports(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	require.NoError(os.Mkdir(filepath.Join(dir, "common"), 0o700))
+	require.NoError(os.WriteFile(filepath.Join(dir, "common", "user.zed"), []byte("definition user {}\n"), 0o600))
+
+	rootURI := "file://" + filepath.ToSlash(filepath.Join(dir, "schema.zed"))
+	groupURI := "file://" + filepath.ToSlash(filepath.Join(dir, "common", "group.zed"))
+	rootText := "import \"common/user.zed\"\nimport \"common/group.zed\"\n\ndefinition document {\n\trelation viewer: user | group#member\n\tpermission view = viewer\n}\n"
+
+	messages, err := runServer(t,
+		// The imported group file is only open in the editor, and does not exist on disk.
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{
+			URI:  groupURI,
+			Text: "import \"user.zed\"\n\ndefinition group {\n\trelation member: user\n\tpermission view = member\n}\n",
+		}}),
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{
+			URI:  rootURI,
+			Text: rootText,
+		}}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: groupURI},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: "definition group {\n\trelation member: unknown\n}\n"}},
+		}),
+		notification("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: rootURI},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: rootText}},
+		}),
+	)
+	require.NoError(err)
+
+	published := publishedDiagnostics(t, messages)
+	require.Len(published, 4)
+
+	require.Equal(groupURI, published[0].URI)
+	require.Empty(published[0].Diagnostics)
+
+	require.Equal(rootURI, published[1].URI)
+	require.Empty(published[1].Diagnostics)
+
+	require.Equal(groupURI, published[2].URI)
+	require.Len(published[2].Diagnostics, 1)
+
+	// Errors found in imported files are reported at the start of the importing document.
+	require.Equal(rootURI, published[3].URI)
+	require.Len(published[3].Diagnostics, 1)
+	require.Equal(Range{}, published[3].Diagnostics[0].Range)
+	require.Contains(published[3].Diagnostics[0].Message, "unknown")
+}
+
+func TestServerRequests(t *testing.T) {
+	require := require.New(t)
+	uri := "untitled:schema.zed"
+	text := "definition user {}\n\ndefinition document {\n  relation viewer: user\n   permission view = viewer\n}"
+
+	messages, err := runServer(t,
+		notification("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, Text: text}}),
+		request(1, "textDocument/definition", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 4, Character: 22},
+		}),
+		request(2, "textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 4, Character: 22},
+		}),
+		request(3, "textDocument/completion", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 3, Character: 19},
+		}),
+		request(4, "textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}}),
+		request(5, "textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: "untitled:unknown.zed"},
+		}),
+		request(6, "textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 1, Character: 0},
+		}),
+	)
+	require.NoError(err)
+
+	var locations []Location
+	require.NoError(json.Unmarshal(responseTo(t, messages, 1).Result, &locations))
+	require.Equal([]Location{{
+		URI:   uri,
+		Range: Range{Start: Position{Line: 3, Character: 11}, End: Position{Line: 3, Character: 17}},
+	}}, locations)
+
+	var hover Hover
+	require.NoError(json.Unmarshal(responseTo(t, messages, 2).Result, &hover))
+	require.Equal("markdown", hover.Contents.Kind)
+	require.Equal("```\nrelation viewer: user\n```", hover.Contents.Value)
+
+	var items []CompletionItem
+	require.NoError(json.Unmarshal(responseTo(t, messages, 3).Result, &items))
+	require.Equal([]CompletionItem{
+		{Label: "user", Kind: CompletionKindClass, Detail: "definition"},
+		{Label: "document", Kind: CompletionKindClass, Detail: "definition"},
+	}, items)
+
+	var edits []TextEdit
+	require.NoError(json.Unmarshal(responseTo(t, messages, 4).Result, &edits))
+	require.Equal([]TextEdit{{
+		Range:   Range{Start: Position{}, End: Position{Line: 5, Character: 1}},
+		NewText: "definition user {}\n\ndefinition document {\n\trelation viewer: user\n\tpermission view = viewer\n}\n",
+	}}, edits)
+
+	require.Equal(codeInvalidParams, responseTo(t, messages, 5).Error.Code)
+	require.Equal("null", string(responseTo(t, messages, 6).Result))
+}
@@ -14,7 +14,9 @@ import (
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -22,6 +24,10 @@ type hasConsistency interface {
 	GetConsistency() *v1.Consistency
 }
 
+type hasOptionalCursor interface {
+	GetOptionalCursor() *v1.Cursor
+}
+
 type ctxKeyType struct{}
 
 var revisionKey ctxKeyType = struct{}{}
@@ -45,7 +51,7 @@ func RevisionFromContext(ctx context.Context) (datastore.Revision, *v1.ZedToken,
 		handle := c.(*revisionHandle)
 		rev := handle.revision
 		if rev != nil {
-			return rev, zedtoken.MustNewFromRevision(rev), nil
+			return rev, zedtoken.MustNewFromRevision(ctx, rev), nil
 		}
 	}
 
@@ -74,7 +80,23 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 	var revision datastore.Revision
 	consistency := req.GetConsistency()
 
+	withOptionalCursor, hasOptionalCursor := req.(hasOptionalCursor)
+
 	switch {
+	case hasOptionalCursor && withOptionalCursor.GetOptionalCursor() != nil:
+		// Always use the revision encoded in the cursor.
+		requestedRev, err := cursor.DecodeToDispatchRevision(ctx, withOptionalCursor.GetOptionalCursor(), ds)
+		if err != nil {
+			return rewriteDatastoreError(ctx, err)
+		}
+
+		err = ds.CheckRevision(ctx, requestedRev)
+		if err != nil {
+			return rewriteDatastoreError(ctx, err)
+		}
+
+		revision = requestedRev
+
 	case consistency == nil || consistency.GetMinimizeLatency():
 		// Minimize Latency: Use the datastore's current revision, whatever it may be.
 		databaseRev, err := ds.OptimizedRevision(ctx)
@@ -102,9 +124,9 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 	case consistency.GetAtExactSnapshot() != nil:
 		// Exact snapshot: Use the revision as encoded in the zed token.
-		requestedRev, err := zedtoken.DecodeRevision(consistency.GetAtExactSnapshot(), ds)
+		requestedRev, err := zedtoken.DecodeRevision(ctx, consistency.GetAtExactSnapshot(), ds)
 		if err != nil {
-			return errInvalidZedToken
+			return rewriteZedTokenError(err)
 		}
 
 		err = ds.CheckRevision(ctx, requestedRev)
@@ -128,8 +150,9 @@ var bypassServiceWhitelist = map[string]struct{}{
 }
 
 // UnaryServerInterceptor returns a new unary server interceptor that performs per-request exchange of
-// the specified consistency configuration for the revision at which to perform the request.
-func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+// the specified consistency configuration for the revision at which to perform the request. If the
+// signer is not nil, zedtokens are signed by it and must have been signed by it to be accepted.
+func UnaryServerInterceptor(signer *zedtoken.Signer) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		for bypass := range bypassServiceWhitelist {
 			if strings.HasPrefix(info.FullMethod, bypass) {
@@ -137,7 +160,7 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 			}
 		}
 		ds := datastoremw.MustFromContext(ctx)
-		newCtx := ContextWithHandle(ctx)
+		newCtx := ContextWithHandle(zedtoken.ContextWithSigner(ctx, signer))
 		if err := AddRevisionToContext(newCtx, req, ds); err != nil {
 			return nil, err
 		}
@@ -147,15 +170,16 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 }
 
 // StreamServerInterceptor returns a new stream server interceptor that performs per-request exchange of
-// the specified consistency configuration for the revision at which to perform the request.
-func StreamServerInterceptor() grpc.StreamServerInterceptor {
+// the specified consistency configuration for the revision at which to perform the request. If the
+// signer is not nil, zedtokens are signed by it and must have been signed by it to be accepted.
+func StreamServerInterceptor(signer *zedtoken.Signer) grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		for bypass := range bypassServiceWhitelist {
 			if strings.HasPrefix(info.FullMethod, bypass) {
 				return handler(srv, stream)
 			}
 		}
-		wrapper := &recvWrapper{stream, ContextWithHandle(stream.Context())}
+		wrapper := &recvWrapper{stream, ContextWithHandle(zedtoken.ContextWithSigner(stream.Context(), signer))}
 		return handler(srv, wrapper)
 	}
 }
@@ -186,9 +210,9 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	}
 
 	if requested != nil {
-		requestedRev, err := zedtoken.DecodeRevision(requested, ds)
+		requestedRev, err := zedtoken.DecodeRevision(ctx, requested, ds)
 		if err != nil {
-			return datastore.NoRevision, errInvalidZedToken
+			return datastore.NoRevision, rewriteZedTokenError(err)
 		}
 
 		if databaseRev.GreaterThan(requestedRev) {
@@ -200,6 +224,16 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	return databaseRev, nil
 }
 
+func rewriteZedTokenError(err error) error {
+	switch {
+	case errors.Is(err, zedtoken.ErrDifferentDatastore), errors.Is(err, zedtoken.ErrInvalidSignature):
+		return status.Errorf(codes.InvalidArgument, "invalid zedtoken: %s", err)
+
+	default:
+		return errInvalidZedToken
+	}
+}
+
 func rewriteDatastoreError(ctx context.Context, err error) error {
 	// Check if the error can be directly used.
 	if _, ok := status.FromError(err); ok {
@@ -213,6 +247,9 @@ func rewriteDatastoreError(ctx context.Context, err error) error {
 	case errors.As(err, &datastore.ErrReadOnly{}):
 		return shared.ErrServiceReadOnly
 
+	case errors.As(err, &cursor.InvalidCursorError{}):
+		return spiceerrors.WithCodeAndReason(err, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_INVALID_CURSOR)
+
 	default:
 		log.Ctx(ctx).Err(err).Msg("unexpected consistency middleware error")
 		return err
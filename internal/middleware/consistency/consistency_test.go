@@ -8,9 +8,13 @@ import (
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore/revision"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -95,7 +99,7 @@ func TestAddRevisionToContextAtLeastAsFresh(t *testing.T) {
 	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(exact),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), exact),
 			},
 		},
 	}, ds)
@@ -119,7 +123,7 @@ func TestAddRevisionToContextAtValidExactSnapshot(t *testing.T) {
 	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtExactSnapshot{
-				AtExactSnapshot: zedtoken.MustNewFromRevision(exact),
+				AtExactSnapshot: zedtoken.MustNewFromRevision(context.Background(), exact),
 			},
 		},
 	}, ds)
@@ -143,7 +147,7 @@ func TestAddRevisionToContextAtInvalidExactSnapshot(t *testing.T) {
 	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtExactSnapshot{
-				AtExactSnapshot: zedtoken.MustNewFromRevision(zero),
+				AtExactSnapshot: zedtoken.MustNewFromRevision(context.Background(), zero),
 			},
 		},
 	}, ds)
@@ -151,6 +155,92 @@ func TestAddRevisionToContextAtInvalidExactSnapshot(t *testing.T) {
 	ds.AssertExpectations(t)
 }
 
+func TestAddRevisionToContextAtSignedExactSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	signer, err := zedtoken.NewSigner("production", "somekey")
+	require.NoError(err)
+	signed := zedtoken.ContextWithSigner(context.Background(), signer)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("CheckRevision", exact).Return(nil).Times(1)
+	ds.On("RevisionFromString", exact.String()).Return(exact, nil).Once()
+
+	updated := ContextWithHandle(signed)
+	err = AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtExactSnapshot{
+				AtExactSnapshot: zedtoken.MustNewFromRevision(signed, exact),
+			},
+		},
+	}, ds)
+	require.NoError(err)
+
+	rev, token, err := RevisionFromContext(updated)
+	require.NoError(err)
+	require.True(exact.Equal(rev))
+
+	// The zedtoken for the selected revision is also signed.
+	_, err = zedtoken.DecodeRevision(signed, token, revision.DecimalDecoder{})
+	require.NoError(err)
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAtOtherDatastoreSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	signer, err := zedtoken.NewSigner("production", "somekey")
+	require.NoError(err)
+	otherSigner, err := zedtoken.NewSigner("staging", "somekey")
+	require.NoError(err)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+
+	updated := ContextWithHandle(zedtoken.ContextWithSigner(context.Background(), signer))
+	err = AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(zedtoken.ContextWithSigner(context.Background(), otherSigner), exact),
+			},
+		},
+	}, ds)
+	require.Equal(codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(err, zedtoken.ErrDifferentDatastore.Error())
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextWithTamperedCursor(t *testing.T) {
+	require := require.New(t)
+
+	signer, err := zedtoken.NewSigner("production", "somekey")
+	require.NoError(err)
+	signed := zedtoken.ContextWithSigner(context.Background(), signer)
+
+	encoded, err := cursor.EncodeFromDispatchCursor(signed, &dispatch.Cursor{Sections: []string{"1"}}, "somehash", exact)
+	require.NoError(err)
+
+	// Move the cursor to another revision, keeping the signature of the original.
+	decoded, err := cursor.Decode(encoded)
+	require.NoError(err)
+	decoded.GetV1().Revision = head.String()
+	tampered, err := cursor.Encode(decoded)
+	require.NoError(err)
+
+	ds := &proxy_test.MockDatastore{}
+
+	updated := ContextWithHandle(signed)
+	err = AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		OptionalCursor: tampered,
+	}, ds)
+	require.Equal(codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(err, zedtoken.ErrInvalidSignature.Error())
+	ds.AssertExpectations(t)
+}
+
 func TestAddRevisionToContextNoConsistencyAPI(t *testing.T) {
 	require := require.New(t)
 
@@ -3,6 +3,7 @@ package pertoken
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -27,14 +28,16 @@ const (
 type MiddlewareForTesting struct {
 	datastoreByToken *sync.Map
 	configFilePaths  []string
+	snapshotPath     string
 }
 
 // NewMiddleware returns a new per-token datastore middleware that initializes each datastore with the data in the
-// config files.
-func NewMiddleware(configFilePaths []string) *MiddlewareForTesting {
+// memdb snapshot file, if any, followed by the data in the config files.
+func NewMiddleware(configFilePaths []string, snapshotPath string) *MiddlewareForTesting {
 	return &MiddlewareForTesting{
 		datastoreByToken: &sync.Map{},
 		configFilePaths:  configFilePaths,
+		snapshotPath:     snapshotPath,
 	}
 }
 
@@ -50,7 +53,7 @@ func (m *MiddlewareForTesting) getOrCreateDatastore(ctx context.Context) (datast
 	}
 
 	log.Ctx(ctx).Debug().Str("token", tokenStr).Msg("initializing new upstream for token")
-	ds, err := memdb.NewMemdbDatastore(0, revisionQuantization, gcWindow)
+	ds, err := m.newDatastore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to init datastore: %w", err)
 	}
@@ -67,6 +70,20 @@ func (m *MiddlewareForTesting) getOrCreateDatastore(ctx context.Context) (datast
 	return ds, nil
 }
 
+func (m *MiddlewareForTesting) newDatastore() (datastore.Datastore, error) {
+	if m.snapshotPath == "" {
+		return memdb.NewMemdbDatastore(0, revisionQuantization, gcWindow)
+	}
+
+	file, err := os.Open(m.snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	return memdb.NewMemdbDatastoreFromSnapshot(file, 0, revisionQuantization, gcWindow)
+}
+
 // UnaryServerInterceptor returns a new unary server interceptor that sets a separate in-memory datastore per token
 func (m *MiddlewareForTesting) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -147,7 +147,15 @@ func convertToBdd(relation *core.Relation, bdd *rudd.BDD, so *core.SetOperation,
 			values = append(values, node)
 
 		case *core.SetOperation_Child_TupleToUserset:
-			arrowIndex, err := varMap.GetArrow(child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation)
+			arrowIndex, err := varMap.GetArrow(child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation, core.FunctionedTupleToUserset_FUNCTION_ANY)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, builder(index, arrowIndex))
+
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			arrowIndex, err := varMap.GetArrow(child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation, child.FunctionedTupleToUserset.Function)
 			if err != nil {
 				return nil, err
 			}
@@ -169,8 +177,17 @@ type bddVarMap struct {
 	varMap   map[string]int
 }
 
-func (bvm bddVarMap) GetArrow(tuplesetName string, relName string) (int, error) {
-	key := tuplesetName + "->" + relName
+// arrowKey returns the key of the variable for an arrow. An `any` arrow is equivalent to a
+// plain arrow, and so shares its variable.
+func arrowKey(tuplesetName string, relName string, function core.FunctionedTupleToUserset_Function) string {
+	if function == core.FunctionedTupleToUserset_FUNCTION_ALL {
+		return tuplesetName + ".all(" + relName + ")"
+	}
+	return tuplesetName + "->" + relName
+}
+
+func (bvm bddVarMap) GetArrow(tuplesetName string, relName string, function core.FunctionedTupleToUserset_Function) (int, error) {
+	key := arrowKey(tuplesetName, relName, function)
 	index, ok := bvm.varMap[key]
 	if !ok {
 		return -1, spiceerrors.MustBugf("missing arrow key %s in varMap", key)
@@ -213,12 +230,18 @@ func buildBddVarMap(relations []*core.Relation, aliasMap map[string]string) (bdd
 		}
 
 		_, err := graph.WalkRewrite(rewrite, func(childOneof *core.SetOperation_Child) interface{} {
+			var key string
 			switch child := childOneof.ChildType.(type) {
 			case *core.SetOperation_Child_TupleToUserset:
-				key := child.TupleToUserset.Tupleset.Relation + "->" + child.TupleToUserset.ComputedUserset.Relation
-				if _, ok := varMap[key]; !ok {
-					varMap[key] = len(varMap)
-				}
+				key = arrowKey(child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation, core.FunctionedTupleToUserset_FUNCTION_ANY)
+			case *core.SetOperation_Child_FunctionedTupleToUserset:
+				key = arrowKey(child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation, child.FunctionedTupleToUserset.Function)
+			default:
+				return nil
+			}
+
+			if _, ok := varMap[key]; !ok {
+				varMap[key] = len(varMap)
 			}
 			return nil
 		})
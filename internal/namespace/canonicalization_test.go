@@ -501,6 +501,24 @@ func TestCanonicalizationComparison(t *testing.T) {
 			"(owner & nil) & editor",
 			true,
 		},
+		{
+			"any arrow same as arrow",
+			"viewer.any(owner)",
+			"viewer->owner",
+			true,
+		},
+		{
+			"all arrow different from arrow",
+			"viewer.all(owner)",
+			"viewer->owner",
+			false,
+		},
+		{
+			"union associativity with all arrows",
+			"viewer.all(owner) + editor",
+			"editor + viewer.all(owner)",
+			true,
+		},
 	}
 
 	for _, tc := range testCases {
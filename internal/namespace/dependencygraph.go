@@ -0,0 +1,242 @@
+package namespace
+
+import (
+	"fmt"
+	"sort"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// DependencyKind is the kind of edge by which a relation or permission depends on another.
+type DependencyKind int
+
+const (
+	// DirectDependency indicates that a relation allows a subject relation as one of its subject
+	// types, e.g. `relation viewer: group#member` depends on `group#member`.
+	DirectDependency DependencyKind = iota
+
+	// ComputedUsersetDependency indicates that a permission references a relation or permission
+	// on the same object, e.g. `permission view = viewer` depends on `viewer`.
+	ComputedUsersetDependency
+
+	// TupleToUsersetDependency indicates that a permission walks an arrow, e.g.
+	// `permission view = parent->view` depends on both `parent` and `view` on each subject type
+	// of `parent`.
+	TupleToUsersetDependency
+)
+
+// RelationDependency is an edge in a DependencyGraph, indicating that the Dependent relation or
+// permission is computed using the Dependency relation or permission.
+type RelationDependency struct {
+	Dependent  *core.RelationReference
+	Dependency *core.RelationReference
+	Kind       DependencyKind
+}
+
+// DependencyGraph is a graph over all relations and permissions in a schema, with an edge from
+// each relation or permission to each relation or permission it is computed from.
+type DependencyGraph struct {
+	typeSystems    map[string]*TypeSystem
+	dependenciesOf map[string][]RelationDependency
+	dependentsOf   map[string][]RelationDependency
+}
+
+// BuildDependencyGraph builds the dependency graph for a schema from the type systems of all of
+// its object definitions.
+func BuildDependencyGraph(typeSystems []*TypeSystem) (*DependencyGraph, error) {
+	dg := &DependencyGraph{
+		typeSystems:    make(map[string]*TypeSystem, len(typeSystems)),
+		dependenciesOf: map[string][]RelationDependency{},
+		dependentsOf:   map[string][]RelationDependency{},
+	}
+
+	for _, ts := range typeSystems {
+		dg.typeSystems[ts.Namespace().Name] = ts
+	}
+
+	for _, ts := range typeSystems {
+		for _, relation := range ts.Namespace().Relation {
+			dependent := tuple.RelationReference(ts.Namespace().Name, relation.Name)
+
+			if relation.UsersetRewrite != nil {
+				if err := dg.addRewriteDependencies(ts, dependent, relation.UsersetRewrite); err != nil {
+					return nil, err
+				}
+			}
+
+			allowedTypes, err := ts.AllowedDirectRelationsAndWildcards(relation.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, allowedType := range allowedTypes {
+				if allowedType.GetPublicWildcard() != nil || allowedType.GetRelation() == tuple.Ellipsis {
+					continue
+				}
+
+				dg.addDependency(dependent, tuple.RelationReference(allowedType.Namespace, allowedType.GetRelation()), DirectDependency)
+			}
+		}
+	}
+
+	return dg, nil
+}
+
+func (dg *DependencyGraph) addRewriteDependencies(ts *TypeSystem, dependent *core.RelationReference, rewrite *core.UsersetRewrite) error {
+	var setOp *core.SetOperation
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		setOp = rw.Union
+	case *core.UsersetRewrite_Intersection:
+		setOp = rw.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		setOp = rw.Exclusion
+	default:
+		return fmt.Errorf("unknown rewrite operation %T", rw)
+	}
+
+	for _, child := range setOp.Child {
+		switch child := child.ChildType.(type) {
+		case *core.SetOperation_Child_UsersetRewrite:
+			if err := dg.addRewriteDependencies(ts, dependent, child.UsersetRewrite); err != nil {
+				return err
+			}
+
+		case *core.SetOperation_Child_ComputedUserset:
+			dg.addDependency(dependent, tuple.RelationReference(dependent.Namespace, child.ComputedUserset.Relation), ComputedUsersetDependency)
+
+		case *core.SetOperation_Child_TupleToUserset:
+			if err := dg.addArrowDependencies(ts, dependent, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation); err != nil {
+				return err
+			}
+
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			if err := dg.addArrowDependencies(ts, dependent, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (dg *DependencyGraph) addArrowDependencies(ts *TypeSystem, dependent *core.RelationReference, tuplesetRelation string, computedRelation string) error {
+	dg.addDependency(dependent, tuple.RelationReference(dependent.Namespace, tuplesetRelation), TupleToUsersetDependency)
+
+	allowedTypes, err := ts.AllowedDirectRelationsAndWildcards(tuplesetRelation)
+	if err != nil {
+		return err
+	}
+
+	for _, allowedType := range allowedTypes {
+		// Arrows over subject types without the computed relation are ignored when
+		// computing the permission.
+		subjectTS, ok := dg.typeSystems[allowedType.Namespace]
+		if !ok || !subjectTS.HasRelation(computedRelation) {
+			continue
+		}
+
+		dg.addDependency(dependent, tuple.RelationReference(allowedType.Namespace, computedRelation), TupleToUsersetDependency)
+	}
+
+	return nil
+}
+
+func (dg *DependencyGraph) addDependency(dependent *core.RelationReference, dependency *core.RelationReference, kind DependencyKind) {
+	dependentKey := tuple.StringRR(dependent)
+	for _, existing := range dg.dependenciesOf[dependentKey] {
+		if existing.Kind == kind && tuple.StringRR(existing.Dependency) == tuple.StringRR(dependency) {
+			return
+		}
+	}
+
+	edge := RelationDependency{
+		Dependent:  dependent,
+		Dependency: dependency,
+		Kind:       kind,
+	}
+	dg.dependenciesOf[dependentKey] = append(dg.dependenciesOf[dependentKey], edge)
+
+	dependencyKey := tuple.StringRR(dependency)
+	dg.dependentsOf[dependencyKey] = append(dg.dependentsOf[dependencyKey], edge)
+}
+
+// IsPermission returns true if the given relation reference refers to a permission.
+func (dg *DependencyGraph) IsPermission(rr *core.RelationReference) bool {
+	ts, ok := dg.typeSystems[rr.Namespace]
+	return ok && ts.IsPermission(rr.Relation)
+}
+
+// TransitiveDependents returns every edge by which a relation or permission transitively
+// depends on the given relation or permission, ordered by dependent.
+func (dg *DependencyGraph) TransitiveDependents(rr *core.RelationReference) ([]RelationDependency, error) {
+	if err := dg.ensureRelation(rr); err != nil {
+		return nil, err
+	}
+
+	return dg.walk(rr, dg.dependentsOf, func(edge RelationDependency) *core.RelationReference {
+		return edge.Dependent
+	}), nil
+}
+
+// TransitiveDependencies returns every edge by which the given relation or permission
+// transitively depends on other relations and permissions, ordered by dependent.
+func (dg *DependencyGraph) TransitiveDependencies(rr *core.RelationReference) ([]RelationDependency, error) {
+	if err := dg.ensureRelation(rr); err != nil {
+		return nil, err
+	}
+
+	return dg.walk(rr, dg.dependenciesOf, func(edge RelationDependency) *core.RelationReference {
+		return edge.Dependency
+	}), nil
+}
+
+func (dg *DependencyGraph) ensureRelation(rr *core.RelationReference) error {
+	ts, ok := dg.typeSystems[rr.Namespace]
+	if !ok {
+		return NewNamespaceNotFoundErr(rr.Namespace)
+	}
+
+	if !ts.HasRelation(rr.Relation) {
+		return NewRelationNotFoundErr(rr.Namespace, rr.Relation)
+	}
+
+	return nil
+}
+
+func (dg *DependencyGraph) walk(
+	start *core.RelationReference,
+	edges map[string][]RelationDependency,
+	next func(RelationDependency) *core.RelationReference,
+) []RelationDependency {
+	visited := map[string]struct{}{tuple.StringRR(start): {}}
+	queue := []*core.RelationReference{start}
+
+	var found []RelationDependency
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range edges[tuple.StringRR(current)] {
+			found = append(found, edge)
+
+			nextKey := tuple.StringRR(next(edge))
+			if _, ok := visited[nextKey]; ok {
+				continue
+			}
+
+			visited[nextKey] = struct{}{}
+			queue = append(queue, next(edge))
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return dependencySortKey(found[i]) < dependencySortKey(found[j])
+	})
+	return found
+}
+
+func dependencySortKey(edge RelationDependency) string {
+	return fmt.Sprintf("%s %s %d", tuple.StringRR(edge.Dependent), tuple.StringRR(edge.Dependency), edge.Kind)
+}
@@ -0,0 +1,159 @@
+package namespace
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const dependencyGraphTestSchema = `
+	definition user {}
+
+	definition group {
+		relation member: user | group#member
+	}
+
+	definition folder {
+		relation viewer: user | group#member
+		permission view = viewer
+	}
+
+	definition document {
+		relation parent: folder
+		relation viewer: user
+		relation banned: user
+		permission view = (viewer + parent->view) - banned
+		permission edit = nil
+		permission view_and_edit = view & edit
+	}
+`
+
+func TestDependencyGraph(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		relation             string
+		expectedDependents   []string
+		expectedDependencies []string
+	}{
+		{
+			"subject relation",
+			"group#member",
+			[]string{
+				"document#view <- folder#view (ttu)",
+				"document#view_and_edit <- document#view (computed)",
+				"folder#view <- folder#viewer (computed)",
+				"folder#viewer <- group#member (direct)",
+				"group#member <- group#member (direct)",
+			},
+			[]string{
+				"group#member <- group#member (direct)",
+			},
+		},
+		{
+			"tupleset relation",
+			"document#parent",
+			[]string{
+				"document#view <- document#parent (ttu)",
+				"document#view_and_edit <- document#view (computed)",
+			},
+			[]string{},
+		},
+		{
+			"permission",
+			"document#view",
+			[]string{
+				"document#view_and_edit <- document#view (computed)",
+			},
+			[]string{
+				"document#view <- document#banned (computed)",
+				"document#view <- document#parent (ttu)",
+				"document#view <- document#viewer (computed)",
+				"document#view <- folder#view (ttu)",
+				"folder#view <- folder#viewer (computed)",
+				"folder#viewer <- group#member (direct)",
+				"group#member <- group#member (direct)",
+			},
+		},
+		{
+			"nil permission",
+			"document#edit",
+			[]string{
+				"document#view_and_edit <- document#edit (computed)",
+			},
+			[]string{},
+		},
+	}
+
+	dg := buildTestDependencyGraph(t, dependencyGraphTestSchema)
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			namespaceName, relationName := tuple.MustSplitRelRef(tc.relation)
+
+			dependents, err := dg.TransitiveDependents(tuple.RelationReference(namespaceName, relationName))
+			require.NoError(err)
+			require.Equal(tc.expectedDependents, dependencyStrings(dependents))
+
+			dependencies, err := dg.TransitiveDependencies(tuple.RelationReference(namespaceName, relationName))
+			require.NoError(err)
+			require.Equal(tc.expectedDependencies, dependencyStrings(dependencies))
+		})
+	}
+}
+
+func TestDependencyGraphUnknownRelation(t *testing.T) {
+	require := require.New(t)
+	dg := buildTestDependencyGraph(t, dependencyGraphTestSchema)
+
+	_, err := dg.TransitiveDependents(tuple.RelationReference("unknown", "viewer"))
+	require.ErrorAs(err, &ErrNamespaceNotFound{})
+
+	_, err = dg.TransitiveDependencies(tuple.RelationReference("document", "unknown"))
+	require.ErrorAs(err, &ErrRelationNotFound{})
+
+	require.True(dg.IsPermission(tuple.RelationReference("document", "view")))
+	require.False(dg.IsPermission(tuple.RelationReference("document", "viewer")))
+}
+
+func buildTestDependencyGraph(t *testing.T, schema string) *DependencyGraph {
+	empty := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schema,
+	}, &empty)
+	require.NoError(t, err)
+
+	typeSystems := make([]*TypeSystem, 0, len(compiled.ObjectDefinitions))
+	for _, nsDef := range compiled.ObjectDefinitions {
+		ts, err := NewNamespaceTypeSystem(nsDef, ResolverForPredefinedDefinitions(PredefinedElements{
+			Namespaces: compiled.ObjectDefinitions,
+			Caveats:    compiled.CaveatDefinitions,
+		}))
+		require.NoError(t, err)
+		typeSystems = append(typeSystems, ts)
+	}
+
+	dg, err := BuildDependencyGraph(typeSystems)
+	require.NoError(t, err)
+	return dg
+}
+
+func dependencyStrings(edges []RelationDependency) []string {
+	kinds := map[DependencyKind]string{
+		DirectDependency:          "direct",
+		ComputedUsersetDependency: "computed",
+		TupleToUsersetDependency:  "ttu",
+	}
+
+	strs := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		strs = append(strs, fmt.Sprintf("%s <- %s (%s)", tuple.StringRR(edge.Dependent), tuple.StringRR(edge.Dependency), kinds[edge.Kind]))
+	}
+	return strs
+}
@@ -0,0 +1,443 @@
+package namespace
+
+import (
+	"fmt"
+	"strings"
+
+	nspkg "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// LintWarning is a warning found by linting a schema. Unlike a type error, a warning does not
+// make the schema invalid, but indicates a likely mistake in it.
+type LintWarning struct {
+	// Message is the human-readable message for the warning.
+	Message string
+
+	// DefinitionName is the name of the definition in which the warning was found.
+	DefinitionName string
+
+	// RelationName is the name of the relation or permission in which the warning was found,
+	// if any.
+	RelationName string
+
+	// SourceCodeString is the source code of the element on which the warning was found.
+	SourceCodeString string
+
+	// SourcePosition is the position of the element on which the warning was found in the
+	// source schema, if any.
+	SourcePosition *core.SourcePosition
+}
+
+// String returns the message of the warning, along with its position, if any.
+func (lw LintWarning) String() string {
+	if lw.SourcePosition == nil {
+		return lw.Message
+	}
+
+	return fmt.Sprintf("%s (line %d, column %d)", lw.Message, lw.SourcePosition.ZeroIndexedLineNumber+1, lw.SourcePosition.ZeroIndexedColumnPosition+1)
+}
+
+// LintSchema lints the schema formed by the type systems of all of its object definitions,
+// which must have been validated, returning the warnings found in schema order.
+func LintSchema(typeSystems []*TypeSystem) ([]LintWarning, error) {
+	dg, err := BuildDependencyGraph(typeSystems)
+	if err != nil {
+		return nil, err
+	}
+
+	sl := &schemaLinter{
+		dg:          dg,
+		hasSubjects: map[string]bool{},
+	}
+
+	var warnings []LintWarning
+	for _, ts := range typeSystems {
+		nsDef := ts.Namespace()
+		if warning, ok := lintNaming(nsDef.Name, "", nsDef); ok {
+			warnings = append(warnings, warning)
+		}
+
+		for _, relation := range nsDef.Relation {
+			relationWarnings, err := sl.lintRelation(ts, relation)
+			if err != nil {
+				return nil, err
+			}
+			warnings = append(warnings, relationWarnings...)
+		}
+	}
+
+	return warnings, nil
+}
+
+type schemaLinter struct {
+	dg *DependencyGraph
+
+	// hasSubjects caches whether each relation or permission can have any subjects, keyed by
+	// its relation reference string.
+	hasSubjects map[string]bool
+}
+
+func (sl *schemaLinter) lintRelation(ts *TypeSystem, relation *core.Relation) ([]LintWarning, error) {
+	nsName := ts.Namespace().Name
+
+	var warnings []LintWarning
+	if warning, ok := lintNaming(nsName, relation.Name, relation); ok {
+		warnings = append(warnings, warning)
+	}
+
+	if !ts.IsPermission(relation.Name) {
+		dependents, err := sl.dg.TransitiveDependents(tuple.RelationReference(nsName, relation.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dependent := range dependents {
+			if sl.dg.IsPermission(dependent.Dependent) {
+				return warnings, nil
+			}
+		}
+
+		return append(warnings, newLintWarning(
+			fmt.Sprintf("relation `%s` is not referenced by any permission", relation.Name),
+			nsName, relation.Name, relation, relation.Name,
+		)), nil
+	}
+
+	arrowWarnings, err := lintArrows(ts, relation)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, arrowWarnings...)
+
+	if rewriteAlwaysNil(ts, relation.UsersetRewrite, map[string]struct{}{}) {
+		return append(warnings, newLintWarning(
+			fmt.Sprintf("permission `%s` always evaluates to nil", relation.Name),
+			nsName, relation.Name, relation, relation.Name,
+		)), nil
+	}
+
+	hasSubjects, err := sl.relationHasSubjects(ts, relation.Name, map[string]struct{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasSubjects {
+		warnings = append(warnings, newLintWarning(
+			fmt.Sprintf("permission `%s` cannot be reached by any subject type", relation.Name),
+			nsName, relation.Name, relation, relation.Name,
+		))
+	}
+
+	return warnings, nil
+}
+
+// lintNaming returns a warning if the name of a relation or permission repeats the name of its
+// definition, or if the name of a definition, relation or permission contains consecutive
+// underscores.
+func lintNaming(nsName string, relationName string, withSource nspkg.WithSourcePosition) (LintWarning, bool) {
+	if relationName == "" {
+		if strings.Contains(definitionNameWithoutPrefix(nsName), "__") {
+			return newLintWarning(
+				fmt.Sprintf("definition `%s` contains consecutive underscores", nsName),
+				nsName, "", withSource, nsName,
+			), true
+		}
+		return LintWarning{}, false
+	}
+
+	if strings.Contains(relationName, "__") {
+		return newLintWarning(
+			fmt.Sprintf("`%s` contains consecutive underscores", relationName),
+			nsName, relationName, withSource, relationName,
+		), true
+	}
+
+	definitionName := definitionNameWithoutPrefix(nsName)
+	if trimmed := strings.TrimPrefix(relationName, definitionName+"_"); trimmed != relationName {
+		return newLintWarning(
+			fmt.Sprintf("`%s` repeats the name of its definition `%s`; consider renaming it to `%s`", relationName, nsName, trimmed),
+			nsName, relationName, withSource, relationName,
+		), true
+	}
+
+	if trimmed := strings.TrimSuffix(relationName, "_"+definitionName); trimmed != relationName {
+		return newLintWarning(
+			fmt.Sprintf("`%s` repeats the name of its definition `%s`; consider renaming it to `%s`", relationName, nsName, trimmed),
+			nsName, relationName, withSource, relationName,
+		), true
+	}
+
+	return LintWarning{}, false
+}
+
+// definitionNameWithoutPrefix returns the name of the definition without its prefix, if any.
+func definitionNameWithoutPrefix(nsName string) string {
+	if index := strings.LastIndex(nsName, "/"); index >= 0 {
+		return nsName[index+1:]
+	}
+	return nsName
+}
+
+// lintArrows returns a warning for each arrow in the permission walking a relation that allows
+// a subject relation, such as `group#member`. The subject relation is ignored when walking the
+// arrow, which is rarely what was intended.
+func lintArrows(ts *TypeSystem, relation *core.Relation) ([]LintWarning, error) {
+	var warnings []LintWarning
+	err := walkArrows(relation.UsersetRewrite, func(childOneof *core.SetOperation_Child, tuplesetRelation string, arrowString string) error {
+		allowedTypes, err := ts.AllowedDirectRelationsAndWildcards(tuplesetRelation)
+		if err != nil {
+			return err
+		}
+
+		for _, allowedType := range allowedTypes {
+			if allowedType.GetPublicWildcard() != nil || allowedType.GetRelation() == tuple.Ellipsis {
+				continue
+			}
+
+			warnings = append(warnings, newLintWarning(
+				fmt.Sprintf(
+					"arrow `%s` under permission `%s` walks relation `%s`, which allows subject relation `%s`; the subject relation is ignored by the arrow",
+					arrowString,
+					relation.Name,
+					tuplesetRelation,
+					tuple.JoinRelRef(allowedType.Namespace, allowedType.GetRelation()),
+				),
+				ts.Namespace().Name, relation.Name, childOneof, arrowString,
+			))
+		}
+		return nil
+	})
+	return warnings, err
+}
+
+func walkArrows(rewrite *core.UsersetRewrite, handler func(childOneof *core.SetOperation_Child, tuplesetRelation string, arrowString string) error) error {
+	setOp := setOperationOf(rewrite)
+	if setOp == nil {
+		return nil
+	}
+
+	for _, childOneof := range setOp.Child {
+		switch child := childOneof.ChildType.(type) {
+		case *core.SetOperation_Child_UsersetRewrite:
+			if err := walkArrows(child.UsersetRewrite, handler); err != nil {
+				return err
+			}
+
+		case *core.SetOperation_Child_TupleToUserset:
+			ttu := child.TupleToUserset
+			arrowString := ttu.Tupleset.Relation + "->" + ttu.ComputedUserset.Relation
+			if err := handler(childOneof, ttu.Tupleset.Relation, arrowString); err != nil {
+				return err
+			}
+
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			fttu := child.FunctionedTupleToUserset
+			arrowString := fttu.Tupleset.Relation + "->" + fttu.ComputedUserset.Relation
+			if fttu.Function == core.FunctionedTupleToUserset_FUNCTION_ALL {
+				arrowString = fttu.Tupleset.Relation + ".all(" + fttu.ComputedUserset.Relation + ")"
+			}
+			if err := handler(childOneof, fttu.Tupleset.Relation, arrowString); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// rewriteAlwaysNil returns true if the rewrite is structurally nil, regardless of the
+// relationships written, e.g. `nil`, `viewer & nil` or `nil - viewer`.
+func rewriteAlwaysNil(ts *TypeSystem, rewrite *core.UsersetRewrite, encountered map[string]struct{}) bool {
+	if rewrite == nil {
+		return false
+	}
+
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		for _, child := range rw.Union.Child {
+			if !childAlwaysNil(ts, child, encountered) {
+				return false
+			}
+		}
+		return true
+
+	case *core.UsersetRewrite_Intersection:
+		for _, child := range rw.Intersection.Child {
+			if childAlwaysNil(ts, child, encountered) {
+				return true
+			}
+		}
+		return false
+
+	case *core.UsersetRewrite_Exclusion:
+		return len(rw.Exclusion.Child) > 0 && childAlwaysNil(ts, rw.Exclusion.Child[0], encountered)
+
+	default:
+		return false
+	}
+}
+
+func childAlwaysNil(ts *TypeSystem, childOneof *core.SetOperation_Child, encountered map[string]struct{}) bool {
+	switch child := childOneof.ChildType.(type) {
+	case *core.SetOperation_Child_XNil:
+		return true
+
+	case *core.SetOperation_Child_UsersetRewrite:
+		return rewriteAlwaysNil(ts, child.UsersetRewrite, encountered)
+
+	case *core.SetOperation_Child_ComputedUserset:
+		relationName := child.ComputedUserset.Relation
+		if _, ok := encountered[relationName]; ok {
+			return false
+		}
+		encountered[relationName] = struct{}{}
+		defer delete(encountered, relationName)
+
+		relation, ok := ts.relationMap[relationName]
+		return ok && rewriteAlwaysNil(ts, relation.UsersetRewrite, encountered)
+
+	default:
+		return false
+	}
+}
+
+// relationHasSubjects returns true if any subject type can be found for the relation or
+// permission. Relations or permissions already being computed are assumed to have no subjects,
+// so a negative result is only cached for the top-level call.
+func (sl *schemaLinter) relationHasSubjects(ts *TypeSystem, relationName string, encountered map[string]struct{}) (bool, error) {
+	key := tuple.JoinRelRef(ts.Namespace().Name, relationName)
+	if hasSubjects, ok := sl.hasSubjects[key]; ok {
+		return hasSubjects, nil
+	}
+
+	if _, ok := encountered[key]; ok {
+		return false, nil
+	}
+
+	relation, ok := ts.relationMap[relationName]
+	if !ok {
+		return false, nil
+	}
+
+	encountered[key] = struct{}{}
+	hasSubjects := true
+	if relation.UsersetRewrite != nil {
+		var err error
+		hasSubjects, err = sl.rewriteHasSubjects(ts, relation.UsersetRewrite, encountered)
+		if err != nil {
+			return false, err
+		}
+	}
+	delete(encountered, key)
+
+	if hasSubjects || len(encountered) == 0 {
+		sl.hasSubjects[key] = hasSubjects
+	}
+	return hasSubjects, nil
+}
+
+func (sl *schemaLinter) rewriteHasSubjects(ts *TypeSystem, rewrite *core.UsersetRewrite, encountered map[string]struct{}) (bool, error) {
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		for _, child := range rw.Union.Child {
+			hasSubjects, err := sl.childHasSubjects(ts, child, encountered)
+			if err != nil || hasSubjects {
+				return hasSubjects, err
+			}
+		}
+		return false, nil
+
+	case *core.UsersetRewrite_Intersection:
+		for _, child := range rw.Intersection.Child {
+			hasSubjects, err := sl.childHasSubjects(ts, child, encountered)
+			if err != nil || !hasSubjects {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case *core.UsersetRewrite_Exclusion:
+		if len(rw.Exclusion.Child) == 0 {
+			return false, nil
+		}
+		return sl.childHasSubjects(ts, rw.Exclusion.Child[0], encountered)
+
+	default:
+		return false, fmt.Errorf("unknown rewrite operation %T", rw)
+	}
+}
+
+func (sl *schemaLinter) childHasSubjects(ts *TypeSystem, childOneof *core.SetOperation_Child, encountered map[string]struct{}) (bool, error) {
+	switch child := childOneof.ChildType.(type) {
+	case *core.SetOperation_Child_XThis:
+		return true, nil
+
+	case *core.SetOperation_Child_XNil:
+		return false, nil
+
+	case *core.SetOperation_Child_UsersetRewrite:
+		return sl.rewriteHasSubjects(ts, child.UsersetRewrite, encountered)
+
+	case *core.SetOperation_Child_ComputedUserset:
+		return sl.relationHasSubjects(ts, child.ComputedUserset.Relation, encountered)
+
+	case *core.SetOperation_Child_TupleToUserset:
+		return sl.arrowHasSubjects(ts, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation, encountered)
+
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		return sl.arrowHasSubjects(ts, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation, encountered)
+
+	default:
+		return false, fmt.Errorf("unknown set operation child %T", child)
+	}
+}
+
+func (sl *schemaLinter) arrowHasSubjects(ts *TypeSystem, tuplesetRelation string, computedRelation string, encountered map[string]struct{}) (bool, error) {
+	allowedTypes, err := ts.AllowedDirectRelationsAndWildcards(tuplesetRelation)
+	if err != nil {
+		return false, err
+	}
+
+	for _, allowedType := range allowedTypes {
+		subjectTS, ok := sl.dg.typeSystems[allowedType.Namespace]
+		if !ok || !subjectTS.HasRelation(computedRelation) {
+			continue
+		}
+
+		hasSubjects, err := sl.relationHasSubjects(subjectTS, computedRelation, encountered)
+		if err != nil || hasSubjects {
+			return hasSubjects, err
+		}
+	}
+
+	return false, nil
+}
+
+func setOperationOf(rewrite *core.UsersetRewrite) *core.SetOperation {
+	if rewrite == nil {
+		return nil
+	}
+
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		return rw.Union
+	case *core.UsersetRewrite_Intersection:
+		return rw.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		return rw.Exclusion
+	default:
+		return nil
+	}
+}
+
+func newLintWarning(message string, nsName string, relationName string, withSource nspkg.WithSourcePosition, sourceCodeString string) LintWarning {
+	return LintWarning{
+		Message:          message,
+		DefinitionName:   nsName,
+		RelationName:     relationName,
+		SourceCodeString: sourceCodeString,
+		SourcePosition:   withSource.GetSourcePosition(),
+	}
+}
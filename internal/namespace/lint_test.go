@@ -0,0 +1,228 @@
+package namespace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestLintSchema(t *testing.T) {
+	testCases := []struct {
+		name             string
+		schema           string
+		expectedWarnings []string
+	}{
+		{
+			"no warnings",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+			}`,
+			nil,
+		},
+		{
+			"unreferenced relation",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				relation owner: user
+				permission view = viewer
+			}`,
+			[]string{
+				"relation `owner` is not referenced by any permission (line 5, column 5)",
+			},
+		},
+		{
+			"relation referenced through another definition",
+			`definition user {}
+
+			definition group {
+				relation member: user
+			}
+
+			definition folder {
+				relation viewer: user
+				permission view = viewer
+			}
+
+			definition document {
+				relation parent: folder
+				relation viewer: group#member
+				permission view = viewer + parent->view
+			}`,
+			nil,
+		},
+		{
+			"relation referenced only by other relations",
+			`definition user {}
+
+			definition group {
+				relation member: user
+			}
+
+			definition document {
+				relation viewer: group#member
+			}`,
+			[]string{
+				"relation `member` is not referenced by any permission (line 4, column 5)",
+				"relation `viewer` is not referenced by any permission (line 8, column 5)",
+			},
+		},
+		{
+			"arrow over subject relation",
+			`definition user {}
+
+			definition group {
+				relation member: user
+				permission view = member
+			}
+
+			definition document {
+				relation owner: group#member | group
+				permission view = owner->view
+			}`,
+			[]string{
+				"arrow `owner->view` under permission `view` walks relation `owner`, which allows subject relation `group#member`; the subject relation is ignored by the arrow (line 10, column 23)",
+			},
+		},
+		{
+			"all arrow over subject relation",
+			`definition user {}
+
+			definition group {
+				relation member: user
+				permission view = member
+			}
+
+			definition document {
+				relation owner: group#member
+				permission view = owner.all(view)
+			}`,
+			[]string{
+				"arrow `owner.all(view)` under permission `view` walks relation `owner`, which allows subject relation `group#member`; the subject relation is ignored by the arrow (line 10, column 23)",
+			},
+		},
+		{
+			"permissions always nil",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+				permission nothing = nil
+				permission intersected = view & nil
+				permission excluded = nil - view
+				permission via_nothing = nothing + nil
+				permission not_nil = view + nothing
+			}`,
+			[]string{
+				"permission `nothing` always evaluates to nil (line 6, column 5)",
+				"permission `intersected` always evaluates to nil (line 7, column 5)",
+				"permission `excluded` always evaluates to nil (line 8, column 5)",
+				"permission `via_nothing` always evaluates to nil (line 9, column 5)",
+			},
+		},
+		{
+			"permissions not reachable",
+			`definition user {}
+
+			definition folder {
+				relation viewer: user
+				permission view = viewer
+			}
+
+			definition document {
+				relation parent: folder
+				relation viewer: user
+				permission view = viewer
+				permission edit = parent->edit
+				permission recursive = recursive + parent->view & recursive
+			}`,
+			[]string{
+				"permission `edit` cannot be reached by any subject type (line 12, column 5)",
+				"permission `recursive` cannot be reached by any subject type (line 13, column 5)",
+			},
+		},
+		{
+			"recursive permission reachable",
+			`definition user {}
+
+			definition folder {
+				relation parent: folder
+				relation viewer: user
+				permission view = viewer + parent->view
+			}`,
+			nil,
+		},
+		{
+			"naming conventions",
+			`definition user {}
+
+			definition test/document {
+				relation document_viewer: user
+				relation owner_document: user
+				relation banned__user: user
+				permission view = document_viewer + owner_document - banned__user
+			}`,
+			[]string{
+				"`document_viewer` repeats the name of its definition `test/document`; consider renaming it to `viewer` (line 4, column 5)",
+				"`owner_document` repeats the name of its definition `test/document`; consider renaming it to `owner` (line 5, column 5)",
+				"`banned__user` contains consecutive underscores (line 6, column 5)",
+			},
+		},
+		{
+			"definition naming",
+			`definition some__user {
+				relation parent: some__user
+				permission view = parent->view
+			}`,
+			[]string{
+				"definition `some__user` contains consecutive underscores (line 1, column 1)",
+				"permission `view` cannot be reached by any subject type (line 3, column 5)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			empty := ""
+			compiled, err := compiler.Compile(compiler.InputSchema{
+				Source:       input.Source("schema"),
+				SchemaString: tc.schema,
+			}, &empty)
+			require.NoError(err)
+
+			typeSystems := make([]*TypeSystem, 0, len(compiled.ObjectDefinitions))
+			for _, nsDef := range compiled.ObjectDefinitions {
+				ts, err := NewNamespaceTypeSystem(nsDef, ResolverForPredefinedDefinitions(PredefinedElements{
+					Namespaces: compiled.ObjectDefinitions,
+					Caveats:    compiled.CaveatDefinitions,
+				}))
+				require.NoError(err)
+
+				_, err = ts.Validate(context.Background())
+				require.NoError(err)
+				typeSystems = append(typeSystems, ts)
+			}
+
+			warnings, err := LintSchema(typeSystems)
+			require.NoError(err)
+
+			var warningStrings []string
+			for _, warning := range warnings {
+				warningStrings = append(warningStrings, warning.String())
+			}
+			require.Equal(tc.expectedWarnings, warningStrings)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package namespace
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -62,6 +63,18 @@ func (re ReachabilityEntrypoint) IsDirectResult() bool {
 	return re.re.ResultStatus == core.ReachabilityEntrypoint_DIRECT_OPERATION_RESULT
 }
 
+// sortKey returns a key uniquely identifying the entrypoint, used to deduplicate and order
+// entrypoints deterministically.
+func (re ReachabilityEntrypoint) sortKey() string {
+	return fmt.Sprintf("%s|%d|%s|%s|%d",
+		tuple.StringRR(re.parentRelation),
+		re.re.Kind,
+		tuple.StringRR(re.re.TargetRelation),
+		re.re.TuplesetRelation,
+		re.re.ResultStatus,
+	)
+}
+
 // ReachabilityGraphFor returns a reachability graph for the given namespace.
 func ReachabilityGraphFor(ts *ValidatedNamespaceTypeSystem) *ReachabilityGraph {
 	return &ReachabilityGraph{ts.TypeSystem, sync.Map{}, sync.Map{}}
@@ -137,7 +150,28 @@ func (rg *ReachabilityGraph) entrypointsForSubjectToResource(
 
 	collected := &[]ReachabilityEntrypoint{}
 	err := rg.collectEntrypoints(ctx, subjectType, resourceType, collected, map[string]struct{}{}, reachabilityOption, entrypointLookupOption)
-	return *collected, err
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the entrypoints deduplicated and in a stable order, as callers (such as reachable
+	// resources) rely on walking the entrypoints in the same order across calls.
+	sorted := make([]ReachabilityEntrypoint, 0, len(*collected))
+	encountered := make(map[string]struct{}, len(*collected))
+	for _, entrypoint := range *collected {
+		key := entrypoint.sortKey()
+		if _, ok := encountered[key]; ok {
+			continue
+		}
+
+		encountered[key] = struct{}{}
+		sorted = append(sorted, entrypoint)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].sortKey() < sorted[j].sortKey()
+	})
+	return sorted, nil
 }
 
 func (rg *ReachabilityGraph) getOrBuildGraph(ctx context.Context, resourceType *core.RelationReference, reachabilityOption reachabilityOption) (*core.ReachabilityGraph, error) {
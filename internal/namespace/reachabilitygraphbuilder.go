@@ -97,60 +97,22 @@ func computeRewriteOpReachability(ctx context.Context, children []*core.SetOpera
 			}
 
 		case *core.SetOperation_Child_TupleToUserset:
-			tuplesetRelation := child.TupleToUserset.Tupleset.Relation
-			directRelationTypes, err := ts.AllowedDirectRelationsAndWildcards(tuplesetRelation)
+			err := addArrowEntrypoints(ctx, graph, rr, child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation, operationResultState, ts)
 			if err != nil {
 				return err
 			}
 
-			computedUsersetRelation := child.TupleToUserset.ComputedUserset.Relation
-			for _, allowedRelationType := range directRelationTypes {
-				// For each namespace allowed to be found on the right hand side of the
-				// tupleset relation, include the *computed userset* relation as an entrypoint.
-				//
-				// For example, given a schema:
-				//
-				// ```
-				// definition user {}
-				//
-				// definition parent1 {
-				//   relation somerel: user
-				// }
-				//
-				// definition parent2 {
-				//   relation somerel: user
-				// }
-				//
-				// definition child {
-				//   relation parent: parent1 | parent2
-				//   permission someperm = parent->somerel
-				// }
-				// ```
-				//
-				// We will add an entrypoint for the arrow itself, keyed to the relation type
-				// included from the computed userset.
-				//
-				// Using the above example, this will add entrypoints for `parent1#somerel`
-				// and `parent2#somerel`, which are the subjects reached after resolving the
-				// right side of the arrow.
-
-				// Check if the relation does exist on the allowed type, and only add the entrypoint if present.
-				relTypeSystem, err := ts.typeSystemForNamespace(ctx, allowedRelationType.Namespace)
-				if err != nil {
-					return err
-				}
+		case *core.SetOperation_Child_FunctionedTupleToUserset:
+			// An `all` arrow requires every subject of the tupleset to have the computed userset,
+			// so reaching one of them does not make the resource a direct result.
+			resultState := operationResultState
+			if child.FunctionedTupleToUserset.Function == core.FunctionedTupleToUserset_FUNCTION_ALL {
+				resultState = core.ReachabilityEntrypoint_REACHABLE_CONDITIONAL_RESULT
+			}
 
-				if relTypeSystem.HasRelation(computedUsersetRelation) {
-					err := addSubjectEntrypoint(graph, allowedRelationType.Namespace, computedUsersetRelation, &core.ReachabilityEntrypoint{
-						Kind:             core.ReachabilityEntrypoint_TUPLESET_TO_USERSET_ENTRYPOINT,
-						TargetRelation:   rr,
-						ResultStatus:     operationResultState,
-						TuplesetRelation: tuplesetRelation,
-					})
-					if err != nil {
-						return err
-					}
-				}
+			err := addArrowEntrypoints(ctx, graph, rr, child.FunctionedTupleToUserset.Tupleset.Relation, child.FunctionedTupleToUserset.ComputedUserset.Relation, resultState, ts)
+			if err != nil {
+				return err
 			}
 
 		case *core.SetOperation_Child_XNil:
@@ -165,6 +127,66 @@ func computeRewriteOpReachability(ctx context.Context, children []*core.SetOpera
 	return nil
 }
 
+// addArrowEntrypoints adds the entrypoints for an arrow from the tupleset relation to the
+// computed userset relation.
+func addArrowEntrypoints(ctx context.Context, graph *core.ReachabilityGraph, rr *core.RelationReference, tuplesetRelation string, computedUsersetRelation string, operationResultState core.ReachabilityEntrypoint_EntrypointResultStatus, ts *TypeSystem) error {
+	directRelationTypes, err := ts.AllowedDirectRelationsAndWildcards(tuplesetRelation)
+	if err != nil {
+		return err
+	}
+
+	for _, allowedRelationType := range directRelationTypes {
+		// For each namespace allowed to be found on the right hand side of the
+		// tupleset relation, include the *computed userset* relation as an entrypoint.
+		//
+		// For example, given a schema:
+		//
+		// ```
+		// definition user {}
+		//
+		// definition parent1 {
+		//   relation somerel: user
+		// }
+		//
+		// definition parent2 {
+		//   relation somerel: user
+		// }
+		//
+		// definition child {
+		//   relation parent: parent1 | parent2
+		//   permission someperm = parent->somerel
+		// }
+		// ```
+		//
+		// We will add an entrypoint for the arrow itself, keyed to the relation type
+		// included from the computed userset.
+		//
+		// Using the above example, this will add entrypoints for `parent1#somerel`
+		// and `parent2#somerel`, which are the subjects reached after resolving the
+		// right side of the arrow.
+
+		// Check if the relation does exist on the allowed type, and only add the entrypoint if present.
+		relTypeSystem, err := ts.typeSystemForNamespace(ctx, allowedRelationType.Namespace)
+		if err != nil {
+			return err
+		}
+
+		if relTypeSystem.HasRelation(computedUsersetRelation) {
+			err := addSubjectEntrypoint(graph, allowedRelationType.Namespace, computedUsersetRelation, &core.ReachabilityEntrypoint{
+				Kind:             core.ReachabilityEntrypoint_TUPLESET_TO_USERSET_ENTRYPOINT,
+				TargetRelation:   rr,
+				ResultStatus:     operationResultState,
+				TuplesetRelation: tuplesetRelation,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func addSubjectEntrypoint(graph *core.ReachabilityGraph, namespaceName string, relationName string, entrypoint *core.ReachabilityEntrypoint) error {
 	key := tuple.JoinRelRef(namespaceName, relationName)
 	if relationName == "" {
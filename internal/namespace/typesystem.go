@@ -346,50 +346,9 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 					)
 				}
 			case *core.SetOperation_Child_TupleToUserset:
-				ttu := child.TupleToUserset
-				if ttu == nil {
-					return nil
-				}
-
-				tupleset := ttu.GetTupleset()
-				if tupleset == nil {
-					return nil
-				}
-
-				relationName := tupleset.GetRelation()
-				found, ok := nts.relationMap[relationName]
-				if !ok {
-					return newTypeErrorWithSource(
-						NewRelationNotFoundErr(nts.nsDef.Name, relationName),
-						childOneof,
-						relationName,
-					)
-				}
-
-				if nspkg.GetRelationKind(found) == iv1.RelationMetadata_PERMISSION {
-					return newTypeErrorWithSource(
-						NewPermissionUsedOnLeftOfArrowErr(nts.nsDef.Name, relation.Name, relationName),
-						childOneof, relationName)
-				}
-
-				// Ensure the tupleset relation doesn't itself import wildcard.
-				referencedWildcard, err := nts.ReferencesWildcardType(ctx, relationName)
-				if err != nil {
-					return err
-				}
-
-				if referencedWildcard != nil {
-					return newTypeErrorWithSource(
-						NewWildcardUsedInArrowErr(
-							nts.nsDef.Name,
-							relation.Name,
-							relationName,
-							referencedWildcard.WildcardType.GetNamespace(),
-							tuple.StringRR(referencedWildcard.ReferencingRelation),
-						),
-						childOneof, relationName,
-					)
-				}
+				return nts.validateArrow(ctx, relation, childOneof, child.TupleToUserset.GetTupleset())
+			case *core.SetOperation_Child_FunctionedTupleToUserset:
+				return nts.validateArrow(ctx, relation, childOneof, child.FunctionedTupleToUserset.GetTupleset())
 			}
 			return nil
 		})
@@ -554,6 +513,50 @@ func (nts *TypeSystem) typeSystemForNamespace(ctx context.Context, namespaceName
 	return NewNamespaceTypeSystem(nsDef, nts.resolver)
 }
 
+// validateArrow validates the tupleset relation of an arrow found in the relation.
+func (nts *TypeSystem) validateArrow(ctx context.Context, relation *core.Relation, childOneof *core.SetOperation_Child, tupleset *core.TupleToUserset_Tupleset) error {
+	if tupleset == nil {
+		return nil
+	}
+
+	relationName := tupleset.GetRelation()
+	found, ok := nts.relationMap[relationName]
+	if !ok {
+		return newTypeErrorWithSource(
+			NewRelationNotFoundErr(nts.nsDef.Name, relationName),
+			childOneof,
+			relationName,
+		)
+	}
+
+	if nspkg.GetRelationKind(found) == iv1.RelationMetadata_PERMISSION {
+		return newTypeErrorWithSource(
+			NewPermissionUsedOnLeftOfArrowErr(nts.nsDef.Name, relation.Name, relationName),
+			childOneof, relationName)
+	}
+
+	// Ensure the tupleset relation doesn't itself import wildcard.
+	referencedWildcard, err := nts.ReferencesWildcardType(ctx, relationName)
+	if err != nil {
+		return err
+	}
+
+	if referencedWildcard != nil {
+		return newTypeErrorWithSource(
+			NewWildcardUsedInArrowErr(
+				nts.nsDef.Name,
+				relation.Name,
+				relationName,
+				referencedWildcard.WildcardType.GetNamespace(),
+				tuple.StringRR(referencedWildcard.ReferencingRelation),
+			),
+			childOneof, relationName,
+		)
+	}
+
+	return nil
+}
+
 // ValidatedNamespaceTypeSystem is validated type system for a namespace.
 type ValidatedNamespaceTypeSystem struct {
 	*TypeSystem
@@ -562,6 +565,12 @@ type ValidatedNamespaceTypeSystem struct {
 func newTypeErrorWithSource(wrapped error, withSource nspkg.WithSourcePosition, sourceCodeString string) error {
 	sourcePosition := withSource.GetSourcePosition()
 	if sourcePosition != nil {
+		// Line numbers are relative to the file in which the element was defined, so errors in
+		// imported files name the file.
+		if sourcePosition.SourceFile != "" {
+			wrapped = fmt.Errorf("%w (in imported file `%s`)", wrapped, sourcePosition.SourceFile)
+		}
+
 		return asTypeError(spiceerrors.NewErrorWithSource(
 			wrapped,
 			sourceCodeString,
@@ -81,6 +81,22 @@ func TestTypeSystem(t *testing.T) {
 			nil,
 			"under permission `viewer` under definition `document`: permissions cannot be used on the left hand side of an arrow (found `editor`)",
 		},
+		{
+			"use of permission in functioned tuple_to_userset",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("owner", nil),
+				ns.MustRelation("editor", ns.Union(
+					ns.ComputedUserset("owner"),
+				)),
+				ns.MustRelation("viewer", ns.Union(
+					ns.MustFunctionedTupleToUserset("editor", "all", "viewer"),
+				)),
+			),
+			[]*core.NamespaceDefinition{},
+			nil,
+			"under permission `viewer` under definition `document`: permissions cannot be used on the left hand side of an arrow (found `editor`)",
+		},
 		{
 			"rewrite without this and types",
 			ns.Namespace(
@@ -201,6 +217,21 @@ func TestTypeSystem(t *testing.T) {
 			nil,
 			"for arrow under permission `viewer`: relation `folder#parent` includes wildcard type `folder` via relation `folder#parent`: wildcard relations cannot be used on the left side of arrows",
 		},
+		{
+			"functioned ttu wildcard type check",
+			ns.Namespace(
+				"folder",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "..."), ns.AllowedPublicNamespace("folder")),
+				ns.MustRelation("viewer", ns.Union(
+					ns.MustFunctionedTupleToUserset("parent", "all", "viewer"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			nil,
+			"for arrow under permission `viewer`: relation `folder#parent` includes wildcard type `folder` via relation `folder#parent`: wildcard relations cannot be used on the left side of arrows",
+		},
 		{
 			"recursive transitive wildcard type check",
 			ns.Namespace(
@@ -0,0 +1,270 @@
+package relationships
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+var (
+	quotaUsageGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "relationships",
+		Name:      "quota_usage",
+		Help:      "The number of relationships stored against each configured relationship quota, as of the last write checked against it",
+	}, []string{"definition", "relation"})
+
+	quotaLimitGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "relationships",
+		Name:      "quota_limit",
+		Help:      "The maximum number of relationships allowed by each configured relationship quota",
+	}, []string{"definition", "relation"})
+)
+
+// Quota limits the number of relationships which can be stored for a definition, or for a
+// single relation of a definition.
+type Quota struct {
+	// Definition is the name of the definition whose relationships are limited.
+	Definition string
+
+	// Relation is the name of the relation whose relationships are limited. If empty, the
+	// relationships of all relations of the definition are counted together.
+	Relation string
+
+	// Limit is the maximum number of relationships allowed.
+	Limit uint64
+}
+
+// ParseQuota parses a quota of the form `definition=limit` or `definition#relation=limit`.
+func ParseQuota(value string) (Quota, error) {
+	target, limitStr, ok := strings.Cut(value, "=")
+	if !ok {
+		return Quota{}, fmt.Errorf("invalid relationship quota `%s`: expected `definition=limit` or `definition#relation=limit`", value)
+	}
+
+	limit, err := strconv.ParseUint(strings.TrimSpace(limitStr), 10, 64)
+	if err != nil {
+		return Quota{}, fmt.Errorf("invalid limit for relationship quota `%s`: %w", value, err)
+	}
+
+	definition, relation, _ := strings.Cut(strings.TrimSpace(target), "#")
+	if definition == "" {
+		return Quota{}, fmt.Errorf("invalid relationship quota `%s`: missing definition", value)
+	}
+
+	return Quota{Definition: definition, Relation: relation, Limit: limit}, nil
+}
+
+// String returns the quota in the form parsed by ParseQuota.
+func (q Quota) String() string {
+	return fmt.Sprintf("%s=%d", q.target(), q.Limit)
+}
+
+func (q Quota) target() string {
+	if q.Relation == "" {
+		return q.Definition
+	}
+	return q.Definition + "#" + q.Relation
+}
+
+func (q Quota) appliesTo(namespace, relation string) bool {
+	return q.Definition == namespace && (q.Relation == "" || q.Relation == relation)
+}
+
+// usage returns the number of relationships counted against the quota.
+func (q Quota) usage(counts map[datastore.RelationKey]uint64) uint64 {
+	if q.Relation != "" {
+		return counts[datastore.RelationKey{Definition: q.Definition, Relation: q.Relation}]
+	}
+
+	var total uint64
+	for key, count := range counts {
+		if key.Definition == q.Definition {
+			total += count
+		}
+	}
+	return total
+}
+
+// WriteWithQuotas applies the updates in the transaction, returning an
+// ErrRelationshipQuotaExceeded if the updates increase the number of relationships counted
+// against any of the quotas beyond its limit. Writes which do not increase the usage of a quota,
+// such as those deleting relationships, are allowed even if the quota is already exceeded.
+//
+// The counts are those maintained by the datastore, and so include relationships which have
+// expired but not yet been removed. Only the counts of the definitions of applicable quotas are
+// read, and so maintained by the datastore.
+func WriteWithQuotas(ctx context.Context, rwt datastore.ReadWriteTransaction, quotas []Quota, updates []*core.RelationTupleUpdate) error {
+	applicable := make([]Quota, 0, len(quotas))
+	var definitions []string
+	for _, quota := range quotas {
+		for _, update := range updates {
+			if update.Operation != core.RelationTupleUpdate_DELETE &&
+				quota.appliesTo(update.Tuple.ResourceAndRelation.Namespace, update.Tuple.ResourceAndRelation.Relation) {
+				applicable = append(applicable, quota)
+				if !slices.Contains(definitions, quota.Definition) {
+					definitions = append(definitions, quota.Definition)
+				}
+				break
+			}
+		}
+	}
+
+	if len(applicable) == 0 {
+		return rwt.WriteRelationships(ctx, updates)
+	}
+
+	before, err := rwt.RelationshipCounts(ctx, definitions...)
+	if err != nil {
+		return err
+	}
+
+	if err := rwt.WriteRelationships(ctx, updates); err != nil {
+		return err
+	}
+
+	after, err := rwt.RelationshipCounts(ctx, definitions...)
+	if err != nil {
+		return err
+	}
+
+	for _, quota := range applicable {
+		usage := quota.usage(after)
+		quotaUsageGauge.WithLabelValues(quota.Definition, quota.Relation).Set(float64(usage))
+		quotaLimitGauge.WithLabelValues(quota.Definition, quota.Relation).Set(float64(quota.Limit))
+
+		if usage > quota.Limit && usage > quota.usage(before) {
+			return NewRelationshipQuotaExceededErr(quota, usage)
+		}
+	}
+
+	return nil
+}
+
+// BulkLoadQuotaChecker checks the relationships created by a bulk load against quotas, batch by
+// batch, as they are read from the source. Datastores update the maintained counts only once the
+// load completes, so the checker adds the relationships of each batch to the counts read before
+// the load started.
+type BulkLoadQuotaChecker struct {
+	quotas []Quota
+	counts map[datastore.RelationKey]uint64
+}
+
+// NewBulkLoadQuotaChecker reads the counts of the definitions of the quotas from the transaction,
+// and so must be called before the bulk load is started.
+func NewBulkLoadQuotaChecker(ctx context.Context, rwt datastore.ReadWriteTransaction, quotas []Quota) (*BulkLoadQuotaChecker, error) {
+	if len(quotas) == 0 {
+		return &BulkLoadQuotaChecker{}, nil
+	}
+
+	var definitions []string
+	for _, quota := range quotas {
+		if !slices.Contains(definitions, quota.Definition) {
+			definitions = append(definitions, quota.Definition)
+		}
+	}
+
+	counts, err := rwt.RelationshipCounts(ctx, definitions...)
+	if err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		counts = make(map[datastore.RelationKey]uint64)
+	}
+
+	return &BulkLoadQuotaChecker{quotas: quotas, counts: counts}, nil
+}
+
+// CheckBatch adds the relationships of a batch to be loaded to the counts, returning an
+// ErrRelationshipQuotaExceeded if they increase the number of relationships counted against any
+// of the quotas beyond its limit. A bulk load only creates relationships, so each relationship
+// of the batch increases the usage of the quotas which apply to it.
+func (c *BulkLoadQuotaChecker) CheckBatch(batch []*core.RelationTuple) error {
+	if len(c.quotas) == 0 {
+		return nil
+	}
+
+	applicable := make([]Quota, 0, len(c.quotas))
+	for _, quota := range c.quotas {
+		for _, rt := range batch {
+			if quota.appliesTo(rt.ResourceAndRelation.Namespace, rt.ResourceAndRelation.Relation) {
+				applicable = append(applicable, quota)
+				break
+			}
+		}
+	}
+
+	for _, rt := range batch {
+		c.counts[datastore.RelationKey{Definition: rt.ResourceAndRelation.Namespace, Relation: rt.ResourceAndRelation.Relation}]++
+	}
+
+	for _, quota := range applicable {
+		usage := quota.usage(c.counts)
+		quotaUsageGauge.WithLabelValues(quota.Definition, quota.Relation).Set(float64(usage))
+		quotaLimitGauge.WithLabelValues(quota.Definition, quota.Relation).Set(float64(quota.Limit))
+
+		if usage > quota.Limit {
+			return NewRelationshipQuotaExceededErr(quota, usage)
+		}
+	}
+
+	return nil
+}
+
+// ErrRelationshipQuotaExceeded indicates that a write would have stored more relationships
+// than allowed by a quota.
+type ErrRelationshipQuotaExceeded struct {
+	error
+	quota Quota
+	count uint64
+}
+
+// NewRelationshipQuotaExceededErr constructs a new error for a write exceeding a relationship
+// quota.
+func NewRelationshipQuotaExceededErr(quota Quota, count uint64) ErrRelationshipQuotaExceeded {
+	return ErrRelationshipQuotaExceeded{
+		error: fmt.Errorf(
+			"relationship quota exceeded: `%s` is limited to %d relationships, but the write would store %d",
+			quota.target(),
+			quota.Limit,
+			count,
+		),
+		quota: quota,
+		count: count,
+	}
+}
+
+// Quota returns the quota which was exceeded.
+func (err ErrRelationshipQuotaExceeded) Quota() Quota {
+	return err.quota
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrRelationshipQuotaExceeded) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.ResourceExhausted,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+			map[string]string{
+				"definition_name":    err.quota.Definition,
+				"relation_name":      err.quota.Relation,
+				"quota_limit":        strconv.FormatUint(err.quota.Limit, 10),
+				"relationship_count": strconv.FormatUint(err.count, 10),
+			},
+		),
+	)
+}
@@ -0,0 +1,135 @@
+package relationships
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestParseQuota(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expected      Quota
+		expectedError string
+	}{
+		{"document=10", Quota{Definition: "document", Limit: 10}, ""},
+		{"document#viewer=0", Quota{Definition: "document", Relation: "viewer", Limit: 0}, ""},
+		{" document#viewer = 5", Quota{Definition: "document", Relation: "viewer", Limit: 5}, ""},
+		{"document", Quota{}, "expected `definition=limit`"},
+		{"document=ten", Quota{}, "invalid limit"},
+		{"document=-1", Quota{}, "invalid limit"},
+		{"#viewer=10", Quota{}, "missing definition"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			quota, err := ParseQuota(tc.value)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, quota)
+
+			roundTripped, err := ParseQuota(quota.String())
+			require.NoError(t, err)
+			require.Equal(t, quota, roundTripped)
+		})
+	}
+}
+
+func TestQuotaUsage(t *testing.T) {
+	counts := map[datastore.RelationKey]uint64{
+		{Definition: "document", Relation: "viewer"}: 3,
+		{Definition: "document", Relation: "owner"}:  2,
+		{Definition: "folder", Relation: "viewer"}:   7,
+	}
+
+	require.Equal(t, uint64(5), Quota{Definition: "document"}.usage(counts))
+	require.Equal(t, uint64(3), Quota{Definition: "document", Relation: "viewer"}.usage(counts))
+	require.Equal(t, uint64(0), Quota{Definition: "document", Relation: "editor"}.usage(counts))
+	require.Equal(t, uint64(0), Quota{Definition: "user"}.usage(counts))
+}
+
+func TestWriteWithQuotas(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+	t.Cleanup(func() { ds.Close() })
+
+	// The standard data has one document viewer and eight folder relationships.
+	quotas := []Quota{{Definition: "document", Relation: "viewer", Limit: 1}, {Definition: "folder", Limit: 8}}
+	write := func(updates ...*core.RelationTupleUpdate) error {
+		_, err := ds.ReadWriteTx(context.Background(), func(rwt datastore.ReadWriteTransaction) error {
+			return WriteWithQuotas(context.Background(), rwt, quotas, updates)
+		})
+		return err
+	}
+
+	var quotaErr ErrRelationshipQuotaExceeded
+	err = write(tuple.Touch(tuple.MustParse("document:healthplan#viewer@user:newviewer")))
+	require.True(errors.As(err, &quotaErr))
+	require.Equal(quotas[0], quotaErr.Quota())
+
+	// Relations not limited by a quota can be written.
+	require.NoError(write(tuple.Touch(tuple.MustParse("document:healthplan#owner@user:newowner"))))
+
+	// Shrink the folder quota below its usage: deletes are still allowed, while creates are not.
+	quotas[1].Limit = 5
+	require.NoError(write(tuple.Delete(tuple.MustParse("folder:isolated#viewer@user:villain"))))
+
+	err = write(tuple.Create(tuple.MustParse("folder:newfolder#viewer@user:newviewer")))
+	require.True(errors.As(err, &quotaErr))
+	require.Equal(quotas[1], quotaErr.Quota())
+
+	// Replacing a relationship does not increase the usage, and so is allowed while over quota.
+	require.NoError(write(
+		tuple.Delete(tuple.MustParse("folder:plans#viewer@user:chief_financial_officer")),
+		tuple.Create(tuple.MustParse("folder:plans#viewer@user:newviewer")),
+	))
+}
+
+func TestBulkLoadQuotaChecker(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+	t.Cleanup(func() { ds.Close() })
+
+	quotas := []Quota{{Definition: "document", Relation: "viewer", Limit: 3}}
+	_, err = ds.ReadWriteTx(context.Background(), func(rwt datastore.ReadWriteTransaction) error {
+		checker, err := NewBulkLoadQuotaChecker(context.Background(), rwt, quotas)
+		require.NoError(err)
+
+		// The standard data has one document viewer.
+		require.NoError(checker.CheckBatch([]*core.RelationTuple{
+			tuple.MustParse("document:healthplan#viewer@user:first"),
+			tuple.MustParse("document:healthplan#owner@user:first"),
+		}))
+		require.NoError(checker.CheckBatch([]*core.RelationTuple{
+			tuple.MustParse("document:healthplan#viewer@user:second"),
+		}))
+
+		var quotaErr ErrRelationshipQuotaExceeded
+		err = checker.CheckBatch([]*core.RelationTuple{
+			tuple.MustParse("document:healthplan#viewer@user:third"),
+		})
+		require.True(errors.As(err, &quotaErr))
+		require.ErrorContains(err, "`document#viewer` is limited to 3 relationships, but the write would store 4")
+		return nil
+	})
+	require.NoError(err)
+}
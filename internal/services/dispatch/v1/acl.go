@@ -53,9 +53,12 @@ func (ds *dispatchServer) DispatchExpand(ctx context.Context, req *dispatchv1.Di
 	return resp, rewriteGraphError(ctx, err)
 }
 
-func (ds *dispatchServer) DispatchLookup(ctx context.Context, req *dispatchv1.DispatchLookupRequest) (*dispatchv1.DispatchLookupResponse, error) {
-	resp, err := ds.localDispatch.DispatchLookup(ctx, req)
-	return resp, rewriteGraphError(ctx, err)
+func (ds *dispatchServer) DispatchLookupResources(
+	req *dispatchv1.DispatchLookupResourcesRequest,
+	resp dispatchv1.DispatchService_DispatchLookupResourcesServer,
+) error {
+	return ds.localDispatch.DispatchLookupResources(req,
+		dispatch.WrapGRPCStream[*dispatchv1.DispatchLookupResourcesResponse](resp))
 }
 
 func (ds *dispatchServer) DispatchReachableResources(
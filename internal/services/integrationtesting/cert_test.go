@@ -148,8 +148,8 @@ func TestCertRotation(t *testing.T) {
 					},
 					{
 						Name:                "consistency",
-						UnaryMiddleware:     consistency.UnaryServerInterceptor(),
-						StreamingMiddleware: consistency.StreamServerInterceptor(),
+						UnaryMiddleware:     consistency.UnaryServerInterceptor(nil),
+						StreamingMiddleware: consistency.StreamServerInterceptor(nil),
 					},
 					{
 						Name:                "servicespecific",
@@ -194,7 +194,7 @@ func TestCertRotation(t *testing.T) {
 	_, err = client.CheckPermission(ctx, &v1.CheckPermissionRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:   rel.Resource,
@@ -247,7 +247,7 @@ func TestCertRotation(t *testing.T) {
 		_, err = client.CheckPermission(ctx, &v1.CheckPermissionRequest{
 			Consistency: &v1.Consistency{
 				Requirement: &v1.Consistency_AtLeastAsFresh{
-					AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+					AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 				},
 			},
 			Resource:   rel.Resource,
@@ -74,7 +74,7 @@ func (v1st v1ServiceTester) Check(ctx context.Context, resource *core.ObjectAndR
 		},
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, atRevision),
 			},
 		},
 		Context: context,
@@ -94,7 +94,7 @@ func (v1st v1ServiceTester) Expand(ctx context.Context, resource *core.ObjectAnd
 		Permission: resource.Relation,
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, atRevision),
 			},
 		},
 	})
@@ -124,7 +124,7 @@ func (v1st v1ServiceTester) Read(_ context.Context, namespaceName string, atRevi
 		},
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), atRevision),
 			},
 		},
 	})
@@ -162,7 +162,7 @@ func (v1st v1ServiceTester) LookupResources(_ context.Context, resourceRelation
 		},
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), atRevision),
 			},
 		},
 	})
@@ -206,7 +206,7 @@ func (v1st v1ServiceTester) LookupSubjects(_ context.Context, resource *core.Obj
 		OptionalSubjectRelation: optionalizeRelation(subjectRelation.Relation),
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), atRevision),
 			},
 		},
 		Context: builtContext,
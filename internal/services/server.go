@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"google.golang.org/grpc"
@@ -10,6 +12,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/services/health"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
 )
 
 // SchemaServiceOption defines the options for enabling or disabling the V1 Schema service.
@@ -52,20 +55,33 @@ func RegisterGrpcServices(
 	schemaServiceOption SchemaServiceOption,
 	watchServiceOption WatchServiceOption,
 	permSysConfig v1svc.PermissionsServerConfig,
+	watchHeartbeat time.Duration,
 ) {
 	healthManager.RegisterReportedService(OverallServerHealthCheckKey)
 
 	v1.RegisterPermissionsServiceServer(srv, v1svc.NewPermissionsServer(dispatch, permSysConfig))
 	healthManager.RegisterReportedService(v1.PermissionsService_ServiceDesc.ServiceName)
 
+	v1.RegisterExperimentalServiceServer(srv, v1svc.NewExperimentalServer(dispatch, permSysConfig))
+	healthManager.RegisterReportedService(v1.ExperimentalService_ServiceDesc.ServiceName)
+
+	experimentalv1.RegisterExperimentalAdminServiceServer(srv, v1svc.NewExperimentalAdminServer())
+	healthManager.RegisterReportedService(experimentalv1.ExperimentalAdminService_ServiceDesc.ServiceName)
+
 	if watchServiceOption == WatchServiceEnabled {
-		v1.RegisterWatchServiceServer(srv, v1svc.NewWatchServer())
+		v1.RegisterWatchServiceServer(srv, v1svc.NewWatchServer(watchHeartbeat))
 		healthManager.RegisterReportedService(v1.WatchService_ServiceDesc.ServiceName)
+
+		experimentalv1.RegisterExperimentalWatchServiceServer(srv, v1svc.NewExperimentalWatchServer(watchHeartbeat))
+		healthManager.RegisterReportedService(experimentalv1.ExperimentalWatchService_ServiceDesc.ServiceName)
 	}
 
 	if schemaServiceOption == V1SchemaServiceEnabled || schemaServiceOption == V1SchemaServiceAdditiveOnly {
 		v1.RegisterSchemaServiceServer(srv, v1svc.NewSchemaServer(schemaServiceOption == V1SchemaServiceAdditiveOnly))
 		healthManager.RegisterReportedService(v1.SchemaService_ServiceDesc.ServiceName)
+
+		experimentalv1.RegisterExperimentalSchemaServiceServer(srv, v1svc.NewExperimentalSchemaServer(schemaServiceOption == V1SchemaServiceAdditiveOnly))
+		healthManager.RegisterReportedService(experimentalv1.ExperimentalSchemaService_ServiceDesc.ServiceName)
 	}
 
 	healthpb.RegisterHealthServer(srv, healthManager.HealthSvc())
@@ -19,9 +19,16 @@ type ValidatedSchemaChanges struct {
 	compiled          *compiler.CompiledSchema
 	newCaveatDefNames *util.Set[string]
 	newObjectDefNames *util.Set[string]
+	typeSystems       []*namespace.TypeSystem
 	additiveOnly      bool
 }
 
+// Lint lints the validated schema, returning the warnings found, if any. Warnings do not prevent
+// the schema changes from being applied.
+func (vsc *ValidatedSchemaChanges) Lint() ([]namespace.LintWarning, error) {
+	return namespace.LintSchema(vsc.typeSystems)
+}
+
 // ValidateSchemaChanges validates the schema found in the compiled schema and returns a
 // ValidatedSchemaChanges, if fully validated.
 func ValidateSchemaChanges(ctx context.Context, compiled *compiler.CompiledSchema, additiveOnly bool) (*ValidatedSchemaChanges, error) {
@@ -37,6 +44,7 @@ func ValidateSchemaChanges(ctx context.Context, compiled *compiler.CompiledSchem
 
 	// 2) Validate the namespaces defined.
 	newObjectDefNames := util.NewSet[string]()
+	typeSystems := make([]*namespace.TypeSystem, 0, len(compiled.ObjectDefinitions))
 	for _, nsdef := range compiled.ObjectDefinitions {
 		ts, err := namespace.NewNamespaceTypeSystem(nsdef,
 			namespace.ResolverForPredefinedDefinitions(namespace.PredefinedElements{
@@ -57,12 +65,14 @@ func ValidateSchemaChanges(ctx context.Context, compiled *compiler.CompiledSchem
 		}
 
 		newObjectDefNames.Add(nsdef.Name)
+		typeSystems = append(typeSystems, ts)
 	}
 
 	return &ValidatedSchemaChanges{
 		compiled:          compiled,
 		newCaveatDefNames: newCaveatDefNames,
 		newObjectDefNames: newObjectDefNames,
+		typeSystems:       typeSystems,
 		additiveOnly:      additiveOnly,
 	}, nil
 }
@@ -111,6 +121,80 @@ func ApplySchemaChangesOverExisting(
 	existingCaveats []*core.CaveatDefinition,
 	existingObjectDefs []*core.NamespaceDefinition,
 ) (*AppliedSchemaChanges, error) {
+	changes, err := checkSchemaChangesOverExisting(ctx, rwt, validated, existingCaveats, existingObjectDefs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the new/changes caveats.
+	if len(changes.caveatDefsWithChanges) > 0 {
+		if err := rwt.WriteCaveats(ctx, changes.caveatDefsWithChanges); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write the new/changed namespaces.
+	if len(changes.objectDefsWithChanges) > 0 {
+		if err := rwt.WriteNamespaces(ctx, changes.objectDefsWithChanges...); err != nil {
+			return nil, err
+		}
+	}
+
+	if !validated.additiveOnly {
+		// Delete the removed namespaces.
+		if changes.removedObjectDefNames.Len() > 0 {
+			if err := rwt.DeleteNamespaces(ctx, changes.removedObjectDefNames.AsSlice()...); err != nil {
+				return nil, err
+			}
+		}
+
+		// Delete the removed caveats.
+		if !changes.removedCaveatDefNames.IsEmpty() {
+			if err := rwt.DeleteCaveats(ctx, changes.removedCaveatDefNames.AsSlice()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	log.Ctx(ctx).Trace().
+		Interface("objectDefinitions", validated.compiled.ObjectDefinitions).
+		Interface("caveatDefinitions", validated.compiled.CaveatDefinitions).
+		Object("addedOrChangedObjectDefinitions", util.StringSet(validated.newObjectDefNames)).
+		Object("removedObjectDefinitions", util.StringSet(changes.removedObjectDefNames)).
+		Object("addedOrChangedCaveatDefinitions", util.StringSet(validated.newCaveatDefNames)).
+		Object("removedCaveatDefinitions", util.StringSet(changes.removedCaveatDefNames)).
+		Msg("completed schema update")
+
+	return &AppliedSchemaChanges{
+		TotalOperationCount:   uint32(len(validated.compiled.ObjectDefinitions) + len(validated.compiled.CaveatDefinitions) + changes.removedObjectDefNames.Len() + changes.removedCaveatDefNames.Len()),
+		NewObjectDefNames:     validated.newObjectDefNames.Subtract(changes.existingObjectDefNames).AsSlice(),
+		RemovedObjectDefNames: changes.removedObjectDefNames.AsSlice(),
+		NewCaveatDefNames:     validated.newCaveatDefNames.Subtract(changes.existingCaveatDefNames).AsSlice(),
+		RemovedCaveatDefNames: changes.removedCaveatDefNames.AsSlice(),
+	}, nil
+}
+
+// schemaChanges holds the definitions written and deleted when applying schema changes.
+type schemaChanges struct {
+	caveatDefsWithChanges  []*core.CaveatDefinition
+	objectDefsWithChanges  []*core.NamespaceDefinition
+	existingCaveatDefNames *util.Set[string]
+	existingObjectDefNames *util.Set[string]
+	removedCaveatDefNames  *util.Set[string]
+	removedObjectDefNames  *util.Set[string]
+}
+
+// checkSchemaChangesOverExisting ensures that the schema changes found in the validated changes
+// struct can be applied over the existing caveat and object definitions given, without breaking
+// the relationships read from the reader, and returns the definitions to write and delete. It
+// only reads, so that the changes can be checked without a transaction.
+func checkSchemaChangesOverExisting(
+	ctx context.Context,
+	reader datastore.Reader,
+	validated *ValidatedSchemaChanges,
+	existingCaveats []*core.CaveatDefinition,
+	existingObjectDefs []*core.NamespaceDefinition,
+) (*schemaChanges, error) {
 	// Build a map of existing caveats to determine those being removed, if any.
 	existingCaveatDefMap := make(map[string]*core.CaveatDefinition, len(existingCaveats))
 	existingCaveatDefNames := util.NewSet[string]()
@@ -123,7 +207,7 @@ func ApplySchemaChangesOverExisting(
 	// For each caveat definition, perform a diff and ensure the changes will not result in type errors.
 	caveatDefsWithChanges := make([]*core.CaveatDefinition, 0, len(validated.compiled.CaveatDefinitions))
 	for _, caveatDef := range validated.compiled.CaveatDefinitions {
-		diff, err := sanityCheckCaveatChanges(ctx, rwt, caveatDef, existingCaveatDefMap)
+		diff, err := sanityCheckCaveatChanges(ctx, reader, caveatDef, existingCaveatDefMap)
 		if err != nil {
 			return nil, err
 		}
@@ -147,7 +231,7 @@ func ApplySchemaChangesOverExisting(
 	// breaking changes.
 	objectDefsWithChanges := make([]*core.NamespaceDefinition, 0, len(validated.compiled.ObjectDefinitions))
 	for _, nsdef := range validated.compiled.ObjectDefinitions {
-		diff, err := sanityCheckNamespaceChanges(ctx, rwt, nsdef, existingObjectDefMap)
+		diff, err := sanityCheckNamespaceChanges(ctx, reader, nsdef, existingObjectDefMap)
 		if err != nil {
 			return nil, err
 		}
@@ -170,57 +254,19 @@ func ApplySchemaChangesOverExisting(
 	removedObjectDefNames := existingObjectDefNames.Subtract(validated.newObjectDefNames)
 	if !validated.additiveOnly {
 		if err := removedObjectDefNames.ForEach(func(nsdefName string) error {
-			return ensureNoRelationshipsExist(ctx, rwt, nsdefName)
+			return ensureNoRelationshipsExist(ctx, reader, nsdefName)
 		}); err != nil {
 			return nil, err
 		}
 	}
 
-	// Write the new/changes caveats.
-	if len(caveatDefsWithChanges) > 0 {
-		if err := rwt.WriteCaveats(ctx, caveatDefsWithChanges); err != nil {
-			return nil, err
-		}
-	}
-
-	// Write the new/changed namespaces.
-	if len(objectDefsWithChanges) > 0 {
-		if err := rwt.WriteNamespaces(ctx, objectDefsWithChanges...); err != nil {
-			return nil, err
-		}
-	}
-
-	if !validated.additiveOnly {
-		// Delete the removed namespaces.
-		if removedObjectDefNames.Len() > 0 {
-			if err := rwt.DeleteNamespaces(ctx, removedObjectDefNames.AsSlice()...); err != nil {
-				return nil, err
-			}
-		}
-
-		// Delete the removed caveats.
-		if !removedCaveatDefNames.IsEmpty() {
-			if err := rwt.DeleteCaveats(ctx, removedCaveatDefNames.AsSlice()); err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	log.Ctx(ctx).Trace().
-		Interface("objectDefinitions", validated.compiled.ObjectDefinitions).
-		Interface("caveatDefinitions", validated.compiled.CaveatDefinitions).
-		Object("addedOrChangedObjectDefinitions", util.StringSet(validated.newObjectDefNames)).
-		Object("removedObjectDefinitions", util.StringSet(removedObjectDefNames)).
-		Object("addedOrChangedCaveatDefinitions", util.StringSet(validated.newCaveatDefNames)).
-		Object("removedCaveatDefinitions", util.StringSet(removedCaveatDefNames)).
-		Msg("completed schema update")
-
-	return &AppliedSchemaChanges{
-		TotalOperationCount:   uint32(len(validated.compiled.ObjectDefinitions) + len(validated.compiled.CaveatDefinitions) + removedObjectDefNames.Len() + removedCaveatDefNames.Len()),
-		NewObjectDefNames:     validated.newObjectDefNames.Subtract(existingObjectDefNames).AsSlice(),
-		RemovedObjectDefNames: removedObjectDefNames.AsSlice(),
-		NewCaveatDefNames:     validated.newCaveatDefNames.Subtract(existingCaveatDefNames).AsSlice(),
-		RemovedCaveatDefNames: removedCaveatDefNames.AsSlice(),
+	return &schemaChanges{
+		caveatDefsWithChanges:  caveatDefsWithChanges,
+		objectDefsWithChanges:  objectDefsWithChanges,
+		existingCaveatDefNames: existingCaveatDefNames,
+		existingObjectDefNames: existingObjectDefNames,
+		removedCaveatDefNames:  removedCaveatDefNames,
+		removedObjectDefNames:  removedObjectDefNames,
 	}, nil
 }
 
@@ -228,7 +274,7 @@ func ApplySchemaChangesOverExisting(
 // the types of the parameters that may already exist on relationships.
 func sanityCheckCaveatChanges(
 	_ context.Context,
-	_ datastore.ReadWriteTransaction,
+	_ datastore.Reader,
 	caveatDef *core.CaveatDefinition,
 	existingDefs map[string]*core.CaveatDefinition,
 ) (*caveats.Diff, error) {
@@ -240,21 +286,31 @@ func sanityCheckCaveatChanges(
 	}
 
 	for _, delta := range diff.Deltas() {
-		switch delta.Type {
-		case caveats.RemovedParameter:
-			return diff, NewSchemaWriteDataValidationError("cannot remove parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
-
-		case caveats.ParameterTypeChanged:
-			return diff, NewSchemaWriteDataValidationError("cannot change the type of parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
+		if err := checkCaveatDelta(caveatDef.Name, delta); err != nil {
+			return diff, err
 		}
 	}
 
 	return diff, nil
 }
 
+// checkCaveatDelta returns an error if the given change to the caveat with the given name would
+// break the types of the parameters that may already exist on relationships.
+func checkCaveatDelta(caveatName string, delta caveats.Delta) error {
+	switch delta.Type {
+	case caveats.RemovedParameter:
+		return NewSchemaWriteDataValidationError("cannot remove parameter `%s` on caveat `%s`", delta.ParameterName, caveatName)
+
+	case caveats.ParameterTypeChanged:
+		return NewSchemaWriteDataValidationError("cannot change the type of parameter `%s` on caveat `%s`", delta.ParameterName, caveatName)
+	}
+
+	return nil
+}
+
 // ensureNoRelationshipsExist ensures that no relationships exist within the namespace with the given name.
-func ensureNoRelationshipsExist(ctx context.Context, rwt datastore.ReadWriteTransaction, namespaceName string) error {
-	qy, qyErr := rwt.QueryRelationships(
+func ensureNoRelationshipsExist(ctx context.Context, reader datastore.Reader, namespaceName string) error {
+	qy, qyErr := reader.QueryRelationships(
 		ctx,
 		datastore.RelationshipsFilter{ResourceType: namespaceName},
 		options.WithLimit(options.LimitOne),
@@ -269,7 +325,7 @@ func ensureNoRelationshipsExist(ctx context.Context, rwt datastore.ReadWriteTran
 		return err
 	}
 
-	qy, qyErr = rwt.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+	qy, qyErr = reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
 		SubjectType: namespaceName,
 	}, options.WithReverseLimit(options.LimitOne))
 	err := errorIfTupleIteratorReturnsTuples(
@@ -292,7 +348,7 @@ func ensureNoRelationshipsExist(ctx context.Context, rwt datastore.ReadWriteTran
 // and relations.
 func sanityCheckNamespaceChanges(
 	ctx context.Context,
-	rwt datastore.ReadWriteTransaction,
+	reader datastore.Reader,
 	nsdef *core.NamespaceDefinition,
 	existingDefs map[string]*core.NamespaceDefinition,
 ) (*namespace.Diff, error) {
@@ -304,85 +360,102 @@ func sanityCheckNamespaceChanges(
 	}
 
 	for _, delta := range diff.Deltas() {
-		switch delta.Type {
-		case namespace.RemovedRelation:
-			qy, qyErr := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{
-				ResourceType:             nsdef.Name,
-				OptionalResourceRelation: delta.RelationName,
-			})
-
-			err = errorIfTupleIteratorReturnsTuples(
-				ctx,
-				qy,
-				qyErr,
-				"cannot delete relation `%s` in object definition `%s`, as a relationship exists under it", delta.RelationName, nsdef.Name)
-			if err != nil {
-				return diff, err
-			}
+		if err := checkNamespaceDelta(ctx, reader, nsdef.Name, delta); err != nil {
+			return diff, err
+		}
+	}
+	return diff, nil
+}
 
-			// Also check for right sides of tuples.
-			qy, qyErr = rwt.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
-				SubjectType: nsdef.Name,
-				RelationFilter: datastore.SubjectRelationFilter{
-					NonEllipsisRelation: delta.RelationName,
-				},
-			}, options.WithReverseLimit(options.LimitOne))
-			err = errorIfTupleIteratorReturnsTuples(
-				ctx,
-				qy,
-				qyErr,
-				"cannot delete relation `%s` in object definition `%s`, as a relationship references it", delta.RelationName, nsdef.Name)
-			qy.Close()
-			if err != nil {
-				return diff, err
-			}
+// checkNamespaceDelta returns an error if the given change to the object definition with the
+// given name would leave relationships without associated schema.
+func checkNamespaceDelta(
+	ctx context.Context,
+	reader datastore.Reader,
+	namespaceName string,
+	delta namespace.Delta,
+) error {
+	switch delta.Type {
+	case namespace.NamespaceRemoved:
+		return ensureNoRelationshipsExist(ctx, reader, namespaceName)
+
+	case namespace.RemovedRelation:
+		qy, qyErr := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+			ResourceType:             namespaceName,
+			OptionalResourceRelation: delta.RelationName,
+		})
+
+		err := errorIfTupleIteratorReturnsTuples(
+			ctx,
+			qy,
+			qyErr,
+			"cannot delete relation `%s` in object definition `%s`, as a relationship exists under it", delta.RelationName, namespaceName)
+		if err != nil {
+			return err
+		}
 
-		case namespace.RelationAllowedTypeRemoved:
-			var optionalSubjectIds []string
-			var relationFilter datastore.SubjectRelationFilter
-			optionalCaveatName := ""
-
-			if delta.AllowedType.GetPublicWildcard() != nil {
-				optionalSubjectIds = []string{tuple.PublicWildcard}
-			} else {
-				relationFilter = datastore.SubjectRelationFilter{
-					NonEllipsisRelation: delta.AllowedType.GetRelation(),
-				}
-			}
+		// Also check for right sides of tuples.
+		qy, qyErr = reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+			SubjectType: namespaceName,
+			RelationFilter: datastore.SubjectRelationFilter{
+				NonEllipsisRelation: delta.RelationName,
+			},
+		}, options.WithReverseLimit(options.LimitOne))
+		err = errorIfTupleIteratorReturnsTuples(
+			ctx,
+			qy,
+			qyErr,
+			"cannot delete relation `%s` in object definition `%s`, as a relationship references it", delta.RelationName, namespaceName)
+		qy.Close()
+		if err != nil {
+			return err
+		}
 
-			if delta.AllowedType.GetRequiredCaveat() != nil {
-				optionalCaveatName = delta.AllowedType.GetRequiredCaveat().CaveatName
+	case namespace.RelationAllowedTypeRemoved:
+		var optionalSubjectIds []string
+		var relationFilter datastore.SubjectRelationFilter
+		optionalCaveatName := ""
+
+		if delta.AllowedType.GetPublicWildcard() != nil {
+			optionalSubjectIds = []string{tuple.PublicWildcard}
+		} else {
+			relationFilter = datastore.SubjectRelationFilter{
+				NonEllipsisRelation: delta.AllowedType.GetRelation(),
 			}
+		}
 
-			qyr, qyrErr := rwt.QueryRelationships(
-				ctx,
-				datastore.RelationshipsFilter{
-					ResourceType:             nsdef.Name,
-					OptionalResourceRelation: delta.RelationName,
-					OptionalSubjectsSelectors: []datastore.SubjectsSelector{
-						{
-							OptionalSubjectType: delta.AllowedType.Namespace,
-							OptionalSubjectIds:  optionalSubjectIds,
-							RelationFilter:      relationFilter,
-						},
+		if delta.AllowedType.GetRequiredCaveat() != nil {
+			optionalCaveatName = delta.AllowedType.GetRequiredCaveat().CaveatName
+		}
+
+		qyr, qyrErr := reader.QueryRelationships(
+			ctx,
+			datastore.RelationshipsFilter{
+				ResourceType:             namespaceName,
+				OptionalResourceRelation: delta.RelationName,
+				OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+					{
+						OptionalSubjectType: delta.AllowedType.Namespace,
+						OptionalSubjectIds:  optionalSubjectIds,
+						RelationFilter:      relationFilter,
 					},
-					OptionalCaveatName: optionalCaveatName,
 				},
-				options.WithLimit(options.LimitOne),
-			)
-			err = errorIfTupleIteratorReturnsTuples(
-				ctx,
-				qyr,
-				qyrErr,
-				"cannot remove allowed type `%s` from relation `%s` in object definition `%s`, as a relationship exists with it",
-				namespace.SourceForAllowedRelation(delta.AllowedType), delta.RelationName, nsdef.Name)
-			qyr.Close()
-			if err != nil {
-				return diff, err
-			}
+				OptionalCaveatName: optionalCaveatName,
+			},
+			options.WithLimit(options.LimitOne),
+		)
+		err := errorIfTupleIteratorReturnsTuples(
+			ctx,
+			qyr,
+			qyrErr,
+			"cannot remove allowed type `%s` from relation `%s` in object definition `%s`, as a relationship exists with it",
+			namespace.SourceForAllowedRelation(delta.AllowedType), delta.RelationName, namespaceName)
+		qyr.Close()
+		if err != nil {
+			return err
 		}
 	}
-	return diff, nil
+	return nil
 }
 
 // errorIfTupleIteratorReturnsTuples takes a tuple iterator and any error that was generated
@@ -7,10 +7,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func TestApplySchemaChanges(t *testing.T) {
@@ -63,3 +66,91 @@ func TestApplySchemaChanges(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func TestDryRunSchemaChanges(t *testing.T) {
+	require := require.New(t)
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+		}
+	`, nil, require)
+
+	emptyDefaultPrefix := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: `definition organization {}`,
+	}, &emptyDefaultPrefix)
+	require.NoError(err)
+
+	for _, additiveOnly := range []bool{false, true} {
+		validated, err := ValidateSchemaChanges(context.Background(), compiled, additiveOnly)
+		require.NoError(err)
+
+		diff, err := DryRunSchemaChanges(context.Background(), ds, validated)
+		require.NoError(err)
+		require.NoError(diff.ApplyError)
+
+		deltaTypes := make([]string, 0, len(diff.Deltas))
+		for _, delta := range diff.Deltas {
+			require.NoError(delta.RejectionError)
+			deltaTypes = append(deltaTypes, delta.DefinitionName+":"+string(delta.NamespaceDelta.Type))
+		}
+
+		if additiveOnly {
+			require.Equal([]string{"organization:namespace-added"}, deltaTypes)
+		} else {
+			require.Equal([]string{
+				"organization:namespace-added",
+				"document:namespace-removed",
+				"user:namespace-removed",
+			}, deltaTypes)
+		}
+
+		// Ensure the changes were not committed.
+		headRev, err := ds.HeadRevision(context.Background())
+		require.NoError(err)
+
+		nsDefs, err := ds.SnapshotReader(headRev).ListAllNamespaces(context.Background())
+		require.NoError(err)
+		require.Len(nsDefs, 2)
+	}
+}
+
+func TestDryRunSchemaChangesReadOnly(t *testing.T) {
+	require := require.New(t)
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+		}
+	`, []*core.RelationTuple{tuple.MustParse("document:first#viewer@user:tom")}, require)
+
+	emptyDefaultPrefix := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: `definition user {}`,
+	}, &emptyDefaultPrefix)
+	require.NoError(err)
+
+	validated, err := ValidateSchemaChanges(context.Background(), compiled, false)
+	require.NoError(err)
+
+	// The dry run only reads, so it can be made against a read-only datastore, and reports the
+	// changes which would be rejected because of the relationships stored.
+	diff, err := DryRunSchemaChanges(context.Background(), proxy.NewReadonlyDatastore(ds), validated)
+	require.NoError(err)
+	require.ErrorContains(diff.ApplyError, "cannot delete object definition `document`, as a relationship exists under it")
+
+	require.Len(diff.Deltas, 1)
+	require.Equal("document", diff.Deltas[0].DefinitionName)
+	require.Equal(diff.ApplyError, diff.Deltas[0].RejectionError)
+}
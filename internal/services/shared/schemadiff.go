@@ -0,0 +1,210 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/authzed/spicedb/internal/caveats"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/util"
+)
+
+// SchemaDelta is a single change made to a definition by a candidate schema.
+type SchemaDelta struct {
+	// DefinitionName is the name of the object or caveat definition that was changed.
+	DefinitionName string
+
+	// NamespaceDelta is the change made, if the definition is an object definition.
+	NamespaceDelta *namespace.Delta
+
+	// CaveatDelta is the change made, if the definition is a caveat definition.
+	CaveatDelta *caveats.Delta
+
+	// RejectionError is the error returned when applying the change, if the change would be
+	// rejected because of the relationships in the datastore.
+	RejectionError error
+}
+
+// SchemaDiff holds the changes between the schema stored in a datastore and a candidate schema.
+type SchemaDiff struct {
+	// Deltas are the changes made by the candidate schema.
+	Deltas []SchemaDelta
+
+	// ApplyError is the error returned by a dry run of applying the candidate schema, if any.
+	ApplyError error
+}
+
+// DryRunSchemaChanges computes the changes between the schema stored in the datastore and the
+// validated schema changes, and then checks whether they could be applied over the data stored
+// in the datastore. The dry run only reads from the datastore, at its head revision, and so can
+// be made against a read-only datastore and never writes the changes.
+func DryRunSchemaChanges(ctx context.Context, ds datastore.Datastore, validated *ValidatedSchemaChanges) (*SchemaDiff, error) {
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := ds.SnapshotReader(headRevision)
+	deltas, err := DiffSchemaChanges(ctx, reader, validated)
+	if err != nil {
+		return nil, err
+	}
+
+	existingCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingObjectDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SchemaDiff{Deltas: deltas}
+	if _, err := checkSchemaChangesOverExisting(ctx, reader, validated, datastore.DefinitionsOf(existingCaveats), datastore.DefinitionsOf(existingObjectDefs)); err != nil {
+		if !isDataValidationError(err) {
+			return nil, err
+		}
+		diff.ApplyError = err
+	}
+
+	return diff, nil
+}
+
+// DiffSchemaChanges computes the changes between the schema stored in the datastore and the
+// validated schema changes. Unlike ApplySchemaChanges, which fails on the first change that
+// would be rejected, every change is returned, with its RejectionError set if it would be
+// rejected.
+func DiffSchemaChanges(ctx context.Context, reader datastore.Reader, validated *ValidatedSchemaChanges) ([]SchemaDelta, error) {
+	existingCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingObjectDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deltas []SchemaDelta
+
+	// Diff the caveats, including those being removed.
+	existingCaveatDefMap := make(map[string]*core.CaveatDefinition, len(existingCaveats))
+	existingCaveatDefNames := util.NewSet[string]()
+	for _, existing := range existingCaveats {
+		existingCaveatDefMap[existing.Definition.Name] = existing.Definition
+		existingCaveatDefNames.Add(existing.Definition.Name)
+	}
+
+	for _, caveatDef := range validated.compiled.CaveatDefinitions {
+		caveatDeltas, err := diffCaveat(existingCaveatDefMap[caveatDef.Name], caveatDef)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, caveatDeltas...)
+	}
+
+	if !validated.additiveOnly {
+		for _, caveatDefName := range sortedNames(existingCaveatDefNames.Subtract(validated.newCaveatDefNames)) {
+			caveatDeltas, err := diffCaveat(existingCaveatDefMap[caveatDefName], nil)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, caveatDeltas...)
+		}
+	}
+
+	// Diff the object definitions, including those being removed.
+	existingObjectDefMap := make(map[string]*core.NamespaceDefinition, len(existingObjectDefs))
+	existingObjectDefNames := util.NewSet[string]()
+	for _, existing := range existingObjectDefs {
+		existingObjectDefMap[existing.Definition.Name] = existing.Definition
+		existingObjectDefNames.Add(existing.Definition.Name)
+	}
+
+	for _, nsdef := range validated.compiled.ObjectDefinitions {
+		nsDeltas, err := diffNamespace(ctx, reader, nsdef.Name, existingObjectDefMap[nsdef.Name], nsdef)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, nsDeltas...)
+	}
+
+	if !validated.additiveOnly {
+		for _, nsdefName := range sortedNames(existingObjectDefNames.Subtract(validated.newObjectDefNames)) {
+			nsDeltas, err := diffNamespace(ctx, reader, nsdefName, existingObjectDefMap[nsdefName], nil)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, nsDeltas...)
+		}
+	}
+
+	return deltas, nil
+}
+
+func diffCaveat(existing *core.CaveatDefinition, updated *core.CaveatDefinition) ([]SchemaDelta, error) {
+	diff, err := caveats.DiffCaveats(existing, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	caveatName := existing.GetName()
+	if updated != nil {
+		caveatName = updated.Name
+	}
+
+	deltas := make([]SchemaDelta, 0, len(diff.Deltas()))
+	for _, delta := range diff.Deltas() {
+		delta := delta
+		deltas = append(deltas, SchemaDelta{
+			DefinitionName: caveatName,
+			CaveatDelta:    &delta,
+			RejectionError: checkCaveatDelta(caveatName, delta),
+		})
+	}
+	return deltas, nil
+}
+
+func diffNamespace(
+	ctx context.Context,
+	reader datastore.Reader,
+	namespaceName string,
+	existing *core.NamespaceDefinition,
+	updated *core.NamespaceDefinition,
+) ([]SchemaDelta, error) {
+	diff, err := namespace.DiffNamespaces(existing, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make([]SchemaDelta, 0, len(diff.Deltas()))
+	for _, delta := range diff.Deltas() {
+		delta := delta
+		rejectionErr := checkNamespaceDelta(ctx, reader, namespaceName, delta)
+		if rejectionErr != nil && !isDataValidationError(rejectionErr) {
+			return nil, rejectionErr
+		}
+
+		deltas = append(deltas, SchemaDelta{
+			DefinitionName: namespaceName,
+			NamespaceDelta: &delta,
+			RejectionError: rejectionErr,
+		})
+	}
+	return deltas, nil
+}
+
+func isDataValidationError(err error) bool {
+	var validationErr ErrSchemaWriteDataValidation
+	return errors.As(err, &validationErr)
+}
+
+func sortedNames(names *util.Set[string]) []string {
+	sorted := names.AsSlice()
+	sort.Strings(sorted)
+	return sorted
+}
@@ -10,8 +10,12 @@ import (
 	"google.golang.org/protobuf/encoding/prototext"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/development"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 type devServer struct {
@@ -102,16 +106,201 @@ func (ds *devServer) LookupShared(ctx context.Context, req *v0.LookupShareReques
 	}, nil
 }
 
-func (ds *devServer) EditCheck(_ context.Context, _ *v0.EditCheckRequest) (*v0.EditCheckResponse, error) {
-	return nil, fmt.Errorf("no longer implemented. Please use the WebAssembly development package")
+func (ds *devServer) EditCheck(ctx context.Context, req *v0.EditCheckRequest) (*v0.EditCheckResponse, error) {
+	devContext, devErrs, err := development.NewDevContext(ctx, toRequestContext(req.Context))
+	if err != nil {
+		return nil, err
+	}
+
+	if devErrs != nil {
+		return &v0.EditCheckResponse{
+			RequestErrors: toV0DeveloperErrors(devErrs.InputErrors),
+		}, nil
+	}
+	defer devContext.Dispose()
+
+	// Run the checks and store their output.
+	results := make([]*v0.EditCheckResult, 0, len(req.CheckRelationships))
+	for _, checkTpl := range req.CheckRelationships {
+		coreTpl := core.ToCoreRelationTuple(checkTpl)
+		cr, err := development.RunCheck(devContext, coreTpl.ResourceAndRelation, coreTpl.Subject, nil)
+		if err != nil {
+			devErr, wireErr := development.DistinguishGraphError(
+				devContext,
+				err,
+				devinterface.DeveloperError_CHECK_WATCH,
+				0, 0,
+				tuple.MustString(coreTpl),
+			)
+			if wireErr != nil {
+				return nil, wireErr
+			}
+
+			results = append(results, &v0.EditCheckResult{
+				Relationship: checkTpl,
+				IsMember:     false,
+				Error:        toV0DeveloperError(devErr),
+			})
+			continue
+		}
+
+		results = append(results, &v0.EditCheckResult{
+			Relationship: checkTpl,
+			IsMember:     cr.Permissionship == dispatch.ResourceCheckResult_MEMBER,
+		})
+	}
+
+	return &v0.EditCheckResponse{
+		CheckResults: results,
+	}, nil
+}
+
+func (ds *devServer) Validate(ctx context.Context, req *v0.ValidateRequest) (*v0.ValidateResponse, error) {
+	devContext, devErrs, err := development.NewDevContext(ctx, toRequestContext(req.Context))
+	if err != nil {
+		return nil, err
+	}
+
+	if devErrs != nil {
+		return &v0.ValidateResponse{
+			RequestErrors: toV0DeveloperErrors(devErrs.InputErrors),
+		}, nil
+	}
+	defer devContext.Dispose()
+
+	// Parse the validation and assertions YAML.
+	validation, devErr := development.ParseExpectedRelationsYAML(req.ValidationYaml)
+	if devErr != nil {
+		return &v0.ValidateResponse{
+			RequestErrors: []*v0.DeveloperError{toV0DeveloperError(devErr)},
+		}, nil
+	}
+
+	assertions, devErr := development.ParseAssertionsYAML(req.AssertionsYaml)
+	if devErr != nil {
+		return &v0.ValidateResponse{
+			RequestErrors: []*v0.DeveloperError{toV0DeveloperError(devErr)},
+		}, nil
+	}
+
+	// Run the assertions and then the validation.
+	assertionErrors, err := development.RunAllAssertions(devContext, assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	membershipSet, validationErrors, err := development.RunValidation(devContext, validation)
+	if err != nil {
+		return nil, err
+	}
+
+	// If requested, regenerate the validation YAML.
+	updatedValidationYaml := ""
+	if membershipSet != nil && req.UpdateValidationYaml {
+		updatedValidationYaml, err = development.GenerateValidation(membershipSet)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &v0.ValidateResponse{
+		ValidationErrors:      toV0DeveloperErrors(append(assertionErrors, validationErrors...)),
+		UpdatedValidationYaml: updatedValidationYaml,
+	}, nil
+}
+
+func (ds *devServer) FormatSchema(_ context.Context, req *v0.FormatSchemaRequest) (*v0.FormatSchemaResponse, error) {
+	// The schema is compiled to report any errors within it, before it is formatted.
+	_, devErr, err := development.CompileSchema(req.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if devErr != nil {
+		return &v0.FormatSchemaResponse{
+			Error: toV0DeveloperError(devErr),
+		}, nil
+	}
+
+	formatted, err := development.FormatSchema(req.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v0.FormatSchemaResponse{
+		FormattedSchema: formatted,
+	}, nil
 }
 
-func (ds *devServer) Validate(_ context.Context, _ *v0.ValidateRequest) (*v0.ValidateResponse, error) {
-	return nil, fmt.Errorf("no longer implemented. Please use the WebAssembly development package")
+// toRequestContext converts a v0 request context into that used by the development package.
+func toRequestContext(reqContext *v0.RequestContext) *devinterface.RequestContext {
+	return &devinterface.RequestContext{
+		Schema:        reqContext.GetSchema(),
+		Relationships: core.ToCoreRelationTuples(reqContext.GetRelationships()),
+	}
 }
 
-func (ds *devServer) FormatSchema(_ context.Context, _ *v0.FormatSchemaRequest) (*v0.FormatSchemaResponse, error) {
-	return nil, fmt.Errorf("no longer implemented. Please use the WebAssembly development package")
+// toV0DeveloperError converts a developer error from the development package into its v0
+// form.
+func toV0DeveloperError(devErr *devinterface.DeveloperError) *v0.DeveloperError {
+	// The source enums share their values.
+	source := v0.DeveloperError_Source(devErr.Source)
+	if _, ok := v0.DeveloperError_Source_name[int32(source)]; !ok {
+		source = v0.DeveloperError_UNKNOWN_SOURCE
+	}
+
+	return &v0.DeveloperError{
+		Message: devErr.Message,
+		Line:    devErr.Line,
+		Column:  devErr.Column,
+		Source:  source,
+		Kind:    toV0ErrorKind(devErr.Kind),
+		Path:    devErr.Path,
+		Context: devErr.Context,
+	}
+}
+
+// toV0ErrorKind converts a developer error kind into its v0 form. Kinds added after the v0 API
+// was frozen are mapped to the closest kind it defines.
+func toV0ErrorKind(kind devinterface.DeveloperError_ErrorKind) v0.DeveloperError_ErrorKind {
+	switch kind {
+	case devinterface.DeveloperError_UNKNOWN_KIND:
+		return v0.DeveloperError_UNKNOWN_KIND
+	case devinterface.DeveloperError_PARSE_ERROR:
+		return v0.DeveloperError_PARSE_ERROR
+	case devinterface.DeveloperError_SCHEMA_ISSUE:
+		return v0.DeveloperError_SCHEMA_ISSUE
+	case devinterface.DeveloperError_DUPLICATE_RELATIONSHIP:
+		return v0.DeveloperError_DUPLICATE_RELATIONSHIP
+	case devinterface.DeveloperError_MISSING_EXPECTED_RELATIONSHIP:
+		return v0.DeveloperError_MISSING_EXPECTED_RELATIONSHIP
+	case devinterface.DeveloperError_EXTRA_RELATIONSHIP_FOUND:
+		return v0.DeveloperError_EXTRA_RELATIONSHIP_FOUND
+	case devinterface.DeveloperError_UNKNOWN_OBJECT_TYPE:
+		return v0.DeveloperError_UNKNOWN_OBJECT_TYPE
+	case devinterface.DeveloperError_UNKNOWN_RELATION:
+		return v0.DeveloperError_UNKNOWN_RELATION
+	case devinterface.DeveloperError_MAXIMUM_RECURSION:
+		return v0.DeveloperError_MAXIMUM_RECURSION
+	case devinterface.DeveloperError_ASSERTION_FAILED:
+		return v0.DeveloperError_ASSERTION_FAILED
+	case devinterface.DeveloperError_INVALID_SUBJECT_TYPE, devinterface.DeveloperError_SCHEMA_WARNING:
+		return v0.DeveloperError_SCHEMA_ISSUE
+	default:
+		return v0.DeveloperError_UNKNOWN_KIND
+	}
+}
+
+func toV0DeveloperErrors(devErrs []*devinterface.DeveloperError) []*v0.DeveloperError {
+	if len(devErrs) == 0 {
+		return nil
+	}
+
+	converted := make([]*v0.DeveloperError, 0, len(devErrs))
+	for _, devErr := range devErrs {
+		converted = append(converted, toV0DeveloperError(devErr))
+	}
+	return converted
 }
 
 func upgradeSchema(configs []string) (string, error) {
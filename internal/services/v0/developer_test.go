@@ -8,6 +8,10 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func TestDeveloperSharing(t *testing.T) {
@@ -74,3 +78,182 @@ func TestDeveloperSharingConverted(t *testing.T) {
 
 	require.Equal("definition foo {}\n\n", lresp.Schema)
 }
+
+const testDeveloperSchema = `definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`
+
+func testRequestContext() *v0.RequestContext {
+	return &v0.RequestContext{
+		Schema: testDeveloperSchema,
+		Relationships: core.ToV0RelationTuples([]*core.RelationTuple{
+			tuple.MustParse("document:somedoc#viewer@user:someuser"),
+		}),
+	}
+}
+
+func TestDeveloperEditCheck(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	resp, err := srv.EditCheck(context.Background(), &v0.EditCheckRequest{
+		Context: testRequestContext(),
+		CheckRelationships: core.ToV0RelationTuples([]*core.RelationTuple{
+			tuple.MustParse("document:somedoc#view@user:someuser"),
+			tuple.MustParse("document:somedoc#view@user:anotheruser"),
+			tuple.MustParse("document:somedoc#unknown@user:someuser"),
+		}),
+	})
+	require.NoError(err)
+	require.Empty(resp.RequestErrors)
+	require.Len(resp.CheckResults, 3)
+
+	require.True(resp.CheckResults[0].IsMember)
+	require.Nil(resp.CheckResults[0].Error)
+
+	require.False(resp.CheckResults[1].IsMember)
+	require.Nil(resp.CheckResults[1].Error)
+
+	require.False(resp.CheckResults[2].IsMember)
+	require.NotNil(resp.CheckResults[2].Error)
+	require.Equal(v0.DeveloperError_CHECK_WATCH, resp.CheckResults[2].Error.Source)
+	require.Equal(v0.DeveloperError_UNKNOWN_RELATION, resp.CheckResults[2].Error.Kind)
+}
+
+func TestDeveloperEditCheckSchemaError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	resp, err := srv.EditCheck(context.Background(), &v0.EditCheckRequest{
+		Context: &v0.RequestContext{
+			Schema: "definition user {}\n\ndefinition document {\n\trelation viewer: unknown\n}",
+		},
+	})
+	require.NoError(err)
+	require.Len(resp.RequestErrors, 1)
+	require.Equal(v0.DeveloperError_SCHEMA, resp.RequestErrors[0].Source)
+	require.Equal(v0.DeveloperError_SCHEMA_ISSUE, resp.RequestErrors[0].Kind)
+	require.Equal(uint32(4), resp.RequestErrors[0].Line)
+}
+
+func TestDeveloperValidate(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	tcs := []struct {
+		name                   string
+		validationYaml         string
+		assertionsYaml         string
+		updateValidationYaml   bool
+		expectedRequestErrors  []v0.DeveloperError_Source
+		expectedValidationKind []v0.DeveloperError_ErrorKind
+		expectedUpdatedYaml    string
+	}{
+		{
+			name:           "valid",
+			validationYaml: "document:somedoc#view:\n- \"[user:someuser] is <document:somedoc#viewer>\"",
+			assertionsYaml: "assertTrue:\n- document:somedoc#view@user:someuser\nassertFalse:\n- document:somedoc#view@user:anotheruser",
+		},
+		{
+			name:                 "updated validation yaml",
+			validationYaml:       "document:somedoc#view: []",
+			updateValidationYaml: true,
+			expectedValidationKind: []v0.DeveloperError_ErrorKind{
+				v0.DeveloperError_EXTRA_RELATIONSHIP_FOUND,
+			},
+			expectedUpdatedYaml: "document:somedoc#view:\n- '[user:someuser] is <document:somedoc#viewer>'\n",
+		},
+		{
+			name:           "failed assertion",
+			assertionsYaml: "assertTrue:\n- document:somedoc#view@user:anotheruser",
+			expectedValidationKind: []v0.DeveloperError_ErrorKind{
+				v0.DeveloperError_ASSERTION_FAILED,
+			},
+		},
+		{
+			name:                  "invalid validation yaml",
+			validationYaml:        "document:somedoc#view: {",
+			expectedRequestErrors: []v0.DeveloperError_Source{v0.DeveloperError_VALIDATION_YAML},
+		},
+		{
+			name:                  "invalid assertions yaml",
+			assertionsYaml:        "assertTrue: [document:somedoc",
+			expectedRequestErrors: []v0.DeveloperError_Source{v0.DeveloperError_ASSERTION},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+			resp, err := srv.Validate(context.Background(), &v0.ValidateRequest{
+				Context:              testRequestContext(),
+				ValidationYaml:       tc.validationYaml,
+				AssertionsYaml:       tc.assertionsYaml,
+				UpdateValidationYaml: tc.updateValidationYaml,
+			})
+			require.NoError(err)
+
+			require.Len(resp.RequestErrors, len(tc.expectedRequestErrors))
+			for i, source := range tc.expectedRequestErrors {
+				require.Equal(source, resp.RequestErrors[i].Source)
+				require.Equal(v0.DeveloperError_PARSE_ERROR, resp.RequestErrors[i].Kind)
+			}
+
+			require.Len(resp.ValidationErrors, len(tc.expectedValidationKind))
+			for i, kind := range tc.expectedValidationKind {
+				require.Equal(kind, resp.ValidationErrors[i].Kind)
+			}
+
+			require.Equal(tc.expectedUpdatedYaml, resp.UpdatedValidationYaml)
+		})
+	}
+}
+
+func TestDeveloperFormatSchema(t *testing.T) {
+	require := require.New(t)
+	srv := NewDeveloperServer(NewInMemoryShareStore("flavored"))
+
+	resp, err := srv.FormatSchema(context.Background(), &v0.FormatSchemaRequest{
+		Schema: "definition user {}   definition document {\nrelation viewer: user\n    permission view = viewer\n}",
+	})
+	require.NoError(err)
+	require.Nil(resp.Error)
+	require.Equal(testDeveloperSchema, resp.FormattedSchema)
+
+	resp, err = srv.FormatSchema(context.Background(), &v0.FormatSchemaRequest{
+		Schema: "definition user {} // the user\n\n\n// documents\ndefinition document {\n  // no relations yet\n}",
+	})
+	require.NoError(err)
+	require.Nil(resp.Error)
+	require.Equal("definition user {} // the user\n\n// documents\ndefinition document {\n\t// no relations yet\n}", resp.FormattedSchema)
+
+	resp, err = srv.FormatSchema(context.Background(), &v0.FormatSchemaRequest{
+		Schema: "definition user {",
+	})
+	require.NoError(err)
+	require.NotNil(resp.Error)
+	require.Equal(v0.DeveloperError_SCHEMA, resp.Error.Source)
+	require.Equal(uint32(1), resp.Error.Line)
+}
+
+func TestToV0ErrorKind(t *testing.T) {
+	// Every kind must map to a kind defined by the v0 API.
+	for value := range devinterface.DeveloperError_ErrorKind_name {
+		kind := toV0ErrorKind(devinterface.DeveloperError_ErrorKind(value))
+		require.Contains(t, v0.DeveloperError_ErrorKind_name, int32(kind), "kind %d", value)
+	}
+
+	require.Equal(t, v0.DeveloperError_ASSERTION_FAILED, toV0ErrorKind(devinterface.DeveloperError_ASSERTION_FAILED))
+	require.Equal(t, v0.DeveloperError_SCHEMA_ISSUE, toV0ErrorKind(devinterface.DeveloperError_INVALID_SUBJECT_TYPE))
+	require.Equal(t, v0.DeveloperError_SCHEMA_ISSUE, toV0ErrorKind(devinterface.DeveloperError_SCHEMA_WARNING))
+	require.Equal(t, v0.DeveloperError_UNKNOWN_KIND, toV0ErrorKind(devinterface.DeveloperError_ErrorKind(1000)))
+}
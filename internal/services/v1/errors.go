@@ -15,12 +15,15 @@ import (
 	"github.com/authzed/spicedb/internal/graph"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/relationships"
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/internal/sharederrors"
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/writehook"
 )
 
 // ErrExceedsMaximumUpdates occurs when too many updates are given to a call.
@@ -98,6 +101,116 @@ func NewExceedsMaximumPreconditionsErr(preconditionCount uint16, maxCountAllowed
 	}
 }
 
+// ErrExceedsMaximumChecks occurs when too many checks are given to a bulk check call.
+type ErrExceedsMaximumChecks struct {
+	error
+	checkCount      uint64
+	maxCountAllowed uint16
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrExceedsMaximumChecks) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Uint64("checkCount", err.checkCount).Uint16("maxCountAllowed", err.maxCountAllowed)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrExceedsMaximumChecks) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+			map[string]string{
+				"check_count":            strconv.FormatUint(err.checkCount, 10),
+				"maximum_checks_allowed": strconv.Itoa(int(err.maxCountAllowed)),
+			},
+		),
+	)
+}
+
+// NewExceedsMaximumChecksErr creates a new error representing that too many checks were given to a BulkCheckPermission call.
+func NewExceedsMaximumChecksErr(checkCount uint64, maxCountAllowed uint16) ErrExceedsMaximumChecks {
+	return ErrExceedsMaximumChecks{
+		error:           fmt.Errorf("check count of %d is greater than maximum allowed of %d", checkCount, maxCountAllowed),
+		checkCount:      checkCount,
+		maxCountAllowed: maxCountAllowed,
+	}
+}
+
+// ErrExceedsMaximumLimit occurs when a limit that is too large is given to a call.
+type ErrExceedsMaximumLimit struct {
+	error
+	providedLimit   uint64
+	maxLimitAllowed uint64
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrExceedsMaximumLimit) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Uint64("providedLimit", err.providedLimit).Uint64("maxLimitAllowed", err.maxLimitAllowed)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrExceedsMaximumLimit) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+			map[string]string{
+				"limit_provided":        strconv.FormatUint(err.providedLimit, 10),
+				"maximum_limit_allowed": strconv.FormatUint(err.maxLimitAllowed, 10),
+			},
+		),
+	)
+}
+
+// NewExceedsMaximumLimitErr creates a new error representing that the limit specified was too large.
+func NewExceedsMaximumLimitErr(providedLimit uint64, maxLimitAllowed uint64) ErrExceedsMaximumLimit {
+	return ErrExceedsMaximumLimit{
+		error:           fmt.Errorf("provided limit %d is greater than maximum allowed of %d", providedLimit, maxLimitAllowed),
+		providedLimit:   providedLimit,
+		maxLimitAllowed: maxLimitAllowed,
+	}
+}
+
+// ErrCouldNotTransactionallyDelete occurs when a limited delete matches more relationships
+// than the limit, and partial deletions were not allowed.
+type ErrCouldNotTransactionallyDelete struct {
+	error
+	limit  uint64
+	filter *v1.RelationshipFilter
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrCouldNotTransactionallyDelete) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Uint64("limit", err.limit).Interface("filter", err.filter)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrCouldNotTransactionallyDelete) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.FailedPrecondition,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+			map[string]string{
+				"limit":  strconv.FormatUint(err.limit, 10),
+				"filter": err.filter.String(),
+			},
+		),
+	)
+}
+
+// NewCouldNotTransactionallyDeleteErr creates a new error representing that a limited delete
+// matched more relationships than the limit.
+func NewCouldNotTransactionallyDeleteErr(filter *v1.RelationshipFilter, limit uint64) ErrCouldNotTransactionallyDelete {
+	return ErrCouldNotTransactionallyDelete{
+		error:  fmt.Errorf("found more than %d relationships to be deleted and partial deletion was not allowed", limit),
+		limit:  limit,
+		filter: filter,
+	}
+}
+
 // ErrPreconditionFailed occurs when the precondition to a write tuple call does not match.
 type ErrPreconditionFailed struct {
 	error
@@ -199,6 +312,7 @@ func rewriteError(ctx context.Context, err error) error {
 	var compilerError compiler.BaseCompilerError
 	var sourceError spiceerrors.ErrorWithSource
 	var typeError namespace.TypeError
+	var quotaExceededError relationships.ErrRelationshipQuotaExceeded
 
 	switch {
 	case errors.As(err, &typeError):
@@ -223,6 +337,13 @@ func rewriteError(ctx context.Context, err error) error {
 		return spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_CAVEAT)
 	case errors.As(err, &datastore.ErrWatchDisabled{}):
 		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	case errors.As(err, &writehook.ErrWriteRejected{}):
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	case errors.As(err, &quotaExceededError):
+		return quotaExceededError.GRPCStatus().Err()
+
+	case errors.As(err, &cursor.InvalidCursorError{}):
+		return spiceerrors.WithCodeAndReason(err, codes.InvalidArgument, v1.ErrorReason_ERROR_REASON_INVALID_CURSOR)
 
 	case errors.As(err, &graph.ErrInvalidArgument{}):
 		return status.Errorf(codes.InvalidArgument, "%s", err)
@@ -0,0 +1,562 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
+	"github.com/authzed/spicedb/internal/middleware"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/handwrittenvalidation"
+	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/cursor"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/datastore/pagination"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/util"
+	"github.com/authzed/spicedb/pkg/writehook"
+)
+
+// bulkCheckGroupConcurrencyLimit is the maximum number of groups of a bulk check that are
+// dispatched in parallel.
+const bulkCheckGroupConcurrencyLimit = 10
+
+// NewExperimentalServer creates a ExperimentalServiceServer instance.
+func NewExperimentalServer(dispatch dispatch.Dispatcher, config PermissionsServerConfig) v1.ExperimentalServiceServer {
+	configWithDefaults := config.withDefaults()
+
+	return &experimentalServer{
+		dispatch: dispatch,
+		config:   configWithDefaults,
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(true),
+				handwrittenvalidation.UnaryServerInterceptor,
+				usagemetrics.UnaryServerInterceptor(),
+			),
+			Stream: middleware.ChainStreamServer(
+				grpcvalidate.StreamServerInterceptor(true),
+				handwrittenvalidation.StreamServerInterceptor,
+				usagemetrics.StreamServerInterceptor(),
+				streamtimeout.MustStreamServerInterceptor(configWithDefaults.StreamingAPITimeout),
+			),
+		},
+	}
+}
+
+type experimentalServer struct {
+	v1.UnimplementedExperimentalServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	dispatch dispatch.Dispatcher
+	config   PermissionsServerConfig
+}
+
+// bulkCheckGroup is a set of bulk check items which share the same resource type, permission,
+// subject and caveat context, and can therefore be checked by a single dispatch.
+type bulkCheckGroup struct {
+	resourceType  *core.RelationReference
+	subject       *core.ObjectAndRelation
+	caveatContext *structpb.Struct
+
+	// resourceIDs are the distinct resource IDs to check, in order of first appearance.
+	resourceIDs     []string
+	seenResourceIDs *util.Set[string]
+
+	// itemIndexes are the indexes of the request items belonging to this group.
+	itemIndexes []int
+}
+
+func (es *experimentalServer) BulkCheckPermission(ctx context.Context, req *v1.BulkCheckPermissionRequest) (*v1.BulkCheckPermissionResponse, error) {
+	if len(req.Items) > int(es.config.MaxBulkCheckItems) {
+		return nil, rewriteError(ctx, NewExceedsMaximumChecksErr(uint64(len(req.Items)), es.config.MaxBulkCheckItems))
+	}
+
+	atRevision, checkedAt, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+
+	pairs := make([]*v1.BulkCheckPermissionPair, len(req.Items))
+	setItemError := func(index int, err error) {
+		pairs[index] = &v1.BulkCheckPermissionPair{
+			Request: req.Items[index],
+			Response: &v1.BulkCheckPermissionPair_Error{
+				Error: status.Convert(rewriteError(ctx, err)).Proto(),
+			},
+		}
+	}
+
+	// Group the items by resource type, permission, subject and context, so that each group
+	// can be checked with a single dispatch.
+	groupsByKey := make(map[string]*bulkCheckGroup, len(req.Items))
+	groups := make([]*bulkCheckGroup, 0, len(req.Items))
+	for index, item := range req.Items {
+		if err := validateBulkCheckItem(item); err != nil {
+			setItemError(index, err)
+			continue
+		}
+
+		key, err := bulkCheckGroupKey(item)
+		if err != nil {
+			setItemError(index, err)
+			continue
+		}
+
+		group, ok := groupsByKey[key]
+		if !ok {
+			group = &bulkCheckGroup{
+				resourceType: &core.RelationReference{
+					Namespace: item.Resource.ObjectType,
+					Relation:  item.Permission,
+				},
+				subject: &core.ObjectAndRelation{
+					Namespace: item.Subject.Object.ObjectType,
+					ObjectId:  item.Subject.Object.ObjectId,
+					Relation:  normalizeSubjectRelation(item.Subject),
+				},
+				caveatContext:   item.Context,
+				seenResourceIDs: util.NewSet[string](),
+			}
+			groupsByKey[key] = group
+			groups = append(groups, group)
+		}
+
+		group.itemIndexes = append(group.itemIndexes, index)
+		if group.seenResourceIDs.Add(item.Resource.ObjectId) {
+			group.resourceIDs = append(group.resourceIDs, item.Resource.ObjectId)
+		}
+	}
+
+	respMetadata := &dispatchv1.ResponseMeta{}
+	var mu sync.Mutex
+
+	errG, groupsCtx := errgroup.WithContext(ctx)
+	errG.SetLimit(bulkCheckGroupConcurrencyLimit)
+	for _, group := range groups {
+		group := group
+		errG.Go(func() error {
+			results, groupMetadata, err := es.checkBulkGroup(groupsCtx, ds, atRevision, group)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if groupMetadata != nil {
+				dispatch.AddResponseMetadata(respMetadata, groupMetadata)
+			}
+
+			for _, index := range group.itemIndexes {
+				if err != nil {
+					setItemError(index, err)
+					continue
+				}
+
+				permissionship, partialCaveat := checkResultToAPITypes(results[req.Items[index].Resource.ObjectId])
+				pairs[index] = &v1.BulkCheckPermissionPair{
+					Request: req.Items[index],
+					Response: &v1.BulkCheckPermissionPair_Item{
+						Item: &v1.BulkCheckPermissionResponseItem{
+							Permissionship:    permissionship,
+							PartialCaveatInfo: partialCaveat,
+						},
+					},
+				}
+			}
+			return nil
+		})
+	}
+
+	// Errors are returned per item, so the group itself never fails.
+	_ = errG.Wait()
+	usagemetrics.SetInContext(ctx, respMetadata)
+
+	if err := ctx.Err(); err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	return &v1.BulkCheckPermissionResponse{
+		CheckedAt: checkedAt,
+		Pairs:     pairs,
+	}, nil
+}
+
+// checkBulkGroup checks all the resources of the group, dispatching them in chunks.
+func (es *experimentalServer) checkBulkGroup(
+	ctx context.Context,
+	ds datastore.Reader,
+	atRevision datastore.Revision,
+	group *bulkCheckGroup,
+) (map[string]*dispatchv1.ResourceCheckResult, *dispatchv1.ResponseMeta, error) {
+	caveatContext, err := GetCaveatContext(ctx, group.caveatContext, es.config.MaxCaveatContextSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	errG, checksCtx := errgroup.WithContext(ctx)
+	errG.Go(func() error {
+		return namespace.CheckNamespaceAndRelation(
+			checksCtx,
+			group.resourceType.Namespace,
+			group.resourceType.Relation,
+			false,
+			ds,
+		)
+	})
+	errG.Go(func() error {
+		return namespace.CheckNamespaceAndRelation(
+			checksCtx,
+			group.subject.Namespace,
+			group.subject.Relation,
+			true,
+			ds,
+		)
+	})
+	if err := errG.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	groupMetadata := &dispatchv1.ResponseMeta{}
+	results := make(map[string]*dispatchv1.ResourceCheckResult, len(group.resourceIDs))
+	chunkSize := int(datastore.FilterMaximumIDCount)
+	for start := 0; start < len(group.resourceIDs); start += chunkSize {
+		end := start + chunkSize
+		if end > len(group.resourceIDs) {
+			end = len(group.resourceIDs)
+		}
+
+		chunkResults, chunkMetadata, err := computed.ComputeBulkCheck(ctx, es.dispatch,
+			computed.CheckParameters{
+				ResourceType:  group.resourceType,
+				Subject:       group.subject,
+				CaveatContext: caveatContext,
+				AtRevision:    atRevision,
+				MaximumDepth:  es.config.MaximumAPIDepth,
+				DebugOption:   computed.NoDebugging,
+			},
+			group.resourceIDs[start:end],
+		)
+		if chunkMetadata != nil {
+			dispatch.AddResponseMetadata(groupMetadata, chunkMetadata)
+		}
+		if err != nil {
+			return nil, groupMetadata, err
+		}
+
+		for resourceID, result := range chunkResults {
+			results[resourceID] = result
+		}
+	}
+
+	return results, groupMetadata, nil
+}
+
+// validateBulkCheckItem performs the validation for a single bulk check item which would be
+// performed by the handwritten validation of a CheckPermissionRequest.
+func validateBulkCheckItem(item *v1.BulkCheckPermissionRequestItem) error {
+	if item.Resource.ObjectId == tuple.PublicWildcard {
+		return status.Errorf(codes.InvalidArgument, "invalid resource ID `%s`: alphanumeric value is required", item.Resource.ObjectId)
+	}
+
+	if err := item.Subject.HandwrittenValidate(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	return nil
+}
+
+// bulkCheckGroupKey returns the key under which the item is grouped with others sharing the
+// same resource type, permission, subject and caveat context.
+func bulkCheckGroupKey(item *v1.BulkCheckPermissionRequestItem) (string, error) {
+	contextString, err := tuple.StringCaveatContext(item.Context)
+	if err != nil {
+		return "", err
+	}
+
+	return item.Resource.ObjectType + "#" + item.Permission + "@" + tuple.StringSubjectRef(item.Subject) + "[" + contextString + "]", nil
+}
+
+// bulkExportCursorHash is the call hash encoded into BulkExportRelationships cursors. The
+// export has no parameters other than the cursor and limit, so it is constant.
+const bulkExportCursorHash = "BulkExportRelationships"
+
+// bulkLoadAdapter adapts a bulk import stream into a datastore.BulkWriteRelationshipSource,
+// validating each batch of relationships as it is received.
+type bulkLoadAdapter struct {
+	stream v1.ExperimentalService_BulkImportRelationshipsServer
+	rwt    datastore.ReadWriteTransaction
+	hooks  []writehook.PreCommitHook
+	quotas *relationships.BulkLoadQuotaChecker
+
+	currentBatch []*core.RelationTuple
+	numSent      int
+	done         bool
+}
+
+func (a *bulkLoadAdapter) Next(ctx context.Context) (*core.RelationTuple, error) {
+	for !a.done && a.numSent == len(a.currentBatch) {
+		batch, err := a.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			a.done = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updates := make([]*core.RelationTupleUpdate, 0, len(batch.Relationships))
+		for _, rel := range batch.Relationships {
+			if err := rel.HandwrittenValidate(); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+			}
+			tpl, err := tuple.FromRelationship(rel)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+			}
+			updates = append(updates, tuple.Create(tpl))
+		}
+
+		if err := relationships.ValidateRelationshipUpdates(ctx, a.rwt, updates); err != nil {
+			return nil, err
+		}
+
+		if len(a.hooks) > 0 {
+			updates, err = a.runPreCommitHooks(ctx, updates)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		a.currentBatch = make([]*core.RelationTuple, 0, len(updates))
+		for _, update := range updates {
+			a.currentBatch = append(a.currentBatch, update.Tuple)
+		}
+		a.numSent = 0
+
+		if err := a.quotas.CheckBatch(a.currentBatch); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.done {
+		return nil, nil
+	}
+
+	rt := a.currentBatch[a.numSent]
+	a.numSent++
+	return rt, nil
+}
+
+// runPreCommitHooks invokes the pre-commit hooks with the creation of each relationship in a
+// batch, as they would be for a WriteRelationships call creating the batch, and returns the
+// updates to load.
+func (a *bulkLoadAdapter) runPreCommitHooks(ctx context.Context, updates []*core.RelationTupleUpdate) ([]*core.RelationTupleUpdate, error) {
+	hookedUpdates, err := writehook.RunPreCommitHooks(ctx, a.rwt, a.hooks, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relationships can only be created by a bulk load, so hooks which rewrite the batch to
+	// delete relationships cannot be applied.
+	for _, update := range hookedUpdates {
+		if update.Operation == core.RelationTupleUpdate_DELETE {
+			return nil, status.Errorf(codes.FailedPrecondition, "pre-commit hooks returned a deletion of %s, which cannot be applied by a bulk import", tuple.StringWithoutCaveat(update.Tuple))
+		}
+	}
+
+	// Hooks may annotate the updates, so those they return are validated again.
+	if err := relationships.ValidateRelationshipUpdates(ctx, a.rwt, hookedUpdates); err != nil {
+		return nil, err
+	}
+	return hookedUpdates, nil
+}
+
+func (es *experimentalServer) BulkImportRelationships(stream v1.ExperimentalService_BulkImportRelationshipsServer) error {
+	ctx := stream.Context()
+	ds := datastoremw.MustFromContext(ctx)
+
+	var numLoaded uint64
+	attempted := false
+	_, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		// The relationships are read from the stream as they are loaded, so the transaction
+		// cannot be replayed if the datastore chooses to retry it.
+		if attempted {
+			return status.Errorf(codes.Aborted, "bulk import transaction was aborted and cannot be retried; please retry the import")
+		}
+		attempted = true
+
+		quotas, err := relationships.NewBulkLoadQuotaChecker(ctx, rwt, es.config.RelationshipQuotas)
+		if err != nil {
+			return err
+		}
+
+		loaded, err := rwt.BulkLoad(ctx, &bulkLoadAdapter{
+			stream: stream,
+			rwt:    rwt,
+			hooks:  es.config.PreCommitHooks,
+			quotas: quotas,
+		})
+		if err != nil {
+			return err
+		}
+
+		numLoaded = loaded
+		return nil
+	})
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: 1,
+	})
+
+	return stream.SendAndClose(&v1.BulkImportRelationshipsResponse{
+		NumLoaded: numLoaded,
+	})
+}
+
+func (es *experimentalServer) BulkExportRelationships(req *v1.BulkExportRelationshipsRequest, resp v1.ExperimentalService_BulkExportRelationshipsServer) error {
+	ctx := resp.Context()
+	atRevision, _, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	reader := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Definition.Name < namespaces[j].Definition.Name
+	})
+
+	// Relationships are exported ordered by resource type and then by resource, so the cursor
+	// records the resource type along with the last relationship returned.
+	var startNamespace string
+	var startCursor options.Cursor
+	if req.OptionalCursor != nil {
+		decoded, err := cursor.DecodeToDispatchCursor(req.OptionalCursor, bulkExportCursorHash)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+
+		if len(decoded.Sections) != 2 {
+			return rewriteError(ctx, cursor.NewInvalidCursorErr(fmt.Errorf("expected two cursor sections, found %d", len(decoded.Sections))))
+		}
+
+		startNamespace = decoded.Sections[0]
+		startCursor = tuple.Parse(decoded.Sections[1])
+		if startCursor == nil {
+			return rewriteError(ctx, cursor.NewInvalidCursorErr(fmt.Errorf("invalid relationship in cursor: %s", decoded.Sections[1])))
+		}
+	}
+
+	batchSize := es.config.MaxDatastoreReadPageSize
+	if req.OptionalLimit > 0 && uint64(req.OptionalLimit) < batchSize {
+		batchSize = uint64(req.OptionalLimit)
+	}
+
+	batch := make([]*v1.Relationship, 0, batchSize)
+	var lastNamespace string
+	var lastTuple *core.RelationTuple
+	sendBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		encodedCursor, err := cursor.EncodeFromDispatchCursor(ctx, &dispatchv1.Cursor{
+			Sections:        []string{lastNamespace, tuple.StringWithoutCaveat(lastTuple)},
+			DispatchVersion: 1,
+		}, bulkExportCursorHash, atRevision)
+		if err != nil {
+			return err
+		}
+
+		if err := resp.Send(&v1.BulkExportRelationshipsResponse{
+			AfterResultCursor: encodedCursor,
+			Relationships:     batch,
+		}); err != nil {
+			return err
+		}
+
+		batch = make([]*v1.Relationship, 0, batchSize)
+		return nil
+	}
+
+	for _, nsDef := range namespaces {
+		nsName := nsDef.Definition.Name
+		if nsName < startNamespace {
+			continue
+		}
+
+		var nsStartCursor options.Cursor
+		if nsName == startNamespace {
+			nsStartCursor = startCursor
+		}
+
+		iter, err := pagination.NewPaginatedIterator(
+			ctx,
+			reader,
+			datastore.RelationshipsFilter{ResourceType: nsName},
+			batchSize,
+			options.ByResource,
+			nsStartCursor,
+		)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+
+		for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+			batch = append(batch, tuple.ToRelationship(tpl))
+			lastNamespace = nsName
+			lastTuple = tpl
+
+			if uint64(len(batch)) == batchSize {
+				if err := sendBatch(); err != nil {
+					iter.Close()
+					return rewriteError(ctx, err)
+				}
+			}
+		}
+		if iter.Err() != nil {
+			iter.Close()
+			return rewriteError(ctx, iter.Err())
+		}
+		iter.Close()
+	}
+
+	if err := sendBatch(); err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: uint32(len(namespaces)) + 1,
+	})
+
+	return nil
+}
@@ -0,0 +1,487 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/writehook"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+func TestBulkCheckPermission(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	type expectedResult struct {
+		permissionship v1.CheckPermissionResponse_Permissionship
+		code           codes.Code
+	}
+
+	items := []struct {
+		item     *v1.BulkCheckPermissionRequestItem
+		expected expectedResult
+	}{
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "masterplan"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, codes.OK},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "healthplan"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, codes.OK},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "masterplan"), Permission: "view", Subject: sub("user", "villain", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, codes.OK},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "healthplan"), Permission: "view", Subject: sub("user", "chief_financial_officer", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, codes.OK},
+		},
+		{
+			// Duplicate of the first item.
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "masterplan"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, codes.OK},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "masterplan"), Permission: "invalidrelation", Subject: sub("user", "eng_lead", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, codes.FailedPrecondition},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("invalidnamespace", "masterplan"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, codes.FailedPrecondition},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "*"), Permission: "view", Subject: sub("user", "eng_lead", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, codes.InvalidArgument},
+		},
+		{
+			&v1.BulkCheckPermissionRequestItem{Resource: obj("document", "masterplan"), Permission: "view", Subject: sub("user", "*", "")},
+			expectedResult{v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, codes.InvalidArgument},
+		},
+	}
+
+	requestItems := make([]*v1.BulkCheckPermissionRequestItem, 0, len(items))
+	for _, item := range items {
+		requestItems = append(requestItems, item.item)
+	}
+
+	var trailer metadata.MD
+	resp, err := client.BulkCheckPermission(context.Background(), &v1.BulkCheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
+			},
+		},
+		Items: requestItems,
+	}, grpc.Trailer(&trailer))
+	require.NoError(err)
+	require.NotNil(resp.CheckedAt)
+	require.Len(resp.Pairs, len(items))
+
+	for index, pair := range resp.Pairs {
+		expected := items[index].expected
+		require.Equal(items[index].item.Resource.ObjectId, pair.Request.Resource.ObjectId)
+
+		if expected.code == codes.OK {
+			require.NotNil(pair.GetItem(), "expected result for item %d, found %v", index, pair.GetError())
+			require.Equal(expected.permissionship, pair.GetItem().Permissionship)
+			continue
+		}
+
+		require.NotNil(pair.GetError(), "expected error for item %d", index)
+		require.Equal(int32(expected.code), pair.GetError().Code)
+	}
+
+	dispatchCount, err := responsemeta.GetIntResponseTrailerMetadata(trailer, responsemeta.DispatchedOperationsCount)
+	require.NoError(err)
+	require.Greater(dispatchCount, 0)
+}
+
+func TestBulkCheckPermissionWithCaveats(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithCaveatedData)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	correctContext, err := structpb.NewStruct(map[string]any{"secret": "1234"})
+	require.NoError(err)
+
+	incorrectContext, err := structpb.NewStruct(map[string]any{"secret": "incorrect_value"})
+	require.NoError(err)
+
+	tooLargeContext, err := structpb.NewStruct(generateMap(64))
+	require.NoError(err)
+
+	resp, err := client.BulkCheckPermission(context.Background(), &v1.BulkCheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
+			},
+		},
+		Items: []*v1.BulkCheckPermissionRequestItem{
+			{Resource: obj("document", "companyplan"), Permission: "view", Subject: sub("user", "owner", ""), Context: correctContext},
+			{Resource: obj("document", "companyplan"), Permission: "view", Subject: sub("user", "owner", ""), Context: incorrectContext},
+			{Resource: obj("document", "companyplan"), Permission: "view", Subject: sub("user", "owner", "")},
+			{Resource: obj("document", "companyplan"), Permission: "view", Subject: sub("user", "owner", ""), Context: tooLargeContext},
+		},
+	})
+	require.NoError(err)
+	require.Len(resp.Pairs, 4)
+
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, resp.Pairs[0].GetItem().Permissionship)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, resp.Pairs[1].GetItem().Permissionship)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION, resp.Pairs[2].GetItem().Permissionship)
+	require.Equal([]string{"secret"}, resp.Pairs[2].GetItem().PartialCaveatInfo.MissingRequiredContext)
+	require.Equal(int32(codes.InvalidArgument), resp.Pairs[3].GetError().Code)
+}
+
+func TestBulkCheckPermissionTooManyItems(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	items := make([]*v1.BulkCheckPermissionRequestItem, 0, 1001)
+	for i := 0; i < 1001; i++ {
+		items = append(items, &v1.BulkCheckPermissionRequestItem{
+			Resource:   obj("document", "masterplan"),
+			Permission: "view",
+			Subject:    sub("user", "eng_lead", ""),
+		})
+	}
+
+	_, err := client.BulkCheckPermission(context.Background(), &v1.BulkCheckPermissionRequest{
+		Items: items,
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestBulkExportRelationships(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	// Export the schema, and then the relationships at the same revision.
+	schemaResp, err := experimentalv1.NewExperimentalSchemaServiceClient(conn).ExportSchema(context.Background(), &experimentalv1.ExportSchemaRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
+			},
+		},
+	})
+	require.NoError(err)
+	require.Contains(schemaResp.SchemaText, "definition document")
+
+	consistency := &v1.Consistency{
+		Requirement: &v1.Consistency_AtExactSnapshot{
+			AtExactSnapshot: schemaResp.ReadAt,
+		},
+	}
+
+	// Export everything in small batches.
+	stream, err := client.BulkExportRelationships(context.Background(), &v1.BulkExportRelationshipsRequest{
+		Consistency:   consistency,
+		OptionalLimit: 5,
+	})
+	require.NoError(err)
+
+	var exported []string
+	var cursors []*v1.Cursor
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		require.LessOrEqual(len(resp.Relationships), 5)
+		require.NotNil(resp.AfterResultCursor)
+
+		for _, rel := range resp.Relationships {
+			exported = append(exported, tuple.MustRelString(rel))
+		}
+		cursors = append(cursors, resp.AfterResultCursor)
+	}
+
+	expected := make([]string, 0, len(tf.StandardTuples))
+	for _, tpl := range tf.StandardTuples {
+		expected = append(expected, tuple.MustRelString(tuple.MustToRelationship(tuple.MustParse(tpl))))
+	}
+	require.ElementsMatch(expected, exported)
+	require.Greater(len(cursors), 1)
+
+	// Resume from the first cursor, which must return everything after the first batch.
+	resumed, err := client.BulkExportRelationships(context.Background(), &v1.BulkExportRelationshipsRequest{
+		OptionalCursor: cursors[0],
+	})
+	require.NoError(err)
+
+	var remaining []string
+	for {
+		resp, err := resumed.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		for _, rel := range resp.Relationships {
+			remaining = append(remaining, tuple.MustRelString(rel))
+		}
+	}
+	require.Equal(exported[5:], remaining)
+}
+
+func TestBulkImportRelationships(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithSchema)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	rels := make([]*v1.Relationship, 0, len(tf.StandardTuples))
+	for _, tpl := range tf.StandardTuples {
+		rels = append(rels, tuple.MustToRelationship(tuple.MustParse(tpl)))
+	}
+
+	// Expiration is imported and exported along with the relationship.
+	tuple.SetRelationshipExpiration(rels[0], timestamppb.New(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	stream, err := client.BulkImportRelationships(context.Background())
+	require.NoError(err)
+
+	// Send the relationships in uneven batches.
+	for start := 0; start < len(rels); start += 3 {
+		end := start + 3
+		if end > len(rels) {
+			end = len(rels)
+		}
+		require.NoError(stream.Send(&v1.BulkImportRelationshipsRequest{
+			Relationships: rels[start:end],
+		}))
+	}
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(err)
+	require.Equal(uint64(len(rels)), resp.NumLoaded)
+
+	readStream, err := v1.NewPermissionsServiceClient(conn).ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	require.NoError(err)
+
+	var found []string
+	for {
+		resp, err := readStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		found = append(found, tuple.MustRelString(resp.Relationship))
+	}
+
+	var expected []string
+	for _, rel := range rels {
+		if rel.Resource.ObjectType == "document" {
+			expected = append(expected, tuple.MustRelString(rel))
+		}
+	}
+	sort.Strings(expected)
+	sort.Strings(found)
+	require.Equal(expected, found)
+
+	exportStream, err := client.BulkExportRelationships(context.Background(), &v1.BulkExportRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+	})
+	require.NoError(err)
+
+	var exported []string
+	for {
+		resp, err := exportStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		for _, rel := range resp.Relationships {
+			exported = append(exported, tuple.MustRelString(rel))
+		}
+	}
+
+	var imported []string
+	for _, rel := range rels {
+		imported = append(imported, tuple.MustRelString(rel))
+	}
+	sort.Strings(imported)
+	sort.Strings(exported)
+	require.Equal(imported, exported)
+	require.Contains(exported, "document:companyplan#parent@folder:company[expiration:2100-01-01T00:00:00Z]")
+}
+
+func TestBulkImportRelationshipsPreCommitHooks(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount: 1000,
+			MaxUpdatesPerWrite:    1000,
+			PreCommitHooks:        []writehook.PreCommitHook{touchHook{}, viewerLimitHook{maxDocuments: 1}},
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	importRels := func(rels ...*v1.Relationship) (*v1.BulkImportRelationshipsResponse, error) {
+		stream, err := client.BulkImportRelationships(context.Background())
+		require.NoError(err)
+		require.NoError(stream.Send(&v1.BulkImportRelationshipsRequest{Relationships: rels}))
+		return stream.CloseAndRecv()
+	}
+
+	// eng_lead is already a viewer of the masterplan document.
+	_, err := importRels(
+		rel("document", "healthplan", "owner", "user", "newowner", ""),
+		rel("document", "healthplan", "viewer", "user", "eng_lead", ""),
+	)
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "write rejected by hook `viewer-limit`: user:eng_lead cannot view more than 1 documents")
+
+	resp, err := importRels(rel("document", "healthplan", "viewer", "user", "newviewer", ""))
+	require.NoError(err)
+	require.Equal(uint64(1), resp.NumLoaded)
+
+	readStream, err := v1.NewPermissionsServiceClient(conn).ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "healthplan"},
+	})
+	require.NoError(err)
+
+	var found []string
+	for {
+		resp, err := readStream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(err)
+		found = append(found, tuple.MustRelString(resp.Relationship))
+	}
+	require.Contains(found, "document:healthplan#viewer@user:newviewer")
+	require.NotContains(found, "document:healthplan#owner@user:newowner")
+}
+
+func TestBulkImportRelationshipsQuotas(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount: 1000,
+			MaxUpdatesPerWrite:    1000,
+			RelationshipQuotas:    []string{"document#viewer=3"},
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewExperimentalServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	importBatches := func(batches ...[]*v1.Relationship) (*v1.BulkImportRelationshipsResponse, error) {
+		stream, err := client.BulkImportRelationships(context.Background())
+		require.NoError(err)
+		for _, batch := range batches {
+			require.NoError(stream.Send(&v1.BulkImportRelationshipsRequest{Relationships: batch}))
+		}
+		return stream.CloseAndRecv()
+	}
+
+	// The standard data has one document viewer, so the quota is exceeded by the second batch.
+	_, err := importBatches(
+		[]*v1.Relationship{
+			rel("document", "healthplan", "viewer", "user", "first", ""),
+			rel("document", "healthplan", "owner", "user", "first", ""),
+		},
+		[]*v1.Relationship{
+			rel("document", "healthplan", "viewer", "user", "second", ""),
+			rel("document", "healthplan", "viewer", "user", "third", ""),
+		},
+	)
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+	require.ErrorContains(err, "`document#viewer` is limited to 3 relationships, but the write would store 4")
+
+	resp, err := importBatches(
+		[]*v1.Relationship{rel("document", "healthplan", "viewer", "user", "first", "")},
+		[]*v1.Relationship{rel("document", "healthplan", "viewer", "user", "second", "")},
+	)
+	require.NoError(err)
+	require.Equal(uint64(2), resp.NumLoaded)
+}
+
+func invalidExpirationRel() *v1.Relationship {
+	invalid := rel("document", "foo", "viewer", "user", "tom", "")
+	invalid.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 5, protowire.VarintType), 1))
+	return invalid
+}
+
+func TestBulkImportRelationshipsErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		relationship *v1.Relationship
+		expectedCode codes.Code
+	}{
+		{"unknown resource type", rel("unknown", "foo", "viewer", "user", "tom", ""), codes.FailedPrecondition},
+		{"unknown relation", rel("document", "foo", "unknown", "user", "tom", ""), codes.FailedPrecondition},
+		{"disallowed subject type", rel("document", "foo", "viewer", "folder", "company", ""), codes.InvalidArgument},
+		{"wildcard resource", rel("document", "*", "viewer", "user", "tom", ""), codes.InvalidArgument},
+		{"invalid expiration", invalidExpirationRel(), codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, _ := testserver.NewTestServer(require, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithData)
+			client := v1.NewExperimentalServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			stream, err := client.BulkImportRelationships(context.Background())
+			require.NoError(err)
+
+			require.NoError(stream.Send(&v1.BulkImportRelationshipsRequest{
+				Relationships: []*v1.Relationship{tc.relationship},
+			}))
+
+			_, err = stream.CloseAndRecv()
+			grpcutil.RequireStatus(t, tc.expectedCode, err)
+		})
+	}
+}
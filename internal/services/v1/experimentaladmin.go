@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"context"
+
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+)
+
+// NewExperimentalAdminServer creates an ExperimentalAdminServiceServer instance.
+func NewExperimentalAdminServer() experimentalv1.ExperimentalAdminServiceServer {
+	return &experimentalAdminServer{
+		WithUnaryServiceSpecificInterceptor: shared.WithUnaryServiceSpecificInterceptor{
+			Unary: grpcvalidate.UnaryServerInterceptor(true),
+		},
+	}
+}
+
+type experimentalAdminServer struct {
+	experimentalv1.UnimplementedExperimentalAdminServiceServer
+	shared.WithUnaryServiceSpecificInterceptor
+}
+
+func (as *experimentalAdminServer) DatastoreStatistics(ctx context.Context, _ *experimentalv1.DatastoreStatisticsRequest) (*experimentalv1.DatastoreStatisticsResponse, error) {
+	ds := datastoremw.MustFromContext(ctx)
+
+	stats, err := ds.DetailedStatistics(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	return ToDatastoreStatisticsResponse(stats), nil
+}
+
+// ToDatastoreStatisticsResponse converts the detailed statistics of a datastore into the
+// response of the DatastoreStatistics API.
+func ToDatastoreStatisticsResponse(stats datastore.DetailedStats) *experimentalv1.DatastoreStatisticsResponse {
+	definitions := make([]*experimentalv1.DefinitionStatistics, 0, len(stats.Definitions))
+	for _, def := range stats.Definitions {
+		relations := make([]*experimentalv1.RelationStatistics, 0, len(def.Relations))
+		for _, rel := range def.Relations {
+			relations = append(relations, &experimentalv1.RelationStatistics{
+				Name:              rel.Relation,
+				RelationshipCount: rel.RelationshipCount,
+			})
+		}
+
+		definitions = append(definitions, &experimentalv1.DefinitionStatistics{
+			Name:              def.Definition,
+			RelationshipCount: def.RelationshipCount,
+			Relations:         relations,
+		})
+	}
+
+	return &experimentalv1.DatastoreStatisticsResponse{
+		CountsAreEstimates: stats.CountsAreEstimates,
+		Definitions:        definitions,
+		StorageSizeBytes:   stats.StorageSizeBytes,
+		ChangelogBacklog:   stats.ChangelogBacklog,
+	}
+}
@@ -0,0 +1,38 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+)
+
+func TestDatastoreStatistics(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := experimentalv1.NewExperimentalAdminServiceClient(conn)
+
+	resp, err := client.DatastoreStatistics(context.Background(), &experimentalv1.DatastoreStatisticsRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.CountsAreEstimates)
+
+	var names []string
+	var total uint64
+	for _, def := range resp.Definitions {
+		names = append(names, def.Name)
+		total += def.RelationshipCount
+
+		var relationTotal uint64
+		for _, rel := range def.Relations {
+			relationTotal += rel.RelationshipCount
+		}
+		require.Equal(t, def.RelationshipCount, relationTotal)
+	}
+	require.Equal(t, []string{"document", "folder"}, names)
+	require.Equal(t, uint64(len(tf.StandardTuples)), total)
+}
@@ -0,0 +1,331 @@
+package v1
+
+import (
+	"context"
+	"sort"
+
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/caveats/types"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/util"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// NewExperimentalSchemaServer creates an ExperimentalSchemaServiceServer instance.
+func NewExperimentalSchemaServer(additiveOnly bool) experimentalv1.ExperimentalSchemaServiceServer {
+	return &experimentalSchemaServer{
+		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
+			Unary: middleware.ChainUnaryServer(
+				grpcvalidate.UnaryServerInterceptor(true),
+				usagemetrics.UnaryServerInterceptor(),
+			),
+			Stream: middleware.ChainStreamServer(
+				grpcvalidate.StreamServerInterceptor(true),
+				usagemetrics.StreamServerInterceptor(),
+			),
+		},
+		additiveOnly: additiveOnly,
+	}
+}
+
+type experimentalSchemaServer struct {
+	experimentalv1.UnimplementedExperimentalSchemaServiceServer
+	shared.WithServiceSpecificInterceptors
+
+	additiveOnly bool
+}
+
+func (es *experimentalSchemaServer) DiffSchema(ctx context.Context, req *experimentalv1.DiffSchemaRequest) (*experimentalv1.DiffSchemaResponse, error) {
+	log.Ctx(ctx).Trace().Str("schema", req.GetSchema()).Msg("requested Schema to be diffed")
+
+	ds := datastoremw.MustFromContext(ctx)
+
+	// The schema is given as a single file, so it is compiled without a file loader and any
+	// import directive is rejected as a schema parse error.
+	emptyDefaultPrefix := ""
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: req.GetSchema(),
+	}, &emptyDefaultPrefix)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	validated, err := shared.ValidateSchemaChanges(ctx, compiled, es.additiveOnly)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	diff, err := shared.DryRunSchemaChanges(ctx, ds, validated)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	resp, err := ToDiffSchemaResponse(diff)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: uint32(len(compiled.ObjectDefinitions) + len(compiled.CaveatDefinitions)),
+	})
+
+	return resp, nil
+}
+
+func (es *experimentalSchemaServer) ReflectSchema(ctx context.Context, req *experimentalv1.ReflectSchemaRequest) (*experimentalv1.ReflectSchemaResponse, error) {
+	// Schema is always read from the head revision.
+	ds := datastoremw.MustFromContext(ctx)
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	reader := ds.SnapshotReader(headRevision)
+	nsDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	caveatDefs, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	filter := util.NewSet(req.OptionalDefinitionNames...)
+	included := func(name string) bool {
+		return filter.IsEmpty() || filter.Has(name)
+	}
+
+	definitions := make([]*experimentalv1.ReflectionDefinition, 0, len(nsDefs))
+	for _, nsDef := range nsDefs {
+		if !included(nsDef.Definition.Name) {
+			continue
+		}
+
+		ts, err := namespace.NewNamespaceTypeSystem(nsDef.Definition, namespace.ResolverForDatastoreReader(reader))
+		if err != nil {
+			return nil, rewriteError(ctx, err)
+		}
+
+		def, err := reflectDefinition(ts)
+		if err != nil {
+			return nil, rewriteError(ctx, err)
+		}
+		definitions = append(definitions, def)
+	}
+
+	caveats := make([]*experimentalv1.ReflectionCaveat, 0, len(caveatDefs))
+	for _, caveatDef := range caveatDefs {
+		if !included(caveatDef.Definition.Name) {
+			continue
+		}
+
+		caveat, err := reflectCaveat(caveatDef.Definition)
+		if err != nil {
+			return nil, rewriteError(ctx, err)
+		}
+		caveats = append(caveats, caveat)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	sort.Slice(caveats, func(i, j int) bool { return caveats[i].Name < caveats[j].Name })
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: uint32(len(nsDefs) + len(caveatDefs)),
+	})
+
+	return &experimentalv1.ReflectSchemaResponse{
+		Definitions: definitions,
+		Caveats:     caveats,
+		ReadAt:      zedtoken.MustNewFromRevision(ctx, headRevision),
+	}, nil
+}
+
+func (es *experimentalSchemaServer) ComputablePermissions(ctx context.Context, req *experimentalv1.ComputablePermissionsRequest) (*experimentalv1.ComputablePermissionsResponse, error) {
+	dg, headRevision, err := dependencyGraphAtHead(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	edges, err := dg.TransitiveDependents(tuple.RelationReference(req.DefinitionName, req.RelationName))
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	permissions := reflectRelationsOrPermissions(dg, edges, func(edge namespace.RelationDependency) *core.RelationReference {
+		return edge.Dependent
+	})
+
+	filtered := make([]*experimentalv1.ReflectionRelationOrPermission, 0, len(permissions))
+	for _, permission := range permissions {
+		if permission.IsPermission {
+			filtered = append(filtered, permission)
+		}
+	}
+
+	return &experimentalv1.ComputablePermissionsResponse{
+		Permissions: filtered,
+		Edges:       reflectDependencyEdges(dg, edges),
+		ReadAt:      zedtoken.MustNewFromRevision(ctx, headRevision),
+	}, nil
+}
+
+func (es *experimentalSchemaServer) DependentRelations(ctx context.Context, req *experimentalv1.DependentRelationsRequest) (*experimentalv1.DependentRelationsResponse, error) {
+	dg, headRevision, err := dependencyGraphAtHead(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	rr := tuple.RelationReference(req.DefinitionName, req.PermissionName)
+	edges, err := dg.TransitiveDependencies(rr)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	if !dg.IsPermission(rr) {
+		return nil, status.Errorf(codes.InvalidArgument, "`%s` is not a permission", tuple.StringRR(rr))
+	}
+
+	return &experimentalv1.DependentRelationsResponse{
+		Relations: reflectRelationsOrPermissions(dg, edges, func(edge namespace.RelationDependency) *core.RelationReference {
+			return edge.Dependency
+		}),
+		Edges:  reflectDependencyEdges(dg, edges),
+		ReadAt: zedtoken.MustNewFromRevision(ctx, headRevision),
+	}, nil
+}
+
+func (es *experimentalSchemaServer) ExportSchema(ctx context.Context, _ *experimentalv1.ExportSchemaRequest) (*experimentalv1.ExportSchemaResponse, error) {
+	atRevision, revisionReadAt, err := consistency.RevisionFromContext(ctx)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	reader := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
+	schemaText, nsDefCount, caveatDefCount, err := generateSchemaAtRevision(ctx, reader)
+	if err != nil {
+		return nil, rewriteError(ctx, err)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: uint32(nsDefCount + caveatDefCount),
+	})
+
+	return &experimentalv1.ExportSchemaResponse{
+		SchemaText: schemaText,
+		ReadAt:     revisionReadAt,
+	}, nil
+}
+
+// dependencyGraphAtHead builds the dependency graph of the schema at the head revision.
+func dependencyGraphAtHead(ctx context.Context) (*namespace.DependencyGraph, datastore.Revision, error) {
+	ds := datastoremw.MustFromContext(ctx)
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := ds.SnapshotReader(headRevision)
+	nsDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typeSystems := make([]*namespace.TypeSystem, 0, len(nsDefs))
+	for _, nsDef := range nsDefs {
+		ts, err := namespace.NewNamespaceTypeSystem(nsDef.Definition, namespace.ResolverForDatastoreReader(reader))
+		if err != nil {
+			return nil, nil, err
+		}
+		typeSystems = append(typeSystems, ts)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: uint32(len(nsDefs)),
+	})
+
+	dg, err := namespace.BuildDependencyGraph(typeSystems)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dg, headRevision, nil
+}
+
+// ToDiffSchemaResponse converts a schema diff into its API representation.
+func ToDiffSchemaResponse(diff *shared.SchemaDiff) (*experimentalv1.DiffSchemaResponse, error) {
+	deltas := make([]*experimentalv1.SchemaDelta, 0, len(diff.Deltas))
+	for _, delta := range diff.Deltas {
+		converted, err := toSchemaDelta(delta)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, converted)
+	}
+
+	resp := &experimentalv1.DiffSchemaResponse{
+		Deltas: deltas,
+	}
+	if diff.ApplyError != nil {
+		resp.ApplyError = diff.ApplyError.Error()
+	}
+	return resp, nil
+}
+
+func toSchemaDelta(delta shared.SchemaDelta) (*experimentalv1.SchemaDelta, error) {
+	converted := &experimentalv1.SchemaDelta{
+		DefinitionName: delta.DefinitionName,
+	}
+	if delta.RejectionError != nil {
+		converted.RejectionReason = delta.RejectionError.Error()
+	}
+
+	switch {
+	case delta.NamespaceDelta != nil:
+		converted.DefinitionKind = experimentalv1.SchemaDelta_OBJECT_DEFINITION
+		converted.DeltaType = string(delta.NamespaceDelta.Type)
+		converted.RelationName = delta.NamespaceDelta.RelationName
+		if delta.NamespaceDelta.AllowedType != nil {
+			converted.AllowedType = namespace.SourceForAllowedRelation(delta.NamespaceDelta.AllowedType)
+		}
+
+	case delta.CaveatDelta != nil:
+		converted.DefinitionKind = experimentalv1.SchemaDelta_CAVEAT_DEFINITION
+		converted.DeltaType = string(delta.CaveatDelta.Type)
+		converted.ParameterName = delta.CaveatDelta.ParameterName
+
+		if delta.CaveatDelta.PreviousType != nil {
+			previousType, err := types.DecodeParameterType(delta.CaveatDelta.PreviousType)
+			if err != nil {
+				return nil, err
+			}
+			converted.PreviousParameterType = previousType.String()
+		}
+
+		if delta.CaveatDelta.CurrentType != nil {
+			currentType, err := types.DecodeParameterType(delta.CaveatDelta.CurrentType)
+			if err != nil {
+				return nil, err
+			}
+			converted.CurrentParameterType = currentType.String()
+		}
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,539 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestDiffSchemaInvalidSchema(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := experimentalv1.NewExperimentalSchemaServiceClient(conn)
+
+	_, err := client.DiffSchema(context.Background(), &experimentalv1.DiffSchemaRequest{
+		Schema: `invalid example/user {}`,
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestDiffSchemaWithImports(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := experimentalv1.NewExperimentalSchemaServiceClient(conn)
+
+	_, err := client.DiffSchema(context.Background(), &experimentalv1.DiffSchemaRequest{
+		Schema: `import "common.zed"
+
+definition example/user {}`,
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	require.ErrorContains(t, err, "cannot import `common.zed`: imports are not supported when compiling a single schema")
+}
+
+func TestDiffSchema(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+	v1client := v1.NewPermissionsServiceClient(conn)
+	experimentalClient := experimentalv1.NewExperimentalSchemaServiceClient(conn)
+
+	originalSchema := `caveat someCaveat(somecondition int) {
+			somecondition == 42
+		}
+
+		definition example/user {}
+
+		definition example/document {
+			relation somerelation: example/user | example/user:*
+			relation anotherrelation: example/user
+			permission view = somerelation
+		}`
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: originalSchema,
+	})
+	require.NoError(t, err)
+
+	_, err = v1client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Create(
+			tuple.MustParse("example/document:somedoc#somerelation@example/user:someuser"),
+		))},
+	})
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name               string
+		schema             string
+		expectedDeltas     []*experimentalv1.SchemaDelta
+		expectedApplyError string
+	}{
+		{
+			"no changes",
+			originalSchema,
+			[]*experimentalv1.SchemaDelta{},
+			"",
+		},
+		{
+			"accepted changes",
+			`caveat someCaveat(somecondition int, another string) {
+				somecondition == 42 && another == "42"
+			}
+
+			definition example/user {}
+
+			definition example/document {
+				relation somerelation: example/user | example/user:*
+				permission view = somerelation + somerelation
+			}
+
+			definition example/folder {}`,
+			[]*experimentalv1.SchemaDelta{
+				{
+					DefinitionName: "someCaveat",
+					DefinitionKind: experimentalv1.SchemaDelta_CAVEAT_DEFINITION,
+					DeltaType:      "added-parameter",
+					ParameterName:  "another",
+				},
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "removed-relation",
+					RelationName:   "anotherrelation",
+				},
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "changed-permission-implementation",
+					RelationName:   "view",
+				},
+				{
+					DefinitionName: "example/folder",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "namespace-added",
+				},
+			},
+			"",
+		},
+		{
+			"rejected changes",
+			`caveat someCaveat(somecondition string) {
+				somecondition == "42"
+			}
+
+			definition example/user {}
+
+			definition example/document {
+				relation anotherrelation: example/user
+			}`,
+			[]*experimentalv1.SchemaDelta{
+				{
+					DefinitionName:        "someCaveat",
+					DefinitionKind:        experimentalv1.SchemaDelta_CAVEAT_DEFINITION,
+					DeltaType:             "parameter-type-changed",
+					ParameterName:         "somecondition",
+					PreviousParameterType: "int",
+					CurrentParameterType:  "string",
+					RejectionReason:       "cannot change the type of parameter `somecondition` on caveat `someCaveat`",
+				},
+				{
+					DefinitionName:  "example/document",
+					DefinitionKind:  experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:       "removed-relation",
+					RelationName:    "somerelation",
+					RejectionReason: "cannot delete relation `somerelation` in object definition `example/document`, as a relationship exists under it",
+				},
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "removed-permission",
+					RelationName:   "view",
+				},
+			},
+			"cannot change the type of parameter `somecondition` on caveat `someCaveat`",
+		},
+		{
+			"rejected removal of allowed type and definition",
+			`definition example/document {
+				relation somerelation: example/document
+			}`,
+			[]*experimentalv1.SchemaDelta{
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "removed-relation",
+					RelationName:   "anotherrelation",
+				},
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "removed-permission",
+					RelationName:   "view",
+				},
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "relation-allowed-type-added",
+					RelationName:   "somerelation",
+					AllowedType:    "example/document",
+				},
+				{
+					DefinitionName:  "example/document",
+					DefinitionKind:  experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:       "relation-allowed-type-removed",
+					RelationName:    "somerelation",
+					AllowedType:     "example/user",
+					RejectionReason: "cannot remove allowed type `example/user` from relation `somerelation` in object definition `example/document`, as a relationship exists with it",
+				},
+				{
+					DefinitionName: "example/document",
+					DefinitionKind: experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:      "relation-allowed-type-removed",
+					RelationName:   "somerelation",
+					AllowedType:    "example/user:*",
+				},
+				{
+					DefinitionName: "someCaveat",
+					DefinitionKind: experimentalv1.SchemaDelta_CAVEAT_DEFINITION,
+					DeltaType:      "caveat-removed",
+				},
+				{
+					DefinitionName:  "example/user",
+					DefinitionKind:  experimentalv1.SchemaDelta_OBJECT_DEFINITION,
+					DeltaType:       "namespace-removed",
+					RejectionReason: "cannot delete object definition `example/user`, as a relationship references it",
+				},
+			},
+			"cannot remove allowed type `example/user` from relation `somerelation` in object definition `example/document`, as a relationship exists with it",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := experimentalClient.DiffSchema(context.Background(), &experimentalv1.DiffSchemaRequest{
+				Schema: tc.schema,
+			})
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.expectedDeltas, withoutExpressionDeltas(resp.Deltas))
+			require.Equal(t, tc.expectedApplyError, resp.ApplyError)
+
+			// Ensure the dry run did not change the stored schema.
+			readback, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+			require.NoError(t, err)
+			require.Contains(t, readback.SchemaText, "relation anotherrelation: example/user")
+			require.Contains(t, readback.SchemaText, "caveat someCaveat(somecondition int)")
+		})
+	}
+}
+
+// withoutExpressionDeltas filters out deltas reporting that a caveat expression may have changed,
+// as these are reported based on the serialized expression and can occur for unchanged caveats.
+func withoutExpressionDeltas(deltas []*experimentalv1.SchemaDelta) []*experimentalv1.SchemaDelta {
+	filtered := make([]*experimentalv1.SchemaDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		if delta.DeltaType != "expression-may-have-changed" {
+			filtered = append(filtered, delta)
+		}
+	}
+	return filtered
+}
+
+func TestReflectSchema(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+	experimentalClient := experimentalv1.NewExperimentalSchemaServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `
+		// someCaveat is a caveat
+		caveat someCaveat(somecondition int, tags list<string>) {
+			somecondition == 42 && "a" in tags
+		}
+
+		/** user is a user */
+		definition example/user {}
+
+		definition example/group {
+			relation member: example/user | example/group#member
+		}
+
+		definition example/document {
+			relation parent: example/document
+			// viewer can view
+			relation viewer: example/user | example/user:* | example/group#member | example/user with someCaveat
+			relation banned: example/user
+
+			permission view = (viewer + parent->view) - banned
+			permission nothing = nil
+		}`,
+	})
+	require.NoError(t, err)
+
+	relationRef := func(name string) *experimentalv1.ReflectionPermissionExpression {
+		return &experimentalv1.ReflectionPermissionExpression{
+			Expression: &experimentalv1.ReflectionPermissionExpression_RelationReference{
+				RelationReference: &experimentalv1.ReflectionRelationReference{RelationName: name},
+			},
+		}
+	}
+	operation := func(kind experimentalv1.ReflectionOperation_OperationKind, children ...*experimentalv1.ReflectionPermissionExpression) *experimentalv1.ReflectionPermissionExpression {
+		return &experimentalv1.ReflectionPermissionExpression{
+			Expression: &experimentalv1.ReflectionPermissionExpression_Operation{
+				Operation: &experimentalv1.ReflectionOperation{Kind: kind, Children: children},
+			},
+		}
+	}
+	terminal := func(name string) *experimentalv1.ReflectionTypeReference {
+		return &experimentalv1.ReflectionTypeReference{
+			SubjectDefinitionName: name,
+			Typeref:               &experimentalv1.ReflectionTypeReference_IsTerminalSubject{IsTerminalSubject: true},
+		}
+	}
+
+	documentDef := &experimentalv1.ReflectionDefinition{
+		Name: "example/document",
+		Relations: []*experimentalv1.ReflectionRelation{
+			{
+				Name:                 "parent",
+				ParentDefinitionName: "example/document",
+				SubjectTypes:         []*experimentalv1.ReflectionTypeReference{terminal("example/document")},
+			},
+			{
+				Name:                 "viewer",
+				Comment:              "// viewer can view",
+				ParentDefinitionName: "example/document",
+				SubjectTypes: []*experimentalv1.ReflectionTypeReference{
+					terminal("example/user"),
+					{
+						SubjectDefinitionName: "example/user",
+						Typeref:               &experimentalv1.ReflectionTypeReference_IsPublicWildcard{IsPublicWildcard: true},
+					},
+					{
+						SubjectDefinitionName: "example/group",
+						Typeref:               &experimentalv1.ReflectionTypeReference_OptionalRelationName{OptionalRelationName: "member"},
+					},
+					{
+						SubjectDefinitionName: "example/user",
+						Typeref:               &experimentalv1.ReflectionTypeReference_IsTerminalSubject{IsTerminalSubject: true},
+						OptionalCaveatName:    "someCaveat",
+					},
+				},
+			},
+			{
+				Name:                 "banned",
+				ParentDefinitionName: "example/document",
+				SubjectTypes:         []*experimentalv1.ReflectionTypeReference{terminal("example/user")},
+			},
+		},
+		Permissions: []*experimentalv1.ReflectionPermission{
+			{
+				Name:                 "view",
+				ParentDefinitionName: "example/document",
+				Expression: operation(experimentalv1.ReflectionOperation_EXCLUSION,
+					operation(experimentalv1.ReflectionOperation_UNION,
+						relationRef("viewer"),
+						&experimentalv1.ReflectionPermissionExpression{
+							Expression: &experimentalv1.ReflectionPermissionExpression_Arrow{
+								Arrow: &experimentalv1.ReflectionArrow{
+									TuplesetRelationName: "parent",
+									ComputedRelationName: "view",
+								},
+							},
+						},
+					),
+					relationRef("banned"),
+				),
+			},
+			{
+				Name:                 "nothing",
+				ParentDefinitionName: "example/document",
+				Expression: operation(experimentalv1.ReflectionOperation_UNION,
+					&experimentalv1.ReflectionPermissionExpression{
+						Expression: &experimentalv1.ReflectionPermissionExpression_Nil{Nil: &experimentalv1.ReflectionNil{}},
+					},
+				),
+			},
+		},
+	}
+
+	userDef := &experimentalv1.ReflectionDefinition{
+		Name:        "example/user",
+		Comment:     "/** user is a user */",
+		Relations:   []*experimentalv1.ReflectionRelation{},
+		Permissions: []*experimentalv1.ReflectionPermission{},
+	}
+
+	someCaveat := &experimentalv1.ReflectionCaveat{
+		Name:    "someCaveat",
+		Comment: "// someCaveat is a caveat",
+		Parameters: []*experimentalv1.ReflectionCaveatParameter{
+			{Name: "somecondition", Type: "int", ParentCaveatName: "someCaveat"},
+			{Name: "tags", Type: "list<string>", ParentCaveatName: "someCaveat"},
+		},
+		Expression: `somecondition == 42 && "a" in tags`,
+	}
+
+	resp, err := experimentalClient.ReflectSchema(context.Background(), &experimentalv1.ReflectSchemaRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.ReadAt)
+	require.Len(t, resp.Definitions, 3)
+	require.Equal(t, []string{"example/document", "example/group", "example/user"}, []string{
+		resp.Definitions[0].Name, resp.Definitions[1].Name, resp.Definitions[2].Name,
+	})
+	require.Empty(t, cmp.Diff(documentDef, resp.Definitions[0], protocmp.Transform()))
+	require.Empty(t, cmp.Diff(userDef, resp.Definitions[2], protocmp.Transform()))
+	require.Empty(t, cmp.Diff([]*experimentalv1.ReflectionCaveat{someCaveat}, resp.Caveats, protocmp.Transform()))
+
+	// Filter by definition name.
+	resp, err = experimentalClient.ReflectSchema(context.Background(), &experimentalv1.ReflectSchemaRequest{
+		OptionalDefinitionNames: []string{"example/user", "someCaveat"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, cmp.Diff([]*experimentalv1.ReflectionDefinition{userDef}, resp.Definitions, protocmp.Transform()))
+	require.Empty(t, cmp.Diff([]*experimentalv1.ReflectionCaveat{someCaveat}, resp.Caveats, protocmp.Transform()))
+
+	// Invalid definition names are rejected.
+	_, err = experimentalClient.ReflectSchema(context.Background(), &experimentalv1.ReflectSchemaRequest{
+		OptionalDefinitionNames: []string{"not a name"},
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestComputablePermissionsAndDependentRelations(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+	experimentalClient := experimentalv1.NewExperimentalSchemaServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `
+		definition user {}
+
+		definition folder {
+			relation viewer: user
+			permission view = viewer
+		}
+
+		definition document {
+			relation parent: folder
+			relation viewer: user
+			permission view = viewer + parent->view
+		}`,
+	})
+	require.NoError(t, err)
+
+	ref := func(definitionName, relationName string, isPermission bool) *experimentalv1.ReflectionRelationOrPermission {
+		return &experimentalv1.ReflectionRelationOrPermission{
+			DefinitionName: definitionName,
+			RelationName:   relationName,
+			IsPermission:   isPermission,
+		}
+	}
+
+	computable, err := experimentalClient.ComputablePermissions(context.Background(), &experimentalv1.ComputablePermissionsRequest{
+		DefinitionName: "folder",
+		RelationName:   "viewer",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, computable.ReadAt)
+	require.Empty(t, cmp.Diff([]*experimentalv1.ReflectionRelationOrPermission{
+		ref("document", "view", true),
+		ref("folder", "view", true),
+	}, computable.Permissions, protocmp.Transform()))
+	require.Empty(t, cmp.Diff([]*experimentalv1.ReflectionDependencyEdge{
+		{
+			Dependent:  ref("document", "view", true),
+			Dependency: ref("folder", "view", true),
+			Kind:       experimentalv1.ReflectionDependencyEdge_TUPLE_TO_USERSET,
+		},
+		{
+			Dependent:  ref("folder", "view", true),
+			Dependency: ref("folder", "viewer", false),
+			Kind:       experimentalv1.ReflectionDependencyEdge_COMPUTED_USERSET,
+		},
+	}, computable.Edges, protocmp.Transform()))
+
+	dependent, err := experimentalClient.DependentRelations(context.Background(), &experimentalv1.DependentRelationsRequest{
+		DefinitionName: "document",
+		PermissionName: "view",
+	})
+	require.NoError(t, err)
+	require.Empty(t, cmp.Diff([]*experimentalv1.ReflectionRelationOrPermission{
+		ref("document", "parent", false),
+		ref("document", "viewer", false),
+		ref("folder", "view", true),
+		ref("folder", "viewer", false),
+	}, dependent.Relations, protocmp.Transform()))
+	require.Len(t, dependent.Edges, 4)
+
+	_, err = experimentalClient.DependentRelations(context.Background(), &experimentalv1.DependentRelationsRequest{
+		DefinitionName: "document",
+		PermissionName: "viewer",
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	_, err = experimentalClient.ComputablePermissions(context.Background(), &experimentalv1.ComputablePermissionsRequest{
+		DefinitionName: "unknown",
+		RelationName:   "viewer",
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+}
+
+func TestExportSchema(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	schemaClient := v1.NewSchemaServiceClient(conn)
+	experimentalClient := experimentalv1.NewExperimentalSchemaServiceClient(conn)
+	fullyConsistent := &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+
+	// An empty schema exports as empty.
+	resp, err := experimentalClient.ExportSchema(context.Background(), &experimentalv1.ExportSchemaRequest{
+		Consistency: fullyConsistent,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.SchemaText)
+
+	_, err = schemaClient.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: "definition user {}",
+	})
+	require.NoError(t, err)
+
+	resp, err = experimentalClient.ExportSchema(context.Background(), &experimentalv1.ExportSchemaRequest{
+		Consistency: fullyConsistent,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "definition user {}", resp.SchemaText)
+	require.NotNil(t, resp.ReadAt)
+
+	_, err = schemaClient.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: "definition user {}\n\ndefinition document {}",
+	})
+	require.NoError(t, err)
+
+	// The schema is exported at the requested revision.
+	atSnapshot, err := experimentalClient.ExportSchema(context.Background(), &experimentalv1.ExportSchemaRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: resp.ReadAt}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "definition user {}", atSnapshot.SchemaText)
+
+	resp, err = experimentalClient.ExportSchema(context.Background(), &experimentalv1.ExportSchemaRequest{
+		Consistency: fullyConsistent,
+	})
+	require.NoError(t, err)
+	require.Contains(t, resp.SchemaText, "definition document {}")
+}
@@ -0,0 +1,244 @@
+package v1
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+type experimentalWatchServer struct {
+	experimentalv1.UnimplementedExperimentalWatchServiceServer
+	shared.WithStreamServiceSpecificInterceptor
+
+	heartbeatDuration time.Duration
+}
+
+// NewExperimentalWatchServer creates an ExperimentalWatchServiceServer instance. As with
+// NewWatchServer, a non-zero heartbeatDuration is the interval at which responses without
+// updates are sent to report progress.
+func NewExperimentalWatchServer(heartbeatDuration time.Duration) experimentalv1.ExperimentalWatchServiceServer {
+	return &experimentalWatchServer{
+		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
+			Stream: grpcvalidate.StreamServerInterceptor(true),
+		},
+		heartbeatDuration: heartbeatDuration,
+	}
+}
+
+func (ws *experimentalWatchServer) Watch(req *experimentalv1.WatchRequest, stream experimentalv1.ExperimentalWatchService_WatchServer) error {
+	ctx := stream.Context()
+	ds := datastoremw.MustFromContext(ctx)
+
+	for _, filter := range req.OptionalRelationshipFilters {
+		if filter.OptionalResourceId != "" && filter.OptionalResourceIdPrefix != "" {
+			return status.Errorf(codes.InvalidArgument, "cannot specify both a resource ID and a resource ID prefix")
+		}
+	}
+
+	var afterRevision datastore.Revision
+	if req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != "" {
+		decodedRevision, err := zedtoken.DecodeRevision(ctx, req.OptionalStartCursor, ds)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to decode start revision: %s", err)
+		}
+
+		afterRevision = decodedRevision
+	} else {
+		var err error
+		afterRevision, err = ds.OptimizedRevision(ctx)
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "failed to start watch: %s", err)
+		}
+	}
+
+	options := datastore.WatchJustRelationships()
+	if req.IncludeSchemaChanges {
+		options.Content |= datastore.WatchSchema
+	}
+	options.CheckpointInterval = ws.heartbeatDuration
+
+	matcher := newWatchUpdateMatcher(req)
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: 1,
+	})
+
+	updates, errchan := ds.Watch(ctx, afterRevision, options)
+	for {
+		select {
+		case update, ok := <-updates:
+			if ok {
+				filtered := matcher.filter(update.Changes)
+
+				var schemaChanges *experimentalv1.WatchSchemaChanges
+				if update.HasSchemaChanges() {
+					var err error
+					schemaChanges, err = toWatchSchemaChanges(update)
+					if err != nil {
+						return status.Errorf(codes.Internal, "watch error: %s", err)
+					}
+				}
+
+				if len(filtered) > 0 || schemaChanges != nil || update.IsCheckpoint {
+					if err := stream.Send(&experimentalv1.WatchResponse{
+						Updates:        filtered,
+						ChangesThrough: zedtoken.MustNewFromRevision(ctx, update.Revision),
+						SchemaChanges:  schemaChanges,
+					}); err != nil {
+						return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+					}
+				}
+			}
+		case err := <-errchan:
+			switch {
+			case errors.As(err, &datastore.ErrWatchCanceled{}):
+				return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+			case errors.As(err, &datastore.ErrWatchDisconnected{}):
+				return status.Errorf(codes.ResourceExhausted, "watch disconnected: %s", err)
+			default:
+				return status.Errorf(codes.Internal, "watch error: %s", err)
+			}
+		}
+	}
+}
+
+// watchUpdateMatcher matches relationship updates against the filters and operations of a
+// watch request.
+type watchUpdateMatcher struct {
+	filters    []*experimentalv1.WatchRelationshipFilter
+	operations map[v1.RelationshipUpdate_Operation]struct{}
+}
+
+func newWatchUpdateMatcher(req *experimentalv1.WatchRequest) watchUpdateMatcher {
+	operations := make(map[v1.RelationshipUpdate_Operation]struct{}, len(req.OptionalUpdateOperations))
+	for _, operation := range req.OptionalUpdateOperations {
+		// Written relationships are reported as touches, whether or not they were created.
+		if operation == v1.RelationshipUpdate_OPERATION_CREATE {
+			operation = v1.RelationshipUpdate_OPERATION_TOUCH
+		}
+		operations[operation] = struct{}{}
+	}
+
+	return watchUpdateMatcher{
+		filters:    req.OptionalRelationshipFilters,
+		operations: operations,
+	}
+}
+
+func (m watchUpdateMatcher) filter(candidates []*core.RelationTupleUpdate) []*v1.RelationshipUpdate {
+	updates := tuple.UpdatesToRelationshipUpdates(candidates)
+
+	filtered := make([]*v1.RelationshipUpdate, 0, len(updates))
+	for _, update := range updates {
+		if m.matches(update) {
+			filtered = append(filtered, update)
+		}
+	}
+	return filtered
+}
+
+func (m watchUpdateMatcher) matches(update *v1.RelationshipUpdate) bool {
+	if len(m.operations) > 0 {
+		if _, ok := m.operations[update.Operation]; !ok {
+			return false
+		}
+	}
+
+	if len(m.filters) == 0 {
+		return true
+	}
+
+	for _, filter := range m.filters {
+		if relationshipMatchesWatchFilter(update.Relationship, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func relationshipMatchesWatchFilter(rel *v1.Relationship, filter *experimentalv1.WatchRelationshipFilter) bool {
+	if rel.Resource.ObjectType != filter.ResourceType {
+		return false
+	}
+
+	if filter.OptionalResourceId != "" && rel.Resource.ObjectId != filter.OptionalResourceId {
+		return false
+	}
+
+	if filter.OptionalResourceIdPrefix != "" && !strings.HasPrefix(rel.Resource.ObjectId, filter.OptionalResourceIdPrefix) {
+		return false
+	}
+
+	if filter.OptionalRelation != "" && rel.Relation != filter.OptionalRelation {
+		return false
+	}
+
+	if filter.OptionalCaveatName != "" && rel.OptionalCaveat.GetCaveatName() != filter.OptionalCaveatName {
+		return false
+	}
+
+	if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
+		if rel.Subject.Object.ObjectType != subjectFilter.SubjectType {
+			return false
+		}
+
+		if subjectFilter.OptionalSubjectId != "" && rel.Subject.Object.ObjectId != subjectFilter.OptionalSubjectId {
+			return false
+		}
+
+		// An empty relation in the relation filter matches only subjects without a relation.
+		if subjectFilter.OptionalRelation != nil && rel.Subject.OptionalRelation != subjectFilter.OptionalRelation.Relation {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toWatchSchemaChanges converts the schema changes at a revision into their API representation.
+func toWatchSchemaChanges(update *datastore.RevisionChanges) (*experimentalv1.WatchSchemaChanges, error) {
+	changes := &experimentalv1.WatchSchemaChanges{
+		DeletedDefinitionNames: update.DeletedNamespaces,
+		DeletedCaveatNames:     update.DeletedCaveats,
+	}
+
+	definitions := make([]compiler.SchemaDefinition, 0, len(update.ChangedDefinitions))
+	for _, def := range update.ChangedDefinitions {
+		switch def := def.(type) {
+		case *core.NamespaceDefinition:
+			changes.ChangedDefinitionNames = append(changes.ChangedDefinitionNames, def.Name)
+			definitions = append(definitions, def)
+
+		case *core.CaveatDefinition:
+			changes.ChangedCaveatNames = append(changes.ChangedCaveatNames, def.Name)
+			definitions = append(definitions, def)
+		}
+	}
+
+	if len(definitions) > 0 {
+		schema, _, err := generator.GenerateSchema(definitions)
+		if err != nil {
+			return nil, err
+		}
+		changes.ChangedSchema = schema
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,281 @@
+package v1_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+func TestExperimentalWatch(t *testing.T) {
+	mutations := []*v1.RelationshipUpdate{
+		{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: tuple.ParseRel("document:newplan#owner@user:tom")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: tuple.ParseRel("document:newplan#viewer@user:tom")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: tuple.ParseRel("document:masterplan#viewer@user:sarah")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: tuple.ParseRel("document:newplan#caveated_viewer@user:fred[test]")},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: tuple.ParseRel("folder:newfolder#owner@user:tom")},
+		{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: tuple.ParseRel("document:masterplan#owner@user:product_manager")},
+		{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: tuple.ParseRel("folder:company#viewer@folder:auditors#viewer")},
+	}
+
+	testCases := []struct {
+		name            string
+		filters         []*experimentalv1.WatchRelationshipFilter
+		operations      []v1.RelationshipUpdate_Operation
+		expectedCode    codes.Code
+		expectedUpdates []string
+	}{
+		{
+			name:         "unfiltered",
+			expectedCode: codes.OK,
+			expectedUpdates: []string{
+				"OPERATION_DELETE(document:masterplan#owner@user:product_manager)",
+				"OPERATION_DELETE(folder:company#viewer@folder:auditors#viewer)",
+				"OPERATION_TOUCH(document:masterplan#viewer@user:sarah)",
+				"OPERATION_TOUCH(document:newplan#caveated_viewer@user:fred)",
+				"OPERATION_TOUCH(document:newplan#owner@user:tom)",
+				"OPERATION_TOUCH(document:newplan#viewer@user:tom)",
+				"OPERATION_TOUCH(folder:newfolder#owner@user:tom)",
+			},
+		},
+		{
+			name:         "resource type and relation",
+			expectedCode: codes.OK,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "document", OptionalRelation: "owner"},
+			},
+			expectedUpdates: []string{
+				"OPERATION_DELETE(document:masterplan#owner@user:product_manager)",
+				"OPERATION_TOUCH(document:newplan#owner@user:tom)",
+			},
+		},
+		{
+			name:         "resource id prefix",
+			expectedCode: codes.OK,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "document", OptionalResourceIdPrefix: "master"},
+			},
+			expectedUpdates: []string{
+				"OPERATION_DELETE(document:masterplan#owner@user:product_manager)",
+				"OPERATION_TOUCH(document:masterplan#viewer@user:sarah)",
+			},
+		},
+		{
+			name:         "resource id",
+			expectedCode: codes.OK,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "folder", OptionalResourceId: "newfolder"},
+			},
+			expectedUpdates: []string{
+				"OPERATION_TOUCH(folder:newfolder#owner@user:tom)",
+			},
+		},
+		{
+			name:         "subject filter",
+			expectedCode: codes.OK,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "document", OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: "user", OptionalSubjectId: "tom"}},
+				{ResourceType: "folder", OptionalSubjectFilter: &v1.SubjectFilter{
+					SubjectType:      "folder",
+					OptionalRelation: &v1.SubjectFilter_RelationFilter{Relation: "viewer"},
+				}},
+			},
+			expectedUpdates: []string{
+				"OPERATION_DELETE(folder:company#viewer@folder:auditors#viewer)",
+				"OPERATION_TOUCH(document:newplan#owner@user:tom)",
+				"OPERATION_TOUCH(document:newplan#viewer@user:tom)",
+			},
+		},
+		{
+			name:         "caveat name",
+			expectedCode: codes.OK,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "document", OptionalCaveatName: "test"},
+			},
+			expectedUpdates: []string{
+				"OPERATION_TOUCH(document:newplan#caveated_viewer@user:fred)",
+			},
+		},
+		{
+			name:         "delete operations",
+			expectedCode: codes.OK,
+			operations:   []v1.RelationshipUpdate_Operation{v1.RelationshipUpdate_OPERATION_DELETE},
+			expectedUpdates: []string{
+				"OPERATION_DELETE(document:masterplan#owner@user:product_manager)",
+				"OPERATION_DELETE(folder:company#viewer@folder:auditors#viewer)",
+			},
+		},
+		{
+			name:         "create operations match touches",
+			expectedCode: codes.OK,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "document", OptionalRelation: "owner"},
+			},
+			operations: []v1.RelationshipUpdate_Operation{v1.RelationshipUpdate_OPERATION_CREATE},
+			expectedUpdates: []string{
+				"OPERATION_TOUCH(document:newplan#owner@user:tom)",
+			},
+		},
+		{
+			name:         "resource id and prefix",
+			expectedCode: codes.InvalidArgument,
+			filters: []*experimentalv1.WatchRelationshipFilter{
+				{ResourceType: "document", OptionalResourceId: "masterplan", OptionalResourceIdPrefix: "master"},
+			},
+		},
+		{
+			name:         "unspecified operation",
+			expectedCode: codes.InvalidArgument,
+			operations:   []v1.RelationshipUpdate_Operation{v1.RelationshipUpdate_OPERATION_UNSPECIFIED},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+			t.Cleanup(cleanup)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stream, err := experimentalv1.NewExperimentalWatchServiceClient(conn).Watch(ctx, &experimentalv1.WatchRequest{
+				OptionalRelationshipFilters: tc.filters,
+				OptionalUpdateOperations:    tc.operations,
+				OptionalStartCursor:         zedtoken.MustNewFromRevision(ctx, revision),
+			})
+			require.NoError(err)
+
+			if tc.expectedCode != codes.OK {
+				_, err := stream.Recv()
+				grpcutil.RequireStatus(t, tc.expectedCode, err)
+				return
+			}
+
+			_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+				Updates: mutations,
+			})
+			require.NoError(err)
+
+			// All mutations are written at a single revision, so are returned in a single response.
+			resp := recvWithTimeout(t, stream)
+			require.Equal(tc.expectedUpdates, updateStrings(resp.Updates))
+			require.Nil(resp.SchemaChanges)
+		})
+	}
+}
+
+func TestExperimentalWatchSchemaChanges(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := experimentalv1.NewExperimentalWatchServiceClient(conn).Watch(ctx, &experimentalv1.WatchRequest{
+		OptionalRelationshipFilters: []*experimentalv1.WatchRelationshipFilter{
+			{ResourceType: "document"},
+		},
+		IncludeSchemaChanges: true,
+		OptionalStartCursor:  zedtoken.MustNewFromRevision(ctx, revision),
+	})
+	require.NoError(err)
+
+	// Removing the `folder` definition requires removing all relationships referencing it first.
+	_, err = v1.NewPermissionsServiceClient(conn).DeleteRelationships(context.Background(), &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "folder"},
+	})
+	require.NoError(err)
+
+	_, err = v1.NewPermissionsServiceClient(conn).DeleteRelationships(context.Background(), &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalRelation: "parent"},
+	})
+	require.NoError(err)
+
+	_, err = v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `caveat test(secret string, expectedSecret string) {
+			secret == expectedSecret
+		}
+
+		definition user {}
+
+		definition document {
+			relation owner: user
+			relation editor: user
+			relation viewer: user
+			relation viewer_and_editor: user
+			relation caveated_viewer: user with test
+			relation auditor: user
+		}`,
+	})
+	require.NoError(err)
+
+	// The deletion of the folder relationships is filtered out, so the first response is the
+	// deletion of the document parent relationships.
+	resp := recvWithTimeout(t, stream)
+	require.Equal([]string{
+		"OPERATION_DELETE(document:companyplan#parent@folder:company)",
+		"OPERATION_DELETE(document:healthplan#parent@folder:plans)",
+		"OPERATION_DELETE(document:masterplan#parent@folder:plans)",
+		"OPERATION_DELETE(document:masterplan#parent@folder:strategy)",
+	}, updateStrings(resp.Updates))
+	require.Nil(resp.SchemaChanges)
+
+	resp = recvWithTimeout(t, stream)
+	require.Empty(resp.Updates)
+	require.NotNil(resp.SchemaChanges)
+	require.Equal([]string{"document"}, resp.SchemaChanges.ChangedDefinitionNames)
+	require.Equal([]string{"test"}, resp.SchemaChanges.ChangedCaveatNames)
+	require.Equal([]string{"folder"}, resp.SchemaChanges.DeletedDefinitionNames)
+	require.Empty(resp.SchemaChanges.DeletedCaveatNames)
+	require.Contains(resp.SchemaChanges.ChangedSchema, "relation auditor: user")
+}
+
+func recvWithTimeout(t *testing.T, stream experimentalv1.ExperimentalWatchService_WatchClient) *experimentalv1.WatchResponse {
+	t.Helper()
+
+	type recvResult struct {
+		resp *experimentalv1.WatchResponse
+		err  error
+	}
+
+	received := make(chan recvResult, 1)
+	go func() {
+		resp, err := stream.Recv()
+		received <- recvResult{resp, err}
+	}()
+
+	select {
+	case result := <-received:
+		require.NoError(t, result.err)
+		return result.resp
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "timed out waiting for watch response")
+		return nil
+	}
+}
+
+func updateStrings(updates []*v1.RelationshipUpdate) []string {
+	strs := make([]string, 0, len(updates))
+	for _, update := range updates {
+		strs = append(strs, update.Operation.String()+"("+tuple.StringRelationshipWithoutCaveat(update.Relationship)+")")
+	}
+	sort.Strings(strs)
+	return strs
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// computeLRRequestHash computes a stable hash of the parameters of a LookupResources request,
+// ignoring the cursor and limit, which are allowed to differ between calls that continue from
+// one another.
+func computeLRRequestHash(req *v1.LookupResourcesRequest) (string, error) {
+	cloned := proto.Clone(req).(*v1.LookupResourcesRequest)
+	cloned.OptionalCursor = nil
+	cloned.OptionalLimit = 0
+	return computeCallHash("LookupResources", cloned)
+}
+
+// computeReadRelationshipsRequestHash computes a stable hash of the parameters of a
+// ReadRelationships request, ignoring the cursor and limit.
+func computeReadRelationshipsRequestHash(req *v1.ReadRelationshipsRequest) (string, error) {
+	cloned := proto.Clone(req).(*v1.ReadRelationshipsRequest)
+	cloned.OptionalCursor = nil
+	cloned.OptionalLimit = 0
+	return computeCallHash("ReadRelationships", cloned)
+}
+
+// computeCallHash computes a stable hash of the name of a call and its request.
+func computeCallHash(callName string, req proto.Message) (string, error) {
+	marshalled, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("could not compute hash for request: %w", err)
+	}
+
+	hasher := sha256.New()
+	hasher.Write([]byte(callName + ":"))
+	hasher.Write(marshalled)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
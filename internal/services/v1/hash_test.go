@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestComputeLRRequestHash(t *testing.T) {
+	baseRequest := func() *v1.LookupResourcesRequest {
+		caveatContext, err := structpb.NewStruct(map[string]any{"a": 1, "b": "two", "c": true})
+		require.NoError(t, err)
+
+		return &v1.LookupResourcesRequest{
+			ResourceObjectType: "document",
+			Permission:         "view",
+			Subject: &v1.SubjectReference{
+				Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"},
+			},
+			Context: caveatContext,
+		}
+	}
+
+	baseHash, err := computeLRRequestHash(baseRequest())
+	require.NoError(t, err)
+
+	// The hash must be stable.
+	for i := 0; i < 10; i++ {
+		hash, err := computeLRRequestHash(baseRequest())
+		require.NoError(t, err)
+		require.Equal(t, baseHash, hash)
+	}
+
+	// The cursor and the limit must not affect the hash.
+	withCursorAndLimit := baseRequest()
+	withCursorAndLimit.OptionalCursor = &v1.Cursor{Token: "sometoken"}
+	withCursorAndLimit.OptionalLimit = 42
+
+	hash, err := computeLRRequestHash(withCursorAndLimit)
+	require.NoError(t, err)
+	require.Equal(t, baseHash, hash)
+
+	// Any other parameter must.
+	withDifferentPermission := baseRequest()
+	withDifferentPermission.Permission = "edit"
+
+	hash, err = computeLRRequestHash(withDifferentPermission)
+	require.NoError(t, err)
+	require.NotEqual(t, baseHash, hash)
+}
@@ -38,7 +38,7 @@ func TestAllMethodsReturnMetadata(t *testing.T) {
 				_, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
 					Consistency: &v1.Consistency{
 						Requirement: &v1.Consistency_AtLeastAsFresh{
-							AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+							AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 						},
 					},
 					Resource:   obj("document", "masterplan"),
@@ -77,7 +77,7 @@ func TestAllMethodsReturnMetadata(t *testing.T) {
 				_, err := client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
 					Consistency: &v1.Consistency{
 						Requirement: &v1.Consistency_AtLeastAsFresh{
-							AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+							AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 						},
 					},
 					Resource:   obj("document", "masterplan"),
@@ -111,7 +111,7 @@ func TestAllMethodsReturnMetadata(t *testing.T) {
 				stream, err := client.LookupResources(ctx, &v1.LookupResourcesRequest{
 					Consistency: &v1.Consistency{
 						Requirement: &v1.Consistency_AtLeastAsFresh{
-							AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+							AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 						},
 					},
 					ResourceObjectType: "document",
@@ -136,7 +136,7 @@ func TestAllMethodsReturnMetadata(t *testing.T) {
 				stream, err := client.LookupSubjects(ctx, &v1.LookupSubjectsRequest{
 					Consistency: &v1.Consistency{
 						Requirement: &v1.Consistency_AtLeastAsFresh{
-							AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+							AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 						},
 					},
 					Resource:          obj("document", "masterplan"),
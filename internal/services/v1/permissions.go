@@ -25,6 +25,7 @@ import (
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/middleware/consistency"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
@@ -121,22 +122,28 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 		return nil, rewriteError(ctx, err)
 	}
 
+	permissionship, partialCaveat := checkResultToAPITypes(cr)
+	return &v1.CheckPermissionResponse{
+		CheckedAt:         checkedAt,
+		Permissionship:    permissionship,
+		PartialCaveatInfo: partialCaveat,
+	}, nil
+}
+
+// checkResultToAPITypes converts a computed check result into the permissionship and partial
+// caveat information returned by the API.
+func checkResultToAPITypes(cr *dispatch.ResourceCheckResult) (v1.CheckPermissionResponse_Permissionship, *v1.PartialCaveatInfo) {
 	var partialCaveat *v1.PartialCaveatInfo
 	permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
-	if cr.Membership == dispatch.ResourceCheckResult_MEMBER {
+	if cr.GetMembership() == dispatch.ResourceCheckResult_MEMBER {
 		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
-	} else if cr.Membership == dispatch.ResourceCheckResult_CAVEATED_MEMBER {
+	} else if cr.GetMembership() == dispatch.ResourceCheckResult_CAVEATED_MEMBER {
 		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION
 		partialCaveat = &v1.PartialCaveatInfo{
 			MissingRequiredContext: cr.MissingExprFields,
 		}
 	}
-
-	return &v1.CheckPermissionResponse{
-		CheckedAt:         checkedAt,
-		Permissionship:    permissionship,
-		PartialCaveatInfo: partialCaveat,
-	}, nil
+	return permissionship, partialCaveat
 }
 
 func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
@@ -355,30 +362,37 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		return rewriteError(ctx, err)
 	}
 
-	// TODO(jschorr): Change the internal dispatched lookup to also be streamed.
-	lookupResp, err := ps.dispatch.DispatchLookup(ctx, &dispatch.DispatchLookupRequest{
-		Metadata: &dispatch.ResolverMeta{
-			AtRevision:     atRevision.String(),
-			DepthRemaining: ps.config.MaximumAPIDepth,
-		},
-		ObjectRelation: &core.RelationReference{
-			Namespace: req.ResourceObjectType,
-			Relation:  req.Permission,
-		},
-		Subject: &core.ObjectAndRelation{
-			Namespace: req.Subject.Object.ObjectType,
-			ObjectId:  req.Subject.Object.ObjectId,
-			Relation:  normalizeSubjectRelation(req.Subject),
-		},
-		Context: req.Context,
-		Limit:   ^uint32(0), // Set no limit for now
-	})
-	usagemetrics.SetInContext(ctx, lookupResp.Metadata)
+	respMetadata := &dispatch.ResponseMeta{
+		DispatchCount:       0,
+		CachedDispatchCount: 0,
+		DepthRequired:       0,
+		DebugInfo:           nil,
+	}
+	usagemetrics.SetInContext(ctx, respMetadata)
+
+	lrRequestHash, err := computeLRRequestHash(req)
 	if err != nil {
 		return rewriteError(ctx, err)
 	}
 
-	for _, found := range lookupResp.ResolvedResources {
+	var currentCursor *dispatch.Cursor
+	if req.OptionalCursor != nil {
+		decodedCursor, err := cursor.DecodeToDispatchCursor(req.OptionalCursor, lrRequestHash)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+		currentCursor = decodedCursor
+	}
+
+	stream := dispatchpkg.NewHandlingDispatchStream(ctx, func(result *dispatch.DispatchLookupResourcesResponse) error {
+		dispatchpkg.AddResponseMetadata(respMetadata, result.Metadata)
+
+		// The final response may only carry metadata.
+		found := result.ResolvedResource
+		if found == nil {
+			return nil
+		}
+
 		var partial *v1.PartialCaveatInfo
 		permissionship := v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION
 		if found.Permissionship == dispatch.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
@@ -388,21 +402,60 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 			}
 		}
 
-		err := resp.Send(&v1.LookupResourcesResponse{
+		encodedCursor, err := cursor.EncodeFromDispatchCursor(ctx, result.AfterResponseCursor, lrRequestHash, atRevision)
+		if err != nil {
+			return err
+		}
+
+		err = resp.Send(&v1.LookupResourcesResponse{
 			LookedUpAt:        revisionReadAt,
 			ResourceObjectId:  found.ResourceId,
 			Permissionship:    permissionship,
 			PartialCaveatInfo: partial,
+			AfterResultCursor: encodedCursor,
 		})
-		if err != nil {
-			return err
-		}
+		return err
+	})
+
+	err = ps.dispatch.DispatchLookupResources(
+		&dispatch.DispatchLookupResourcesRequest{
+			Metadata: &dispatch.ResolverMeta{
+				AtRevision:     atRevision.String(),
+				DepthRemaining: ps.config.MaximumAPIDepth,
+			},
+			ObjectRelation: &core.RelationReference{
+				Namespace: req.ResourceObjectType,
+				Relation:  req.Permission,
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: req.Subject.Object.ObjectType,
+				ObjectId:  req.Subject.Object.ObjectId,
+				Relation:  normalizeSubjectRelation(req.Subject),
+			},
+			Context:        req.Context,
+			OptionalCursor: currentCursor,
+			OptionalLimit:  req.OptionalLimit,
+		},
+		stream)
+	if err != nil {
+		return rewriteError(ctx, err)
 	}
+
 	return nil
 }
 
 func (ps *permissionServer) LookupSubjects(req *v1.LookupSubjectsRequest, resp v1.PermissionsService_LookupSubjectsServer) error {
 	ctx := resp.Context()
+
+	// Paginated lookup of subjects is not yet supported; reject rather than silently returning
+	// the full result set.
+	if req.OptionalConcreteLimit != 0 {
+		return status.Errorf(codes.Unimplemented, "concrete limit is not yet supported")
+	}
+	if req.OptionalCursor != nil {
+		return status.Errorf(codes.Unimplemented, "cursor is not yet supported")
+	}
+
 	atRevision, revisionReadAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
 		return rewriteError(ctx, err)
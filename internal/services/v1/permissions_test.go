@@ -262,7 +262,7 @@ func TestCheckPermissions(t *testing.T) {
 							checkResp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
 								Consistency: &v1.Consistency{
 									Requirement: &v1.Consistency_AtLeastAsFresh{
-										AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+										AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 									},
 								},
 								Resource:   tc.resource,
@@ -318,7 +318,7 @@ func TestCheckPermissionWithDebugInfo(t *testing.T) {
 	checkResp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:   obj("document", "masterplan"),
@@ -485,7 +485,7 @@ func TestLookupResources(t *testing.T) {
 						Subject:            tc.subject,
 						Consistency: &v1.Consistency{
 							Requirement: &v1.Consistency_AtLeastAsFresh{
-								AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+								AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
 							},
 						},
 					}, grpc.Trailer(&trailer))
@@ -522,6 +522,133 @@ func TestLookupResources(t *testing.T) {
 	}
 }
 
+func TestLookupResourcesWithCursors(t *testing.T) {
+	for _, subject := range []*v1.SubjectReference{
+		sub("user", "chief_financial_officer", ""),
+		sub("user", "auditor", ""),
+		sub("user", "legal", ""),
+		sub("user", "owner", ""),
+		sub("user", "villain", ""),
+	} {
+		subject := subject
+		t.Run(subject.Object.ObjectId, func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+			client := v1.NewPermissionsServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			lookupAll := func(limit uint32, cursor *v1.Cursor) ([]*v1.LookupResourcesResponse, error) {
+				lookupClient, err := client.LookupResources(context.Background(), &v1.LookupResourcesRequest{
+					ResourceObjectType: "document",
+					Permission:         "view",
+					Subject:            subject,
+					Consistency: &v1.Consistency{
+						Requirement: &v1.Consistency_AtLeastAsFresh{
+							AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
+						},
+					},
+					OptionalLimit:  limit,
+					OptionalCursor: cursor,
+				})
+				require.NoError(err)
+
+				var responses []*v1.LookupResourcesResponse
+				for {
+					resp, err := lookupClient.Recv()
+					if errors.Is(err, io.EOF) {
+						return responses, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+
+					responses = append(responses, resp)
+				}
+			}
+
+			allResponses, err := lookupAll(0, nil)
+			require.NoError(err)
+
+			expectedObjectIds := make([]string, 0, len(allResponses))
+			for _, resp := range allResponses {
+				expectedObjectIds = append(expectedObjectIds, resp.ResourceObjectId)
+				require.NotNil(resp.AfterResultCursor)
+			}
+
+			// Page through the results one at a time.
+			var cursor *v1.Cursor
+			foundObjectIds := []string{}
+			for {
+				responses, err := lookupAll(1, cursor)
+				require.NoError(err)
+				require.LessOrEqual(len(responses), 1)
+				if len(responses) == 0 {
+					break
+				}
+
+				foundObjectIds = append(foundObjectIds, responses[0].ResourceObjectId)
+				cursor = responses[0].AfterResultCursor
+			}
+
+			sort.Strings(expectedObjectIds)
+			sort.Strings(foundObjectIds)
+			require.Equal(expectedObjectIds, foundObjectIds)
+		})
+	}
+}
+
+func TestLookupResourcesWithMismatchedCursor(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	consistency := &v1.Consistency{
+		Requirement: &v1.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
+		},
+	}
+
+	lookupClient, err := client.LookupResources(context.Background(), &v1.LookupResourcesRequest{
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            sub("user", "chief_financial_officer", ""),
+		Consistency:        consistency,
+		OptionalLimit:      1,
+	})
+	require.NoError(err)
+
+	resp, err := lookupClient.Recv()
+	require.NoError(err)
+	require.NotNil(resp.AfterResultCursor)
+
+	// Using the cursor for a different request must fail.
+	lookupClient, err = client.LookupResources(context.Background(), &v1.LookupResourcesRequest{
+		ResourceObjectType: "document",
+		Permission:         "edit",
+		Subject:            sub("user", "chief_financial_officer", ""),
+		Consistency:        consistency,
+		OptionalCursor:     resp.AfterResultCursor,
+	})
+	require.NoError(err)
+
+	_, err = lookupClient.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	// As must using an invalid cursor.
+	lookupClient, err = client.LookupResources(context.Background(), &v1.LookupResourcesRequest{
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            sub("user", "chief_financial_officer", ""),
+		Consistency:        consistency,
+		OptionalCursor:     &v1.Cursor{Token: "invalid"},
+	})
+	require.NoError(err)
+
+	_, err = lookupClient.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func TestExpand(t *testing.T) {
 	testCases := []struct {
 		startObjectType    string
@@ -557,7 +684,7 @@ func TestExpand(t *testing.T) {
 						Permission: tc.startPermission,
 						Consistency: &v1.Consistency{
 							Requirement: &v1.Consistency_AtLeastAsFresh{
-								AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+								AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
 							},
 						},
 					}, grpc.Trailer(&trailer))
@@ -793,7 +920,7 @@ func TestLookupSubjects(t *testing.T) {
 						OptionalSubjectRelation: tc.subjectRelation,
 						Consistency: &v1.Consistency{
 							Requirement: &v1.Consistency_AtLeastAsFresh{
-								AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+								AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
 							},
 						},
 					}, grpc.Trailer(&trailer))
@@ -830,6 +957,29 @@ func TestLookupSubjects(t *testing.T) {
 	}
 }
 
+func TestLookupSubjectsWithConcreteLimit(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	lookupClient, err := client.LookupSubjects(context.Background(), &v1.LookupSubjectsRequest{
+		Resource:          obj("document", "masterplan"),
+		Permission:        "view",
+		SubjectObjectType: "user",
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
+			},
+		},
+		OptionalConcreteLimit: 1,
+	})
+	require.NoError(err)
+
+	_, err = lookupClient.Recv()
+	grpcutil.RequireStatus(t, codes.Unimplemented, err)
+}
+
 func TestCheckWithCaveats(t *testing.T) {
 	req := require.New(t)
 	conn, cleanup, _, revision := testserver.NewTestServer(req, testTimedeltas[0], memdb.DisableGC, true, tf.StandardDatastoreWithCaveatedData)
@@ -841,7 +991,7 @@ func TestCheckWithCaveats(t *testing.T) {
 	request := &v1.CheckPermissionRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:   obj("document", "companyplan"),
@@ -953,7 +1103,7 @@ func TestCheckWithCaveatErrors(t *testing.T) {
 			request := &v1.CheckPermissionRequest{
 				Consistency: &v1.Consistency{
 					Requirement: &v1.Consistency_AtLeastAsFresh{
-						AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+						AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 					},
 				},
 				Resource:   obj("document", "firstdoc"),
@@ -1006,7 +1156,7 @@ func TestLookupResourcesWithCaveats(t *testing.T) {
 	request := &v1.LookupResourcesRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		ResourceObjectType: "document",
@@ -1049,7 +1199,7 @@ func TestLookupResourcesWithCaveats(t *testing.T) {
 	request = &v1.LookupResourcesRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		ResourceObjectType: "document",
@@ -1127,7 +1277,7 @@ func TestLookupSubjectsWithCaveats(t *testing.T) {
 	request := &v1.LookupSubjectsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:          obj("document", "first"),
@@ -1172,7 +1322,7 @@ func TestLookupSubjectsWithCaveats(t *testing.T) {
 	request = &v1.LookupSubjectsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:          obj("document", "first"),
@@ -1217,7 +1367,7 @@ func TestLookupSubjectsWithCaveats(t *testing.T) {
 	request = &v1.LookupSubjectsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:          obj("document", "first"),
@@ -1291,7 +1441,7 @@ func TestLookupSubjectsWithCaveatedWildcards(t *testing.T) {
 	request := &v1.LookupSubjectsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:          obj("document", "first"),
@@ -1330,7 +1480,7 @@ func TestLookupSubjectsWithCaveatedWildcards(t *testing.T) {
 	request = &v1.LookupSubjectsRequest{
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(ctx, revision),
 			},
 		},
 		Resource:          obj("document", "first"),
@@ -0,0 +1,292 @@
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/caveats"
+	caveattypes "github.com/authzed/spicedb/pkg/caveats/types"
+	nspkg "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/util"
+)
+
+// reflectDefinition converts an object definition, via its type system, into its reflection
+// API representation.
+func reflectDefinition(ts *namespace.TypeSystem) (*experimentalv1.ReflectionDefinition, error) {
+	nsDef := ts.Namespace()
+	def := &experimentalv1.ReflectionDefinition{
+		Name:        nsDef.Name,
+		Comment:     reflectComment(nsDef.Metadata),
+		Relations:   []*experimentalv1.ReflectionRelation{},
+		Permissions: []*experimentalv1.ReflectionPermission{},
+	}
+
+	for _, relation := range nsDef.Relation {
+		if ts.IsPermission(relation.Name) {
+			expression, err := reflectRewrite(relation.UsersetRewrite)
+			if err != nil {
+				return nil, err
+			}
+
+			def.Permissions = append(def.Permissions, &experimentalv1.ReflectionPermission{
+				Name:                 relation.Name,
+				Comment:              reflectComment(relation.Metadata),
+				ParentDefinitionName: nsDef.Name,
+				Expression:           expression,
+			})
+			continue
+		}
+
+		allowedTypes, err := ts.AllowedDirectRelationsAndWildcards(relation.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		subjectTypes := make([]*experimentalv1.ReflectionTypeReference, 0, len(allowedTypes))
+		for _, allowedType := range allowedTypes {
+			subjectTypes = append(subjectTypes, reflectAllowedType(allowedType))
+		}
+
+		def.Relations = append(def.Relations, &experimentalv1.ReflectionRelation{
+			Name:                 relation.Name,
+			Comment:              reflectComment(relation.Metadata),
+			ParentDefinitionName: nsDef.Name,
+			SubjectTypes:         subjectTypes,
+		})
+	}
+
+	return def, nil
+}
+
+func reflectAllowedType(allowedType *core.AllowedRelation) *experimentalv1.ReflectionTypeReference {
+	typeRef := &experimentalv1.ReflectionTypeReference{
+		SubjectDefinitionName: allowedType.Namespace,
+	}
+
+	if allowedType.RequiredCaveat != nil {
+		typeRef.OptionalCaveatName = allowedType.RequiredCaveat.CaveatName
+	}
+
+	switch {
+	case allowedType.GetPublicWildcard() != nil:
+		typeRef.Typeref = &experimentalv1.ReflectionTypeReference_IsPublicWildcard{IsPublicWildcard: true}
+
+	case allowedType.GetRelation() == tuple.Ellipsis:
+		typeRef.Typeref = &experimentalv1.ReflectionTypeReference_IsTerminalSubject{IsTerminalSubject: true}
+
+	default:
+		typeRef.Typeref = &experimentalv1.ReflectionTypeReference_OptionalRelationName{OptionalRelationName: allowedType.GetRelation()}
+	}
+
+	return typeRef
+}
+
+func reflectRewrite(rewrite *core.UsersetRewrite) (*experimentalv1.ReflectionPermissionExpression, error) {
+	var kind experimentalv1.ReflectionOperation_OperationKind
+	var setOp *core.SetOperation
+
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		kind = experimentalv1.ReflectionOperation_UNION
+		setOp = rw.Union
+
+	case *core.UsersetRewrite_Intersection:
+		kind = experimentalv1.ReflectionOperation_INTERSECTION
+		setOp = rw.Intersection
+
+	case *core.UsersetRewrite_Exclusion:
+		kind = experimentalv1.ReflectionOperation_EXCLUSION
+		setOp = rw.Exclusion
+
+	default:
+		return nil, fmt.Errorf("unknown rewrite operation %T", rw)
+	}
+
+	children := make([]*experimentalv1.ReflectionPermissionExpression, 0, len(setOp.Child))
+	for _, child := range setOp.Child {
+		reflected, err := reflectSetOpChild(child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, reflected)
+	}
+
+	return &experimentalv1.ReflectionPermissionExpression{
+		Expression: &experimentalv1.ReflectionPermissionExpression_Operation{
+			Operation: &experimentalv1.ReflectionOperation{
+				Kind:     kind,
+				Children: children,
+			},
+		},
+	}, nil
+}
+
+func reflectSetOpChild(child *core.SetOperation_Child) (*experimentalv1.ReflectionPermissionExpression, error) {
+	switch child := child.ChildType.(type) {
+	case *core.SetOperation_Child_UsersetRewrite:
+		return reflectRewrite(child.UsersetRewrite)
+
+	case *core.SetOperation_Child_ComputedUserset:
+		return &experimentalv1.ReflectionPermissionExpression{
+			Expression: &experimentalv1.ReflectionPermissionExpression_RelationReference{
+				RelationReference: &experimentalv1.ReflectionRelationReference{
+					RelationName: child.ComputedUserset.Relation,
+				},
+			},
+		}, nil
+
+	case *core.SetOperation_Child_TupleToUserset:
+		return &experimentalv1.ReflectionPermissionExpression{
+			Expression: &experimentalv1.ReflectionPermissionExpression_Arrow{
+				Arrow: &experimentalv1.ReflectionArrow{
+					TuplesetRelationName: child.TupleToUserset.Tupleset.Relation,
+					ComputedRelationName: child.TupleToUserset.ComputedUserset.Relation,
+				},
+			},
+		}, nil
+
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		functionName, err := reflectArrowFunction(child.FunctionedTupleToUserset.Function)
+		if err != nil {
+			return nil, err
+		}
+
+		return &experimentalv1.ReflectionPermissionExpression{
+			Expression: &experimentalv1.ReflectionPermissionExpression_Arrow{
+				Arrow: &experimentalv1.ReflectionArrow{
+					TuplesetRelationName: child.FunctionedTupleToUserset.Tupleset.Relation,
+					ComputedRelationName: child.FunctionedTupleToUserset.ComputedUserset.Relation,
+					FunctionName:         functionName,
+				},
+			},
+		}, nil
+
+	case *core.SetOperation_Child_XNil:
+		return &experimentalv1.ReflectionPermissionExpression{
+			Expression: &experimentalv1.ReflectionPermissionExpression_Nil{
+				Nil: &experimentalv1.ReflectionNil{},
+			},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported set operation child %T", child)
+	}
+}
+
+// reflectCaveat converts a caveat definition into its reflection API representation.
+func reflectCaveat(caveatDef *core.CaveatDefinition) (*experimentalv1.ReflectionCaveat, error) {
+	parameterNames := maps.Keys(caveatDef.ParameterTypes)
+	sort.Strings(parameterNames)
+
+	parameters := make([]*experimentalv1.ReflectionCaveatParameter, 0, len(parameterNames))
+	for _, paramName := range parameterNames {
+		decoded, err := caveattypes.DecodeParameterType(caveatDef.ParameterTypes[paramName])
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter type on caveat: %w", err)
+		}
+
+		parameters = append(parameters, &experimentalv1.ReflectionCaveatParameter{
+			Name:             paramName,
+			Type:             decoded.String(),
+			ParentCaveatName: caveatDef.Name,
+		})
+	}
+
+	deserialized, err := caveats.DeserializeCaveat(caveatDef.SerializedExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid caveat expression bytes: %w", err)
+	}
+
+	exprString, err := deserialized.ExprString()
+	if err != nil {
+		return nil, fmt.Errorf("invalid caveat expression: %w", err)
+	}
+
+	return &experimentalv1.ReflectionCaveat{
+		Name:       caveatDef.Name,
+		Comment:    reflectComment(caveatDef.Metadata),
+		Parameters: parameters,
+		Expression: strings.TrimSpace(exprString),
+	}, nil
+}
+
+func reflectComment(metadata *core.Metadata) string {
+	return strings.Join(nspkg.GetComments(metadata), "\n")
+}
+
+// reflectRelationsOrPermissions returns the distinct relations and permissions found on the
+// given side of the dependency edges, ordered by definition and name.
+func reflectRelationsOrPermissions(
+	dg *namespace.DependencyGraph,
+	edges []namespace.RelationDependency,
+	side func(namespace.RelationDependency) *core.RelationReference,
+) []*experimentalv1.ReflectionRelationOrPermission {
+	found := util.NewSet[string]()
+	reflected := make([]*experimentalv1.ReflectionRelationOrPermission, 0, len(edges))
+	for _, edge := range edges {
+		rr := side(edge)
+		if !found.Add(tuple.StringRR(rr)) {
+			continue
+		}
+		reflected = append(reflected, reflectRelationOrPermission(dg, rr))
+	}
+
+	sort.Slice(reflected, func(i, j int) bool {
+		if reflected[i].DefinitionName != reflected[j].DefinitionName {
+			return reflected[i].DefinitionName < reflected[j].DefinitionName
+		}
+		return reflected[i].RelationName < reflected[j].RelationName
+	})
+	return reflected
+}
+
+func reflectDependencyEdges(dg *namespace.DependencyGraph, edges []namespace.RelationDependency) []*experimentalv1.ReflectionDependencyEdge {
+	reflected := make([]*experimentalv1.ReflectionDependencyEdge, 0, len(edges))
+	for _, edge := range edges {
+		reflected = append(reflected, &experimentalv1.ReflectionDependencyEdge{
+			Dependent:  reflectRelationOrPermission(dg, edge.Dependent),
+			Dependency: reflectRelationOrPermission(dg, edge.Dependency),
+			Kind:       reflectDependencyKind(edge.Kind),
+		})
+	}
+	return reflected
+}
+
+func reflectRelationOrPermission(dg *namespace.DependencyGraph, rr *core.RelationReference) *experimentalv1.ReflectionRelationOrPermission {
+	return &experimentalv1.ReflectionRelationOrPermission{
+		DefinitionName: rr.Namespace,
+		RelationName:   rr.Relation,
+		IsPermission:   dg.IsPermission(rr),
+	}
+}
+
+func reflectArrowFunction(function core.FunctionedTupleToUserset_Function) (string, error) {
+	switch function {
+	case core.FunctionedTupleToUserset_FUNCTION_ANY:
+		return "any", nil
+	case core.FunctionedTupleToUserset_FUNCTION_ALL:
+		return "all", nil
+	default:
+		return "", fmt.Errorf("unknown arrow function %v", function)
+	}
+}
+
+func reflectDependencyKind(kind namespace.DependencyKind) experimentalv1.ReflectionDependencyEdge_DependencyKind {
+	switch kind {
+	case namespace.DirectDependency:
+		return experimentalv1.ReflectionDependencyEdge_DIRECT
+	case namespace.ComputedUsersetDependency:
+		return experimentalv1.ReflectionDependencyEdge_COMPUTED_USERSET
+	case namespace.TupleToUsersetDependency:
+		return experimentalv1.ReflectionDependencyEdge_TUPLE_TO_USERSET
+	default:
+		return experimentalv1.ReflectionDependencyEdge_UNKNOWN_DEPENDENCY_KIND
+	}
+}
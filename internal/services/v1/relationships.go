@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -21,6 +22,7 @@ import (
 	"github.com/authzed/spicedb/internal/namespace"
 	"github.com/authzed/spicedb/internal/relationships"
 	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore/pagination"
@@ -28,6 +30,7 @@ import (
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/util"
+	"github.com/authzed/spicedb/pkg/writehook"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -63,6 +66,40 @@ type PermissionsServerConfig struct {
 	// MaxDatastoreReadPageSize defines the maximum number of relationships loaded from the
 	// datastore in one query.
 	MaxDatastoreReadPageSize uint64
+
+	// MaxBulkCheckItems defines the maximum number of items allowed in a single
+	// BulkCheckPermission call.
+	MaxBulkCheckItems uint16
+
+	// MaxDeleteRelationshipsLimit defines the maximum limit allowed for a limited
+	// DeleteRelationships call.
+	MaxDeleteRelationshipsLimit uint32
+
+	// PreCommitHooks are invoked, in order, with the updates of each WriteRelationships
+	// call, and with each batch of each BulkImportRelationships call, before they are
+	// written.
+	PreCommitHooks []writehook.PreCommitHook
+
+	// RelationshipQuotas limit the number of relationships which can be stored for
+	// definitions or relations by WriteRelationships calls.
+	RelationshipQuotas []relationships.Quota
+}
+
+// withDefaults returns a copy of the configuration with any unset values filled
+// in with their defaults.
+func (c PermissionsServerConfig) withDefaults() PermissionsServerConfig {
+	return PermissionsServerConfig{
+		MaxPreconditionsCount:       defaultIfZero(c.MaxPreconditionsCount, 1000),
+		MaxUpdatesPerWrite:          defaultIfZero(c.MaxUpdatesPerWrite, 1000),
+		MaximumAPIDepth:             defaultIfZero(c.MaximumAPIDepth, 50),
+		StreamingAPITimeout:         defaultIfZero(c.StreamingAPITimeout, 30*time.Second),
+		MaxCaveatContextSize:        c.MaxCaveatContextSize,
+		MaxDatastoreReadPageSize:    defaultIfZero(c.MaxDatastoreReadPageSize, 1_000),
+		MaxBulkCheckItems:           defaultIfZero(c.MaxBulkCheckItems, 1000),
+		MaxDeleteRelationshipsLimit: defaultIfZero(c.MaxDeleteRelationshipsLimit, 1000),
+		PreCommitHooks:              c.PreCommitHooks,
+		RelationshipQuotas:          c.RelationshipQuotas,
+	}
 }
 
 // NewPermissionsServer creates a PermissionsServiceServer instance.
@@ -70,14 +107,7 @@ func NewPermissionsServer(
 	dispatch dispatch.Dispatcher,
 	config PermissionsServerConfig,
 ) v1.PermissionsServiceServer {
-	configWithDefaults := PermissionsServerConfig{
-		MaxPreconditionsCount:    defaultIfZero(config.MaxPreconditionsCount, 1000),
-		MaxUpdatesPerWrite:       defaultIfZero(config.MaxUpdatesPerWrite, 1000),
-		MaximumAPIDepth:          defaultIfZero(config.MaximumAPIDepth, 50),
-		StreamingAPITimeout:      defaultIfZero(config.StreamingAPITimeout, 30*time.Second),
-		MaxCaveatContextSize:     config.MaxCaveatContextSize,
-		MaxDatastoreReadPageSize: defaultIfZero(config.MaxDatastoreReadPageSize, 1_000),
-	}
+	configWithDefaults := config.withDefaults()
 
 	return &permissionServer{
 		dispatch: dispatch,
@@ -147,31 +177,80 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 		DispatchCount: 1,
 	})
 
+	// Relationships are read ordered by resource, so the cursor holds the last relationship
+	// returned.
+	rrRequestHash, err := computeReadRelationshipsRequestHash(req)
+	if err != nil {
+		return rewriteError(ctx, err)
+	}
+
+	var startCursor options.Cursor
+	if req.OptionalCursor != nil {
+		decodedCursor, err := cursor.DecodeToDispatchCursor(req.OptionalCursor, rrRequestHash)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+
+		if len(decodedCursor.Sections) != 1 {
+			return rewriteError(ctx, cursor.NewInvalidCursorErr(fmt.Errorf("expected one cursor section, found %d", len(decodedCursor.Sections))))
+		}
+
+		startCursor = tuple.Parse(decodedCursor.Sections[0])
+		if startCursor == nil {
+			return rewriteError(ctx, cursor.NewInvalidCursorErr(fmt.Errorf("invalid relationship in cursor: %s", decodedCursor.Sections[0])))
+		}
+	}
+
+	pageSize := ps.config.MaxDatastoreReadPageSize
+	if req.OptionalLimit > 0 && uint64(req.OptionalLimit) < pageSize {
+		pageSize = uint64(req.OptionalLimit)
+	}
+
 	tupleIterator, err := pagination.NewPaginatedIterator(
 		ctx,
 		ds,
 		datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter),
-		ps.config.MaxDatastoreReadPageSize,
+		pageSize,
 		options.ByResource,
+		startCursor,
 	)
 	if err != nil {
 		return rewriteError(ctx, err)
 	}
 	defer tupleIterator.Close()
 
+	sent := uint32(0)
 	for tpl := tupleIterator.Next(); tpl != nil; tpl = tupleIterator.Next() {
 		if tupleIterator.Err() != nil {
 			return status.Errorf(codes.Internal, "error when reading tuples: %s", tupleIterator.Err())
 		}
 
-		err := resp.Send(&v1.ReadRelationshipsResponse{
-			ReadAt:       revisionReadAt,
-			Relationship: tuple.ToRelationship(tpl),
+		if req.OptionalLimit > 0 && sent >= req.OptionalLimit {
+			break
+		}
+
+		afterResultCursor, err := cursor.EncodeFromDispatchCursor(ctx, &dispatchv1.Cursor{
+			Sections:        []string{tuple.StringWithoutCaveat(tpl)},
+			DispatchVersion: 1,
+		}, rrRequestHash, atRevision)
+		if err != nil {
+			return rewriteError(ctx, err)
+		}
+
+		err = resp.Send(&v1.ReadRelationshipsResponse{
+			ReadAt:            revisionReadAt,
+			Relationship:      tuple.ToRelationship(tpl),
+			AfterResultCursor: afterResultCursor,
 		})
 		if err != nil {
 			return err
 		}
+		sent++
 	}
+	if tupleIterator.Err() != nil {
+		return status.Errorf(codes.Internal, "error when reading tuples: %s", tupleIterator.Err())
+	}
+
 	tupleIterator.Close()
 	return nil
 }
@@ -206,8 +285,12 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 		}
 	}
 
+	tupleUpdates, err := tuple.UpdateFromRelationshipUpdates(req.Updates)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
 	// Execute the write operation(s).
-	tupleUpdates := tuple.UpdateFromRelationshipUpdates(req.Updates)
 	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
 		// Validate the preconditions.
 		for _, precond := range req.OptionalPreconditions {
@@ -231,7 +314,20 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 			return err
 		}
 
-		return rwt.WriteRelationships(ctx, tupleUpdates)
+		if len(ps.config.PreCommitHooks) > 0 {
+			hookedUpdates, err := writehook.RunPreCommitHooks(ctx, rwt, ps.config.PreCommitHooks, tupleUpdates)
+			if err != nil {
+				return rewriteError(ctx, err)
+			}
+
+			// Hooks may annotate the updates, so those they return are validated again.
+			if err := relationships.ValidateRelationshipUpdates(ctx, rwt, hookedUpdates); err != nil {
+				return rewriteError(ctx, err)
+			}
+			return relationships.WriteWithQuotas(ctx, rwt, ps.config.RelationshipQuotas, hookedUpdates)
+		}
+
+		return relationships.WriteWithQuotas(ctx, rwt, ps.config.RelationshipQuotas, tupleUpdates)
 	})
 	if err != nil {
 		return nil, rewriteError(ctx, err)
@@ -248,7 +344,7 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 	}
 
 	return &v1.WriteRelationshipsResponse{
-		WrittenAt: zedtoken.MustNewFromRevision(revision),
+		WrittenAt: zedtoken.MustNewFromRevision(ctx, revision),
 	}, nil
 }
 
@@ -260,8 +356,17 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		)
 	}
 
+	limit := uint64(req.OptionalLimit)
+	if limit > uint64(ps.config.MaxDeleteRelationshipsLimit) {
+		return nil, rewriteError(
+			ctx,
+			NewExceedsMaximumLimitErr(limit, uint64(ps.config.MaxDeleteRelationshipsLimit)),
+		)
+	}
+
 	ds := datastoremw.MustFromContext(ctx)
 
+	deletionProgress := v1.DeleteRelationshipsResponse_DELETION_PROGRESS_COMPLETE
 	revision, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
 		if err := ps.checkFilterNamespaces(ctx, req.RelationshipFilter, rwt); err != nil {
 			return err
@@ -276,13 +381,58 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 			return err
 		}
 
-		return rwt.DeleteRelationships(ctx, req.RelationshipFilter)
+		if limit == 0 {
+			_, err := rwt.DeleteRelationships(ctx, req.RelationshipFilter)
+			return err
+		}
+
+		if !req.OptionalAllowPartialDeletions {
+			// Ensure that no more than the limit would be deleted, so that the delete is
+			// all-or-nothing. The delete itself is not limited, as it also removes any
+			// matching relationships which have expired but not yet been collected.
+			limitPlusOne := limit + 1
+			iter, err := rwt.QueryRelationships(
+				ctx,
+				datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter),
+				options.WithLimit(&limitPlusOne),
+			)
+			if err != nil {
+				return err
+			}
+
+			var matching uint64
+			for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+				matching++
+			}
+			err = iter.Err()
+			iter.Close()
+			if err != nil {
+				return err
+			}
+
+			if matching > limit {
+				return NewCouldNotTransactionallyDeleteErr(req.RelationshipFilter, limit)
+			}
+
+			_, err = rwt.DeleteRelationships(ctx, req.RelationshipFilter)
+			return err
+		}
+
+		limitReached, err := rwt.DeleteRelationships(ctx, req.RelationshipFilter, options.WithDeleteLimit(&limit))
+		if err != nil {
+			return err
+		}
+		if limitReached {
+			deletionProgress = v1.DeleteRelationshipsResponse_DELETION_PROGRESS_PARTIAL
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, rewriteError(ctx, err)
 	}
 
 	return &v1.DeleteRelationshipsResponse{
-		DeletedAt: zedtoken.MustNewFromRevision(revision),
+		DeletedAt:        zedtoken.MustNewFromRevision(ctx, revision),
+		DeletionProgress: deletionProgress,
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,15 +14,19 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/writehook"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -215,7 +220,7 @@ func TestReadRelationships(t *testing.T) {
 					stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
 						Consistency: &v1.Consistency{
 							Requirement: &v1.Consistency_AtLeastAsFresh{
-								AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+								AtLeastAsFresh: zedtoken.MustNewFromRevision(context.Background(), revision),
 							},
 						},
 						RelationshipFilter: tc.filter,
@@ -418,6 +423,51 @@ func TestWriteCaveatedRelationships(t *testing.T) {
 	req.True(proto.Equal(relWritten, relRead))
 }
 
+func TestWriteRelationshipsWithExpiration(t *testing.T) {
+	req := require.New(t)
+
+	conn, cleanup, _, _ := testserver.NewTestServer(req, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	expiring := rel("document", "companyplan", "viewer", "user", "expiring", "")
+	tuple.SetRelationshipExpiration(expiring, timestamppb.New(expiresAt))
+
+	expired := rel("document", "companyplan", "viewer", "user", "expired", "")
+	tuple.SetRelationshipExpiration(expired, timestamppb.New(time.Now().Add(-time.Hour)))
+
+	resp, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: expiring},
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: expired},
+		},
+	})
+	req.NoError(err)
+
+	// The expiration is returned when the relationship is read back.
+	relRead := readFirst(req, client, resp.WrittenAt, expiring)
+	req.True(proto.Equal(expiring, relRead))
+
+	expiration, err := tuple.RelationshipExpiration(relRead)
+	req.NoError(err)
+	req.Equal(expiresAt, expiration.AsTime())
+
+	// A relationship which has already expired is not returned.
+	_, found := readAll(req, client, resp.WrittenAt)["document:companyplan#viewer@user:expired"]
+	req.False(found)
+
+	// An invalid expiration is rejected.
+	invalid := rel("document", "companyplan", "viewer", "user", "invalid", "")
+	invalid.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 5, protowire.VarintType), 1))
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_CREATE, Relationship: invalid},
+		},
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func readFirst(require *require.Assertions, client v1.PermissionsServiceClient, token *v1.ZedToken, rel *v1.Relationship) *v1.Relationship {
 	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
 		Consistency: &v1.Consistency{
@@ -973,7 +1023,7 @@ func TestDeleteRelationships(t *testing.T) {
 				}
 				require.NoError(err)
 				require.NotNil(resp.DeletedAt)
-				rev, err := zedtoken.DecodeRevision(resp.DeletedAt, ds)
+				rev, err := zedtoken.DecodeRevision(context.Background(), resp.DeletedAt, ds)
 				require.NoError(err)
 				require.True(rev.GreaterThan(revision))
 				require.EqualValues(standardTuplesWithout(tc.deleted), readAll(require, client, resp.DeletedAt))
@@ -1122,6 +1172,78 @@ func TestWriteRelationshipsUpdatesOverLimit(t *testing.T) {
 	require.Contains(err.Error(), "update count of 2 is greater than maximum allowed of 1")
 }
 
+func TestReadRelationshipsWithLimitAndCursor(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	request := func(limit uint32, cursor *v1.Cursor) *v1.ReadRelationshipsRequest {
+		return &v1.ReadRelationshipsRequest{
+			Consistency: &v1.Consistency{
+				Requirement: &v1.Consistency_AtExactSnapshot{
+					AtExactSnapshot: zedtoken.MustNewFromRevision(context.Background(), revision),
+				},
+			},
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+			OptionalLimit:      limit,
+			OptionalCursor:     cursor,
+		}
+	}
+
+	expected := readAll(require, client, zedtoken.MustNewFromRevision(context.Background(), revision))
+	for rel := range expected {
+		if !strings.HasPrefix(rel, "document:") {
+			delete(expected, rel)
+		}
+	}
+
+	for _, limit := range []uint32{1, 2, 5, 100} {
+		got := make(map[string]struct{})
+		var cursor *v1.Cursor
+		for {
+			stream, err := client.ReadRelationships(context.Background(), request(limit, cursor))
+			require.NoError(err)
+
+			var count uint32
+			for {
+				resp, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(err)
+				require.NotNil(resp.AfterResultCursor)
+
+				relString := tuple.MustRelString(resp.Relationship)
+				require.NotContains(got, relString)
+				got[relString] = struct{}{}
+				cursor = resp.AfterResultCursor
+				count++
+			}
+
+			require.LessOrEqual(count, limit)
+			if count < limit {
+				break
+			}
+		}
+
+		require.Equal(expected, got, "mismatch for limit %d", limit)
+	}
+
+	// A cursor cannot be used with a different filter.
+	stream, err := client.ReadRelationships(context.Background(), request(1, nil))
+	require.NoError(err)
+	resp, err := stream.Recv()
+	require.NoError(err)
+
+	differentFilter := request(1, resp.AfterResultCursor)
+	differentFilter.RelationshipFilter.ResourceType = "folder"
+	stream, err = client.ReadRelationships(context.Background(), differentFilter)
+	require.NoError(err)
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func readAll(require *require.Assertions, client v1.PermissionsServiceClient, token *v1.ZedToken) map[string]struct{} {
 	got := make(map[string]struct{})
 	namespaces := []string{"document", "folder"}
@@ -1162,3 +1284,200 @@ func standardTuplesWithout(without map[string]struct{}) map[string]struct{} {
 	}
 	return out
 }
+
+// viewerLimitHook rejects writes which would make a user a viewer of more than the maximum
+// number of documents.
+type viewerLimitHook struct {
+	maxDocuments int
+}
+
+func (h viewerLimitHook) Name() string { return "viewer-limit" }
+
+func (h viewerLimitHook) PreCommit(ctx context.Context, reader datastore.Reader, updates []*core.RelationTupleUpdate) ([]*core.RelationTupleUpdate, error) {
+	for _, update := range updates {
+		if update.Operation == core.RelationTupleUpdate_DELETE || update.Tuple.ResourceAndRelation.Relation != "viewer" {
+			continue
+		}
+
+		iter, err := reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+			SubjectType:        update.Tuple.Subject.Namespace,
+			OptionalSubjectIds: []string{update.Tuple.Subject.ObjectId},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		count := 1
+		for rt := iter.Next(); rt != nil; rt = iter.Next() {
+			if rt.ResourceAndRelation.Namespace == "document" && rt.ResourceAndRelation.Relation == "viewer" {
+				count++
+			}
+		}
+		iter.Close()
+
+		if count > h.maxDocuments {
+			return nil, writehook.Reject(fmt.Sprintf("%s cannot view more than %d documents", tuple.StringONR(update.Tuple.Subject), h.maxDocuments))
+		}
+	}
+	return updates, nil
+}
+
+// touchHook annotates every update by rewriting it to a touch.
+type touchHook struct{}
+
+func (touchHook) Name() string { return "touch" }
+
+func (touchHook) PreCommit(_ context.Context, _ datastore.Reader, updates []*core.RelationTupleUpdate) ([]*core.RelationTupleUpdate, error) {
+	touched := make([]*core.RelationTupleUpdate, 0, len(updates))
+	for _, update := range updates {
+		touched = append(touched, tuple.Touch(update.Tuple))
+	}
+	return touched, nil
+}
+
+func TestWriteRelationshipsPreCommitHooks(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount: 1000,
+			MaxUpdatesPerWrite:    1000,
+			PreCommitHooks:        []writehook.PreCommitHook{touchHook{}, viewerLimitHook{maxDocuments: 1}},
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	// eng_lead is already a viewer of the masterplan document.
+	_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: rel("document", "healthplan", "viewer", "user", "eng_lead", ""),
+		}},
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "write rejected by hook `viewer-limit`: user:eng_lead cannot view more than 1 documents")
+
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: rel("document", "healthplan", "viewer", "user", "newviewer", ""),
+		}},
+	})
+	require.NoError(err)
+
+	// The existing relationship would fail to be created, were it not rewritten to a touch by
+	// the first hook.
+	resp, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+			Relationship: rel("document", "masterplan", "owner", "user", "product_manager", ""),
+		}},
+	})
+	require.NoError(err)
+
+	rels := readAll(require, client, resp.WrittenAt)
+	require.Contains(rels, "document:healthplan#viewer@user:newviewer")
+	require.NotContains(rels, "document:healthplan#viewer@user:eng_lead")
+}
+
+func TestWriteRelationshipsQuotas(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount: 1000,
+			MaxUpdatesPerWrite:    1000,
+			RelationshipQuotas:    []string{"document#viewer=2", "folder=8"},
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	write := func(updates ...*v1.RelationshipUpdate) error {
+		_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+		return err
+	}
+	touch := func(relationship *v1.Relationship) *v1.RelationshipUpdate {
+		return &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: relationship}
+	}
+
+	// The standard data has one document viewer, so one more is allowed.
+	require.NoError(write(touch(rel("document", "healthplan", "viewer", "user", "newviewer", ""))))
+
+	err := write(touch(rel("document", "healthplan", "viewer", "user", "anotherviewer", "")))
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+	require.ErrorContains(err, "`document#viewer` is limited to 2 relationships, but the write would store 3")
+
+	// Touching an existing relationship does not increase the count.
+	require.NoError(write(touch(rel("document", "masterplan", "viewer", "user", "eng_lead", ""))))
+
+	// Other relations of the definition are not limited.
+	require.NoError(write(touch(rel("document", "healthplan", "owner", "user", "newowner", ""))))
+
+	// The standard data already has eight folder relationships.
+	err = write(touch(rel("folder", "newfolder", "viewer", "user", "newviewer", "")))
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+	require.ErrorContains(err, "`folder` is limited to 8 relationships, but the write would store 9")
+
+	// Relationships can be replaced within the quota.
+	require.NoError(write(
+		&v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_DELETE,
+			Relationship: rel("document", "healthplan", "viewer", "user", "newviewer", ""),
+		},
+		touch(rel("document", "healthplan", "viewer", "user", "anotherviewer", "")),
+	))
+}
+
+func TestDeleteRelationshipsWithLimit(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	// The standard data has eight folder relationships.
+	folders := &v1.RelationshipFilter{ResourceType: "folder"}
+	deleteWithLimit := func(limit uint32, allowPartial bool) (*v1.DeleteRelationshipsResponse, error) {
+		return client.DeleteRelationships(context.Background(), &v1.DeleteRelationshipsRequest{
+			RelationshipFilter:            folders,
+			OptionalLimit:                 limit,
+			OptionalAllowPartialDeletions: allowPartial,
+		})
+	}
+	countFolders := func(token *v1.ZedToken) int {
+		var count int
+		for rel := range readAll(require, client, token) {
+			if strings.HasPrefix(rel, "folder:") {
+				count++
+			}
+		}
+		return count
+	}
+
+	_, err := deleteWithLimit(1001, true)
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	// Without partial deletions, nothing is deleted if more than the limit match.
+	_, err = deleteWithLimit(3, false)
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "found more than 3 relationships to be deleted")
+
+	resp, err := deleteWithLimit(3, true)
+	require.NoError(err)
+	require.Equal(v1.DeleteRelationshipsResponse_DELETION_PROGRESS_PARTIAL, resp.DeletionProgress)
+	require.Equal(5, countFolders(resp.DeletedAt))
+
+	resp, err = deleteWithLimit(5, false)
+	require.NoError(err)
+	require.Equal(v1.DeleteRelationshipsResponse_DELETION_PROGRESS_COMPLETE, resp.DeletionProgress)
+	require.Equal(0, countFolders(resp.DeletedAt))
+}
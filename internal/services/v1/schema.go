@@ -3,9 +3,12 @@ package v1
 import (
 	"context"
 
+	"github.com/authzed/authzed-go/pkg/responsemeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	log "github.com/authzed/spicedb/internal/logging"
@@ -20,6 +23,18 @@ import (
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 )
 
+// SchemaWarningsTrailer is the key in the response trailer metadata of WriteSchema calls, with one
+// value for each warning found when linting the written schema. Warnings do not prevent the schema
+// from being written, and are omitted if the schema could not be linted.
+//
+// WriteSchemaResponse has no field for warnings in the version of the API used, so they are
+// returned alongside the other response metadata keys defined by the responsemeta package.
+const SchemaWarningsTrailer responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.schemawarnings"
+
+// lintSchema lints the validated schema being written. It is a variable so that tests can make
+// linting fail.
+var lintSchema = (*shared.ValidatedSchemaChanges).Lint
+
 // NewSchemaServer creates a SchemaServiceServer instance.
 func NewSchemaServer(additiveOnly bool) v1.SchemaServiceServer {
 	return &schemaServer{
@@ -52,20 +67,35 @@ func (ss *schemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest)
 		return nil, rewriteError(ctx, err)
 	}
 
-	reader := ds.SnapshotReader(headRevision)
-
-	nsDefs, err := reader.ListAllNamespaces(ctx)
+	schemaText, nsDefCount, caveatDefCount, err := generateSchemaAtRevision(ctx, ds.SnapshotReader(headRevision))
 	if err != nil {
 		return nil, rewriteError(ctx, err)
 	}
 
-	caveatDefs, err := reader.ListAllCaveats(ctx)
+	if nsDefCount == 0 {
+		return nil, status.Errorf(codes.NotFound, "No schema has been defined; please call WriteSchema to start")
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		DispatchCount: uint32(nsDefCount + caveatDefCount),
+	})
+
+	return &v1.ReadSchemaResponse{
+		SchemaText: schemaText,
+	}, nil
+}
+
+// generateSchemaAtRevision generates the schema text for all the namespace and caveat
+// definitions found in the reader, returning the text and the number of each definition found.
+func generateSchemaAtRevision(ctx context.Context, reader datastore.Reader) (string, int, int, error) {
+	nsDefs, err := reader.ListAllNamespaces(ctx)
 	if err != nil {
-		return nil, rewriteError(ctx, err)
+		return "", 0, 0, err
 	}
 
-	if len(nsDefs) == 0 {
-		return nil, status.Errorf(codes.NotFound, "No schema has been defined; please call WriteSchema to start")
+	caveatDefs, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return "", 0, 0, err
 	}
 
 	schemaDefinitions := make([]compiler.SchemaDefinition, 0, len(nsDefs)+len(caveatDefs))
@@ -79,16 +109,10 @@ func (ss *schemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest)
 
 	schemaText, _, err := generator.GenerateSchema(schemaDefinitions)
 	if err != nil {
-		return nil, rewriteError(ctx, err)
+		return "", 0, 0, err
 	}
 
-	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
-		DispatchCount: uint32(len(nsDefs) + len(caveatDefs)),
-	})
-
-	return &v1.ReadSchemaResponse{
-		SchemaText: schemaText,
-	}, nil
+	return schemaText, len(nsDefs), len(caveatDefs), nil
 }
 
 func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaRequest) (*v1.WriteSchemaResponse, error) {
@@ -113,6 +137,14 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 		return nil, rewriteError(ctx, err)
 	}
 
+	// Warnings are advisory, so a failure to lint the schema does not prevent it from being
+	// written.
+	warnings, err := lintSchema(validated)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("unable to lint schema being written")
+		warnings = nil
+	}
+
 	// Update the schema.
 	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
 		applied, err := shared.ApplySchemaChanges(ctx, rwt, validated)
@@ -128,5 +160,16 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 		return nil, rewriteError(ctx, err)
 	}
 
+	if len(warnings) > 0 {
+		trailer := metadata.MD{}
+		for _, warning := range warnings {
+			trailer.Append(string(SchemaWarningsTrailer), warning.String())
+		}
+
+		if err := grpc.SetTrailer(ctx, trailer); err != nil {
+			return nil, rewriteError(ctx, err)
+		}
+	}
+
 	return &v1.WriteSchemaResponse{}, nil
 }
@@ -9,9 +9,12 @@ import (
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -79,6 +82,45 @@ func TestSchemaWriteAndReadBack(t *testing.T) {
 	require.Equal(t, userSchema, readback.SchemaText)
 }
 
+func TestSchemaWriteWithWarnings(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	var trailer metadata.MD
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+definition document {
+	relation viewer: user
+	relation owner: user
+	permission view = viewer
+	permission edit = nil
+}`,
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"relation `owner` is not referenced by any permission (line 5, column 2)",
+		"permission `edit` always evaluates to nil (line 7, column 2)",
+	}, trailer.Get(string(v1svc.SchemaWarningsTrailer)))
+
+	// The schema is written despite the warnings.
+	readback, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, readback.SchemaText, "permission edit = nil")
+
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`,
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Empty(t, trailer.Get(string(v1svc.SchemaWarningsTrailer)))
+}
+
 func TestSchemaDeleteRelation(t *testing.T) {
 	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
 	t.Cleanup(cleanup)
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/services/shared"
+)
+
+func TestWriteSchemaLintFailure(t *testing.T) {
+	require := require.New(t)
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	t.Cleanup(func() { ds.Close() })
+
+	lintSchema = func(*shared.ValidatedSchemaChanges) ([]namespace.LintWarning, error) {
+		return nil, errors.New("linter failed")
+	}
+	t.Cleanup(func() { lintSchema = (*shared.ValidatedSchemaChanges).Lint })
+
+	// The schema is written even though it could not be linted.
+	ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+	ss := NewSchemaServer(false)
+	_, err = ss.WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: `definition user {}
+
+definition document {
+	relation viewer: user
+}`,
+	})
+	require.NoError(err)
+
+	read, err := ss.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(err)
+	require.Contains(read.SchemaText, "definition document")
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"errors"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
@@ -21,14 +22,19 @@ import (
 type watchServer struct {
 	v1.UnimplementedWatchServiceServer
 	shared.WithStreamServiceSpecificInterceptor
+
+	heartbeatDuration time.Duration
 }
 
-// NewWatchServer creates an instance of the watch server.
-func NewWatchServer() v1.WatchServiceServer {
+// NewWatchServer creates an instance of the watch server. If heartbeatDuration is non-zero,
+// a response without updates is sent at that interval to report the revision through which
+// changes have been processed, even if none of them matched the request.
+func NewWatchServer(heartbeatDuration time.Duration) v1.WatchServiceServer {
 	s := &watchServer{
 		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
 			Stream: grpcvalidate.StreamServerInterceptor(true),
 		},
+		heartbeatDuration: heartbeatDuration,
 	}
 	return s
 }
@@ -44,7 +50,7 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 
 	var afterRevision datastore.Revision
 	if req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != "" {
-		decodedRevision, err := zedtoken.DecodeRevision(req.OptionalStartCursor, ds)
+		decodedRevision, err := zedtoken.DecodeRevision(ctx, req.OptionalStartCursor, ds)
 		if err != nil {
 			return status.Errorf(codes.InvalidArgument, "failed to decode start revision: %s", err)
 		}
@@ -62,16 +68,19 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 		DispatchCount: 1,
 	})
 
-	updates, errchan := ds.Watch(ctx, afterRevision)
+	options := datastore.WatchJustRelationships()
+	options.CheckpointInterval = ws.heartbeatDuration
+
+	updates, errchan := ds.Watch(ctx, afterRevision, options)
 	for {
 		select {
 		case update, ok := <-updates:
 			if ok {
 				filtered := filterUpdates(objectTypesMap, update.Changes)
-				if len(filtered) > 0 {
+				if len(filtered) > 0 || update.IsCheckpoint {
 					if err := stream.Send(&v1.WatchResponse{
 						Updates:        filtered,
-						ChangesThrough: zedtoken.MustNewFromRevision(update.Revision),
+						ChangesThrough: zedtoken.MustNewFromRevision(ctx, update.Revision),
 					}); err != nil {
 						return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
 					}
@@ -15,10 +15,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/testutil"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
@@ -49,7 +51,14 @@ func update(
 	}
 }
 
+func withExpiration(update *v1.RelationshipUpdate, expiration time.Time) *v1.RelationshipUpdate {
+	tuple.SetRelationshipExpiration(update.Relationship, timestamppb.New(expiration))
+	return update
+}
+
 func TestWatch(t *testing.T) {
+	expiresAt := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
 	testCases := []struct {
 		name              string
 		objectTypesFilter []string
@@ -86,6 +95,16 @@ func TestWatch(t *testing.T) {
 				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document2", "viewer", "user", "user1"),
 			},
 		},
+		{
+			name:         "watch with expiration",
+			expectedCode: codes.OK,
+			mutations: []*v1.RelationshipUpdate{
+				withExpiration(update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "document1", "viewer", "user", "user1"), expiresAt),
+			},
+			expectedUpdates: []*v1.RelationshipUpdate{
+				withExpiration(update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document1", "viewer", "user", "user1"), expiresAt),
+			},
+		},
 		{
 			name:         "invalid zedtoken",
 			startCursor:  &v1.ZedToken{Token: "bad-token"},
@@ -107,7 +126,7 @@ func TestWatch(t *testing.T) {
 			t.Cleanup(cleanup)
 			client := v1.NewWatchServiceClient(conn)
 
-			cursor := zedtoken.MustNewFromRevision(revision)
+			cursor := zedtoken.MustNewFromRevision(context.Background(), revision)
 			if tc.startCursor != nil {
 				cursor = tc.startCursor
 			}
@@ -166,7 +185,11 @@ func TestWatch(t *testing.T) {
 					}
 				}
 
-				require.Equal(sortUpdates(tc.expectedUpdates), sortUpdates(receivedUpdates))
+				expectedUpdates, sortedUpdates := sortUpdates(tc.expectedUpdates), sortUpdates(receivedUpdates)
+				require.Len(sortedUpdates, len(expectedUpdates))
+				for index, expected := range expectedUpdates {
+					testutil.RequireProtoEqual(t, expected, sortedUpdates[index], "mismatch in update %d", index)
+				}
 			} else {
 				_, err := stream.Recv()
 				grpcutil.RequireStatus(t, tc.expectedCode, err)
@@ -193,3 +216,53 @@ func sortUpdates(in []*v1.RelationshipUpdate) []*v1.RelationshipUpdate {
 
 	return out
 }
+
+func TestWatchHeartbeat(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, ds, revision := testserver.NewTestServerWithConfig(
+		require,
+		0,
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxUpdatesPerWrite:    1000,
+			MaxPreconditionsCount: 1000,
+			WatchHeartbeat:        100 * time.Millisecond,
+		},
+		testfixtures.StandardDatastoreWithData,
+	)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := v1.NewWatchServiceClient(conn).Watch(ctx, &v1.WatchRequest{
+		OptionalObjectTypes: []string{"folder"},
+		OptionalStartCursor: zedtoken.MustNewFromRevision(ctx, revision),
+	})
+	require.NoError(err)
+
+	resp, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "newplan", "owner", "user", "tom"),
+		},
+	})
+	require.NoError(err)
+
+	writtenRevision, err := zedtoken.DecodeRevision(ctx, resp.WrittenAt, ds)
+	require.NoError(err)
+
+	// None of the changes match the filter, but heartbeats must still report progress past them.
+	for {
+		heartbeat, err := stream.Recv()
+		require.NoError(err)
+		require.Empty(heartbeat.Updates)
+
+		changesThrough, err := zedtoken.DecodeRevision(ctx, heartbeat.ChangesThrough, ds)
+		require.NoError(err)
+		if !changesThrough.LessThan(writtenRevision) {
+			return
+		}
+	}
+}
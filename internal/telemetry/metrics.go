@@ -2,11 +2,13 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
 	"runtime/debug"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/jzelinskie/cobrautil/v2"
@@ -164,3 +166,136 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 		log.Error().Err(err).Msg("error collecting metrics")
 	}
 }
+
+// RegisterDatastoreStatisticsCollector registers a collector which reports the detailed
+// statistics of the datastore as gauges. As computing the statistics may be expensive, they
+// are recomputed in the background once per refreshInterval, and the most recently computed
+// statistics are reported when metrics are collected. The returned function unregisters the
+// collector and stops recomputing the statistics.
+//
+// Unlike the telemetry collector, these statistics include the names of definitions and
+// relations, and so must never be registered with the telemetry registry.
+func RegisterDatastoreStatisticsCollector(registerer prometheus.Registerer, ds datastore.Datastore, refreshInterval time.Duration) (func(), error) {
+	c := &datastoreStatsCollector{
+		ds:              ds,
+		refreshInterval: refreshInterval,
+		definitionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("spicedb", "datastore", "definition_relationships"),
+			"Number of relationships stored for each definition.",
+			[]string{"definition"},
+			nil,
+		),
+		relationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("spicedb", "datastore", "relation_relationships"),
+			"Number of relationships stored for each relation.",
+			[]string{"definition", "relation"},
+			nil,
+		),
+		storageDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("spicedb", "datastore", "relationship_storage_bytes"),
+			"Storage used by relationships, including their indexes.",
+			nil,
+			nil,
+		),
+		backlogDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("spicedb", "datastore", "changelog_backlog"),
+			"Number of changelog entries which have not yet been garbage collected.",
+			nil,
+			nil,
+		),
+	}
+
+	if err := registerer.Register(c); err != nil {
+		return nil, fmt.Errorf("unable to register datastore statistics collector: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.refreshPeriodically(ctx)
+	}()
+
+	return func() {
+		registerer.Unregister(c)
+		cancel()
+		<-done
+	}, nil
+}
+
+type datastoreStatsCollector struct {
+	ds              datastore.Datastore
+	refreshInterval time.Duration
+	definitionDesc  *prometheus.Desc
+	relationDesc    *prometheus.Desc
+	storageDesc     *prometheus.Desc
+	backlogDesc     *prometheus.Desc
+
+	sync.Mutex
+	stats *datastore.DetailedStats
+}
+
+var _ prometheus.Collector = &datastoreStatsCollector{}
+
+func (c *datastoreStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.definitionDesc
+	ch <- c.relationDesc
+	ch <- c.storageDesc
+	ch <- c.backlogDesc
+}
+
+func (c *datastoreStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Lock()
+	stats := c.stats
+	c.Unlock()
+
+	// Nothing is reported until the statistics have first been computed.
+	if stats == nil {
+		return
+	}
+
+	for _, def := range stats.Definitions {
+		ch <- prometheus.MustNewConstMetric(c.definitionDesc, prometheus.GaugeValue, float64(def.RelationshipCount), def.Definition)
+		for _, rel := range def.Relations {
+			ch <- prometheus.MustNewConstMetric(c.relationDesc, prometheus.GaugeValue, float64(rel.RelationshipCount), def.Definition, rel.Relation)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.storageDesc, prometheus.GaugeValue, float64(stats.StorageSizeBytes))
+	ch <- prometheus.MustNewConstMetric(c.backlogDesc, prometheus.GaugeValue, float64(stats.ChangelogBacklog))
+}
+
+// refreshPeriodically computes the statistics immediately and then once per refresh
+// interval, until the context is canceled. Each computation may take up to the refresh
+// interval; if it fails, the previous statistics continue to be reported.
+func (c *datastoreStatsCollector) refreshPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *datastoreStatsCollector) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.refreshInterval)
+	defer cancel()
+
+	stats, err := c.ds.DetailedStatistics(ctx)
+	if err != nil {
+		// Computations canceled by unregistering the collector are not failures.
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			log.Warn().Err(err).Msg("unable to collect detailed datastore statistics")
+		}
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.stats = &stats
+}
@@ -211,12 +211,39 @@ func (vrwt validatingReadWriteTransaction) WriteRelationships(ctx context.Contex
 	return vrwt.delegate.WriteRelationships(ctx, mutations)
 }
 
-func (vrwt validatingReadWriteTransaction) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error {
+func (vrwt validatingReadWriteTransaction) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
 	if err := filter.Validate(); err != nil {
-		return err
+		return false, err
+	}
+
+	return vrwt.delegate.DeleteRelationships(ctx, filter, options...)
+}
+
+func (vrwt validatingReadWriteTransaction) BulkLoad(ctx context.Context, source datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return vrwt.delegate.BulkLoad(ctx, validatingBulkSource{source})
+}
+
+func (vrwt validatingReadWriteTransaction) RelationshipCounts(ctx context.Context, definitions ...string) (map[datastore.RelationKey]uint64, error) {
+	return vrwt.delegate.RelationshipCounts(ctx, definitions...)
+}
+
+// validatingBulkSource validates each relationship read from the source before handing it to
+// the datastore.
+type validatingBulkSource struct {
+	delegate datastore.BulkWriteRelationshipSource
+}
+
+func (vbs validatingBulkSource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	tpl, err := vbs.delegate.Next(ctx)
+	if err != nil || tpl == nil {
+		return tpl, err
+	}
+
+	if err := validateUpdatesToWrite(tuple.Create(tpl)); err != nil {
+		return nil, err
 	}
 
-	return vrwt.delegate.DeleteRelationships(ctx, filter)
+	return tpl, nil
 }
 
 func (vrwt validatingReadWriteTransaction) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
@@ -17,12 +17,16 @@ import (
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/middleware/logging"
+	"github.com/authzed/spicedb/pkg/writehook"
 )
 
 // ServerConfig is configuration for the test server.
 type ServerConfig struct {
 	MaxUpdatesPerWrite    uint16
 	MaxPreconditionsCount uint16
+	WatchHeartbeat        time.Duration
+	PreCommitHooks        []writehook.PreCommitHook
+	RelationshipQuotas    []string
 }
 
 // NewTestServer creates a new test server, using defaults for the config.
@@ -59,6 +63,9 @@ func NewTestServerWithConfig(require *require.Assertions,
 		server.WithMaximumPreconditionCount(config.MaxPreconditionsCount),
 		server.WithMaximumUpdatesPerWrite(config.MaxUpdatesPerWrite),
 		server.WithMaxCaveatContextSize(4096),
+		server.WithWatchHeartbeat(config.WatchHeartbeat),
+		server.SetPreCommitHooks(config.PreCommitHooks),
+		server.SetRelationshipQuotas(config.RelationshipQuotas),
 		server.WithGRPCServer(util.GRPCServerConfig{
 			Network: util.BufferedNetwork,
 			Enabled: true,
@@ -87,8 +94,8 @@ func NewTestServerWithConfig(require *require.Assertions,
 					},
 					{
 						Name:                "consistency",
-						UnaryMiddleware:     consistency.UnaryServerInterceptor(),
-						StreamingMiddleware: consistency.StreamServerInterceptor(),
+						UnaryMiddleware:     consistency.UnaryServerInterceptor(nil),
+						StreamingMiddleware: consistency.StreamServerInterceptor(nil),
 					},
 					{
 						Name:                "servicespecific",
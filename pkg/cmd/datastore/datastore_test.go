@@ -7,6 +7,8 @@ import (
 
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 func TestDefaults(t *testing.T) {
@@ -78,3 +80,48 @@ func TestLoadDatastoreFromFileAndContents(t *testing.T) {
 	require.Contains(t, namespaceNames, "user")
 	require.Contains(t, namespaceNames, "repository")
 }
+
+func TestLoadDatastoreWithRelationshipIntegrity(t *testing.T) {
+	keyFile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	_, err = keyFile.Write([]byte("some-secret"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ds, err := NewDatastore(ctx,
+		SetBootstrapFileContents(map[string][]byte{"test": []byte(`
+schema: |-
+  definition user {}
+  definition document {
+    relation viewer: user
+  }
+relationships: document:first#viewer@user:tom
+`)}),
+		WithRelationshipIntegrityEnabled(true),
+		WithRelationshipIntegrityCurrentKeyID("current"),
+		WithRelationshipIntegrityCurrentKeyFilename(keyFile.Name()),
+		WithEngine(MemoryEngine))
+	require.NoError(t, err)
+
+	revision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	// Bootstrapped relationships are hashed with the current key.
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: "document"})
+	require.NoError(t, err)
+	defer iter.Close()
+
+	rt := iter.Next()
+	require.NotNil(t, rt)
+	require.Equal(t, "current", rt.Integrity.KeyId)
+	require.Nil(t, iter.Next())
+	require.NoError(t, iter.Err())
+
+	_, err = NewDatastore(ctx,
+		WithRelationshipIntegrityEnabled(true),
+		WithRelationshipIntegrityCurrentKeyID("current"),
+		WithRelationshipIntegrityCurrentKeyFilename(keyFile.Name()),
+		WithRelationshipIntegrityExpiredKeys("missing-filename"),
+		WithEngine(MemoryEngine))
+	require.ErrorContains(t, err, "id=filename")
+}
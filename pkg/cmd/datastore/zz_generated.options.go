@@ -36,15 +36,24 @@ func (c *Config) ToOption() ConfigOption {
 		to.RequestHedgingInitialSlowValue = c.RequestHedgingInitialSlowValue
 		to.RequestHedgingMaxRequests = c.RequestHedgingMaxRequests
 		to.RequestHedgingQuantile = c.RequestHedgingQuantile
+		to.RelationshipIntegrityEnabled = c.RelationshipIntegrityEnabled
+		to.RelationshipIntegrityCurrentKeyID = c.RelationshipIntegrityCurrentKeyID
+		to.RelationshipIntegrityCurrentKeyFilename = c.RelationshipIntegrityCurrentKeyFilename
+		to.RelationshipIntegrityExpiredKeys = c.RelationshipIntegrityExpiredKeys
+		to.RelationshipIntegrityLogMismatches = c.RelationshipIntegrityLogMismatches
 		to.FollowerReadDelay = c.FollowerReadDelay
 		to.MaxRetries = c.MaxRetries
 		to.OverlapKey = c.OverlapKey
 		to.OverlapStrategy = c.OverlapStrategy
 		to.GCInterval = c.GCInterval
 		to.GCMaxOperationTime = c.GCMaxOperationTime
+		to.ReadReplicaURIs = c.ReadReplicaURIs
+		to.ReadReplicaPollInterval = c.ReadReplicaPollInterval
 		to.SpannerCredentialsFile = c.SpannerCredentialsFile
 		to.SpannerEmulatorHost = c.SpannerEmulatorHost
 		to.TablePrefix = c.TablePrefix
+		to.MemorySnapshotFile = c.MemorySnapshotFile
+		to.MemorySnapshotInterval = c.MemorySnapshotInterval
 		to.WatchBufferLength = c.WatchBufferLength
 		to.MigrationPhase = c.MigrationPhase
 	}
@@ -205,6 +214,48 @@ func WithRequestHedgingQuantile(requestHedgingQuantile float64) ConfigOption {
 	}
 }
 
+// WithRelationshipIntegrityEnabled returns an option that can set RelationshipIntegrityEnabled on a Config
+func WithRelationshipIntegrityEnabled(relationshipIntegrityEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityEnabled = relationshipIntegrityEnabled
+	}
+}
+
+// WithRelationshipIntegrityCurrentKeyID returns an option that can set RelationshipIntegrityCurrentKeyID on a Config
+func WithRelationshipIntegrityCurrentKeyID(relationshipIntegrityCurrentKeyID string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityCurrentKeyID = relationshipIntegrityCurrentKeyID
+	}
+}
+
+// WithRelationshipIntegrityCurrentKeyFilename returns an option that can set RelationshipIntegrityCurrentKeyFilename on a Config
+func WithRelationshipIntegrityCurrentKeyFilename(relationshipIntegrityCurrentKeyFilename string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityCurrentKeyFilename = relationshipIntegrityCurrentKeyFilename
+	}
+}
+
+// WithRelationshipIntegrityExpiredKeys returns an option that can append RelationshipIntegrityExpiredKeyss to Config.RelationshipIntegrityExpiredKeys
+func WithRelationshipIntegrityExpiredKeys(relationshipIntegrityExpiredKeys string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityExpiredKeys = append(c.RelationshipIntegrityExpiredKeys, relationshipIntegrityExpiredKeys)
+	}
+}
+
+// SetRelationshipIntegrityExpiredKeys returns an option that can set RelationshipIntegrityExpiredKeys on a Config
+func SetRelationshipIntegrityExpiredKeys(relationshipIntegrityExpiredKeys []string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityExpiredKeys = relationshipIntegrityExpiredKeys
+	}
+}
+
+// WithRelationshipIntegrityLogMismatches returns an option that can set RelationshipIntegrityLogMismatches on a Config
+func WithRelationshipIntegrityLogMismatches(relationshipIntegrityLogMismatches bool) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityLogMismatches = relationshipIntegrityLogMismatches
+	}
+}
+
 // WithFollowerReadDelay returns an option that can set FollowerReadDelay on a Config
 func WithFollowerReadDelay(followerReadDelay time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -247,6 +298,27 @@ func WithGCMaxOperationTime(gCMaxOperationTime time.Duration) ConfigOption {
 	}
 }
 
+// WithReadReplicaURIs returns an option that can append ReadReplicaURIss to Config.ReadReplicaURIs
+func WithReadReplicaURIs(readReplicaURIs string) ConfigOption {
+	return func(c *Config) {
+		c.ReadReplicaURIs = append(c.ReadReplicaURIs, readReplicaURIs)
+	}
+}
+
+// SetReadReplicaURIs returns an option that can set ReadReplicaURIs on a Config
+func SetReadReplicaURIs(readReplicaURIs []string) ConfigOption {
+	return func(c *Config) {
+		c.ReadReplicaURIs = readReplicaURIs
+	}
+}
+
+// WithReadReplicaPollInterval returns an option that can set ReadReplicaPollInterval on a Config
+func WithReadReplicaPollInterval(readReplicaPollInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ReadReplicaPollInterval = readReplicaPollInterval
+	}
+}
+
 // WithSpannerCredentialsFile returns an option that can set SpannerCredentialsFile on a Config
 func WithSpannerCredentialsFile(spannerCredentialsFile string) ConfigOption {
 	return func(c *Config) {
@@ -268,6 +340,20 @@ func WithTablePrefix(tablePrefix string) ConfigOption {
 	}
 }
 
+// WithMemorySnapshotFile returns an option that can set MemorySnapshotFile on a Config
+func WithMemorySnapshotFile(memorySnapshotFile string) ConfigOption {
+	return func(c *Config) {
+		c.MemorySnapshotFile = memorySnapshotFile
+	}
+}
+
+// WithMemorySnapshotInterval returns an option that can set MemorySnapshotInterval on a Config
+func WithMemorySnapshotInterval(memorySnapshotInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MemorySnapshotInterval = memorySnapshotInterval
+	}
+}
+
 // WithWatchBufferLength returns an option that can set WatchBufferLength on a Config
 func WithWatchBufferLength(watchBufferLength uint16) ConfigOption {
 	return func(c *Config) {
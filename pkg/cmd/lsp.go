@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/jzelinskie/cobrautil/v2/cobrazerolog"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/lsp"
+	"github.com/authzed/spicedb/pkg/releases"
+)
+
+func RegisterLSPFlags(_ *cobra.Command) {
+}
+
+func NewLSPCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "runs a language server for schema files",
+		Long: "Runs a Language Server Protocol server for schema files over stdio, providing diagnostics, " +
+			"go-to-definition, hover, completion and formatting to editors. Logs are written to stderr.",
+		// Stdout carries the protocol, so the logger is configured to write to stderr and the
+		// release check of the other commands is skipped.
+		PreRunE: cobrautil.CommandStack(
+			cobrautil.SyncViperPreRunE(programName),
+			cobrazerolog.New(
+				cobrazerolog.WithTarget(func(logger zerolog.Logger) {
+					logging.SetGlobalLogger(logger)
+				}),
+			).RunE(),
+		),
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// The version is only reported to the client, so it is left empty if unknown.
+			version, _ := releases.CurrentVersion()
+			return lsp.NewServer(version).Run(cmd.Context(), os.Stdin, os.Stdout)
+		},
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/jzelinskie/cobrautil/v2/cobrazerolog"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/services/shared"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	experimentalv1 "github.com/authzed/spicedb/pkg/proto/experimental/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func RegisterSchemaRootFlags(_ *cobra.Command) {
+}
+
+func NewSchemaCommand(_ string) (*cobra.Command, error) {
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "schema operations",
+		Long:  "Operations on schema files and the schema stored in the configured datastore",
+	}
+
+	cfg := datastore.Config{}
+
+	diffCmd := NewSchemaDiffCommand(schemaCmd.Use, &cfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(diffCmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	schemaCmd.AddCommand(diffCmd)
+	schemaCmd.AddCommand(NewSchemaFmtCommand(schemaCmd.Use))
+
+	return schemaCmd, nil
+}
+
+func NewSchemaDiffCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <schema file>",
+		Short: "diffs a schema against the stored schema",
+		Long: "Diffs a schema file against the schema stored in the datastore, reporting each change and whether it " +
+			"would be rejected, and performs a dry run of writing the schema without committing it. Files imported " +
+			"by the schema are resolved relative to its directory.\n" +
+			"Exits with an error if the schema could not be written.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			schemaBytes, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read schema file: %w", err)
+			}
+
+			emptyDefaultPrefix := ""
+			compiled, err := compiler.Compile(compiler.InputSchema{
+				Source:       input.Source(args[0]),
+				SchemaString: string(schemaBytes),
+			}, &emptyDefaultPrefix, compiler.WithFileLoader(compiler.NewFSFileLoader(os.DirFS(filepath.Dir(args[0])))))
+			if err != nil {
+				return err
+			}
+
+			validated, err := shared.ValidateSchemaChanges(ctx, compiled, false)
+			if err != nil {
+				return err
+			}
+
+			// Disable background GC and hedging.
+			cfg.GCInterval = -1 * time.Hour
+			cfg.RequestHedgingEnabled = false
+
+			ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create datastore: %w", err)
+			}
+			defer func() {
+				if err := ds.Close(); err != nil {
+					log.Ctx(ctx).Warn().Err(err).Msg("failed to close datastore")
+				}
+			}()
+
+			diff, err := shared.DryRunSchemaChanges(ctx, ds, validated)
+			if err != nil {
+				return err
+			}
+
+			resp, err := v1svc.ToDiffSchemaResponse(diff)
+			if err != nil {
+				return err
+			}
+
+			printSchemaDiff(cmd.OutOrStdout(), resp)
+			if resp.ApplyError != "" {
+				return fmt.Errorf("schema cannot be written: %s", resp.ApplyError)
+			}
+			return nil
+		},
+	}
+}
+
+func NewSchemaFmtCommand(programName string) *cobra.Command {
+	var check bool
+	cmd := &cobra.Command{
+		Use:   "fmt <schema file>...",
+		Short: "formats schema files",
+		Long: "Formats schema files in place, normalizing their indentation and spacing while preserving their " +
+			"comments, the order of their definitions and the blank lines grouping them, printing the name of each " +
+			"file changed. Files imported by the schemas are not formatted unless also given.\n" +
+			"With --check, files are not changed; the name of each file which is not formatted is printed, and the " +
+			"command exits with an error if there are any.",
+		// Formatting is commonly run in CI, so the release check of the other commands is skipped.
+		PreRunE: cobrautil.CommandStack(
+			cobrautil.SyncViperPreRunE(programName),
+			cobrazerolog.New(
+				cobrazerolog.WithTarget(func(logger zerolog.Logger) {
+					log.SetGlobalLogger(logger)
+				}),
+			).RunE(),
+		),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			unformatted := 0
+			for _, path := range args {
+				schemaBytes, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read schema file: %w", err)
+				}
+
+				formatted, err := compiler.FormatSchema(compiler.InputSchema{
+					Source:       input.Source(path),
+					SchemaString: string(schemaBytes),
+				})
+				if err != nil {
+					return err
+				}
+
+				if formatted == string(schemaBytes) {
+					continue
+				}
+
+				unformatted++
+				fmt.Fprintln(cmd.OutOrStdout(), path)
+				if check {
+					continue
+				}
+
+				info, err := os.Stat(path)
+				if err != nil {
+					return fmt.Errorf("failed to read schema file: %w", err)
+				}
+
+				if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+					return fmt.Errorf("failed to write schema file: %w", err)
+				}
+			}
+
+			if check && unformatted > 0 {
+				return fmt.Errorf("%d schema file(s) are not formatted", unformatted)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "report files which are not formatted, and exit with an error if there are any, rather than formatting them")
+	return cmd
+}
+
+func printSchemaDiff(out io.Writer, resp *experimentalv1.DiffSchemaResponse) {
+	if len(resp.Deltas) == 0 {
+		fmt.Fprintln(out, "no changes")
+		return
+	}
+
+	for _, delta := range resp.Deltas {
+		kind := "definition"
+		if delta.DefinitionKind == experimentalv1.SchemaDelta_CAVEAT_DEFINITION {
+			kind = "caveat"
+		}
+
+		details := make([]string, 0, 3)
+		for _, detail := range []string{delta.RelationName, delta.AllowedType, delta.ParameterName} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		if delta.PreviousParameterType != "" || delta.CurrentParameterType != "" {
+			details = append(details, fmt.Sprintf("%s -> %s", delta.PreviousParameterType, delta.CurrentParameterType))
+		}
+
+		line := fmt.Sprintf("%s %s: %s", kind, delta.DefinitionName, delta.DeltaType)
+		if len(details) > 0 {
+			line += " " + strings.Join(details, " ")
+		}
+		if delta.RejectionReason != "" {
+			line += " (rejected: " + delta.RejectionReason + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+}
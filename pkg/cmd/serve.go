@@ -50,6 +50,8 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	util.RegisterGRPCServerFlags(cmd.Flags(), &config.GRPCServer, "grpc", "gRPC", ":50051", true)
 	cmd.Flags().StringSliceVar(&config.PresharedKey, PresharedKeyFlag, []string{}, "preshared key(s) to require for authenticated requests")
 	cmd.Flags().DurationVar(&config.ShutdownGracePeriod, "grpc-shutdown-grace-period", 0*time.Second, "amount of time after receiving sigint to continue serving")
+	cmd.Flags().StringSliceVar(&config.ZedTokenSigningKeys, "zedtoken-signing-key", []string{}, "key(s) with which to sign zedtokens, which are then only accepted if signed for this datastore by one of the keys. The first key signs new zedtokens, so a key can be rotated by adding its replacement before it")
+	cmd.Flags().BoolVar(&config.ZedTokenAcceptUnsigned, "zedtoken-accept-unsigned", false, "also accept unsigned zedtokens when --zedtoken-signing-key is set. To enable signing without rejecting the zedtokens already held by clients, first deploy with this flag, then remove it once unsigned zedtokens are no longer needed, such as after the datastore GC window has passed")
 	if err := cmd.MarkFlagRequired(PresharedKeyFlag); err != nil {
 		return fmt.Errorf("failed to mark flag as required: %w", err)
 	}
@@ -118,6 +120,12 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	cmd.Flags().Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls")
 	cmd.Flags().Uint16Var(&config.MaximumPreconditionCount, "update-relationships-max-preconditions-per-call", 1000, "maximum number of preconditions allowed for WriteRelationships and DeleteRelationships calls")
 	cmd.Flags().IntVar(&config.MaxCaveatContextSize, "max-caveat-context-size", 4096, "maximum allowed size of request caveat context in bytes. A value of zero or less means no limit")
+	cmd.Flags().Uint16Var(&config.MaxBulkCheckItems, "bulk-check-permission-max-items", 1000, "maximum number of items allowed for BulkCheckPermission calls")
+	cmd.Flags().Uint32Var(&config.MaxDeleteRelationshipsLimit, "delete-relationships-max-deletions-per-call", 1000, "maximum limit allowed for DeleteRelationships calls which specify a limit on the number of relationships to delete")
+	cmd.Flags().StringVar(&config.PreCommitHookEndpoint, "write-relationships-pre-commit-hook-endpoint", "", "address of a local sidecar serving the pre-commit hook gRPC service, which is called with the updates of every WriteRelationships call, and with each batch of BulkImportRelationships calls, before they are committed")
+	cmd.Flags().DurationVar(&config.PreCommitHookTimeout, "write-relationships-pre-commit-hook-timeout", 1*time.Second, "maximum duration of a call to the pre-commit hook sidecar")
+	cmd.Flags().StringSliceVar(&config.RelationshipQuotas, "write-relationships-quota", nil, "maximum number of relationships which WriteRelationships calls may store for a definition or relation, in the form definition=limit or definition#relation=limit (may be repeated)")
+	cmd.Flags().DurationVar(&config.WatchHeartbeat, "watch-api-heartbeat", 1*time.Second, "interval at which the watch API reports the revision through which changes have been processed, even if no changes matched. A value of zero disables heartbeats")
 
 	cmd.Flags().BoolVar(&config.V1SchemaAdditiveOnly, "testing-only-schema-additive-writes", false, "append new definitions to the existing schema, rather than overwriting it")
 	if err := cmd.Flags().MarkHidden("testing-only-schema-additive-writes"); err != nil {
@@ -127,6 +135,7 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	// Flags for misc services
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.DashboardAPI, "dashboard", "dashboard", ":8080", true)
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.MetricsAPI, "metrics", "metrics", ":9090", true)
+	cmd.Flags().DurationVar(&config.DatastoreStatisticsMetricsInterval, "metrics-datastore-statistics-interval", 0, "how often the per-definition datastore statistics reported as metrics are recomputed in the background, which may scan the relationships of small datastores (0 to disable)")
 
 	// Flags for telemetry
 	cmd.Flags().StringVar(&config.TelemetryEndpoint, "telemetry-endpoint", telemetry.DefaultEndpoint, "endpoint to which telemetry is reported, empty string to disable")
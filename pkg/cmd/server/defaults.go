@@ -32,6 +32,7 @@ import (
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
 	"github.com/authzed/spicedb/pkg/middleware/serverversion"
 	"github.com/authzed/spicedb/pkg/releases"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 var DisableTelemetryHandler *prometheus.Registry
@@ -122,7 +123,7 @@ const (
 )
 
 // DefaultMiddleware generates the default middleware chain used for the public SpiceDB gRPC API
-func DefaultMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, enableVersionResponse bool, dispatcher dispatch.Dispatcher, ds datastore.Datastore) (*MiddlewareChain, error) {
+func DefaultMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, enableVersionResponse bool, dispatcher dispatch.Dispatcher, ds datastore.Datastore, zedTokenSigner *zedtoken.Signer) (*MiddlewareChain, error) {
 	chain, err := NewMiddlewareChain([]ReferenceableMiddleware{
 		{
 			Name:                DefaultMiddlewareRequestID,
@@ -174,8 +175,8 @@ func DefaultMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, enable
 		{
 			Name:                DefaultInternalMiddlewareConsistency,
 			Internal:            true,
-			UnaryMiddleware:     consistencymw.UnaryServerInterceptor(),
-			StreamingMiddleware: consistencymw.StreamServerInterceptor(),
+			UnaryMiddleware:     consistencymw.UnaryServerInterceptor(zedTokenSigner),
+			StreamingMiddleware: consistencymw.StreamServerInterceptor(zedTokenSigner),
 		},
 		{
 			Name:                DefaultInternalMiddlewareServerSpecific,
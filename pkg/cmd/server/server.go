@@ -15,11 +15,14 @@ import (
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	grpcprom "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/authzed/spicedb/internal/auth"
 	"github.com/authzed/spicedb/internal/dashboard"
@@ -30,6 +33,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	"github.com/authzed/spicedb/internal/gateway"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/relationships"
 	"github.com/authzed/spicedb/internal/services"
 	dispatchSvc "github.com/authzed/spicedb/internal/services/dispatch"
 	"github.com/authzed/spicedb/internal/services/health"
@@ -39,6 +43,8 @@ import (
 	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/writehook"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 const (
@@ -60,6 +66,8 @@ type Config struct {
 	PresharedKey           []string
 	ShutdownGracePeriod    time.Duration
 	DisableVersionResponse bool
+	ZedTokenSigningKeys    []string
+	ZedTokenAcceptUnsigned bool
 
 	// GRPC Gateway config
 	HTTPGateway                    util.HTTPServerConfig
@@ -101,16 +109,30 @@ type Config struct {
 	ClusterDispatchCacheConfig CacheConfig
 
 	// API Behavior
-	DisableV1SchemaAPI       bool
-	V1SchemaAdditiveOnly     bool
-	MaximumUpdatesPerWrite   uint16
-	MaximumPreconditionCount uint16
-	MaxDatastoreReadPageSize uint64
+	DisableV1SchemaAPI          bool
+	V1SchemaAdditiveOnly        bool
+	MaximumUpdatesPerWrite      uint16
+	MaximumPreconditionCount    uint16
+	MaxDatastoreReadPageSize    uint64
+	MaxBulkCheckItems           uint16
+	MaxDeleteRelationshipsLimit uint32
+	WatchHeartbeat              time.Duration
+	RelationshipQuotas          []string
+
+	// Pre-commit hooks for relationship writes
+	PreCommitHooks        []writehook.PreCommitHook
+	PreCommitHookEndpoint string
+	PreCommitHookTimeout  time.Duration
 
 	// Additional Services
 	DashboardAPI util.HTTPServerConfig
 	MetricsAPI   util.HTTPServerConfig
 
+	// DatastoreStatisticsMetricsInterval is how often the detailed datastore statistics
+	// reported as metrics are recomputed in the background. Zero, the default, disables the
+	// metrics.
+	DatastoreStatisticsMetricsInterval time.Duration
+
 	// Middleware for grpc API
 	MiddlewareModification []MiddlewareModification
 
@@ -325,7 +347,25 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		watchServiceOption = services.WatchServiceDisabled
 	}
 
-	defaultMiddlewareChain, err := DefaultMiddleware(log.Logger, c.GRPCAuthFunc, !c.DisableVersionResponse, dispatcher, ds)
+	var zedTokenSigner *zedtoken.Signer
+	if len(c.ZedTokenSigningKeys) > 0 {
+		stats, err := ds.Statistics(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine datastore unique ID for signing zedtokens: %w", err)
+		}
+
+		zedTokenSigner, err = zedtoken.NewSigner(stats.UniqueID, c.ZedTokenSigningKeys...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure zedtoken signing: %w", err)
+		}
+		if c.ZedTokenAcceptUnsigned {
+			zedTokenSigner = zedTokenSigner.AcceptingUnsigned()
+			log.Ctx(ctx).Warn().Msg("accepting unsigned zedtokens; disable once the zedtokens issued before signing was enabled are no longer in use")
+		}
+		log.Ctx(ctx).Info().Int("keys-count", len(c.ZedTokenSigningKeys)).Msg("signing zedtokens")
+	}
+
+	defaultMiddlewareChain, err := DefaultMiddleware(log.Logger, c.GRPCAuthFunc, !c.DisableVersionResponse, dispatcher, ds, zedTokenSigner)
 	if err != nil {
 		return nil, fmt.Errorf("error building default middleware: %w", err)
 	}
@@ -335,12 +375,36 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		return nil, fmt.Errorf("error building Middlewares: %w", err)
 	}
 
+	preCommitHooks := c.PreCommitHooks
+	if c.PreCommitHookEndpoint != "" {
+		conn, err := grpc.DialContext(ctx, c.PreCommitHookEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to pre-commit hook endpoint: %w", err)
+		}
+		closeables.AddWithError(conn.Close)
+
+		preCommitHooks = append(slices.Clone(preCommitHooks), writehook.NewGRPCHook(c.PreCommitHookEndpoint, conn, c.PreCommitHookTimeout))
+	}
+
+	quotas := make([]relationships.Quota, 0, len(c.RelationshipQuotas))
+	for _, value := range c.RelationshipQuotas {
+		quota, err := relationships.ParseQuota(value)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, quota)
+	}
+
 	permSysConfig := v1svc.PermissionsServerConfig{
-		MaxPreconditionsCount:    c.MaximumPreconditionCount,
-		MaxUpdatesPerWrite:       c.MaximumUpdatesPerWrite,
-		MaximumAPIDepth:          c.DispatchMaxDepth,
-		MaxCaveatContextSize:     c.MaxCaveatContextSize,
-		MaxDatastoreReadPageSize: c.MaxDatastoreReadPageSize,
+		MaxPreconditionsCount:       c.MaximumPreconditionCount,
+		MaxUpdatesPerWrite:          c.MaximumUpdatesPerWrite,
+		MaximumAPIDepth:             c.DispatchMaxDepth,
+		MaxCaveatContextSize:        c.MaxCaveatContextSize,
+		MaxDatastoreReadPageSize:    c.MaxDatastoreReadPageSize,
+		MaxBulkCheckItems:           c.MaxBulkCheckItems,
+		MaxDeleteRelationshipsLimit: c.MaxDeleteRelationshipsLimit,
+		PreCommitHooks:              preCommitHooks,
+		RelationshipQuotas:          quotas,
 	}
 
 	healthManager := health.NewHealthManager(dispatcher, ds)
@@ -353,6 +417,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 				v1SchemaServiceOption,
 				watchServiceOption,
 				permSysConfig,
+				c.WatchHeartbeat,
 			)
 		},
 	)
@@ -399,6 +464,15 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 	}
 
+	if c.DatastoreStatisticsMetricsInterval > 0 && !c.DatastoreConfig.DisableStats {
+		stopCollector, err := telemetry.RegisterDatastoreStatisticsCollector(prometheus.DefaultRegisterer, ds, c.DatastoreStatisticsMetricsInterval)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("unable to initialize datastore statistics collector")
+		} else {
+			closeables.AddWithoutError(stopCollector)
+		}
+	}
+
 	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, MetricsHandler(registry))
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize metrics server: %w", err)
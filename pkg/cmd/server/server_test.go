@@ -109,7 +109,7 @@ func TestModifyMiddleware(t *testing.T) {
 		},
 	}}
 
-	defaultMw, err := DefaultMiddleware(logging.Logger, nil, false, nil, nil)
+	defaultMw, err := DefaultMiddleware(logging.Logger, nil, false, nil, nil, nil)
 	require.NoError(t, err)
 
 	unary, streaming, err := c.buildMiddleware(defaultMw)
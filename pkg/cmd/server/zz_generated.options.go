@@ -7,6 +7,7 @@ import (
 	datastore "github.com/authzed/spicedb/pkg/cmd/datastore"
 	util "github.com/authzed/spicedb/pkg/cmd/util"
 	datastore1 "github.com/authzed/spicedb/pkg/datastore"
+	writehook "github.com/authzed/spicedb/pkg/writehook"
 	auth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	grpc "google.golang.org/grpc"
 	"time"
@@ -31,6 +32,8 @@ func (c *Config) ToOption() ConfigOption {
 		to.PresharedKey = c.PresharedKey
 		to.ShutdownGracePeriod = c.ShutdownGracePeriod
 		to.DisableVersionResponse = c.DisableVersionResponse
+		to.ZedTokenSigningKeys = c.ZedTokenSigningKeys
+		to.ZedTokenAcceptUnsigned = c.ZedTokenAcceptUnsigned
 		to.HTTPGateway = c.HTTPGateway
 		to.HTTPGatewayUpstreamAddr = c.HTTPGatewayUpstreamAddr
 		to.HTTPGatewayUpstreamTLSCertPath = c.HTTPGatewayUpstreamTLSCertPath
@@ -62,8 +65,16 @@ func (c *Config) ToOption() ConfigOption {
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxDatastoreReadPageSize = c.MaxDatastoreReadPageSize
+		to.MaxBulkCheckItems = c.MaxBulkCheckItems
+		to.MaxDeleteRelationshipsLimit = c.MaxDeleteRelationshipsLimit
+		to.WatchHeartbeat = c.WatchHeartbeat
+		to.RelationshipQuotas = c.RelationshipQuotas
+		to.PreCommitHooks = c.PreCommitHooks
+		to.PreCommitHookEndpoint = c.PreCommitHookEndpoint
+		to.PreCommitHookTimeout = c.PreCommitHookTimeout
 		to.DashboardAPI = c.DashboardAPI
 		to.MetricsAPI = c.MetricsAPI
+		to.DatastoreStatisticsMetricsInterval = c.DatastoreStatisticsMetricsInterval
 		to.MiddlewareModification = c.MiddlewareModification
 		to.DispatchUnaryMiddleware = c.DispatchUnaryMiddleware
 		to.DispatchStreamingMiddleware = c.DispatchStreamingMiddleware
@@ -124,6 +135,27 @@ func WithDisableVersionResponse(disableVersionResponse bool) ConfigOption {
 	}
 }
 
+// WithZedTokenSigningKeys returns an option that can append ZedTokenSigningKeyss to Config.ZedTokenSigningKeys
+func WithZedTokenSigningKeys(zedTokenSigningKeys string) ConfigOption {
+	return func(c *Config) {
+		c.ZedTokenSigningKeys = append(c.ZedTokenSigningKeys, zedTokenSigningKeys)
+	}
+}
+
+// SetZedTokenSigningKeys returns an option that can set ZedTokenSigningKeys on a Config
+func SetZedTokenSigningKeys(zedTokenSigningKeys []string) ConfigOption {
+	return func(c *Config) {
+		c.ZedTokenSigningKeys = zedTokenSigningKeys
+	}
+}
+
+// WithZedTokenAcceptUnsigned returns an option that can set ZedTokenAcceptUnsigned on a Config
+func WithZedTokenAcceptUnsigned(zedTokenAcceptUnsigned bool) ConfigOption {
+	return func(c *Config) {
+		c.ZedTokenAcceptUnsigned = zedTokenAcceptUnsigned
+	}
+}
+
 // WithHTTPGateway returns an option that can set HTTPGateway on a Config
 func WithHTTPGateway(hTTPGateway util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -348,6 +380,69 @@ func WithMaxDatastoreReadPageSize(maxDatastoreReadPageSize uint64) ConfigOption
 	}
 }
 
+// WithMaxBulkCheckItems returns an option that can set MaxBulkCheckItems on a Config
+func WithMaxBulkCheckItems(maxBulkCheckItems uint16) ConfigOption {
+	return func(c *Config) {
+		c.MaxBulkCheckItems = maxBulkCheckItems
+	}
+}
+
+// WithMaxDeleteRelationshipsLimit returns an option that can set MaxDeleteRelationshipsLimit on a Config
+func WithMaxDeleteRelationshipsLimit(maxDeleteRelationshipsLimit uint32) ConfigOption {
+	return func(c *Config) {
+		c.MaxDeleteRelationshipsLimit = maxDeleteRelationshipsLimit
+	}
+}
+
+// WithWatchHeartbeat returns an option that can set WatchHeartbeat on a Config
+func WithWatchHeartbeat(watchHeartbeat time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.WatchHeartbeat = watchHeartbeat
+	}
+}
+
+// WithRelationshipQuotas returns an option that can append RelationshipQuotass to Config.RelationshipQuotas
+func WithRelationshipQuotas(relationshipQuotas string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipQuotas = append(c.RelationshipQuotas, relationshipQuotas)
+	}
+}
+
+// SetRelationshipQuotas returns an option that can set RelationshipQuotas on a Config
+func SetRelationshipQuotas(relationshipQuotas []string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipQuotas = relationshipQuotas
+	}
+}
+
+// WithPreCommitHooks returns an option that can append PreCommitHookss to Config.PreCommitHooks
+func WithPreCommitHooks(preCommitHooks writehook.PreCommitHook) ConfigOption {
+	return func(c *Config) {
+		c.PreCommitHooks = append(c.PreCommitHooks, preCommitHooks)
+	}
+}
+
+// SetPreCommitHooks returns an option that can set PreCommitHooks on a Config
+func SetPreCommitHooks(preCommitHooks []writehook.PreCommitHook) ConfigOption {
+	return func(c *Config) {
+		c.PreCommitHooks = preCommitHooks
+	}
+}
+
+// WithPreCommitHookEndpoint returns an option that can set PreCommitHookEndpoint on a Config
+func WithPreCommitHookEndpoint(preCommitHookEndpoint string) ConfigOption {
+	return func(c *Config) {
+		c.PreCommitHookEndpoint = preCommitHookEndpoint
+	}
+}
+
+// WithPreCommitHookTimeout returns an option that can set PreCommitHookTimeout on a Config
+func WithPreCommitHookTimeout(preCommitHookTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.PreCommitHookTimeout = preCommitHookTimeout
+	}
+}
+
 // WithDashboardAPI returns an option that can set DashboardAPI on a Config
 func WithDashboardAPI(dashboardAPI util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -362,6 +457,13 @@ func WithMetricsAPI(metricsAPI util.HTTPServerConfig) ConfigOption {
 	}
 }
 
+// WithDatastoreStatisticsMetricsInterval returns an option that can set DatastoreStatisticsMetricsInterval on a Config
+func WithDatastoreStatisticsMetricsInterval(datastoreStatisticsMetricsInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DatastoreStatisticsMetricsInterval = datastoreStatisticsMetricsInterval
+	}
+}
+
 // WithMiddlewareModification returns an option that can append MiddlewareModifications to Config.MiddlewareModification
 func WithMiddlewareModification(middlewareModification MiddlewareModification) ConfigOption {
 	return func(c *Config) {
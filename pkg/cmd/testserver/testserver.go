@@ -32,6 +32,7 @@ type Config struct {
 	HTTPGateway              util.HTTPServerConfig
 	ReadOnlyHTTPGateway      util.HTTPServerConfig
 	LoadConfigs              []string
+	LoadSnapshot             string
 	MaximumUpdatesPerWrite   uint16
 	MaximumPreconditionCount uint16
 	MaxCaveatContextSize     int
@@ -52,7 +53,7 @@ func (dr datastoreReady) ReadyState(_ context.Context) (datastore.ReadyState, er
 func (c *Config) Complete() (RunnableTestServer, error) {
 	dispatcher := graph.NewLocalOnlyDispatcher(10)
 
-	datastoreMiddleware := pertoken.NewMiddleware(c.LoadConfigs)
+	datastoreMiddleware := pertoken.NewMiddleware(c.LoadConfigs, c.LoadSnapshot)
 
 	healthManager := health.NewHealthManager(dispatcher, &datastoreReady{})
 
@@ -69,19 +70,20 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 				MaximumAPIDepth:       maxDepth,
 				MaxCaveatContextSize:  c.MaxCaveatContextSize,
 			},
+			0,
 		)
 	}
 	gRPCSrv, err := c.GRPCServer.Complete(zerolog.InfoLevel, registerServices,
 		grpc.ChainUnaryInterceptor(
 			datastoreMiddleware.UnaryServerInterceptor(),
 			dispatchmw.UnaryServerInterceptor(dispatcher),
-			consistencymw.UnaryServerInterceptor(),
+			consistencymw.UnaryServerInterceptor(nil),
 			servicespecific.UnaryServerInterceptor,
 		),
 		grpc.ChainStreamInterceptor(
 			datastoreMiddleware.StreamServerInterceptor(),
 			dispatchmw.StreamServerInterceptor(dispatcher),
-			consistencymw.StreamServerInterceptor(),
+			consistencymw.StreamServerInterceptor(nil),
 			servicespecific.StreamServerInterceptor,
 		),
 	)
@@ -94,14 +96,14 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 			datastoreMiddleware.UnaryServerInterceptor(),
 			readonly.UnaryServerInterceptor(),
 			dispatchmw.UnaryServerInterceptor(dispatcher),
-			consistencymw.UnaryServerInterceptor(),
+			consistencymw.UnaryServerInterceptor(nil),
 			servicespecific.UnaryServerInterceptor,
 		),
 		grpc.ChainStreamInterceptor(
 			datastoreMiddleware.StreamServerInterceptor(),
 			readonly.StreamServerInterceptor(),
 			dispatchmw.StreamServerInterceptor(dispatcher),
-			consistencymw.StreamServerInterceptor(),
+			consistencymw.StreamServerInterceptor(nil),
 			servicespecific.StreamServerInterceptor,
 		),
 	)
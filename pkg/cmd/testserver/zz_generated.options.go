@@ -22,6 +22,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.HTTPGateway = c.HTTPGateway
 		to.ReadOnlyHTTPGateway = c.ReadOnlyHTTPGateway
 		to.LoadConfigs = c.LoadConfigs
+		to.LoadSnapshot = c.LoadSnapshot
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
@@ -78,6 +79,13 @@ func SetLoadConfigs(loadConfigs []string) ConfigOption {
 	}
 }
 
+// WithLoadSnapshot returns an option that can set LoadSnapshot on a Config
+func WithLoadSnapshot(loadSnapshot string) ConfigOption {
+	return func(c *Config) {
+		c.LoadSnapshot = loadSnapshot
+	}
+}
+
 // WithMaximumUpdatesPerWrite returns an option that can set MaximumUpdatesPerWrite on a Config
 func WithMaximumUpdatesPerWrite(maximumUpdatesPerWrite uint16) ConfigOption {
 	return func(c *Config) {
@@ -0,0 +1,153 @@
+// Package cursor converts dispatch cursors to the opaque cursors returned by the API and
+// vice versa.
+package cursor
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	impl "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// Public facing errors
+const (
+	errEncodeError = "error encoding cursor: %w"
+	errDecodeError = "error decoding cursor: %w"
+)
+
+// ErrNilCursor is returned as the base error when nil is provided as the
+// cursor argument to Decode
+var ErrNilCursor = errors.New("cursor pointer was nil")
+
+// ErrHashMismatch is returned as the base error when a mismatching hash was given to the decoder.
+var ErrHashMismatch = errors.New("the cursor provided does not have the same arguments as the original API call; please ensure you are making the same API call, with the exact same parameters (besides the cursor)")
+
+// InvalidCursorError occurs when a cursor could not be decoded.
+type InvalidCursorError struct {
+	error
+}
+
+// Unwrap returns the underlying error.
+func (err InvalidCursorError) Unwrap() error {
+	return err.error
+}
+
+// NewInvalidCursorErr constructs a new invalid cursor error.
+func NewInvalidCursorErr(err error) error {
+	return InvalidCursorError{err}
+}
+
+// Encode converts a decoded cursor to its opaque version.
+func Encode(decoded *impl.DecodedCursor) (*v1.Cursor, error) {
+	marshalled, err := decoded.MarshalVT()
+	if err != nil {
+		return nil, NewInvalidCursorErr(fmt.Errorf(errEncodeError, err))
+	}
+
+	return &v1.Cursor{
+		Token: base64.StdEncoding.EncodeToString(marshalled),
+	}, nil
+}
+
+// Decode converts an encoded cursor to its decoded version.
+func Decode(encoded *v1.Cursor) (*impl.DecodedCursor, error) {
+	if encoded == nil {
+		return nil, NewInvalidCursorErr(fmt.Errorf(errDecodeError, ErrNilCursor))
+	}
+
+	decodedBytes, err := base64.StdEncoding.DecodeString(encoded.Token)
+	if err != nil {
+		return nil, NewInvalidCursorErr(fmt.Errorf(errDecodeError, err))
+	}
+
+	decoded := &impl.DecodedCursor{}
+	if err := decoded.UnmarshalVT(decodedBytes); err != nil {
+		return nil, NewInvalidCursorErr(fmt.Errorf(errDecodeError, err))
+	}
+
+	return decoded, nil
+}
+
+// EncodeFromDispatchCursor encodes an internal dispatching cursor into a cursor for external
+// consumption, including the given call and parameters hash and the revision at which the
+// call was made. The revision is signed as for zedtokens if the context has a signer.
+func EncodeFromDispatchCursor(ctx context.Context, dispatchCursor *dispatch.Cursor, callAndParameterHash string, revision datastore.Revision) (*v1.Cursor, error) {
+	if dispatchCursor == nil {
+		return nil, NewInvalidCursorErr(fmt.Errorf(errEncodeError, ErrNilCursor))
+	}
+
+	v1Cursor := &impl.V1Cursor{
+		Revision:              revision.String(),
+		Sections:              dispatchCursor.Sections,
+		CallAndParametersHash: callAndParameterHash,
+		DispatchVersion:       dispatchCursor.DispatchVersion,
+	}
+	v1Cursor.DatastoreUniqueId, v1Cursor.Signature = zedtoken.SignRevision(ctx, v1Cursor.Revision)
+
+	return Encode(&impl.DecodedCursor{
+		VersionOneof: &impl.DecodedCursor_V1{
+			V1: v1Cursor,
+		},
+	})
+}
+
+// DecodeToDispatchCursor decodes an encoded API cursor into an internal dispatching cursor,
+// returning an error if the provided call and parameters hash does not match that found in
+// the cursor.
+func DecodeToDispatchCursor(encoded *v1.Cursor, callAndParameterHash string) (*dispatch.Cursor, error) {
+	decoded, err := Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	v1decoded := decoded.GetV1()
+	if v1decoded == nil {
+		return nil, NewInvalidCursorErr(fmt.Errorf(errDecodeError, fmt.Errorf("unknown cursor version: %T", decoded.VersionOneof)))
+	}
+
+	if v1decoded.CallAndParametersHash != callAndParameterHash {
+		return nil, NewInvalidCursorErr(ErrHashMismatch)
+	}
+
+	return &dispatch.Cursor{
+		Sections:        v1decoded.Sections,
+		DispatchVersion: v1decoded.DispatchVersion,
+	}, nil
+}
+
+// DecodeToDispatchRevision decodes an encoded API cursor into the revision at which the
+// original call was made. If the context has a signer, the revision must have been signed for
+// the same datastore, as for zedtokens.
+func DecodeToDispatchRevision(ctx context.Context, encoded *v1.Cursor, ds revisionDecoder) (datastore.Revision, error) {
+	decoded, err := Decode(encoded)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	v1decoded := decoded.GetV1()
+	if v1decoded == nil {
+		return datastore.NoRevision, NewInvalidCursorErr(fmt.Errorf(errDecodeError, fmt.Errorf("unknown cursor version: %T", decoded.VersionOneof)))
+	}
+
+	if err := zedtoken.VerifyRevision(ctx, v1decoded.Revision, v1decoded.DatastoreUniqueId, v1decoded.Signature); err != nil {
+		return datastore.NoRevision, NewInvalidCursorErr(fmt.Errorf(errDecodeError, err))
+	}
+
+	parsed, err := ds.RevisionFromString(v1decoded.Revision)
+	if err != nil {
+		return datastore.NoRevision, NewInvalidCursorErr(fmt.Errorf(errDecodeError, err))
+	}
+
+	return parsed, nil
+}
+
+type revisionDecoder interface {
+	RevisionFromString(string) (datastore.Revision, error)
+}
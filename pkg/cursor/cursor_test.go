@@ -0,0 +1,119 @@
+package cursor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revision"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+var encodeRevisionTests = []datastore.Revision{
+	revision.NewFromDecimal(decimal.Zero),
+	revision.NewFromDecimal(decimal.NewFromInt(1)),
+	revision.NewFromDecimal(decimal.NewFromInt(1621538189028928000)),
+	revision.NewFromDecimal(decimal.New(12345, -2)),
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, rev := range encodeRevisionTests {
+		rev := rev
+		t.Run(rev.String(), func(t *testing.T) {
+			require := require.New(t)
+			encoded, err := EncodeFromDispatchCursor(context.Background(), &dispatch.Cursor{
+				Sections:        []string{"1", "2", "document:foo#viewer@user:tom"},
+				DispatchVersion: 1,
+			}, "somehash", rev)
+			require.NoError(err)
+			require.NotNil(encoded)
+
+			decoded, err := DecodeToDispatchCursor(encoded, "somehash")
+			require.NoError(err)
+			require.Equal([]string{"1", "2", "document:foo#viewer@user:tom"}, decoded.Sections)
+			require.Equal(uint32(1), decoded.DispatchVersion)
+
+			decodedRev, err := DecodeToDispatchRevision(context.Background(), encoded, revision.DecimalDecoder{})
+			require.NoError(err)
+			require.True(rev.Equal(decodedRev))
+
+			_, err = DecodeToDispatchCursor(encoded, "anotherhash")
+			require.ErrorIs(err, ErrHashMismatch)
+			require.ErrorAs(err, &InvalidCursorError{})
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cursor *v1.Cursor
+	}{
+		{"nil", nil},
+		{"invalid base64", &v1.Cursor{Token: "%%%"}},
+		{"invalid proto", &v1.Cursor{Token: "abc"}},
+		{"missing version", &v1.Cursor{Token: ""}},
+	} {
+		tc := tc
+		t.Run(fmt.Sprintf("decode %s", tc.name), func(t *testing.T) {
+			_, err := DecodeToDispatchCursor(tc.cursor, "")
+			require.Error(t, err)
+			require.ErrorAs(t, err, &InvalidCursorError{})
+		})
+	}
+}
+
+func TestSignedRevision(t *testing.T) {
+	require := require.New(t)
+
+	signer, err := zedtoken.NewSigner("somedatastore", "somekey")
+	require.NoError(err)
+	ctx := zedtoken.ContextWithSigner(context.Background(), signer)
+
+	rev := revision.NewFromDecimal(decimal.NewFromInt(1621538189028928000))
+	dispatchCursor := &dispatch.Cursor{Sections: []string{"1"}, DispatchVersion: 1}
+
+	encoded, err := EncodeFromDispatchCursor(ctx, dispatchCursor, "somehash", rev)
+	require.NoError(err)
+
+	decodedRev, err := DecodeToDispatchRevision(ctx, encoded, revision.DecimalDecoder{})
+	require.NoError(err)
+	require.True(rev.Equal(decodedRev))
+
+	// A cursor whose revision has been changed is rejected.
+	decoded, err := Decode(encoded)
+	require.NoError(err)
+	decoded.GetV1().Revision = "1"
+	tampered, err := Encode(decoded)
+	require.NoError(err)
+
+	_, err = DecodeToDispatchRevision(ctx, tampered, revision.DecimalDecoder{})
+	require.ErrorIs(err, zedtoken.ErrInvalidSignature)
+	require.ErrorAs(err, &InvalidCursorError{})
+
+	// A cursor issued for another datastore is rejected.
+	otherSigner, err := zedtoken.NewSigner("otherdatastore", "somekey")
+	require.NoError(err)
+	other, err := EncodeFromDispatchCursor(zedtoken.ContextWithSigner(context.Background(), otherSigner), dispatchCursor, "somehash", rev)
+	require.NoError(err)
+
+	_, err = DecodeToDispatchRevision(ctx, other, revision.DecimalDecoder{})
+	require.ErrorIs(err, zedtoken.ErrDifferentDatastore)
+	require.ErrorAs(err, &InvalidCursorError{})
+
+	// Unsigned cursors are only accepted while migrating to signing.
+	unsigned, err := EncodeFromDispatchCursor(context.Background(), dispatchCursor, "somehash", rev)
+	require.NoError(err)
+
+	_, err = DecodeToDispatchRevision(ctx, unsigned, revision.DecimalDecoder{})
+	require.ErrorIs(err, zedtoken.ErrInvalidSignature)
+
+	_, err = DecodeToDispatchRevision(zedtoken.ContextWithSigner(context.Background(), signer.AcceptingUnsigned()), unsigned, revision.DecimalDecoder{})
+	require.NoError(err)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/authzed/spicedb/pkg/tuple"
 
@@ -47,6 +48,61 @@ const FilterMaximumIDCount uint16 = 100
 type RevisionChanges struct {
 	Revision Revision
 	Changes  []*core.RelationTupleUpdate
+
+	// ChangedDefinitions are the namespace and caveat definitions written in the transaction.
+	// Only populated when the watch was started with WatchSchema content.
+	ChangedDefinitions []SchemaDefinition
+
+	// DeletedNamespaces are the names of the namespaces deleted in the transaction. Only
+	// populated when the watch was started with WatchSchema content.
+	DeletedNamespaces []string
+
+	// DeletedCaveats are the names of the caveats deleted in the transaction. Only populated
+	// when the watch was started with WatchSchema content.
+	DeletedCaveats []string
+
+	// IsCheckpoint, if true, indicates that this carries no changes and that all changes
+	// up to and including Revision have already been reported by the watch. Only sent when
+	// the watch was started with a CheckpointInterval.
+	IsCheckpoint bool
+}
+
+// HasSchemaChanges returns true if any namespace or caveat definition was written or deleted
+// in the transaction.
+func (rc RevisionChanges) HasSchemaChanges() bool {
+	return len(rc.ChangedDefinitions) > 0 || len(rc.DeletedNamespaces) > 0 || len(rc.DeletedCaveats) > 0
+}
+
+// WatchContent is a bitmask of the kinds of changes a watch reports.
+type WatchContent int
+
+const (
+	// WatchRelationships reports changes to relationships.
+	WatchRelationships WatchContent = 1 << 0
+
+	// WatchSchema reports namespace and caveat definitions written or deleted.
+	WatchSchema WatchContent = 1 << 1
+)
+
+// WatchOptions are the options for a watch.
+type WatchOptions struct {
+	// Content is the kinds of changes to report.
+	Content WatchContent
+
+	// CheckpointInterval, if non-zero, is the interval at which the watch reports a
+	// checkpoint with the latest revision through which all changes have been reported,
+	// whether or not any changes occurred since the previous checkpoint.
+	CheckpointInterval time.Duration
+}
+
+// WatchJustRelationships returns the options for a watch reporting only relationship changes.
+func WatchJustRelationships() WatchOptions {
+	return WatchOptions{Content: WatchRelationships}
+}
+
+// Includes returns true if the options request the given kind of changes.
+func (wo WatchOptions) Includes(content WatchContent) bool {
+	return wo.Content&content == content
 }
 
 // RelationshipsFilter is a filter for relationships.
@@ -249,14 +305,45 @@ type ReadWriteTransaction interface {
 	// WriteRelationships takes a list of tuple mutations and applies them to the datastore.
 	WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error
 
-	// DeleteRelationships deletes all Relationships that match the provided filter.
-	DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter) error
+	// DeleteRelationships deletes the Relationships that match the provided filter, up to the
+	// limit if one is given. Returns true if the limit was reached, in which case more
+	// Relationships matching the filter may remain.
+	DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error)
 
 	// WriteNamespaces takes proto namespace definitions and persists them.
 	WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error
 
 	// DeleteNamespaces deletes namespaces including associated relationships.
 	DeleteNamespaces(ctx context.Context, nsNames ...string) error
+
+	// BulkLoad takes a relationship source iterator, and writes all of the
+	// relationships to the backing datastore in an optimized fashion. This
+	// method can and will omit checks and otherwise cut corners in the
+	// interest of performance, and should not be relied upon for OLTP-style
+	// workloads. Relationships are written with CREATE semantics. Returns
+	// the number of relationships loaded.
+	BulkLoad(ctx context.Context, iter BulkWriteRelationshipSource) (uint64, error)
+
+	// RelationshipCounts returns the number of relationships stored for each relation of the
+	// given definitions which has any, including the changes made earlier in the transaction.
+	// The counts include expired relationships which have not yet been removed.
+	//
+	// The counts of a definition are only maintained by the datastore once they have been
+	// read: the first read counts the existing relationships of the definition, after which
+	// the counts are updated as relationships are written and deleted, rather than by
+	// scanning the relationships.
+	RelationshipCounts(ctx context.Context, definitions ...string) (map[RelationKey]uint64, error)
+}
+
+// BulkWriteRelationshipSource is an interface for transferring relationships
+// to a backing datastore with a zero-copy methodology.
+type BulkWriteRelationshipSource interface {
+	// Next returns a pointer to a relation tuple if one is available, or nil if
+	// there are no more or there was an error.
+	//
+	// Note: sources may re-use the same memory address for every tuple, data
+	// may change on every call to next even if the pointer has not changed.
+	Next(ctx context.Context) (*core.RelationTuple, error)
 }
 
 // TxUserFunc is a type for the function that users supply when they invoke a read-write transaction.
@@ -297,10 +384,11 @@ type Datastore interface {
 	// used by the specific datastore implementation.
 	RevisionFromString(serialized string) (Revision, error)
 
-	// Watch notifies the caller about all changes to tuples.
+	// Watch notifies the caller about all changes to tuples and, if requested in the
+	// options, to the schema.
 	//
 	// All events following afterRevision will be sent to the caller.
-	Watch(ctx context.Context, afterRevision Revision) (<-chan *RevisionChanges, <-chan error)
+	Watch(ctx context.Context, afterRevision Revision, options WatchOptions) (<-chan *RevisionChanges, <-chan error)
 
 	// ReadyState returns a state indicating whether the datastore is ready to accept data.
 	// Datastores that require database schema creation will return not-ready until the migrations
@@ -314,6 +402,11 @@ type Datastore interface {
 	// Statistics returns relevant values about the data contained in this cluster.
 	Statistics(ctx context.Context) (Stats, error)
 
+	// DetailedStatistics returns the number of relationships stored for each definition and
+	// relation, along with the storage used by the datastore. Computing it may be considerably
+	// more expensive than computing Statistics.
+	DetailedStatistics(ctx context.Context) (DetailedStats, error)
+
 	// Close closes the data store.
 	Close() error
 }
@@ -362,6 +455,50 @@ type Stats struct {
 	ObjectTypeStatistics []ObjectTypeStat
 }
 
+// RelationStats represents statistics for the relationships of a single relation.
+type RelationStats struct {
+	// Relation is the name of the relation.
+	Relation string
+
+	// RelationshipCount is the number of relationships stored for the relation.
+	RelationshipCount uint64
+}
+
+// DefinitionStats represents statistics for the relationships of a single definition.
+type DefinitionStats struct {
+	// Definition is the name of the definition.
+	Definition string
+
+	// RelationshipCount is the number of relationships stored for the definition.
+	RelationshipCount uint64
+
+	// Relations holds the statistics of each relation of the definition with stored
+	// relationships, sorted by relation name. It is empty if the relationship counts were
+	// estimated for the definition as a whole.
+	Relations []RelationStats
+}
+
+// DetailedStats represents statistics for the relationships of each definition stored in
+// the datastore, and for the storage used by the datastore.
+type DetailedStats struct {
+	// CountsAreEstimates is true if the relationship counts were estimated, such as from a
+	// sample of the stored relationships, rather than counted exactly.
+	CountsAreEstimates bool
+
+	// Definitions holds the statistics of each definition with stored relationships, sorted
+	// by definition name.
+	Definitions []DefinitionStats
+
+	// StorageSizeBytes is the storage used by relationships, including their indexes, or zero
+	// if the datastore cannot report it.
+	StorageSizeBytes uint64
+
+	// ChangelogBacklog is the number of entries in the datastore's changelog which have not
+	// yet been garbage collected, or zero if the datastore does not keep a changelog or cannot
+	// cheaply report its size.
+	ChangelogBacklog uint64
+}
+
 // RelationshipIterator is an iterator over matched tuples.
 type RelationshipIterator interface {
 	// Next returns the next tuple in the result set.
@@ -4,7 +4,7 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
-//go:generate go run github.com/ecordell/optgen -output zz_generated.query_options.go . QueryOptions ReverseQueryOptions
+//go:generate go run github.com/ecordell/optgen -output zz_generated.query_options.go . QueryOptions ReverseQueryOptions DeleteOptions
 
 // SortOrder is an enum which represents the order in which the caller would like
 // the data returned.
@@ -36,6 +36,15 @@ type QueryOptions struct {
 type ReverseQueryOptions struct {
 	ReverseLimit *uint64
 	ResRelation  *ResourceRelation
+
+	SortForReverse  SortOrder
+	AfterForReverse Cursor
+}
+
+// DeleteOptions are the options that can affect the results of a delete relationships
+// operation.
+type DeleteOptions struct {
+	DeleteLimit *uint64
 }
 
 // ResourceRelation combines a resource object type and relation.
@@ -31,7 +31,7 @@ func TestDownstreamErrors(t *testing.T) {
 			On("QueryRelationships", options.Cursor(nil), defaultSortOrder, defaultPageSize).
 			Return(nilIter, defaultError)
 
-		_, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{}, defaultPageSize, defaultSortOrder)
+		_, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{}, defaultPageSize, defaultSortOrder, nil)
 		require.ErrorIs(err, defaultError)
 		require.True(ds.AssertExpectations(t))
 	})
@@ -49,7 +49,7 @@ func TestDownstreamErrors(t *testing.T) {
 			On("QueryRelationships", options.Cursor(nil), defaultSortOrder, defaultPageSize).
 			Return(iterMock, nil)
 
-		iter, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{}, defaultPageSize, defaultSortOrder)
+		iter, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{}, defaultPageSize, defaultSortOrder, nil)
 		require.NoError(err)
 		require.NotNil(iter)
 
@@ -77,7 +77,7 @@ func TestDownstreamErrors(t *testing.T) {
 			On("QueryRelationships", options.Cursor(nil), defaultSortOrder, defaultPageSize).
 			Return(iterMock, nil)
 
-		iter, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{}, defaultPageSize, defaultSortOrder)
+		iter, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{}, defaultPageSize, defaultSortOrder, nil)
 		require.NoError(err)
 		require.NotNil(iter)
 
@@ -137,7 +137,7 @@ func TestPaginatedIterator(t *testing.T) {
 			ctx := context.Background()
 			iter, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{
 				ResourceType: "unused",
-			}, tc.pageSize, options.ByResource)
+			}, tc.pageSize, options.ByResource, nil)
 			require.NoError(err)
 			defer iter.Close()
 
@@ -169,6 +169,46 @@ func TestPaginatedIterator(t *testing.T) {
 	}
 }
 
+func TestPaginatedIteratorStartCursor(t *testing.T) {
+	require := require.New(t)
+
+	tpls := make([]*core.RelationTuple, 0, 10)
+	for i := 0; i < 10; i++ {
+		tpls = append(tpls, &core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{
+				Namespace: "document",
+				ObjectId:  strconv.Itoa(i),
+				Relation:  "owner",
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: "user",
+				ObjectId:  strconv.Itoa(i),
+				Relation:  datastore.Ellipsis,
+			},
+		})
+	}
+
+	pageSize := uint64(100)
+	ds := &mockedReader{}
+	ds.
+		On("QueryRelationships", options.Cursor(tpls[3]), options.ByResource, pageSize).
+		Return(common.NewSliceRelationshipIterator(tpls[4:], options.ByResource), nil)
+
+	iter, err := NewPaginatedIterator(context.Background(), ds, datastore.RelationshipsFilter{
+		ResourceType: "unused",
+	}, pageSize, options.ByResource, tpls[3])
+	require.NoError(err)
+	defer iter.Close()
+
+	var found []*core.RelationTuple
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found = append(found, tpl)
+	}
+	require.NoError(iter.Err())
+	require.Equal(tpls[4:], found)
+	require.True(ds.AssertExpectations(t))
+}
+
 func generateMock(tpls []*core.RelationTuple, pageSize uint64, order options.SortOrder) *mockedReader {
 	mock := &mockedReader{}
 	tplsLen := uint64(len(tpls))
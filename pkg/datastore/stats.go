@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"sort"
+
 	"github.com/authzed/spicedb/pkg/namespace"
 	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
 )
@@ -29,3 +31,45 @@ func ComputeObjectTypeStats(objTypes []RevisionedNamespace) []ObjectTypeStat {
 
 	return stats
 }
+
+// RelationKey identifies a relation of a definition for which relationships are counted. A key
+// without a relation counts relationships of the definition for which the relation is not known.
+type RelationKey struct {
+	Definition string
+	Relation   string
+}
+
+// ComputeDefinitionStats creates a sorted list of definition stats from the number of
+// relationships counted for each relation.
+func ComputeDefinitionStats(counts map[RelationKey]uint64) []DefinitionStats {
+	byDefinition := make(map[string]*DefinitionStats)
+	for key, count := range counts {
+		def, ok := byDefinition[key.Definition]
+		if !ok {
+			def = &DefinitionStats{Definition: key.Definition}
+			byDefinition[key.Definition] = def
+		}
+
+		def.RelationshipCount += count
+		if key.Relation == "" {
+			continue
+		}
+		def.Relations = append(def.Relations, RelationStats{
+			Relation:          key.Relation,
+			RelationshipCount: count,
+		})
+	}
+
+	stats := make([]DefinitionStats, 0, len(byDefinition))
+	for _, def := range byDefinition {
+		sort.Slice(def.Relations, func(i, j int) bool {
+			return def.Relations[i].Relation < def.Relations[j].Relation
+		})
+		stats = append(stats, *def)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Definition < stats[j].Definition
+	})
+
+	return stats
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// BulkUploadTest tests whether relationships can be loaded in bulk into a datastore.
+func BulkUploadTest(t *testing.T, tester DatastoreTester) {
+	testCases := []int{0, 1, 10, 100, 1_000, 10_000}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(strconv.Itoa(tc), func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+			require.NoError(err)
+
+			ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+			bulkSource := newTestRelationshipSource(tc, "bulk")
+
+			var loaded uint64
+			head, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+				loaded, err = rwt.BulkLoad(ctx, bulkSource)
+				return err
+			})
+			require.NoError(err)
+			require.Equal(uint64(tc), loaded)
+
+			iter, err := ds.SnapshotReader(head).QueryRelationships(ctx, datastore.RelationshipsFilter{
+				ResourceType: testResourceNamespace,
+			})
+			require.NoError(err)
+			defer iter.Close()
+
+			var found int
+			for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+				found++
+			}
+			require.NoError(iter.Err())
+			require.Equal(tc, found)
+		})
+	}
+}
+
+// BulkUploadErrorsTest tests that bulk loading a relationship which already exists fails.
+func BulkUploadErrorsTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple("bulk0", "user0"))
+	require.NoError(err)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, newTestRelationshipSource(10, "bulk"))
+		return err
+	})
+	require.Error(err)
+}
+
+// testRelationshipSource is a datastore.BulkWriteRelationshipSource which generates a fixed
+// number of distinct relationships, re-using the same tuple for each.
+type testRelationshipSource struct {
+	remaining int
+	prefix    string
+	current   *core.RelationTuple
+}
+
+func newTestRelationshipSource(count int, prefix string) *testRelationshipSource {
+	return &testRelationshipSource{
+		remaining: count,
+		prefix:    prefix,
+		current:   makeTestTuple("", ""),
+	}
+}
+
+func (trs *testRelationshipSource) Next(_ context.Context) (*core.RelationTuple, error) {
+	if trs.remaining <= 0 {
+		return nil, nil
+	}
+	trs.remaining--
+
+	trs.current.ResourceAndRelation.ObjectId = fmt.Sprintf("%s%d", trs.prefix, trs.remaining)
+	trs.current.Subject.ObjectId = fmt.Sprintf("user%d", trs.remaining)
+	return trs.current, nil
+}
@@ -46,6 +46,7 @@ func AllExceptWatch(t *testing.T, tester DatastoreTester) {
 	t.Run("TestSimple", func(t *testing.T) { SimpleTest(t, tester) })
 	t.Run("TestObjectIDs", func(t *testing.T) { ObjectIDsTest(t, tester) })
 	t.Run("TestDeleteRelationships", func(t *testing.T) { DeleteRelationshipsTest(t, tester) })
+	t.Run("TestDeleteRelationshipsWithLimit", func(t *testing.T) { DeleteRelationshipsWithLimitTest(t, tester) })
 	t.Run("TestInvalidReads", func(t *testing.T) { InvalidReadsTest(t, tester) })
 	t.Run("TestDeleteNonExistant", func(t *testing.T) { DeleteNotExistantTest(t, tester) })
 	t.Run("TestDeleteAlreadyDeleted", func(t *testing.T) { DeleteAlreadyDeletedTest(t, tester) })
@@ -55,18 +56,25 @@ func AllExceptWatch(t *testing.T, tester DatastoreTester) {
 	t.Run("TestUsersets", func(t *testing.T) { UsersetsTest(t, tester) })
 	t.Run("TestMultipleReadsInRWT", func(t *testing.T) { MultipleReadsInRWTTest(t, tester) })
 	t.Run("TestConcurrentWriteSerialization", func(t *testing.T) { ConcurrentWriteSerializationTest(t, tester) })
+	t.Run("TestRelationshipExpiration", func(t *testing.T) { RelationshipExpirationTest(t, tester) })
+
+	t.Run("TestBulkUpload", func(t *testing.T) { BulkUploadTest(t, tester) })
+	t.Run("TestBulkUploadErrors", func(t *testing.T) { BulkUploadErrorsTest(t, tester) })
 
 	t.Run("TestOrdering", func(t *testing.T) { OrderingTest(t, tester) })
 	t.Run("TestLimit", func(t *testing.T) { LimitTest(t, tester) })
 	t.Run("TestOrderedLimit", func(t *testing.T) { OrderedLimitTest(t, tester) })
 	t.Run("TestResume", func(t *testing.T) { ResumeTest(t, tester) })
 	t.Run("TestCursorErrors", func(t *testing.T) { CursorErrorsTest(t, tester) })
+	t.Run("TestReverseQueryResume", func(t *testing.T) { ReverseQueryResumeTest(t, tester) })
 
 	t.Run("TestRevisionQuantization", func(t *testing.T) { RevisionQuantizationTest(t, tester) })
 	t.Run("TestRevisionSerialization", func(t *testing.T) { RevisionSerializationTest(t, tester) })
 	t.Run("TestRevisionGC", func(t *testing.T) { RevisionGCTest(t, tester) })
 
 	t.Run("TestStats", func(t *testing.T) { StatsTest(t, tester) })
+	t.Run("TestDetailedStats", func(t *testing.T) { DetailedStatsTest(t, tester) })
+	t.Run("TestRelationshipCounts", func(t *testing.T) { RelationshipCountsTest(t, tester) })
 
 	t.Run("TestCaveatNotFound", func(t *testing.T) { CaveatNotFoundTest(t, tester) })
 	t.Run("TestWriteReadDeleteCaveat", func(t *testing.T) { WriteReadDeleteCaveatTest(t, tester) })
@@ -81,7 +89,10 @@ func All(t *testing.T, tester DatastoreTester) {
 
 	t.Run("TestWatch", func(t *testing.T) { WatchTest(t, tester) })
 	t.Run("TestWatchCancel", func(t *testing.T) { WatchCancelTest(t, tester) })
+	t.Run("TestWatchCheckpoint", func(t *testing.T) { WatchCheckpointTest(t, tester) })
 	t.Run("TestCaveatedRelationshipWatch", func(t *testing.T) { CaveatedRelationshipWatchTest(t, tester) })
+	t.Run("TestExpiredRelationshipWatch", func(t *testing.T) { ExpiredRelationshipWatchTest(t, tester) })
+	t.Run("TestWatchSchema", func(t *testing.T) { WatchSchemaTest(t, tester) })
 }
 
 var testResourceNS = namespace.Namespace(
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// expiredRelationshipDeleter is implemented by datastores which remove expired relationships
+// as part of garbage collection.
+type expiredRelationshipDeleter interface {
+	DeleteExpiredRels(ctx context.Context) (int64, error)
+}
+
+func makeExpiringTestTuple(resourceID, userID string, expiration time.Time) *core.RelationTuple {
+	tpl := makeTestTuple(resourceID, userID)
+	tpl.OptionalExpirationTime = timestamppb.New(expiration)
+	return tpl
+}
+
+// RelationshipExpirationTest tests that expired relationships are not returned by queries,
+// and that they can be replaced by a CREATE before they have been removed.
+func RelationshipExpirationTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+	ctx := context.Background()
+
+	// Truncated to a precision supported by all datastores.
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	past := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+
+	unexpiring := makeTestTuple("expiring", "tom")
+	notYetExpired := makeExpiringTestTuple("expiring", "sarah", future)
+	expired := makeExpiringTestTuple("expiring", "fred", past)
+
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, unexpiring, notYetExpired, expired)
+	require.NoError(err)
+
+	reader := ds.SnapshotReader(rev)
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:             testResourceNamespace,
+		OptionalResourceIds:      []string{"expiring"},
+		OptionalResourceRelation: testReaderRelation,
+	})
+	require.NoError(err)
+	requireSameTuples(require, iter, unexpiring, notYetExpired)
+
+	iter, err = reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+		SubjectType:        testUserNamespace,
+		OptionalSubjectIds: []string{"tom", "sarah", "fred"},
+	})
+	require.NoError(err)
+	requireSameTuples(require, iter, unexpiring, notYetExpired)
+
+	// An expired relationship may be replaced, but one which has not yet expired may not.
+	replacement := makeTestTuple("expiring", "fred")
+	rev, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, replacement)
+	require.NoError(err)
+
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple("expiring", "sarah"))
+	require.ErrorAs(err, &common.CreateRelationshipExistsError{})
+
+	iter, err = ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:        testResourceNamespace,
+		OptionalResourceIds: []string{"expiring"},
+	})
+	require.NoError(err)
+	requireSameTuples(require, iter, unexpiring, notYetExpired, replacement)
+}
+
+// ExpiredRelationshipWatchTest tests that removing an expired relationship is emitted as a
+// deletion by Watch.
+func ExpiredRelationshipWatchTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	deleter, ok := rawDS.(expiredRelationshipDeleter)
+	if !ok {
+		t.Skip("datastore does not remove expired relationships during garbage collection")
+	}
+
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expired := makeExpiringTestTuple("expiring", "fred", time.Now().Add(-1*time.Hour).Truncate(time.Second))
+	unexpiring := makeTestTuple("expiring", "tom")
+	writeRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, expired, unexpiring)
+	require.NoError(err)
+
+	removed, err := deleter.DeleteExpiredRels(ctx)
+	require.NoError(err)
+	require.Equal(int64(1), removed)
+
+	// Nothing remains to be removed.
+	removed, err = deleter.DeleteExpiredRels(ctx)
+	require.NoError(err)
+	require.Zero(removed)
+
+	changes, errchan := ds.Watch(ctx, writeRev, datastore.WatchJustRelationships())
+	require.Zero(len(errchan))
+
+	changeWait := time.NewTimer(waitForChangesTimeout)
+	select {
+	case change, ok := <-changes:
+		require.True(ok)
+		require.Len(change.Changes, 1)
+		require.Equal(core.RelationTupleUpdate_DELETE, change.Changes[0].Operation)
+		require.Equal(tuple.StringWithoutCaveat(expired), tuple.StringWithoutCaveat(change.Changes[0].Tuple))
+	case <-changeWait.C:
+		require.Fail("timed out waiting for expired relationship deletion via Watch API")
+	}
+}
+
+func requireSameTuples(require *require.Assertions, iter datastore.RelationshipIterator, expected ...*core.RelationTuple) {
+	defer iter.Close()
+
+	found := make(map[string]*core.RelationTuple, len(expected))
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found[tuple.StringWithoutCaveat(tpl)] = tpl
+	}
+	require.NoError(iter.Err())
+	require.Len(found, len(expected))
+
+	for _, tpl := range expected {
+		foundTpl, ok := found[tuple.StringWithoutCaveat(tpl)]
+		require.True(ok, "missing relationship %s", tuple.StringWithoutCaveat(tpl))
+		require.Empty(cmp.Diff(tpl, foundTpl, protocmp.Transform()))
+	}
+}
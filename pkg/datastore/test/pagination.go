@@ -263,6 +263,76 @@ func ResumeTest(t *testing.T, tester DatastoreTester) {
 	}
 }
 
+func ReverseQueryResumeTest(t *testing.T, tester DatastoreTester) {
+	testCases := []struct {
+		subjectType      string
+		resourceRelation *options.ResourceRelation
+	}{
+		{testfixtures.UserNS.Name, &options.ResourceRelation{Namespace: testfixtures.DocumentNS.Name, Relation: "viewer"}},
+		{testfixtures.UserNS.Name, &options.ResourceRelation{Namespace: testfixtures.DocumentNS.Name, Relation: "owner"}},
+		{testfixtures.FolderNS.Name, &options.ResourceRelation{Namespace: testfixtures.DocumentNS.Name, Relation: "parent"}},
+	}
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(t, err)
+
+	ds, rev := testfixtures.StandardDatastoreWithData(rawDS, require.New(t))
+	tRequire := testfixtures.TupleChecker{Require: require.New(t), DS: ds}
+
+	for _, tc := range testCases {
+		expected := lo.Filter(sortedStandardData(tc.resourceRelation.Namespace, options.ByResource), func(item *core.RelationTuple, _ int) bool {
+			return item.ResourceAndRelation.Relation == tc.resourceRelation.Relation && item.Subject.Namespace == tc.subjectType
+		})
+		require.NotEmpty(t, expected)
+
+		for batchSize := 1; batchSize <= len(expected); batchSize++ {
+			testLimit := uint64(batchSize)
+
+			t.Run(fmt.Sprintf("%s-%s-batches-%d", tc.subjectType, tc.resourceRelation.Relation, batchSize), func(t *testing.T) {
+				require := require.New(t)
+				ctx := context.Background()
+
+				foreachTxType(ctx, ds, rev, func(reader datastore.Reader) {
+					// Test that if you ask for resume without an order we error
+					_, err := reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+						SubjectType: tc.subjectType,
+					}, options.WithResRelation(tc.resourceRelation), options.WithAfterForReverse(&core.RelationTuple{}))
+					require.ErrorIs(err, datastore.ErrCursorsWithoutSorting)
+
+					cursor := options.Cursor(nil)
+					for offset := 0; offset <= len(expected); offset += batchSize {
+						iter, err := reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+							SubjectType: tc.subjectType,
+						},
+							options.WithResRelation(tc.resourceRelation),
+							options.WithSortForReverse(options.ByResource),
+							options.WithReverseLimit(&testLimit),
+							options.WithAfterForReverse(cursor),
+						)
+						require.NoError(err)
+						defer iter.Close()
+
+						upperBound := offset + batchSize
+						if upperBound > len(expected) {
+							upperBound = len(expected)
+						}
+						tRequire.VerifyOrderedIteratorResults(iter, expected[offset:upperBound]...)
+
+						cursor, err = iter.Cursor()
+						if upperBound-offset > 0 {
+							require.NotEmpty(cursor)
+							require.NoError(err)
+						} else {
+							require.Empty(cursor)
+							require.ErrorIs(err, datastore.ErrCursorEmpty)
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
 func CursorErrorsTest(t *testing.T, tester DatastoreTester) {
 	testCases := []struct {
 		order              options.SortOrder
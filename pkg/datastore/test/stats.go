@@ -5,9 +5,13 @@ import (
 	"testing"
 	"time"
 
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/require"
 
 	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 const statsRetryCount = 3
@@ -41,3 +45,106 @@ func StatsTest(t *testing.T, tester DatastoreTester) {
 		require.Equal(newStats.UniqueID, stats.UniqueID, "unique ID must be stable")
 	}
 }
+
+func DetailedStatsTest(t *testing.T, tester DatastoreTester) {
+	ctx := context.Background()
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ = testfixtures.StandardDatastoreWithData(ds, require)
+
+	expected := make(map[datastore.RelationKey]uint64)
+	for _, tupleStr := range testfixtures.StandardTuples {
+		tpl := tuple.MustParse(tupleStr)
+		expected[datastore.RelationKey{Definition: tpl.ResourceAndRelation.Namespace, Relation: tpl.ResourceAndRelation.Relation}]++
+	}
+
+	stats, err := ds.DetailedStatistics(ctx)
+	require.NoError(err)
+	require.False(stats.CountsAreEstimates, "small datastores must be counted exactly")
+	require.Equal(datastore.ComputeDefinitionStats(expected), stats.Definitions)
+}
+
+// RelationshipCountsTest tests that the relationship counts maintained by the datastore count
+// the existing relationships of a definition when first read, and then track each way in which
+// relationships are written and deleted.
+func RelationshipCountsTest(t *testing.T, tester DatastoreTester) {
+	ctx := context.Background()
+	require := require.New(t)
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+
+	documentViewer := datastore.RelationKey{Definition: "document", Relation: "viewer"}
+	folderViewer := datastore.RelationKey{Definition: "folder", Relation: "viewer"}
+	resourceReader := datastore.RelationKey{Definition: testResourceNamespace, Relation: testReaderRelation}
+
+	requireCounts := func(expected map[datastore.RelationKey]uint64, f func(rwt datastore.ReadWriteTransaction) error) {
+		_, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+			if err := f(rwt); err != nil {
+				return err
+			}
+
+			counts, err := rwt.RelationshipCounts(ctx, "document", "folder", testResourceNamespace)
+			require.NoError(err)
+			require.Equal(expected, counts)
+			return nil
+		})
+		require.NoError(err)
+	}
+
+	// Relationships written before the counts are first read are counted.
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("document:first#viewer@user:tom")),
+			tuple.Touch(tuple.MustParse("folder:first#viewer@user:tom")),
+		})
+	})
+	require.NoError(err)
+
+	requireCounts(map[datastore.RelationKey]uint64{documentViewer: 2, folderViewer: 1}, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("document:second#viewer@user:tom")),
+		})
+	})
+
+	// Only the counts of the requested definitions are returned.
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		counts, err := rwt.RelationshipCounts(ctx, "folder")
+		require.NoError(err)
+		require.Equal(map[datastore.RelationKey]uint64{folderViewer: 1}, counts)
+		return nil
+	})
+	require.NoError(err)
+
+	// Touching an existing relationship and deleting a missing one do not change the counts.
+	requireCounts(map[datastore.RelationKey]uint64{documentViewer: 2, folderViewer: 1}, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Touch(tuple.MustParse("document:first#viewer@user:tom")),
+			tuple.Touch(tuple.MustParse("document:third#viewer@user:tom")),
+			tuple.Delete(tuple.MustParse("document:second#viewer@user:tom")),
+			tuple.Delete(tuple.MustParse("document:missing#viewer@user:tom")),
+		})
+	})
+
+	requireCounts(map[datastore.RelationKey]uint64{documentViewer: 2, folderViewer: 1, resourceReader: 10}, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, newTestRelationshipSource(10, "bulk"))
+		return err
+	})
+
+	requireCounts(map[datastore.RelationKey]uint64{documentViewer: 1, folderViewer: 1, resourceReader: 10}, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+			ResourceType:       "document",
+			OptionalResourceId: "first",
+		})
+		return err
+	})
+
+	requireCounts(map[datastore.RelationKey]uint64{documentViewer: 1, resourceReader: 10}, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteNamespaces(ctx, "folder")
+	})
+}
@@ -307,7 +307,7 @@ func SimpleTest(t *testing.T, tester DatastoreTester) {
 
 			// Delete with DeleteRelationship
 			deletedAt, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
-				err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+				_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
 					ResourceType: testResourceNamespace,
 				})
 				require.NoError(err)
@@ -372,6 +372,58 @@ func ObjectIDsTest(t *testing.T, tester DatastoreTester) {
 	}
 }
 
+// DeleteRelationshipsWithLimitTest tests that limited deletes remove at most the limit, and
+// report whether it was reached.
+func DeleteRelationshipsWithLimitTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, newTestRelationshipSource(10, "limited"))
+		return err
+	})
+	require.NoError(err)
+
+	filter := &v1.RelationshipFilter{ResourceType: testResourceNamespace, OptionalRelation: testReaderRelation}
+	deleteWithLimit := func(limit uint64) (bool, datastore.Revision) {
+		var limitReached bool
+		deletedAt, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+			var err error
+			limitReached, err = rwt.DeleteRelationships(ctx, filter, options.WithDeleteLimit(&limit))
+			return err
+		})
+		require.NoError(err)
+		return limitReached, deletedAt
+	}
+	remaining := func(revision datastore.Revision) int {
+		iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilterFromPublicFilter(filter))
+		require.NoError(err)
+		defer iter.Close()
+
+		var found int
+		for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+			found++
+		}
+		require.NoError(iter.Err())
+		return found
+	}
+
+	limitReached, deletedAt := deleteWithLimit(4)
+	require.True(limitReached)
+	require.Equal(6, remaining(deletedAt))
+
+	limitReached, deletedAt = deleteWithLimit(10)
+	require.False(limitReached)
+	require.Equal(0, remaining(deletedAt))
+
+	limitReached, _ = deleteWithLimit(1)
+	require.False(limitReached)
+}
+
 // DeleteRelationshipsTest tests whether or not the requirements for deleting
 // relationships hold for a particular datastore.
 func DeleteRelationshipsTest(t *testing.T, tester DatastoreTester) {
@@ -470,7 +522,7 @@ func DeleteRelationshipsTest(t *testing.T, tester DatastoreTester) {
 			require.NoError(err)
 
 			deletedAt, err := ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
-				err := rwt.DeleteRelationships(ctx, tt.filter)
+				_, err := rwt.DeleteRelationships(ctx, tt.filter)
 				require.NoError(err)
 				return err
 			})
@@ -59,7 +59,7 @@ func WatchTest(t *testing.T, tester DatastoreTester) {
 			lowestRevision, err := ds.HeadRevision(ctx)
 			require.NoError(err)
 
-			changes, errchan := ds.Watch(ctx, lowestRevision)
+			changes, errchan := ds.Watch(ctx, lowestRevision, datastore.WatchJustRelationships())
 			require.Zero(len(errchan))
 
 			var testUpdates [][]*core.RelationTupleUpdate
@@ -91,7 +91,7 @@ func WatchTest(t *testing.T, tester DatastoreTester) {
 			testUpdates = append(testUpdates, batch, []*core.RelationTupleUpdate{deleteUpdate})
 
 			_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
-				err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+				_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
 					ResourceType:     testResourceNamespace,
 					OptionalRelation: testReaderRelation,
 					OptionalSubjectFilter: &v1.SubjectFilter{
@@ -111,7 +111,7 @@ func WatchTest(t *testing.T, tester DatastoreTester) {
 			verifyUpdates(require, testUpdates, changes, errchan, tc.expectFallBehind)
 
 			// Test the catch-up case
-			changes, errchan = ds.Watch(ctx, lowestRevision)
+			changes, errchan = ds.Watch(ctx, lowestRevision, datastore.WatchJustRelationships())
 			verifyUpdates(require, testUpdates, changes, errchan, tc.expectFallBehind)
 		})
 	}
@@ -178,7 +178,7 @@ func WatchCancelTest(t *testing.T, tester DatastoreTester) {
 	startWatchRevision := setupDatastore(ds, require)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	changes, errchan := ds.Watch(ctx, startWatchRevision)
+	changes, errchan := ds.Watch(ctx, startWatchRevision, datastore.WatchJustRelationships())
 	require.Zero(len(errchan))
 
 	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple("test", "test"))
@@ -215,3 +215,176 @@ func WatchCancelTest(t *testing.T, tester DatastoreTester) {
 		}
 	}
 }
+
+// WatchSchemaTest tests whether or not written namespaces and caveats are reported by a watch
+// for schema changes, and relationship changes are not.
+func WatchCheckpointTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	startWatchRevision := setupDatastore(ds, require)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	options := datastore.WatchJustRelationships()
+	options.CheckpointInterval = 100 * time.Millisecond
+
+	changes, errchan := ds.Watch(ctx, startWatchRevision, options)
+	require.Zero(len(errchan))
+
+	writtenRevision, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple("test", "test"))
+	require.NoError(err)
+
+	// A checkpoint must eventually be reported at or after the write, and only after the
+	// write itself has been reported.
+	sawWrite := false
+	for {
+		changeWait := time.NewTimer(waitForChangesTimeout)
+		select {
+		case change, ok := <-changes:
+			require.True(ok, "watch closed unexpectedly")
+
+			if !change.IsCheckpoint {
+				require.False(change.Revision.LessThan(writtenRevision))
+				require.Equal(1, len(change.Changes))
+				sawWrite = true
+				continue
+			}
+
+			require.Empty(change.Changes)
+			require.False(change.HasSchemaChanges())
+			if change.Revision.LessThan(writtenRevision) {
+				require.False(sawWrite, "checkpoint went backwards")
+				continue
+			}
+
+			require.True(sawWrite, "checkpoint reported before the write")
+			return
+
+		case err := <-errchan:
+			require.Failf("unexpected watch error", "%v", err)
+
+		case <-changeWait.C:
+			require.Fail("Timed out waiting for checkpoint")
+		}
+	}
+}
+
+func WatchSchemaTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	setupDatastore(ds, require)
+	skipIfNotCaveatStorer(t, ds)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lowestRevision, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	changes, errchan := ds.Watch(ctx, lowestRevision, datastore.WatchOptions{Content: datastore.WatchSchema})
+	require.Zero(len(errchan))
+
+	coreCaveat := createCoreCaveat(t)
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteNamespaces(ctx, testResourceNS); err != nil {
+			return err
+		}
+		return rwt.WriteCaveats(ctx, []*core.CaveatDefinition{coreCaveat})
+	})
+	require.NoError(err)
+
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, makeTestTuple("test", "test"))
+	require.NoError(err)
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, testGroupNS)
+	})
+	require.NoError(err)
+
+	verifySchemaChanges(require, changes, errchan, []string{
+		"definition(" + testResourceNamespace + ")",
+		"definition(" + coreCaveat.Name + ")",
+		"definition(" + testGroupNamespace + ")",
+	})
+}
+
+// WatchSchemaDeletionTest tests whether or not deleted namespaces and caveats are reported by
+// a watch for schema changes.
+func WatchSchemaDeletionTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	setupDatastore(ds, require)
+	skipIfNotCaveatStorer(t, ds)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	coreCaveat := createCoreCaveat(t)
+	lowestRevision, err := writeCaveat(ctx, ds, coreCaveat)
+	require.NoError(err)
+
+	changes, errchan := ds.Watch(ctx, lowestRevision, datastore.WatchOptions{Content: datastore.WatchSchema})
+	require.Zero(len(errchan))
+
+	_, err = ds.ReadWriteTx(ctx, func(rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.DeleteNamespaces(ctx, testGroupNamespace); err != nil {
+			return err
+		}
+		return rwt.DeleteCaveats(ctx, []string{coreCaveat.Name})
+	})
+	require.NoError(err)
+
+	verifySchemaChanges(require, changes, errchan, []string{
+		"deleted-namespace(" + testGroupNamespace + ")",
+		"deleted-caveat(" + coreCaveat.Name + ")",
+	})
+}
+
+func verifySchemaChanges(
+	require *require.Assertions,
+	changes <-chan *datastore.RevisionChanges,
+	errchan <-chan error,
+	expected []string,
+) {
+	missingExpected := strset.New(expected...)
+	for !missingExpected.IsEmpty() {
+		changeWait := time.NewTimer(waitForChangesTimeout)
+		select {
+		case change, ok := <-changes:
+			require.True(ok, "watch closed unexpectedly")
+			require.Empty(change.Changes, "relationship changes reported by schema watch")
+
+			for _, def := range change.ChangedDefinitions {
+				key := "definition(" + def.GetName() + ")"
+				require.True(missingExpected.Has(key), "unexpected change: %s", key)
+				missingExpected.Remove(key)
+			}
+			for _, name := range change.DeletedNamespaces {
+				key := "deleted-namespace(" + name + ")"
+				require.True(missingExpected.Has(key), "unexpected change: %s", key)
+				missingExpected.Remove(key)
+			}
+			for _, name := range change.DeletedCaveats {
+				key := "deleted-caveat(" + name + ")"
+				require.True(missingExpected.Has(key), "unexpected change: %s", key)
+				missingExpected.Remove(key)
+			}
+
+		case err := <-errchan:
+			require.NoError(err)
+
+		case <-changeWait.C:
+			require.Fail("Timed out", "waiting for changes: %s", missingExpected)
+		}
+	}
+}
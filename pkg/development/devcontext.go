@@ -40,8 +40,12 @@ type DevContext struct {
 	Ctx            context.Context
 	Datastore      datastore.Datastore
 	Revision       datastore.Revision
+	Schema         string
 	CompiledSchema *compiler.CompiledSchema
 	Dispatcher     dispatch.Dispatcher
+
+	// SchemaWarnings holds the warnings found by linting the schema, if any.
+	SchemaWarnings []*devinterface.DeveloperError
 }
 
 // NewDevContext creates a new DevContext from the specified request context, parsing and populating
@@ -104,12 +108,19 @@ func newDevContextWithDatastore(ctx context.Context, requestContext *devinterfac
 		return nil, nil, verr
 	}
 
+	schemaWarnings, err := LintSchema(compiled)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &DevContext{
 		Ctx:            ctx,
 		Datastore:      ds,
+		Schema:         requestContext.Schema,
 		CompiledSchema: compiled,
 		Revision:       currentRevision,
 		Dispatcher:     graph.NewLocalOnlyDispatcher(10),
+		SchemaWarnings: schemaWarnings,
 	}, nil, nil
 }
 
@@ -123,11 +134,11 @@ func (dc *DevContext) RunV1InMemoryService() (*grpc.ClientConn, func(), error) {
 	s := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			datastoremw.UnaryServerInterceptor(dc.Datastore),
-			consistency.UnaryServerInterceptor(),
+			consistency.UnaryServerInterceptor(nil),
 		),
 		grpc.ChainStreamInterceptor(
 			datastoremw.StreamServerInterceptor(dc.Datastore),
-			consistency.StreamServerInterceptor(),
+			consistency.StreamServerInterceptor(nil),
 		),
 	)
 	ps := v1svc.NewPermissionsServer(dc.Dispatcher, v1svc.PermissionsServerConfig{
@@ -111,6 +111,38 @@ definition document {
 	require.Equal(t, "document:somedoc#viewer:\n- '[user:someuser[...]] is <document:somedoc#viewer>'\n", generated)
 }
 
+func TestDevelopmentSchemaWarnings(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	devCtx, devErrs, err := NewDevContext(context.Background(), &devinterface.RequestContext{
+		Schema: `definition user {}
+
+definition group {
+	relation member: user
+	permission view = member
+}
+
+definition document {
+	relation owner: group#member
+	permission view = owner->view
+}
+`,
+	})
+
+	require.Nil(t, err)
+	require.Nil(t, devErrs)
+	defer devCtx.Dispose()
+
+	require.Len(t, devCtx.SchemaWarnings, 1)
+	warning := devCtx.SchemaWarnings[0]
+	require.Equal(t, devinterface.DeveloperError_SCHEMA_WARNING, warning.Kind)
+	require.Equal(t, devinterface.DeveloperError_SCHEMA, warning.Source)
+	require.Equal(t, "owner->view", warning.Context)
+	require.Equal(t, uint32(10), warning.Line)
+	require.Equal(t, uint32(20), warning.Column)
+	require.Contains(t, warning.Message, "which allows subject relation `group#member`")
+}
+
 func TestDevContextV1Service(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
 
@@ -2,7 +2,9 @@ package development
 
 import (
 	"errors"
+	"strings"
 
+	"github.com/authzed/spicedb/internal/namespace"
 	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
@@ -41,3 +43,60 @@ func CompileSchema(schema string) (*compiler.CompiledSchema, *devinterface.Devel
 
 	return compiled, nil, nil
 }
+
+// FormatSchema formats the schema, preserving its comments and layout. The schema is expected to
+// have been compiled, so any error returned is an internal error.
+func FormatSchema(schema string) (string, error) {
+	formatted, err := compiler.FormatSchema(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schema,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(formatted), nil
+}
+
+// LintSchema lints a compiled and validated schema, returning each warning found as a developer
+// error of kind SCHEMA_WARNING. The non-developer error is returned only if an internal error
+// occurred.
+func LintSchema(compiled *compiler.CompiledSchema) ([]*devinterface.DeveloperError, error) {
+	resolver := namespace.ResolverForPredefinedDefinitions(namespace.PredefinedElements{
+		Namespaces: compiled.ObjectDefinitions,
+		Caveats:    compiled.CaveatDefinitions,
+	})
+
+	typeSystems := make([]*namespace.TypeSystem, 0, len(compiled.ObjectDefinitions))
+	for _, nsDef := range compiled.ObjectDefinitions {
+		ts, err := namespace.NewNamespaceTypeSystem(nsDef, resolver)
+		if err != nil {
+			return nil, err
+		}
+		typeSystems = append(typeSystems, ts)
+	}
+
+	warnings, err := namespace.LintSchema(typeSystems)
+	if err != nil {
+		return nil, err
+	}
+
+	devWarnings := make([]*devinterface.DeveloperError, 0, len(warnings))
+	for _, warning := range warnings {
+		devWarning := &devinterface.DeveloperError{
+			Message: warning.Message,
+			Kind:    devinterface.DeveloperError_SCHEMA_WARNING,
+			Source:  devinterface.DeveloperError_SCHEMA,
+			Context: warning.SourceCodeString,
+		}
+
+		if warning.SourcePosition != nil {
+			devWarning.Line = uint32(warning.SourcePosition.ZeroIndexedLineNumber) + 1
+			devWarning.Column = uint32(warning.SourcePosition.ZeroIndexedColumnPosition) + 1
+		}
+
+		devWarnings = append(devWarnings, devWarning)
+	}
+
+	return devWarnings, nil
+}
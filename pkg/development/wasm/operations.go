@@ -5,28 +5,25 @@ package main
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/authzed/spicedb/pkg/development"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
-	"github.com/authzed/spicedb/pkg/schemadsl/generator"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func runOperation(devContext *development.DevContext, operation *devinterface.Operation) (*devinterface.OperationResult, error) {
 	switch {
 	case operation.FormatSchemaParameters != nil:
-		formatted, _, err := generator.GenerateSchema(devContext.CompiledSchema.OrderedDefinitions)
+		formatted, err := development.FormatSchema(devContext.Schema)
 		if err != nil {
 			return nil, err
 		}
 
-		trimmed := strings.TrimSpace(formatted)
 		return &devinterface.OperationResult{
 			FormatSchemaResult: &devinterface.FormatSchemaResult{
-				FormattedSchema: trimmed,
+				FormattedSchema: formatted,
 			},
 		}, nil
 
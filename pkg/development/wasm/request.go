@@ -70,6 +70,7 @@ func runDeveloperRequest(this js.Value, args []js.Value) any {
 		OperationsResults: &devinterface.OperationsResults{
 			Results: results,
 		},
+		SchemaWarnings: devContext.SchemaWarnings,
 	})
 }
 
@@ -38,12 +38,12 @@ func (cr *DispatchExpandResponse) MarshalZerologObject(e *zerolog.Event) {
 }
 
 // MarshalZerologObject implements zerolog object marshalling.
-func (lr *DispatchLookupRequest) MarshalZerologObject(e *zerolog.Event) {
+func (lr *DispatchLookupResourcesRequest) MarshalZerologObject(e *zerolog.Event) {
 	e.Object("metadata", lr.Metadata)
 	e.Str("object", tuple.StringRR(lr.ObjectRelation))
 	e.Str("subject", tuple.StringONR(lr.Subject))
 	e.Interface("context", lr.Context)
-	e.Uint32("limit", lr.Limit)
+	e.Uint32("limit", lr.OptionalLimit)
 }
 
 // MarshalZerologObject implements zerolog object marshalling.
@@ -72,7 +72,7 @@ func (strs strArray) MarshalZerologArray(a *zerolog.Array) {
 }
 
 // MarshalZerologObject implements zerolog object marshalling.
-func (cr *DispatchLookupResponse) MarshalZerologObject(e *zerolog.Event) {
+func (cr *DispatchLookupResourcesResponse) MarshalZerologObject(e *zerolog.Event) {
 	e.Object("metadata", cr.Metadata)
 }
 
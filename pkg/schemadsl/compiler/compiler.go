@@ -3,6 +3,9 @@ package compiler
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
 
 	"google.golang.org/protobuf/proto"
 
@@ -41,8 +44,28 @@ type CompiledSchema struct {
 	OrderedDefinitions []SchemaDefinition
 }
 
+// Option is an option for Compile.
+type Option func(*config)
+
+type config struct {
+	fileLoader FileLoader
+}
+
+// WithFileLoader allows the schema to import other schema files, which are loaded via the
+// given loader. Without a loader, schemas containing import directives fail to compile.
+func WithFileLoader(loader FileLoader) Option {
+	return func(c *config) {
+		c.fileLoader = loader
+	}
+}
+
 // Compile compilers the input schema into a set of namespace definition protos.
-func Compile(schema InputSchema, objectTypePrefix *string) (*CompiledSchema, error) {
+func Compile(schema InputSchema, objectTypePrefix *string, opts ...Option) (*CompiledSchema, error) {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	mapper := newPositionMapper(schema)
 	root := parser.Parse(createAstNode, schema.Source, schema.SchemaString).(*dslNode)
 	errs := root.FindAll(dslshape.NodeTypeError)
@@ -51,23 +74,49 @@ func Compile(schema InputSchema, objectTypePrefix *string) (*CompiledSchema, err
 		return nil, err
 	}
 
+	resolver := &importResolver{
+		loader: cfg.fileLoader,
+		mapper: mapper,
+		loaded: map[string]struct{}{},
+	}
+
+	// The root schema cannot be imported by the files it imports. Imports are resolved relative
+	// to the directory containing the root schema, so it is keyed by its file name, whatever the
+	// directory in which it was given.
+	if cfg.fileLoader != nil {
+		if rootPath := path.Base(filepath.ToSlash(string(schema.Source))); fs.ValidPath(rootPath) {
+			resolver.loaded[rootPath] = struct{}{}
+		}
+	}
+
+	nodes, err := resolver.topLevelNodes(root, "")
+	if err != nil {
+		return nil, withErrorContext(err, mapper)
+	}
+
 	compiled, err := translate(translationContext{
 		objectTypePrefix: objectTypePrefix,
 		mapper:           mapper,
 		schemaString:     schema.SchemaString,
-	}, root)
+		rootSource:       schema.Source,
+	}, nodes)
 	if err != nil {
-		var errorWithNode errorWithNode
-		if errors.As(err, &errorWithNode) {
-			err = toContextError(errorWithNode.error.Error(), errorWithNode.errorSourceCode, errorWithNode.node, mapper)
-		}
-
-		return nil, err
+		return nil, withErrorContext(err, mapper)
 	}
 
 	return compiled, nil
 }
 
+// withErrorContext converts an error raised for a node into an ErrorWithContext.
+func withErrorContext(err error, mapper input.PositionMapper) error {
+	var errorWithNode errorWithNode
+	if errors.As(err, &errorWithNode) {
+		return toContextError(errorWithNode.error.Error(), errorWithNode.errorSourceCode, errorWithNode.node, mapper)
+	}
+
+	return err
+}
+
 func errorNodeToError(node *dslNode, mapper input.PositionMapper) error {
 	if node.GetType() != dslshape.NodeTypeError {
 		return fmt.Errorf("given none error node")
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -344,6 +345,33 @@ func TestCompile(t *testing.T) {
 			},
 		},
 
+		{
+			"arrow function permissions",
+			&someTenant,
+			`definition arrowed {
+				permission foos = bars.all(bazs) + bars.any(bazs)
+			}`,
+			"",
+			[]SchemaDefinition{
+				namespace.Namespace("sometenant/arrowed",
+					namespace.MustRelation("foos",
+						namespace.Union(
+							namespace.MustFunctionedTupleToUserset("bars", "all", "bazs"),
+							namespace.TupleToUserset("bars", "bazs"),
+						),
+					),
+				),
+			},
+		},
+		{
+			"unknown arrow function",
+			&someTenant,
+			`definition arrowed {
+				permission foos = bars.none(bazs)
+			}`,
+			"parse error in `unknown arrow function`, line 2, column 28: Expected function name `any` or `all`, found: none",
+			[]SchemaDefinition{},
+		},
 		{
 			"multiarrow permission",
 			&someTenant,
@@ -957,3 +985,191 @@ func filterSourcePositions(m protoreflect.Message) {
 		return true
 	})
 }
+
+func TestCompileWithImports(t *testing.T) {
+	files := fstest.MapFS{
+		"common/user.zed": {Data: []byte(`definition user {}
+
+partial ownable {
+	relation owner: user
+	permission admin = owner
+}`)},
+		"common/group.zed": {Data: []byte(`import "user.zed"
+
+definition group {
+	relation member: user
+}`)},
+		"cycle/first.zed":  {Data: []byte(`import "second.zed"` + "\n" + `definition first {}`)},
+		"cycle/second.zed": {Data: []byte(`import "first.zed"` + "\n" + `definition second {}`)},
+		"broken.zed": {Data: []byte(`definition broken {
+	relation foo: user +
+}`)},
+		"duplicate.zed": {Data: []byte(`
+
+definition document {}`)},
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+		expectedNames []string
+	}{
+		{
+			"imports and partials",
+			`import "common/group.zed"
+
+definition document {
+	...ownable
+	relation viewer: user | group#member
+	permission view = viewer + admin
+}`,
+			"",
+			[]string{"user", "group", "document"},
+		},
+		{
+			"cyclic imports",
+			`import "cycle/first.zed"`,
+			"",
+			[]string{"second", "first"},
+		},
+		{
+			"missing file",
+			`import "missing.zed"`,
+			"parse error in `root.zed`, line 1, column 1: could not load imported file `missing.zed`",
+			nil,
+		},
+		{
+			"outside of root",
+			`import "../outside.zed"`,
+			"parse error in `root.zed`, line 1, column 1: cannot import `../outside.zed`: imported files must be within the directory of the root schema",
+			nil,
+		},
+		{
+			"parse error in imported file",
+			`import "broken.zed"`,
+			"parse error in `broken.zed`, line 2, column 21: Expected end of statement or definition, found: TokenTypePlus",
+			nil,
+		},
+		{
+			"duplicate definition in imported file",
+			`definition document {}
+import "duplicate.zed"`,
+			"parse error in `duplicate.zed`, line 3, column 1: found name reused between multiple definitions and/or caveats: document",
+			nil,
+		},
+		{
+			"missing partial",
+			`definition document {
+	...missing
+}`,
+			"parse error in `root.zed`, line 2, column 2: partial `missing` not found",
+			nil,
+		},
+		{
+			"self-referencing partial",
+			`partial first {
+	...second
+}
+
+partial second {
+	...first
+}
+
+definition document {
+	...first
+}`,
+			"partial `first` references itself via first -> second -> first",
+			nil,
+		},
+		{
+			"duplicate partial",
+			`partial first {}
+partial first {}`,
+			"found name reused between multiple partials: first",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			empty := ""
+			compiled, err := Compile(InputSchema{
+				Source:       input.Source("root.zed"),
+				SchemaString: test.input,
+			}, &empty, WithFileLoader(NewFSFileLoader(files)))
+			if test.expectedError != "" {
+				require.ErrorContains(err, test.expectedError)
+				return
+			}
+
+			require.NoError(err)
+			names := make([]string, 0, len(compiled.OrderedDefinitions))
+			for _, def := range compiled.OrderedDefinitions {
+				names = append(names, def.GetName())
+			}
+			require.Equal(test.expectedNames, names)
+		})
+	}
+}
+
+func TestCompileImportCycleToRoot(t *testing.T) {
+	require := require.New(t)
+
+	// The root schema is given with its directory, as when compiled from the command line, while
+	// imports are resolved relative to that directory.
+	files := fstest.MapFS{
+		"root.zed":        {Data: []byte(`import "shared/user.zed"` + "\n" + `definition document {}`)},
+		"shared/user.zed": {Data: []byte(`import "../root.zed"` + "\n" + `definition user {}`)},
+	}
+
+	empty := ""
+	compiled, err := Compile(InputSchema{
+		Source:       input.Source("schemas/root.zed"),
+		SchemaString: string(files["root.zed"].Data),
+	}, &empty, WithFileLoader(NewFSFileLoader(files)))
+	require.NoError(err)
+
+	names := make([]string, 0, len(compiled.OrderedDefinitions))
+	for _, def := range compiled.OrderedDefinitions {
+		names = append(names, def.GetName())
+	}
+	require.Equal([]string{"user", "document"}, names)
+}
+
+func TestCompileImportSourcePositions(t *testing.T) {
+	require := require.New(t)
+	loader := FileLoaderFunc(func(path string) (string, error) {
+		require.Equal("shared/ownable.zed", path)
+		return "\npartial ownable {\n\trelation owner: user\n}", nil
+	})
+
+	empty := ""
+	compiled, err := Compile(InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: "import \"shared/ownable.zed\"\n\ndefinition user {}\n\ndefinition document {\n\t...ownable\n\trelation viewer: user\n}",
+	}, &empty, WithFileLoader(loader))
+	require.NoError(err)
+
+	document := compiled.ObjectDefinitions[1]
+	require.Equal("document", document.Name)
+	testutil.RequireProtoEqual(t, &core.SourcePosition{ZeroIndexedLineNumber: 4}, document.SourcePosition, "definition position")
+
+	owner, viewer := document.Relation[0], document.Relation[1]
+	require.Equal("owner", owner.Name)
+	testutil.RequireProtoEqual(t, &core.SourcePosition{
+		ZeroIndexedLineNumber:     2,
+		ZeroIndexedColumnPosition: 1,
+		SourceFile:                "shared/ownable.zed",
+	}, owner.SourcePosition, "spliced relation position")
+	testutil.RequireProtoEqual(t, &core.SourcePosition{ZeroIndexedLineNumber: 6, ZeroIndexedColumnPosition: 1}, viewer.SourcePosition, "relation position")
+
+	// Imports are rejected without a file loader.
+	_, err = Compile(InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: `import "shared/ownable.zed"`,
+	}, &empty)
+	require.ErrorContains(err, "imports are not supported when compiling a single schema")
+}
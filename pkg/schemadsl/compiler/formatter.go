@@ -0,0 +1,586 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
+	"github.com/authzed/spicedb/pkg/schemadsl/lexer"
+	"github.com/authzed/spicedb/pkg/schemadsl/parser"
+)
+
+// FormatSchema formats the input schema, normalizing its indentation and spacing.
+//
+// Unlike the generator, which reconstructs a schema from its compiled definitions, the formatter
+// works on the parse tree of the schema: the order of its imports, definitions, caveats and
+// partials is kept, along with all of its comments and the blank lines grouping them. As such,
+// the schema need only parse, and the files it imports are not loaded.
+func FormatSchema(schema InputSchema) (string, error) {
+	root := parser.Parse(createAstNode, schema.Source, schema.SchemaString).(*dslNode)
+	errs := root.FindAll(dslshape.NodeTypeError)
+	if len(errs) > 0 {
+		return "", errorNodeToError(errs[0], newPositionMapper(schema))
+	}
+
+	sf := newSchemaFormatter(schema)
+	if err := sf.emitTopLevel(root); err != nil {
+		return "", err
+	}
+	return sf.buf.String(), nil
+}
+
+// schemaFormatter holds the state of formatting a single schema. Comments are not found in the
+// parse tree at all of the places they may appear, so they are instead read from the lexer and
+// emitted, in order, before the first node which follows them.
+type schemaFormatter struct {
+	schema   string
+	comments []lexer.Lexeme // the comments in the schema, in order
+	tokens   []lexer.Lexeme // the tokens in the schema other than comments and whitespace
+
+	tokenIndexByStart map[int]int // index into tokens by starting byte position
+	tokenIndexByEnd   map[int]int // index into tokens by (inclusive) ending byte position
+
+	nextComment int             // the index of the next comment to be emitted
+	buf         strings.Builder // the formatted schema
+	lineOpen    bool            // whether the last line in buf has not been terminated
+}
+
+// block holds the state of formatting the top level of the schema, or the body of a definition,
+// partial or caveat.
+type block struct {
+	depth      int  // the indentation of the lines in the block
+	prevEnd    int  // the byte position following the last node or comment emitted
+	hasLines   bool // whether a line has been started in the block
+	written    bool // whether anything, including a trailing comment, has been written in the block
+	forceBlank bool // whether the next line started must be preceded by a blank line
+}
+
+func newSchemaFormatter(schema InputSchema) *schemaFormatter {
+	sf := &schemaFormatter{
+		schema:            schema.SchemaString,
+		tokenIndexByStart: map[int]int{},
+		tokenIndexByEnd:   map[int]int{},
+	}
+
+	lx := lexer.NewPeekableLexer(lexer.Lex(schema.Source, schema.SchemaString))
+	defer lx.Close()
+
+	for {
+		token := lx.NextToken()
+		switch token.Kind {
+		case lexer.TokenTypeEOF, lexer.TokenTypeError:
+			return sf
+
+		case lexer.TokenTypeSinglelineComment, lexer.TokenTypeMultilineComment:
+			sf.comments = append(sf.comments, token)
+
+		case lexer.TokenTypeWhitespace, lexer.TokenTypeNewline, lexer.TokenTypeSyntheticSemicolon:
+			continue
+
+		default:
+			sf.tokenIndexByStart[int(token.Position)] = len(sf.tokens)
+			sf.tokenIndexByEnd[int(token.Position)+len(token.Value)-1] = len(sf.tokens)
+			sf.tokens = append(sf.tokens, token)
+		}
+	}
+}
+
+func (sf *schemaFormatter) write(value string) {
+	sf.buf.WriteString(value)
+	sf.lineOpen = true
+}
+
+// startLine terminates the current line and indents a new one for the element found at the
+// given position, preceding it by a blank line if one was found before it in the schema.
+func (sf *schemaFormatter) startLine(b *block, pos int) {
+	if sf.lineOpen {
+		sf.buf.WriteString("\n")
+	}
+
+	if b.hasLines && (b.forceBlank || strings.Count(sf.schema[b.prevEnd:pos], "\n") > 1) {
+		sf.buf.WriteString("\n")
+	}
+
+	b.forceBlank = false
+	b.hasLines = true
+	b.written = true
+	sf.write(strings.Repeat("\t", b.depth))
+}
+
+// emitComments emits the comments found before the given position. Comments found on the same
+// line as the element preceding them are kept at the end of its line.
+func (sf *schemaFormatter) emitComments(b *block, before int) {
+	for sf.nextComment < len(sf.comments) && int(sf.comments[sf.nextComment].Position) < before {
+		comment := sf.comments[sf.nextComment]
+		sf.nextComment++
+
+		pos := int(comment.Position)
+		if sf.lineOpen && b.prevEnd <= pos && !strings.Contains(sf.schema[b.prevEnd:pos], "\n") {
+			sf.write(" ")
+			b.written = true
+		} else {
+			sf.startLine(b, pos)
+		}
+
+		sf.write(sf.reindent(pos, strings.TrimRight(comment.Value, " \t\r"), b.depth))
+		b.prevEnd = pos + len(comment.Value)
+	}
+}
+
+// skipComments marks the comments found before the given position as emitted, as they are part
+// of source written as-is.
+func (sf *schemaFormatter) skipComments(before int) {
+	for sf.nextComment < len(sf.comments) && int(sf.comments[sf.nextComment].Position) < before {
+		sf.nextComment++
+	}
+}
+
+// hasComments returns whether any comment remaining to be emitted is found before the given
+// position.
+func (sf *schemaFormatter) hasComments(before int) bool {
+	return sf.nextComment < len(sf.comments) && int(sf.comments[sf.nextComment].Position) < before
+}
+
+// reindent returns the given text, found at the given position in the schema, with its lines
+// after the first moved from their original indentation to the given depth, keeping any further
+// indentation relative to the first line.
+func (sf *schemaFormatter) reindent(pos int, text string, depth int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return text
+	}
+
+	column := pos - (strings.LastIndex(sf.schema[:pos], "\n") + 1)
+	indentation := strings.Repeat("\t", depth)
+	for index, line := range lines[1:] {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			lines[index+1] = ""
+			continue
+		}
+
+		leading := len(line) - len(trimmed)
+		if leading > column {
+			trimmed = line[column:]
+		}
+		lines[index+1] = indentation + trimmed
+	}
+	return strings.Join(lines, "\n")
+}
+
+// verbatim returns the source of the node as-is, other than its indentation. It is used for
+// nodes containing comments, whose placement cannot otherwise be kept.
+func (sf *schemaFormatter) verbatim(start, end int, depth int) string {
+	sf.skipComments(end)
+	return sf.reindent(start, strings.TrimSpace(sf.schema[start:end]), depth)
+}
+
+func nodeRange(node *dslNode) (int, int, error) {
+	start, err := node.GetInt(dslshape.NodePredicateStartRune)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := node.GetInt(dslshape.NodePredicateEndRune)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end + 1, nil
+}
+
+// nonCommentChildren returns the children of the node, other than the comments decorated on it.
+func nonCommentChildren(node *dslNode) []*dslNode {
+	var children []*dslNode
+	for _, child := range node.GetChildren() {
+		if child.GetType() != dslshape.NodeTypeComment {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// emitTopLevel emits the imports, definitions, caveats and partials of the schema, each
+// separated by a blank line, other than consecutive imports.
+func (sf *schemaFormatter) emitTopLevel(root *dslNode) error {
+	b := &block{}
+	var previous *dslNode
+	for _, node := range nonCommentChildren(root) {
+		start, end, err := nodeRange(node)
+		if err != nil {
+			return err
+		}
+
+		b.forceBlank = previous != nil && (previous.GetType() != dslshape.NodeTypeImport || node.GetType() != dslshape.NodeTypeImport)
+		sf.emitComments(b, start)
+		sf.startLine(b, start)
+
+		switch node.GetType() {
+		case dslshape.NodeTypeImport:
+			err = sf.emitStatement(node, start, end, b.depth)
+
+		case dslshape.NodeTypeDefinition, dslshape.NodeTypePartial, dslshape.NodeTypeCaveatDefinition:
+			err = sf.emitBlockNode(node, start, end, b.depth)
+
+		default:
+			err = node.Errorf("unexpected node at root level: %v", node.GetType())
+		}
+		if err != nil {
+			return err
+		}
+
+		b.prevEnd = end
+		previous = node
+	}
+
+	sf.emitComments(b, len(sf.schema)+1)
+	if sf.lineOpen {
+		sf.buf.WriteString("\n")
+	}
+	return nil
+}
+
+// emitBlockNode emits a definition, partial or caveat, along with its body.
+func (sf *schemaFormatter) emitBlockNode(node *dslNode, start, end int, depth int) error {
+	// The body begins at the first brace, as none is found in the header.
+	braceIndex, ok := sf.tokenIndexByStart[start]
+	for ok && braceIndex < len(sf.tokens) && sf.tokens[braceIndex].Kind != lexer.TokenTypeLeftBrace {
+		braceIndex++
+	}
+	if !ok || braceIndex >= len(sf.tokens) {
+		return node.Errorf("missing body")
+	}
+	brace := int(sf.tokens[braceIndex].Position)
+
+	if sf.hasComments(brace) {
+		sf.write(sf.verbatim(start, brace, depth) + " {")
+	} else {
+		header, err := sf.header(node)
+		if err != nil {
+			return err
+		}
+		sf.write(header + " {")
+	}
+
+	b := &block{depth: depth + 1, prevEnd: brace + 1}
+	if node.GetType() == dslshape.NodeTypeCaveatDefinition {
+		if err := sf.emitCaveatExpression(node, b); err != nil {
+			return err
+		}
+	} else {
+		for _, child := range nonCommentChildren(node) {
+			childStart, childEnd, err := nodeRange(child)
+			if err != nil {
+				return err
+			}
+
+			sf.emitComments(b, childStart)
+			sf.startLine(b, childStart)
+			if err := sf.emitStatement(child, childStart, childEnd, b.depth); err != nil {
+				return err
+			}
+			b.prevEnd = childEnd
+		}
+	}
+
+	// }
+	sf.emitComments(b, end-1)
+	if b.written {
+		sf.buf.WriteString("\n" + strings.Repeat("\t", depth))
+	}
+	sf.write("}")
+	return nil
+}
+
+// emitCaveatExpression emits the CEL expression of a caveat, which is kept as-is other than its
+// indentation.
+func (sf *schemaFormatter) emitCaveatExpression(node *dslNode, b *block) error {
+	expressionNode, err := node.Lookup(dslshape.NodeCaveatDefinitionPredicateExpession)
+	if err != nil {
+		return err
+	}
+
+	start, end, err := nodeRange(expressionNode)
+	if err != nil {
+		return err
+	}
+
+	sf.emitComments(b, start)
+	sf.startLine(b, start)
+	sf.write(sf.verbatim(start, end, b.depth))
+	b.prevEnd = end
+	return nil
+}
+
+// header returns the header of a definition, partial or caveat, preceding its body.
+func (sf *schemaFormatter) header(node *dslNode) (string, error) {
+	switch node.GetType() {
+	case dslshape.NodeTypeDefinition:
+		name, err := node.GetString(dslshape.NodeDefinitionPredicateName)
+		if err != nil {
+			return "", err
+		}
+		return "definition " + name, nil
+
+	case dslshape.NodeTypePartial:
+		name, err := node.GetString(dslshape.NodePartialPredicateName)
+		if err != nil {
+			return "", err
+		}
+		return "partial " + name, nil
+
+	case dslshape.NodeTypeCaveatDefinition:
+		name, err := node.GetString(dslshape.NodeCaveatDefinitionPredicateName)
+		if err != nil {
+			return "", err
+		}
+
+		parameters := make([]string, 0, len(node.List(dslshape.NodeCaveatDefinitionPredicateParameters)))
+		for _, parameterNode := range node.List(dslshape.NodeCaveatDefinitionPredicateParameters) {
+			parameterName, err := parameterNode.GetString(dslshape.NodeCaveatParameterPredicateName)
+			if err != nil {
+				return "", err
+			}
+
+			typeRefNode, err := parameterNode.Lookup(dslshape.NodeCaveatParameterPredicateType)
+			if err != nil {
+				return "", err
+			}
+
+			typeRef, err := formatCaveatTypeReference(typeRefNode)
+			if err != nil {
+				return "", err
+			}
+
+			parameters = append(parameters, parameterName+" "+typeRef)
+		}
+		return fmt.Sprintf("caveat %s(%s)", name, strings.Join(parameters, ", ")), nil
+
+	default:
+		return "", node.Errorf("unexpected node with body: %v", node.GetType())
+	}
+}
+
+func formatCaveatTypeReference(typeRefNode *dslNode) (string, error) {
+	typeName, err := typeRefNode.GetString(dslshape.NodeCaveatTypeReferencePredicateType)
+	if err != nil {
+		return "", err
+	}
+
+	childTypeNodes := typeRefNode.List(dslshape.NodeCaveatTypeReferencePredicateChildTypes)
+	if len(childTypeNodes) == 0 {
+		return typeName, nil
+	}
+
+	childTypes := make([]string, 0, len(childTypeNodes))
+	for _, childTypeNode := range childTypeNodes {
+		childType, err := formatCaveatTypeReference(childTypeNode)
+		if err != nil {
+			return "", err
+		}
+		childTypes = append(childTypes, childType)
+	}
+	return typeName + "<" + strings.Join(childTypes, ", ") + ">", nil
+}
+
+// emitStatement emits an import, relation, permission or partial reference.
+func (sf *schemaFormatter) emitStatement(node *dslNode, start, end int, depth int) error {
+	if sf.hasComments(end) {
+		sf.write(sf.verbatim(start, end, depth))
+		return nil
+	}
+
+	statement, err := sf.statement(node)
+	if err != nil {
+		return err
+	}
+
+	sf.write(statement)
+	return nil
+}
+
+func (sf *schemaFormatter) statement(node *dslNode) (string, error) {
+	switch node.GetType() {
+	case dslshape.NodeTypeImport:
+		importPath, err := node.GetString(dslshape.NodeImportPredicatePath)
+		if err != nil {
+			return "", err
+		}
+		return "import " + strconv.Quote(importPath), nil
+
+	case dslshape.NodeTypePartialReference:
+		partialName, err := node.GetString(dslshape.NodePartialReferencePredicateName)
+		if err != nil {
+			return "", err
+		}
+		return "..." + partialName, nil
+
+	case dslshape.NodeTypeRelation:
+		relationName, err := node.GetString(dslshape.NodePredicateName)
+		if err != nil {
+			return "", err
+		}
+
+		typeRefNode, err := node.Lookup(dslshape.NodeRelationPredicateAllowedTypes)
+		if err != nil {
+			return "", err
+		}
+
+		allowedTypes := make([]string, 0, len(typeRefNode.List(dslshape.NodeTypeReferencePredicateType)))
+		for _, specificTypeNode := range typeRefNode.List(dslshape.NodeTypeReferencePredicateType) {
+			allowedType, err := formatSpecificType(specificTypeNode)
+			if err != nil {
+				return "", err
+			}
+			allowedTypes = append(allowedTypes, allowedType)
+		}
+		return fmt.Sprintf("relation %s: %s", relationName, strings.Join(allowedTypes, " | ")), nil
+
+	case dslshape.NodeTypePermission:
+		permissionName, err := node.GetString(dslshape.NodePredicateName)
+		if err != nil {
+			return "", err
+		}
+
+		expressionNode, err := node.Lookup(dslshape.NodePermissionPredicateComputeExpression)
+		if err != nil {
+			return "", err
+		}
+
+		expression, _, _, err := sf.expression(expressionNode, false)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("permission %s = %s", permissionName, expression), nil
+
+	default:
+		return "", node.Errorf("unexpected node in definition: %v", node.GetType())
+	}
+}
+
+func formatSpecificType(specificTypeNode *dslNode) (string, error) {
+	formatted, err := specificTypeNode.GetString(dslshape.NodeSpecificReferencePredicateType)
+	if err != nil {
+		return "", err
+	}
+
+	if specificTypeNode.Has(dslshape.NodeSpecificReferencePredicateWildcard) {
+		formatted += ":*"
+	}
+
+	if specificTypeNode.Has(dslshape.NodeSpecificReferencePredicateRelation) {
+		relationName, err := specificTypeNode.GetString(dslshape.NodeSpecificReferencePredicateRelation)
+		if err != nil {
+			return "", err
+		}
+		formatted += "#" + relationName
+	}
+
+	if caveatNode, err := specificTypeNode.Lookup(dslshape.NodeSpecificReferencePredicateCaveat); err == nil {
+		caveatName, err := caveatNode.GetString(dslshape.NodeCaveatPredicateCaveat)
+		if err != nil {
+			return "", err
+		}
+		formatted += " with " + caveatName
+	}
+
+	return formatted, nil
+}
+
+// expression returns the formatted permission expression, along with the indexes of its first
+// and last tokens, including any parentheses around it. As the parse tree does not hold
+// parentheses, those found around each expression in the schema are kept, with a single pair
+// kept for expressions found within more than one. isFunctionArgument indicates that the
+// expression is the argument of an arrow function, whose parentheses are its own.
+func (sf *schemaFormatter) expression(node *dslNode, isFunctionArgument bool) (string, int, int, error) {
+	var formatted string
+	var first, last int
+	switch node.GetType() {
+	case dslshape.NodeTypeIdentifier, dslshape.NodeTypeNilExpression:
+		formatted = "nil"
+		if node.GetType() == dslshape.NodeTypeIdentifier {
+			identifier, err := node.GetString(dslshape.NodeIdentiferPredicateValue)
+			if err != nil {
+				return "", 0, 0, err
+			}
+			formatted = identifier
+		}
+
+		start, end, err := nodeRange(node)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		var startOk, endOk bool
+		first, startOk = sf.tokenIndexByStart[start]
+		last, endOk = sf.tokenIndexByEnd[end-1]
+		if !startOk || !endOk {
+			return "", 0, 0, node.Errorf("missing tokens for expression")
+		}
+
+	case dslshape.NodeTypeUnionExpression, dslshape.NodeTypeIntersectExpression, dslshape.NodeTypeExclusionExpression, dslshape.NodeTypeArrowExpression:
+		leftNode, err := node.Lookup(dslshape.NodeExpressionPredicateLeftExpr)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		rightNode, err := node.Lookup(dslshape.NodeExpressionPredicateRightExpr)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		functionName := ""
+		if node.Has(dslshape.NodeArrowExpressionFunctionName) {
+			functionName, err = node.GetString(dslshape.NodeArrowExpressionFunctionName)
+			if err != nil {
+				return "", 0, 0, err
+			}
+		}
+
+		left, leftFirst, _, err := sf.expression(leftNode, false)
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		right, _, rightLast, err := sf.expression(rightNode, functionName != "")
+		if err != nil {
+			return "", 0, 0, err
+		}
+
+		first, last = leftFirst, rightLast
+		switch {
+		case node.GetType() == dslshape.NodeTypeUnionExpression:
+			formatted = left + " + " + right
+		case node.GetType() == dslshape.NodeTypeIntersectExpression:
+			formatted = left + " & " + right
+		case node.GetType() == dslshape.NodeTypeExclusionExpression:
+			formatted = left + " - " + right
+		case functionName != "":
+			formatted = left + "." + functionName + "(" + right + ")"
+			last++
+		default:
+			formatted = left + "->" + right
+		}
+
+	default:
+		return "", 0, 0, node.Errorf("unexpected node in expression: %v", node.GetType())
+	}
+
+	// As expressions are balanced, parentheses found directly around one are a matching pair.
+	pairs := 0
+	for first-pairs > 0 && last+pairs+1 < len(sf.tokens) &&
+		sf.tokens[first-pairs-1].Kind == lexer.TokenTypeLeftParen &&
+		sf.tokens[last+pairs+1].Kind == lexer.TokenTypeRightParen {
+		pairs++
+	}
+
+	if isFunctionArgument {
+		pairs--
+	}
+	if pairs > 0 {
+		formatted = "(" + formatted + ")"
+	}
+	return formatted, first - pairs, last + pairs, nil
+}
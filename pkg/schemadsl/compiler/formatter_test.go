@@ -0,0 +1,261 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestFormatSchema(t *testing.T) {
+	type formatTest struct {
+		name          string
+		input         string
+		expected      string
+		expectedError string
+	}
+
+	tests := []formatTest{
+		{
+			"empty",
+			"  \n\n",
+			"",
+			"",
+		},
+		{
+			"parse error",
+			"definition user {",
+			"",
+			"parse error in `test`, line 1, column 1: Expected end of statement or definition, found: TokenTypeError",
+		},
+		{
+			"normalizes spacing and indentation",
+			"definition user{}   definition  document {\nrelation viewer :user|  group#member |user:*\n        permission view=viewer+  edit - banned & allowed\n}",
+			`definition user {}
+
+definition document {
+	relation viewer: user | group#member | user:*
+	permission view = viewer + edit - banned & allowed
+}
+`,
+			"",
+		},
+		{
+			"keeps order of definitions and caveats",
+			`definition zed {}
+caveat is_allowed(allowed bool, tags list<string>, attrs map<any>) {
+  allowed && 'x' in tags
+}
+definition abc {
+	relation viewer: zed with is_allowed
+}`,
+			`definition zed {}
+
+caveat is_allowed(allowed bool, tags list<string>, attrs map<any>) {
+	allowed && 'x' in tags
+}
+
+definition abc {
+	relation viewer: zed with is_allowed
+}
+`,
+			"",
+		},
+		{
+			"keeps caveat expression layout",
+			`caveat multiline(a int, b int) {
+        a > 1 &&
+          // b must be small
+          b < 2
+}`,
+			`caveat multiline(a int, b int) {
+	a > 1 &&
+	  // b must be small
+	  b < 2
+}
+`,
+			"",
+		},
+		{
+			"keeps all comments",
+			`// leading comment
+
+/**
+ * user is a user
+ */
+definition user {} // trailing user
+
+definition document { // trailing brace
+    // viewer comment
+    relation viewer: user /* inline */ // and another
+
+    /* dangling at end */
+}
+
+// trailing comment at end of file`,
+			`// leading comment
+
+/**
+ * user is a user
+ */
+definition user {} // trailing user
+
+definition document { // trailing brace
+	// viewer comment
+	relation viewer: user /* inline */ // and another
+
+	/* dangling at end */
+}
+
+// trailing comment at end of file
+`,
+			"",
+		},
+		{
+			"reindents doc comments",
+			`definition document {
+        /**
+         * viewer can view
+         */
+        relation viewer: user
+}`,
+			`definition document {
+	/**
+	 * viewer can view
+	 */
+	relation viewer: user
+}
+`,
+			"",
+		},
+		{
+			"keeps blank line grouping",
+			`definition document {
+
+
+	relation viewer: user
+	relation editor: user
+
+
+
+	permission view = viewer + editor
+	permission edit = editor
+
+
+}`,
+			`definition document {
+	relation viewer: user
+	relation editor: user
+
+	permission view = viewer + editor
+	permission edit = editor
+}
+`,
+			"",
+		},
+		{
+			"separates definitions",
+			`definition user {}
+// the document
+definition document {}
+definition folder {}`,
+			`definition user {}
+
+// the document
+definition document {}
+
+definition folder {}
+`,
+			"",
+		},
+		{
+			"keeps parentheses",
+			`definition document {
+	permission view = ((viewer + editor)) & (allowed)
+	permission edit = (editor - banned) + owner
+	permission arrows = parent->view + org.any(admin) + (team.all(member))
+	permission nested = (a + (b & c)) - nil
+}`,
+			`definition document {
+	permission view = (viewer + editor) & (allowed)
+	permission edit = (editor - banned) + owner
+	permission arrows = parent->view + org.any(admin) + (team.all(member))
+	permission nested = (a + (b & c)) - nil
+}
+`,
+			"",
+		},
+		{
+			"keeps statements with interior comments",
+			`definition document {
+  permission view = viewer + // viewers
+    editor
+	relation editor: user /* users */ | group#member
+}`,
+			`definition document {
+	permission view = viewer + // viewers
+	  editor
+	relation editor: user /* users */ | group#member
+}
+`,
+			"",
+		},
+		{
+			"imports and partials",
+			`import "common/user.zed"
+import    "common/group.zed"
+partial viewable {
+  relation viewer: user
+}
+definition document {
+  ...viewable; relation editor: user
+}`,
+			`import "common/user.zed"
+import "common/group.zed"
+
+partial viewable {
+	relation viewer: user
+}
+
+definition document {
+	...viewable
+	relation editor: user
+}
+`,
+			"",
+		},
+		{
+			"empty bodies with comments",
+			`definition user {
+	// nothing yet
+}`,
+			`definition user {
+	// nothing yet
+}
+`,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			formatted, err := FormatSchema(InputSchema{input.Source("test"), test.input})
+			if test.expectedError != "" {
+				require.EqualError(err, test.expectedError)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(test.expected, formatted)
+
+			// Formatting is idempotent.
+			reformatted, err := FormatSchema(InputSchema{input.Source("test"), formatted})
+			require.NoError(err)
+			require.Equal(formatted, reformatted)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package compiler
+
+import (
+	"io/fs"
+	"path"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemadsl/parser"
+)
+
+// FileLoader loads the contents of the schema files referenced by import directives.
+type FileLoader interface {
+	// LoadFile returns the contents of the schema file at the given slash-separated path,
+	// relative to the directory containing the schema being compiled.
+	LoadFile(path string) (string, error)
+}
+
+// FileLoaderFunc adapts a function into a FileLoader.
+type FileLoaderFunc func(path string) (string, error)
+
+// LoadFile implements FileLoader.
+func (f FileLoaderFunc) LoadFile(path string) (string, error) {
+	return f(path)
+}
+
+// NewFSFileLoader returns a FileLoader which reads the schema files from the file system,
+// such as one returned by os.DirFS for the directory containing the schema being compiled.
+func NewFSFileLoader(fsys fs.FS) FileLoader {
+	return FileLoaderFunc(func(path string) (string, error) {
+		contents, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return "", err
+		}
+		return string(contents), nil
+	})
+}
+
+// importResolver loads and parses the files imported by a schema.
+type importResolver struct {
+	loader FileLoader
+	mapper *positionMapper
+	loaded map[string]struct{}
+}
+
+// topLevelNodes returns the top-level nodes of the parsed file, with each import directive
+// replaced by the top-level nodes of the file it imports. Import paths are resolved relative
+// to the directory of the importing file, whose path is given relative to the root schema.
+// A file which has already been imported is not imported again, which also allows files to
+// import one another.
+func (ir *importResolver) topLevelNodes(fileNode *dslNode, filePath string) ([]*dslNode, error) {
+	var nodes []*dslNode
+	for _, node := range fileNode.GetChildren() {
+		if node.GetType() != dslshape.NodeTypeImport {
+			nodes = append(nodes, node)
+			continue
+		}
+
+		importPath, err := node.GetString(dslshape.NodeImportPredicatePath)
+		if err != nil {
+			return nil, node.Errorf("invalid import path: %w", err)
+		}
+
+		if ir.loader == nil {
+			return nil, node.ErrorWithSourcef(importPath, "cannot import `%s`: imports are not supported when compiling a single schema", importPath)
+		}
+
+		resolvedPath := path.Join(path.Dir(filePath), importPath)
+		if !fs.ValidPath(resolvedPath) {
+			return nil, node.ErrorWithSourcef(importPath, "cannot import `%s`: imported files must be within the directory of the root schema", importPath)
+		}
+
+		if _, ok := ir.loaded[resolvedPath]; ok {
+			continue
+		}
+		ir.loaded[resolvedPath] = struct{}{}
+
+		contents, err := ir.loader.LoadFile(resolvedPath)
+		if err != nil {
+			return nil, node.ErrorWithSourcef(importPath, "could not load imported file `%s`: %w", resolvedPath, err)
+		}
+
+		source := input.Source(resolvedPath)
+		ir.mapper.addSource(source, contents)
+
+		importedNode := parser.Parse(createAstNode, source, contents).(*dslNode)
+		errs := importedNode.FindAll(dslshape.NodeTypeError)
+		if len(errs) > 0 {
+			return nil, errorNodeToError(errs[0], ir.mapper)
+		}
+
+		importedNodes, err := ir.topLevelNodes(importedNode, resolvedPath)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, importedNodes...)
+	}
+
+	return nodes, nil
+}
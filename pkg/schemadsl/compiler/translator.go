@@ -23,6 +23,8 @@ type translationContext struct {
 	objectTypePrefix *string
 	mapper           input.PositionMapper
 	schemaString     string
+	rootSource       input.Source
+	partials         map[string]*dslNode
 }
 
 func (tctx translationContext) prefixedPath(definitionName string) (string, error) {
@@ -44,17 +46,40 @@ func (tctx translationContext) prefixedPath(definitionName string) (string, erro
 
 const Ellipsis = "..."
 
-func translate(tctx translationContext, root *dslNode) (*CompiledSchema, error) {
-	orderedDefinitions := make([]SchemaDefinition, 0, len(root.GetChildren()))
+func translate(tctx translationContext, nodes []*dslNode) (*CompiledSchema, error) {
+	orderedDefinitions := make([]SchemaDefinition, 0, len(nodes))
 	var objectDefinitions []*core.NamespaceDefinition
 	var caveatDefinitions []*core.CaveatDefinition
 
+	// Partials are collected first, so that definitions may reference partials found later in
+	// the schema or in other files.
+	tctx.partials = map[string]*dslNode{}
+	for _, partialNode := range nodes {
+		if partialNode.GetType() != dslshape.NodeTypePartial {
+			continue
+		}
+
+		partialName, err := partialNode.GetString(dslshape.NodePartialPredicateName)
+		if err != nil {
+			return nil, partialNode.ErrorWithSourcef(partialName, "invalid partial name: %w", err)
+		}
+
+		if _, ok := tctx.partials[partialName]; ok {
+			return nil, partialNode.ErrorWithSourcef(partialName, "found name reused between multiple partials: %s", partialName)
+		}
+
+		tctx.partials[partialName] = partialNode
+	}
+
 	names := util.NewSet[string]()
 
-	for _, definitionNode := range root.GetChildren() {
+	for _, definitionNode := range nodes {
 		var definition SchemaDefinition
 
 		switch definitionNode.GetType() {
+		case dslshape.NodeTypePartial:
+			continue
+
 		case dslshape.NodeTypeCaveatDefinition:
 			def, err := translateCaveatDefinition(tctx, definitionNode)
 			if err != nil {
@@ -166,7 +191,7 @@ func translateCaveatDefinition(tctx translationContext, defNode *dslNode) (*core
 	}
 
 	def.Metadata = addComments(def.Metadata, defNode)
-	def.SourcePosition = getSourcePosition(defNode, tctx.mapper)
+	def.SourcePosition = getSourcePosition(tctx, defNode)
 	return def, nil
 }
 
@@ -200,18 +225,9 @@ func translateObjectDefinition(tctx translationContext, defNode *dslNode) (*core
 		return nil, defNode.ErrorWithSourcef(definitionName, "invalid definition name: %w", err)
 	}
 
-	relationsAndPermissions := []*core.Relation{}
-	for _, relationOrPermissionNode := range defNode.GetChildren() {
-		if relationOrPermissionNode.GetType() == dslshape.NodeTypeComment {
-			continue
-		}
-
-		relationOrPermission, err := translateRelationOrPermission(tctx, relationOrPermissionNode)
-		if err != nil {
-			return nil, err
-		}
-
-		relationsAndPermissions = append(relationsAndPermissions, relationOrPermission)
+	relationsAndPermissions, err := translateRelationsAndPermissions(tctx, defNode, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	nspath, err := tctx.prefixedPath(definitionName)
@@ -233,7 +249,7 @@ func translateObjectDefinition(tctx translationContext, defNode *dslNode) (*core
 
 	ns := namespace.Namespace(nspath, relationsAndPermissions...)
 	ns.Metadata = addComments(ns.Metadata, defNode)
-	ns.SourcePosition = getSourcePosition(defNode, tctx.mapper)
+	ns.SourcePosition = getSourcePosition(tctx, defNode)
 
 	err = ns.Validate()
 	if err != nil {
@@ -243,12 +259,63 @@ func translateObjectDefinition(tctx translationContext, defNode *dslNode) (*core
 	return ns, nil
 }
 
-func getSourcePosition(dslNode *dslNode, mapper input.PositionMapper) *core.SourcePosition {
+// translateRelationsAndPermissions translates the relations and permissions of a definition or
+// partial, splicing in those of the partials it references. The partials currently being
+// spliced are given, so that partials referencing themselves can be reported.
+func translateRelationsAndPermissions(tctx translationContext, defNode *dslNode, splicing []string) ([]*core.Relation, error) {
+	relationsAndPermissions := []*core.Relation{}
+	for _, childNode := range defNode.GetChildren() {
+		switch childNode.GetType() {
+		case dslshape.NodeTypeComment:
+			continue
+
+		case dslshape.NodeTypePartialReference:
+			spliced, err := translatePartialReference(tctx, childNode, splicing)
+			if err != nil {
+				return nil, err
+			}
+
+			relationsAndPermissions = append(relationsAndPermissions, spliced...)
+
+		default:
+			relationOrPermission, err := translateRelationOrPermission(tctx, childNode)
+			if err != nil {
+				return nil, err
+			}
+
+			relationsAndPermissions = append(relationsAndPermissions, relationOrPermission)
+		}
+	}
+
+	return relationsAndPermissions, nil
+}
+
+func translatePartialReference(tctx translationContext, refNode *dslNode, splicing []string) ([]*core.Relation, error) {
+	partialName, err := refNode.GetString(dslshape.NodePartialReferencePredicateName)
+	if err != nil {
+		return nil, refNode.ErrorWithSourcef(partialName, "invalid partial reference: %w", err)
+	}
+
+	partialNode, ok := tctx.partials[partialName]
+	if !ok {
+		return nil, refNode.ErrorWithSourcef(partialName, "partial `%s` not found", partialName)
+	}
+
+	for _, name := range splicing {
+		if name == partialName {
+			return nil, refNode.ErrorWithSourcef(partialName, "partial `%s` references itself via %s", partialName, strings.Join(append(splicing, partialName), " -> "))
+		}
+	}
+
+	return translateRelationsAndPermissions(tctx, partialNode, append(splicing[:len(splicing):len(splicing)], partialName))
+}
+
+func getSourcePosition(tctx translationContext, dslNode *dslNode) *core.SourcePosition {
 	if !dslNode.Has(dslshape.NodePredicateStartRune) {
 		return nil
 	}
 
-	sourceRange, err := dslNode.Range(mapper)
+	sourceRange, err := dslNode.Range(tctx.mapper)
 	if err != nil {
 		return nil
 	}
@@ -258,9 +325,15 @@ func getSourcePosition(dslNode *dslNode, mapper input.PositionMapper) *core.Sour
 		return nil
 	}
 
+	var sourceFile string
+	if sourceRange.Source() != tctx.rootSource {
+		sourceFile = string(sourceRange.Source())
+	}
+
 	return &core.SourcePosition{
 		ZeroIndexedLineNumber:     uint64(line),
 		ZeroIndexedColumnPosition: uint64(col),
+		SourceFile:                sourceFile,
 	}
 }
 
@@ -294,7 +367,7 @@ func translateRelationOrPermission(tctx translationContext, relOrPermNode *dslNo
 			return nil, err
 		}
 		rel.Metadata = addComments(rel.Metadata, relOrPermNode)
-		rel.SourcePosition = getSourcePosition(relOrPermNode, tctx.mapper)
+		rel.SourcePosition = getSourcePosition(tctx, relOrPermNode)
 		return rel, err
 
 	case dslshape.NodeTypePermission:
@@ -303,7 +376,7 @@ func translateRelationOrPermission(tctx translationContext, relOrPermNode *dslNo
 			return nil, err
 		}
 		rel.Metadata = addComments(rel.Metadata, relOrPermNode)
-		rel.SourcePosition = getSourcePosition(relOrPermNode, tctx.mapper)
+		rel.SourcePosition = getSourcePosition(tctx, relOrPermNode)
 		return rel, err
 
 	default:
@@ -399,7 +472,7 @@ func translateExpression(tctx translationContext, expressionNode *dslNode) (*cor
 		return translated, err
 	}
 
-	translated.SourcePosition = getSourcePosition(expressionNode, tctx.mapper)
+	translated.SourcePosition = getSourcePosition(tctx, expressionNode)
 	return translated, nil
 }
 
@@ -473,7 +546,7 @@ func translateExpressionOperation(tctx translationContext, expressionOpNode *dsl
 		return translated, err
 	}
 
-	translated.SourcePosition = getSourcePosition(expressionOpNode, tctx.mapper)
+	translated.SourcePosition = getSourcePosition(tctx, expressionOpNode)
 	return translated, nil
 }
 
@@ -515,6 +588,18 @@ func translateExpressionOperationDirect(tctx translationContext, expressionOpNod
 			return nil, err
 		}
 
+		// `.any` is the same as an arrow, so only `.all` requires a functioned arrow.
+		if expressionOpNode.Has(dslshape.NodeArrowExpressionFunctionName) {
+			functionName, err := expressionOpNode.GetString(dslshape.NodeArrowExpressionFunctionName)
+			if err != nil {
+				return nil, err
+			}
+
+			if functionName == "all" {
+				return namespace.MustFunctionedTupleToUserset(tuplesetRelation, functionName, usersetRelation), nil
+			}
+		}
+
 		return namespace.TupleToUserset(tuplesetRelation, usersetRelation), nil
 
 	case dslshape.NodeTypeUnionExpression:
@@ -590,7 +675,7 @@ func translateSpecificTypeReference(tctx translationContext, typeRefNode *dslNod
 			return nil, typeRefNode.Errorf("invalid type relation: %w", err)
 		}
 
-		ref.SourcePosition = getSourcePosition(typeRefNode, tctx.mapper)
+		ref.SourcePosition = getSourcePosition(tctx, typeRefNode)
 		return ref, nil
 	}
 
@@ -619,7 +704,7 @@ func translateSpecificTypeReference(tctx translationContext, typeRefNode *dslNod
 		return nil, typeRefNode.Errorf("invalid type relation: %w", err)
 	}
 
-	ref.SourcePosition = getSourcePosition(typeRefNode, tctx.mapper)
+	ref.SourcePosition = getSourcePosition(tctx, typeRefNode)
 	return ref, nil
 }
 
@@ -14,6 +14,9 @@ const (
 
 	NodeTypeDefinition       // A definition.
 	NodeTypeCaveatDefinition // A caveat definition.
+	NodeTypeImport           // An import of another schema file.
+	NodeTypePartial          // A partial definition.
+	NodeTypePartialReference // A reference to a partial, spliced into a definition.
 
 	NodeTypeCaveatParameter // A caveat parameter.
 	NodeTypeCaveatExpession // A caveat expression.
@@ -78,6 +81,27 @@ const (
 	// The name of the definition
 	NodeDefinitionPredicateName = "definition-name"
 
+	//
+	// NodeTypeImport
+	//
+
+	// The path of the imported file, relative to the importing file.
+	NodeImportPredicatePath = "import-path"
+
+	//
+	// NodeTypePartial
+	//
+
+	// The name of the partial
+	NodePartialPredicateName = "partial-name"
+
+	//
+	// NodeTypePartialReference
+	//
+
+	// The name of the partial being referenced.
+	NodePartialReferencePredicateName = "partial-reference-name"
+
 	//
 	// NodeTypeCaveatDefinition
 	//
@@ -176,6 +200,13 @@ const (
 	// The value of the identifier.
 	NodeIdentiferPredicateValue = "identifier-value"
 
+	//
+	// NodeTypeArrowExpression
+	//
+
+	// The name of the function applied by the arrow, if any.
+	NodeArrowExpressionFunctionName = "function-name"
+
 	//
 	// NodeTypeUnionExpression + NodeTypeIntersectExpression + NodeTypeExclusionExpression + NodeTypeArrowExpression
 	//
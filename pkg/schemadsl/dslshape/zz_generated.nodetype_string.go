@@ -13,25 +13,28 @@ func _() {
 	_ = x[NodeTypeComment-2]
 	_ = x[NodeTypeDefinition-3]
 	_ = x[NodeTypeCaveatDefinition-4]
-	_ = x[NodeTypeCaveatParameter-5]
-	_ = x[NodeTypeCaveatExpession-6]
-	_ = x[NodeTypeRelation-7]
-	_ = x[NodeTypePermission-8]
-	_ = x[NodeTypeTypeReference-9]
-	_ = x[NodeTypeSpecificTypeReference-10]
-	_ = x[NodeTypeCaveatReference-11]
-	_ = x[NodeTypeUnionExpression-12]
-	_ = x[NodeTypeIntersectExpression-13]
-	_ = x[NodeTypeExclusionExpression-14]
-	_ = x[NodeTypeArrowExpression-15]
-	_ = x[NodeTypeIdentifier-16]
-	_ = x[NodeTypeNilExpression-17]
-	_ = x[NodeTypeCaveatTypeReference-18]
+	_ = x[NodeTypeImport-5]
+	_ = x[NodeTypePartial-6]
+	_ = x[NodeTypePartialReference-7]
+	_ = x[NodeTypeCaveatParameter-8]
+	_ = x[NodeTypeCaveatExpession-9]
+	_ = x[NodeTypeRelation-10]
+	_ = x[NodeTypePermission-11]
+	_ = x[NodeTypeTypeReference-12]
+	_ = x[NodeTypeSpecificTypeReference-13]
+	_ = x[NodeTypeCaveatReference-14]
+	_ = x[NodeTypeUnionExpression-15]
+	_ = x[NodeTypeIntersectExpression-16]
+	_ = x[NodeTypeExclusionExpression-17]
+	_ = x[NodeTypeArrowExpression-18]
+	_ = x[NodeTypeIdentifier-19]
+	_ = x[NodeTypeNilExpression-20]
+	_ = x[NodeTypeCaveatTypeReference-21]
 }
 
-const _NodeType_name = "NodeTypeErrorNodeTypeFileNodeTypeCommentNodeTypeDefinitionNodeTypeCaveatDefinitionNodeTypeCaveatParameterNodeTypeCaveatExpessionNodeTypeRelationNodeTypePermissionNodeTypeTypeReferenceNodeTypeSpecificTypeReferenceNodeTypeCaveatReferenceNodeTypeUnionExpressionNodeTypeIntersectExpressionNodeTypeExclusionExpressionNodeTypeArrowExpressionNodeTypeIdentifierNodeTypeNilExpressionNodeTypeCaveatTypeReference"
+const _NodeType_name = "NodeTypeErrorNodeTypeFileNodeTypeCommentNodeTypeDefinitionNodeTypeCaveatDefinitionNodeTypeImportNodeTypePartialNodeTypePartialReferenceNodeTypeCaveatParameterNodeTypeCaveatExpessionNodeTypeRelationNodeTypePermissionNodeTypeTypeReferenceNodeTypeSpecificTypeReferenceNodeTypeCaveatReferenceNodeTypeUnionExpressionNodeTypeIntersectExpressionNodeTypeExclusionExpressionNodeTypeArrowExpressionNodeTypeIdentifierNodeTypeNilExpressionNodeTypeCaveatTypeReference"
 
-var _NodeType_index = [...]uint16{0, 13, 25, 40, 58, 82, 105, 128, 144, 162, 183, 212, 235, 258, 285, 312, 335, 353, 374, 401}
+var _NodeType_index = [...]uint16{0, 13, 25, 40, 58, 82, 96, 111, 135, 158, 181, 197, 215, 236, 265, 288, 311, 338, 365, 388, 406, 427, 454}
 
 func (i NodeType) String() string {
 	if i < 0 || i >= NodeType(len(_NodeType_index)-1) {
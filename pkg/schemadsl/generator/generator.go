@@ -286,6 +286,20 @@ func (sg *sourceGenerator) emitSetOpChild(setOpChild *core.SetOperation_Child) {
 		sg.append(child.TupleToUserset.Tupleset.Relation)
 		sg.append("->")
 		sg.append(child.TupleToUserset.ComputedUserset.Relation)
+
+	case *core.SetOperation_Child_FunctionedTupleToUserset:
+		sg.append(child.FunctionedTupleToUserset.Tupleset.Relation)
+		switch child.FunctionedTupleToUserset.Function {
+		case core.FunctionedTupleToUserset_FUNCTION_ANY:
+			sg.append(".any(")
+		case core.FunctionedTupleToUserset_FUNCTION_ALL:
+			sg.append(".all(")
+		default:
+			sg.appendIssue("unknown function for arrow")
+			return
+		}
+		sg.append(child.FunctionedTupleToUserset.ComputedUserset.Relation)
+		sg.append(")")
 	}
 }
 
@@ -151,6 +151,19 @@ func TestGenerateNamespace(t *testing.T) {
 			),
 			`definition foos/test {
 	permission someperm = (rela - relb - rely->relz - nil) + relc
+}`,
+			true,
+		},
+		{
+			"permission with functioned arrows",
+			namespace.Namespace("foos/test",
+				namespace.MustRelation("someperm", namespace.Union(
+					namespace.MustFunctionedTupleToUserset("rela", "all", "relb"),
+					namespace.MustFunctionedTupleToUserset("relc", "any", "reld"),
+				)),
+			),
+			`definition foos/test {
+	permission someperm = rela.all(relb) + relc.any(reld)
 }`,
 			true,
 		},
@@ -291,6 +304,18 @@ definition foos/test {
 }`,
 		},
 
+		{
+			"arrow functions",
+			`definition foos/test {
+				relation parent: foos/test
+				permission view = parent.all(view) + parent.any(view)
+			}`,
+			`definition foos/test {
+	relation parent: foos/test
+	permission view = parent.all(view) + parent->view
+}`,
+		},
+
 		{
 			"becomes single line comment",
 			`definition foos/test {
@@ -2,6 +2,9 @@
 package parser
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 	"github.com/authzed/spicedb/pkg/schemadsl/lexer"
@@ -49,9 +52,11 @@ Loop:
 			break Loop
 		}
 
-		// The top level of the DSL is a set of definitions and caveats:
+		// The top level of the DSL is a set of imports, definitions, caveats and partials:
+		// import "path/to/file.zed"
 		// definition foobar { ... }
 		// caveat somecaveat (...) { ... }
+		// partial somepartial { ... }
 
 		switch {
 		case p.isKeyword("definition"):
@@ -60,6 +65,12 @@ Loop:
 		case p.isKeyword("caveat"):
 			rootNode.Connect(dslshape.NodePredicateChild, p.consumeCaveat())
 
+		case p.isContextualKeyword("import"):
+			rootNode.Connect(dslshape.NodePredicateChild, p.consumeImport())
+
+		case p.isContextualKeyword("partial"):
+			rootNode.Connect(dslshape.NodePredicateChild, p.consumePartial())
+
 		default:
 			p.emitErrorf("Unexpected token at root level: %v", p.currentToken.Kind)
 			break Loop
@@ -243,14 +254,40 @@ func (p *sourceParser) consumeDefinition() AstNode {
 	}
 
 	defNode.MustDecorate(dslshape.NodeDefinitionPredicateName, definitionName)
+	p.consumeDefinitionBody(defNode)
+	return defNode
+}
+
+// consumePartial attempts to consume a partial definition, whose relations and permissions can
+// be spliced into other definitions.
+// ```partial somepartial { ... }```
+func (p *sourceParser) consumePartial() AstNode {
+	partialNode := p.startNode(dslshape.NodeTypePartial)
+	defer p.mustFinishNode()
+
+	// partial ...
+	p.consumeIdentifier()
+	partialName, ok := p.consumeIdentifier()
+	if !ok {
+		return partialNode
+	}
+
+	partialNode.MustDecorate(dslshape.NodePartialPredicateName, partialName)
+	p.consumeDefinitionBody(partialNode)
+	return partialNode
+}
 
+// consumeDefinitionBody consumes the braced body of a definition or partial, connecting its
+// relations, permissions and partial references to the given node.
+// ```{ relation foo: bar; permission baz = foo; ...somepartial }```
+func (p *sourceParser) consumeDefinitionBody(defNode AstNode) {
 	// {
-	_, ok = p.consume(lexer.TokenTypeLeftBrace)
+	_, ok := p.consume(lexer.TokenTypeLeftBrace)
 	if !ok {
-		return defNode
+		return
 	}
 
-	// Relations and permissions.
+	// Relations, permissions and partial references.
 	for {
 		// }
 		if _, ok := p.tryConsume(lexer.TokenTypeRightBrace); ok {
@@ -259,12 +296,16 @@ func (p *sourceParser) consumeDefinition() AstNode {
 
 		// relation ...
 		// permission ...
+		// ...somepartial
 		switch {
 		case p.isKeyword("relation"):
 			defNode.Connect(dslshape.NodePredicateChild, p.consumeRelation())
 
 		case p.isKeyword("permission"):
 			defNode.Connect(dslshape.NodePredicateChild, p.consumePermission())
+
+		case p.isToken(lexer.TokenTypeEllipsis):
+			defNode.Connect(dslshape.NodePredicateChild, p.consumePartialReference())
 		}
 
 		ok := p.consumeStatementTerminator()
@@ -272,8 +313,46 @@ func (p *sourceParser) consumeDefinition() AstNode {
 			break
 		}
 	}
+}
 
-	return defNode
+// consumePartialReference consumes a reference to a partial.
+// ```...somepartial```
+func (p *sourceParser) consumePartialReference() AstNode {
+	refNode := p.startNode(dslshape.NodeTypePartialReference)
+	defer p.mustFinishNode()
+
+	// ...
+	p.consume(lexer.TokenTypeEllipsis)
+	partialName, ok := p.consumeIdentifier()
+	if !ok {
+		return refNode
+	}
+
+	refNode.MustDecorate(dslshape.NodePartialReferencePredicateName, partialName)
+	return refNode
+}
+
+// consumeImport attempts to consume an import of another schema file.
+// ```import "path/to/file.zed"```
+func (p *sourceParser) consumeImport() AstNode {
+	importNode := p.startNode(dslshape.NodeTypeImport)
+	defer p.mustFinishNode()
+
+	// import ...
+	p.consumeIdentifier()
+	pathToken, ok := p.consume(lexer.TokenTypeString)
+	if !ok {
+		return importNode
+	}
+
+	importPath, err := strconv.Unquote(pathToken.Value)
+	if err != nil || !strings.HasPrefix(pathToken.Value, `"`) || importPath == "" {
+		p.emitErrorf("Expected a non-empty double-quoted import path, found: %s", pathToken.Value)
+		return importNode
+	}
+
+	importNode.MustDecorate(dslshape.NodeImportPredicatePath, importPath)
+	return importNode
 }
 
 // consumeRelation consumes a relation.
@@ -471,22 +550,50 @@ func (p *sourceParser) tryConsumeComputeExpression(subTryExprFn tryParserFn, bin
 	return p.performLeftRecursiveParsing(subTryExprFn, rightNodeBuilder, nil, binaryTokenType)
 }
 
-// tryConsumeArrowExpression attempts to consume an arrow expression.
+// tryConsumeArrowExpression attempts to consume an arrow expression, either in arrow form or
+// applying a function over the arrow.
 // ```foo->bar->baz->meh```
+// ```foo.all(bar)```
+// ```foo.any(bar)```
 func (p *sourceParser) tryConsumeArrowExpression() (AstNode, bool) {
 	rightNodeBuilder := func(leftNode AstNode, operatorToken lexer.Lexeme) (AstNode, bool) {
+		var functionName string
+		if operatorToken.Kind == lexer.TokenTypePeriod {
+			if !p.isContextualKeyword("any") && !p.isContextualKeyword("all") {
+				p.emitErrorf("Expected function name `any` or `all`, found: %s", p.currentToken.Value)
+				return nil, false
+			}
+
+			name, _ := p.consumeIdentifier()
+
+			if _, ok := p.consume(lexer.TokenTypeLeftParen); !ok {
+				return nil, false
+			}
+
+			functionName = name
+		}
+
 		rightNode, ok := p.tryConsumeBaseExpression()
 		if !ok {
 			return nil, false
 		}
 
+		if functionName != "" {
+			if _, ok := p.consume(lexer.TokenTypeRightParen); !ok {
+				return nil, false
+			}
+		}
+
 		// Create the expression node representing the binary expression.
 		exprNode := p.createNode(dslshape.NodeTypeArrowExpression)
 		exprNode.Connect(dslshape.NodeExpressionPredicateLeftExpr, leftNode)
 		exprNode.Connect(dslshape.NodeExpressionPredicateRightExpr, rightNode)
+		if functionName != "" {
+			exprNode.MustDecorate(dslshape.NodeArrowExpressionFunctionName, functionName)
+		}
 		return exprNode, true
 	}
-	return p.performLeftRecursiveParsing(p.tryConsumeIdentifierLiteral, rightNodeBuilder, nil, lexer.TokenTypeRightArrow)
+	return p.performLeftRecursiveParsing(p.tryConsumeIdentifierLiteral, rightNodeBuilder, nil, lexer.TokenTypeRightArrow, lexer.TokenTypePeriod)
 }
 
 // tryConsumeBaseExpression attempts to consume base compute expressions (identifiers, parenthesis).
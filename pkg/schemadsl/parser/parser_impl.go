@@ -168,6 +168,13 @@ func (p *sourceParser) isKeyword(keyword string) bool {
 	return p.isToken(lexer.TokenTypeKeyword) && p.currentToken.Value == keyword
 }
 
+// isContextualKeyword returns true if the current token is an identifier matching the keyword
+// given. Contextual keywords are only reserved where they are recognized by the parser, which
+// allows schemas to continue using them as names elsewhere.
+func (p *sourceParser) isContextualKeyword(keyword string) bool {
+	return p.isToken(lexer.TokenTypeIdentifier) && p.currentToken.Value == keyword
+}
+
 // emitErrorf creates a new error node and attachs it as a child of the current
 // node.
 func (p *sourceParser) emitErrorf(format string, args ...interface{}) {
@@ -113,6 +113,11 @@ func TestParser(t *testing.T) {
 		{"empty caveat test", "emptycaveat"},
 		{"unclosed caveat test", "unclosedcaveat"},
 		{"invalid caveat expr test", "invalidcaveatexpr"},
+		{"imports test", "imports"},
+		{"broken import test", "brokenimport"},
+		{"partials test", "partials"},
+		{"arrow functions test", "arrowfunctions"},
+		{"broken arrow function test", "brokenarrowfunction"},
 	}
 
 	for _, test := range parserTests {
@@ -0,0 +1,99 @@
+package tuple
+
+import (
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// relationshipExpirationField is the number of the `optional_expires_at` field of the
+// Relationship message in newer versions of the v1 API. The version of the API used here
+// predates the field, so it is read from and written to the unknown fields of the message,
+// which keeps relationships wire compatible with clients built against newer versions.
+//
+// NOTE: unknown fields are dropped when converting to and from JSON, so the expiration of a
+// relationship is not available through the HTTP gateway.
+const relationshipExpirationField protowire.Number = 5
+
+// RelationshipExpiration returns the expiration time set on the given relationship, or nil if
+// the relationship does not expire.
+func RelationshipExpiration(rel *v1.Relationship) (*timestamppb.Timestamp, error) {
+	var expiration *timestamppb.Timestamp
+	unknown := rel.ProtoReflect().GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid relationship: %w", protowire.ParseError(n))
+		}
+
+		if num != relationshipExpirationField {
+			m := protowire.ConsumeFieldValue(num, typ, unknown[n:])
+			if m < 0 {
+				return nil, fmt.Errorf("invalid relationship: %w", protowire.ParseError(m))
+			}
+			unknown = unknown[n+m:]
+			continue
+		}
+
+		if typ != protowire.BytesType {
+			return nil, fmt.Errorf("invalid relationship expiration: unexpected wire type %d", typ)
+		}
+
+		value, m := protowire.ConsumeBytes(unknown[n:])
+		if m < 0 {
+			return nil, fmt.Errorf("invalid relationship expiration: %w", protowire.ParseError(m))
+		}
+
+		// As with any message field, the last occurrence is merged into those before it.
+		if expiration == nil {
+			expiration = &timestamppb.Timestamp{}
+		}
+		if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(value, expiration); err != nil {
+			return nil, fmt.Errorf("invalid relationship expiration: %w", err)
+		}
+		unknown = unknown[n+m:]
+	}
+
+	if expiration != nil {
+		if err := expiration.CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid relationship expiration: %w", err)
+		}
+	}
+
+	return expiration, nil
+}
+
+// SetRelationshipExpiration sets the expiration time of the given relationship, replacing any
+// existing expiration. A nil expiration removes it.
+func SetRelationshipExpiration(rel *v1.Relationship, expiration *timestamppb.Timestamp) {
+	unknown := rel.ProtoReflect().GetUnknown()
+	retained := make([]byte, 0, len(unknown))
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			break
+		}
+
+		m := protowire.ConsumeFieldValue(num, typ, unknown[n:])
+		if m < 0 {
+			break
+		}
+
+		if num != relationshipExpirationField {
+			retained = append(retained, unknown[:n+m]...)
+		}
+		unknown = unknown[n+m:]
+	}
+
+	if expiration != nil {
+		// Marshaling a Timestamp cannot fail.
+		value, _ := proto.MarshalOptions{Deterministic: true}.Marshal(expiration)
+		retained = protowire.AppendTag(retained, relationshipExpirationField, protowire.BytesType)
+		retained = protowire.AppendBytes(retained, value)
+	}
+
+	rel.ProtoReflect().SetUnknown(retained)
+}
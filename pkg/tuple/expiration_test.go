@@ -0,0 +1,94 @@
+package tuple
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRelationshipExpiration(t *testing.T) {
+	require := require.New(t)
+
+	relationship := rel("document", "foo", "viewer", "user", "tom", "")
+	expiration, err := RelationshipExpiration(relationship)
+	require.NoError(err)
+	require.Nil(expiration)
+
+	// Unrelated unknown fields are retained when the expiration is set and removed.
+	relationship.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 42, protowire.VarintType), 7))
+	unrelated := relationship.ProtoReflect().GetUnknown()
+
+	expiresAt := time.Date(2023, 9, 15, 13, 0, 0, 0, time.UTC)
+	SetRelationshipExpiration(relationship, timestamppb.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	SetRelationshipExpiration(relationship, timestamppb.New(expiresAt))
+
+	expiration, err = RelationshipExpiration(relationship)
+	require.NoError(err)
+	require.Equal(expiresAt, expiration.AsTime())
+
+	// The expiration is carried on the wire as field 5 of the relationship.
+	marshaled, err := proto.Marshal(relationship)
+	require.NoError(err)
+
+	unmarshaled := &v1.Relationship{}
+	require.NoError(proto.Unmarshal(marshaled, unmarshaled))
+	expiration, err = RelationshipExpiration(unmarshaled)
+	require.NoError(err)
+	require.Equal(expiresAt, expiration.AsTime())
+	require.Equal("document:foo#viewer@user:tom[expiration:2023-09-15T13:00:00Z]", MustStringRelationship(unmarshaled))
+
+	SetRelationshipExpiration(relationship, nil)
+	expiration, err = RelationshipExpiration(relationship)
+	require.NoError(err)
+	require.Nil(expiration)
+	require.Equal(unrelated, relationship.ProtoReflect().GetUnknown())
+}
+
+func TestInvalidRelationshipExpiration(t *testing.T) {
+	tcs := []struct {
+		name    string
+		unknown []byte
+	}{
+		{
+			"wrong wire type",
+			protowire.AppendVarint(protowire.AppendTag(nil, relationshipExpirationField, protowire.VarintType), 1),
+		},
+		{
+			"truncated",
+			protowire.AppendTag(nil, relationshipExpirationField, protowire.BytesType),
+		},
+		{
+			"invalid timestamp",
+			protowire.AppendBytes(
+				protowire.AppendTag(nil, relationshipExpirationField, protowire.BytesType),
+				protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), uint64(1<<60)),
+			),
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			relationship := rel("document", "foo", "viewer", "user", "tom", "")
+			relationship.ProtoReflect().SetUnknown(tc.unknown)
+
+			_, err := RelationshipExpiration(relationship)
+			require.Error(t, err)
+
+			// The expiration is not dropped when converting the relationship.
+			_, err = FromRelationship(relationship)
+			require.Error(t, err)
+
+			_, err = UpdateFromRelationshipUpdates([]*v1.RelationshipUpdate{
+				{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: relationship},
+			})
+			require.Error(t, err)
+			require.Panics(t, func() { MustFromRelationship(relationship) })
+		})
+	}
+}
@@ -47,7 +47,12 @@ func StringRelationship(rel *v1.Relationship) (string, error) {
 		return "", err
 	}
 
-	return StringRelationshipWithoutCaveat(rel) + caveatString, nil
+	expiration, err := RelationshipExpiration(rel)
+	if err != nil {
+		return "", err
+	}
+
+	return StringRelationshipWithoutCaveat(rel) + caveatString + StringExpiration(expiration), nil
 }
 
 // StringRelationshipWithoutCaveat converts a v1.Relationship to a string, excluding any caveat.
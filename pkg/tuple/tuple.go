@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/jzelinskie/stringz"
 	"golang.org/x/exp/maps"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
@@ -28,6 +30,7 @@ const (
 	subjectIDExpr     = "([a-zA-Z0-9/_|\\-=+]{1,})|\\*"
 	relationExpr      = "[a-z][a-z0-9_]{1,62}[a-z0-9]"
 	caveatNameExpr    = "([a-z][a-z0-9_]{1,61}[a-z0-9]/)?[a-z][a-z0-9_]{1,62}[a-z0-9]"
+	expirationExpr    = "[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9:.]+(Z|[+\\-][0-9]{2}:[0-9]{2})"
 )
 
 var onrExpr = fmt.Sprintf(
@@ -46,6 +49,8 @@ var subjectExpr = fmt.Sprintf(
 
 var caveatExpr = fmt.Sprintf(`\[(?P<caveatName>(%s))(:(?P<caveatContext>(\{(.+)\})))?\]`, caveatNameExpr)
 
+var expirationClauseExpr = fmt.Sprintf(`\[expiration:(?P<expiration>%s)\]`, expirationExpr)
+
 var (
 	onrRegex        = regexp.MustCompile(fmt.Sprintf("^%s$", onrExpr))
 	subjectRegex    = regexp.MustCompile(fmt.Sprintf("^%s$", subjectExpr))
@@ -55,10 +60,11 @@ var (
 
 var parserRegex = regexp.MustCompile(
 	fmt.Sprintf(
-		`^%s@%s(%s)?$`,
+		`^%s@%s(%s)?(%s)?$`,
 		onrExpr,
 		subjectExpr,
 		caveatExpr,
+		expirationClauseExpr,
 	),
 )
 
@@ -106,7 +112,7 @@ func String(tpl *core.RelationTuple) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s@%s%s", StringONR(tpl.ResourceAndRelation), StringONR(tpl.Subject), caveatString), nil
+	return fmt.Sprintf("%s@%s%s%s", StringONR(tpl.ResourceAndRelation), StringONR(tpl.Subject), caveatString, StringExpiration(tpl.OptionalExpirationTime)), nil
 }
 
 // StringWithoutCaveat converts a tuple to a string, without its caveat included.
@@ -136,6 +142,16 @@ func StringCaveat(caveat *core.ContextualizedCaveat) (string, error) {
 	return fmt.Sprintf("[%s%s]", caveat.CaveatName, contextString), nil
 }
 
+// StringExpiration converts the expiration time of a tuple to a string. If the expiration is nil,
+// returns an empty string.
+func StringExpiration(expiration *timestamppb.Timestamp) string {
+	if expiration == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("[expiration:%s]", expiration.AsTime().UTC().Format(time.RFC3339Nano))
+}
+
 // StringCaveatContext converts the context of a caveat to a string. If the context is nil or empty, returns an empty string.
 func StringCaveatContext(context *structpb.Struct) (string, error) {
 	if context == nil || len(context.Fields) == 0 {
@@ -207,6 +223,16 @@ func Parse(tpl string) *core.RelationTuple {
 		}
 	}
 
+	var optionalExpiration *timestamppb.Timestamp
+	expirationString := groups[stringz.SliceIndex(parserRegex.SubexpNames(), "expiration")]
+	if expirationString != "" {
+		expiration, err := time.Parse(time.RFC3339Nano, expirationString)
+		if err != nil {
+			return nil
+		}
+		optionalExpiration = timestamppb.New(expiration)
+	}
+
 	resourceID := groups[stringz.SliceIndex(parserRegex.SubexpNames(), "resourceID")]
 	if err := ValidateResourceID(resourceID); err != nil {
 		return nil
@@ -228,7 +254,8 @@ func Parse(tpl string) *core.RelationTuple {
 			ObjectId:  subjectID,
 			Relation:  subjectRelation,
 		},
-		Caveat: optionalCaveat,
+		Caveat:                 optionalCaveat,
+		OptionalExpirationTime: optionalExpiration,
 	}
 }
 
@@ -280,7 +307,7 @@ func ToRelationship(tpl *core.RelationTuple) *v1.Relationship {
 			Context:    tpl.Caveat.Context,
 		}
 	}
-	return &v1.Relationship{
+	rel := &v1.Relationship{
 		Resource: &v1.ObjectReference{
 			ObjectType: tpl.ResourceAndRelation.Namespace,
 			ObjectId:   tpl.ResourceAndRelation.ObjectId,
@@ -295,6 +322,10 @@ func ToRelationship(tpl *core.RelationTuple) *v1.Relationship {
 		},
 		OptionalCaveat: caveat,
 	}
+	if tpl.OptionalExpirationTime != nil {
+		SetRelationshipExpiration(rel, tpl.OptionalExpirationTime)
+	}
+	return rel
 }
 
 // MustToFilter converts a RelationTuple into a RelationshipFilter. Will panic if
@@ -356,14 +387,20 @@ func UpdatesToRelationshipUpdates(updates []*core.RelationTupleUpdate) []*v1.Rel
 	return relationshipUpdates
 }
 
-func UpdateFromRelationshipUpdates(updates []*v1.RelationshipUpdate) []*core.RelationTupleUpdate {
+// UpdateFromRelationshipUpdates converts a slice of RelationshipUpdate into a slice of
+// RelationTupleUpdate, returning an error if any relationship has an invalid expiration.
+func UpdateFromRelationshipUpdates(updates []*v1.RelationshipUpdate) ([]*core.RelationTupleUpdate, error) {
 	relationshipUpdates := make([]*core.RelationTupleUpdate, 0, len(updates))
 
 	for _, update := range updates {
-		relationshipUpdates = append(relationshipUpdates, UpdateFromRelationshipUpdate(update))
+		relationshipUpdate, err := UpdateFromRelationshipUpdate(update)
+		if err != nil {
+			return nil, err
+		}
+		relationshipUpdates = append(relationshipUpdates, relationshipUpdate)
 	}
 
-	return relationshipUpdates
+	return relationshipUpdates, nil
 }
 
 // UpdateToRelationshipUpdate converts a RelationTupleUpdate into a
@@ -392,7 +429,12 @@ func MustFromRelationship(r *v1.Relationship) *core.RelationTuple {
 	if err := r.Validate(); err != nil {
 		panic(fmt.Sprintf("invalid relationship: %#v %s", r, err))
 	}
-	return FromRelationship(r)
+
+	tpl, err := FromRelationship(r)
+	if err != nil {
+		panic(fmt.Sprintf("invalid relationship: %#v %s", r, err))
+	}
+	return tpl
 }
 
 // MustFromRelationships converts a slice of Relationship's into a slice of RelationTuple's.
@@ -405,8 +447,9 @@ func MustFromRelationships(rels []*v1.Relationship) []*core.RelationTuple {
 	return tuples
 }
 
-// FromRelationship converts a Relationship into a RelationTuple.
-func FromRelationship(r *v1.Relationship) *core.RelationTuple {
+// FromRelationship converts a Relationship into a RelationTuple, returning an error if the
+// expiration of the Relationship is invalid.
+func FromRelationship(r *v1.Relationship) (*core.RelationTuple, error) {
 	var caveat *core.ContextualizedCaveat
 	if r.OptionalCaveat != nil {
 		caveat = &core.ContextualizedCaveat{
@@ -414,6 +457,11 @@ func FromRelationship(r *v1.Relationship) *core.RelationTuple {
 			Context:    r.OptionalCaveat.Context,
 		}
 	}
+	expiration, err := RelationshipExpiration(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.RelationTuple{
 		ResourceAndRelation: &core.ObjectAndRelation{
 			Namespace: r.Resource.ObjectType,
@@ -425,13 +473,14 @@ func FromRelationship(r *v1.Relationship) *core.RelationTuple {
 			ObjectId:  r.Subject.Object.ObjectId,
 			Relation:  stringz.DefaultEmpty(r.Subject.OptionalRelation, Ellipsis),
 		},
-		Caveat: caveat,
-	}
+		Caveat:                 caveat,
+		OptionalExpirationTime: expiration,
+	}, nil
 }
 
 // UpdateFromRelationshipUpdate converts a RelationshipUpdate into a
-// RelationTupleUpdate.
-func UpdateFromRelationshipUpdate(update *v1.RelationshipUpdate) *core.RelationTupleUpdate {
+// RelationTupleUpdate, returning an error if the expiration of the relationship is invalid.
+func UpdateFromRelationshipUpdate(update *v1.RelationshipUpdate) (*core.RelationTupleUpdate, error) {
 	var op core.RelationTupleUpdate_Operation
 	switch update.Operation {
 	case v1.RelationshipUpdate_OPERATION_CREATE:
@@ -444,10 +493,15 @@ func UpdateFromRelationshipUpdate(update *v1.RelationshipUpdate) *core.RelationT
 		panic("unknown tuple mutation")
 	}
 
+	tpl, err := FromRelationship(update.Relationship)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.RelationTupleUpdate{
 		Operation: op,
-		Tuple:     FromRelationship(update.Relationship),
-	}
+		Tuple:     tpl,
+	}, nil
 }
 
 // MustWithCaveat adds the given caveat name to the tuple. This is for testing only.
@@ -483,3 +537,10 @@ func WithCaveat(tpl *core.RelationTuple, caveatName string, contexts ...map[stri
 	}
 	return tpl, nil
 }
+
+// MustWithExpiration adds the given expiration time to the tuple. This is for testing only.
+func MustWithExpiration(tpl *core.RelationTuple, expiration time.Time) *core.RelationTuple {
+	tpl = tpl.CloneVT()
+	tpl.OptionalExpirationTime = timestamppb.New(expiration)
+	return tpl
+}
@@ -3,6 +3,7 @@ package tuple
 import (
 	"strings"
 	"testing"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/require"
@@ -384,6 +385,79 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseExpiration(t *testing.T) {
+	tcs := []struct {
+		input          string
+		expectedOutput string
+		expected       *core.RelationTuple
+	}{
+		{
+			"document:foo#viewer@user:tom[expiration:2023-09-15T13:00:00Z]",
+			"document:foo#viewer@user:tom[expiration:2023-09-15T13:00:00Z]",
+			MustWithExpiration(
+				makeTuple(ObjectAndRelation("document", "foo", "viewer"), ObjectAndRelation("user", "tom", "...")),
+				time.Date(2023, 9, 15, 13, 0, 0, 0, time.UTC),
+			),
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:2023-09-15T15:00:00.5+02:00]",
+			"document:foo#viewer@user:tom[expiration:2023-09-15T13:00:00.5Z]",
+			MustWithExpiration(
+				makeTuple(ObjectAndRelation("document", "foo", "viewer"), ObjectAndRelation("user", "tom", "...")),
+				time.Date(2023, 9, 15, 13, 0, 0, 500_000_000, time.UTC),
+			),
+		},
+		{
+			`document:foo#viewer@user:tom[somecaveat:{"hi":"there"}][expiration:2023-09-15T13:00:00Z]`,
+			`document:foo#viewer@user:tom[somecaveat:{"hi":"there"}][expiration:2023-09-15T13:00:00Z]`,
+			MustWithExpiration(
+				MustWithCaveat(
+					makeTuple(ObjectAndRelation("document", "foo", "viewer"), ObjectAndRelation("user", "tom", "...")),
+					"somecaveat",
+					map[string]any{"hi": "there"},
+				),
+				time.Date(2023, 9, 15, 13, 0, 0, 0, time.UTC),
+			),
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:2023-09-15T13:00:00Z][somecaveat]",
+			"",
+			nil,
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:2023-19-15T13:00:00Z]",
+			"",
+			nil,
+		},
+		{
+			"document:foo#viewer@user:tom[expiration:tomorrow]",
+			"",
+			nil,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			parsed := Parse(tc.input)
+			testutil.RequireProtoEqual(t, tc.expected, parsed, "found difference in parsed tuple")
+			if parsed == nil {
+				return
+			}
+
+			require.Equal(t, tc.expectedOutput, MustString(parsed))
+			require.NotContains(t, StringWithoutCaveat(parsed), "[")
+
+			// The expiration survives conversion to and from a v1 relationship.
+			relationship := ToRelationship(parsed)
+			require.Equal(t, tc.expectedOutput, MustRelString(relationship))
+			converted, err := FromRelationship(relationship)
+			require.NoError(t, err)
+			testutil.RequireProtoEqual(t, tc.expected, converted, "found difference in converted tuple")
+		})
+	}
+}
+
 func TestConvert(t *testing.T) {
 	for _, tc := range testCases {
 		tc := tc
@@ -400,7 +474,8 @@ func TestConvert(t *testing.T) {
 			relString := strings.Replace(MustRelString(relationship), " ", "", -1)
 			require.Equal(tc.expectedOutput, relString)
 
-			backToTpl := FromRelationship(relationship)
+			backToTpl, err := FromRelationship(relationship)
+			require.NoError(err)
 			testutil.RequireProtoEqual(t, tc.tupleFormat, backToTpl, "found difference in converted tuple")
 
 			serialized := strings.Replace(MustString(backToTpl), " ", "", -1)
@@ -0,0 +1,65 @@
+package writehook
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	writehookv1 "github.com/authzed/spicedb/pkg/proto/writehook/v1"
+)
+
+// NewGRPCHook returns a hook which calls out to a PreCommitHookService, typically served by a
+// sidecar, over the given connection. A non-zero timeout bounds each call, which is made while
+// the write's transaction is open.
+func NewGRPCHook(name string, conn grpc.ClientConnInterface, timeout time.Duration) PreCommitHook {
+	return &grpcHook{
+		name:    name,
+		client:  writehookv1.NewPreCommitHookServiceClient(conn),
+		timeout: timeout,
+	}
+}
+
+type grpcHook struct {
+	name    string
+	client  writehookv1.PreCommitHookServiceClient
+	timeout time.Duration
+}
+
+func (h *grpcHook) Name() string {
+	return h.name
+}
+
+func (h *grpcHook) PreCommit(ctx context.Context, _ datastore.Reader, updates []*core.RelationTupleUpdate) ([]*core.RelationTupleUpdate, error) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	resp, err := h.client.PreCommit(ctx, &writehookv1.PreCommitRequest{Updates: updates})
+	if err != nil {
+		// Only an explicit rejection rejects the write; a failure to call the hook means the
+		// write could not be checked, so the caller may retry it.
+		switch status.Code(err) {
+		case codes.Canceled:
+			return nil, err
+		case codes.DeadlineExceeded:
+			return nil, status.Errorf(codes.DeadlineExceeded, "pre-commit hook `%s` timed out: %s", h.name, status.Convert(err).Message())
+		default:
+			return nil, status.Errorf(codes.Unavailable, "pre-commit hook `%s` call failed: %s", h.name, status.Convert(err).Message())
+		}
+	}
+
+	if resp.RejectionReason != "" {
+		return nil, Reject(resp.RejectionReason)
+	}
+	if resp.ReplaceUpdates {
+		return resp.Updates, nil
+	}
+	return updates, nil
+}
@@ -0,0 +1,134 @@
+package writehook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/authzed/grpcutil"
+	"github.com/dustin/go-humanize"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	writehookv1 "github.com/authzed/spicedb/pkg/proto/writehook/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type fakeHookSvc struct {
+	writehookv1.UnimplementedPreCommitHookServiceServer
+}
+
+func (fakeHookSvc) PreCommit(ctx context.Context, req *writehookv1.PreCommitRequest) (*writehookv1.PreCommitResponse, error) {
+	for _, update := range req.Updates {
+		switch update.Tuple.Subject.ObjectId {
+		case "banned":
+			return &writehookv1.PreCommitResponse{RejectionReason: "user:banned cannot be granted access"}, nil
+		case "failing":
+			return nil, status.Error(codes.Internal, "sidecar is broken")
+		case "slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "annotated":
+			return &writehookv1.PreCommitResponse{
+				ReplaceUpdates: true,
+				Updates:        []*core.RelationTupleUpdate{tuple.Touch(update.Tuple)},
+			}, nil
+		}
+	}
+	return &writehookv1.PreCommitResponse{}, nil
+}
+
+func newFakeHookConn(t *testing.T) *grpc.ClientConn {
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+	writehookv1.RegisterPreCommitHookServiceServer(s, fakeHookSvc{})
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCHook(t *testing.T) {
+	hooks := []PreCommitHook{NewGRPCHook("sidecar", newFakeHookConn(t), 0)}
+
+	for _, tc := range []struct {
+		name             string
+		update           *core.RelationTupleUpdate
+		expectedUpdate   *core.RelationTupleUpdate
+		expectedErrorMsg string
+	}{
+		{
+			"unchanged",
+			tuple.Create(tuple.MustParse("document:first#viewer@user:tom")),
+			tuple.Create(tuple.MustParse("document:first#viewer@user:tom")),
+			"",
+		},
+		{
+			"annotated",
+			tuple.Create(tuple.MustParse("document:first#viewer@user:annotated")),
+			tuple.Touch(tuple.MustParse("document:first#viewer@user:annotated")),
+			"",
+		},
+		{
+			"rejected",
+			tuple.Create(tuple.MustParse("document:first#viewer@user:banned")),
+			nil,
+			"write rejected by hook `sidecar`: user:banned cannot be granted access",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updates, err := RunPreCommitHooks(context.Background(), nil, hooks, []*core.RelationTupleUpdate{tc.update})
+			if tc.expectedErrorMsg != "" {
+				require.ErrorAs(t, err, &ErrWriteRejected{})
+				require.EqualError(t, err, tc.expectedErrorMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, updates, 1)
+			require.True(t, tc.expectedUpdate.EqualVT(updates[0]))
+		})
+	}
+}
+
+func TestGRPCHookFailures(t *testing.T) {
+	hooks := []PreCommitHook{NewGRPCHook("sidecar", newFakeHookConn(t), 50*time.Millisecond)}
+
+	for _, tc := range []struct {
+		name         string
+		subjectID    string
+		expectedCode codes.Code
+	}{
+		{"error", "failing", codes.Unavailable},
+		{"timeout", "slow", codes.DeadlineExceeded},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			update := tuple.Create(tuple.MustParse("document:first#viewer@user:" + tc.subjectID))
+			_, err := RunPreCommitHooks(context.Background(), nil, hooks, []*core.RelationTupleUpdate{update})
+			require.Error(t, err)
+			require.False(t, errors.As(err, &ErrWriteRejected{}))
+			grpcutil.RequireStatus(t, tc.expectedCode, err)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package writehook defines hooks which are invoked with the relationship updates of every
+// WriteRelationships call, and with each batch of relationships created by a bulk import,
+// before they are committed, and which can reject or annotate them.
+package writehook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// PreCommitHook is invoked with the updates of a WriteRelationships call, within the
+// transaction in which they will be written.
+type PreCommitHook interface {
+	// Name returns the name of the hook, which is included in the errors of the writes it
+	// rejects.
+	Name() string
+
+	// PreCommit returns the updates to write, which may be the given updates or annotated
+	// copies of them. A hook rejects the write by returning an error made by Reject; any other
+	// error is a failure to run the hook, and is returned as is. The reader reads the state of
+	// the datastore within the transaction, before the updates are applied.
+	PreCommit(ctx context.Context, reader datastore.Reader, updates []*core.RelationTupleUpdate) ([]*core.RelationTupleUpdate, error)
+}
+
+// Reject returns the error with which a hook rejects a write for the given reason.
+func Reject(reason string) error {
+	return rejection{errors.New(reason)}
+}
+
+// rejection is the error with which a hook rejects a write, as opposed to one with which it
+// failed to run.
+type rejection struct {
+	error
+}
+
+// ErrWriteRejected is returned when a pre-commit hook rejects a write.
+type ErrWriteRejected struct {
+	error
+	hookName string
+}
+
+// HookName returns the name of the hook which rejected the write.
+func (err ErrWriteRejected) HookName() string {
+	return err.hookName
+}
+
+func (err ErrWriteRejected) Unwrap() error {
+	return err.error
+}
+
+// NewWriteRejectedErr constructs a new error for a write rejected by the named hook.
+func NewWriteRejectedErr(hookName string, err error) ErrWriteRejected {
+	return ErrWriteRejected{
+		error:    fmt.Errorf("write rejected by hook `%s`: %w", hookName, err),
+		hookName: hookName,
+	}
+}
+
+// RunPreCommitHooks invokes each of the hooks in order, each with the updates returned by the
+// one before it, and returns the updates returned by the last. A rejection by any of the hooks
+// is returned as an ErrWriteRejected.
+func RunPreCommitHooks(ctx context.Context, reader datastore.Reader, hooks []PreCommitHook, updates []*core.RelationTupleUpdate) ([]*core.RelationTupleUpdate, error) {
+	for _, hook := range hooks {
+		hooked, err := hook.PreCommit(ctx, reader, updates)
+		if err != nil {
+			if errors.As(err, &rejection{}) {
+				return nil, NewWriteRejectedErr(hook.Name(), err)
+			}
+			return nil, err
+		}
+		updates = hooked
+	}
+	return updates, nil
+}
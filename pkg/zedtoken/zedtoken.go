@@ -2,6 +2,9 @@
 package zedtoken
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -24,22 +27,153 @@ const (
 // zedtoken argument to Decode
 var ErrNilZedToken = errors.New("zedtoken pointer was nil")
 
+// ErrDifferentDatastore is returned as the base error when a zedtoken signed for
+// another datastore is provided to DecodeRevision
+var ErrDifferentDatastore = errors.New("zedtoken was issued by a different datastore")
+
+// ErrInvalidSignature is returned as the base error when a zedtoken which is unsigned
+// or has a signature made by none of the signing keys is provided to DecodeRevision
+var ErrInvalidSignature = errors.New("zedtoken signature is invalid")
+
+// Signer signs zedtokens as having been issued by a datastore, and verifies that
+// zedtokens were signed for the same datastore.
+type Signer struct {
+	datastoreUniqueID string
+	keys              [][]byte
+	acceptUnsigned    bool
+}
+
+// NewSigner creates a Signer for the datastore with the given unique ID. Zedtokens are
+// signed with the first key, and verified with any of the keys, so that keys can be
+// rotated by adding a new key before the one it replaces.
+func NewSigner(datastoreUniqueID string, keys ...string) (*Signer, error) {
+	if datastoreUniqueID == "" {
+		return nil, errors.New("a datastore unique ID is required to sign zedtokens")
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required to sign zedtokens")
+	}
+
+	signer := &Signer{datastoreUniqueID: datastoreUniqueID}
+	for index, key := range keys {
+		if len(key) == 0 {
+			return nil, fmt.Errorf("zedtoken signing key #%d is empty", index+1)
+		}
+		signer.keys = append(signer.keys, []byte(key))
+	}
+	return signer, nil
+}
+
+// signature returns the signature of the revision issued by the datastore made with the
+// given key.
+func signature(key []byte, revision string, datastoreUniqueID string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(revision))
+	mac.Write([]byte{0})
+	mac.Write([]byte(datastoreUniqueID))
+	return mac.Sum(nil)
+}
+
+// AcceptingUnsigned returns a copy of the signer which also accepts unsigned zedtokens,
+// for use while migrating to signed zedtokens. Zedtokens which are signed must still have
+// been signed for the same datastore by one of the keys.
+//
+// To migrate, the signer is first deployed accepting unsigned zedtokens, so that those
+// issued before signing was enabled remain valid while clients receive signed ones.
+// Once the unsigned zedtokens held by clients are no longer needed, such as after the
+// GC window of the datastore has passed, unsigned zedtokens should no longer be accepted.
+func (s *Signer) AcceptingUnsigned() *Signer {
+	accepting := *s
+	accepting.acceptUnsigned = true
+	return &accepting
+}
+
+func (s *Signer) verify(decoded *zedtoken.DecodedZedToken) error {
+	token := decoded.GetV1()
+	if token == nil {
+		return s.verifyRevision("", "", nil)
+	}
+	return s.verifyRevision(token.Revision, token.DatastoreUniqueId, token.Signature)
+}
+
+func (s *Signer) verifyRevision(revision string, datastoreUniqueID string, sig []byte) error {
+	if len(sig) == 0 {
+		if s.acceptUnsigned {
+			return nil
+		}
+		return ErrInvalidSignature
+	}
+
+	if datastoreUniqueID != s.datastoreUniqueID {
+		return ErrDifferentDatastore
+	}
+
+	for _, key := range s.keys {
+		if hmac.Equal(sig, signature(key, revision, datastoreUniqueID)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+type ctxKeyType struct{}
+
+var signerKey ctxKeyType = struct{}{}
+
+// ContextWithSigner returns a context in which zedtokens are signed and verified by the
+// signer, which may be nil to use unsigned zedtokens.
+func ContextWithSigner(ctx context.Context, signer *Signer) context.Context {
+	return context.WithValue(ctx, signerKey, signer)
+}
+
+func signerFromContext(ctx context.Context) *Signer {
+	if signer, ok := ctx.Value(signerKey).(*Signer); ok {
+		return signer
+	}
+	return nil
+}
+
+// SignRevision returns the unique ID of the datastore and the signature of the string form of
+// a revision, made by the signer of the context, for embedding in other opaque values issued
+// with a revision, such as cursors. Both are empty if the context has no signer.
+func SignRevision(ctx context.Context, revision string) (string, []byte) {
+	signer := signerFromContext(ctx)
+	if signer == nil {
+		return "", nil
+	}
+	return signer.datastoreUniqueID, signature(signer.keys[0], revision, signer.datastoreUniqueID)
+}
+
+// VerifyRevision verifies a signature returned by SignRevision, returning ErrDifferentDatastore
+// or ErrInvalidSignature as for zedtokens if the context has a signer.
+func VerifyRevision(ctx context.Context, revision string, datastoreUniqueID string, sig []byte) error {
+	if signer := signerFromContext(ctx); signer != nil {
+		return signer.verifyRevision(revision, datastoreUniqueID, sig)
+	}
+	return nil
+}
+
 // MustNewFromRevision generates an encoded zedtoken from an integral revision.
-func MustNewFromRevision(revision datastore.Revision) *v1.ZedToken {
-	encoded, err := NewFromRevision(revision)
+func MustNewFromRevision(ctx context.Context, revision datastore.Revision) *v1.ZedToken {
+	encoded, err := NewFromRevision(ctx, revision)
 	if err != nil {
 		panic(err)
 	}
 	return encoded
 }
 
-// NewFromRevision generates an encoded zedtoken from an integral revision.
-func NewFromRevision(revision datastore.Revision) (*v1.ZedToken, error) {
+// NewFromRevision generates an encoded zedtoken from an integral revision, which is
+// signed if the context has a signer.
+func NewFromRevision(ctx context.Context, revision datastore.Revision) (*v1.ZedToken, error) {
+	token := &zedtoken.DecodedZedToken_V1ZedToken{
+		Revision: revision.String(),
+	}
+	token.DatastoreUniqueId, token.Signature = SignRevision(ctx, token.Revision)
+
 	toEncode := &zedtoken.DecodedZedToken{
 		VersionOneof: &zedtoken.DecodedZedToken_V1{
-			V1: &zedtoken.DecodedZedToken_V1ZedToken{
-				Revision: revision.String(),
-			},
+			V1: token,
 		},
 	}
 	encoded, err := Encode(toEncode)
@@ -78,13 +212,20 @@ func Decode(encoded *v1.ZedToken) (*zedtoken.DecodedZedToken, error) {
 	return decoded, nil
 }
 
-// DecodeRevision converts and extracts the revision from a zedtoken or legacy zookie.
-func DecodeRevision(encoded *v1.ZedToken, ds revisionDecoder) (datastore.Revision, error) {
+// DecodeRevision converts and extracts the revision from a zedtoken or legacy zookie. If the
+// context has a signer, the zedtoken must have been signed for the same datastore.
+func DecodeRevision(ctx context.Context, encoded *v1.ZedToken, ds revisionDecoder) (datastore.Revision, error) {
 	decoded, err := Decode(encoded)
 	if err != nil {
 		return datastore.NoRevision, err
 	}
 
+	if signer := signerFromContext(ctx); signer != nil {
+		if err := signer.verify(decoded); err != nil {
+			return datastore.NoRevision, fmt.Errorf(errDecodeError, err)
+		}
+	}
+
 	switch ver := decoded.VersionOneof.(type) {
 	case *zedtoken.DecodedZedToken_DeprecatedV1Zookie:
 		return revision.NewFromDecimal(decimal.NewFromInt(int64(ver.DeprecatedV1Zookie.Revision))), nil
@@ -1,6 +1,7 @@
 package zedtoken
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -31,10 +32,10 @@ func TestZedTokenEncode(t *testing.T) {
 		rev := rev
 		t.Run(rev.String(), func(t *testing.T) {
 			require := require.New(t)
-			encoded, err := NewFromRevision(rev)
+			encoded, err := NewFromRevision(context.Background(), rev)
 			require.NoError(err)
 
-			decoded, err := DecodeRevision(encoded, revision.DecimalDecoder{})
+			decoded, err := DecodeRevision(context.Background(), encoded, revision.DecimalDecoder{})
 			require.NoError(err)
 			require.True(rev.Equal(decoded))
 		})
@@ -122,7 +123,7 @@ func TestDecode(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			require := require.New(t)
 
-			decoded, err := DecodeRevision(&v1.ZedToken{
+			decoded, err := DecodeRevision(context.Background(), &v1.ZedToken{
 				Token: testCase.token,
 			}, revision.DecimalDecoder{})
 			if testCase.expectError {
@@ -139,3 +140,116 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedZedTokens(t *testing.T) {
+	require := require.New(t)
+	rev := revision.NewFromDecimal(decimal.NewFromInt(1621538189028928000))
+
+	signer, err := NewSigner("production", "newkey", "oldkey")
+	require.NoError(err)
+	ctx := ContextWithSigner(context.Background(), signer)
+
+	encoded, err := NewFromRevision(ctx, rev)
+	require.NoError(err)
+
+	decoded, err := DecodeRevision(ctx, encoded, revision.DecimalDecoder{})
+	require.NoError(err)
+	require.True(rev.Equal(decoded))
+
+	// Signed zedtokens can be decoded without a signer.
+	decoded, err = DecodeRevision(context.Background(), encoded, revision.DecimalDecoder{})
+	require.NoError(err)
+	require.True(rev.Equal(decoded))
+
+	// Zedtokens signed with a key which is still accepted can be decoded after rotation.
+	oldSigner, err := NewSigner("production", "oldkey")
+	require.NoError(err)
+	rotated, err := NewFromRevision(ContextWithSigner(context.Background(), oldSigner), rev)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, rotated, revision.DecimalDecoder{})
+	require.NoError(err)
+
+	retiredSigner, err := NewSigner("production", "retiredkey")
+	require.NoError(err)
+	retired, err := NewFromRevision(ContextWithSigner(context.Background(), retiredSigner), rev)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, retired, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrInvalidSignature)
+
+	stagingSigner, err := NewSigner("staging", "newkey")
+	require.NoError(err)
+	staging, err := NewFromRevision(ContextWithSigner(context.Background(), stagingSigner), rev)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, staging, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrDifferentDatastore)
+
+	unsigned, err := NewFromRevision(context.Background(), rev)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, unsigned, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrInvalidSignature)
+
+	// Changing the revision of a signed zedtoken invalidates its signature.
+	forged, err := Decode(encoded)
+	require.NoError(err)
+	forged.GetV1().Revision = "1"
+	reencoded, err := Encode(forged)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, reencoded, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrInvalidSignature)
+}
+
+func TestSignerAcceptingUnsigned(t *testing.T) {
+	require := require.New(t)
+	rev := revision.NewFromDecimal(decimal.NewFromInt(1621538189028928000))
+
+	signer, err := NewSigner("production", "key")
+	require.NoError(err)
+	ctx := ContextWithSigner(context.Background(), signer.AcceptingUnsigned())
+
+	// Zedtokens issued while accepting unsigned ones are still signed.
+	encoded, err := NewFromRevision(ctx, rev)
+	require.NoError(err)
+	decoded, err := DecodeRevision(ContextWithSigner(context.Background(), signer), encoded, revision.DecimalDecoder{})
+	require.NoError(err)
+	require.True(rev.Equal(decoded))
+
+	unsigned, err := NewFromRevision(context.Background(), rev)
+	require.NoError(err)
+	decoded, err = DecodeRevision(ctx, unsigned, revision.DecimalDecoder{})
+	require.NoError(err)
+	require.True(rev.Equal(decoded))
+
+	// Legacy zookies carry no signature either.
+	_, err = DecodeRevision(ctx, &v1.ZedToken{Token: "CAESAggB"}, revision.DecimalDecoder{})
+	require.NoError(err)
+
+	// Signed zedtokens must still be valid.
+	retiredSigner, err := NewSigner("production", "retiredkey")
+	require.NoError(err)
+	retired, err := NewFromRevision(ContextWithSigner(context.Background(), retiredSigner), rev)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, retired, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrInvalidSignature)
+
+	stagingSigner, err := NewSigner("staging", "key")
+	require.NoError(err)
+	staging, err := NewFromRevision(ContextWithSigner(context.Background(), stagingSigner), rev)
+	require.NoError(err)
+	_, err = DecodeRevision(ctx, staging, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrDifferentDatastore)
+
+	// The original signer is unchanged.
+	_, err = DecodeRevision(ContextWithSigner(context.Background(), signer), unsigned, revision.DecimalDecoder{})
+	require.ErrorIs(err, ErrInvalidSignature)
+}
+
+func TestNewSigner(t *testing.T) {
+	_, err := NewSigner("", "key")
+	require.Error(t, err)
+
+	_, err = NewSigner("production")
+	require.Error(t, err)
+
+	_, err = NewSigner("production", "key", "")
+	require.Error(t, err)
+}